@@ -0,0 +1,74 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package checkworker implements 'agent check-worker'.
+package checkworker
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/command"
+	"github.com/DataDog/datadog-agent/comp/core"
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	ipcfx "github.com/DataDog/datadog-agent/comp/core/ipc/fx"
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	secretsfx "github.com/DataDog/datadog-agent/comp/core/secrets/fx"
+	tagger "github.com/DataDog/datadog-agent/comp/core/tagger/def"
+	remoteTaggerfx "github.com/DataDog/datadog-agent/comp/core/tagger/fx-remote"
+	workloadfilter "github.com/DataDog/datadog-agent/comp/core/workloadfilter/def"
+	workloadfilterfx "github.com/DataDog/datadog-agent/comp/core/workloadfilter/fx"
+	"github.com/DataDog/datadog-agent/pkg/collector/python"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+// cliParams are the command-line arguments for this subcommand
+type cliParams struct {
+	*command.GlobalParams
+
+	logLevelDefaultOff command.LogLevelDefaultOff
+	socketPath         string
+}
+
+// Commands returns a slice of subcommands for the 'agent' command.
+func Commands(globalParams *command.GlobalParams) []*cobra.Command {
+	cliParams := &cliParams{
+		GlobalParams: globalParams,
+	}
+	checkWorkerCommand := &cobra.Command{
+		Use:   "check-worker",
+		Short: "Run a Python check instance isolated from the Agent process",
+		Long: `Run a Python check instance configured with 'isolation: process' in its own interpreter.
+This command is started and supervised by the Agent, it isn't meant to be run manually.`,
+		Hidden: true,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if cliParams.socketPath == "" {
+				return errors.New("the --socket flag is required")
+			}
+			return fxutil.OneShot(runWorker,
+				fx.Supply(cliParams),
+				fx.Supply(core.BundleParams{
+					ConfigParams: config.NewAgentParams(globalParams.ConfFilePath, config.WithExtraConfFiles(globalParams.ExtraConfFilePath), config.WithFleetPoliciesDirPath(globalParams.FleetPoliciesDirPath)),
+					LogParams:    log.ForOneShot(command.LoggerName, cliParams.logLevelDefaultOff.Value(), false),
+				}),
+				core.Bundle(),
+				secretsfx.Module(),
+				ipcfx.ModuleReadOnly(),
+				remoteTaggerfx.Module(tagger.NewRemoteParams()),
+				workloadfilterfx.Module(),
+			)
+		},
+	}
+	checkWorkerCommand.Flags().StringVar(&cliParams.socketPath, "socket", "", "path of the socket the Agent listens on")
+	cliParams.logLevelDefaultOff.Register(checkWorkerCommand)
+
+	return []*cobra.Command{checkWorkerCommand}
+}
+
+func runWorker(_ log.Component, params *cliParams, tagger tagger.Component, filterStore workloadfilter.Component) error {
+	return python.RunIsolatedCheckWorker(params.socketPath, tagger, filterStore)
+}
@@ -0,0 +1,27 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checkworker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/command"
+	"github.com/DataDog/datadog-agent/comp/core"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+func TestCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"check-worker", "--socket", "/tmp/worker.sock", "--log_level", "debug"},
+		runWorker,
+		func(cliParams *cliParams, _ core.BundleParams) {
+			require.Equal(t, "/tmp/worker.sock", cliParams.socketPath)
+			require.Equal(t, "debug", cliParams.logLevelDefaultOff.Value())
+		})
+}
@@ -0,0 +1,163 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+//go:build ncm
+
+// Package networkconfigmanagement implements the 'agent network-config-management' subcommand.
+package networkconfigmanagement
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/command"
+	"github.com/DataDog/datadog-agent/comp/core"
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	ipc "github.com/DataDog/datadog-agent/comp/core/ipc/def"
+	ipcfx "github.com/DataDog/datadog-agent/comp/core/ipc/fx"
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	secretsnoopfx "github.com/DataDog/datadog-agent/comp/core/secrets/fx-noop"
+	ncmconfig "github.com/DataDog/datadog-agent/pkg/networkconfigmanagement/config"
+	ncmremote "github.com/DataDog/datadog-agent/pkg/networkconfigmanagement/remote"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+// cliParams are the command-line arguments for the host-keys subcommands
+type cliParams struct {
+	*command.GlobalParams
+
+	// args are the positional command-line arguments
+	args []string
+
+	knownHostsFile string
+}
+
+// Commands returns a slice of subcommands for the 'agent' command.
+func Commands(globalParams *command.GlobalParams) []*cobra.Command {
+	cliParams := &cliParams{
+		GlobalParams: globalParams,
+	}
+	logLevelDefaultOff := command.LogLevelDefaultOff{}
+
+	oneShot := func(fn interface{}) error {
+		return fxutil.OneShot(fn,
+			fx.Supply(cliParams),
+			fx.Supply(core.BundleParams{
+				ConfigParams: config.NewAgentParams(globalParams.ConfFilePath, config.WithExtraConfFiles(globalParams.ExtraConfFilePath), config.WithFleetPoliciesDirPath(globalParams.FleetPoliciesDirPath)),
+				LogParams:    log.ForOneShot(command.LoggerName, logLevelDefaultOff.Value(), true)}),
+			core.Bundle(),
+			secretsnoopfx.Module(),
+			ipcfx.ModuleReadOnly(),
+		)
+	}
+
+	ncmCmd := &cobra.Command{
+		Use:   "network-config-management",
+		Short: "Network Config Management utilities",
+	}
+	logLevelDefaultOff.Register(ncmCmd)
+
+	hostKeysCmd := &cobra.Command{
+		Use:   "host-keys",
+		Short: "Manage the SSH host keys pinned for the network devices",
+	}
+	ncmCmd.AddCommand(hostKeysCmd)
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the SSH host keys pinned for the network devices",
+		Long: `List the SSH host keys pinned for the network devices. By default, the keys trusted on first use by the
+agent are listed; use --known-hosts-file to list the keys of another known_hosts file.`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return oneShot(listHostKeys)
+		},
+	}
+	listCmd.Flags().StringVar(&cliParams.knownHostsFile, "known-hosts-file", "", "known_hosts file to list the keys of")
+	hostKeysCmd.AddCommand(listCmd)
+
+	repinCmd := &cobra.Command{
+		Use:   "repin <ip_address>[:port]",
+		Short: "Replace the SSH host key pinned for a network device by the key it currently presents",
+		Long: `Connect to a network device configured in the running agent, and replace the SSH host key pinned for it by
+the key it currently presents. Only use this command once you have confirmed the device key was legitimately changed.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			cliParams.args = args
+			return oneShot(repinHostKey)
+		},
+	}
+	hostKeysCmd.AddCommand(repinCmd)
+
+	return []*cobra.Command{ncmCmd}
+}
+
+func listHostKeys(cliParams *cliParams, conf config.Component) error {
+	knownHostsFile := cliParams.knownHostsFile
+	if knownHostsFile == "" {
+		knownHostsFile = ncmremote.KnownHostsStorePath(conf.GetString("run_path"))
+	}
+
+	keys, err := ncmremote.ListPinnedHostKeys(knownHostsFile)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		fmt.Printf("No host keys pinned in %s\n", knownHostsFile)
+		return nil
+	}
+
+	fmt.Printf("Host keys pinned in %s:\n", knownHostsFile)
+	for _, key := range keys {
+		fmt.Printf("  %s %s %s\n", color.BlueString(strings.Join(key.Hosts, ",")), key.KeyType, key.Fingerprint)
+	}
+	return nil
+}
+
+func repinHostKey(cliParams *cliParams, conf config.Component, client ipc.HTTPClient) error {
+	deviceIP := cliParams.args[0]
+	if host, _, err := net.SplitHostPort(deviceIP); err == nil {
+		deviceIP = host
+	}
+
+	ncmContext, err := ncmconfig.GetNCMContextFromCoreCheck(client)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve the network config management configuration from the agent, is it running? %w", err)
+	}
+	device, ok := ncmContext.Devices[deviceIP]
+	if !ok {
+		return fmt.Errorf("no network config management device configured with ip_address %s", deviceIP)
+	}
+
+	if device.Transport == ncmconfig.TransportRESTCONF {
+		return fmt.Errorf("the configuration of device %s is retrieved with %s, which does not use SSH host keys", deviceIP, device.Transport)
+	}
+	sshConfig := ncmremote.NewSSHClientConfig(&device, conf.GetString("run_path"))
+	switch sshConfig.HostKeyPolicy {
+	case ncmconfig.HostKeyPolicyInsecure:
+		return fmt.Errorf("the host key of device %s is not verified (ssh_host_key_policy: %s), there is no key to re-pin", deviceIP, sshConfig.HostKeyPolicy)
+	case ncmconfig.HostKeyPolicyStrict:
+		return fmt.Errorf("the host keys of device %s are read from %s (ssh_host_key_policy: %s), update this file instead", deviceIP, sshConfig.KnownHostsFile, sshConfig.HostKeyPolicy)
+	}
+
+	key, err := ncmremote.FetchHostKey(&device)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve the host key of device %s: %w", deviceIP, err)
+	}
+
+	host := net.JoinHostPort(device.IPAddress, device.Auth.Port)
+	if err := ncmremote.PinHostKey(sshConfig.KnownHostsFile, host, key); err != nil {
+		return fmt.Errorf("unable to pin the host key of device %s: %w", deviceIP, err)
+	}
+
+	fmt.Printf("Pinned host key %s %s for %s in %s\n", key.Type(), color.GreenString(ssh.FingerprintSHA256(key)), host, sshConfig.KnownHostsFile)
+	return nil
+}
@@ -0,0 +1,20 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+//go:build !ncm
+
+// Package networkconfigmanagement does nothing when compiling without the ncm build tag.
+package networkconfigmanagement
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/command"
+)
+
+// Commands returns nil when compiling without the ncm build tag
+func Commands(*command.GlobalParams) []*cobra.Command {
+	return nil
+}
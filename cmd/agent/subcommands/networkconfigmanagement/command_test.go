@@ -0,0 +1,37 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+//go:build ncm
+
+package networkconfigmanagement
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/command"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+func TestListHostKeysCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"network-config-management", "host-keys", "list", "--known-hosts-file", "/etc/ssh/ssh_known_hosts"},
+		listHostKeys,
+		func(cliParams *cliParams) {
+			require.Equal(t, "/etc/ssh/ssh_known_hosts", cliParams.knownHostsFile)
+		})
+}
+
+func TestRepinHostKeyCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"network-config-management", "host-keys", "repin", "10.0.0.1:22"},
+		repinHostKey,
+		func(cliParams *cliParams) {
+			require.Equal(t, []string{"10.0.0.1:22"}, cliParams.args)
+		})
+}
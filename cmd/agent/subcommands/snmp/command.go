@@ -206,6 +206,10 @@ func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 	// This command does nothing until the backend supports it, so it isn't enabled yet.
 	snmpCmd.AddCommand(snmpScanCmd)
 
+	snmpCmd.AddCommand(profileCommand(globalParams))
+	snmpCmd.AddCommand(simulateCommand(globalParams))
+	snmpCmd.AddCommand(trapsCommand(globalParams))
+
 	return []*cobra.Command{snmpCmd}
 }
 
@@ -62,6 +62,59 @@ func TestScanCommand(t *testing.T) {
 		})
 }
 
+func TestProfileTestCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"snmp", "profile", "test", "--profile", "my-device.yaml", "--walk", "my-device.snmprec", "--json"},
+		testProfile,
+		func(params *profileTestParams, args argsType) {
+			require.Empty(t, args)
+			require.Equal(t, "my-device.yaml", params.profilePath)
+			require.Equal(t, "my-device.snmprec", params.walkPath)
+			require.True(t, params.jsonOutput)
+		})
+}
+
+func TestProfileGenerateCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"snmp", "profile", "generate", "--walk", "my-device.snmprec", "-o", "my-device.yaml"},
+		generateProfile,
+		func(params *profileGenerateParams, args argsType) {
+			require.Empty(t, args)
+			require.Equal(t, "my-device.snmprec", params.walkPath)
+			require.Equal(t, "my-device.yaml", params.outputPath)
+		})
+}
+
+func TestSimulateCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"snmp", "simulate", "my-device.snmprec", "-u", "datadog", "-a", "sha", "-A", "auth-key"},
+		simulate,
+		func(params *simulateParams, args argsType) {
+			require.Equal(t, argsType{"my-device.snmprec"}, args)
+			require.Equal(t, defaultSimulatorAddress, params.address)
+			require.Equal(t, "datadog", params.username)
+			require.Equal(t, "SHA", params.authProtocol)
+			require.Equal(t, "auth-key", params.authKey)
+		})
+}
+
+func TestTrapsResolveCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"snmp", "traps", "resolve", "1.3.6.1.6.3.1.1.5.3", "1.3.6.1.2.1.2.2.1.1.2"},
+		resolveTrap,
+		func(args argsType) {
+			require.Equal(t, argsType{"1.3.6.1.6.3.1.1.5.3", "1.3.6.1.2.1.2.2.1.1.2"}, args)
+		})
+}
+
+func TestFormatNamedNumbers(t *testing.T) {
+	assert.Equal(t, "unknown(-1), up(1), down(2)", formatNamedNumbers(map[int]string{2: "down", -1: "unknown", 1: "up"}))
+}
+
 func TestSplitIP(t *testing.T) {
 	for _, tc := range []struct {
 		addr    string
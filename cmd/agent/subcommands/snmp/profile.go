@@ -0,0 +1,201 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package snmp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/command"
+	"github.com/DataDog/datadog-agent/comp/core"
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	secretsfx "github.com/DataDog/datadog-agent/comp/core/secrets/fx"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/profiletest"
+	"github.com/DataDog/datadog-agent/pkg/snmp/profilegen"
+	"github.com/DataDog/datadog-agent/pkg/snmp/snmprec"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+// profileTestParams holds the flags of `agent snmp profile test`
+type profileTestParams struct {
+	profilePath string
+	walkPath    string
+	jsonOutput  bool
+}
+
+// profileGenerateParams holds the flags of `agent snmp profile generate`
+type profileGenerateParams struct {
+	walkPath   string
+	outputPath string
+}
+
+// profileCommand returns the 'agent snmp profile' command, grouping the tools to write profiles.
+func profileCommand(globalParams *command.GlobalParams) *cobra.Command {
+	profileCmd := &cobra.Command{
+		Use:   "profile",
+		Short: "SNMP profile tools",
+		Long:  ``,
+	}
+
+	testParams := &profileTestParams{}
+	profileTestCmd := &cobra.Command{
+		Use:   "test --profile <profile.yaml> --walk <device.snmprec>",
+		Short: "Test a profile against a recorded walk.",
+		Long: `Run the SNMP check with a profile against a walk recorded in the snmprec format, printing the metrics,
+		tags and metadata it collects. Warnings are printed for the OIDs of the profile missing from the walk.
+		The profile can extend the default profiles of the agent.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := fxutil.OneShot(testProfile,
+				fx.Supply(testParams),
+				fx.Provide(func() argsType { return args }),
+				fx.Supply(core.BundleParams{
+					ConfigParams: config.NewAgentParams(globalParams.ConfFilePath, config.WithExtraConfFiles(globalParams.ExtraConfFilePath), config.WithFleetPoliciesDirPath(globalParams.FleetPoliciesDirPath)),
+					LogParams:    log.ForOneShot(command.LoggerName, "off", true)}),
+				core.Bundle(),
+				secretsfx.Module(),
+			)
+			if err != nil {
+				var ue configErr
+				if errors.As(err, &ue) {
+					fmt.Println("Usage:", cmd.UseLine())
+				}
+				return err
+			}
+			return nil
+		},
+	}
+	profileTestCmd.Flags().StringVarP(&testParams.profilePath, "profile", "p", "", "Path to the profile to test")
+	profileTestCmd.Flags().StringVarP(&testParams.walkPath, "walk", "w", "", "Path to the walk to test the profile against, in the snmprec format")
+	profileTestCmd.Flags().BoolVar(&testParams.jsonOutput, "json", false, "Print the result as JSON")
+	profileCmd.AddCommand(profileTestCmd)
+
+	generateParams := &profileGenerateParams{}
+	profileGenerateCmd := &cobra.Command{
+		Use:   "generate --walk <device.snmprec>",
+		Short: "Generate a starter profile from a recorded walk.",
+		Long: `Generate a starter profile from a walk recorded in the snmprec format. The profile extends the default
+		profiles covering the IF-MIB, ENTITY-SENSOR-MIB and HOST-RESOURCES-MIB tables found in the walk, matches the
+		sysObjectID of the device, and collects the other numeric scalars and table columns, with metric types
+		guessed from their SMI types and rows tagged by index.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := fxutil.OneShot(generateProfile,
+				fx.Supply(generateParams),
+				fx.Provide(func() argsType { return args }),
+				fx.Supply(core.BundleParams{
+					ConfigParams: config.NewAgentParams(globalParams.ConfFilePath, config.WithExtraConfFiles(globalParams.ExtraConfFilePath), config.WithFleetPoliciesDirPath(globalParams.FleetPoliciesDirPath)),
+					LogParams:    log.ForOneShot(command.LoggerName, "off", true)}),
+				core.Bundle(),
+				secretsfx.Module(),
+			)
+			if err != nil {
+				var ue configErr
+				if errors.As(err, &ue) {
+					fmt.Println("Usage:", cmd.UseLine())
+				}
+				return err
+			}
+			return nil
+		},
+	}
+	profileGenerateCmd.Flags().StringVarP(&generateParams.walkPath, "walk", "w", "", "Path to the walk to generate the profile from, in the snmprec format")
+	profileGenerateCmd.Flags().StringVarP(&generateParams.outputPath, "output", "o", "", "Write the profile to this file instead of stdout")
+	profileCmd.AddCommand(profileGenerateCmd)
+
+	return profileCmd
+}
+
+// generateProfile prints a starter profile generated from a walk.
+func generateProfile(params *profileGenerateParams, args argsType) error {
+	if len(args) > 0 {
+		return confErrf("unexpected arguments: %s", strings.Join(args, " "))
+	}
+	if params.walkPath == "" {
+		return confErrf("missing flag: --walk")
+	}
+
+	pdus, err := snmprec.ReadFile(params.walkPath)
+	if err != nil {
+		return fmt.Errorf("unable to read walk: %w", err)
+	}
+	profile, err := profilegen.Generate(pdus)
+	if err != nil {
+		return err
+	}
+	content, err := profilegen.Marshal(profile)
+	if err != nil {
+		return err
+	}
+
+	if params.outputPath == "" {
+		fmt.Print(string(content))
+		return nil
+	}
+	if err := os.WriteFile(params.outputPath, content, 0o644); err != nil {
+		return fmt.Errorf("unable to write profile: %w", err)
+	}
+	fmt.Printf("Profile written to %s, with %d metric definitions\n", params.outputPath, len(profile.Metrics))
+	return nil
+}
+
+// testProfile runs the check with a profile against a walk and prints what it collects.
+func testProfile(params *profileTestParams, args argsType, conf config.Component) error {
+	if len(args) > 0 {
+		return confErrf("unexpected arguments: %s", strings.Join(args, " "))
+	}
+	if params.profilePath == "" {
+		return confErrf("missing flag: --profile")
+	}
+	if params.walkPath == "" {
+		return confErrf("missing flag: --walk")
+	}
+
+	result, err := profiletest.Run(params.profilePath, params.walkPath, conf)
+	if err != nil {
+		return err
+	}
+
+	if params.jsonOutput {
+		out, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	fmt.Printf("Profile: %s\n", result.Profile)
+	fmt.Printf("sysObjectID: %s\n", result.SysObjectID)
+	fmt.Printf("\nMetrics (%d):\n", len(result.Metrics))
+	for _, metric := range result.Metrics {
+		fmt.Printf("  %s %s %v [%s]\n", metric.Type, metric.Name, metric.Value, strings.Join(metric.Tags, ", "))
+	}
+	fmt.Printf("\nService checks (%d):\n", len(result.ServiceChecks))
+	for _, serviceCheck := range result.ServiceChecks {
+		fmt.Printf("  %s %s [%s] %s\n", serviceCheck.Name, serviceCheck.Status, strings.Join(serviceCheck.Tags, ", "), serviceCheck.Message)
+	}
+	fmt.Printf("\nMetadata (%d):\n", len(result.Metadata))
+	for _, payload := range result.Metadata {
+		out, err := json.MarshalIndent(payload, "  ", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  %s\n", out)
+	}
+	if len(result.Warnings) > 0 {
+		fmt.Printf("\nWarnings (%d):\n", len(result.Warnings))
+		for _, warning := range result.Warnings {
+			fmt.Printf("  %s\n", warning)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package snmp
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/command"
+	"github.com/DataDog/datadog-agent/comp/core"
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	secretsfx "github.com/DataDog/datadog-agent/comp/core/secrets/fx"
+	"github.com/DataDog/datadog-agent/pkg/snmp/snmpparse"
+	"github.com/DataDog/datadog-agent/pkg/snmp/snmprec"
+	"github.com/DataDog/datadog-agent/pkg/snmp/snmpsim"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+const defaultSimulatorAddress = "127.0.0.1:1161"
+
+// simulateParams holds the flags of `agent snmp simulate`
+type simulateParams struct {
+	address         string
+	communityString string
+	username        string
+	authProtocol    string
+	authKey         string
+	privProtocol    string
+	privKey         string
+	engineID        string
+}
+
+// simulateCommand returns the 'agent snmp simulate' command.
+func simulateCommand(globalParams *command.GlobalParams) *cobra.Command {
+	params := &simulateParams{}
+	simulateCmd := &cobra.Command{
+		Use:   "simulate <device.snmprec>",
+		Short: "Serve a recorded walk as a local SNMP agent.",
+		Long: `Serve a walk recorded in the snmprec format as a local SNMP v2c/v3 agent, to test the SNMP check,
+		autodiscovery and traps configurations end-to-end without the device. The simulator runs until interrupted.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := fxutil.OneShot(simulate,
+				fx.Supply(params),
+				fx.Provide(func() argsType { return args }),
+				fx.Supply(core.BundleParams{
+					ConfigParams: config.NewAgentParams(globalParams.ConfFilePath, config.WithExtraConfFiles(globalParams.ExtraConfFilePath), config.WithFleetPoliciesDirPath(globalParams.FleetPoliciesDirPath)),
+					LogParams:    log.ForOneShot(command.LoggerName, "off", true)}),
+				core.Bundle(),
+				secretsfx.Module(),
+			)
+			if err != nil {
+				var ue configErr
+				if errors.As(err, &ue) {
+					fmt.Println("Usage:", cmd.UseLine())
+				}
+				return err
+			}
+			return nil
+		},
+	}
+	simulateCmd.Flags().StringVar(&params.address, "address", defaultSimulatorAddress, "Set the address to listen on")
+
+	// snmp v1 or v2c specific
+	simulateCmd.Flags().StringVarP(&params.communityString, "community-string", "C", "", "Set the community string accepted by the simulator")
+
+	// snmp v3 specific
+	simulateCmd.Flags().StringVarP(&params.username, "user-name", "u", "", "Set the security name accepted by the simulator")
+	simulateCmd.Flags().VarP(Flag(&snmpparse.AuthOpts, &params.authProtocol), "auth-protocol", "a",
+		fmt.Sprintf("Set authentication protocol (%s)", snmpparse.AuthOpts.OptsStr()))
+	simulateCmd.Flags().StringVarP(&params.authKey, "auth-key", "A", "", "Set authentication protocol pass phrase")
+	simulateCmd.Flags().VarP(Flag(&snmpparse.PrivOpts, &params.privProtocol), "priv-protocol", "x",
+		fmt.Sprintf("Set privacy protocol (%s)", snmpparse.PrivOpts.OptsStr()))
+	simulateCmd.Flags().StringVarP(&params.privKey, "priv-key", "X", "", "Set privacy protocol pass phrase")
+	simulateCmd.Flags().StringVar(&params.engineID, "engine-id", "", "Set the authoritative engine ID, in hex (default random)")
+
+	return simulateCmd
+}
+
+// simulate serves a walk until the command is interrupted.
+func simulate(params *simulateParams, args argsType) error {
+	if len(args) != 1 {
+		return confErrf("expected exactly one argument: the walk file, %d arguments were given", len(args))
+	}
+	if params.communityString == "" && params.username == "" {
+		// default to the community most tools use when none is set
+		params.communityString = "public"
+	}
+	engineID, err := hex.DecodeString(strings.TrimPrefix(params.engineID, "0x"))
+	if err != nil {
+		return confErrf("invalid engine ID %q: %v", params.engineID, err)
+	}
+	authProtocol, _ := snmpparse.AuthOpts.GetVal(params.authProtocol)
+	privProtocol, _ := snmpparse.PrivOpts.GetVal(params.privProtocol)
+
+	pdus, err := snmprec.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("unable to read walk: %w", err)
+	}
+	agent, err := snmpsim.New(pdus, snmpsim.Config{
+		Community:    params.communityString,
+		User:         params.username,
+		AuthProtocol: authProtocol,
+		AuthKey:      params.authKey,
+		PrivProtocol: privProtocol,
+		PrivKey:      params.privKey,
+		EngineID:     string(engineID),
+	})
+	if err != nil {
+		return configErr{err}
+	}
+	if err := agent.Listen(params.address); err != nil {
+		return fmt.Errorf("unable to listen on %s: %w", params.address, err)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		_ = agent.Close()
+	}()
+
+	fmt.Printf("Serving %d OIDs from %s on %s, press Ctrl+C to stop\n", len(pdus), args[0], agent.Addr())
+	return agent.Serve()
+}
@@ -0,0 +1,115 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package snmp
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/command"
+	"github.com/DataDog/datadog-agent/comp/core"
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	secretsfx "github.com/DataDog/datadog-agent/comp/core/secrets/fx"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/oidresolver"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/oidresolver/oidresolverimpl"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+// trapsCommand returns the 'agent snmp traps' command, grouping the tools to debug traps collection.
+func trapsCommand(globalParams *command.GlobalParams) *cobra.Command {
+	trapsCmd := &cobra.Command{
+		Use:   "traps",
+		Short: "SNMP traps tools",
+		Long:  ``,
+	}
+
+	trapsResolveCmd := &cobra.Command{
+		Use:   "resolve <trap-oid> [<variable-oid>...]",
+		Short: "Resolve a trap OID and its variables.",
+		Long: `Resolve a trap OID and the OIDs of its variables the way the traps server does, using the traps databases
+		and the MIB files configured with network_devices.snmp_traps.mibs_path, and print their names, descriptions
+		and enumerations.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := fxutil.OneShot(resolveTrap,
+				fx.Provide(func() argsType { return args }),
+				fx.Supply(core.BundleParams{
+					ConfigParams: config.NewAgentParams(globalParams.ConfFilePath, config.WithExtraConfFiles(globalParams.ExtraConfFilePath), config.WithFleetPoliciesDirPath(globalParams.FleetPoliciesDirPath)),
+					LogParams:    log.ForOneShot(command.LoggerName, "off", true)}),
+				core.Bundle(),
+				secretsfx.Module(),
+				oidresolverimpl.Module(),
+			)
+			if err != nil {
+				var ue configErr
+				if errors.As(err, &ue) {
+					fmt.Println("Usage:", cmd.UseLine())
+				}
+				return err
+			}
+			return nil
+		},
+	}
+	trapsCmd.AddCommand(trapsResolveCmd)
+
+	return trapsCmd
+}
+
+// resolveTrap prints the metadata of a trap and its variables.
+func resolveTrap(args argsType, resolver oidresolver.Component) error {
+	if len(args) == 0 {
+		return confErrf("missing argument: the trap OID")
+	}
+	trapOID := args[0]
+	if !oidresolver.IsValidOID(oidresolver.NormalizeOID(trapOID)) {
+		return confErrf("invalid trap OID %q", trapOID)
+	}
+
+	trap, err := resolver.GetTrapMetadata(trapOID)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Trap %s: %s (%s)\n", oidresolver.NormalizeOID(trapOID), trap.Name, trap.MIBName)
+	if trap.Description != "" {
+		fmt.Printf("  %s\n", trap.Description)
+	}
+
+	for _, variableOID := range args[1:] {
+		fmt.Println()
+		variable, err := resolver.GetVariableMetadata(trapOID, variableOID)
+		if err != nil {
+			fmt.Printf("Variable %s: %s\n", oidresolver.NormalizeOID(variableOID), err)
+			continue
+		}
+		fmt.Printf("Variable %s: %s\n", oidresolver.NormalizeOID(variableOID), variable.Name)
+		if variable.Description != "" {
+			fmt.Printf("  %s\n", variable.Description)
+		}
+		if len(variable.Enumeration) > 0 {
+			fmt.Printf("  Enumeration: %s\n", formatNamedNumbers(variable.Enumeration))
+		}
+		if len(variable.Bits) > 0 {
+			fmt.Printf("  Bits: %s\n", formatNamedNumbers(variable.Bits))
+		}
+	}
+	return nil
+}
+
+// formatNamedNumbers formats enumerations and bits as `name(number)` in the order of their numbers
+func formatNamedNumbers(namedNumbers map[int]string) string {
+	numbers := slices.Sorted(maps.Keys(namedNumbers))
+	parts := make([]string, 0, len(numbers))
+	for _, number := range numbers {
+		parts = append(parts, fmt.Sprintf("%s(%d)", namedNumbers[number], number))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/DataDog/datadog-agent/cmd/agent/command"
 	cmdanalyzelogs "github.com/DataDog/datadog-agent/cmd/agent/subcommands/analyzelogs"
 	cmdcheck "github.com/DataDog/datadog-agent/cmd/agent/subcommands/check"
+	cmdcheckworker "github.com/DataDog/datadog-agent/cmd/agent/subcommands/checkworker"
 	cmdconfig "github.com/DataDog/datadog-agent/cmd/agent/subcommands/config"
 	cmdconfigcheck "github.com/DataDog/datadog-agent/cmd/agent/subcommands/configcheck"
 	cmdcontrolsvc "github.com/DataDog/datadog-agent/cmd/agent/subcommands/controlsvc"
@@ -26,6 +27,7 @@ import (
 	cmdintegrations "github.com/DataDog/datadog-agent/cmd/agent/subcommands/integrations"
 	cmdjmx "github.com/DataDog/datadog-agent/cmd/agent/subcommands/jmx"
 	cmdlaunchgui "github.com/DataDog/datadog-agent/cmd/agent/subcommands/launchgui"
+	cmdnetworkconfigmanagement "github.com/DataDog/datadog-agent/cmd/agent/subcommands/networkconfigmanagement"
 	cmdprocesschecks "github.com/DataDog/datadog-agent/cmd/agent/subcommands/processchecks"
 	cmdremoteconfig "github.com/DataDog/datadog-agent/cmd/agent/subcommands/remoteconfig"
 	cmdrun "github.com/DataDog/datadog-agent/cmd/agent/subcommands/run"
@@ -46,6 +48,7 @@ import (
 func AgentSubcommands() []command.SubcommandFactory {
 	return []command.SubcommandFactory{
 		cmdcheck.Commands,
+		cmdcheckworker.Commands,
 		cmdconfigcheck.Commands,
 		cmdconfig.Commands,
 		cmddiagnose.Commands,
@@ -63,6 +66,7 @@ func AgentSubcommands() []command.SubcommandFactory {
 		cmdrun.Commands,
 		cmdsecret.Commands,
 		cmdsnmp.Commands,
+		cmdnetworkconfigmanagement.Commands,
 		cmdstatus.Commands,
 		cmdstreamlogs.Commands,
 		cmdstreamep.Commands,
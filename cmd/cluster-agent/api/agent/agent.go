@@ -12,6 +12,7 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -59,6 +60,7 @@ func SetupHandlers(r *mux.Router, wmeta workloadmeta.Component, ac autodiscovery
 	r.HandleFunc("/config/{setting}", settings.GetValue).Methods("GET")
 	r.HandleFunc("/config/{setting}", settings.SetValue).Methods("POST")
 	r.HandleFunc("/autoscaler-list", func(w http.ResponseWriter, r *http.Request) { getAutoscalerList(w, r) }).Methods("GET")
+	r.HandleFunc("/autoscaler-simulate", func(w http.ResponseWriter, r *http.Request) { getAutoscalerSimulation(w, r) }).Methods("GET")
 	r.HandleFunc("/local-autoscaling-check", func(w http.ResponseWriter, r *http.Request) { getLocalAutoscalingWorkloadCheck(w, r) }).Methods("GET")
 	r.HandleFunc("/tagger-list", func(w http.ResponseWriter, r *http.Request) { getTaggerList(w, r, taggerComp) }).Methods("GET")
 	r.HandleFunc("/workload-list", func(w http.ResponseWriter, r *http.Request) {
@@ -209,6 +211,36 @@ func getAutoscalerList(w http.ResponseWriter, _ *http.Request) {
 	w.Write(autoscalerListBytes)
 }
 
+func getAutoscalerSimulation(w http.ResponseWriter, r *http.Request) {
+	params := autoscalingWorkload.SimulationParams{
+		Duration: autoscalingWorkload.DefaultSimulationDuration,
+		Step:     autoscalingWorkload.DefaultSimulationStep,
+	}
+	for name, value := range map[string]*time.Duration{"duration": &params.Duration, "step": &params.Step} {
+		if raw := r.URL.Query().Get(name); raw != "" {
+			parsed, err := time.ParseDuration(raw)
+			if err != nil {
+				httputils.SetJSONError(w, log.Errorf("Invalid %s parameter: %v", name, err), 400)
+				return
+			}
+			*value = parsed
+		}
+	}
+
+	simulation, err := autoscalingWorkload.Simulate(r.Context(), r.URL.Query().Get("id"), params)
+	if err != nil {
+		httputils.SetJSONError(w, log.Errorf("Unable to simulate autoscaler: %v", err), 500)
+		return
+	}
+	simulationBytes, err := json.Marshal(simulation)
+	if err != nil {
+		httputils.SetJSONError(w, log.Errorf("Unable to marshal autoscaler simulation response: %v", err), 500)
+		return
+	}
+
+	w.Write(simulationBytes)
+}
+
 //nolint:revive // TODO(CINT) Fix revive linter
 func getTaggerList(w http.ResponseWriter, _ *http.Request, taggerComp tagger.Component) {
 	response := taggerComp.List()
@@ -0,0 +1,29 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+//go:build !windows && kubeapiserver
+
+// Package autoscaler implements 'cluster-agent autoscaler'.
+package autoscaler
+
+import (
+	"github.com/DataDog/datadog-agent/cmd/cluster-agent/command"
+	"github.com/DataDog/datadog-agent/pkg/cli/subcommands/autoscaler"
+
+	"github.com/spf13/cobra"
+)
+
+// Commands returns a slice of subcommands for the 'cluster-agent' command.
+func Commands(globalParams *command.GlobalParams) []*cobra.Command {
+	cmd := autoscaler.MakeCommand(func() autoscaler.GlobalParams {
+		return autoscaler.GlobalParams{
+			ConfFilePath: globalParams.ConfFilePath,
+			ConfigName:   command.ConfigName,
+			LoggerName:   command.LoggerName,
+		}
+	})
+
+	return []*cobra.Command{cmd}
+}
@@ -10,6 +10,7 @@ package subcommands
 
 import (
 	"github.com/DataDog/datadog-agent/cmd/cluster-agent/command"
+	cmdautoscaler "github.com/DataDog/datadog-agent/cmd/cluster-agent/subcommands/autoscaler"
 	cmdautoscalerlist "github.com/DataDog/datadog-agent/cmd/cluster-agent/subcommands/autoscalerlist"
 	cmdcheck "github.com/DataDog/datadog-agent/cmd/cluster-agent/subcommands/check"
 	cmdclusterchecks "github.com/DataDog/datadog-agent/cmd/cluster-agent/subcommands/clusterchecks"
@@ -49,6 +50,7 @@ func ClusterAgentSubcommands() []command.SubcommandFactory {
 		cmdtelemetry.Commands,
 		cmdstatus.Commands,
 		cmdautoscalerlist.Commands,
+		cmdautoscaler.Commands,
 		cmdworkloadlist.Commands,
 		cmdtaggerlist.Commands,
 		cmdcoverage.Commands,
@@ -25,6 +25,10 @@ func main() {
 	var fast bool
 	var analyzers []string
 
+	var format string
+	var output string
+	var vulnsDB string
+
 	var cpuprofile string
 	var closers []io.Closer
 
@@ -34,8 +38,14 @@ func main() {
 	}
 	rootCmd.PersistentFlags().BoolVar(&fast, "fast", false, "use fast mode")
 	rootCmd.PersistentFlags().StringSliceVar(&analyzers, "analyzers", nil, "analyzers to use")
+	rootCmd.PersistentFlags().StringVar(&format, "format", formatCycloneDXJSON, "output format ("+strings.Join(outputFormats, ", ")+")")
+	rootCmd.PersistentFlags().StringVarP(&output, "output", "o", "", "write the SBOM to this file instead of stdout")
+	rootCmd.PersistentFlags().StringVar(&vulnsDB, "vulns", "", "match the SBOM against the OSV vulnerability database stored at this path")
 	rootCmd.PersistentFlags().StringVar(&cpuprofile, "cpuprofile", "", "write cpu profile to file")
 	rootCmd.PersistentPreRunE = func(_ *cobra.Command, _ []string) error {
+		if err := validateFormat(format); err != nil {
+			return err
+		}
 		if cpuprofile != "" {
 			f, err := os.Create(cpuprofile)
 			if err != nil {
@@ -65,11 +75,29 @@ func main() {
 		Args: cobra.MinimumNArgs(1),
 		RunE: func(_ *cobra.Command, args []string) error {
 			path := args[0]
-			return runScanFS(path, analyzers, fast)
+			report, err := runScanFS(path, analyzers, fast)
+			if err != nil {
+				return err
+			}
+			return outputReport(report, format, output, vulnsDB)
 		},
 	}
 	rootCmd.AddCommand(fsCmd)
 
+	var imageArchiveCmd = &cobra.Command{
+		Use:   "image-archive <docker-save.tar|oci-layout-dir>",
+		Short: "Scan an image archive without relying on any container runtime",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			report, err := runScanImageArchive(args[0], analyzers, fast)
+			if err != nil {
+				return err
+			}
+			return outputReport(report, format, output, vulnsDB)
+		},
+	}
+	rootCmd.AddCommand(imageArchiveCmd)
+
 	var dockerCmd = &cobra.Command{
 		Use:  "docker",
 		Args: cobra.MinimumNArgs(1),
@@ -79,7 +107,11 @@ func main() {
 			if err != nil {
 				return err
 			}
-			return runScanDocker(imageMeta, analyzers, fast)
+			report, err := runScanDocker(imageMeta, analyzers, fast)
+			if err != nil {
+				return err
+			}
+			return outputReport(report, format, output, vulnsDB)
 		},
 	}
 	rootCmd.AddCommand(dockerCmd)
@@ -94,7 +126,11 @@ func main() {
 			if err != nil {
 				return err
 			}
-			return runScanContainerd(imageMeta, analyzers, fast, containerdStrategy)
+			report, err := runScanContainerd(imageMeta, analyzers, fast, containerdStrategy)
+			if err != nil {
+				return err
+			}
+			return outputReport(report, format, output, vulnsDB)
 		},
 	}
 	containerdCmd.Flags().StringVar(&containerdStrategy, "strategy", "image", "strategy to use (mount, overlayfs or image)")
@@ -109,7 +145,11 @@ func main() {
 			if err != nil {
 				return err
 			}
-			return runScanCrio(imageMeta, analyzers, fast)
+			report, err := runScanCrio(imageMeta, analyzers, fast)
+			if err != nil {
+				return err
+			}
+			return outputReport(report, format, output, vulnsDB)
 		},
 	}
 	rootCmd.AddCommand(crioCmd)
@@ -0,0 +1,95 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux && trivy && containerd && docker && crio
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/DataDog/agent-payload/v5/cyclonedx_v1_4"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/DataDog/datadog-agent/pkg/sbom"
+	"github.com/DataDog/datadog-agent/pkg/sbom/bomconvert"
+	"github.com/DataDog/datadog-agent/pkg/sbom/osv"
+)
+
+const (
+	formatCycloneDXJSON  = "cyclonedx-json"
+	formatCycloneDXProto = "cyclonedx-proto"
+	formatSPDXJSON       = "spdx-json"
+)
+
+var outputFormats = []string{formatCycloneDXJSON, formatCycloneDXProto, formatSPDXJSON}
+
+func validateFormat(format string) error {
+	if slices.Contains(outputFormats, format) {
+		return nil
+	}
+	return fmt.Errorf("unknown format %q, expected one of %v", format, outputFormats)
+}
+
+func marshalBOM(bom *cyclonedx_v1_4.Bom, format string) ([]byte, error) {
+	switch format {
+	case formatCycloneDXJSON:
+		return protojson.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(bom)
+	case formatCycloneDXProto:
+		return proto.Marshal(bom)
+	case formatSPDXJSON:
+		return json.MarshalIndent(bomconvert.ConvertBOMToSPDX(bom), "", "  ")
+	default:
+		return nil, validateFormat(format)
+	}
+}
+
+// matchVulnerabilities adds the vulnerabilities found in the OSV database to the BOM, and
+// prints a summary on stderr as SPDX documents do not carry vulnerabilities
+func matchVulnerabilities(bom *cyclonedx_v1_4.Bom, vulnsDB string) error {
+	db, err := osv.LoadDatabase(vulnsDB)
+	if err != nil {
+		return err
+	}
+
+	findings := osv.MatchBOM(db, bom)
+	osv.AddToBOM(bom, findings)
+
+	fmt.Fprintf(os.Stderr, "%d vulnerabilities found (database: %d entries)\n", len(findings), db.Len())
+	for _, finding := range findings {
+		fmt.Fprintf(os.Stderr, "%s\t%s\t%s\t%s\t%s\n", finding.VulnerabilityID, finding.Severity, finding.PackageName, finding.PackageVersion, finding.FixedVersion)
+	}
+	return nil
+}
+
+// outputReport writes the report in the given format, either to the output file or to stdout.
+// Vulnerabilities are matched first if a vulnerability database is given.
+func outputReport(report sbom.Report, format string, output string, vulnsDB string) error {
+	bom := report.ToCycloneDX()
+	if vulnsDB != "" {
+		if err := matchVulnerabilities(bom, vulnsDB); err != nil {
+			return fmt.Errorf("error matching vulnerabilities: %w", err)
+		}
+	}
+
+	content, err := marshalBOM(bom, format)
+	if err != nil {
+		return fmt.Errorf("error marshalling report to %s: %w", format, err)
+	}
+
+	if output == "" {
+		_, err = os.Stdout.Write(content)
+		return err
+	}
+
+	if err := os.WriteFile(output, content, 0644); err != nil {
+		return fmt.Errorf("error writing report to %s: %w", output, err)
+	}
+	return nil
+}
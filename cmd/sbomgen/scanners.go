@@ -9,7 +9,6 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
 	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
@@ -21,7 +20,7 @@ import (
 	"github.com/containerd/containerd"
 )
 
-func runScanFS(path string, analyzers []string, fast bool) error {
+func runScanFS(path string, analyzers []string, fast bool) (sbom.Report, error) {
 	collector := trivy.NewCollectorForCLI()
 
 	ctx := context.Background()
@@ -30,18 +29,28 @@ func runScanFS(path string, analyzers []string, fast bool) error {
 		Fast:      fast,
 	}, false)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return outputReport(report)
+	return report, nil
 }
 
-func runScanDocker(imageMeta *workloadmeta.ContainerImageMetadata, analyzers []string, fast bool) error {
+func runScanImageArchive(path string, analyzers []string, fast bool) (sbom.Report, error) {
+	collector := trivy.NewCollectorForCLI()
+
+	ctx := context.Background()
+	return collector.ScanImageArchive(ctx, path, sbom.ScanOptions{
+		Analyzers: analyzers,
+		Fast:      fast,
+	})
+}
+
+func runScanDocker(imageMeta *workloadmeta.ContainerImageMetadata, analyzers []string, fast bool) (sbom.Report, error) {
 	collector := trivy.NewCollectorForCLI()
 
 	cl, err := docker.GetDockerUtil()
 	if err != nil {
-		return fmt.Errorf("error creating docker client: %w", err)
+		return nil, fmt.Errorf("error creating docker client: %w", err)
 	}
 	dockerClient := cl.RawClient()
 
@@ -56,23 +65,23 @@ func runScanDocker(imageMeta *workloadmeta.ContainerImageMetadata, analyzers []s
 		},
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return outputReport(report)
+	return report, nil
 }
 
-func runScanContainerd(imageMeta *workloadmeta.ContainerImageMetadata, analyzers []string, fast bool, strategy string) error {
+func runScanContainerd(imageMeta *workloadmeta.ContainerImageMetadata, analyzers []string, fast bool, strategy string) (sbom.Report, error) {
 	collector := trivy.NewCollectorForCLI()
 
 	containerdClient, err := containerdutil.NewContainerdUtil()
 	if err != nil {
-		return fmt.Errorf("error creating containerd client: %w", err)
+		return nil, fmt.Errorf("error creating containerd client: %w", err)
 	}
 
 	image, err := containerdClient.Image(imageMeta.Namespace, imageMeta.Name)
 	if err != nil {
-		return fmt.Errorf("error getting image %s/%s: %w", imageMeta.Namespace, imageMeta.Name, err)
+		return nil, fmt.Errorf("error getting image %s/%s: %w", imageMeta.Namespace, imageMeta.Name, err)
 	}
 
 	var report sbom.Report
@@ -85,7 +94,7 @@ func runScanContainerd(imageMeta *workloadmeta.ContainerImageMetadata, analyzers
 	case "image":
 		scanner = collector.ScanContainerdImage
 	default:
-		return fmt.Errorf("unknown strategy: %s", strategy)
+		return nil, fmt.Errorf("unknown strategy: %s", strategy)
 	}
 
 	ctx := context.Background()
@@ -94,18 +103,18 @@ func runScanContainerd(imageMeta *workloadmeta.ContainerImageMetadata, analyzers
 		Fast:      fast,
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return outputReport(report)
+	return report, nil
 }
 
-func runScanCrio(imageMeta *workloadmeta.ContainerImageMetadata, analyzers []string, fast bool) error {
+func runScanCrio(imageMeta *workloadmeta.ContainerImageMetadata, analyzers []string, fast bool) (sbom.Report, error) {
 	collector := trivy.NewCollectorForCLI()
 
 	crioClient, err := crio.NewCRIOClient()
 	if err != nil {
-		return fmt.Errorf("error creating CRI-O client: %w", err)
+		return nil, fmt.Errorf("error creating CRI-O client: %w", err)
 	}
 
 	ctx := context.Background()
@@ -119,19 +128,8 @@ func runScanCrio(imageMeta *workloadmeta.ContainerImageMetadata, analyzers []str
 		},
 	)
 	if err != nil {
-		return err
-	}
-
-	return outputReport(report)
-}
-
-func outputReport(report sbom.Report) error {
-	bom := report.ToCycloneDX()
-	bomJSON, err := json.MarshalIndent(bom, "", "  ")
-	if err != nil {
-		return err
+		return nil, err
 	}
 
-	fmt.Printf("sbom: %+v\n", string(bomJSON))
-	return nil
+	return report, nil
 }
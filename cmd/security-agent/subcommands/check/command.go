@@ -112,27 +112,14 @@ func commandsWrapped(bundleParamsFactory func() core.BundleParams) []*cobra.Comm
 
 // RunCheck runs a check
 func RunCheck(log log.Component, config config.Component, _ secrets.Component, statsdComp statsd.Component, checkArgs *CliParams, compression logscompression.Component, ipc ipc.Component) error {
-	var hname string
-	var err error
-	if flavor.GetFlavor() == flavor.ClusterAgent {
-		hname, err = hostname.Get(context.TODO())
-	} else {
-		hname, err = hostnameutils.GetHostnameWithContextAndFallback(context.Background(), ipc)
-	}
+	hname, err := getHostname(ipc)
 	if err != nil {
 		return err
 	}
 
-	var statsdClient ddgostatsd.ClientInterface
-	metricsEnabled := config.GetBool("compliance_config.metrics.enabled")
-	if metricsEnabled {
-		cl, err := statsdComp.Get()
-		if err != nil {
-			log.Warnf("Error creating statsd Client: %s", err)
-		} else {
-			statsdClient = cl
-			defer cl.Flush()
-		}
+	statsdClient := newStatsdClient(log, config, statsdComp)
+	if statsdClient != nil {
+		defer statsdClient.Flush()
 	}
 
 	if len(checkArgs.args) == 1 && checkArgs.args[0] == "k8sconfig" {
@@ -142,48 +129,12 @@ func RunCheck(log log.Component, config config.Component, _ secrets.Component, s
 		return nil
 	}
 
-	var resolver compliance.Resolver
-	if checkArgs.overrideRegoInput != "" {
-		resolver = newFakeResolver(checkArgs.overrideRegoInput)
-	} else {
-		resolver = compliance.NewResolver(context.Background(), compliance.ResolverOptions{
-			Hostname:           hname,
-			HostRoot:           os.Getenv("HOST_ROOT"),
-			DockerProvider:     compliance.DefaultDockerProvider,
-			LinuxAuditProvider: compliance.DefaultLinuxAuditProvider,
-			KubernetesProvider: complianceKubernetesProvider,
-			StatsdClient:       statsdClient,
-		})
-	}
+	resolver := newResolver(hname, statsdClient, checkArgs.overrideRegoInput)
 	defer resolver.Close()
 
-	configDir := config.GetString("compliance_config.dir")
-	var benchDir, benchGlob string
-	var ruleFilter compliance.RuleFilter
-	if checkArgs.file != "" {
-		benchDir, benchGlob = filepath.Dir(checkArgs.file), filepath.Base(checkArgs.file)
-	} else if checkArgs.framework != "" {
-		benchDir, benchGlob = configDir, fmt.Sprintf("%s.yaml", checkArgs.framework)
-	} else {
-		ruleFilter = compliance.MakeDefaultRuleFilter(ipc)
-		benchDir, benchGlob = configDir, "*.yaml"
-	}
-
-	log.Infof("Loading compliance rules from %s", benchDir)
-	benchmarks, err := compliance.LoadBenchmarks(benchDir, benchGlob, func(r *compliance.Rule) bool {
-		if ruleFilter != nil && !ruleFilter(r) {
-			return false
-		}
-		if len(checkArgs.args) > 0 {
-			return r.ID == checkArgs.args[0]
-		}
-		return true
-	})
+	benchmarks, err := loadBenchmarks(log, config, ipc, checkArgs.file, checkArgs.framework, checkArgs.args)
 	if err != nil {
-		return fmt.Errorf("could not load benchmark files %q: %w", filepath.Join(benchDir, benchGlob), err)
-	}
-	if len(benchmarks) == 0 {
-		return fmt.Errorf("could not find any benchmark in %q", filepath.Join(benchDir, benchGlob))
+		return err
 	}
 
 	events := make([]*compliance.CheckEvent, 0)
@@ -227,6 +178,76 @@ func RunCheck(log log.Component, config config.Component, _ secrets.Component, s
 	return nil
 }
 
+func getHostname(ipc ipc.Component) (string, error) {
+	if flavor.GetFlavor() == flavor.ClusterAgent {
+		return hostname.Get(context.TODO())
+	}
+	return hostnameutils.GetHostnameWithContextAndFallback(context.Background(), ipc)
+}
+
+// newStatsdClient returns a statsd client if the compliance metrics are enabled
+func newStatsdClient(log log.Component, config config.Component, statsdComp statsd.Component) ddgostatsd.ClientInterface {
+	if !config.GetBool("compliance_config.metrics.enabled") {
+		return nil
+	}
+
+	cl, err := statsdComp.Get()
+	if err != nil {
+		log.Warnf("Error creating statsd Client: %s", err)
+		return nil
+	}
+	return cl
+}
+
+func newResolver(hname string, statsdClient ddgostatsd.ClientInterface, overrideRegoInput string) compliance.Resolver {
+	if overrideRegoInput != "" {
+		return newFakeResolver(overrideRegoInput)
+	}
+	return compliance.NewResolver(context.Background(), compliance.ResolverOptions{
+		Hostname:           hname,
+		HostRoot:           os.Getenv("HOST_ROOT"),
+		DockerProvider:     compliance.DefaultDockerProvider,
+		LinuxAuditProvider: compliance.DefaultLinuxAuditProvider,
+		KubernetesProvider: complianceKubernetesProvider,
+		StatsdClient:       statsdClient,
+	})
+}
+
+// loadBenchmarks loads the benchmarks from the given file or framework, or the
+// default benchmarks of the configuration directory. If a rule ID is given,
+// only this rule is loaded.
+func loadBenchmarks(log log.Component, config config.Component, ipc ipc.Component, file, framework string, args []string) ([]*compliance.Benchmark, error) {
+	configDir := config.GetString("compliance_config.dir")
+	var benchDir, benchGlob string
+	var ruleFilter compliance.RuleFilter
+	if file != "" {
+		benchDir, benchGlob = filepath.Dir(file), filepath.Base(file)
+	} else if framework != "" {
+		benchDir, benchGlob = configDir, fmt.Sprintf("%s.yaml", framework)
+	} else {
+		ruleFilter = compliance.MakeDefaultRuleFilter(ipc)
+		benchDir, benchGlob = configDir, "*.yaml"
+	}
+
+	log.Infof("Loading compliance rules from %s", benchDir)
+	benchmarks, err := compliance.LoadBenchmarks(benchDir, benchGlob, func(r *compliance.Rule) bool {
+		if ruleFilter != nil && !ruleFilter(r) {
+			return false
+		}
+		if len(args) > 0 {
+			return r.ID == args[0]
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not load benchmark files %q: %w", filepath.Join(benchDir, benchGlob), err)
+	}
+	if len(benchmarks) == 0 {
+		return nil, fmt.Errorf("could not find any benchmark in %q", filepath.Join(benchDir, benchGlob))
+	}
+	return benchmarks, nil
+}
+
 func dumpComplianceEvents(reportFile string, events []*compliance.CheckEvent) error {
 	eventsMap := make(map[string][]*compliance.CheckEvent)
 	for _, event := range events {
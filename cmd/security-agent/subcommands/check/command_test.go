@@ -58,3 +58,32 @@ func TestCommands(t *testing.T) {
 		//)
 	}
 }
+
+func TestReportCommands(t *testing.T) {
+	tests := []struct {
+		name     string
+		cliInput []string
+		check    func(cliParams *ReportCliParams, params core.BundleParams)
+	}{
+		{
+			name:     "report",
+			cliInput: []string{"report", "--format", "arf", "-o", "report.xml", "cis-docker-1.2.0-2.1"},
+			check: func(cliParams *ReportCliParams, params core.BundleParams) {
+				require.Equal(t, command.LoggerName, params.LoggerName(), "logger name not matching")
+				require.Equal(t, "info", params.LogLevelFn(nil), "params.LogLevelFn not matching")
+				require.Equal(t, "arf", cliParams.format)
+				require.Equal(t, "report.xml", cliParams.output)
+				require.Equal(t, []string{"cis-docker-1.2.0-2.1"}, cliParams.args)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		fxutil.TestOneShotSubcommand(t,
+			SecurityAgentReportCommands(&command.GlobalParams{}),
+			test.cliInput,
+			RunReport,
+			test.check,
+		)
+	}
+}
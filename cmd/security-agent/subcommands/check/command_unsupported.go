@@ -24,3 +24,8 @@ func SecurityAgentCommands(_ *command.GlobalParams) []*cobra.Command {
 func ClusterAgentCommands(_ core.BundleParams) []*cobra.Command {
 	return nil
 }
+
+// SecurityAgentReportCommands returns security agent report commands
+func SecurityAgentReportCommands(_ *command.GlobalParams) []*cobra.Command {
+	return nil
+}
@@ -0,0 +1,144 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !windows
+
+package check
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/cmd/security-agent/command"
+	"github.com/DataDog/datadog-agent/comp/core"
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	ipc "github.com/DataDog/datadog-agent/comp/core/ipc/def"
+	ipcfx "github.com/DataDog/datadog-agent/comp/core/ipc/fx"
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	secrets "github.com/DataDog/datadog-agent/comp/core/secrets/def"
+	secretsfx "github.com/DataDog/datadog-agent/comp/core/secrets/fx"
+	"github.com/DataDog/datadog-agent/comp/core/sysprobeconfig/sysprobeconfigimpl"
+	"github.com/DataDog/datadog-agent/comp/dogstatsd/statsd"
+	"github.com/DataDog/datadog-agent/pkg/compliance/report"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+// ReportCliParams needs to be exported because the compliance subcommand is tightly coupled to this subcommand and tests need to be able to access this type.
+type ReportCliParams struct {
+	*command.GlobalParams
+
+	args []string
+
+	framework         string
+	file              string
+	format            string
+	output            string
+	verbose           bool
+	overrideRegoInput string
+}
+
+// SecurityAgentReportCommands returns the security agent report commands
+func SecurityAgentReportCommands(globalParams *command.GlobalParams) []*cobra.Command {
+	reportArgs := &ReportCliParams{
+		GlobalParams: globalParams,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "report [rule-id]",
+		Short: "Run compliance benchmarks and write a standalone report of their results",
+		Long: `Run compliance benchmarks and write a standalone report of their results, along with the inputs each rule
+was evaluated against. The report can be written as JSON, as XCCDF results or as an ARF collection for
+OpenSCAP-compatible tools, or as an HTML summary.`,
+		Args: cobra.MaximumNArgs(1),
+		PreRunE: func(_ *cobra.Command, _ []string) error {
+			if !slices.Contains(report.Formats, report.Format(reportArgs.format)) {
+				return fmt.Errorf("unsupported report format %q, expected one of %v", reportArgs.format, report.Formats)
+			}
+			return nil
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			reportArgs.args = args
+
+			logLevel := "info"
+			if reportArgs.verbose {
+				logLevel = "trace"
+			}
+
+			return fxutil.OneShot(RunReport,
+				fx.Supply(reportArgs),
+				fx.Supply(core.BundleParams{
+					ConfigParams:         config.NewSecurityAgentParams(globalParams.ConfigFilePaths, config.WithFleetPoliciesDirPath(globalParams.FleetPoliciesDirPath)),
+					SysprobeConfigParams: sysprobeconfigimpl.NewParams(sysprobeconfigimpl.WithSysProbeConfFilePath(globalParams.SysProbeConfFilePath), sysprobeconfigimpl.WithFleetPoliciesDirPath(globalParams.FleetPoliciesDirPath)),
+					LogParams:            log.ForOneShot(command.LoggerName, logLevel, true),
+				}),
+				core.Bundle(),
+				secretsfx.Module(),
+				statsd.Module(),
+				ipcfx.ModuleReadOnly(),
+			)
+		},
+	}
+
+	cmd.Flags().StringVarP(&reportArgs.framework, "framework", "", "", "Framework to run the checks from")
+	cmd.Flags().StringVarP(&reportArgs.file, "file", "f", "", "Compliance suite file to read rules from")
+	cmd.Flags().StringVarP(&reportArgs.format, "format", "", string(report.FormatJSON), fmt.Sprintf("Report format, one of %v", report.Formats))
+	cmd.Flags().StringVarP(&reportArgs.output, "output", "o", "", "Path to the report file, defaults to the standard output")
+	cmd.Flags().BoolVarP(&reportArgs.verbose, "verbose", "v", false, "Include verbose details")
+	cmd.Flags().StringVarP(&reportArgs.overrideRegoInput, "override-rego-input", "", "", "Rego input to use when running rego checks")
+
+	return []*cobra.Command{cmd}
+}
+
+// RunReport runs compliance benchmarks and writes a report of their results
+func RunReport(log log.Component, config config.Component, _ secrets.Component, statsdComp statsd.Component, reportArgs *ReportCliParams, ipc ipc.Component) error {
+	hname, err := getHostname(ipc)
+	if err != nil {
+		return err
+	}
+
+	statsdClient := newStatsdClient(log, config, statsdComp)
+	if statsdClient != nil {
+		defer statsdClient.Flush()
+	}
+
+	resolver := newResolver(hname, statsdClient, reportArgs.overrideRegoInput)
+	defer resolver.Close()
+
+	benchmarks, err := loadBenchmarks(log, config, ipc, reportArgs.file, reportArgs.framework, reportArgs.args)
+	if err != nil {
+		return err
+	}
+
+	complianceReport := report.Run(context.Background(), benchmarks, report.Options{
+		Hostname:     hname,
+		Resolver:     resolver,
+		StatsdClient: statsdClient,
+	})
+
+	var w io.Writer = os.Stdout
+	if reportArgs.output != "" {
+		f, err := os.OpenFile(reportArgs.output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+		if err != nil {
+			return fmt.Errorf("could not create report file %q: %w", reportArgs.output, err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := report.Write(w, complianceReport, report.Format(reportArgs.format)); err != nil {
+		return fmt.Errorf("could not write report: %w", err)
+	}
+
+	if reportArgs.output != "" {
+		log.Infof("Compliance report written to %s", reportArgs.output)
+	}
+	return nil
+}
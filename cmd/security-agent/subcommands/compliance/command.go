@@ -39,6 +39,7 @@ func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 	}
 
 	complianceCmd.AddCommand(check.SecurityAgentCommands(globalParams)...)
+	complianceCmd.AddCommand(check.SecurityAgentReportCommands(globalParams)...)
 	complianceCmd.AddCommand(complianceLoadCommand(globalParams))
 
 	return []*cobra.Command{complianceCmd}
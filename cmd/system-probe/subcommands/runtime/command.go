@@ -57,6 +57,7 @@ func commonPolicyCommands(globalParams *command.GlobalParams) []*cobra.Command {
 
 	commonPolicyCmd.AddCommand(evalCommands(globalParams)...)
 	commonPolicyCmd.AddCommand(commonCheckPoliciesCommands(globalParams)...)
+	commonPolicyCmd.AddCommand(testPoliciesCommands(globalParams)...)
 	commonPolicyCmd.AddCommand(commonReloadPoliciesCommands(globalParams)...)
 	commonPolicyCmd.AddCommand(downloadPolicyCommands(globalParams)...)
 
@@ -135,6 +136,59 @@ func commonCheckPoliciesCommands(globalParams *command.GlobalParams) []*cobra.Co
 	return []*cobra.Command{commonCheckPoliciesCmd}
 }
 
+type testPoliciesCliParams struct {
+	*command.GlobalParams
+
+	dir          string
+	testsDir     string
+	windowsModel bool
+	json         bool
+}
+
+func testPoliciesCommands(globalParams *command.GlobalParams) []*cobra.Command {
+	cliParams := &testPoliciesCliParams{
+		GlobalParams: globalParams,
+	}
+
+	testPoliciesCmd := &cobra.Command{
+		Use:   "test",
+		Short: "Run the policy test suites and report the rule and field coverage",
+		Long: `Run the policy test suites (*.test.yaml files) against the policies and report the rule and field coverage.
+
+A test suite lists events, along with the rules they are expected to match or not to match:
+
+  tests:
+    - name: curl downloads a script
+      event:
+        type: exec
+        values:
+          exec.file.name: curl
+        variables:
+          allowed_downloaders: ["wget"]
+      match: [suspicious_download]
+      no_match: [allowed_download]`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return fxutil.OneShot(testPolicies,
+				fx.Supply(cliParams),
+				fx.Supply(core.BundleParams{
+					ConfigParams: config.NewAgentParams(""),
+					LogParams:    log.ForOneShot("SYS-PROBE", "off", false)}),
+				core.Bundle(),
+				secretsnoopfx.Module(),
+			)
+		},
+	}
+
+	testPoliciesCmd.Flags().StringVar(&cliParams.dir, "policies-dir", pkgconfigsetup.DefaultRuntimePoliciesDir, "Path to policies directory")
+	testPoliciesCmd.Flags().StringVar(&cliParams.testsDir, "tests-dir", "", "Path to the test suites directory, defaults to the policies directory")
+	testPoliciesCmd.Flags().BoolVar(&cliParams.json, "json", false, "Output the report in JSON")
+	if runtime.GOOS == "linux" {
+		testPoliciesCmd.Flags().BoolVar(&cliParams.windowsModel, "windows-model", false, "Evaluate policies using the Windows model")
+	}
+
+	return []*cobra.Command{testPoliciesCmd}
+}
+
 func commonReloadPoliciesCommands(_ *command.GlobalParams) []*cobra.Command {
 	commonReloadPoliciesCmd := &cobra.Command{
 		Use:   "reload",
@@ -371,6 +425,24 @@ func checkPolicies(_ log.Component, _ config.Component, args *checkPoliciesCliPa
 	}, os.Stdout)
 }
 
+func testPolicies(_ log.Component, _ config.Component, args *testPoliciesCliParams) error {
+	report, err := clihelpers.RunPolicyTests(clihelpers.RunPolicyTestsParams{
+		Dir:             args.dir,
+		TestsDir:        args.testsDir,
+		UseWindowsModel: args.windowsModel,
+		JSON:            args.json,
+	}, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	if !report.Succeeded {
+		return fmt.Errorf("%d policy tests failed", report.Failed())
+	}
+
+	return nil
+}
+
 func checkPoliciesLoaded(client secagent.SecurityModuleCmdClientWrapper, writer io.Writer) error {
 	output, err := client.GetRuleSetReport()
 	if err != nil {
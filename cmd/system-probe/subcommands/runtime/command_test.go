@@ -197,6 +197,14 @@ func TestCheckPoliciesCommand(t *testing.T) {
 		func() {})
 }
 
+func TestTestPoliciesCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"runtime", "policy", "test", "--tests-dir=tests", "--json"},
+		testPolicies,
+		func() {})
+}
+
 func TestReloadRuntimePoliciesCommand(t *testing.T) {
 	fxutil.TestOneShotSubcommand(t,
 		Commands(&command.GlobalParams{}),
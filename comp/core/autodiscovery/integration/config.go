@@ -131,13 +131,17 @@ type MatchingProgram interface {
 
 // CommonInstanceConfig holds the reserved fields for the yaml instance data
 type CommonInstanceConfig struct {
-	MinCollectionInterval int      `yaml:"min_collection_interval"`
-	EmptyDefaultHostname  bool     `yaml:"empty_default_hostname"`
-	Tags                  []string `yaml:"tags"`
-	Service               string   `yaml:"service"`
-	Name                  string   `yaml:"name"`
-	Namespace             string   `yaml:"namespace"`
-	NoIndex               bool     `yaml:"no_index"`
+	MinCollectionInterval  int      `yaml:"min_collection_interval"`
+	EmptyDefaultHostname   bool     `yaml:"empty_default_hostname"`
+	Tags                   []string `yaml:"tags"`
+	Service                string   `yaml:"service"`
+	Name                   string   `yaml:"name"`
+	Namespace              string   `yaml:"namespace"`
+	NoIndex                bool     `yaml:"no_index"`
+	RunTimeout             int      `yaml:"run_timeout"`
+	Isolation              string   `yaml:"isolation"`
+	IsolationMemoryLimitMB int      `yaml:"isolation_memory_limit_mb"`
+	IsolationCPULimit      float64  `yaml:"isolation_cpu_limit"`
 }
 
 // CommonGlobalConfig holds the reserved fields for the yaml init_config data
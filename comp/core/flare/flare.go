@@ -6,6 +6,7 @@
 package flare
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -41,6 +42,7 @@ var fbFactory flareBuilderFactory = helpers.NewFlareBuilder
 type dependencies struct {
 	fx.In
 
+	Lc        fx.Lifecycle
 	Log       log.Component
 	Config    config.Component
 	Params    Params
@@ -62,6 +64,8 @@ type flare struct {
 	config    config.Component
 	params    Params
 	providers []*types.FlareFiller
+	// ctx is cancelled when the agent stops, interrupting the flares being sent
+	ctx context.Context
 }
 
 func newFlare(deps dependencies) provides {
@@ -71,6 +75,12 @@ func newFlare(deps dependencies) provides {
 		params:    deps.Params,
 		providers: fxutil.GetAndFilterGroup(deps.Providers),
 	}
+	var cancel context.CancelFunc
+	f.ctx, cancel = context.WithCancel(context.Background())
+	deps.Lc.Append(fx.Hook{OnStop: func(context.Context) error {
+		cancel()
+		return nil
+	}})
 
 	// Adding legacy and internal providers. Registering then as Provider through FX create cycle dependencies.
 	//
@@ -189,11 +199,11 @@ func (f *flare) createAndReturnFlarePath(w http.ResponseWriter, r *http.Request)
 	w.Write([]byte(filePath))
 }
 
-// Send sends a flare archive to Datadog
+// Send sends a flare archive to Datadog, or to the destination configured by `flare.destination`
 func (f *flare) Send(flarePath string, caseID string, email string, source helpers.FlareSource) (string, error) {
 	// For now this is a wrapper around helpers.SendFlare since some code hasn't migrated to FX yet.
 	// The `source` is the reason why the flare was created, for now it's either local or remote-config
-	return helpers.SendTo(f.config, flarePath, caseID, email, f.config.GetString("api_key"), utils.GetInfraEndpoint(f.config), source)
+	return helpers.SendToWithContext(f.ctx, f.config, flarePath, caseID, email, f.config.GetString("api_key"), utils.GetInfraEndpoint(f.config), source)
 }
 
 // Create creates a new flare and returns the path to the final archive file.
@@ -239,6 +249,9 @@ func (f *flare) create(flareArgs types.FlareArgs, providerTimeout time.Duration,
 		fb.AddFile("diagnose.log", diagnoseResult)
 	}
 
+	// Record where the flare is sent and how it is encrypted
+	fb.AddFileFromFunc("flare_metadata.json", func() ([]byte, error) { return helpers.GetFlareMetadata(f.config) }) //nolint:errcheck
+
 	f.runProviders(fb, providerTimeout)
 
 	return fb.Save()
@@ -0,0 +1,315 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package helpers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+
+	pkgconfigmodel "github.com/DataDog/datadog-agent/pkg/config/model"
+	httputils "github.com/DataDog/datadog-agent/pkg/util/http"
+)
+
+const (
+	// DestinationDatadog sends flares to Datadog support
+	DestinationDatadog = "datadog"
+	// DestinationLocal copies flares to a local directory
+	DestinationLocal = "local"
+	// DestinationHTTP uploads flares with an HTTP PUT, usually to a presigned URL
+	DestinationHTTP = "http"
+	// DestinationS3 uploads flares to a bucket of an S3 compatible storage
+	DestinationS3 = "s3"
+)
+
+// flareDestination stores flares outside of Datadog
+type flareDestination interface {
+	// upload stores a flare archive and returns a message telling where it was stored
+	upload(ctx context.Context, archivePath string) (string, error)
+}
+
+// flareMetadata records where and how a flare is sent, it is added to the flare archive
+type flareMetadata struct {
+	Destination string `json:"destination"`
+	Encryption  string `json:"encryption"`
+	// EncryptionKeys are the fingerprints of the keys the flare is encrypted to
+	EncryptionKeys []string `json:"encryption_keys,omitempty"`
+	// EncryptionKeysError explains why the fingerprints of the keys are missing
+	EncryptionKeysError string `json:"encryption_keys_error,omitempty"`
+}
+
+// getDestination returns the destination configured for flares
+func getDestination(cfg pkgconfigmodel.Reader) string {
+	destination := cfg.GetString("flare.destination.type")
+	if destination == "" {
+		return DestinationDatadog
+	}
+	return destination
+}
+
+// GetFlareMetadata returns the destination and the encryption used to send flares, as JSON. A key file that can't
+// be read is reported in the metadata rather than failing the flare, sending it reports the actual error.
+func GetFlareMetadata(cfg pkgconfigmodel.Reader) ([]byte, error) {
+	metadata := flareMetadata{
+		Destination: getDestination(cfg),
+		Encryption:  cfg.GetString("flare.encryption.type"),
+	}
+	if metadata.Encryption == "" {
+		metadata.Encryption = EncryptionNone
+	}
+	if metadata.Encryption == EncryptionOpenPGP {
+		keys, err := readOpenPGPKeys(cfg.GetString("flare.encryption.public_key_file"))
+		if err != nil {
+			metadata.EncryptionKeysError = err.Error()
+		}
+		for _, key := range keys {
+			metadata.EncryptionKeys = append(metadata.EncryptionKeys, strings.ToUpper(hex.EncodeToString(key.PrimaryKey.Fingerprint)))
+		}
+	}
+	return json.MarshalIndent(metadata, "", "  ")
+}
+
+// newFlareDestination creates the destination configured by `flare.destination`
+func newFlareDestination(cfg pkgconfigmodel.Reader, destination string) (flareDestination, error) {
+	client := &http.Client{
+		Transport: httputils.CreateHTTPTransport(cfg),
+		Timeout:   httpTimeout,
+	}
+
+	switch destination {
+	case DestinationLocal:
+		directory := cfg.GetString("flare.destination.local.directory")
+		if directory == "" {
+			return nil, errors.New("flare.destination.local.directory is required to store flares in a local directory")
+		}
+		return &localDestination{directory: directory}, nil
+	case DestinationHTTP:
+		uploadURL := cfg.GetString("flare.destination.http.url")
+		if uploadURL == "" {
+			return nil, errors.New("flare.destination.http.url is required to upload flares over HTTP")
+		}
+		return &httpDestination{url: uploadURL, client: client}, nil
+	case DestinationS3:
+		return newS3Destination(cfg, client)
+	default:
+		return nil, fmt.Errorf("unsupported flare destination %q, supported destinations are %q, %q, %q and %q", destination, DestinationDatadog, DestinationLocal, DestinationHTTP, DestinationS3)
+	}
+}
+
+// localDestination copies flares to a directory, e.g. one synchronized with an internal storage
+type localDestination struct {
+	directory string
+}
+
+func (d *localDestination) upload(_ context.Context, archivePath string) (string, error) {
+	if err := os.MkdirAll(d.directory, 0700); err != nil {
+		return "", err
+	}
+
+	archive, err := os.Open(archivePath)
+	if err != nil {
+		return "", err
+	}
+	defer archive.Close()
+
+	destPath := filepath.Join(d.directory, filepath.Base(archivePath))
+	dest, err := os.OpenFile(destPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return "", err
+	}
+	defer dest.Close()
+
+	if _, err := io.Copy(dest, archive); err != nil {
+		return "", fmt.Errorf("could not copy flare to %s: %w", destPath, err)
+	}
+	if err := dest.Close(); err != nil {
+		return "", err
+	}
+	return "Your flare was stored at " + destPath, nil
+}
+
+// httpDestination uploads flares with an HTTP PUT, the URL usually being presigned by a storage service
+type httpDestination struct {
+	url    string
+	client *http.Client
+}
+
+func (d *httpDestination) upload(ctx context.Context, archivePath string) (string, error) {
+	request, err := newUploadRequest(ctx, d.url, archivePath)
+	if err != nil {
+		return "", err
+	}
+	if err := doUploadRequest(d.client, request); err != nil {
+		return "", err
+	}
+	return "Your flare was uploaded to " + redactURL(d.url), nil
+}
+
+// s3Destination uploads flares to a bucket of an S3 compatible storage, signing the requests with AWS Signature V4
+type s3Destination struct {
+	endpoint    string
+	bucket      string
+	region      string
+	prefix      string
+	credentials aws.Credentials
+	client      *http.Client
+	signer      *v4.Signer
+}
+
+func newS3Destination(cfg pkgconfigmodel.Reader, client *http.Client) (*s3Destination, error) {
+	d := &s3Destination{
+		endpoint: strings.TrimSuffix(cfg.GetString("flare.destination.s3.endpoint"), "/"),
+		bucket:   cfg.GetString("flare.destination.s3.bucket"),
+		region:   cfg.GetString("flare.destination.s3.region"),
+		prefix:   cfg.GetString("flare.destination.s3.prefix"),
+		credentials: aws.Credentials{
+			AccessKeyID:     cfg.GetString("flare.destination.s3.access_key_id"),
+			SecretAccessKey: cfg.GetString("flare.destination.s3.secret_access_key"),
+			SessionToken:    cfg.GetString("flare.destination.s3.session_token"),
+		},
+		client: client,
+		// S3 expects the object keys to be escaped only once
+		signer: v4.NewSigner(func(o *v4.SignerOptions) { o.DisableURIPathEscaping = true }),
+	}
+	if d.bucket == "" {
+		return nil, errors.New("flare.destination.s3.bucket is required to upload flares to S3")
+	}
+	if d.credentials.AccessKeyID == "" || d.credentials.SecretAccessKey == "" {
+		return nil, errors.New("flare.destination.s3.access_key_id and flare.destination.s3.secret_access_key are required to upload flares to S3")
+	}
+	if d.region == "" {
+		d.region = "us-east-1"
+	}
+	if d.endpoint == "" {
+		d.endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", d.region)
+	}
+	return d, nil
+}
+
+func (d *s3Destination) upload(ctx context.Context, archivePath string) (string, error) {
+	payloadHash, err := hashFile(archivePath)
+	if err != nil {
+		return "", err
+	}
+
+	// Path-style requests are supported by all the S3 compatible storages
+	key := d.prefix + filepath.Base(archivePath)
+	request, err := newUploadRequest(ctx, d.endpoint+"/"+d.bucket+"/"+escapeS3Key(key), archivePath)
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	err = d.signer.SignHTTP(request.Context(), d.credentials, request, payloadHash, "s3", d.region, time.Now())
+	if err != nil {
+		request.Body.Close()
+		return "", fmt.Errorf("could not sign S3 request: %w", err)
+	}
+
+	if err := doUploadRequest(d.client, request); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Your flare was uploaded to s3://%s/%s", d.bucket, key), nil
+}
+
+// escapeS3Key escapes each segment of an object key as expected by S3 in the canonical request, since the signer
+// uses the path of the request as is: every byte but the unreserved characters is percent-encoded.
+func escapeS3Key(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		var escaped strings.Builder
+		for j := 0; j < len(segment); j++ {
+			c := segment[j]
+			if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') || c == '-' || c == '.' || c == '_' || c == '~' {
+				escaped.WriteByte(c)
+			} else {
+				fmt.Fprintf(&escaped, "%%%02X", c)
+			}
+		}
+		segments[i] = escaped.String()
+	}
+	return strings.Join(segments, "/")
+}
+
+// newUploadRequest creates a PUT request with the content of an archive
+func newUploadRequest(ctx context.Context, uploadURL string, archivePath string) (*http.Request, error) {
+	archive, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	info, err := archive.Stat()
+	if err != nil {
+		archive.Close()
+		return nil, err
+	}
+
+	// the request closes the archive once sent
+	request, err := http.NewRequestWithContext(ctx, http.MethodPut, uploadURL, archive)
+	if err != nil {
+		archive.Close()
+		return nil, fmt.Errorf("invalid flare upload URL %s", redactURL(uploadURL))
+	}
+	request.ContentLength = info.Size()
+	request.Header.Set("Content-Type", "application/octet-stream")
+	return request, nil
+}
+
+func doUploadRequest(client *http.Client, request *http.Request) error {
+	response, err := client.Do(request)
+	if err != nil {
+		// errors of the HTTP client contain the URL, which can be presigned
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("could not upload flare to %s: %w", redactURL(request.URL.String()), err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+		return fmt.Errorf("could not upload flare to %s: HTTP %s\nServer returned:\n%s", redactURL(request.URL.String()), response.Status, body)
+	}
+	return nil
+}
+
+// redactURL removes the query of a URL, as presigned URLs carry their credentials in the query
+func redactURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "<invalid URL>"
+	}
+	parsed.User = nil
+	parsed.RawQuery = ""
+	parsed.Fragment = ""
+	return parsed.String()
+}
+
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,101 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package helpers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	pgp "github.com/ProtonMail/go-crypto/openpgp"
+
+	pkgconfigmodel "github.com/DataDog/datadog-agent/pkg/config/model"
+)
+
+const (
+	// EncryptionNone is used when flares are sent as plain zip archives
+	EncryptionNone = "none"
+	// EncryptionOpenPGP encrypts flares to an OpenPGP public key
+	EncryptionOpenPGP = "openpgp"
+)
+
+// encryptFlare encrypts a flare archive as configured by `flare.encryption`. It returns the path to the archive to
+// send, which is the original archive when encryption is disabled, and the encryption used. Flares can only be
+// encrypted to OpenPGP keys, age keys are not supported.
+func encryptFlare(cfg pkgconfigmodel.Reader, archivePath string) (string, string, error) {
+	switch encryption := cfg.GetString("flare.encryption.type"); encryption {
+	case "", EncryptionNone:
+		return archivePath, EncryptionNone, nil
+	case EncryptionOpenPGP:
+		keyFile := cfg.GetString("flare.encryption.public_key_file")
+		if keyFile == "" {
+			return "", "", fmt.Errorf("flare.encryption.public_key_file is required to encrypt flares with %s", encryption)
+		}
+		encryptedPath, err := encryptOpenPGP(archivePath, keyFile)
+		if err != nil {
+			return "", "", fmt.Errorf("could not encrypt flare: %w", err)
+		}
+		return encryptedPath, encryption, nil
+	default:
+		return "", "", fmt.Errorf("unsupported flare encryption type %q, supported types are %q and %q", encryption, EncryptionNone, EncryptionOpenPGP)
+	}
+}
+
+// readOpenPGPKeys reads the public keys of a key file, either armored or binary
+func readOpenPGPKeys(keyFile string) (pgp.EntityList, error) {
+	content, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := pgp.ReadArmoredKeyRing(bytes.NewReader(content))
+	if err != nil {
+		keys, err = pgp.ReadKeyRing(bytes.NewReader(content))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read OpenPGP public key from %s: %w", keyFile, err)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no OpenPGP public key found in %s", keyFile)
+	}
+	return keys, nil
+}
+
+// encryptOpenPGP encrypts an archive to the keys of a key file and returns the path of the encrypted archive, which
+// is written next to the original one with a `.gpg` extension.
+func encryptOpenPGP(archivePath string, keyFile string) (string, error) {
+	keys, err := readOpenPGPKeys(keyFile)
+	if err != nil {
+		return "", err
+	}
+
+	archive, err := os.Open(archivePath)
+	if err != nil {
+		return "", err
+	}
+	defer archive.Close()
+
+	encryptedPath := archivePath + ".gpg"
+	encrypted, err := os.OpenFile(encryptedPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return "", err
+	}
+	defer encrypted.Close()
+
+	plaintext, err := pgp.Encrypt(encrypted, keys, nil, &pgp.FileHints{IsBinary: true, FileName: filepath.Base(archivePath)}, nil)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(plaintext, archive); err != nil {
+		plaintext.Close()
+		return "", err
+	}
+	if err := plaintext.Close(); err != nil {
+		return "", err
+	}
+	return encryptedPath, encrypted.Close()
+}
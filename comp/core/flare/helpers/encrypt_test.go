@@ -0,0 +1,151 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package helpers
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pgp "github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/core/config"
+)
+
+// writeOpenPGPKey generates a key pair and writes its armored public key to a file
+func writeOpenPGPKey(t *testing.T) (*pgp.Entity, string) {
+	entity, err := pgp.NewEntity("Flare Test", "", "flare@example.com", nil)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, pgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.Serialize(w))
+	require.NoError(t, w.Close())
+
+	keyFile := filepath.Join(t.TempDir(), "flare.asc")
+	require.NoError(t, os.WriteFile(keyFile, buf.Bytes(), 0600))
+	return entity, keyFile
+}
+
+func decryptOpenPGP(t *testing.T, entity *pgp.Entity, path string) ([]byte, string) {
+	encrypted, err := os.Open(path)
+	require.NoError(t, err)
+	defer encrypted.Close()
+
+	message, err := pgp.ReadMessage(encrypted, pgp.EntityList{entity}, nil, nil)
+	require.NoError(t, err)
+	content, err := io.ReadAll(message.UnverifiedBody)
+	require.NoError(t, err)
+	return content, message.LiteralData.FileName
+}
+
+func writeArchive(t *testing.T, content string) string {
+	archivePath := filepath.Join(t.TempDir(), "datadog-agent-2025-01-01T00-00-00Z.zip")
+	require.NoError(t, os.WriteFile(archivePath, []byte(content), 0600))
+	return archivePath
+}
+
+func TestEncryptFlareDisabled(t *testing.T) {
+	cfg := config.NewMock(t)
+	archivePath := writeArchive(t, "flare content")
+
+	path, encryption, err := encryptFlare(cfg, archivePath)
+	require.NoError(t, err)
+	assert.Equal(t, archivePath, path)
+	assert.Equal(t, EncryptionNone, encryption)
+}
+
+func TestEncryptFlareOpenPGP(t *testing.T) {
+	entity, keyFile := writeOpenPGPKey(t)
+	cfg := config.NewMockWithOverrides(t, map[string]interface{}{
+		"flare.encryption.type":            "openpgp",
+		"flare.encryption.public_key_file": keyFile,
+	})
+	archivePath := writeArchive(t, "flare content")
+
+	path, encryption, err := encryptFlare(cfg, archivePath)
+	require.NoError(t, err)
+	assert.Equal(t, archivePath+".gpg", path)
+	assert.Equal(t, EncryptionOpenPGP, encryption)
+
+	encrypted, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(encrypted), "flare content")
+
+	content, fileName := decryptOpenPGP(t, entity, path)
+	assert.Equal(t, "flare content", string(content))
+	assert.Equal(t, filepath.Base(archivePath), fileName)
+}
+
+func TestEncryptFlareErrors(t *testing.T) {
+	archivePath := writeArchive(t, "flare content")
+	invalidKeyFile := filepath.Join(t.TempDir(), "invalid.asc")
+	require.NoError(t, os.WriteFile(invalidKeyFile, []byte("not a key"), 0600))
+
+	testCases := []struct {
+		name          string
+		overrides     map[string]interface{}
+		expectedError string
+	}{
+		{
+			name:          "unsupported type",
+			overrides:     map[string]interface{}{"flare.encryption.type": "rot13"},
+			expectedError: `unsupported flare encryption type "rot13"`,
+		},
+		{
+			name:          "missing key",
+			overrides:     map[string]interface{}{"flare.encryption.type": "openpgp"},
+			expectedError: "flare.encryption.public_key_file is required to encrypt flares with openpgp",
+		},
+		{
+			name: "invalid key",
+			overrides: map[string]interface{}{
+				"flare.encryption.type":            "openpgp",
+				"flare.encryption.public_key_file": invalidKeyFile,
+			},
+			expectedError: "could not read OpenPGP public key from " + invalidKeyFile,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, _, err := encryptFlare(config.NewMockWithOverrides(t, testCase.overrides), archivePath)
+			assert.ErrorContains(t, err, testCase.expectedError)
+		})
+	}
+}
+
+func TestGetFlareMetadata(t *testing.T) {
+	metadata, err := GetFlareMetadata(config.NewMock(t))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"destination": "datadog", "encryption": "none"}`, string(metadata))
+
+	entity, keyFile := writeOpenPGPKey(t)
+	cfg := config.NewMockWithOverrides(t, map[string]interface{}{
+		"flare.destination.type":           "s3",
+		"flare.encryption.type":            "openpgp",
+		"flare.encryption.public_key_file": keyFile,
+	})
+	metadata, err = GetFlareMetadata(cfg)
+	require.NoError(t, err)
+	fingerprint := strings.ToUpper(hex.EncodeToString(entity.PrimaryKey.Fingerprint))
+	assert.JSONEq(t, `{"destination": "s3", "encryption": "openpgp", "encryption_keys": ["`+fingerprint+`"]}`, string(metadata))
+
+	// an unreadable key file doesn't prevent creating the flare
+	cfg.SetWithoutSource("flare.encryption.public_key_file", filepath.Join(t.TempDir(), "missing.asc"))
+	metadata, err = GetFlareMetadata(cfg)
+	require.NoError(t, err)
+	assert.Contains(t, string(metadata), `"encryption": "openpgp"`)
+	assert.Contains(t, string(metadata), `"encryption_keys_error": "`)
+	assert.NotContains(t, string(metadata), `"encryption_keys": [`)
+}
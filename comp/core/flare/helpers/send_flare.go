@@ -61,7 +61,7 @@ func NewRemoteConfigFlareSource(rcTaskUUID string) FlareSource {
 	}
 }
 
-func getFlareReader(multipartBoundary, archivePath, caseID, email, hostname string, source FlareSource) io.ReadCloser {
+func getFlareReader(multipartBoundary, archivePath, caseID, email, hostname, encryption string, source FlareSource) io.ReadCloser {
 	//No need to close the reader, http.Client does it for us
 	bodyReader, bodyWriter := io.Pipe()
 
@@ -87,6 +87,9 @@ func getFlareReader(multipartBoundary, archivePath, caseID, email, hostname stri
 			// UUID of the remote-config task sending the flare
 			writer.WriteField("rc_task_uuid", source.rcTaskUUID) //nolint:errcheck
 		}
+		if encryption != EncryptionNone {
+			writer.WriteField("encryption", encryption) //nolint:errcheck
+		}
 
 		p, err := writer.CreateFormFile("flare_file", filepath.Base(archivePath))
 		if err != nil {
@@ -114,7 +117,7 @@ func getFlareReader(multipartBoundary, archivePath, caseID, email, hostname stri
 	return bodyReader
 }
 
-func readAndPostFlareFile(archivePath, caseID, email, hostname, url string, source FlareSource, client *http.Client, apiKey string) (*http.Response, error) {
+func readAndPostFlareFile(archivePath, caseID, email, hostname, encryption, url string, source FlareSource, client *http.Client, apiKey string) (*http.Response, error) {
 	// Having resolved the POST URL, we do not expect to see further redirects, so do not
 	// handle them.
 	client.CheckRedirect = func(_ *http.Request, _ []*http.Request) error {
@@ -136,7 +139,7 @@ func readAndPostFlareFile(archivePath, caseID, email, hostname, url string, sour
 
 	// Manually set the Body and ContentLenght. http.NewRequest doesn't do all of this
 	// for us, since a PipeReader is not one of the Reader types it knows how to handle.
-	request.Body = getFlareReader(boundaryWriter.Boundary(), archivePath, caseID, email, hostname, source)
+	request.Body = getFlareReader(boundaryWriter.Boundary(), archivePath, caseID, email, hostname, encryption, source)
 
 	// -1 here means 'unknown' and makes this a 'chunked' request. See https://github.com/golang/go/issues/18117
 	request.ContentLength = -1
@@ -243,10 +246,33 @@ func mkURL(baseURL string, caseID string) string {
 	return url
 }
 
-// SendTo sends a flare file to the backend. This is part of the "helpers" package while all the code is moved to
-// components. When possible use the "Send" method of the "flare" component instead.
+// SendTo sends a flare file to the backend, or to the destination configured by `flare.destination`, after
+// encrypting it if `flare.encryption` is configured. This is part of the "helpers" package while all the code is
+// moved to components. When possible use the "Send" method of the "flare" component instead.
 func SendTo(cfg pkgconfigmodel.Reader, archivePath, caseID, email, apiKey, url string, source FlareSource) (string, error) {
-	hostname, err := hostnameUtil.Get(context.TODO())
+	return SendToWithContext(context.Background(), cfg, archivePath, caseID, email, apiKey, url, source)
+}
+
+// SendToWithContext is SendTo, with a context cancelling the upload to the destination configured by `flare.destination`
+func SendToWithContext(ctx context.Context, cfg pkgconfigmodel.Reader, archivePath, caseID, email, apiKey, url string, source FlareSource) (string, error) {
+	archivePath, encryption, err := encryptFlare(cfg, archivePath)
+	if err != nil {
+		return "", err
+	}
+	if encryption != EncryptionNone {
+		// the encrypted archive is only a copy of the flare made for sending it
+		defer os.Remove(archivePath)
+	}
+
+	if destination := getDestination(cfg); destination != DestinationDatadog {
+		flareDestination, err := newFlareDestination(cfg, destination)
+		if err != nil {
+			return "", err
+		}
+		return flareDestination.upload(ctx, archivePath)
+	}
+
+	hostname, err := hostnameUtil.Get(ctx)
 	if err != nil {
 		hostname = "unknown"
 	}
@@ -272,7 +298,7 @@ func SendTo(cfg pkgconfigmodel.Reader, archivePath, caseID, email, apiKey, url s
 	var baseDelay = 1 * time.Second
 
 	for attempt := 3; attempt > 0; attempt-- {
-		r, err := readAndPostFlareFile(archivePath, caseID, email, hostname, url, source, client, apiKey)
+		r, err := readAndPostFlareFile(archivePath, caseID, email, hostname, encryption, url, source, client, apiKey)
 		if err != nil {
 			// Always close the response body if it exists
 			statusCode := 0
@@ -108,6 +108,7 @@ func TestFlareHasRightForm(t *testing.T) {
 				assert.Equal(t, caseID, lastRequest.FormValue("case_id"))
 				assert.Equal(t, email, lastRequest.FormValue("email"))
 				assert.Equal(t, av.String(), lastRequest.FormValue("agent_version"))
+				assert.Empty(t, lastRequest.FormValue("encryption"))
 			}
 		})
 	}
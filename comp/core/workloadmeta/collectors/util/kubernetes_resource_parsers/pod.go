@@ -89,9 +89,33 @@ func (p podParser) Parse(obj interface{}) workloadmeta.Entity {
 		if memoryReq, found := container.Resources.Requests[corev1.ResourceMemory]; found {
 			c.Resources.MemoryRequest = kubernetes.FormatMemoryRequests(memoryReq)
 		}
+		if cpuLimit, found := container.Resources.Limits[corev1.ResourceCPU]; found {
+			c.Resources.CPULimit = kubernetes.FormatCPURequests(cpuLimit)
+		}
+		if memoryLimit, found := container.Resources.Limits[corev1.ResourceMemory]; found {
+			c.Resources.MemoryLimit = kubernetes.FormatMemoryRequests(memoryLimit)
+		}
 		containersList = append(containersList, c)
 	}
 
+	// Only the last termination state is kept, it's used by the autoscaling to detect OOM kills
+	var containerStatuses []workloadmeta.KubernetesContainerStatus
+	for _, status := range pod.Status.ContainerStatuses {
+		containerStatus := workloadmeta.KubernetesContainerStatus{
+			Name:         status.Name,
+			RestartCount: status.RestartCount,
+		}
+		if terminated := status.LastTerminationState.Terminated; terminated != nil {
+			containerStatus.LastTerminationState.Terminated = &workloadmeta.KubernetesContainerStateTerminated{
+				ExitCode:   terminated.ExitCode,
+				StartedAt:  terminated.StartedAt.Time,
+				FinishedAt: terminated.FinishedAt.Time,
+				Reason:     terminated.Reason,
+			}
+		}
+		containerStatuses = append(containerStatuses, containerStatus)
+	}
+
 	return &workloadmeta.KubernetesPod{
 		EntityID: workloadmeta.EntityID{
 			Kind: workloadmeta.KindKubernetesPod,
@@ -113,5 +137,6 @@ func (p podParser) Parse(obj interface{}) workloadmeta.Entity {
 		RuntimeClass:               rtcName,
 		GPUVendorList:              gpuVendorList,
 		Containers:                 containersList,
+		ContainerStatuses:          containerStatuses,
 	}
 }
@@ -9,6 +9,7 @@ package kubernetesresourceparsers
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -142,3 +143,71 @@ func TestPodParser_Parse(t *testing.T) {
 		cmp.Diff(expected, parsed, opt),
 	)
 }
+
+func TestPodParser_ParseResourcesAndStatuses(t *testing.T) {
+	parser, err := NewPodParser(nil)
+	assert.NoError(t, err)
+
+	finishedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "TestPod",
+			UID:  "uniqueIdentifier",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "app",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("250m"),
+							corev1.ResourceMemory: resource.MustParse("128Mi"),
+						},
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("500m"),
+							corev1.ResourceMemory: resource.MustParse("256Mi"),
+						},
+					},
+				},
+			},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:         "app",
+					RestartCount: 2,
+					LastTerminationState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode:   137,
+							Reason:     "OOMKilled",
+							FinishedAt: metav1.NewTime(finishedAt),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	parsed := parser.Parse(&pod).(*workloadmeta.KubernetesPod)
+
+	assert.Len(t, parsed.Containers, 1)
+	resources := parsed.Containers[0].Resources
+	assert.Equal(t, 25.0, *resources.CPURequest)
+	assert.Equal(t, 50.0, *resources.CPULimit)
+	assert.Equal(t, uint64(128*1024*1024), *resources.MemoryRequest)
+	assert.Equal(t, uint64(256*1024*1024), *resources.MemoryLimit)
+
+	assert.Equal(t, []workloadmeta.KubernetesContainerStatus{
+		{
+			Name:         "app",
+			RestartCount: 2,
+			LastTerminationState: workloadmeta.KubernetesContainerState{
+				Terminated: &workloadmeta.KubernetesContainerStateTerminated{
+					ExitCode:   137,
+					Reason:     "OOMKilled",
+					FinishedAt: finishedAt,
+				},
+			},
+		},
+	}, parsed.ContainerStatuses)
+}
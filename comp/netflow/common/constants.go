@@ -26,4 +26,16 @@ const (
 
 	// DefaultPrometheusListenerAddress is the default goflow prometheus listener address
 	DefaultPrometheusListenerAddress = "localhost:9090"
+
+	// DefaultGeoIPCacheSize is the default number of IPs whose GeoIP enrichment is cached
+	DefaultGeoIPCacheSize = 10000
+
+	// DefaultGeoIPReloadInterval is the default interval in seconds to check GeoIP databases for changes
+	DefaultGeoIPReloadInterval = 60
+
+	// DefaultSinkMaxSizeMB is the default size in MB of the files of ndjson sinks before they are rotated
+	DefaultSinkMaxSizeMB = 100
+
+	// DefaultSinkMaxBackups is the default number of rotated files kept by ndjson sinks
+	DefaultSinkMaxBackups = 5
 )
@@ -11,6 +11,8 @@ import (
 	"encoding/binary"
 	flowmessage "github.com/netsampler/goflow2/pb"
 	"hash/fnv"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/payload"
 )
 
 // Flow contains flow info used for aggregation
@@ -67,6 +69,10 @@ type Flow struct {
 	SrcReverseDNSHostname string
 	DstReverseDNSHostname string
 
+	// GeoIP enrichment added during Flow aggregation processing
+	SrcGeoIP *payload.GeoIP
+	DstGeoIP *payload.GeoIP
+
 	// Ethernet information
 	Tos uint32 // FLOW KEY
 
@@ -36,6 +36,40 @@ type NetflowConfig struct {
 	PrometheusListenerEnabled bool   `mapstructure:"prometheus_listener_enabled"`
 
 	ReverseDNSEnrichmentEnabled bool `mapstructure:"reverse_dns_enrichment_enabled"`
+
+	GeoIP GeoIPConfig `mapstructure:"geoip_enrichment"`
+
+	Sinks []SinkConfig `mapstructure:"sinks"`
+}
+
+// GeoIPConfig contains configuration for the GeoIP and ASN enrichment of flows
+type GeoIPConfig struct {
+	Enabled        bool   `mapstructure:"enabled"`
+	LocationDBPath string `mapstructure:"location_db_path"` // GeoIP2/GeoLite2 City or Country database
+	ASNDBPath      string `mapstructure:"asn_db_path"`      // GeoIP2/GeoLite2 ASN database
+	CacheSize      int    `mapstructure:"cache_size"`
+	ReloadInterval int    `mapstructure:"reload_interval"` // in seconds
+}
+
+// Sink types
+const (
+	SinkTypeNDJSON = "ndjson"
+	SinkTypeIPFIX  = "ipfix"
+)
+
+// SinkConfig contains configuration for a local export of the aggregated flows
+type SinkConfig struct {
+	Type   string `mapstructure:"type"`   // ndjson or ipfix
+	Filter string `mapstructure:"filter"` // only flows matching the filter are exported, all flows by default
+
+	// ndjson sinks
+	Path       string `mapstructure:"path"`
+	MaxSizeMB  int    `mapstructure:"max_size_mb"`
+	MaxBackups int    `mapstructure:"max_backups"`
+
+	// ipfix sinks
+	Destination         string `mapstructure:"destination"` // Example `collector.local:4739`
+	ObservationDomainID uint32 `mapstructure:"observation_domain_id"`
 }
 
 // ListenerConfig contains configuration for a single flow listener
@@ -138,9 +172,46 @@ func (mainConfig *NetflowConfig) SetDefaults(namespace string, logger log.Compon
 		mainConfig.PrometheusListenerAddress = common.DefaultPrometheusListenerAddress
 	}
 
+	if mainConfig.GeoIP.CacheSize <= 0 {
+		mainConfig.GeoIP.CacheSize = common.DefaultGeoIPCacheSize
+	}
+	if mainConfig.GeoIP.ReloadInterval == 0 {
+		mainConfig.GeoIP.ReloadInterval = common.DefaultGeoIPReloadInterval
+	}
+
+	for i := range mainConfig.Sinks {
+		sinkConfig := &mainConfig.Sinks[i]
+		switch sinkConfig.Type {
+		case SinkTypeNDJSON:
+			if sinkConfig.Path == "" {
+				return fmt.Errorf("a path must be set for the `%s` sink", sinkConfig.Type)
+			}
+			if sinkConfig.MaxSizeMB <= 0 {
+				sinkConfig.MaxSizeMB = common.DefaultSinkMaxSizeMB
+			}
+			if sinkConfig.MaxBackups <= 0 {
+				sinkConfig.MaxBackups = common.DefaultSinkMaxBackups
+			}
+		case SinkTypeIPFIX:
+			if sinkConfig.Destination == "" {
+				return fmt.Errorf("a destination must be set for the `%s` sink", sinkConfig.Type)
+			}
+		default:
+			return fmt.Errorf("the provided sink type `%s` is not valid (valid sink types: %s, %s)", sinkConfig.Type, SinkTypeNDJSON, SinkTypeIPFIX)
+		}
+	}
+
 	return nil
 }
 
+// Target returns the file or the collector the flows are exported to.
+func (c *SinkConfig) Target() string {
+	if c.Type == SinkTypeIPFIX {
+		return c.Destination
+	}
+	return c.Path
+}
+
 // Addr returns the host:port address to listen on.
 func (c *ListenerConfig) Addr() string {
 	return fmt.Sprintf("%s:%d", c.BindHost, c.Port)
@@ -55,6 +55,19 @@ network_devices:
           my-ns2<abc
           zz
     reverse_dns_enrichment_enabled: true
+    geoip_enrichment:
+      enabled: true
+      location_db_path: /opt/geoip/GeoLite2-City.mmdb
+      asn_db_path: /opt/geoip/GeoLite2-ASN.mmdb
+      cache_size: 500
+      reload_interval: 10
+    sinks:
+      - type: ndjson
+        path: /var/log/datadog/flows.json
+        filter: dst_port == 443
+      - type: ipfix
+        destination: 127.0.0.1:4739
+        observation_domain_id: 12
 `,
 			expectedConfig: NetflowConfig{
 				Enabled:                                true,
@@ -84,6 +97,27 @@ network_devices:
 					},
 				},
 				ReverseDNSEnrichmentEnabled: true,
+				GeoIP: GeoIPConfig{
+					Enabled:        true,
+					LocationDBPath: "/opt/geoip/GeoLite2-City.mmdb",
+					ASNDBPath:      "/opt/geoip/GeoLite2-ASN.mmdb",
+					CacheSize:      500,
+					ReloadInterval: 10,
+				},
+				Sinks: []SinkConfig{
+					{
+						Type:       "ndjson",
+						Filter:     "dst_port == 443",
+						Path:       "/var/log/datadog/flows.json",
+						MaxSizeMB:  100,
+						MaxBackups: 5,
+					},
+					{
+						Type:                "ipfix",
+						Destination:         "127.0.0.1:4739",
+						ObservationDomainID: 12,
+					},
+				},
 			},
 		},
 		{
@@ -114,6 +148,10 @@ network_devices:
 					},
 				},
 				ReverseDNSEnrichmentEnabled: false,
+				GeoIP: GeoIPConfig{
+					CacheSize:      10000,
+					ReloadInterval: 60,
+				},
 			},
 		},
 		{
@@ -145,6 +183,10 @@ network_devices:
 					},
 				},
 				ReverseDNSEnrichmentEnabled: false,
+				GeoIP: GeoIPConfig{
+					CacheSize:      10000,
+					ReloadInterval: 60,
+				},
 			},
 		},
 		{
@@ -158,6 +200,28 @@ network_devices:
 `,
 			expectedError: "the provided flow type `invalidType` is not valid",
 		},
+		{
+			name: "invalid sink type",
+			configYaml: `
+network_devices:
+  netflow:
+    enabled: true
+    sinks:
+      - type: kafka
+`,
+			expectedError: "the provided sink type `kafka` is not valid (valid sink types: ndjson, ipfix)",
+		},
+		{
+			name: "ndjson sink without path",
+			configYaml: `
+network_devices:
+  netflow:
+    enabled: true
+    sinks:
+      - type: ndjson
+`,
+			expectedError: "a path must be set for the `ndjson` sink",
+		},
 		{
 			name: "invalid namespace with >100 chars",
 			configYaml: `
@@ -210,6 +274,10 @@ network_devices:
 					},
 				},
 				ReverseDNSEnrichmentEnabled: false,
+				GeoIP: GeoIPConfig{
+					CacheSize:      10000,
+					ReloadInterval: 60,
+				},
 			},
 		},
 	}
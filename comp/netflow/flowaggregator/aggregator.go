@@ -21,6 +21,8 @@ import (
 	log "github.com/DataDog/datadog-agent/comp/core/log/def"
 	"github.com/DataDog/datadog-agent/comp/forwarder/eventplatform"
 	"github.com/DataDog/datadog-agent/comp/netflow/format"
+	"github.com/DataDog/datadog-agent/comp/netflow/geoip"
+	"github.com/DataDog/datadog-agent/comp/netflow/sink"
 	rdnsquerier "github.com/DataDog/datadog-agent/comp/rdnsquerier/def"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
@@ -56,6 +58,9 @@ type FlowAggregator struct {
 	lastSequencePerExporter   map[sequenceDeltaKey]uint32
 	lastSequencePerExporterMu sync.Mutex
 
+	// sinks export the flows outside of Datadog, in addition to the event platform
+	sinks []sink.Sink
+
 	logger log.Component
 }
 
@@ -80,13 +85,13 @@ var maxNegativeSequenceDiffToReset = map[common.FlowType]int{
 }
 
 // NewFlowAggregator returns a new FlowAggregator
-func NewFlowAggregator(sender sender.Sender, epForwarder eventplatform.Forwarder, config *config.NetflowConfig, hostname string, logger log.Component, rdnsQuerier rdnsquerier.Component) *FlowAggregator {
+func NewFlowAggregator(sender sender.Sender, epForwarder eventplatform.Forwarder, config *config.NetflowConfig, hostname string, logger log.Component, rdnsQuerier rdnsquerier.Component, geoIP *geoip.Enricher) *FlowAggregator {
 	flushInterval := time.Duration(config.AggregatorFlushInterval) * time.Second
 	flowContextTTL := time.Duration(config.AggregatorFlowContextTTL) * time.Second
 	rollupTrackerRefreshInterval := time.Duration(config.AggregatorRollupTrackerRefreshInterval) * time.Second
 	return &FlowAggregator{
 		flowIn:                       make(chan *common.Flow, config.AggregatorBufferSize),
-		flowAcc:                      newFlowAccumulator(flushInterval, flowContextTTL, config.AggregatorPortRollupThreshold, config.AggregatorPortRollupDisabled, logger, rdnsQuerier),
+		flowAcc:                      newFlowAccumulator(flushInterval, flowContextTTL, config.AggregatorPortRollupThreshold, config.AggregatorPortRollupDisabled, logger, rdnsQuerier, geoIP),
 		FlushFlowsToSendInterval:     flushFlowsToSendInterval,
 		rollupTrackerRefreshInterval: rollupTrackerRefreshInterval,
 		sender:                       sender,
@@ -100,10 +105,25 @@ func NewFlowAggregator(sender sender.Sender, epForwarder eventplatform.Forwarder
 		goflowPrometheusGatherer:     prometheus.DefaultGatherer,
 		TimeNowFunction:              time.Now,
 		lastSequencePerExporter:      make(map[sequenceDeltaKey]uint32),
+		sinks:                        newSinks(config.Sinks, logger),
 		logger:                       logger,
 	}
 }
 
+// newSinks creates the configured sinks, sinks that can't be created are logged and skipped
+func newSinks(sinkConfigs []config.SinkConfig, logger log.Component) []sink.Sink {
+	var sinks []sink.Sink
+	for _, sinkConfig := range sinkConfigs {
+		flowSink, err := sink.New(sinkConfig, logger)
+		if err != nil {
+			logger.Errorf("Error creating %s flow sink %s: %s", sinkConfig.Type, sinkConfig.Target(), err)
+			continue
+		}
+		sinks = append(sinks, flowSink)
+	}
+	return sinks
+}
+
 // Start will start the FlowAggregator worker
 func (agg *FlowAggregator) Start() {
 	agg.logger.Info("Flow Aggregator started")
@@ -116,6 +136,11 @@ func (agg *FlowAggregator) Stop() {
 	close(agg.stopChan)
 	<-agg.flushLoopDone
 	<-agg.runDone
+	for _, flowSink := range agg.sinks {
+		if err := flowSink.Close(); err != nil {
+			agg.logger.Warnf("Error closing flow sink: %s", err)
+		}
+	}
 }
 
 // GetFlowInChan returns flow input chan
@@ -138,6 +163,7 @@ func (agg *FlowAggregator) run() {
 }
 
 func (agg *FlowAggregator) sendFlows(flows []*common.Flow, flushTime time.Time) {
+	var sinkRecords []sink.Record
 	for _, flow := range flows {
 		flowPayload := buildPayload(flow, agg.hostname, flushTime)
 
@@ -148,6 +174,9 @@ func (agg *FlowAggregator) sendFlows(flows []*common.Flow, flushTime time.Time)
 			continue
 		}
 		agg.logger.Tracef("flushed flow: %s", string(payloadBytes))
+		if len(agg.sinks) > 0 {
+			sinkRecords = append(sinkRecords, sink.Record{Flow: flow, Payload: payloadBytes})
+		}
 
 		m := message.NewMessage(payloadBytes, nil, "", 0)
 		err = agg.epForwarder.SendEventPlatformEventBlocking(m, eventplatform.EventTypeNetworkDevicesNetFlow)
@@ -157,6 +186,12 @@ func (agg *FlowAggregator) sendFlows(flows []*common.Flow, flushTime time.Time)
 			continue
 		}
 	}
+
+	for _, flowSink := range agg.sinks {
+		if err := flowSink.Send(sinkRecords); err != nil {
+			agg.logger.Warnf("Error exporting flows to sink: %s", err)
+		}
+	}
 }
 
 func (agg *FlowAggregator) sendExporterMetadata(flows []*common.Flow, flushTime time.Time) {
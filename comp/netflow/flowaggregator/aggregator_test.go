@@ -12,6 +12,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
@@ -170,7 +172,7 @@ func TestAggregator(t *testing.T) {
 	logger := logmock.New(t)
 	rdnsQuerier := fxutil.Test[rdnsquerier.Component](t, rdnsquerierfxmock.MockModule())
 
-	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier)
+	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier, nil)
 	aggregator.FlushFlowsToSendInterval = 1 * time.Second
 	aggregator.TimeNowFunction = func() time.Time {
 		return flushTime
@@ -273,7 +275,7 @@ func TestAggregator_withMockPayload(t *testing.T) {
 
 	logger := logmock.New(t)
 	rdnsQuerier := fxutil.Test[rdnsquerier.Component](t, rdnsquerierfxmock.MockModule())
-	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier)
+	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier, nil)
 	aggregator.FlushFlowsToSendInterval = 1 * time.Second
 	aggregator.TimeNowFunction = func() time.Time {
 		return flushTime
@@ -366,7 +368,7 @@ func TestFlowAggregator_flush_submitCollectorMetrics_error(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	epForwarder := eventplatformimpl.NewMockEventPlatformForwarder(ctrl)
 
-	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier)
+	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier, nil)
 	aggregator.goflowPrometheusGatherer = prometheus.GathererFunc(func() ([]*promClient.MetricFamily, error) {
 		return nil, fmt.Errorf("some prometheus gatherer error")
 	})
@@ -407,7 +409,7 @@ func TestFlowAggregator_submitCollectorMetrics(t *testing.T) {
 	logger := logmock.New(t)
 	rdnsQuerier := fxutil.Test[rdnsquerier.Component](t, rdnsquerierfxmock.MockModule())
 
-	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier)
+	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier, nil)
 	aggregator.goflowPrometheusGatherer = prometheus.GathererFunc(func() ([]*promClient.MetricFamily, error) {
 		return []*promClient.MetricFamily{
 			{
@@ -484,7 +486,7 @@ func TestFlowAggregator_submitCollectorMetrics_error(t *testing.T) {
 	logger := logmock.New(t)
 	rdnsQuerier := fxutil.Test[rdnsquerier.Component](t, rdnsquerierfxmock.MockModule())
 
-	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier)
+	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier, nil)
 	aggregator.goflowPrometheusGatherer = prometheus.GathererFunc(func() ([]*promClient.MetricFamily, error) {
 		return nil, fmt.Errorf("some prometheus gatherer error")
 	})
@@ -519,7 +521,7 @@ func TestFlowAggregator_sendExporterMetadata_multiplePayloads(t *testing.T) {
 	logger := logmock.New(t)
 	rdnsQuerier := fxutil.Test[rdnsquerier.Component](t, rdnsquerierfxmock.MockModule())
 
-	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier)
+	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier, nil)
 
 	var flows []*common.Flow
 	for i := 1; i <= 250; i++ {
@@ -582,6 +584,73 @@ func TestFlowAggregator_sendExporterMetadata_multiplePayloads(t *testing.T) {
 	aggregator.sendExporterMetadata(flows, now)
 }
 
+func TestFlowAggregator_sendFlows_sinks(t *testing.T) {
+	dir := t.TempDir()
+	sender := mocksender.NewMockSender("")
+	conf := config.NetflowConfig{
+		StopTimeout:                            10,
+		AggregatorBufferSize:                   20,
+		AggregatorFlushInterval:                1,
+		AggregatorPortRollupThreshold:          10,
+		AggregatorRollupTrackerRefreshInterval: 3600,
+		Sinks: []config.SinkConfig{
+			{Type: config.SinkTypeNDJSON, Path: filepath.Join(dir, "all.json"), MaxSizeMB: 1, MaxBackups: 1},
+			{Type: config.SinkTypeNDJSON, Path: filepath.Join(dir, "https.json"), MaxSizeMB: 1, MaxBackups: 1, Filter: "dst_port == 443 and protocol == tcp"},
+			// invalid sinks are skipped
+			{Type: config.SinkTypeNDJSON, Path: filepath.Join(dir, "invalid.json"), Filter: "dst_port =="},
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	epForwarder := eventplatformimpl.NewMockEventPlatformForwarder(ctrl)
+	epForwarder.EXPECT().SendEventPlatformEventBlocking(gomock.Any(), "network-devices-netflow").Return(nil).Times(2)
+	logger := logmock.New(t)
+	rdnsQuerier := fxutil.Test[rdnsquerier.Component](t, rdnsquerierfxmock.MockModule())
+
+	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier, nil)
+	require.Len(t, aggregator.sinks, 2)
+
+	flows := []*common.Flow{
+		{
+			Namespace:    "my-ns",
+			FlowType:     common.TypeNetFlow9,
+			ExporterAddr: []byte{127, 0, 0, 1},
+			SrcAddr:      []byte{10, 10, 10, 10},
+			DstAddr:      []byte{10, 10, 10, 20},
+			IPProtocol:   6,
+			SrcPort:      common.EphemeralPort,
+			DstPort:      443,
+		},
+		{
+			Namespace:    "my-ns",
+			FlowType:     common.TypeNetFlow9,
+			ExporterAddr: []byte{127, 0, 0, 1},
+			SrcAddr:      []byte{10, 10, 10, 10},
+			DstAddr:      []byte{10, 10, 10, 30},
+			IPProtocol:   17,
+			SrcPort:      common.EphemeralPort,
+			DstPort:      53,
+		},
+	}
+	aggregator.sendFlows(flows, time.Unix(1681295467, 0))
+	for _, flowSink := range aggregator.sinks {
+		require.NoError(t, flowSink.Close())
+	}
+
+	readLines := func(path string) []string {
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	}
+	all := readLines(filepath.Join(dir, "all.json"))
+	require.Len(t, all, 2)
+	assert.Contains(t, all[0], `"destination":{"ip":"10.10.10.20","port":"443"`)
+	assert.Contains(t, all[1], `"destination":{"ip":"10.10.10.30","port":"53"`)
+	https := readLines(filepath.Join(dir, "https.json"))
+	assert.Equal(t, all[:1], https)
+	assert.NoFileExists(t, filepath.Join(dir, "invalid.json"))
+}
+
 func TestFlowAggregator_sendExporterMetadata_noPayloads(t *testing.T) {
 	sender := mocksender.NewMockSender("")
 	conf := config.NetflowConfig{
@@ -605,7 +674,7 @@ func TestFlowAggregator_sendExporterMetadata_noPayloads(t *testing.T) {
 	logger := logmock.New(t)
 	rdnsQuerier := fxutil.Test[rdnsquerier.Component](t, rdnsquerierfxmock.MockModule())
 
-	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier)
+	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier, nil)
 
 	var flows []*common.Flow
 	now := time.Unix(1681295467, 0)
@@ -638,7 +707,7 @@ func TestFlowAggregator_sendExporterMetadata_invalidIPIgnored(t *testing.T) {
 
 	logger := logmock.New(t)
 	rdnsQuerier := fxutil.Test[rdnsquerier.Component](t, rdnsquerierfxmock.MockModule())
-	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier)
+	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier, nil)
 
 	now := time.Unix(1681295467, 0)
 	flows := []*common.Flow{
@@ -724,7 +793,7 @@ func TestFlowAggregator_sendExporterMetadata_multipleNamespaces(t *testing.T) {
 
 	logger := logmock.New(t)
 	rdnsQuerier := fxutil.Test[rdnsquerier.Component](t, rdnsquerierfxmock.MockModule())
-	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier)
+	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier, nil)
 
 	now := time.Unix(1681295467, 0)
 	flows := []*common.Flow{
@@ -830,7 +899,7 @@ func TestFlowAggregator_sendExporterMetadata_singleExporterIpWithMultipleFlowTyp
 	logger := logmock.New(t)
 	rdnsQuerier := fxutil.Test[rdnsquerier.Component](t, rdnsquerierfxmock.MockModule())
 
-	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier)
+	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier, nil)
 
 	now := time.Unix(1681295467, 0)
 	flows := []*common.Flow{
@@ -1183,7 +1252,7 @@ func TestFlowAggregator_getSequenceDelta(t *testing.T) {
 				AggregatorPortRollupThreshold:          10,
 				AggregatorRollupTrackerRefreshInterval: 3600,
 			}
-			agg := NewFlowAggregator(sender, nil, &conf, "my-hostname", logger, rdnsQuerier)
+			agg := NewFlowAggregator(sender, nil, &conf, "my-hostname", logger, rdnsQuerier, nil)
 			for roundNum, testRound := range tt.rounds {
 				assert.Equal(t, testRound.expectedSequenceDelta, agg.getSequenceDelta(testRound.flowsToFlush), fmt.Sprintf("Test Round %d", roundNum))
 			}
@@ -38,6 +38,7 @@ func buildPayload(aggFlow *common.Flow, hostname string, flushTime time.Time) pa
 			Mac:                format.MacAddress(aggFlow.SrcMac),
 			Mask:               format.CIDR(aggFlow.SrcAddr, aggFlow.SrcMask),
 			ReverseDNSHostname: aggFlow.SrcReverseDNSHostname,
+			GeoIP:              aggFlow.SrcGeoIP,
 		},
 		Destination: payload.Endpoint{
 			IP:                 format.IPAddr(aggFlow.DstAddr),
@@ -45,6 +46,7 @@ func buildPayload(aggFlow *common.Flow, hostname string, flushTime time.Time) pa
 			Mac:                format.MacAddress(aggFlow.DstMac),
 			Mask:               format.CIDR(aggFlow.DstAddr, aggFlow.DstMask),
 			ReverseDNSHostname: aggFlow.DstReverseDNSHostname,
+			GeoIP:              aggFlow.DstGeoIP,
 		},
 		Ingress: payload.ObservationPoint{
 			Interface: payload.Interface{
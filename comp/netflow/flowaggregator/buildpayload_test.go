@@ -54,6 +54,17 @@ func Test_Endpoint_reverseDNS(t *testing.T) {
 			},
 			expected: "{\"ip\":\"192.168.0.1\",\"port\":\"80\",\"mac\":\"00:00:00:00:00:01\",\"mask\":\"128.0.0.0/1\",\"reverse_dns_hostname\":\"test_hostname\"}",
 		},
+		{
+			name: "geoip",
+			endpoint: payload.Endpoint{
+				IP:    "8.8.8.8",
+				Port:  "53",
+				Mac:   "00:00:00:00:00:01",
+				Mask:  "8.8.8.0/24",
+				GeoIP: &payload.GeoIP{CountryISOCode: "US", CountryName: "United States", ASN: 15169, ASOrganization: "GOOGLE"},
+			},
+			expected: "{\"ip\":\"8.8.8.8\",\"port\":\"53\",\"mac\":\"00:00:00:00:00:01\",\"mask\":\"8.8.8.0/24\",\"geoip\":{\"country_iso_code\":\"US\",\"country_name\":\"United States\",\"asn\":15169,\"as_org\":\"GOOGLE\"}}",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
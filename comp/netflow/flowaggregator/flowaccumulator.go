@@ -11,6 +11,7 @@ import (
 
 	log "github.com/DataDog/datadog-agent/comp/core/log/def"
 	"github.com/DataDog/datadog-agent/comp/netflow/common"
+	"github.com/DataDog/datadog-agent/comp/netflow/geoip"
 	"github.com/DataDog/datadog-agent/comp/netflow/portrollup"
 	rdnsquerier "github.com/DataDog/datadog-agent/comp/rdnsquerier/def"
 	"go.uber.org/atomic"
@@ -43,6 +44,8 @@ type flowAccumulator struct {
 
 	logger      log.Component
 	rdnsQuerier rdnsquerier.Component
+	// geoIP is nil when GeoIP enrichment is disabled
+	geoIP *geoip.Enricher
 }
 
 func newFlowContext(flow *common.Flow) flowContext {
@@ -53,7 +56,7 @@ func newFlowContext(flow *common.Flow) flowContext {
 	}
 }
 
-func newFlowAccumulator(aggregatorFlushInterval time.Duration, aggregatorFlowContextTTL time.Duration, portRollupThreshold int, portRollupDisabled bool, logger log.Component, rdnsQuerier rdnsquerier.Component) *flowAccumulator {
+func newFlowAccumulator(aggregatorFlushInterval time.Duration, aggregatorFlowContextTTL time.Duration, portRollupThreshold int, portRollupDisabled bool, logger log.Component, rdnsQuerier rdnsquerier.Component, geoIP *geoip.Enricher) *flowAccumulator {
 	return &flowAccumulator{
 		flows:                  make(map[uint64]flowContext),
 		flowFlushInterval:      aggregatorFlushInterval,
@@ -64,6 +67,7 @@ func newFlowAccumulator(aggregatorFlushInterval time.Duration, aggregatorFlowCon
 		hashCollisionFlowCount: atomic.NewUint64(0),
 		logger:                 logger,
 		rdnsQuerier:            rdnsQuerier,
+		geoIP:                  geoIP,
 	}
 }
 
@@ -125,12 +129,14 @@ func (f *flowAccumulator) add(flowToAdd *common.Flow) {
 	aggHash := flowToAdd.AggregationHash()
 	aggFlow, ok := f.flows[aggHash]
 	if !ok {
+		f.addGeoIPEnrichment(flowToAdd)
 		f.flows[aggHash] = newFlowContext(flowToAdd)
 		f.addRDNSEnrichment(aggHash, flowToAdd.SrcAddr, flowToAdd.DstAddr)
 		return
 	}
 	if aggFlow.flow == nil {
 		// flowToAdd is for the same hash as an aggregated flow that has been flushed
+		f.addGeoIPEnrichment(flowToAdd)
 		aggFlow.flow = flowToAdd
 		f.addRDNSEnrichment(aggHash, flowToAdd.SrcAddr, flowToAdd.DstAddr)
 	} else {
@@ -233,6 +239,15 @@ func (f *flowAccumulator) addRDNSEnrichment(aggHash uint64, srcAddr []byte, dstA
 	}
 }
 
+// addGeoIPEnrichment sets the geolocation and autonomous system of the flow endpoints, lookups are cached per IP
+func (f *flowAccumulator) addGeoIPEnrichment(flow *common.Flow) {
+	if f.geoIP == nil {
+		return
+	}
+	flow.SrcGeoIP = f.geoIP.Lookup(flow.SrcAddr)
+	flow.DstGeoIP = f.geoIP.Lookup(flow.DstAddr)
+}
+
 func (f *flowAccumulator) getFlowContextCount() int {
 	f.flowsMutex.Lock()
 	defer f.flowsMutex.Unlock()
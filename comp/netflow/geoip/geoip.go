@@ -0,0 +1,221 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+// Package geoip enriches flows with the geolocation and autonomous system of their public IPs,
+// read from local databases in the MaxMind DB format (GeoIP2/GeoLite2 City, Country and ASN).
+package geoip
+
+import (
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	"github.com/DataDog/datadog-agent/comp/netflow/config"
+	"github.com/DataDog/datadog-agent/comp/netflow/payload"
+)
+
+// Enricher looks up the geolocation and autonomous system of IPs, caching the results per IP.
+// The databases are reloaded when their files are replaced.
+type Enricher struct {
+	location *database
+	asn      *database
+	// mutex protects the readers of the databases, which are swapped on reload
+	mutex sync.RWMutex
+
+	cache          *lru.Cache[string, *payload.GeoIP]
+	reloadInterval time.Duration
+	stopChan       chan struct{}
+	stopOnce       sync.Once
+	logger         log.Component
+}
+
+// database is a database file and the state of the file it was loaded from
+type database struct {
+	path    string
+	reader  *mmdbReader
+	modTime time.Time
+	size    int64
+}
+
+// NewEnricher loads the databases configured for the enrichment
+func NewEnricher(conf config.GeoIPConfig, logger log.Component) (*Enricher, error) {
+	if conf.LocationDBPath == "" && conf.ASNDBPath == "" {
+		return nil, errors.New("no GeoIP database configured, set location_db_path or asn_db_path")
+	}
+	cache, err := lru.New[string, *payload.GeoIP](conf.CacheSize)
+	if err != nil {
+		return nil, err
+	}
+	enricher := &Enricher{
+		cache:          cache,
+		reloadInterval: time.Duration(conf.ReloadInterval) * time.Second,
+		stopChan:       make(chan struct{}),
+		logger:         logger,
+	}
+	if conf.LocationDBPath != "" {
+		if enricher.location, err = loadDatabase(conf.LocationDBPath); err != nil {
+			return nil, err
+		}
+		logger.Infof("Loaded GeoIP location database %s (%s)", conf.LocationDBPath, enricher.location.reader.metadata.databaseType)
+	}
+	if conf.ASNDBPath != "" {
+		if enricher.asn, err = loadDatabase(conf.ASNDBPath); err != nil {
+			return nil, err
+		}
+		logger.Infof("Loaded GeoIP ASN database %s (%s)", conf.ASNDBPath, enricher.asn.reader.metadata.databaseType)
+	}
+	return enricher, nil
+}
+
+func loadDatabase(path string) (*database, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := openMMDB(path)
+	if err != nil {
+		return nil, err
+	}
+	return &database{path: path, reader: reader, modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+// Start watches the database files and reloads them when they change, until Stop is called
+func (e *Enricher) Start() {
+	if e.reloadInterval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(e.reloadInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-e.stopChan:
+				return
+			case <-ticker.C:
+				e.reloadChangedDatabases()
+			}
+		}
+	}()
+}
+
+// Stop stops watching the database files
+func (e *Enricher) Stop() {
+	e.stopOnce.Do(func() {
+		close(e.stopChan)
+	})
+}
+
+// reloadChangedDatabases reloads the databases whose file was modified or replaced, keeping the current
+// database if the new file can't be read, e.g. while it is being written
+func (e *Enricher) reloadChangedDatabases() {
+	reloaded := false
+	for _, db := range []*database{e.location, e.asn} {
+		if db == nil {
+			continue
+		}
+		info, err := os.Stat(db.path)
+		if err != nil {
+			e.logger.Debugf("Unable to check GeoIP database %s: %s", db.path, err)
+			continue
+		}
+		if info.ModTime().Equal(db.modTime) && info.Size() == db.size {
+			continue
+		}
+		newDB, err := loadDatabase(db.path)
+		if err != nil {
+			e.logger.Warnf("Unable to reload GeoIP database %s, keeping the previous version: %s", db.path, err)
+			continue
+		}
+		e.mutex.Lock()
+		db.reader, db.modTime, db.size = newDB.reader, newDB.modTime, newDB.size
+		e.mutex.Unlock()
+		e.logger.Infof("Reloaded GeoIP database %s", db.path)
+		reloaded = true
+	}
+	if reloaded {
+		e.cache.Purge()
+	}
+}
+
+// Lookup returns the geolocation and autonomous system of an IP, or nil if it isn't a public IP
+// or isn't in the databases
+func (e *Enricher) Lookup(ipBytes []byte) *payload.GeoIP {
+	ip := net.IP(ipBytes)
+	if !isPublicIP(ip) {
+		return nil
+	}
+	key := string(ipBytes)
+	if result, ok := e.cache.Get(key); ok {
+		return result
+	}
+
+	result := &payload.GeoIP{}
+	e.mutex.RLock()
+	if e.location != nil {
+		record, err := e.location.reader.lookup(ip)
+		if err != nil {
+			e.logger.Debugf("Error looking up IP %s in GeoIP database %s: %s", ip, e.location.path, err)
+		}
+		setLocation(result, record)
+	}
+	if e.asn != nil {
+		record, err := e.asn.reader.lookup(ip)
+		if err != nil {
+			e.logger.Debugf("Error looking up IP %s in GeoIP database %s: %s", ip, e.asn.path, err)
+		}
+		setASN(result, record)
+	}
+	e.mutex.RUnlock()
+
+	if *result == (payload.GeoIP{}) {
+		result = nil
+	}
+	e.cache.Add(key, result)
+	return result
+}
+
+// setLocation sets the fields of a record of a City or Country database, see
+// https://dev.maxmind.com/geoip/docs/databases/city-and-country
+func setLocation(result *payload.GeoIP, record any) {
+	fields, _ := record.(map[string]any)
+	country, ok := fields["country"].(map[string]any)
+	if !ok {
+		// IPs of anycast networks and satellite providers only have a registered country
+		country, _ = fields["registered_country"].(map[string]any)
+	}
+	result.CountryISOCode, _ = country["iso_code"].(string)
+	result.CountryName = englishName(country)
+	city, _ := fields["city"].(map[string]any)
+	result.City = englishName(city)
+}
+
+// setASN sets the fields of a record of an ASN database
+func setASN(result *payload.GeoIP, record any) {
+	fields, _ := record.(map[string]any)
+	if number, ok := toUint(fields["autonomous_system_number"]); ok {
+		result.ASN = uint32(number)
+	}
+	result.ASOrganization, _ = fields["autonomous_system_organization"].(string)
+}
+
+func englishName(fields map[string]any) string {
+	names, _ := fields["names"].(map[string]any)
+	name, _ := names["en"].(string)
+	return name
+}
+
+// isPublicIP returns whether an IP is routable on the internet, the databases don't cover other IPs
+func isPublicIP(ip net.IP) bool {
+	if len(ip) != net.IPv4len && len(ip) != net.IPv6len {
+		return false
+	}
+	return !(ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsMulticast() || ip.IsUnspecified())
+}
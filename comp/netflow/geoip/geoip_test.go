@@ -0,0 +1,121 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package geoip
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	logmock "github.com/DataDog/datadog-agent/comp/core/log/mock"
+	"github.com/DataDog/datadog-agent/comp/netflow/config"
+	"github.com/DataDog/datadog-agent/comp/netflow/payload"
+)
+
+func testCityRecord(isoCode string, country string, city string) map[string]any {
+	return map[string]any{
+		"city":      map[string]any{"geoname_id": uint64(1), "names": map[string]any{"en": city, "fr": city + "-fr"}},
+		"continent": map[string]any{"code": "NA", "names": map[string]any{"en": "North America"}},
+		"country":   map[string]any{"iso_code": isoCode, "names": map[string]any{"en": country}},
+		"location":  map[string]any{"latitude": 37.751, "longitude": -97.822},
+	}
+}
+
+func writeTestDatabases(t *testing.T, dir string, cityName string) config.GeoIPConfig {
+	city := buildTestMMDB(t, 6, 28, "GeoLite2-City", []testNetwork{
+		{"8.8.8.0/24", testCityRecord("US", "United States", cityName)},
+		{"2001:4860::/32", testCityRecord("US", "United States", cityName)},
+		// anycast networks only have a registered country
+		{"1.1.1.0/24", map[string]any{"registered_country": map[string]any{"iso_code": "AU", "names": map[string]any{"en": "Australia"}}}},
+	})
+	asn := buildTestMMDB(t, 6, 24, "GeoLite2-ASN", []testNetwork{
+		{"8.8.8.0/24", map[string]any{"autonomous_system_number": uint64(15169), "autonomous_system_organization": "GOOGLE"}},
+		{"9.9.9.0/24", map[string]any{"autonomous_system_number": uint64(19281), "autonomous_system_organization": "QUAD9-AS-1"}},
+	})
+	conf := config.GeoIPConfig{
+		Enabled:        true,
+		LocationDBPath: filepath.Join(dir, "GeoLite2-City.mmdb"),
+		ASNDBPath:      filepath.Join(dir, "GeoLite2-ASN.mmdb"),
+		CacheSize:      10,
+		ReloadInterval: 60,
+	}
+	require.NoError(t, os.WriteFile(conf.LocationDBPath, city, 0o644))
+	require.NoError(t, os.WriteFile(conf.ASNDBPath, asn, 0o644))
+	return conf
+}
+
+func TestEnricherLookup(t *testing.T) {
+	conf := writeTestDatabases(t, t.TempDir(), "Mountain View")
+	enricher, err := NewEnricher(conf, logmock.New(t))
+	require.NoError(t, err)
+
+	google := &payload.GeoIP{CountryISOCode: "US", CountryName: "United States", City: "Mountain View", ASN: 15169, ASOrganization: "GOOGLE"}
+	assert.Equal(t, google, enricher.Lookup(net.ParseIP("8.8.8.8").To4()))
+	assert.Equal(t, &payload.GeoIP{CountryISOCode: "US", CountryName: "United States", City: "Mountain View"},
+		enricher.Lookup(net.ParseIP("2001:4860::8888")))
+	assert.Equal(t, &payload.GeoIP{CountryISOCode: "AU", CountryName: "Australia"}, enricher.Lookup(net.ParseIP("1.1.1.1").To4()))
+	assert.Equal(t, &payload.GeoIP{ASN: 19281, ASOrganization: "QUAD9-AS-1"}, enricher.Lookup(net.ParseIP("9.9.9.9").To4()))
+
+	// IPs missing from the databases, private IPs and invalid IPs aren't enriched
+	assert.Nil(t, enricher.Lookup(net.ParseIP("4.4.4.4").To4()))
+	assert.Nil(t, enricher.Lookup(net.ParseIP("10.0.0.1").To4()))
+	assert.Nil(t, enricher.Lookup(net.ParseIP("fe80::1")))
+	assert.Nil(t, enricher.Lookup([]byte{1, 2, 3}))
+	assert.Nil(t, enricher.Lookup(nil))
+
+	// lookups are cached per IP
+	assert.Equal(t, 5, enricher.cache.Len())
+	assert.Same(t, enricher.Lookup(net.ParseIP("8.8.8.8").To4()), enricher.Lookup(net.ParseIP("8.8.8.8").To4()))
+}
+
+func TestEnricherReload(t *testing.T) {
+	dir := t.TempDir()
+	conf := writeTestDatabases(t, dir, "Mountain View")
+	enricher, err := NewEnricher(conf, logmock.New(t))
+	require.NoError(t, err)
+
+	ip := net.ParseIP("8.8.8.8").To4()
+	assert.Equal(t, "Mountain View", enricher.Lookup(ip).City)
+
+	// unchanged files aren't reloaded
+	reader := enricher.location.reader
+	enricher.reloadChangedDatabases()
+	assert.Same(t, reader, enricher.location.reader)
+	assert.Equal(t, 1, enricher.cache.Len())
+
+	// replaced files are reloaded and the cache is cleared
+	writeTestDatabases(t, dir, "Mountain View, CA")
+	enricher.reloadChangedDatabases()
+	assert.Zero(t, enricher.cache.Len())
+	assert.Equal(t, "Mountain View, CA", enricher.Lookup(ip).City)
+
+	// invalid files keep the previous version
+	require.NoError(t, os.WriteFile(conf.LocationDBPath, []byte("being written"), 0o644))
+	enricher.reloadChangedDatabases()
+	assert.Equal(t, "Mountain View, CA", enricher.Lookup(ip).City)
+}
+
+func TestNewEnricher_Errors(t *testing.T) {
+	_, err := NewEnricher(config.GeoIPConfig{Enabled: true, CacheSize: 10}, logmock.New(t))
+	assert.EqualError(t, err, "no GeoIP database configured, set location_db_path or asn_db_path")
+
+	_, err = NewEnricher(config.GeoIPConfig{Enabled: true, ASNDBPath: filepath.Join(t.TempDir(), "missing.mmdb"), CacheSize: 10}, logmock.New(t))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestEnricherStartStop(t *testing.T) {
+	conf := writeTestDatabases(t, t.TempDir(), "Mountain View")
+	enricher, err := NewEnricher(conf, logmock.New(t))
+	require.NoError(t, err)
+	enricher.Start()
+	enricher.Stop()
+	// stopping twice is safe
+	enricher.Stop()
+}
@@ -0,0 +1,352 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+)
+
+// mmdbReader reads databases in the MaxMind DB format, see https://maxmind.github.io/MaxMind-DB/.
+// The whole file is loaded in memory.
+type mmdbReader struct {
+	buffer       []byte
+	metadata     mmdbMetadata
+	nodeByteSize uint
+	// treeSize is the size of the search tree, the data section starts 16 bytes after it
+	treeSize  uint
+	ipv4Start uint
+}
+
+// mmdbMetadata contains the metadata fields used to read a database
+type mmdbMetadata struct {
+	nodeCount    uint
+	recordSize   uint
+	ipVersion    uint
+	databaseType string
+}
+
+var mmdbMetadataMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+const (
+	mmdbDataSectionSeparatorSize = 16
+	// mmdbMaxDepth bounds the nesting of maps and arrays, and the pointers followed while decoding
+	mmdbMaxDepth = 32
+)
+
+// mmdb data types
+const (
+	mmdbExtended = iota
+	mmdbPointer
+	mmdbString
+	mmdbDouble
+	mmdbBytes
+	mmdbUint16
+	mmdbUint32
+	mmdbMap
+	mmdbInt32
+	mmdbUint64
+	mmdbUint128
+	mmdbArray
+	mmdbContainer
+	mmdbEndMarker
+	mmdbBool
+	mmdbFloat
+)
+
+// openMMDB loads a database file
+func openMMDB(path string) (*mmdbReader, error) {
+	buffer, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return newMMDBReader(buffer)
+}
+
+func newMMDBReader(buffer []byte) (*mmdbReader, error) {
+	markerIndex := bytes.LastIndex(buffer, mmdbMetadataMarker)
+	if markerIndex < 0 {
+		return nil, errors.New("invalid MaxMind DB file: metadata not found")
+	}
+	metadataStart := uint(markerIndex + len(mmdbMetadataMarker))
+	metadataDecoder := &mmdbDecoder{buffer: buffer[metadataStart:]}
+	value, _, err := metadataDecoder.decode(0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MaxMind DB metadata: %w", err)
+	}
+	fields, ok := value.(map[string]any)
+	if !ok {
+		return nil, errors.New("invalid MaxMind DB metadata: not a map")
+	}
+
+	var metadata mmdbMetadata
+	metadata.nodeCount, _ = toUint(fields["node_count"])
+	metadata.recordSize, _ = toUint(fields["record_size"])
+	metadata.ipVersion, _ = toUint(fields["ip_version"])
+	metadata.databaseType, _ = fields["database_type"].(string)
+	if metadata.recordSize != 24 && metadata.recordSize != 28 && metadata.recordSize != 32 {
+		return nil, fmt.Errorf("unsupported MaxMind DB record size %d", metadata.recordSize)
+	}
+	if metadata.ipVersion != 4 && metadata.ipVersion != 6 {
+		return nil, fmt.Errorf("unsupported MaxMind DB IP version %d", metadata.ipVersion)
+	}
+
+	reader := &mmdbReader{
+		buffer:       buffer,
+		metadata:     metadata,
+		nodeByteSize: metadata.recordSize / 4,
+	}
+	reader.treeSize = metadata.nodeCount * reader.nodeByteSize
+	if reader.treeSize+mmdbDataSectionSeparatorSize > uint(markerIndex) {
+		return nil, errors.New("invalid MaxMind DB file: the search tree exceeds the file size")
+	}
+
+	// IPv4 addresses are stored in IPv6 databases as ::a.b.c.d, find the node of ::/96 once
+	if metadata.ipVersion == 6 {
+		node := uint(0)
+		for i := 0; i < 96 && node < metadata.nodeCount; i++ {
+			node = reader.readRecord(node, 0)
+		}
+		reader.ipv4Start = node
+	}
+	return reader, nil
+}
+
+// lookup returns the record of the network containing an IP, or nil if the IP isn't in the database
+func (r *mmdbReader) lookup(ip net.IP) (any, error) {
+	node := uint(0)
+	bitCount := 128
+	if ipv4 := ip.To4(); ipv4 != nil {
+		ip = ipv4
+		bitCount = 32
+		node = r.ipv4Start
+	} else if r.metadata.ipVersion == 4 {
+		return nil, nil
+	}
+
+	nodeCount := r.metadata.nodeCount
+	for i := 0; i < bitCount && node < nodeCount; i++ {
+		bit := (ip[i>>3] >> (7 - uint(i&7))) & 1
+		node = r.readRecord(node, uint(bit))
+	}
+	if node == nodeCount {
+		// not found
+		return nil, nil
+	}
+	if node < nodeCount {
+		return nil, errors.New("invalid MaxMind DB file: the search tree is too deep")
+	}
+
+	offset := node - nodeCount - mmdbDataSectionSeparatorSize
+	decoder := &mmdbDecoder{buffer: r.buffer[r.treeSize+mmdbDataSectionSeparatorSize:]}
+	value, _, err := decoder.decode(offset, 0)
+	return value, err
+}
+
+// readRecord reads the left (0) or right (1) record of a node of the search tree
+func (r *mmdbReader) readRecord(node uint, bit uint) uint {
+	b := r.buffer[node*r.nodeByteSize : (node+1)*r.nodeByteSize]
+	switch r.metadata.recordSize {
+	case 24:
+		b = b[bit*3:]
+		return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+	case 28:
+		if bit == 0 {
+			return uint(b[3]&0xF0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return uint(b[3]&0x0F)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6])
+	default:
+		return uint(binary.BigEndian.Uint32(b[bit*4:]))
+	}
+}
+
+// mmdbDecoder decodes the data section of a database, pointers are relative to the start of its buffer
+type mmdbDecoder struct {
+	buffer []byte
+}
+
+// decode decodes the value at an offset and returns it with the offset following it
+func (d *mmdbDecoder) decode(offset uint, depth int) (any, uint, error) {
+	if depth > mmdbMaxDepth {
+		return nil, 0, errors.New("maximum data depth exceeded")
+	}
+	dataType, size, offset, err := d.decodeControl(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if dataType == mmdbPointer {
+		pointer, next, err := d.decodePointer(size, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		value, _, err := d.decode(pointer, depth+1)
+		return value, next, err
+	}
+
+	switch dataType {
+	case mmdbMap:
+		// each entry takes at least two bytes, a key and a value, checked before allocating the map
+		if size > (uint(len(d.buffer))-offset)/2 {
+			return nil, 0, fmt.Errorf("map size %d exceeds the remaining data", size)
+		}
+		value := make(map[string]any, size)
+		for i := uint(0); i < size; i++ {
+			var key, item any
+			key, offset, err = d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			keyString, ok := key.(string)
+			if !ok {
+				return nil, 0, errors.New("map key is not a string")
+			}
+			item, offset, err = d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			value[keyString] = item
+		}
+		return value, offset, nil
+	case mmdbArray:
+		// each item takes at least one byte, checked before allocating the array
+		if size > uint(len(d.buffer))-offset {
+			return nil, 0, fmt.Errorf("array size %d exceeds the remaining data", size)
+		}
+		value := make([]any, 0, size)
+		for i := uint(0); i < size; i++ {
+			var item any
+			item, offset, err = d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			value = append(value, item)
+		}
+		return value, offset, nil
+	case mmdbBool:
+		return size != 0, offset, nil
+	}
+
+	if offset+size > uint(len(d.buffer)) {
+		return nil, 0, errors.New("unexpected end of data")
+	}
+	content := d.buffer[offset : offset+size]
+	next := offset + size
+	switch dataType {
+	case mmdbString:
+		return string(content), next, nil
+	case mmdbBytes:
+		return bytes.Clone(content), next, nil
+	case mmdbDouble:
+		if size != 8 {
+			return nil, 0, fmt.Errorf("invalid double size %d", size)
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(content)), next, nil
+	case mmdbFloat:
+		if size != 4 {
+			return nil, 0, fmt.Errorf("invalid float size %d", size)
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(content))), next, nil
+	case mmdbUint16, mmdbUint32, mmdbUint64:
+		if size > 8 {
+			return nil, 0, fmt.Errorf("invalid unsigned integer size %d", size)
+		}
+		var value uint64
+		for _, b := range content {
+			value = value<<8 | uint64(b)
+		}
+		return value, next, nil
+	case mmdbInt32:
+		if size > 4 {
+			return nil, 0, fmt.Errorf("invalid int32 size %d", size)
+		}
+		var value uint32
+		for _, b := range content {
+			value = value<<8 | uint32(b)
+		}
+		return int64(int32(value)), next, nil
+	case mmdbUint128:
+		// too large for the fields we read, kept as bytes
+		return bytes.Clone(content), next, nil
+	default:
+		return nil, 0, fmt.Errorf("unsupported data type %d", dataType)
+	}
+}
+
+// decodeControl decodes the control byte of a value, returning its type, its size and the offset of its content
+func (d *mmdbDecoder) decodeControl(offset uint) (int, uint, uint, error) {
+	if offset >= uint(len(d.buffer)) {
+		return 0, 0, 0, errors.New("unexpected end of data")
+	}
+	control := d.buffer[offset]
+	offset++
+	dataType := int(control >> 5)
+	if dataType == mmdbExtended {
+		if offset >= uint(len(d.buffer)) {
+			return 0, 0, 0, errors.New("unexpected end of data")
+		}
+		dataType = 7 + int(d.buffer[offset])
+		offset++
+	}
+	if dataType == mmdbPointer {
+		// the size bits of pointers are decoded by decodePointer
+		return dataType, uint(control & 0x1F), offset, nil
+	}
+
+	size := uint(control & 0x1F)
+	if size >= 29 {
+		extraBytes := size - 28
+		if offset+extraBytes > uint(len(d.buffer)) {
+			return 0, 0, 0, errors.New("unexpected end of data")
+		}
+		var extra uint
+		for _, b := range d.buffer[offset : offset+extraBytes] {
+			extra = extra<<8 | uint(b)
+		}
+		offset += extraBytes
+		switch size {
+		case 29:
+			size = 29 + extra
+		case 30:
+			size = 285 + extra
+		default:
+			size = 65821 + extra
+		}
+	}
+	return dataType, size, offset, nil
+}
+
+// decodePointer decodes a pointer from the size bits of its control byte and the bytes following it
+func (d *mmdbDecoder) decodePointer(sizeBits uint, offset uint) (uint, uint, error) {
+	pointerSize := (sizeBits >> 3) + 1
+	if offset+pointerSize > uint(len(d.buffer)) {
+		return 0, 0, errors.New("unexpected end of data")
+	}
+	var pointer uint
+	if pointerSize != 4 {
+		pointer = sizeBits & 0x7
+	}
+	for _, b := range d.buffer[offset : offset+pointerSize] {
+		pointer = pointer<<8 | uint(b)
+	}
+	switch pointerSize {
+	case 2:
+		pointer += 2048
+	case 3:
+		pointer += 526336
+	}
+	return pointer, offset + pointerSize, nil
+}
+
+func toUint(value any) (uint, bool) {
+	number, ok := value.(uint64)
+	return uint(number), ok
+}
@@ -0,0 +1,277 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package geoip
+
+import (
+	"encoding/binary"
+	"math"
+	"net"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testNetwork struct {
+	cidr   string
+	record any
+}
+
+// buildTestMMDB writes a database in the MaxMind DB format with the given networks
+func buildTestMMDB(t *testing.T, ipVersion int, recordSize int, databaseType string, networks []testNetwork) []byte {
+	const empty = -1
+	// nodes hold the index of the next node, empty, or -2-<index of a network>
+	nodes := [][2]int{{empty, empty}}
+	for networkIndex, network := range networks {
+		ip, ipNet, err := net.ParseCIDR(network.cidr)
+		require.NoError(t, err)
+		prefixLen, _ := ipNet.Mask.Size()
+		if ip.To4() != nil {
+			ip = ip.To4()
+			if ipVersion == 6 {
+				ip = append(make(net.IP, 12), ip...)
+				prefixLen += 96
+			}
+		}
+		node := 0
+		for i := 0; i < prefixLen; i++ {
+			bit := (ip[i/8] >> (7 - uint(i%8))) & 1
+			if i == prefixLen-1 {
+				nodes[node][bit] = -2 - networkIndex
+				break
+			}
+			if nodes[node][bit] < 0 {
+				nodes = append(nodes, [2]int{empty, empty})
+				nodes[node][bit] = len(nodes) - 1
+			}
+			node = nodes[node][bit]
+		}
+	}
+
+	nodeCount := len(nodes)
+	var data []byte
+	dataOffsets := make([]int, len(networks))
+	for i, network := range networks {
+		dataOffsets[i] = len(data)
+		data = appendTestValue(data, network.record)
+	}
+
+	var tree []byte
+	for _, node := range nodes {
+		var records [2]uint32
+		for bit, value := range node {
+			switch {
+			case value == empty:
+				records[bit] = uint32(nodeCount)
+			case value >= 0:
+				records[bit] = uint32(value)
+			default:
+				records[bit] = uint32(nodeCount + mmdbDataSectionSeparatorSize + dataOffsets[-2-value])
+			}
+		}
+		switch recordSize {
+		case 24:
+			tree = append(tree, byte(records[0]>>16), byte(records[0]>>8), byte(records[0]),
+				byte(records[1]>>16), byte(records[1]>>8), byte(records[1]))
+		case 28:
+			tree = append(tree, byte(records[0]>>16), byte(records[0]>>8), byte(records[0]),
+				byte((records[0]>>20)&0xF0|(records[1]>>24)&0x0F),
+				byte(records[1]>>16), byte(records[1]>>8), byte(records[1]))
+		case 32:
+			tree = binary.BigEndian.AppendUint32(tree, records[0])
+			tree = binary.BigEndian.AppendUint32(tree, records[1])
+		}
+	}
+
+	buffer := append(tree, make([]byte, mmdbDataSectionSeparatorSize)...)
+	buffer = append(buffer, data...)
+	buffer = append(buffer, mmdbMetadataMarker...)
+	return appendTestValue(buffer, map[string]any{
+		"node_count":                  uint64(nodeCount),
+		"record_size":                 uint64(recordSize),
+		"ip_version":                  uint64(ipVersion),
+		"database_type":               databaseType,
+		"binary_format_major_version": uint64(2),
+		"languages":                   []any{"en"},
+	})
+}
+
+func appendTestControl(buffer []byte, dataType int, size int) []byte {
+	var sizeBits byte
+	var extra []byte
+	switch {
+	case size < 29:
+		sizeBits = byte(size)
+	case size < 285:
+		sizeBits, extra = 29, []byte{byte(size - 29)}
+	case size < 65821:
+		sizeBits, extra = 30, []byte{byte((size - 285) >> 8), byte(size - 285)}
+	default:
+		size -= 65821
+		sizeBits, extra = 31, []byte{byte(size >> 16), byte(size >> 8), byte(size)}
+	}
+	if dataType > 7 {
+		buffer = append(buffer, sizeBits, byte(dataType-7))
+	} else {
+		buffer = append(buffer, byte(dataType<<5)|sizeBits)
+	}
+	return append(buffer, extra...)
+}
+
+func appendTestValue(buffer []byte, value any) []byte {
+	switch value := value.(type) {
+	case string:
+		return append(appendTestControl(buffer, mmdbString, len(value)), value...)
+	case uint64:
+		var content []byte
+		for v := value; v > 0; v >>= 8 {
+			content = append([]byte{byte(v)}, content...)
+		}
+		return append(appendTestControl(buffer, mmdbUint64, len(content)), content...)
+	case float64:
+		buffer = appendTestControl(buffer, mmdbDouble, 8)
+		return binary.BigEndian.AppendUint64(buffer, math.Float64bits(value))
+	case bool:
+		size := 0
+		if value {
+			size = 1
+		}
+		return appendTestControl(buffer, mmdbBool, size)
+	case []any:
+		buffer = appendTestControl(buffer, mmdbArray, len(value))
+		for _, item := range value {
+			buffer = appendTestValue(buffer, item)
+		}
+		return buffer
+	case map[string]any:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		buffer = appendTestControl(buffer, mmdbMap, len(value))
+		for _, key := range keys {
+			buffer = appendTestValue(buffer, key)
+			buffer = appendTestValue(buffer, value[key])
+		}
+		return buffer
+	default:
+		panic("unsupported test value")
+	}
+}
+
+func TestMMDBLookup(t *testing.T) {
+	networks := []testNetwork{
+		{"8.8.8.0/24", map[string]any{"name": "google", "ratio": 0.5, "anycast": true}},
+		{"1.0.0.0/8", map[string]any{"name": "apnic", "tags": []any{"a", "b"}}},
+		{"2001:db8::/32", map[string]any{"name": "documentation", "long": strings.Repeat("x", 300)}},
+	}
+	for _, recordSize := range []int{24, 28, 32} {
+		reader, err := newMMDBReader(buildTestMMDB(t, 6, recordSize, "Test-DB", networks))
+		require.NoError(t, err)
+		assert.Equal(t, "Test-DB", reader.metadata.databaseType)
+		assert.Equal(t, uint(recordSize), reader.metadata.recordSize)
+
+		record, err := reader.lookup(net.ParseIP("8.8.8.8"))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"name": "google", "ratio": 0.5, "anycast": true}, record)
+
+		record, err = reader.lookup(net.ParseIP("1.2.3.4").To4())
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"name": "apnic", "tags": []any{"a", "b"}}, record)
+
+		record, err = reader.lookup(net.ParseIP("2001:db8::1"))
+		require.NoError(t, err)
+		assert.Equal(t, strings.Repeat("x", 300), record.(map[string]any)["long"])
+
+		for _, ip := range []string{"8.8.9.8", "2.0.0.1", "2001:db9::1"} {
+			record, err = reader.lookup(net.ParseIP(ip))
+			require.NoError(t, err)
+			assert.Nil(t, record, ip)
+		}
+	}
+}
+
+func TestMMDBLookup_IPv4Database(t *testing.T) {
+	reader, err := newMMDBReader(buildTestMMDB(t, 4, 24, "Test-DB", []testNetwork{
+		{"8.8.8.0/24", map[string]any{"name": "google"}},
+	}))
+	require.NoError(t, err)
+
+	record, err := reader.lookup(net.ParseIP("8.8.8.8"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"name": "google"}, record)
+
+	record, err = reader.lookup(net.ParseIP("2001:db8::1"))
+	require.NoError(t, err)
+	assert.Nil(t, record)
+}
+
+func TestMMDBDecoder(t *testing.T) {
+	decoder := &mmdbDecoder{buffer: []byte{
+		// offset 0: "foo"
+		0x43, 'f', 'o', 'o',
+		// offset 4: a map of two keys, with a key and a value pointing to "foo"
+		0xE2,
+		0x20, 0x00, 0x43, 'k', 'e', 'y',
+		0x43, 'b', 'a', 'r', 0x20, 0x00,
+		// offset 17: extended types, int32 -1, uint128 and an end marker
+		0x04, 0x01, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x02, 0x03, 0x01, 0x02,
+		0x00, 0x06,
+	}}
+
+	value, next, err := decoder.decode(4, 0)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"foo": "key", "bar": "foo"}, value)
+	assert.Equal(t, uint(17), next)
+
+	value, next, err = decoder.decode(next, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-1), value)
+
+	value, next, err = decoder.decode(next, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x01, 0x02}, value)
+
+	_, _, err = decoder.decode(next, 0)
+	assert.EqualError(t, err, "unsupported data type 13")
+
+	// two bytes pointers start at 2048
+	_, _, err = (&mmdbDecoder{buffer: []byte{0x28, 0x00, 0x00}}).decode(0, 0)
+	assert.EqualError(t, err, "unexpected end of data")
+
+	// a pointer to itself
+	_, _, err = (&mmdbDecoder{buffer: []byte{0x20, 0x00}}).decode(0, 0)
+	assert.EqualError(t, err, "maximum data depth exceeded")
+
+	// containers larger than the data are rejected before being allocated
+	_, _, err = (&mmdbDecoder{buffer: []byte{0xFF, 0xFF, 0xFF, 0xFF}}).decode(0, 0)
+	assert.EqualError(t, err, "map size 16843036 exceeds the remaining data")
+	_, _, err = (&mmdbDecoder{buffer: []byte{0x1F, 0x04, 0xFF, 0xFF, 0xFF, 0x00}}).decode(0, 0)
+	assert.EqualError(t, err, "array size 16843036 exceeds the remaining data")
+	_, _, err = (&mmdbDecoder{buffer: []byte{0xE3, 0x41, 'a', 0x41, 'b'}}).decode(0, 0)
+	assert.EqualError(t, err, "map size 3 exceeds the remaining data")
+}
+
+func TestNewMMDBReader_Errors(t *testing.T) {
+	_, err := newMMDBReader([]byte("not a database"))
+	assert.EqualError(t, err, "invalid MaxMind DB file: metadata not found")
+
+	invalidRecordSize := appendTestValue(append([]byte{}, mmdbMetadataMarker...), map[string]any{
+		"node_count": uint64(1), "record_size": uint64(20), "ip_version": uint64(6),
+	})
+	_, err = newMMDBReader(invalidRecordSize)
+	assert.EqualError(t, err, "unsupported MaxMind DB record size 20")
+
+	truncatedTree := appendTestValue(append([]byte{}, mmdbMetadataMarker...), map[string]any{
+		"node_count": uint64(10), "record_size": uint64(24), "ip_version": uint64(6),
+	})
+	_, err = newMMDBReader(truncatedTree)
+	assert.EqualError(t, err, "invalid MaxMind DB file: the search tree exceeds the file size")
+}
@@ -18,6 +18,15 @@ type Exporter struct {
 	IP string `json:"ip"`
 }
 
+// GeoIP contains the geolocation and autonomous system of an endpoint IP
+type GeoIP struct {
+	CountryISOCode string `json:"country_iso_code,omitempty"`
+	CountryName    string `json:"country_name,omitempty"`
+	City           string `json:"city,omitempty"`
+	ASN            uint32 `json:"asn,omitempty"`
+	ASOrganization string `json:"as_org,omitempty"`
+}
+
 // Endpoint contains source or destination endpoint details
 type Endpoint struct {
 	IP                 string `json:"ip"`
@@ -25,6 +34,7 @@ type Endpoint struct {
 	Mac                string `json:"mac"`
 	Mask               string `json:"mask"`
 	ReverseDNSHostname string `json:"reverse_dns_hostname,omitempty"`
+	GeoIP              *GeoIP `json:"geoip,omitempty"`
 }
 
 // NextHop contains next hop details
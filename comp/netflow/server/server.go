@@ -21,6 +21,7 @@ import (
 	"github.com/DataDog/datadog-agent/comp/ndmtmp/forwarder"
 	nfconfig "github.com/DataDog/datadog-agent/comp/netflow/config"
 	"github.com/DataDog/datadog-agent/comp/netflow/flowaggregator"
+	"github.com/DataDog/datadog-agent/comp/netflow/geoip"
 	rdnsquerier "github.com/DataDog/datadog-agent/comp/rdnsquerier/def"
 	rdnsquerierimplnone "github.com/DataDog/datadog-agent/comp/rdnsquerier/impl-none"
 )
@@ -63,11 +64,23 @@ func newServer(lc fx.Lifecycle, deps dependencies) (provides, error) {
 		deps.Logger.Infof("Reverse DNS Enrichment is disabled for NDM NetFlow")
 	}
 
-	flowAgg := flowaggregator.NewFlowAggregator(sender, deps.Forwarder, conf, deps.Hostname.GetSafe(context.Background()), deps.Logger, rdnsQuerier)
+	// A nil enricher disables GeoIP enrichment. Databases that can't be loaded don't prevent collecting flows.
+	var geoIPEnricher *geoip.Enricher
+	if conf.Enabled && conf.GeoIP.Enabled {
+		geoIPEnricher, err = geoip.NewEnricher(conf.GeoIP, deps.Logger)
+		if err != nil {
+			deps.Logger.Errorf("GeoIP Enrichment is disabled for NDM NetFlow, unable to load databases: %s", err)
+		} else {
+			deps.Logger.Infof("GeoIP Enrichment is enabled for NDM NetFlow")
+		}
+	}
+
+	flowAgg := flowaggregator.NewFlowAggregator(sender, deps.Forwarder, conf, deps.Hostname.GetSafe(context.Background()), deps.Logger, rdnsQuerier, geoIPEnricher)
 
 	server := &Server{
 		config:  conf,
 		FlowAgg: flowAgg,
+		geoIP:   geoIPEnricher,
 		logger:  deps.Logger,
 	}
 
@@ -103,6 +116,7 @@ type Server struct {
 	config    *nfconfig.NetflowConfig
 	listeners []*netflowListener
 	FlowAgg   *flowaggregator.FlowAggregator
+	geoIP     *geoip.Enricher
 	logger    log.Component
 	running   bool
 }
@@ -114,6 +128,9 @@ func (s *Server) Start() error {
 	}
 	s.running = true
 	go s.FlowAgg.Start()
+	if s.geoIP != nil {
+		s.geoIP.Start()
+	}
 
 	if s.config.PrometheusListenerEnabled {
 		go func() {
@@ -146,6 +163,9 @@ func (s *Server) Stop() {
 		return
 	}
 	s.FlowAgg.Stop()
+	if s.geoIP != nil {
+		s.geoIP.Stop()
+	}
 
 	for _, listener := range s.listeners {
 		stopped := make(chan interface{})
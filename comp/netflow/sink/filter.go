@@ -0,0 +1,447 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package sink
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+	"github.com/DataDog/datadog-agent/comp/netflow/format"
+	"github.com/DataDog/datadog-agent/comp/netflow/portrollup"
+)
+
+// Filter matches flows against an expression combining comparisons with `and`, `or`, `not` and parentheses:
+//
+//	exporter in (10.0.0.1, 10.1.0.0/16) and (dst_port == 443 or protocol == udp) and not src_port < 1024
+//
+// The fields are:
+//   - exporter, src_ip, dst_ip and ip (either the source or the destination), compared to IPs or CIDRs with
+//     `==`, `!=` and `in`
+//   - src_port, dst_port and port (either the source or the destination), compared to port numbers with `==`,
+//     `!=`, `<`, `<=`, `>`, `>=` and `in`, ephemeral ports rolled up by the aggregator only match `*`
+//   - protocol, compared to protocol names or numbers with `==`, `!=` and `in`
+type Filter struct {
+	root filterNode
+}
+
+// ParseFilter parses a filter expression, an empty expression returns a nil filter
+func ParseFilter(expression string) (*Filter, error) {
+	tokens, err := tokenizeFilter(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter `%s`: %w", expression, err)
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	parser := &filterParser{tokens: tokens}
+	root, err := parser.parseOr()
+	if err == nil && parser.pos < len(tokens) {
+		err = fmt.Errorf("unexpected `%s`", tokens[parser.pos])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter `%s`: %w", expression, err)
+	}
+	return &Filter{root: root}, nil
+}
+
+// Match returns whether a flow matches the filter, a nil filter matches all flows
+func (f *Filter) Match(flow *common.Flow) bool {
+	if f == nil {
+		return true
+	}
+	return f.root.match(flow)
+}
+
+type filterNode interface {
+	match(flow *common.Flow) bool
+}
+
+type andNode struct{ left, right filterNode }
+
+func (n *andNode) match(flow *common.Flow) bool { return n.left.match(flow) && n.right.match(flow) }
+
+type orNode struct{ left, right filterNode }
+
+func (n *orNode) match(flow *common.Flow) bool { return n.left.match(flow) || n.right.match(flow) }
+
+type notNode struct{ node filterNode }
+
+func (n *notNode) match(flow *common.Flow) bool { return !n.node.match(flow) }
+
+// ipComparison matches when one of the IPs of a flow is in one of the networks
+type ipComparison struct {
+	ips      func(flow *common.Flow) [][]byte
+	networks []*net.IPNet
+}
+
+func (c *ipComparison) match(flow *common.Flow) bool {
+	for _, ip := range c.ips(flow) {
+		if len(ip) != net.IPv4len && len(ip) != net.IPv6len {
+			continue
+		}
+		for _, network := range c.networks {
+			if network.Contains(ip) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// portComparison matches when one of the ports of a flow compares to one of the values
+type portComparison struct {
+	ports    func(flow *common.Flow) []int32
+	operator string
+	values   []int32
+}
+
+func (c *portComparison) match(flow *common.Flow) bool {
+	for _, port := range c.ports(flow) {
+		for _, value := range c.values {
+			if comparePorts(port, c.operator, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func comparePorts(port int32, operator string, value int32) bool {
+	if operator == "==" {
+		return port == value
+	}
+	// ephemeral ports aren't ordered
+	if port == portrollup.EphemeralPort || value == portrollup.EphemeralPort {
+		return false
+	}
+	switch operator {
+	case "<":
+		return port < value
+	case "<=":
+		return port <= value
+	case ">":
+		return port > value
+	default:
+		return port >= value
+	}
+}
+
+type protocolComparison struct {
+	protocols []uint32
+}
+
+func (c *protocolComparison) match(flow *common.Flow) bool {
+	for _, protocol := range c.protocols {
+		if flow.IPProtocol == protocol {
+			return true
+		}
+	}
+	return false
+}
+
+var filterIPFields = map[string]func(flow *common.Flow) [][]byte{
+	"exporter": func(flow *common.Flow) [][]byte { return [][]byte{flow.ExporterAddr} },
+	"src_ip":   func(flow *common.Flow) [][]byte { return [][]byte{flow.SrcAddr} },
+	"dst_ip":   func(flow *common.Flow) [][]byte { return [][]byte{flow.DstAddr} },
+	"ip":       func(flow *common.Flow) [][]byte { return [][]byte{flow.SrcAddr, flow.DstAddr} },
+}
+
+var filterPortFields = map[string]func(flow *common.Flow) []int32{
+	"src_port": func(flow *common.Flow) []int32 { return []int32{flow.SrcPort} },
+	"dst_port": func(flow *common.Flow) []int32 { return []int32{flow.DstPort} },
+	"port":     func(flow *common.Flow) []int32 { return []int32{flow.SrcPort, flow.DstPort} },
+}
+
+var filterOperators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// tokenizeFilter splits an expression into parentheses, commas, operators and words
+func tokenizeFilter(expression string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(expression); {
+		c := expression[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+			continue
+		case c == '(' || c == ')' || c == ',':
+			tokens = append(tokens, string(c))
+			i++
+			continue
+		}
+		operatorFound := false
+		for _, operator := range filterOperators {
+			if strings.HasPrefix(expression[i:], operator) {
+				tokens = append(tokens, operator)
+				i += len(operator)
+				operatorFound = true
+				break
+			}
+		}
+		if operatorFound {
+			continue
+		}
+		start := i
+		for i < len(expression) && isFilterWordChar(expression[i]) {
+			i++
+		}
+		if i == start {
+			return nil, fmt.Errorf("unexpected character `%c`", c)
+		}
+		tokens = append(tokens, expression[start:i])
+	}
+	return tokens, nil
+}
+
+func isFilterWordChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') ||
+		c == '_' || c == '.' || c == ':' || c == '/' || c == '-' || c == '*'
+}
+
+type filterParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *filterParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *filterParser) next() (string, error) {
+	if p.pos >= len(p.tokens) {
+		return "", errors.New("unexpected end of expression")
+	}
+	token := p.tokens[p.pos]
+	p.pos++
+	return token, nil
+}
+
+func (p *filterParser) isKeyword(keyword string) bool {
+	return strings.EqualFold(p.peek(), keyword)
+}
+
+func (p *filterParser) parseOr() (filterNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") {
+		p.pos++
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &andNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseNot() (filterNode, error) {
+	if p.isKeyword("not") {
+		p.pos++
+		node, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{node: node}, nil
+	}
+	if p.peek() == "(" {
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if token, err := p.next(); err != nil || token != ")" {
+			return nil, errors.New("missing `)`")
+		}
+		return node, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *filterParser) parseComparison() (filterNode, error) {
+	field, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	field = strings.ToLower(field)
+	operator, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	operator = strings.ToLower(operator)
+	if operator != "in" && !slices.Contains(filterOperators, operator) {
+		return nil, fmt.Errorf("unexpected `%s`, expected an operator after `%s`", operator, field)
+	}
+	values, err := p.parseValues(operator)
+	if err != nil {
+		return nil, err
+	}
+
+	var node filterNode
+	if ips, ok := filterIPFields[field]; ok {
+		node, err = newIPComparison(field, ips, operator, values)
+	} else if ports, ok := filterPortFields[field]; ok {
+		node, err = newPortComparison(field, ports, operator, values)
+	} else if field == "protocol" {
+		node, err = newProtocolComparison(operator, values)
+	} else {
+		return nil, fmt.Errorf("unknown field `%s`", field)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if operator == "!=" {
+		return &notNode{node: node}, nil
+	}
+	return node, nil
+}
+
+// parseValues parses the value of a comparison, or the list of values of `in`
+func (p *filterParser) parseValues(operator string) ([]string, error) {
+	if operator != "in" {
+		value, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if !isFilterValue(value) {
+			return nil, fmt.Errorf("unexpected `%s`", value)
+		}
+		return []string{value}, nil
+	}
+
+	if token, err := p.next(); err != nil || token != "(" {
+		return nil, errors.New("`in` must be followed by a list of values in parentheses")
+	}
+	var values []string
+	for {
+		value, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if !isFilterValue(value) {
+			return nil, fmt.Errorf("unexpected `%s`", value)
+		}
+		values = append(values, value)
+		separator, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if separator == ")" {
+			return values, nil
+		}
+		if separator != "," {
+			return nil, fmt.Errorf("unexpected `%s`, expected `,` or `)`", separator)
+		}
+	}
+}
+
+func isFilterValue(token string) bool {
+	return token != "" && isFilterWordChar(token[0])
+}
+
+func newIPComparison(field string, ips func(flow *common.Flow) [][]byte, operator string, values []string) (filterNode, error) {
+	if operator != "==" && operator != "!=" && operator != "in" {
+		return nil, fmt.Errorf("operator `%s` is not supported for `%s`", operator, field)
+	}
+	networks := make([]*net.IPNet, 0, len(values))
+	for _, value := range values {
+		if strings.Contains(value, "/") {
+			_, network, err := net.ParseCIDR(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR `%s`", value)
+			}
+			networks = append(networks, network)
+			continue
+		}
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP `%s`", value)
+		}
+		if ipv4 := ip.To4(); ipv4 != nil {
+			ip = ipv4
+		}
+		networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+	}
+	return &ipComparison{ips: ips, networks: networks}, nil
+}
+
+func newPortComparison(field string, ports func(flow *common.Flow) []int32, operator string, values []string) (filterNode, error) {
+	switch operator {
+	case "==", "in", "!=":
+		operator = "=="
+	case "<", "<=", ">", ">=":
+	default:
+		return nil, fmt.Errorf("operator `%s` is not supported for `%s`", operator, field)
+	}
+	portValues := make([]int32, 0, len(values))
+	for _, value := range values {
+		if value == "*" {
+			if operator != "==" {
+				return nil, fmt.Errorf("ephemeral ports can't be compared with `%s`", operator)
+			}
+			portValues = append(portValues, portrollup.EphemeralPort)
+			continue
+		}
+		port, err := strconv.ParseUint(value, 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port `%s`", value)
+		}
+		portValues = append(portValues, int32(port))
+	}
+	return &portComparison{ports: ports, operator: operator, values: portValues}, nil
+}
+
+func newProtocolComparison(operator string, values []string) (filterNode, error) {
+	if operator != "==" && operator != "!=" && operator != "in" {
+		return nil, fmt.Errorf("operator `%s` is not supported for `protocol`", operator)
+	}
+	protocols := make([]uint32, 0, len(values))
+	for _, value := range values {
+		protocol, ok := parseProtocol(value)
+		if !ok {
+			return nil, fmt.Errorf("unknown protocol `%s`", value)
+		}
+		protocols = append(protocols, protocol)
+	}
+	return &protocolComparison{protocols: protocols}, nil
+}
+
+// parseProtocol parses an IP protocol number or its name, as formatted in the flow payloads
+func parseProtocol(value string) (uint32, bool) {
+	if number, err := strconv.ParseUint(value, 10, 8); err == nil {
+		return uint32(number), true
+	}
+	for number := uint32(0); number <= 255; number++ {
+		if name := format.IPProtocol(number); name != "" && strings.EqualFold(name, value) {
+			return number, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,118 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package sink
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+	"github.com/DataDog/datadog-agent/comp/netflow/portrollup"
+)
+
+func TestFilter_Match(t *testing.T) {
+	https := &common.Flow{
+		ExporterAddr: net.ParseIP("10.0.0.1").To4(),
+		SrcAddr:      net.ParseIP("192.168.1.10").To4(),
+		DstAddr:      net.ParseIP("8.8.8.8").To4(),
+		SrcPort:      portrollup.EphemeralPort,
+		DstPort:      443,
+		IPProtocol:   6,
+	}
+	dns := &common.Flow{
+		ExporterAddr: net.ParseIP("2001:db8::1"),
+		SrcAddr:      net.ParseIP("2001:db8:1::10"),
+		DstAddr:      net.ParseIP("2001:db8:2::53"),
+		SrcPort:      53000,
+		DstPort:      53,
+		IPProtocol:   17,
+	}
+
+	tests := []struct {
+		expression string
+		https      bool
+		dns        bool
+	}{
+		{"", true, true},
+		{"exporter == 10.0.0.1", true, false},
+		{"exporter != 10.0.0.1", false, true},
+		{"exporter in (10.0.0.0/8, 2001:db8::/32)", true, true},
+		{"src_ip == 192.168.0.0/16", true, false},
+		{"dst_ip in (2001:db8:2::/48)", false, true},
+		{"ip == 8.8.8.8", true, false},
+		{"ip != 8.8.8.8", false, true},
+		{"dst_port == 443", true, false},
+		{"dst_port in (53, 443)", true, true},
+		{"src_port == *", true, false},
+		{"src_port > 1024", false, true},
+		{"src_port <= 1024", false, false},
+		{"port < 100", false, true},
+		{"port >= 50000", false, true},
+		{"protocol == tcp", true, false},
+		{"protocol == 17", false, true},
+		{"protocol in (TCP, udp)", true, true},
+		{"protocol != tcp", false, true},
+		{"not protocol == tcp", false, true},
+		{"protocol == tcp and dst_port == 53", false, false},
+		{"protocol == tcp or dst_port == 53", true, true},
+		{"exporter == 10.0.0.1 and (dst_port == 53 or dst_port == 443)", true, false},
+		{"not (dst_port == 53 or dst_port == 443)", false, false},
+		{"dst_port == 53 or dst_port == 443 and protocol == udp", false, true},
+		{"NOT dst_port==53 AND port<1000", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			filter, err := ParseFilter(tt.expression)
+			require.NoError(t, err)
+			assert.Equal(t, tt.https, filter.Match(https), "https flow")
+			assert.Equal(t, tt.dns, filter.Match(dns), "dns flow")
+		})
+	}
+}
+
+func TestFilter_MatchMissingAddress(t *testing.T) {
+	filter, err := ParseFilter("exporter in (0.0.0.0/0, ::/0)")
+	require.NoError(t, err)
+	assert.False(t, filter.Match(&common.Flow{}))
+}
+
+func TestParseFilter_Errors(t *testing.T) {
+	tests := []struct {
+		expression    string
+		expectedError string
+	}{
+		{"dst_port", "invalid filter `dst_port`: unexpected end of expression"},
+		{"dst_port ==", "invalid filter `dst_port ==`: unexpected end of expression"},
+		{"dst_port == 80 and", "invalid filter `dst_port == 80 and`: unexpected end of expression"},
+		{"dst_port == 80 dst_port == 443", "invalid filter `dst_port == 80 dst_port == 443`: unexpected `dst_port`"},
+		{"(dst_port == 80", "invalid filter `(dst_port == 80`: missing `)`"},
+		{"dst_port == 80)", "invalid filter `dst_port == 80)`: unexpected `)`"},
+		{"dst_port = 80", "invalid filter `dst_port = 80`: unexpected character `=`"},
+		{"dst_port 80", "invalid filter `dst_port 80`: unexpected `80`, expected an operator after `dst_port`"},
+		{"vlan == 10", "invalid filter `vlan == 10`: unknown field `vlan`"},
+		{"dst_port == http", "invalid filter `dst_port == http`: invalid port `http`"},
+		{"dst_port == 70000", "invalid filter `dst_port == 70000`: invalid port `70000`"},
+		{"dst_port < *", "invalid filter `dst_port < *`: ephemeral ports can't be compared with `<`"},
+		{"dst_port in 80", "invalid filter `dst_port in 80`: `in` must be followed by a list of values in parentheses"},
+		{"dst_port in (80 443)", "invalid filter `dst_port in (80 443)`: unexpected `443`, expected `,` or `)`"},
+		{"dst_port == (", "invalid filter `dst_port == (`: unexpected `(`"},
+		{"exporter > 10.0.0.1", "invalid filter `exporter > 10.0.0.1`: operator `>` is not supported for `exporter`"},
+		{"exporter == 10.0.0.300", "invalid filter `exporter == 10.0.0.300`: invalid IP `10.0.0.300`"},
+		{"src_ip == 10.0.0.0/33", "invalid filter `src_ip == 10.0.0.0/33`: invalid CIDR `10.0.0.0/33`"},
+		{"protocol == foo", "invalid filter `protocol == foo`: unknown protocol `foo`"},
+		{"protocol < 10", "invalid filter `protocol < 10`: operator `<` is not supported for `protocol`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			filter, err := ParseFilter(tt.expression)
+			assert.EqualError(t, err, tt.expectedError)
+			assert.Nil(t, filter)
+		})
+	}
+}
@@ -0,0 +1,286 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package sink
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"time"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+)
+
+const (
+	ipfixVersion      = 10
+	ipfixSetHeaderLen = 4
+	// ipfixTemplateSetID is the set ID of template sets, data sets use the ID of their template
+	ipfixTemplateSetID    = 2
+	ipfixFirstTemplateID  = 256
+	ipfixMaxMessageLength = 1400 // fits in the MTU of most networks
+)
+
+// Information Elements, see https://www.iana.org/assignments/ipfix/ipfix.xhtml
+const (
+	ieOctetDeltaCount             = 1
+	iePacketDeltaCount            = 2
+	ieProtocolIdentifier          = 4
+	ieIPClassOfService            = 5
+	ieTCPControlBits              = 6
+	ieSourceTransportPort         = 7
+	ieSourceIPv4Address           = 8
+	ieSourceIPv4PrefixLength      = 9
+	ieIngressInterface            = 10
+	ieDestinationTransportPort    = 11
+	ieDestinationIPv4Address      = 12
+	ieDestinationIPv4PrefixLength = 13
+	ieEgressInterface             = 14
+	ieIPNextHopIPv4Address        = 15
+	ieSourceIPv6Address           = 27
+	ieDestinationIPv6Address      = 28
+	ieSourceIPv6PrefixLength      = 29
+	ieDestinationIPv6PrefixLength = 30
+	ieSamplingInterval            = 34
+	ieSourceMacAddress            = 56
+	ieIPVersion                   = 60
+	ieFlowDirection               = 61
+	ieIPNextHopIPv6Address        = 62
+	ieDestinationMacAddress       = 80
+	ieExporterIPv4Address         = 130
+	ieExporterIPv6Address         = 131
+	ieFlowStartSeconds            = 150
+	ieFlowEndSeconds              = 151
+	ieEthernetType                = 256
+)
+
+// ipfixField is a field of a template, encoding a value of a flow
+type ipfixField struct {
+	id     uint16
+	length uint16
+	encode func(buffer []byte, flow *common.Flow) []byte
+}
+
+// ipfixTemplateKey identifies the template of a flow, IPv4 and IPv6 addresses being different fields
+type ipfixTemplateKey struct {
+	ipv6Addresses bool
+	ipv6NextHop   bool
+	ipv6Exporter  bool
+}
+
+type ipfixTemplate struct {
+	id       uint16
+	fields   []ipfixField
+	set      []byte // encoded template set
+	dataSize int    // size of a data record
+}
+
+// ipfixSink re-exports flows as IPFIX to a collector over UDP. Templates are included in every message
+// so that collectors can decode them regardless of when they started listening.
+type ipfixSink struct {
+	conn                net.Conn
+	observationDomainID uint32
+	templates           map[ipfixTemplateKey]*ipfixTemplate
+	// sequenceNumber is the number of data records sent
+	sequenceNumber  uint32
+	timeNowFunction func() time.Time
+}
+
+func newIPFIXSink(destination string, observationDomainID uint32) (*ipfixSink, error) {
+	conn, err := net.Dial("udp", destination)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to IPFIX collector %s: %w", destination, err)
+	}
+	return &ipfixSink{
+		conn:                conn,
+		observationDomainID: observationDomainID,
+		templates:           make(map[ipfixTemplateKey]*ipfixTemplate),
+		timeNowFunction:     time.Now,
+	}, nil
+}
+
+func (s *ipfixSink) Send(records []Record) error {
+	var errs []error
+	for _, message := range s.encodeMessages(records) {
+		if _, err := s.conn.Write(message); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *ipfixSink) Close() error {
+	return s.conn.Close()
+}
+
+// encodeMessages encodes flows in messages holding consecutive flows of the same template
+func (s *ipfixSink) encodeMessages(records []Record) [][]byte {
+	var messages [][]byte
+	var message []byte
+	var current *ipfixTemplate
+	var dataSetStart, recordCount int
+	exportTime := uint32(s.timeNowFunction().Unix())
+
+	finishMessage := func() {
+		if message == nil {
+			return
+		}
+		binary.BigEndian.PutUint16(message[dataSetStart+2:], uint16(len(message)-dataSetStart))
+		binary.BigEndian.PutUint16(message[2:], uint16(len(message)))
+		binary.BigEndian.PutUint32(message[8:], s.sequenceNumber)
+		s.sequenceNumber += uint32(recordCount)
+		messages = append(messages, message)
+		message = nil
+	}
+
+	for _, record := range records {
+		template := s.getTemplate(record.Flow)
+		if template != current || len(message)+template.dataSize > ipfixMaxMessageLength {
+			finishMessage()
+			current = template
+			recordCount = 0
+			message = make([]byte, 0, ipfixMaxMessageLength)
+			message = binary.BigEndian.AppendUint16(message, ipfixVersion)
+			message = binary.BigEndian.AppendUint16(message, 0) // length, set when finished
+			message = binary.BigEndian.AppendUint32(message, exportTime)
+			message = binary.BigEndian.AppendUint32(message, 0) // sequence number, set when finished
+			message = binary.BigEndian.AppendUint32(message, s.observationDomainID)
+			message = append(message, template.set...)
+			dataSetStart = len(message)
+			message = binary.BigEndian.AppendUint16(message, template.id)
+			message = binary.BigEndian.AppendUint16(message, 0) // length, set when finished
+		}
+		for _, field := range template.fields {
+			message = field.encode(message, record.Flow)
+		}
+		recordCount++
+	}
+	finishMessage()
+	return messages
+}
+
+// getTemplate returns the template of a flow, creating it on first use
+func (s *ipfixSink) getTemplate(flow *common.Flow) *ipfixTemplate {
+	key := ipfixTemplateKey{
+		ipv6Addresses: len(flow.SrcAddr) == net.IPv6len || len(flow.DstAddr) == net.IPv6len,
+		ipv6NextHop:   len(flow.NextHop) == net.IPv6len,
+		ipv6Exporter:  len(flow.ExporterAddr) == net.IPv6len,
+	}
+	if template, ok := s.templates[key]; ok {
+		return template
+	}
+
+	fields := []ipfixField{
+		addressField(ieSourceIPv4Address, ieSourceIPv6Address, key.ipv6Addresses, func(flow *common.Flow) []byte { return flow.SrcAddr }),
+		addressField(ieDestinationIPv4Address, ieDestinationIPv6Address, key.ipv6Addresses, func(flow *common.Flow) []byte { return flow.DstAddr }),
+		{ieSourceTransportPort, 2, func(buffer []byte, flow *common.Flow) []byte {
+			return binary.BigEndian.AppendUint16(buffer, exportedPort(flow.SrcPort))
+		}},
+		{ieDestinationTransportPort, 2, func(buffer []byte, flow *common.Flow) []byte {
+			return binary.BigEndian.AppendUint16(buffer, exportedPort(flow.DstPort))
+		}},
+		{ieProtocolIdentifier, 1, func(buffer []byte, flow *common.Flow) []byte { return append(buffer, byte(flow.IPProtocol)) }},
+		{ieOctetDeltaCount, 8, func(buffer []byte, flow *common.Flow) []byte {
+			return binary.BigEndian.AppendUint64(buffer, flow.Bytes)
+		}},
+		{iePacketDeltaCount, 8, func(buffer []byte, flow *common.Flow) []byte {
+			return binary.BigEndian.AppendUint64(buffer, flow.Packets)
+		}},
+		{ieFlowStartSeconds, 4, func(buffer []byte, flow *common.Flow) []byte {
+			return binary.BigEndian.AppendUint32(buffer, uint32(flow.StartTimestamp))
+		}},
+		{ieFlowEndSeconds, 4, func(buffer []byte, flow *common.Flow) []byte {
+			return binary.BigEndian.AppendUint32(buffer, uint32(flow.EndTimestamp))
+		}},
+		{ieIngressInterface, 4, func(buffer []byte, flow *common.Flow) []byte {
+			return binary.BigEndian.AppendUint32(buffer, flow.InputInterface)
+		}},
+		{ieEgressInterface, 4, func(buffer []byte, flow *common.Flow) []byte {
+			return binary.BigEndian.AppendUint32(buffer, flow.OutputInterface)
+		}},
+		{ieTCPControlBits, 2, func(buffer []byte, flow *common.Flow) []byte {
+			return binary.BigEndian.AppendUint16(buffer, uint16(flow.TCPFlags))
+		}},
+		{ieIPClassOfService, 1, func(buffer []byte, flow *common.Flow) []byte { return append(buffer, byte(flow.Tos)) }},
+		{ieFlowDirection, 1, func(buffer []byte, flow *common.Flow) []byte { return append(buffer, byte(flow.Direction)) }},
+		{ieSourceMacAddress, 6, func(buffer []byte, flow *common.Flow) []byte { return appendMacAddress(buffer, flow.SrcMac) }},
+		{ieDestinationMacAddress, 6, func(buffer []byte, flow *common.Flow) []byte { return appendMacAddress(buffer, flow.DstMac) }},
+		prefixLengthField(ieSourceIPv4PrefixLength, ieSourceIPv6PrefixLength, key.ipv6Addresses, func(flow *common.Flow) uint32 { return flow.SrcMask }),
+		prefixLengthField(ieDestinationIPv4PrefixLength, ieDestinationIPv6PrefixLength, key.ipv6Addresses, func(flow *common.Flow) uint32 { return flow.DstMask }),
+		addressField(ieIPNextHopIPv4Address, ieIPNextHopIPv6Address, key.ipv6NextHop, func(flow *common.Flow) []byte { return flow.NextHop }),
+		addressField(ieExporterIPv4Address, ieExporterIPv6Address, key.ipv6Exporter, func(flow *common.Flow) []byte { return flow.ExporterAddr }),
+		{ieSamplingInterval, 4, func(buffer []byte, flow *common.Flow) []byte {
+			return binary.BigEndian.AppendUint32(buffer, uint32(min(flow.SamplingRate, math.MaxUint32)))
+		}},
+		{ieIPVersion, 1, func(buffer []byte, _ *common.Flow) []byte {
+			if key.ipv6Addresses {
+				return append(buffer, 6)
+			}
+			return append(buffer, 4)
+		}},
+		{ieEthernetType, 2, func(buffer []byte, flow *common.Flow) []byte {
+			return binary.BigEndian.AppendUint16(buffer, uint16(flow.EtherType))
+		}},
+	}
+
+	template := &ipfixTemplate{
+		id:     uint16(ipfixFirstTemplateID + len(s.templates)),
+		fields: fields,
+	}
+	set := binary.BigEndian.AppendUint16(nil, ipfixTemplateSetID)
+	set = binary.BigEndian.AppendUint16(set, uint16(ipfixSetHeaderLen+4+4*len(fields)))
+	set = binary.BigEndian.AppendUint16(set, template.id)
+	set = binary.BigEndian.AppendUint16(set, uint16(len(fields)))
+	for _, field := range fields {
+		set = binary.BigEndian.AppendUint16(set, field.id)
+		set = binary.BigEndian.AppendUint16(set, field.length)
+		template.dataSize += int(field.length)
+	}
+	template.set = set
+	s.templates[key] = template
+	return template
+}
+
+// addressField encodes an IP address as IPv4 or IPv6, missing addresses are encoded as zeros
+func addressField(ipv4ID uint16, ipv6ID uint16, ipv6 bool, address func(flow *common.Flow) []byte) ipfixField {
+	id, length := ipv4ID, uint16(net.IPv4len)
+	if ipv6 {
+		id, length = ipv6ID, uint16(net.IPv6len)
+	}
+	return ipfixField{id, length, func(buffer []byte, flow *common.Flow) []byte {
+		ip := net.IP(address(flow))
+		if length == net.IPv4len {
+			ip = ip.To4()
+		} else {
+			ip = ip.To16()
+		}
+		if ip == nil {
+			return append(buffer, make([]byte, length)...)
+		}
+		return append(buffer, ip...)
+	}}
+}
+
+func prefixLengthField(ipv4ID uint16, ipv6ID uint16, ipv6 bool, prefixLength func(flow *common.Flow) uint32) ipfixField {
+	id := ipv4ID
+	if ipv6 {
+		id = ipv6ID
+	}
+	return ipfixField{id, 1, func(buffer []byte, flow *common.Flow) []byte { return append(buffer, byte(prefixLength(flow))) }}
+}
+
+func appendMacAddress(buffer []byte, mac uint64) []byte {
+	return append(buffer, byte(mac>>40), byte(mac>>32), byte(mac>>24), byte(mac>>16), byte(mac>>8), byte(mac))
+}
+
+// exportedPort returns the port of a flow, ephemeral ports rolled up by the aggregator are exported as 0
+func exportedPort(port int32) uint16 {
+	if port < 0 {
+		return 0
+	}
+	return uint16(port)
+}
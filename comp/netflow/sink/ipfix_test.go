@@ -0,0 +1,187 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package sink
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/netsampler/goflow2/decoders/netflow"
+	flowpb "github.com/netsampler/goflow2/pb"
+	"github.com/netsampler/goflow2/producer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+	"github.com/DataDog/datadog-agent/comp/netflow/portrollup"
+)
+
+// ipfixCollector decodes the IPFIX messages received on a local UDP port
+type ipfixCollector struct {
+	t         *testing.T
+	conn      *net.UDPConn
+	templates netflow.NetFlowTemplateSystem
+}
+
+func newIPFIXCollector(t *testing.T) *ipfixCollector {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return &ipfixCollector{t: t, conn: conn, templates: netflow.CreateTemplateSystem()}
+}
+
+func (c *ipfixCollector) receive() (netflow.IPFIXPacket, []*flowpb.FlowMessage) {
+	buffer := make([]byte, 65535)
+	require.NoError(c.t, c.conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	size, err := c.conn.Read(buffer)
+	require.NoError(c.t, err)
+
+	decoded, err := netflow.DecodeMessage(bytes.NewBuffer(buffer[:size]), c.templates)
+	require.NoError(c.t, err)
+	packet, ok := decoded.(netflow.IPFIXPacket)
+	require.True(c.t, ok)
+	assert.Equal(c.t, int(packet.Length), size)
+	messages, err := producer.ProcessMessageNetFlow(packet, nil)
+	require.NoError(c.t, err)
+	return packet, messages
+}
+
+func TestIPFIXSink(t *testing.T) {
+	collector := newIPFIXCollector(t)
+	sink, err := newIPFIXSink(collector.conn.LocalAddr().String(), 42)
+	require.NoError(t, err)
+	defer sink.Close()
+	sink.timeNowFunction = func() time.Time { return time.Unix(1700000000, 0) }
+
+	ipv4Flow := &common.Flow{
+		ExporterAddr:    net.ParseIP("10.0.0.1").To4(),
+		StartTimestamp:  1699999900,
+		EndTimestamp:    1699999990,
+		Bytes:           5000000000,
+		Packets:         42,
+		SrcAddr:         net.ParseIP("192.168.1.10").To4(),
+		DstAddr:         net.ParseIP("8.8.8.8").To4(),
+		EtherType:       0x0800,
+		IPProtocol:      6,
+		TCPFlags:        0x1b,
+		SrcPort:         portrollup.EphemeralPort,
+		DstPort:         443,
+		InputInterface:  3,
+		OutputInterface: 7,
+		Direction:       1,
+		SrcMac:          0x00aabbccddee,
+		DstMac:          0x112233445566,
+		SrcMask:         24,
+		DstMask:         16,
+		Tos:             0x10,
+		NextHop:         net.ParseIP("10.0.0.254").To4(),
+		SamplingRate:    100,
+	}
+	ipv6Flow := &common.Flow{
+		ExporterAddr:   net.ParseIP("10.0.0.1").To4(),
+		StartTimestamp: 1699999950,
+		EndTimestamp:   1699999960,
+		Bytes:          300,
+		Packets:        2,
+		SrcAddr:        net.ParseIP("2001:db8:1::10"),
+		DstAddr:        net.ParseIP("2001:db8:2::53"),
+		EtherType:      0x86dd,
+		IPProtocol:     17,
+		SrcPort:        53000,
+		DstPort:        53,
+		SrcMask:        48,
+		DstMask:        64,
+	}
+	require.NoError(t, sink.Send([]Record{{Flow: ipv4Flow}, {Flow: ipv6Flow}}))
+
+	// flows of different templates are sent in different messages
+	packet, messages := collector.receive()
+	assert.Equal(t, uint16(10), packet.Version)
+	assert.Equal(t, uint32(1700000000), packet.ExportTime)
+	assert.Equal(t, uint32(0), packet.SequenceNumber)
+	assert.Equal(t, uint32(42), packet.ObservationDomainId)
+	require.Len(t, messages, 1)
+	message := messages[0]
+	assert.Equal(t, []byte(ipv4Flow.SrcAddr), message.SrcAddr)
+	assert.Equal(t, []byte(ipv4Flow.DstAddr), message.DstAddr)
+	assert.Equal(t, uint32(0), message.SrcPort)
+	assert.Equal(t, uint32(443), message.DstPort)
+	assert.Equal(t, uint32(6), message.Proto)
+	assert.Equal(t, uint64(5000000000), message.Bytes)
+	assert.Equal(t, uint64(42), message.Packets)
+	assert.Equal(t, uint64(1699999900), message.TimeFlowStart)
+	assert.Equal(t, uint64(1699999990), message.TimeFlowEnd)
+	assert.Equal(t, uint32(3), message.InIf)
+	assert.Equal(t, uint32(7), message.OutIf)
+	assert.Equal(t, uint32(0x1b), message.TcpFlags)
+	assert.Equal(t, uint32(0x10), message.IpTos)
+	assert.Equal(t, uint32(1), message.FlowDirection)
+	assert.Equal(t, uint64(0x00aabbccddee), message.SrcMac)
+	assert.Equal(t, uint64(0x112233445566), message.DstMac)
+	assert.Equal(t, uint32(24), message.SrcNet)
+	assert.Equal(t, uint32(16), message.DstNet)
+	assert.Equal(t, []byte(ipv4Flow.NextHop), message.NextHop)
+	assert.Equal(t, uint32(0x0800), message.Etype)
+
+	packet, messages = collector.receive()
+	assert.Equal(t, uint32(1), packet.SequenceNumber)
+	require.Len(t, messages, 1)
+	message = messages[0]
+	assert.Equal(t, []byte(ipv6Flow.SrcAddr), message.SrcAddr)
+	assert.Equal(t, []byte(ipv6Flow.DstAddr), message.DstAddr)
+	assert.Equal(t, uint32(53000), message.SrcPort)
+	assert.Equal(t, uint32(53), message.DstPort)
+	assert.Equal(t, uint32(48), message.SrcNet)
+	assert.Equal(t, uint32(64), message.DstNet)
+	assert.Equal(t, uint32(0x86dd), message.Etype)
+	// missing addresses are exported as zeros
+	assert.Equal(t, make([]byte, 4), message.NextHop)
+
+	assert.Len(t, sink.templates, 2)
+}
+
+func TestIPFIXSink_SplitsMessages(t *testing.T) {
+	collector := newIPFIXCollector(t)
+	sink, err := newIPFIXSink(collector.conn.LocalAddr().String(), 1)
+	require.NoError(t, err)
+	defer sink.Close()
+
+	var records []Record
+	for i := 0; i < 40; i++ {
+		records = append(records, Record{Flow: &common.Flow{
+			SrcAddr:    net.IPv4(10, 0, 0, byte(i)).To4(),
+			DstAddr:    net.IPv4(10, 0, 1, byte(i)).To4(),
+			IPProtocol: 6,
+			DstPort:    int32(1000 + i),
+		}})
+	}
+	require.NoError(t, sink.Send(records))
+
+	var ports []uint32
+	var sequenceNumbers []uint32
+	for len(ports) < len(records) {
+		packet, messages := collector.receive()
+		assert.LessOrEqual(t, int(packet.Length), ipfixMaxMessageLength)
+		sequenceNumbers = append(sequenceNumbers, packet.SequenceNumber)
+		for _, message := range messages {
+			ports = append(ports, message.DstPort)
+		}
+	}
+	require.Len(t, ports, len(records))
+	for i, port := range ports {
+		assert.Equal(t, uint32(1000+i), port)
+	}
+	assert.Greater(t, len(sequenceNumbers), 1)
+	assert.Equal(t, uint32(0), sequenceNumbers[0])
+	assert.Equal(t, uint32(len(records)), sink.sequenceNumber)
+}
+
+func TestNewIPFIXSink_InvalidDestination(t *testing.T) {
+	_, err := newIPFIXSink("localhost", 1)
+	assert.ErrorContains(t, err, "unable to connect to IPFIX collector localhost")
+}
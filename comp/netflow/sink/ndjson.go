@@ -0,0 +1,122 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package sink
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ndjsonSink writes the flow payloads to a file, one JSON object per line. The file is rotated
+// when it exceeds its maximum size: `flows.json` is renamed `flows.json.1`, `flows.json.1` is
+// renamed `flows.json.2`, and so on up to the maximum number of backups.
+type ndjsonSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	file   *os.File
+	writer *bufio.Writer
+	size   int64
+}
+
+func newNDJSONSink(path string, maxSize int64, maxBackups int) (*ndjsonSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	sink := &ndjsonSink{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+	if err := sink.open(); err != nil {
+		return nil, err
+	}
+	return sink, nil
+}
+
+func (s *ndjsonSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	s.file = file
+	s.writer = bufio.NewWriter(file)
+	s.size = info.Size()
+	return nil
+}
+
+func (s *ndjsonSink) Send(records []Record) error {
+	if s.file == nil {
+		// a previous rotation failed, try again
+		if err := s.open(); err != nil {
+			return err
+		}
+	}
+	for _, record := range records {
+		lineSize := int64(len(record.Payload)) + 1
+		if s.size > 0 && s.size+lineSize > s.maxSize {
+			if err := s.rotate(); err != nil {
+				return fmt.Errorf("unable to rotate %s: %w", s.path, err)
+			}
+		}
+		s.writer.Write(record.Payload) //nolint:errcheck // the error is returned by Flush
+		s.writer.WriteByte('\n')       //nolint:errcheck
+		s.size += lineSize
+	}
+	return s.writer.Flush()
+}
+
+// rotate closes the current file, shifts the backups and opens a new file
+func (s *ndjsonSink) rotate() error {
+	err := s.close()
+	s.file = nil
+	if err != nil {
+		return err
+	}
+	if s.maxBackups <= 0 {
+		if err := os.Remove(s.path); err != nil {
+			return err
+		}
+		return s.open()
+	}
+	for i := s.maxBackups - 1; i >= 1; i-- {
+		err := os.Rename(s.backupPath(i), s.backupPath(i+1))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if err := os.Rename(s.path, s.backupPath(1)); err != nil {
+		return err
+	}
+	return s.open()
+}
+
+func (s *ndjsonSink) backupPath(index int) string {
+	return fmt.Sprintf("%s.%d", s.path, index)
+}
+
+func (s *ndjsonSink) close() error {
+	flushErr := s.writer.Flush()
+	return errors.Join(flushErr, s.file.Close())
+}
+
+func (s *ndjsonSink) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	err := s.close()
+	s.file = nil
+	return err
+}
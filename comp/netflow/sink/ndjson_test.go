@@ -0,0 +1,90 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package sink
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRecords(start int, count int) []Record {
+	var records []Record
+	for i := start; i < start+count; i++ {
+		records = append(records, Record{Payload: []byte(fmt.Sprintf(`{"id":%03d}`, i))})
+	}
+	return records
+}
+
+func readFile(t *testing.T, path string) string {
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestNDJSONSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flows", "flows.json")
+	sink, err := newNDJSONSink(path, 1024, 2)
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Send(testRecords(0, 2)))
+	require.NoError(t, sink.Send(testRecords(2, 1)))
+	require.NoError(t, sink.Close())
+	assert.Equal(t, "{\"id\":000}\n{\"id\":001}\n{\"id\":002}\n", readFile(t, path))
+
+	// the file is appended to when the sink is recreated
+	sink, err = newNDJSONSink(path, 1024, 2)
+	require.NoError(t, err)
+	require.NoError(t, sink.Send(testRecords(3, 1)))
+	require.NoError(t, sink.Close())
+	assert.Equal(t, "{\"id\":000}\n{\"id\":001}\n{\"id\":002}\n{\"id\":003}\n", readFile(t, path))
+}
+
+func TestNDJSONSink_Rotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flows.json")
+	// each line is 11 bytes, files hold 3 lines
+	sink, err := newNDJSONSink(path, 35, 2)
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Send(testRecords(0, 4)))
+	assert.Equal(t, "{\"id\":003}\n", readFile(t, path))
+	assert.Equal(t, "{\"id\":000}\n{\"id\":001}\n{\"id\":002}\n", readFile(t, path+".1"))
+
+	require.NoError(t, sink.Send(testRecords(4, 6)))
+	require.NoError(t, sink.Close())
+	assert.Equal(t, "{\"id\":009}\n", readFile(t, path))
+	assert.Equal(t, "{\"id\":006}\n{\"id\":007}\n{\"id\":008}\n", readFile(t, path+".1"))
+	assert.Equal(t, "{\"id\":003}\n{\"id\":004}\n{\"id\":005}\n", readFile(t, path+".2"))
+	// older files are removed
+	assert.NoFileExists(t, path+".3")
+}
+
+func TestNDJSONSink_RotationWithoutBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flows.json")
+	sink, err := newNDJSONSink(path, 35, 0)
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Send(testRecords(0, 5)))
+	require.NoError(t, sink.Close())
+	assert.Equal(t, "{\"id\":003}\n{\"id\":004}\n", readFile(t, path))
+	assert.NoFileExists(t, path+".1")
+}
+
+func TestNDJSONSink_LargeRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flows.json")
+	sink, err := newNDJSONSink(path, 5, 1)
+	require.NoError(t, err)
+
+	// records larger than the maximum size are written to their own file
+	require.NoError(t, sink.Send(testRecords(0, 2)))
+	require.NoError(t, sink.Close())
+	assert.Equal(t, "{\"id\":001}\n", readFile(t, path))
+	assert.Equal(t, "{\"id\":000}\n", readFile(t, path+".1"))
+}
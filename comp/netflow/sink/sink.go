@@ -0,0 +1,78 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+// Package sink exports aggregated flows outside of Datadog, to rotating NDJSON files
+// or to an IPFIX collector, each sink only receiving the flows matching its filter.
+package sink
+
+import (
+	"fmt"
+
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+	"github.com/DataDog/datadog-agent/comp/netflow/config"
+)
+
+// Record is an aggregated flow along with its payload, as sent to Datadog
+type Record struct {
+	Flow    *common.Flow
+	Payload []byte // JSON encoded payload.FlowPayload
+}
+
+// Sink receives the aggregated flows at each flush
+type Sink interface {
+	// Send exports a batch of flows
+	Send(records []Record) error
+	// Close flushes and releases the resources of the sink
+	Close() error
+}
+
+// New creates the sink described by a configuration, only receiving the flows matching its filter
+func New(conf config.SinkConfig, logger log.Component) (Sink, error) {
+	filter, err := ParseFilter(conf.Filter)
+	if err != nil {
+		return nil, err
+	}
+	var sink Sink
+	switch conf.Type {
+	case config.SinkTypeNDJSON:
+		sink, err = newNDJSONSink(conf.Path, int64(conf.MaxSizeMB)*1024*1024, conf.MaxBackups)
+	case config.SinkTypeIPFIX:
+		sink, err = newIPFIXSink(conf.Destination, conf.ObservationDomainID)
+	default:
+		return nil, fmt.Errorf("unknown sink type `%s`", conf.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("Exporting flows to %s sink %s", conf.Type, conf.Target())
+	if filter == nil {
+		return sink, nil
+	}
+	return &filteredSink{filter: filter, sink: sink}, nil
+}
+
+// filteredSink forwards the flows matching a filter to a sink
+type filteredSink struct {
+	filter *Filter
+	sink   Sink
+}
+
+func (s *filteredSink) Send(records []Record) error {
+	var matching []Record
+	for _, record := range records {
+		if s.filter.Match(record.Flow) {
+			matching = append(matching, record)
+		}
+	}
+	if len(matching) == 0 {
+		return nil
+	}
+	return s.sink.Send(matching)
+}
+
+func (s *filteredSink) Close() error {
+	return s.sink.Close()
+}
@@ -0,0 +1,41 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package sink
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	logmock "github.com/DataDog/datadog-agent/comp/core/log/mock"
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+	"github.com/DataDog/datadog-agent/comp/netflow/config"
+)
+
+func TestNew_Filter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flows.json")
+	sink, err := New(config.SinkConfig{Type: config.SinkTypeNDJSON, Path: path, MaxSizeMB: 1, MaxBackups: 1, Filter: "protocol == udp"}, logmock.New(t))
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Send([]Record{
+		{Flow: &common.Flow{IPProtocol: 6}, Payload: []byte(`{"ip_protocol":"TCP"}`)},
+		{Flow: &common.Flow{IPProtocol: 17}, Payload: []byte(`{"ip_protocol":"UDP"}`)},
+	}))
+	// batches without matching flows aren't sent
+	require.NoError(t, sink.Send([]Record{{Flow: &common.Flow{IPProtocol: 1}, Payload: []byte(`{"ip_protocol":"ICMP"}`)}}))
+	require.NoError(t, sink.Close())
+	assert.Equal(t, "{\"ip_protocol\":\"UDP\"}\n", readFile(t, path))
+}
+
+func TestNew_Errors(t *testing.T) {
+	_, err := New(config.SinkConfig{Type: "kafka"}, logmock.New(t))
+	assert.EqualError(t, err, "unknown sink type `kafka`")
+
+	_, err = New(config.SinkConfig{Type: config.SinkTypeNDJSON, Path: filepath.Join(t.TempDir(), "flows.json"), Filter: "port"}, logmock.New(t))
+	assert.EqualError(t, err, "invalid filter `port`: unexpected end of expression")
+}
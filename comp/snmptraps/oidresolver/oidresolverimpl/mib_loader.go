@@ -0,0 +1,265 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package oidresolverimpl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/oidresolver"
+)
+
+// builtinOIDs are the roots of the OID tree, defined by the ASN.1 and SMI base modules that don't need to be loaded
+var builtinOIDs = map[string][]int{
+	"ccitt":           {0},
+	"iso":             {1},
+	"joint-iso-ccitt": {2},
+	"org":             {1, 3},
+	"dod":             {1, 3, 6},
+	"internet":        {1, 3, 6, 1},
+	"directory":       {1, 3, 6, 1, 1},
+	"mgmt":            {1, 3, 6, 1, 2},
+	"mib-2":           {1, 3, 6, 1, 2, 1},
+	"transmission":    {1, 3, 6, 1, 2, 1, 10},
+	"experimental":    {1, 3, 6, 1, 3},
+	"private":         {1, 3, 6, 1, 4},
+	"enterprises":     {1, 3, 6, 1, 4, 1},
+	"security":        {1, 3, 6, 1, 5},
+	"snmpV2":          {1, 3, 6, 1, 6},
+	"snmpDomains":     {1, 3, 6, 1, 6, 1},
+	"snmpProxys":      {1, 3, 6, 1, 6, 2},
+	"snmpModules":     {1, 3, 6, 1, 6, 3},
+	"zeroDotZero":     {0, 0},
+}
+
+// builtinTypes are the textual conventions of SNMPv2-TC with enumerations, which are used by most MIBs
+var builtinTypes = map[string]*mibSyntax{
+	"TruthValue": {typeName: "INTEGER", namedNumbers: map[int]string{1: "true", 2: "false"}},
+	"RowStatus": {typeName: "INTEGER", namedNumbers: map[int]string{
+		1: "active", 2: "notInService", 3: "notReady", 4: "createAndGo", 5: "createAndWait", 6: "destroy",
+	}},
+}
+
+// maxTypeDepth bounds the chains of types derived from other types
+const maxTypeDepth = 16
+
+type mibNodeKey struct {
+	module string
+	name   string
+}
+
+// mibTree resolves the names used by a set of modules to OIDs and types
+type mibTree struct {
+	modules map[string]*mibModule
+	// moduleNames keeps the order modules were loaded in, for reproducible fallbacks
+	moduleNames []string
+	nodes       map[mibNodeKey]*mibNode
+	resolved    map[mibNodeKey][]int
+	resolving   map[mibNodeKey]bool
+}
+
+func newMIBTree(modules []*mibModule) *mibTree {
+	tree := &mibTree{
+		modules:   make(map[string]*mibModule),
+		nodes:     make(map[mibNodeKey]*mibNode),
+		resolved:  make(map[mibNodeKey][]int),
+		resolving: make(map[mibNodeKey]bool),
+	}
+	for _, module := range modules {
+		if _, ok := tree.modules[module.name]; !ok {
+			tree.moduleNames = append(tree.moduleNames, module.name)
+		}
+		tree.modules[module.name] = module
+		for _, node := range module.nodes {
+			tree.nodes[mibNodeKey{module.name, node.name}] = node
+		}
+	}
+	return tree
+}
+
+// resolveOID returns the OID of a name used in a module: defined by the module itself, imported from another
+// module, built-in, or as a last resort defined by any module, for MIBs with incomplete imports.
+func (t *mibTree) resolveOID(moduleName string, name string) ([]int, bool) {
+	if oid, ok := t.resolveNode(mibNodeKey{moduleName, name}); ok {
+		return oid, true
+	}
+	if module, ok := t.modules[moduleName]; ok {
+		if from, ok := module.imports[name]; ok {
+			if oid, ok := t.resolveNode(mibNodeKey{from, name}); ok {
+				return oid, true
+			}
+		}
+	}
+	if oid, ok := builtinOIDs[name]; ok {
+		return oid, true
+	}
+	for _, other := range t.moduleNames {
+		if other == moduleName {
+			continue
+		}
+		if oid, ok := t.resolveNode(mibNodeKey{other, name}); ok {
+			return oid, true
+		}
+	}
+	return nil, false
+}
+
+func (t *mibTree) resolveNode(key mibNodeKey) ([]int, bool) {
+	if oid, ok := t.resolved[key]; ok {
+		return oid, true
+	}
+	node, ok := t.nodes[key]
+	if !ok || node.kind == "TRAP-TYPE" || t.resolving[key] {
+		return nil, false
+	}
+	var parent []int
+	if node.parent != "." {
+		t.resolving[key] = true
+		parent, ok = t.resolveOID(key.module, node.parent)
+		delete(t.resolving, key)
+		if !ok {
+			return nil, false
+		}
+	}
+	oid := make([]int, 0, len(parent)+len(node.subIDs))
+	oid = append(append(oid, parent...), node.subIDs...)
+	t.resolved[key] = oid
+	return oid, true
+}
+
+// resolveType returns the syntax a type name used in a module refers to
+func (t *mibTree) resolveType(moduleName string, name string) (*mibSyntax, bool) {
+	if module, ok := t.modules[moduleName]; ok {
+		if syntax, ok := module.types[name]; ok {
+			return syntax, true
+		}
+		if from, ok := module.imports[name]; ok {
+			if imported, ok := t.modules[from]; ok {
+				if syntax, ok := imported.types[name]; ok {
+					return syntax, true
+				}
+			}
+		}
+	}
+	if syntax, ok := builtinTypes[name]; ok {
+		return syntax, true
+	}
+	for _, other := range t.moduleNames {
+		if syntax, ok := t.modules[other].types[name]; ok {
+			return syntax, true
+		}
+	}
+	return nil, false
+}
+
+// namedNumbers returns the enumeration or bits of a syntax, following the types it is derived from
+func (t *mibTree) namedNumbers(moduleName string, syntax *mibSyntax) (map[int]string, bool) {
+	for depth := 0; syntax != nil && depth < maxTypeDepth; depth++ {
+		if len(syntax.namedNumbers) > 0 {
+			return syntax.namedNumbers, syntax.isBits
+		}
+		syntax, _ = t.resolveType(moduleName, syntax.typeName)
+	}
+	return nil, false
+}
+
+// trapDB converts the traps and objects of the modules to the trap db format
+func (t *mibTree) trapDB() (oidresolver.TrapDBFileContent, []string) {
+	trapDB := oidresolver.TrapDBFileContent{
+		Traps:     make(oidresolver.TrapSpec),
+		Variables: make(oidresolver.VariableSpec),
+	}
+	var unresolved []string
+	for _, moduleName := range t.moduleNames {
+		for _, node := range t.modules[moduleName].nodes {
+			switch node.kind {
+			case "OBJECT-TYPE":
+				oid, ok := t.resolveOID(moduleName, node.name)
+				if !ok {
+					unresolved = append(unresolved, moduleName+"::"+node.name)
+					continue
+				}
+				variable := oidresolver.VariableMetadata{Name: node.name, Description: node.description}
+				if namedNumbers, isBits := t.namedNumbers(moduleName, node.syntax); isBits {
+					variable.Bits = namedNumbers
+				} else {
+					variable.Enumeration = namedNumbers
+				}
+				trapDB.Variables[formatOID(oid)] = variable
+			case "NOTIFICATION-TYPE":
+				oid, ok := t.resolveOID(moduleName, node.name)
+				if !ok {
+					unresolved = append(unresolved, moduleName+"::"+node.name)
+					continue
+				}
+				trapDB.Traps[formatOID(oid)] = oidresolver.TrapMetadata{Name: node.name, MIBName: moduleName, Description: node.description}
+			case "TRAP-TYPE":
+				// SMIv1 traps are converted to SMIv2 as <enterprise>.0.<specific trap>, see RFC 3584
+				enterprise, ok := t.resolveOID(moduleName, node.enterprise)
+				if !ok {
+					unresolved = append(unresolved, moduleName+"::"+node.name)
+					continue
+				}
+				oid := append(append(make([]int, 0, len(enterprise)+2), enterprise...), 0, node.trapNumber)
+				trapDB.Traps[formatOID(oid)] = oidresolver.TrapMetadata{Name: node.name, MIBName: moduleName, Description: node.description}
+			}
+		}
+	}
+	return trapDB, unresolved
+}
+
+func formatOID(oid []int) string {
+	parts := make([]string, len(oid))
+	for i, subID := range oid {
+		parts[i] = strconv.Itoa(subID)
+	}
+	return strings.Join(parts, ".")
+}
+
+// loadMIBDirectory parses the MIB files of a directory and returns the traps and variables they define.
+// Files that can't be parsed are skipped with a warning.
+func loadMIBDirectory(mibsPath string, logger log.Component) (oidresolver.TrapDBFileContent, error) {
+	files, err := os.ReadDir(mibsPath)
+	if err != nil {
+		return oidresolver.TrapDBFileContent{}, fmt.Errorf("failed to read dir `%s`: %w", mibsPath, err)
+	}
+	fileNames := make([]string, 0, len(files))
+	for _, file := range files {
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+		fileNames = append(fileNames, file.Name())
+	}
+	sort.Strings(fileNames)
+
+	var modules []*mibModule
+	for _, fileName := range fileNames {
+		content, err := os.ReadFile(filepath.Join(mibsPath, fileName))
+		if err != nil {
+			logger.Warnf("unable to read MIB file %s: %s", fileName, err)
+			continue
+		}
+		fileModules, err := parseMIB(string(content))
+		if err != nil {
+			logger.Warnf("unable to parse MIB file %s: %s", fileName, err)
+			continue
+		}
+		modules = append(modules, fileModules...)
+	}
+
+	trapDB, unresolved := newMIBTree(modules).trapDB()
+	if len(unresolved) > 0 {
+		logger.Warnf("unable to resolve the OID of %d MIB objects, a module they depend on may be missing from %s: %s",
+			len(unresolved), mibsPath, strings.Join(unresolved, ", "))
+	}
+	logger.Debugf("loaded %d traps and %d variables from %d MIB modules in %s", len(trapDB.Traps), len(trapDB.Variables), len(modules), mibsPath)
+	return trapDB, nil
+}
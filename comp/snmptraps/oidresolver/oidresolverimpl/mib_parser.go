@@ -0,0 +1,536 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package oidresolverimpl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// The MIB parser reads the subset of SMIv1 (RFC 1155, RFC 1212, RFC 1215) and SMIv2 (RFC 2578,
+// RFC 2579) modules needed to resolve traps: OID assignments, OBJECT-TYPE, NOTIFICATION-TYPE,
+// TRAP-TYPE and the enumerations of TEXTUAL-CONVENTION and type assignments. Other constructs
+// are skipped.
+
+type mibTokenKind int
+
+const (
+	mibIdentifier mibTokenKind = iota
+	mibNumber
+	mibString
+	mibSymbol
+)
+
+type mibToken struct {
+	kind  mibTokenKind
+	value string
+	line  int
+}
+
+// mibSyntax is the syntax of an object or a type: the name of the base type, and the named
+// numbers of INTEGER enumerations and BITS
+type mibSyntax struct {
+	typeName     string
+	namedNumbers map[int]string
+	isBits       bool
+}
+
+// mibNode is a node of the OID tree defined by a module
+type mibNode struct {
+	name string
+	// parent is the name of the node the OID is relative to, and subIDs the following sub-identifiers
+	parent string
+	subIDs []int
+	// kind is the macro defining the node, e.g. OBJECT-TYPE or NOTIFICATION-TYPE
+	kind        string
+	description string
+	syntax      *mibSyntax
+	// enterprise and trapNumber define SMIv1 traps, which aren't nodes of the OID tree
+	enterprise string
+	trapNumber int
+}
+
+// mibModule is a parsed MIB module
+type mibModule struct {
+	name string
+	// imports maps the imported symbols to the module they are imported from
+	imports map[string]string
+	nodes   []*mibNode
+	types   map[string]*mibSyntax
+}
+
+type mibParser struct {
+	tokens []mibToken
+	pos    int
+}
+
+// parseMIB parses the modules of a MIB file
+func parseMIB(content string) ([]*mibModule, error) {
+	tokens, err := tokenizeMIB(content)
+	if err != nil {
+		return nil, err
+	}
+	p := &mibParser{tokens: tokens}
+	var modules []*mibModule
+	for !p.done() {
+		module, err := p.parseModule()
+		if err != nil {
+			return nil, err
+		}
+		modules = append(modules, module)
+	}
+	if len(modules) == 0 {
+		return nil, fmt.Errorf("no MIB module found")
+	}
+	return modules, nil
+}
+
+// tokenizeMIB splits a MIB in tokens, dropping the comments
+func tokenizeMIB(content string) ([]mibToken, error) {
+	var tokens []mibToken
+	line := 1
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f':
+			i++
+		case c == '-' && i+1 < len(content) && content[i+1] == '-':
+			// comments run until the end of the line or the next "--"
+			i += 2
+			for i < len(content) && content[i] != '\n' {
+				if content[i] == '-' && i+1 < len(content) && content[i+1] == '-' {
+					i += 2
+					break
+				}
+				i++
+			}
+		case c == '"':
+			end := strings.IndexByte(content[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			value := content[i+1 : i+1+end]
+			tokens = append(tokens, mibToken{kind: mibString, value: value, line: line})
+			line += strings.Count(value, "\n")
+			i += end + 2
+		case c == '\'':
+			// binary or hexadecimal strings: '0101'B, 'ff'H
+			end := strings.IndexByte(content[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated quoted string", line)
+			}
+			i += end + 2
+			if i < len(content) && (content[i] == 'B' || content[i] == 'b' || content[i] == 'H' || content[i] == 'h') {
+				i++
+			}
+			tokens = append(tokens, mibToken{kind: mibString, value: "", line: line})
+		case isDigit(c) || (c == '-' && i+1 < len(content) && isDigit(content[i+1])):
+			start := i
+			i++
+			for i < len(content) && isDigit(content[i]) {
+				i++
+			}
+			tokens = append(tokens, mibToken{kind: mibNumber, value: content[start:i], line: line})
+		case isLetter(c):
+			start := i
+			for i < len(content) && (isLetter(content[i]) || isDigit(content[i]) || content[i] == '_' ||
+				(content[i] == '-' && !(i+1 < len(content) && content[i+1] == '-'))) {
+				i++
+			}
+			tokens = append(tokens, mibToken{kind: mibIdentifier, value: content[start:i], line: line})
+		case strings.HasPrefix(content[i:], "::="):
+			tokens = append(tokens, mibToken{kind: mibSymbol, value: "::=", line: line})
+			i += 3
+		case strings.HasPrefix(content[i:], ".."):
+			tokens = append(tokens, mibToken{kind: mibSymbol, value: "..", line: line})
+			i += 2
+		default:
+			tokens = append(tokens, mibToken{kind: mibSymbol, value: string(c), line: line})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return unicode.IsLetter(rune(c))
+}
+
+func (p *mibParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *mibParser) peek(offset int) mibToken {
+	if p.pos+offset >= len(p.tokens) {
+		return mibToken{kind: mibSymbol}
+	}
+	return p.tokens[p.pos+offset]
+}
+
+func (p *mibParser) next() mibToken {
+	token := p.peek(0)
+	p.pos++
+	return token
+}
+
+func (p *mibParser) is(value string) bool {
+	token := p.peek(0)
+	return token.kind != mibString && token.value == value
+}
+
+func (p *mibParser) expect(value string) error {
+	token := p.next()
+	if token.kind == mibString || token.value != value {
+		return p.errorf(token, "expected %q, got %q", value, token.value)
+	}
+	return nil
+}
+
+func (p *mibParser) errorf(token mibToken, format string, args ...any) error {
+	if p.pos > len(p.tokens) {
+		return fmt.Errorf("unexpected end of file: "+format, args...)
+	}
+	return fmt.Errorf("line %d: "+format, append([]any{token.line}, args...)...)
+}
+
+// parseModule parses `<name> DEFINITIONS ::= BEGIN ... END`
+func (p *mibParser) parseModule() (*mibModule, error) {
+	token := p.next()
+	if token.kind != mibIdentifier {
+		return nil, p.errorf(token, "expected a module name, got %q", token.value)
+	}
+	module := &mibModule{
+		name:    token.value,
+		imports: make(map[string]string),
+		types:   make(map[string]*mibSyntax),
+	}
+	// skip the optional module OID
+	if p.is("{") {
+		p.skipBlock()
+	}
+	if err := p.expect("DEFINITIONS"); err != nil {
+		return nil, err
+	}
+	// skip tag defaults, e.g. IMPLICIT TAGS
+	for !p.done() && !p.is("::=") {
+		p.next()
+	}
+	if err := p.expect("::="); err != nil {
+		return nil, err
+	}
+	if err := p.expect("BEGIN"); err != nil {
+		return nil, err
+	}
+
+	for {
+		if p.done() {
+			return nil, fmt.Errorf("module %s: missing END", module.name)
+		}
+		if p.is("END") {
+			p.next()
+			return module, nil
+		}
+		if err := p.parseAssignment(module); err != nil {
+			return nil, fmt.Errorf("module %s: %w", module.name, err)
+		}
+	}
+}
+
+// parseAssignment parses a statement of the body of a module
+func (p *mibParser) parseAssignment(module *mibModule) error {
+	token := p.next()
+	if token.kind != mibIdentifier {
+		// be lenient with stray symbols
+		return nil
+	}
+	switch token.value {
+	case "IMPORTS":
+		return p.parseImports(module)
+	case "EXPORTS":
+		for !p.done() && !p.is(";") {
+			p.next()
+		}
+		p.next()
+		return nil
+	}
+
+	name := token.value
+	switch {
+	case p.is("MACRO"):
+		// macro definitions, as in SNMPv2-SMI, run until END
+		for !p.done() && !p.is("END") {
+			p.next()
+		}
+		p.next()
+		return nil
+	case p.is("::="):
+		p.next()
+		return p.parseTypeAssignment(module, name)
+	case p.is(","):
+		// stray lists of names (e.g. in EXPORTS without keyword) are ignored
+		return nil
+	}
+
+	node := &mibNode{name: name}
+	if p.is("OBJECT") && p.peek(1).value == "IDENTIFIER" {
+		p.next()
+		p.next()
+		node.kind = "OBJECT IDENTIFIER"
+	} else {
+		node.kind = p.peek(0).value
+	}
+
+	// clauses run until the value assignment
+	for {
+		if p.done() {
+			return fmt.Errorf("%s: missing value assignment", name)
+		}
+		clause := p.next()
+		if clause.kind == mibString {
+			continue
+		}
+		switch clause.value {
+		case "::=":
+			return p.parseValue(module, node)
+		case "SYNTAX":
+			syntax, err := p.parseSyntax()
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			if node.syntax == nil {
+				node.syntax = syntax
+			}
+		case "DESCRIPTION":
+			if description := p.next(); description.kind == mibString && node.description == "" {
+				node.description = normalizeDescription(description.value)
+			}
+		case "ENTERPRISE":
+			node.enterprise = p.next().value
+		case "{":
+			p.pos--
+			p.skipBlock()
+		}
+	}
+}
+
+// parseValue parses the value of an assignment, which is an OID or a trap number
+func (p *mibParser) parseValue(module *mibModule, node *mibNode) error {
+	if node.kind == "TRAP-TYPE" {
+		token := p.next()
+		number, err := strconv.Atoi(token.value)
+		if err != nil || token.kind != mibNumber {
+			return p.errorf(token, "%s: invalid trap number %q", node.name, token.value)
+		}
+		node.trapNumber = number
+		module.nodes = append(module.nodes, node)
+		return nil
+	}
+	if !p.is("{") {
+		// values of other types, e.g. `maxValue INTEGER ::= 10`
+		p.next()
+		return nil
+	}
+	p.next()
+	for !p.is("}") {
+		if p.done() {
+			return fmt.Errorf("%s: unterminated OID value", node.name)
+		}
+		token := p.next()
+		switch token.kind {
+		case mibNumber:
+			subID, err := strconv.Atoi(token.value)
+			if err != nil || subID < 0 {
+				return p.errorf(token, "%s: invalid sub-identifier %q", node.name, token.value)
+			}
+			node.subIDs = append(node.subIDs, subID)
+		case mibIdentifier:
+			// name(number) forms give the number, a leading name is the parent
+			if p.is("(") {
+				p.next()
+				number := p.next()
+				subID, err := strconv.Atoi(number.value)
+				if err != nil || subID < 0 {
+					return p.errorf(number, "%s: invalid sub-identifier %q", node.name, number.value)
+				}
+				if err := p.expect(")"); err != nil {
+					return err
+				}
+				if node.parent == "" && len(node.subIDs) == 0 {
+					// a fully numbered OID, e.g. { iso(1) org(3) }, is absolute
+					node.parent = "."
+				}
+				node.subIDs = append(node.subIDs, subID)
+				continue
+			}
+			if node.parent != "" || len(node.subIDs) > 0 {
+				return p.errorf(token, "%s: unexpected %q in OID value", node.name, token.value)
+			}
+			node.parent = token.value
+		default:
+			return p.errorf(token, "%s: unexpected %q in OID value", node.name, token.value)
+		}
+	}
+	p.next()
+	if node.parent == "" {
+		node.parent = "."
+	}
+	module.nodes = append(module.nodes, node)
+	return nil
+}
+
+// parseImports parses `IMPORTS a, b FROM MODULE-A c FROM MODULE-B ;`
+func (p *mibParser) parseImports(module *mibModule) error {
+	var symbols []string
+	for {
+		if p.done() {
+			return fmt.Errorf("unterminated IMPORTS")
+		}
+		token := p.next()
+		switch {
+		case token.value == ";" && token.kind == mibSymbol:
+			return nil
+		case token.value == "FROM" && token.kind == mibIdentifier:
+			from := p.next()
+			for _, symbol := range symbols {
+				module.imports[symbol] = from.value
+			}
+			symbols = symbols[:0]
+		case token.kind == mibIdentifier:
+			symbols = append(symbols, token.value)
+		}
+	}
+}
+
+// parseTypeAssignment parses `<Name> ::= TEXTUAL-CONVENTION ... SYNTAX <syntax>` and `<Name> ::= <syntax>`
+func (p *mibParser) parseTypeAssignment(module *mibModule, name string) error {
+	if p.is("TEXTUAL-CONVENTION") {
+		p.next()
+		for !p.is("SYNTAX") {
+			if p.done() {
+				return fmt.Errorf("%s: missing SYNTAX", name)
+			}
+			p.next()
+		}
+		p.next()
+	}
+	syntax, err := p.parseSyntax()
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	module.types[name] = syntax
+	return nil
+}
+
+// parseSyntax parses a type, keeping the base type and named numbers
+func (p *mibParser) parseSyntax() (*mibSyntax, error) {
+	syntax := &mibSyntax{}
+	// tagged types, e.g. [APPLICATION 1] IMPLICIT INTEGER
+	if p.is("[") {
+		p.skipBlock()
+	}
+	if p.is("IMPLICIT") || p.is("EXPLICIT") {
+		p.next()
+	}
+
+	token := p.next()
+	if token.kind != mibIdentifier {
+		return nil, p.errorf(token, "invalid syntax %q", token.value)
+	}
+	syntax.typeName = token.value
+	switch token.value {
+	case "OCTET", "OBJECT":
+		// OCTET STRING, OBJECT IDENTIFIER
+		syntax.typeName += " " + p.next().value
+	case "SEQUENCE", "SET":
+		if p.is("OF") {
+			p.next()
+			p.next()
+			return syntax, nil
+		}
+	case "BITS":
+		syntax.isBits = true
+	}
+
+	if p.is("{") {
+		if syntax.typeName == "SEQUENCE" || syntax.typeName == "SET" || syntax.typeName == "CHOICE" {
+			p.skipBlock()
+			return syntax, nil
+		}
+		namedNumbers, err := p.parseNamedNumbers()
+		if err != nil {
+			return nil, err
+		}
+		syntax.namedNumbers = namedNumbers
+	}
+	// constraints, e.g. (SIZE (0..255)) or (0..100)
+	if p.is("(") {
+		p.skipBlock()
+	}
+	return syntax, nil
+}
+
+// parseNamedNumbers parses `{ up(1), down(2) }`
+func (p *mibParser) parseNamedNumbers() (map[int]string, error) {
+	namedNumbers := make(map[int]string)
+	p.next()
+	for !p.is("}") {
+		if p.done() {
+			return nil, fmt.Errorf("unterminated named numbers")
+		}
+		name := p.next()
+		if name.kind == mibSymbol && name.value == "," {
+			continue
+		}
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		number := p.next()
+		value, err := strconv.Atoi(number.value)
+		if err != nil {
+			return nil, p.errorf(number, "invalid number %q for %s", number.value, name.value)
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		namedNumbers[value] = name.value
+	}
+	p.next()
+	return namedNumbers, nil
+}
+
+// skipBlock skips balanced brackets, braces or parentheses starting at the current token
+func (p *mibParser) skipBlock() {
+	depth := 0
+	for !p.done() {
+		token := p.next()
+		if token.kind != mibSymbol {
+			continue
+		}
+		switch token.value {
+		case "{", "(", "[":
+			depth++
+		case "}", ")", "]":
+			depth--
+		}
+		if depth <= 0 {
+			return
+		}
+	}
+}
+
+// normalizeDescription collapses the indentation and line breaks of descriptions
+func normalizeDescription(description string) string {
+	return strings.Join(strings.Fields(description), " ")
+}
@@ -0,0 +1,344 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package oidresolverimpl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	logmock "github.com/DataDog/datadog-agent/comp/core/log/mock"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/oidresolver"
+)
+
+const testTCMIB = `
+ACME-TC DEFINITIONS ::= BEGIN
+
+IMPORTS
+    TEXTUAL-CONVENTION FROM SNMPv2-TC;
+
+AcmeLinkState ::= TEXTUAL-CONVENTION
+    STATUS      current
+    DESCRIPTION "The state of a link." -- a comment -- SYNTAX INTEGER { up(1), down(2), testing(3) }
+
+AcmeDerivedState ::= AcmeLinkState
+
+AcmeFlags ::= TEXTUAL-CONVENTION
+    STATUS      current
+    DESCRIPTION "Flags of a link."
+    SYNTAX      BITS { fault(0), warning(1) }
+
+END
+`
+
+const testSMIv2MIB = `
+-- The ACME MIB, with a comment containing "quotes" and ::= symbols
+ACME-MIB DEFINITIONS ::= BEGIN
+
+IMPORTS
+    MODULE-IDENTITY, OBJECT-TYPE, NOTIFICATION-TYPE, Integer32, enterprises
+        FROM SNMPv2-SMI
+    DisplayString, TruthValue
+        FROM SNMPv2-TC
+    AcmeDerivedState, AcmeFlags
+        FROM ACME-TC;
+
+acmeMIB MODULE-IDENTITY
+    LAST-UPDATED "202401010000Z"
+    ORGANIZATION "ACME"
+    CONTACT-INFO "ACME support"
+    DESCRIPTION  "The MIB of ACME devices."
+    REVISION     "202401010000Z"
+    DESCRIPTION  "Initial version."
+    ::= { enterprises 99999 }
+
+acmeObjects       OBJECT IDENTIFIER ::= { acmeMIB 1 }
+acmeNotifications OBJECT IDENTIFIER ::= { acmeMIB 0 }
+
+AcmeLinkEntry ::= SEQUENCE {
+    acmeLinkIndex  Integer32,
+    acmeLinkState  AcmeDerivedState
+}
+
+acmeLinkTable OBJECT-TYPE
+    SYNTAX      SEQUENCE OF AcmeLinkEntry
+    MAX-ACCESS  not-accessible
+    STATUS      current
+    DESCRIPTION "The links."
+    ::= { acmeObjects 1 }
+
+acmeLinkEntry OBJECT-TYPE
+    SYNTAX      AcmeLinkEntry
+    MAX-ACCESS  not-accessible
+    STATUS      current
+    DESCRIPTION "A link."
+    INDEX       { acmeLinkIndex }
+    ::= { acmeLinkTable 1 }
+
+acmeLinkIndex OBJECT-TYPE
+    SYNTAX      Integer32 (1..2147483647)
+    MAX-ACCESS  not-accessible
+    STATUS      current
+    DESCRIPTION "The index of the link."
+    ::= { acmeLinkEntry 1 }
+
+acmeLinkState OBJECT-TYPE
+    SYNTAX      AcmeDerivedState
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION
+        "The state of the link,
+         as reported by the device."
+    ::= { acmeLinkEntry 2 }
+
+acmeLinkName OBJECT-TYPE
+    SYNTAX      DisplayString (SIZE (0..255))
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "The name of the link."
+    DEFVAL      { "" }
+    ::= { acmeLinkEntry 3 }
+
+acmeLinkFlags OBJECT-TYPE
+    SYNTAX      AcmeFlags
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "The flags of the link."
+    DEFVAL      { { warning } }
+    ::= { acmeLinkEntry 4 }
+
+acmeFanPresent OBJECT-TYPE
+    SYNTAX      TruthValue
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "Whether the fan is present."
+    ::= { acmeObjects 2 }
+
+acmeFanSpeed OBJECT-TYPE
+    SYNTAX      INTEGER { low(1), high(2), unknown(-1) }
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "The speed of the fan."
+    ::= { acmeObjects 3 }
+
+acmeLinkStateChange NOTIFICATION-TYPE
+    OBJECTS     { acmeLinkState, acmeLinkName }
+    STATUS      current
+    DESCRIPTION "Sent when the state of a link changes."
+    ::= { acmeNotifications 1 }
+
+END
+`
+
+const testSMIv1MIB = `
+ACME-V1-MIB DEFINITIONS ::= BEGIN
+
+IMPORTS
+    enterprises FROM RFC1155-SMI
+    OBJECT-TYPE FROM RFC-1212
+    TRAP-TYPE   FROM RFC-1215;
+
+acmeLegacy OBJECT IDENTIFIER ::= { iso(1) org(3) dod(6) internet(1) private(4) enterprises(1) 99998 }
+
+acmeLegacyTemperature OBJECT-TYPE
+    SYNTAX  INTEGER
+    ACCESS  read-only
+    STATUS  mandatory
+    DESCRIPTION "The temperature."
+    ::= { acmeLegacy 1 }
+
+acmeLegacyOverheat TRAP-TYPE
+    ENTERPRISE  acmeLegacy
+    VARIABLES   { acmeLegacyTemperature }
+    DESCRIPTION "Sent when the device overheats."
+    ::= 3
+
+END
+
+ACME-V1-EXTRA-MIB DEFINITIONS ::= BEGIN
+
+acmeLegacyExtra OBJECT IDENTIFIER ::= { acmeLegacy 2 }
+
+END
+`
+
+func TestParseMIB(t *testing.T) {
+	modules, err := parseMIB(testSMIv1MIB)
+	require.NoError(t, err)
+	require.Len(t, modules, 2)
+
+	module := modules[0]
+	assert.Equal(t, "ACME-V1-MIB", module.name)
+	assert.Equal(t, map[string]string{
+		"enterprises": "RFC1155-SMI",
+		"OBJECT-TYPE": "RFC-1212",
+		"TRAP-TYPE":   "RFC-1215",
+	}, module.imports)
+	assert.Equal(t, []*mibNode{
+		{name: "acmeLegacy", parent: ".", subIDs: []int{1, 3, 6, 1, 4, 1, 99998}, kind: "OBJECT IDENTIFIER"},
+		{name: "acmeLegacyTemperature", parent: "acmeLegacy", subIDs: []int{1}, kind: "OBJECT-TYPE", description: "The temperature.", syntax: &mibSyntax{typeName: "INTEGER"}},
+		{name: "acmeLegacyOverheat", kind: "TRAP-TYPE", description: "Sent when the device overheats.", enterprise: "acmeLegacy", trapNumber: 3},
+	}, module.nodes)
+
+	assert.Equal(t, "ACME-V1-EXTRA-MIB", modules[1].name)
+	assert.Equal(t, []*mibNode{
+		{name: "acmeLegacyExtra", parent: "acmeLegacy", subIDs: []int{2}, kind: "OBJECT IDENTIFIER"},
+	}, modules[1].nodes)
+}
+
+func TestParseMIB_Types(t *testing.T) {
+	modules, err := parseMIB(testTCMIB)
+	require.NoError(t, err)
+	require.Len(t, modules, 1)
+	assert.Equal(t, map[string]*mibSyntax{
+		"AcmeLinkState":    {typeName: "INTEGER", namedNumbers: map[int]string{1: "up", 2: "down", 3: "testing"}},
+		"AcmeDerivedState": {typeName: "AcmeLinkState"},
+		"AcmeFlags":        {typeName: "BITS", namedNumbers: map[int]string{0: "fault", 1: "warning"}, isBits: true},
+	}, modules[0].types)
+}
+
+func TestParseMIB_Errors(t *testing.T) {
+	for name, content := range map[string]string{
+		"empty":              "-- nothing but a comment",
+		"missing END":        "FOO-MIB DEFINITIONS ::= BEGIN foo OBJECT IDENTIFIER ::= { bar 1 }",
+		"not a module":       "foo OBJECT IDENTIFIER ::= { bar 1 }",
+		"unterminated str":   `FOO-MIB DEFINITIONS ::= BEGIN foo OBJECT-TYPE DESCRIPTION "foo`,
+		"invalid sub-id":     "FOO-MIB DEFINITIONS ::= BEGIN foo OBJECT IDENTIFIER ::= { bar baz } END",
+		"invalid trap value": "FOO-MIB DEFINITIONS ::= BEGIN foo TRAP-TYPE ENTERPRISE bar ::= baz END",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseMIB(content)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestMIBTreeTrapDB(t *testing.T) {
+	var modules []*mibModule
+	// the order of the modules doesn't matter
+	for _, content := range []string{testSMIv2MIB, testSMIv1MIB, testTCMIB} {
+		parsed, err := parseMIB(content)
+		require.NoError(t, err)
+		modules = append(modules, parsed...)
+	}
+
+	trapDB, unresolved := newMIBTree(modules).trapDB()
+	assert.Empty(t, unresolved)
+	assert.Equal(t, oidresolver.TrapDBFileContent{
+		Traps: oidresolver.TrapSpec{
+			"1.3.6.1.4.1.99999.0.1": {Name: "acmeLinkStateChange", MIBName: "ACME-MIB", Description: "Sent when the state of a link changes."},
+			"1.3.6.1.4.1.99998.0.3": {Name: "acmeLegacyOverheat", MIBName: "ACME-V1-MIB", Description: "Sent when the device overheats."},
+		},
+		Variables: oidresolver.VariableSpec{
+			"1.3.6.1.4.1.99999.1.1":     {Name: "acmeLinkTable", Description: "The links."},
+			"1.3.6.1.4.1.99999.1.1.1":   {Name: "acmeLinkEntry", Description: "A link."},
+			"1.3.6.1.4.1.99999.1.1.1.1": {Name: "acmeLinkIndex", Description: "The index of the link."},
+			"1.3.6.1.4.1.99999.1.1.1.2": {
+				Name:        "acmeLinkState",
+				Description: "The state of the link, as reported by the device.",
+				Enumeration: map[int]string{1: "up", 2: "down", 3: "testing"},
+			},
+			"1.3.6.1.4.1.99999.1.1.1.3": {Name: "acmeLinkName", Description: "The name of the link."},
+			"1.3.6.1.4.1.99999.1.1.1.4": {Name: "acmeLinkFlags", Description: "The flags of the link.", Bits: map[int]string{0: "fault", 1: "warning"}},
+			"1.3.6.1.4.1.99999.1.2":     {Name: "acmeFanPresent", Description: "Whether the fan is present.", Enumeration: map[int]string{1: "true", 2: "false"}},
+			"1.3.6.1.4.1.99999.1.3":     {Name: "acmeFanSpeed", Description: "The speed of the fan.", Enumeration: map[int]string{1: "low", 2: "high", -1: "unknown"}},
+			"1.3.6.1.4.1.99998.1":       {Name: "acmeLegacyTemperature", Description: "The temperature."},
+		},
+	}, trapDB)
+}
+
+func TestMIBTreeTrapDB_Unresolved(t *testing.T) {
+	modules, err := parseMIB(`
+FOO-MIB DEFINITIONS ::= BEGIN
+fooA OBJECT-TYPE SYNTAX INTEGER ::= { fooB 1 }
+fooB OBJECT-TYPE SYNTAX INTEGER ::= { fooA 1 }
+fooC OBJECT-TYPE SYNTAX INTEGER ::= { missing 1 }
+fooD OBJECT-TYPE SYNTAX INTEGER ::= { enterprises 1 }
+END`)
+	require.NoError(t, err)
+
+	trapDB, unresolved := newMIBTree(modules).trapDB()
+	assert.Equal(t, []string{"FOO-MIB::fooA", "FOO-MIB::fooB", "FOO-MIB::fooC"}, unresolved)
+	assert.Equal(t, oidresolver.VariableSpec{"1.3.6.1.4.1.1": {Name: "fooD"}}, trapDB.Variables)
+}
+
+func TestLoadMIBDirectory(t *testing.T) {
+	mibsPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(mibsPath, "ACME-MIB.mib"), []byte(testSMIv2MIB), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(mibsPath, "ACME-TC.txt"), []byte(testTCMIB), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(mibsPath, "BROKEN-MIB"), []byte("BROKEN-MIB DEFINITIONS ::= BEGIN"), 0o644))
+	require.NoError(t, os.Mkdir(filepath.Join(mibsPath, "subdir"), 0o755))
+
+	trapDB, err := loadMIBDirectory(mibsPath, logmock.New(t))
+	require.NoError(t, err)
+	assert.Len(t, trapDB.Traps, 1)
+	assert.Len(t, trapDB.Variables, 8)
+	assert.Equal(t, map[int]string{1: "up", 2: "down", 3: "testing"}, trapDB.Variables["1.3.6.1.4.1.99999.1.1.1.2"].Enumeration)
+
+	_, err = loadMIBDirectory(filepath.Join(mibsPath, "missing"), logmock.New(t))
+	assert.Error(t, err)
+}
+
+func TestResolverWithMIBs(t *testing.T) {
+	confdPath := t.TempDir()
+	trapsDBPath := filepath.Join(confdPath, "snmp.d", "traps_db")
+	require.NoError(t, os.MkdirAll(trapsDBPath, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(trapsDBPath, "dd_traps_db.yaml"), []byte(`
+traps:
+  1.3.6.1.4.1.99999.0.1:
+    name: oldName
+    mib: OLD-MIB
+  1.3.6.1.6.3.1.1.5.3:
+    name: linkDown
+    mib: IF-MIB
+vars: {}
+`), 0o644))
+
+	// no MIB directory
+	resolver, err := newMultiFilesOIDResolver(confdPath, "", logmock.New(t))
+	require.NoError(t, err)
+	trap, err := resolver.GetTrapMetadata("1.3.6.1.4.1.99999.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, "oldName", trap.Name)
+
+	// the default MIB directory takes precedence over the traps db files
+	mibsPath := filepath.Join(confdPath, "snmp.d", "mibs")
+	require.NoError(t, os.MkdirAll(mibsPath, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(mibsPath, "ACME-MIB"), []byte(testSMIv2MIB), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(mibsPath, "ACME-TC"), []byte(testTCMIB), 0o644))
+	resolver, err = newMultiFilesOIDResolver(confdPath, "", logmock.New(t))
+	require.NoError(t, err)
+	trap, err = resolver.GetTrapMetadata("1.3.6.1.4.1.99999.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, "acmeLinkStateChange", trap.Name)
+	assert.Equal(t, "ACME-MIB", trap.MIBName)
+	variable, err := resolver.GetVariableMetadata("1.3.6.1.4.1.99999.0.1", "1.3.6.1.4.1.99999.1.1.1.2.7")
+	require.NoError(t, err)
+	assert.Equal(t, "acmeLinkState", variable.Name)
+	assert.Equal(t, "down", variable.Enumeration[2])
+	trap, err = resolver.GetTrapMetadata("1.3.6.1.6.3.1.1.5.3")
+	require.NoError(t, err)
+	assert.Equal(t, "linkDown", trap.Name)
+
+	// a configured MIB directory replaces the default one
+	otherMIBsPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(otherMIBsPath, "ACME-V1-MIB"), []byte(testSMIv1MIB), 0o644))
+	resolver, err = newMultiFilesOIDResolver(confdPath, otherMIBsPath, logmock.New(t))
+	require.NoError(t, err)
+	trap, err = resolver.GetTrapMetadata("1.3.6.1.4.1.99999.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, "oldName", trap.Name)
+	trap, err = resolver.GetTrapMetadata("1.3.6.1.4.1.99998.0.3")
+	require.NoError(t, err)
+	assert.Equal(t, "acmeLegacyOverheat", trap.Name)
+
+	// a missing MIB directory is not fatal
+	_, err = newMultiFilesOIDResolver(confdPath, filepath.Join(confdPath, "missing"), logmock.New(t))
+	assert.NoError(t, err)
+}
@@ -9,6 +9,7 @@ package oidresolverimpl
 import (
 	"compress/gzip"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
@@ -58,12 +59,13 @@ type multiFilesOIDResolver struct {
 }
 
 func newResolver(conf config.Component, logger log.Component) (oidresolver.Component, error) {
-	return newMultiFilesOIDResolver(conf.GetString("confd_path"), logger)
+	return newMultiFilesOIDResolver(conf.GetString("confd_path"), conf.GetString("network_devices.snmp_traps.mibs_path"), logger)
 }
 
 // newMultiFilesOIDResolver creates a new MultiFilesOIDResolver instance by loading json or yaml files
-// (optionnally gzipped) located in the directory snmp.d/traps_db/
-func newMultiFilesOIDResolver(confdPath string, logger log.Component) (*multiFilesOIDResolver, error) {
+// (optionnally gzipped) located in the directory snmp.d/traps_db/, then the raw MIB files located in mibsPath,
+// or in the directory snmp.d/mibs/ if mibsPath is empty.
+func newMultiFilesOIDResolver(confdPath string, mibsPath string, logger log.Component) (*multiFilesOIDResolver, error) {
 	oidResolver := &multiFilesOIDResolver{
 		traps:  make(oidresolver.TrapSpec),
 		logger: logger,
@@ -83,6 +85,20 @@ func newMultiFilesOIDResolver(confdPath string, logger log.Component) (*multiFil
 			logger.Warnf("unable to load trap db file %s: %s", fileName, err)
 		}
 	}
+
+	// MIB files are loaded last so that they take precedence over the trap db files
+	if mibsPath == "" {
+		mibsPath = filepath.Join(confdPath, "snmp.d", "mibs")
+		if _, err := os.Stat(mibsPath); errors.Is(err, fs.ErrNotExist) {
+			return oidResolver, nil
+		}
+	}
+	trapDB, err := loadMIBDirectory(mibsPath, logger)
+	if err != nil {
+		logger.Warnf("unable to load MIB files: %s", err)
+		return oidResolver, nil
+	}
+	oidResolver.updateResolverWithData(trapDB)
 	return oidResolver, nil
 }
 
@@ -34,7 +34,7 @@ func remote(c corecompcfg.Component, ipcAddress string, ipc ipc.Component) (conf
 		ipc.GetAuthToken(), // TODO IPC: GRPC client will be provided by the IPC component
 		ipc.GetTLSClientConfig(),
 		rc.WithAgent(rcClientName, version.AgentVersion),
-		rc.WithProducts(state.ProductAPMSampling, state.ProductAgentConfig),
+		rc.WithProducts(state.ProductAPMSampling, state.ProductAgentConfig, state.ProductAPMSpanFilters),
 		rc.WithPollInterval(rcClientPollInterval),
 		rc.WithDirectorRootOverride(c.GetString("site"), c.GetString("remote_configuration.director_root")),
 	)
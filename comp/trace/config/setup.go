@@ -12,6 +12,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -313,6 +314,50 @@ func applyDatadogConfig(c *config.AgentConfig, core corecompcfg.Component) error
 		c.ProbabilisticSamplerHashSeed = uint32(core.GetInt("apm_config.probabilistic_sampler.hash_seed"))
 	}
 
+	if core.IsSet("apm_config.tail_sampling.enabled") {
+		c.TailSampling.Enabled = core.GetBool("apm_config.tail_sampling.enabled")
+	}
+	if k := "apm_config.tail_sampling.decision_wait"; core.IsSet(k) {
+		if wait := core.GetDuration(k); wait > 0 {
+			c.TailSampling.DecisionWait = wait
+		} else {
+			log.Errorf("Invalid %q: %v, it must be positive; using %v", k, wait, c.TailSampling.DecisionWait)
+		}
+	}
+	if core.IsSet("apm_config.tail_sampling.max_traces") {
+		c.TailSampling.MaxTraces = core.GetInt("apm_config.tail_sampling.max_traces")
+	}
+	if core.IsSet("apm_config.tail_sampling.max_memory_bytes") {
+		c.TailSampling.MaxMemoryBytes = core.GetInt64("apm_config.tail_sampling.max_memory_bytes")
+	}
+	if k := "apm_config.tail_sampling.policies"; core.IsSet(k) {
+		policies := make([]*config.TailSamplingPolicy, 0)
+		if err := structure.UnmarshalKey(core, k, &policies); err != nil {
+			log.Errorf("Bad format for %q it should be of the form '[{\"name\": \"errors\",\"type\":\"error\"}]', error: %v", k, err)
+		} else {
+			c.TailSampling.Policies = make([]*config.TailSamplingPolicy, 0, len(policies))
+			for _, p := range policies {
+				if p == nil {
+					continue
+				}
+				if err := p.Validate(); err != nil {
+					log.Errorf("Ignoring invalid tail sampling policy in %q: %v", k, err)
+					continue
+				}
+				c.TailSampling.Policies = append(c.TailSampling.Policies, p)
+			}
+		}
+	}
+
+	if k := "apm_config.span_filters"; core.IsSet(k) {
+		rules := make([]*config.SpanFilterRule, 0)
+		if err := structure.UnmarshalKey(core, k, &rules); err != nil {
+			log.Errorf("Bad format for %q it should be of the form '[{\"name\": \"health\",\"action\":\"drop\",\"condition\":\"...\"}]', error: %v", k, err)
+		} else {
+			c.SpanFilters = rules
+		}
+	}
+
 	if core.IsSet("apm_config.error_tracking_standalone.enabled") {
 		c.ErrorTrackingStandalone = core.GetBool("apm_config.error_tracking_standalone.enabled")
 	}
@@ -471,6 +516,12 @@ func applyDatadogConfig(c *config.AgentConfig, core corecompcfg.Component) error
 	c.Obfuscation.CreditCards.Enabled = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.credit_cards.enabled")
 	c.Obfuscation.CreditCards.Luhn = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.credit_cards.luhn")
 	c.Obfuscation.CreditCards.KeepValues = pkgconfigsetup.Datadog().GetStringSlice("apm_config.obfuscation.credit_cards.keep_values")
+	c.Obfuscation.GraphQL.Enabled = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.graphql.enabled")
+	c.Obfuscation.GraphQL.KeepValues = pkgconfigsetup.Datadog().GetStringSlice("apm_config.obfuscation.graphql.keep_values")
+	c.Obfuscation.CQL.Enabled = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.cql.enabled")
+	c.Obfuscation.CQL.ReplaceDigits = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.cql.replace_digits")
+	c.Obfuscation.DynamoDB.Enabled = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.dynamodb.enabled")
+	c.Obfuscation.DynamoDB.ReplaceDigits = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.dynamodb.replace_digits")
 	c.Obfuscation.Cache.Enabled = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.cache.enabled")
 	c.Obfuscation.Cache.MaxSize = pkgconfigsetup.Datadog().GetInt64("apm_config.obfuscation.cache.max_size")
 
@@ -529,6 +580,20 @@ func applyDatadogConfig(c *config.AgentConfig, core corecompcfg.Component) error
 		// Default of 4 was chosen through experimentation, but may not be the optimal value.
 		c.MaxSenderRetries = 4
 	}
+	if core.IsSet("apm_config.disk_buffer.enabled") {
+		c.DiskBuffer.Enabled = core.GetBool("apm_config.disk_buffer.enabled")
+	}
+	if core.IsSet("apm_config.disk_buffer.path") {
+		c.DiskBuffer.Path = core.GetString("apm_config.disk_buffer.path")
+	} else {
+		c.DiskBuffer.Path = filepath.Join(core.GetString("run_path"), "apm_disk_buffer")
+	}
+	if core.IsSet("apm_config.disk_buffer.max_size_bytes") {
+		c.DiskBuffer.MaxSizeBytes = core.GetInt64("apm_config.disk_buffer.max_size_bytes")
+	}
+	if core.IsSet("apm_config.disk_buffer.max_age") {
+		c.DiskBuffer.MaxAge = core.GetDuration("apm_config.disk_buffer.max_age")
+	}
 	if core.IsSet("apm_config.sync_flushing") {
 		c.SynchronousFlushing = core.GetBool("apm_config.sync_flushing")
 	}
@@ -663,6 +728,8 @@ func applyDatadogConfig(c *config.AgentConfig, core corecompcfg.Component) error
 	if k := "apm_config.enable_v1_trace_endpoint"; core.IsSet(k) {
 		c.EnableV1TraceEndpoint = core.GetBool("apm_config.enable_v1_trace_endpoint")
 	}
+	c.EnableZipkinEndpoint = core.GetBool("apm_config.zipkin_receiver.enabled") // default is false
+	c.EnableJaegerEndpoint = core.GetBool("apm_config.jaeger_receiver.enabled") // default is false
 	c.SendAllInternalStats = core.GetBool("apm_config.send_all_internal_stats") // default is false
 	c.DebugServerPort = core.GetInt("apm_config.debug.port")
 	return nil
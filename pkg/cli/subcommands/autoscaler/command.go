@@ -0,0 +1,147 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+// Package autoscaler implements 'agent autoscaler'.
+package autoscaler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/fx"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/DataDog/datadog-agent/comp/core"
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	ipc "github.com/DataDog/datadog-agent/comp/core/ipc/def"
+	ipcfx "github.com/DataDog/datadog-agent/comp/core/ipc/fx"
+	ipchttp "github.com/DataDog/datadog-agent/comp/core/ipc/httphelpers"
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	secretsnoopfx "github.com/DataDog/datadog-agent/comp/core/secrets/fx-noop"
+	autoscalingWorkload "github.com/DataDog/datadog-agent/pkg/clusteragent/autoscaling/workload"
+	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
+	"github.com/DataDog/datadog-agent/pkg/util/flavor"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+// cliParams are the command-line arguments for the simulate subcommand
+type cliParams struct {
+	GlobalParams
+	autoscalerID string
+	duration     time.Duration
+	step         time.Duration
+	json         bool
+}
+
+// GlobalParams contains the values of agent-global Cobra flags.
+//
+// A pointer to this type is passed to SubcommandFactory's, but its contents
+// are not valid until Cobra calls the subcommand's Run or RunE function.
+type GlobalParams struct {
+	ConfFilePath string
+	ConfigName   string
+	LoggerName   string
+}
+
+// MakeCommand returns an `autoscaler` command to be used by cluster- binaries.
+func MakeCommand(globalParamsGetter func() GlobalParams) *cobra.Command {
+	cliParams := &cliParams{}
+
+	autoscalerCmd := &cobra.Command{
+		Use:   "autoscaler",
+		Short: "Inspect the workload autoscaling of a running agent",
+		Long:  ``,
+	}
+
+	simulateCmd := &cobra.Command{
+		Use:   "simulate <namespace>/<name>",
+		Short: "Print the scaling actions an autoscaler would take over time, without applying them",
+		Long: `Replays the horizontal and vertical scaling decisions of a DatadogPodAutoscaler over time,
+based on the current recommendations and state of the autoscaler. The output details why actions are
+limited (stabilization windows, scaling rules, constraints). Nothing is applied to the cluster.
+
+Recommendations are kept at their current values for the whole simulation: the local recommender is
+not run again at each step. The observed usage is only displayed when autoscaling.failover.enabled is
+set, as it is read from the local metrics store.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			globalParams := globalParamsGetter()
+
+			cliParams.GlobalParams = globalParams
+			cliParams.autoscalerID = args[0]
+
+			return fxutil.OneShot(simulate,
+				fx.Supply(cliParams),
+				fx.Supply(core.BundleParams{
+					ConfigParams: config.NewAgentParams(
+						globalParams.ConfFilePath,
+						config.WithConfigName(globalParams.ConfigName),
+					),
+					LogParams: log.ForOneShot(globalParams.LoggerName, "off", true)}),
+				core.Bundle(),
+				secretsnoopfx.Module(),
+				ipcfx.ModuleReadOnly(),
+			)
+		},
+	}
+	simulateCmd.Flags().DurationVarP(&cliParams.duration, "duration", "d", autoscalingWorkload.DefaultSimulationDuration, "duration of the simulation")
+	simulateCmd.Flags().DurationVarP(&cliParams.step, "step", "s", autoscalingWorkload.DefaultSimulationStep, "interval between two simulated decisions")
+	simulateCmd.Flags().BoolVarP(&cliParams.json, "json", "j", false, "print the simulation as JSON")
+
+	autoscalerCmd.AddCommand(simulateCmd)
+	return autoscalerCmd
+}
+
+func simulate(_ log.Component, config config.Component, client ipc.HTTPClient, cliParams *cliParams) error {
+	if !strings.Contains(cliParams.autoscalerID, "/") {
+		return fmt.Errorf("invalid autoscaler %q, expected format: <namespace>/<name>", cliParams.autoscalerID)
+	}
+
+	if flavor.GetFlavor() != flavor.ClusterAgent {
+		return fmt.Errorf("running autoscaler simulate is only supported on the cluster agent")
+	}
+
+	ipcAddress, err := pkgconfigsetup.GetIPCAddress(config)
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	query.Set("id", cliParams.autoscalerID)
+	query.Set("duration", cliParams.duration.String())
+	query.Set("step", cliParams.step.String())
+	urlstr := fmt.Sprintf("https://%v:%v/autoscaler-simulate?%s", ipcAddress, config.GetInt("cluster_agent.cmd_port"), query.Encode())
+
+	return getSimulation(client, color.Output, urlstr, cliParams.json)
+}
+
+func getSimulation(client ipc.HTTPClient, w io.Writer, url string, printJSON bool) error {
+	r, err := client.Get(url, ipchttp.WithLeaveConnectionOpen)
+	if err != nil {
+		if r != nil && string(r) != "" {
+			return fmt.Errorf("the agent ran into an error while simulating the autoscaler: %s", string(r))
+		}
+		return fmt.Errorf("failed to query the agent (running?): %s", err)
+	}
+
+	if printJSON {
+		fmt.Fprintln(w, string(r))
+		return nil
+	}
+
+	simulation := autoscalingWorkload.Simulation{}
+	if err = json.Unmarshal(r, &simulation); err != nil {
+		return fmt.Errorf("error unmarshalling json: %s", err)
+	}
+
+	simulation.Print(w)
+	return nil
+}
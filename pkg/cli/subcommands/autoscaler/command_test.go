@@ -0,0 +1,35 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package autoscaler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/core"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+func TestCommand(t *testing.T) {
+	commands := []*cobra.Command{
+		MakeCommand(func() GlobalParams {
+			return GlobalParams{}
+		}),
+	}
+
+	fxutil.TestOneShotSubcommand(t,
+		commands,
+		[]string{"autoscaler", "simulate", "default/dpa-0", "--duration", "1h", "--step", "1m"},
+		simulate,
+		func(cliParams *cliParams, _ core.BundleParams) {
+			require.Equal(t, "default/dpa-0", cliParams.autoscalerID)
+			require.Equal(t, time.Hour, cliParams.duration)
+			require.Equal(t, time.Minute, cliParams.step)
+		})
+}
@@ -10,6 +10,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -67,6 +68,7 @@ import (
 	metricscompression "github.com/DataDog/datadog-agent/comp/serializer/metricscompression/fx"
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	"github.com/DataDog/datadog-agent/pkg/cli/standalone"
+	"github.com/DataDog/datadog-agent/pkg/cli/subcommands/check/replay"
 	pkgcollector "github.com/DataDog/datadog-agent/pkg/collector"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/collector/check/stats"
@@ -78,6 +80,7 @@ import (
 	statuscollector "github.com/DataDog/datadog-agent/pkg/status/collector"
 	"github.com/DataDog/datadog-agent/pkg/util/defaultpaths"
 	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+	"github.com/DataDog/datadog-agent/pkg/util/hostname"
 	"github.com/DataDog/datadog-agent/pkg/util/option"
 	"github.com/DataDog/datadog-agent/pkg/util/scrubber"
 )
@@ -119,6 +122,12 @@ type cliParams struct {
 	discoveryRetryInterval    uint
 	discoveryMinInstances     uint
 	generateIntegrationTraces bool
+	recordDir                 string
+	replayDir                 string
+	replayTolerance           float64
+
+	// fixtures is the recording or the replay in progress, if any
+	fixtures *replay.Session
 }
 
 // GlobalParams contains the values of agent-global Cobra flags.
@@ -151,6 +160,26 @@ func MakeCommand(globalParamsGetter func() GlobalParams) *cobra.Command {
 			cliParams.cmd = cmd
 			cliParams.args = args
 
+			if cliParams.recordDir != "" && cliParams.replayDir != "" {
+				return errors.New("the --record and --replay flags are mutually exclusive")
+			}
+			// the proxy settings and the certificate authorities trusted by the checks are set
+			// in the environment, so the session must start before the agent configuration is loaded
+			var err error
+			if cliParams.recordDir != "" {
+				cliParams.fixtures, err = replay.Record(cliParams.recordDir)
+			} else if cliParams.replayDir != "" {
+				cliParams.fixtures, err = replay.Replay(cliParams.replayDir, cliParams.replayTolerance)
+			}
+			if err != nil {
+				return err
+			}
+			if cliParams.fixtures != nil {
+				defer cliParams.fixtures.Close()
+				// the samples of each instance are compared using the aggregator output of the JSON format
+				cliParams.formatJSON = true
+			}
+
 			eventplatforParams := eventplatformimpl.NewDefaultParams()
 			eventplatforParams.UseNoopEventPlatformForwarder = true
 
@@ -223,6 +252,9 @@ func MakeCommand(globalParamsGetter func() GlobalParams) *cobra.Command {
 	cmd.Flags().UintVarP(&cliParams.discoveryTimeout, "discovery-timeout", "", 5, "max retry duration until Autodiscovery resolves the check template (in seconds)")
 	cmd.Flags().UintVarP(&cliParams.discoveryRetryInterval, "discovery-retry-interval", "", 1, "(unused)")
 	cmd.Flags().UintVarP(&cliParams.discoveryMinInstances, "discovery-min-instances", "", 1, "minimum number of config instances to be discovered before running the check(s)")
+	cmd.Flags().StringVar(&cliParams.recordDir, "record", "", "record the HTTP(S), SNMP and SQL responses received by the check and the samples it emits into a directory, the database connections of Python checks are not recorded (implies --json)")
+	cmd.Flags().StringVar(&cliParams.replayDir, "replay", "", "run the check against the responses recorded in a directory and compare the samples it emits to the recorded ones (implies --json)")
+	cmd.Flags().Float64Var(&cliParams.replayTolerance, "replay-tolerance", 0, "relative tolerance when comparing the replayed metric values to the recorded ones, e.g. 0.01 for 1%")
 
 	// Power user flags - mark as hidden
 	createHiddenStringFlag(cmd, &cliParams.profileMemoryDir, "m-dir", "", "an existing directory in which to store memory profiling data, ignoring clean-up")
@@ -478,8 +510,16 @@ func run(
 		return err
 	}
 
+	var agentHost string
+	if cliParams.fixtures != nil {
+		agentHost, _ = hostname.Get(context.Background())
+	}
+
 	checkRuns := collectorData["runnerStats"].(map[string]interface{})["Checks"].(map[string]interface{})
 	for _, c := range cs {
+		if cliParams.fixtures != nil {
+			cliParams.fixtures.AddInstanceConfig(c.InstanceConfig())
+		}
 		s := runCheck(cliParams, c, printer)
 		resultBytes, err := json.Marshal(s)
 		if err != nil {
@@ -501,6 +541,11 @@ func run(
 
 		if cliParams.formatJSON {
 			aggregatorData := printer.GetMetricsDataForPrint()
+			if cliParams.fixtures != nil {
+				if err := cliParams.fixtures.AddInstance(aggregatorData, agentHost); err != nil {
+					return err
+				}
+			}
 
 			// There is only one checkID per run so we'll just access that
 			instanceData := map[string]interface{}{
@@ -621,6 +666,10 @@ func run(
 		pkgconfigsetup.Datadog().Set("integration_tracing_exhaustive", previousIntegrationTracingExhaustive, model.SourceAgentRuntime)
 	}
 
+	if cliParams.fixtures != nil {
+		return cliParams.fixtures.Finish(color.Output, cliParams.checkName)
+	}
+
 	return nil
 }
 
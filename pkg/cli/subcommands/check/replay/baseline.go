@@ -0,0 +1,320 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// baselineFile is the name of the file holding the samples of the recorded run
+	baselineFile = "baseline.json"
+	// baselineVersion is the version of the format of the baseline file
+	baselineVersion = 1
+)
+
+// Sample kinds
+const (
+	KindMetric       = "metric"
+	KindSketch       = "sketch"
+	KindServiceCheck = "service_check"
+	KindEvent        = "event"
+)
+
+// Sample is a sample emitted by a check, stripped from what changes from one run to another,
+// like timestamps.
+type Sample struct {
+	Kind string   `json:"kind"`
+	Name string   `json:"name"`
+	Type string   `json:"type,omitempty"`
+	Host string   `json:"host,omitempty"`
+	Tags []string `json:"tags,omitempty"`
+	// Values holds the values of the points of metrics, the count, min, max and sum of the
+	// points of sketches, and the status of service checks
+	Values []float64 `json:"values,omitempty"`
+	// Message holds the message of service checks and the text of events
+	Message string `json:"message,omitempty"`
+}
+
+// key identifies the sample across runs
+func (s Sample) key() string {
+	return strings.Join([]string{s.Kind, s.Name, s.Type, s.Host, strings.Join(s.Tags, ",")}, "|")
+}
+
+func (s Sample) String() string {
+	var b strings.Builder
+	b.WriteString(s.Kind + " " + s.Name)
+	if s.Type != "" {
+		b.WriteString(" (" + s.Type + ")")
+	}
+	if s.Host != "" {
+		b.WriteString(" host:" + s.Host)
+	}
+	if len(s.Tags) > 0 {
+		b.WriteString(" tags:[" + strings.Join(s.Tags, " ") + "]")
+	}
+	return b.String()
+}
+
+// baseline is the format of the baseline file
+type baseline struct {
+	Version   int        `json:"version"`
+	Check     string     `json:"check"`
+	Instances [][]Sample `json:"instances"`
+}
+
+// aggregatorOutput is the part of the output of the aggregator printer holding the samples
+type aggregatorOutput struct {
+	Metrics []struct {
+		Name   string       `json:"metric"`
+		Points [][2]float64 `json:"points"`
+		Tags   []string     `json:"tags"`
+		Host   string       `json:"host"`
+		Type   string       `json:"type"`
+	} `json:"metrics"`
+	Sketches struct {
+		Sketches []struct {
+			Name   string   `json:"metric"`
+			Tags   []string `json:"tags"`
+			Host   string   `json:"host"`
+			Points []struct {
+				Sketch struct {
+					Summary struct {
+						Min, Max, Sum float64
+						Cnt           int64
+					} `json:"summary"`
+				} `json:"sketch"`
+			} `json:"points"`
+		} `json:"sketches"`
+	} `json:"sketches"`
+	ServiceChecks []struct {
+		Name    string   `json:"check"`
+		Host    string   `json:"host_name"`
+		Status  int      `json:"status"`
+		Message string   `json:"message"`
+		Tags    []string `json:"tags"`
+	} `json:"service_checks"`
+	Events []struct {
+		Title     string   `json:"msg_title"`
+		Text      string   `json:"msg_text"`
+		Host      string   `json:"host"`
+		Tags      []string `json:"tags"`
+		AlertType string   `json:"alert_type"`
+	} `json:"events"`
+}
+
+// Samples extracts the samples from the output of the aggregator printer for a check
+// instance. The host of the samples is dropped when it's the host of the agent, so that
+// runs on different hosts can be compared.
+func Samples(aggregatorData map[string]interface{}, agentHost string) ([]Sample, error) {
+	content, err := json.Marshal(aggregatorData)
+	if err != nil {
+		return nil, err
+	}
+	var output aggregatorOutput
+	if err := json.Unmarshal(content, &output); err != nil {
+		return nil, fmt.Errorf("unable to read the samples of the check: %w", err)
+	}
+
+	host := func(h string) string {
+		if h == agentHost {
+			return ""
+		}
+		return h
+	}
+
+	var samples []Sample
+	for _, m := range output.Metrics {
+		s := Sample{Kind: KindMetric, Name: m.Name, Type: m.Type, Host: host(m.Host), Tags: sortedTags(m.Tags)}
+		for _, p := range m.Points {
+			s.Values = append(s.Values, p[1])
+		}
+		samples = append(samples, s)
+	}
+	for _, m := range output.Sketches.Sketches {
+		s := Sample{Kind: KindSketch, Name: m.Name, Host: host(m.Host), Tags: sortedTags(m.Tags)}
+		for _, p := range m.Points {
+			summary := p.Sketch.Summary
+			s.Values = append(s.Values, float64(summary.Cnt), summary.Min, summary.Max, summary.Sum)
+		}
+		samples = append(samples, s)
+	}
+	for _, sc := range output.ServiceChecks {
+		samples = append(samples, Sample{
+			Kind:    KindServiceCheck,
+			Name:    sc.Name,
+			Host:    host(sc.Host),
+			Tags:    sortedTags(sc.Tags),
+			Values:  []float64{float64(sc.Status)},
+			Message: sc.Message,
+		})
+	}
+	for _, e := range output.Events {
+		samples = append(samples, Sample{
+			Kind:    KindEvent,
+			Name:    e.Title,
+			Type:    e.AlertType,
+			Host:    host(e.Host),
+			Tags:    sortedTags(e.Tags),
+			Message: e.Text,
+		})
+	}
+
+	sort.SliceStable(samples, func(i, j int) bool { return samples[i].key() < samples[j].key() })
+	return samples, nil
+}
+
+func sortedTags(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+	sorted := append([]string(nil), tags...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+// writeBaseline writes the samples of each check instance to the fixtures directory
+func writeBaseline(dir string, checkName string, instances [][]Sample) error {
+	content, err := json.MarshalIndent(baseline{
+		Version:   baselineVersion,
+		Check:     checkName,
+		Instances: instances,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, baselineFile), content, 0o600)
+}
+
+// readBaseline reads the samples of each check instance from the fixtures directory
+func readBaseline(dir string, checkName string) ([][]Sample, error) {
+	content, err := os.ReadFile(filepath.Join(dir, baselineFile))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the baseline: %w", err)
+	}
+	var b baseline
+	if err := json.Unmarshal(content, &b); err != nil {
+		return nil, fmt.Errorf("invalid baseline: %w", err)
+	}
+	if b.Version != baselineVersion {
+		return nil, fmt.Errorf("unsupported baseline version %d", b.Version)
+	}
+	if b.Check != checkName {
+		return nil, fmt.Errorf("the fixtures were recorded for check %s, not %s", b.Check, checkName)
+	}
+	return b.Instances, nil
+}
+
+// Change is the kind of a difference between the baseline and a replayed run
+type Change string
+
+// Changes
+const (
+	Missing    Change = "missing"
+	Unexpected Change = "unexpected"
+	Changed    Change = "changed"
+)
+
+// Difference is a difference between a sample of the baseline and a sample of a replayed run
+type Difference struct {
+	Instance int
+	Change   Change
+	Expected *Sample
+	Actual   *Sample
+}
+
+func (d Difference) String() string {
+	switch d.Change {
+	case Missing:
+		return fmt.Sprintf("instance #%d: - %s %s", d.Instance, d.Expected, describeValues(*d.Expected))
+	case Unexpected:
+		return fmt.Sprintf("instance #%d: + %s %s", d.Instance, d.Actual, describeValues(*d.Actual))
+	default:
+		return fmt.Sprintf("instance #%d: ~ %s: %s -> %s", d.Instance, d.Expected, describeValues(*d.Expected), describeValues(*d.Actual))
+	}
+}
+
+func describeValues(s Sample) string {
+	values := make([]string, 0, len(s.Values))
+	for _, v := range s.Values {
+		values = append(values, strconv.FormatFloat(v, 'g', -1, 64))
+	}
+	description := "[" + strings.Join(values, " ") + "]"
+	if s.Message != "" {
+		description += fmt.Sprintf(" %q", s.Message)
+	}
+	return description
+}
+
+// Compare compares the samples of each instance of a replayed run to the baseline. Values
+// are equal when their relative difference is within tolerance.
+func Compare(expected, actual [][]Sample, tolerance float64) []Difference {
+	var diffs []Difference
+	for i := 0; i < len(expected) || i < len(actual); i++ {
+		var e, a []Sample
+		if i < len(expected) {
+			e = expected[i]
+		}
+		if i < len(actual) {
+			a = actual[i]
+		}
+		diffs = append(diffs, compareInstance(i, e, a, tolerance)...)
+	}
+	return diffs
+}
+
+func compareInstance(instance int, expected, actual []Sample, tolerance float64) []Difference {
+	// samples sharing a key are matched in order
+	remaining := map[string][]Sample{}
+	for _, s := range actual {
+		remaining[s.key()] = append(remaining[s.key()], s)
+	}
+
+	var diffs []Difference
+	for _, e := range expected {
+		candidates := remaining[e.key()]
+		if len(candidates) == 0 {
+			diffs = append(diffs, Difference{Instance: instance, Change: Missing, Expected: &e})
+			continue
+		}
+		a := candidates[0]
+		remaining[e.key()] = candidates[1:]
+		if !equalValues(e.Values, a.Values, tolerance) || e.Message != a.Message {
+			diffs = append(diffs, Difference{Instance: instance, Change: Changed, Expected: &e, Actual: &a})
+		}
+	}
+	for _, a := range actual {
+		if candidates := remaining[a.key()]; len(candidates) > 0 {
+			a := candidates[0]
+			remaining[a.key()] = candidates[1:]
+			diffs = append(diffs, Difference{Instance: instance, Change: Unexpected, Actual: &a})
+		}
+	}
+	return diffs
+}
+
+func equalValues(expected, actual []float64, tolerance float64) bool {
+	if len(expected) != len(actual) {
+		return false
+	}
+	for i := range expected {
+		e, a := expected[i], actual[i]
+		if e == a || (math.IsNaN(e) && math.IsNaN(a)) {
+			continue
+		}
+		if math.Abs(e-a) > tolerance*math.Max(math.Abs(e), math.Abs(a)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package replay
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const aggregatorJSON = `{
+	"metrics": [
+		{"metric": "nginx.net.connections", "points": [[1700000000, 12], [1700000015, 14]], "tags": ["port:80", "env:prod"], "host": "my-host", "type": "gauge", "interval": 0},
+		{"metric": "snmp.device.reachable", "points": [[1700000000, 1]], "tags": null, "host": "router", "type": "gauge", "interval": 0}
+	],
+	"sketches": {"sketches": [
+		{"metric": "request.latency", "tags": ["env:prod"], "host": "my-host", "interval": 10, "points": [{"sketch": {"summary": {"Min": 1, "Max": 3, "Sum": 6, "Avg": 2, "Cnt": 3}}, "ts": 1700000000}]}
+	]},
+	"service_checks": [
+		{"check": "nginx.can_connect", "host_name": "my-host", "timestamp": 1700000000, "status": 0, "message": "", "tags": ["port:80"]}
+	],
+	"events": [
+		{"msg_title": "Config changed", "msg_text": "new config", "timestamp": 1700000000, "host": "my-host", "alert_type": "info"}
+	]
+}`
+
+func aggregatorData(t *testing.T) map[string]interface{} {
+	var data map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(aggregatorJSON), &data))
+	return data
+}
+
+func TestSamples(t *testing.T) {
+	samples, err := Samples(aggregatorData(t), "my-host")
+	require.NoError(t, err)
+
+	assert.Equal(t, []Sample{
+		{Kind: KindEvent, Name: "Config changed", Type: "info", Message: "new config"},
+		{Kind: KindMetric, Name: "nginx.net.connections", Type: "gauge", Tags: []string{"env:prod", "port:80"}, Values: []float64{12, 14}},
+		{Kind: KindMetric, Name: "snmp.device.reachable", Type: "gauge", Host: "router", Values: []float64{1}},
+		{Kind: KindServiceCheck, Name: "nginx.can_connect", Tags: []string{"port:80"}, Values: []float64{0}},
+		{Kind: KindSketch, Name: "request.latency", Tags: []string{"env:prod"}, Values: []float64{3, 1, 3, 6}},
+	}, samples)
+}
+
+func TestCompare(t *testing.T) {
+	gauge := func(name string, value float64) Sample {
+		return Sample{Kind: KindMetric, Name: name, Type: "gauge", Values: []float64{value}}
+	}
+	expected := [][]Sample{
+		{gauge("a", 100), gauge("b", 1), gauge("c", 1)},
+		{gauge("a", 1)},
+	}
+	actual := [][]Sample{
+		{gauge("a", 100.5), gauge("b", 2), gauge("d", 1)},
+		{gauge("a", 1)},
+		{gauge("e", 1)},
+	}
+
+	assert.Empty(t, Compare(expected, expected, 0))
+
+	diffs := Compare(expected, actual, 0.01)
+	a, b, c, d, e := gauge("b", 1), gauge("b", 2), gauge("c", 1), gauge("d", 1), gauge("e", 1)
+	assert.Equal(t, []Difference{
+		{Instance: 0, Change: Changed, Expected: &a, Actual: &b},
+		{Instance: 0, Change: Missing, Expected: &c},
+		{Instance: 0, Change: Unexpected, Actual: &d},
+		{Instance: 2, Change: Unexpected, Actual: &e},
+	}, diffs)
+	assert.Equal(t, "instance #0: ~ metric b (gauge): [1] -> [2]", diffs[0].String())
+	assert.Equal(t, "instance #0: - metric c (gauge) [1]", diffs[1].String())
+	assert.Equal(t, "instance #0: + metric d (gauge) [1]", diffs[2].String())
+
+	assert.Len(t, Compare(expected, actual, 0), 5)
+}
+
+func TestBaseline(t *testing.T) {
+	dir := t.TempDir()
+	samples, err := Samples(aggregatorData(t), "my-host")
+	require.NoError(t, err)
+
+	require.NoError(t, writeBaseline(dir, "nginx", [][]Sample{samples}))
+	instances, err := readBaseline(dir, "nginx")
+	require.NoError(t, err)
+	assert.Equal(t, [][]Sample{samples}, instances)
+
+	_, err = readBaseline(dir, "redis")
+	assert.EqualError(t, err, "the fixtures were recorded for check nginx, not redis")
+}
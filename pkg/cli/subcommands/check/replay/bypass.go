@@ -0,0 +1,102 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package replay
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// noProxyVariables are the environment variables listing the hosts reached without a proxy,
+// which are cleared while recording or replaying so that every request goes through the proxy
+var noProxyVariables = []string{"NO_PROXY", "no_proxy", "DD_PROXY_NO_PROXY"}
+
+// configuredHosts returns the hosts of the HTTP(S) URLs found in the configuration of a check
+// instance
+func configuredHosts(instanceConfig string) []string {
+	var config any
+	if err := yaml.Unmarshal([]byte(instanceConfig), &config); err != nil {
+		return nil
+	}
+	hosts := map[string]struct{}{}
+	walkStrings(config, func(value string) {
+		u, err := url.Parse(strings.TrimSpace(value))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+			return
+		}
+		hosts[u.Hostname()] = struct{}{}
+	})
+
+	result := make([]string, 0, len(hosts))
+	for host := range hosts {
+		result = append(result, host)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// walkStrings calls f on every string of a decoded YAML document
+func walkStrings(value any, f func(string)) {
+	switch v := value.(type) {
+	case string:
+		f(v)
+	case []any:
+		for _, item := range v {
+			walkStrings(item, f)
+		}
+	case map[string]any:
+		for _, item := range v {
+			walkStrings(item, f)
+		}
+	}
+}
+
+// recordedHosts returns the hosts of the recorded HTTP interactions
+func recordedHosts(keys []string) map[string]struct{} {
+	hosts := map[string]struct{}{}
+	for _, key := range keys {
+		// the keys are formatted as "<method> <url> [<body hash>]"
+		fields := strings.Fields(key)
+		if len(fields) < 2 {
+			continue
+		}
+		if u, err := url.Parse(fields[1]); err == nil {
+			hosts[u.Hostname()] = struct{}{}
+		}
+	}
+	return hosts
+}
+
+// isLoopback returns whether requests to host bypass the proxy in the Go HTTP clients, which
+// never use a proxy for localhost and the loopback addresses
+func isLoopback(host string) bool {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// forceProxy makes the default HTTP transport send all its requests through proxyURL,
+// including the ones to loopback addresses, and returns a function restoring it
+func forceProxy(proxyURL string) func() {
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return func() {}
+	}
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return func() {}
+	}
+	previous := transport.Proxy
+	transport.Proxy = http.ProxyURL(u)
+	return func() { transport.Proxy = previous }
+}
@@ -0,0 +1,71 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package replay
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check/fixtures"
+)
+
+func TestConfiguredHosts(t *testing.T) {
+	config := `
+openmetrics_endpoint: http://localhost:9090/metrics
+url: "https://api.example.com/v1"
+tags:
+  - "env:prod"
+extra:
+  - endpoint: http://127.0.0.1:8080
+  - host: 10.0.0.1
+`
+	assert.Equal(t, []string{"127.0.0.1", "api.example.com", "localhost"}, configuredHosts(config))
+	assert.Empty(t, configuredHosts("not: [valid"))
+}
+
+func TestBypassedHosts(t *testing.T) {
+	store, err := fixtures.NewRecorder(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, store.Record(httpKind, "GET https://api.example.com:443/v1", httpResponse{Status: 200}))
+
+	s := &Session{store: store}
+	s.AddInstanceConfig("url: https://api.example.com/v1")
+	s.AddInstanceConfig("url: http://localhost:9090/metrics\nother: http://localhost:9091")
+	assert.Equal(t, []string{"localhost"}, s.bypassedHosts())
+
+	assert.True(t, isLoopback("localhost"))
+	assert.True(t, isLoopback("::1"))
+	assert.False(t, isLoopback("api.example.com"))
+}
+
+func TestForceProxy(t *testing.T) {
+	store, err := fixtures.NewRecorder(t.TempDir())
+	require.NoError(t, err)
+	p, err := NewProxy(store)
+	require.NoError(t, err)
+	defer p.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	// the request to the loopback server goes through the proxy, which the environment can't do
+	restore := forceProxy(p.URL())
+	status, body := get(t, http.DefaultClient, http.MethodGet, server.URL+"/status", "")
+	restore()
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "ok", body)
+	assert.Equal(t, []string{"GET " + server.URL + "/status"}, store.Keys(httpKind))
+
+	status, _ = get(t, http.DefaultClient, http.MethodGet, server.URL+"/direct", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Len(t, store.Keys(httpKind), 1)
+}
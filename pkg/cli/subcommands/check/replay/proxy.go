@@ -0,0 +1,342 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check/fixtures"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// httpKind is the kind of the HTTP interactions in check fixtures
+	httpKind = "http"
+	// maxBodySize is the maximum size of a recorded request or response body
+	maxBodySize = 64 << 20
+)
+
+// httpResponse is a recorded HTTP response
+type httpResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+}
+
+// Proxy is an HTTP proxy recording the responses to the requests of the checks, or replaying
+// them. HTTPS requests are intercepted with certificates issued by an ephemeral certificate
+// authority, which the checks must trust.
+type Proxy struct {
+	store    *fixtures.Store
+	listener net.Listener
+	server   *http.Server
+
+	ca    *x509.Certificate
+	caKey *ecdsa.PrivateKey
+	caPEM []byte
+
+	upstream http.RoundTripper
+
+	mu    sync.Mutex // protects certs
+	certs map[string]*tls.Certificate
+}
+
+// NewProxy starts a proxy listening on the loopback interface. In record mode, requests are
+// sent directly to their destination.
+func NewProxy(store *fixtures.Store) (*Proxy, error) {
+	p := &Proxy{
+		store: store,
+		certs: map[string]*tls.Certificate{},
+		upstream: &http.Transport{
+			// never use the proxy settings of the environment, which point to this proxy
+			Proxy:               nil,
+			TLSHandshakeTimeout: 10 * time.Second,
+			DisableCompression:  true,
+		},
+	}
+	if err := p.generateCA(); err != nil {
+		return nil, fmt.Errorf("unable to generate the certificate authority of the proxy: %w", err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("unable to start the proxy: %w", err)
+	}
+	p.listener = ln
+	p.server = &http.Server{Handler: p, ReadHeaderTimeout: 30 * time.Second}
+	go func() {
+		if err := p.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Warnf("The check fixtures proxy stopped: %s", err)
+		}
+	}()
+	return p, nil
+}
+
+// URL returns the URL of the proxy
+func (p *Proxy) URL() string {
+	return "http://" + p.listener.Addr().String()
+}
+
+// CACertificate returns the PEM-encoded certificate of the certificate authority of the proxy
+func (p *Proxy) CACertificate() []byte {
+	return p.caPEM
+}
+
+// Close stops the proxy
+func (p *Proxy) Close() error {
+	return p.server.Close()
+}
+
+// ServeHTTP serves a proxied request
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodConnect {
+		p.serveConnect(w, req)
+		return
+	}
+	if !req.URL.IsAbs() {
+		http.Error(w, "the check fixtures proxy only serves proxied requests", http.StatusBadRequest)
+		return
+	}
+
+	resp := p.handle(req)
+	for k, values := range resp.Header {
+		for _, v := range values {
+			w.Header().Add(k, v)
+		}
+	}
+	w.WriteHeader(resp.Status)
+	_, _ = w.Write(resp.Body)
+}
+
+// serveConnect intercepts a tunnel, serving the requests it carries over TLS
+func (p *Proxy) serveConnect(w http.ResponseWriter, req *http.Request) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "unable to intercept the connection", http.StatusInternalServerError)
+		return
+	}
+	clientConn, _, err := hijacker.Hijack()
+	if err != nil {
+		return
+	}
+	defer clientConn.Close()
+	if _, err := io.WriteString(clientConn, "HTTP/1.1 200 Connection established\r\n\r\n"); err != nil {
+		return
+	}
+
+	host := req.URL.Host
+	hostname, _, err := net.SplitHostPort(host)
+	if err != nil {
+		hostname = host
+	}
+	tlsConn := tls.Server(clientConn, &tls.Config{
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			if hello.ServerName != "" {
+				return p.certificate(hello.ServerName)
+			}
+			return p.certificate(hostname)
+		},
+		NextProtos: []string{"http/1.1"},
+	})
+	if err := tlsConn.Handshake(); err != nil {
+		log.Debugf("TLS handshake with a check failed for %s: %s", host, err)
+		return
+	}
+
+	reader := bufio.NewReader(tlsConn)
+	for {
+		inner, err := http.ReadRequest(reader)
+		if err != nil {
+			return
+		}
+		inner.URL.Scheme = "https"
+		inner.URL.Host = host
+		resp := p.handle(inner)
+
+		httpResp := &http.Response{
+			StatusCode:    resp.Status,
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        resp.Header,
+			Body:          io.NopCloser(bytes.NewReader(resp.Body)),
+			ContentLength: int64(len(resp.Body)),
+			Close:         inner.Close,
+		}
+		if httpResp.Header == nil {
+			httpResp.Header = http.Header{}
+		}
+		if err := httpResp.Write(tlsConn); err != nil || inner.Close {
+			return
+		}
+	}
+}
+
+// handle records the response to a request, or replays it
+func (p *Proxy) handle(req *http.Request) *httpResponse {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(io.LimitReader(req.Body, maxBodySize))
+		req.Body.Close()
+		if err != nil {
+			return errorResponse(http.StatusBadRequest, "unable to read the request: "+err.Error())
+		}
+	}
+	key := requestKey(req, body)
+
+	if p.store.Mode() == fixtures.Replay {
+		var resp httpResponse
+		if err := p.store.Replay(httpKind, key, &resp); err != nil {
+			return errorResponse(http.StatusBadGateway, err.Error())
+		}
+		return &resp
+	}
+
+	outReq, err := http.NewRequestWithContext(req.Context(), req.Method, req.URL.String(), bytes.NewReader(body))
+	if err != nil {
+		return errorResponse(http.StatusBadRequest, err.Error())
+	}
+	outReq.Header = req.Header.Clone()
+	removeHopHeaders(outReq.Header)
+
+	upstreamResp, err := p.upstream.RoundTrip(outReq)
+	if err != nil {
+		// errors aren't recorded: the check gets a bad gateway response in both modes
+		return errorResponse(http.StatusBadGateway, err.Error())
+	}
+	defer upstreamResp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(upstreamResp.Body, maxBodySize))
+	if err != nil {
+		return errorResponse(http.StatusBadGateway, "unable to read the response: "+err.Error())
+	}
+
+	resp := &httpResponse{Status: upstreamResp.StatusCode, Header: upstreamResp.Header.Clone(), Body: respBody}
+	removeHopHeaders(resp.Header)
+	resp.Header.Del("Content-Length")
+	if err := p.store.Record(httpKind, key, resp); err != nil {
+		log.Warnf("Unable to record the response to %s: %s", key, err)
+	}
+	return resp
+}
+
+// requestKey identifies a request by its method, URL and the hash of its body
+func requestKey(req *http.Request, body []byte) string {
+	key := req.Method + " " + req.URL.String()
+	if len(body) > 0 {
+		sum := sha256.Sum256(body)
+		key += " " + hex.EncodeToString(sum[:8])
+	}
+	return key
+}
+
+func errorResponse(status int, message string) *httpResponse {
+	return &httpResponse{
+		Status: status,
+		Header: http.Header{"Content-Type": []string{"text/plain; charset=utf-8"}},
+		Body:   []byte(message + "\n"),
+	}
+}
+
+// hopHeaders are the headers which only apply to a single connection
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+func removeHopHeaders(h http.Header) {
+	for _, header := range hopHeaders {
+		h.Del(header)
+	}
+}
+
+// generateCA generates the ephemeral certificate authority issuing the certificates of the
+// intercepted hosts
+func (p *Proxy) generateCA() error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Datadog Agent check fixtures proxy"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return err
+	}
+	p.ca = cert
+	p.caKey = key
+	p.caPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	return nil
+}
+
+// certificate returns a certificate for host issued by the certificate authority of the proxy
+func (p *Proxy) certificate(host string) (*tls.Certificate, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if cert, ok := p.certs[host]; ok {
+		return cert, nil
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(int64(len(p.certs) + 2)),
+		Subject:      pkix.Name{CommonName: host},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		template.IPAddresses = []net.IP{ip}
+	} else {
+		template.DNSNames = []string{host}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, p.ca, &key.PublicKey, p.caKey)
+	if err != nil {
+		return nil, err
+	}
+	cert := &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+	p.certs[host] = cert
+	return cert, nil
+}
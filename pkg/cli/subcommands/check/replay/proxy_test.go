@@ -0,0 +1,115 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package replay
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check/fixtures"
+)
+
+// proxyClient returns a client sending its requests through the proxy and trusting its
+// certificate authority
+func proxyClient(t *testing.T, p *Proxy) *http.Client {
+	proxyURL, err := url.Parse(p.URL())
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(p.CACertificate()))
+	return &http.Client{Transport: &http.Transport{
+		Proxy:           http.ProxyURL(proxyURL),
+		TLSClientConfig: &tls.Config{RootCAs: roots},
+	}}
+}
+
+func get(t *testing.T, client *http.Client, method, url, body string) (int, string) {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	content, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(content)
+}
+
+func TestProxyRecordReplay(t *testing.T) {
+	dir := t.TempDir()
+	calls := 0
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Call", "yes")
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		_, _ = w.Write([]byte(r.Method + " " + r.URL.Path + " " + string(body)))
+	})
+	tlsServer := httptest.NewTLSServer(handler)
+	plainServer := httptest.NewServer(handler)
+
+	// record
+	recorder, err := fixtures.NewRecorder(dir)
+	require.NoError(t, err)
+	p, err := NewProxy(recorder)
+	require.NoError(t, err)
+	p.upstream = tlsServer.Client().Transport
+	client := proxyClient(t, p)
+
+	status, body := get(t, client, http.MethodGet, tlsServer.URL+"/status", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "GET /status ", body)
+	status, body = get(t, client, http.MethodPost, tlsServer.URL+"/query", "select 1")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "POST /query select 1", body)
+	status, _ = get(t, client, http.MethodGet, tlsServer.URL+"/missing", "")
+	assert.Equal(t, http.StatusNotFound, status)
+	status, body = get(t, client, http.MethodGet, plainServer.URL+"/metrics", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "GET /metrics ", body)
+	assert.Equal(t, 4, calls)
+
+	require.NoError(t, p.Close())
+	require.NoError(t, recorder.Save())
+	tlsServer.Close()
+	plainServer.Close()
+
+	// replay, with the servers stopped
+	store, err := fixtures.Load(dir)
+	require.NoError(t, err)
+	p, err = NewProxy(store)
+	require.NoError(t, err)
+	defer p.Close()
+	client = proxyClient(t, p)
+
+	status, body = get(t, client, http.MethodGet, tlsServer.URL+"/status", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "GET /status ", body)
+	status, body = get(t, client, http.MethodPost, tlsServer.URL+"/query", "select 1")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "POST /query select 1", body)
+	status, _ = get(t, client, http.MethodGet, tlsServer.URL+"/missing", "")
+	assert.Equal(t, http.StatusNotFound, status)
+	status, body = get(t, client, http.MethodGet, plainServer.URL+"/metrics", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "GET /metrics ", body)
+	assert.Equal(t, 4, calls)
+
+	// a request with another body wasn't recorded
+	status, _ = get(t, client, http.MethodPost, tlsServer.URL+"/query", "select 2")
+	assert.Equal(t, http.StatusBadGateway, status)
+	assert.Len(t, store.Missing(), 1)
+	assert.Contains(t, store.Missing()[0], "http POST "+tlsServer.URL+"/query ")
+}
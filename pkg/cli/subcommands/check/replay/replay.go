@@ -0,0 +1,226 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package replay implements the record and replay modes of the check command.
+//
+// When recording, the HTTP(S), SNMP and SQL responses received by the check are
+// written to a fixtures directory along with the samples emitted by each check instance. When
+// replaying, the check runs against the recorded responses and the samples it emits are
+// compared with the recorded ones.
+//
+// The HTTP(S) requests are captured by a proxy that the checks use through the proxy settings
+// of the environment. The default transport of the Go checks is pointed to it as well, since
+// the Go HTTP clients never proxy the requests to loopback addresses. The hosts found in the
+// configuration of the checks without any recorded interaction are reported, as their requests
+// bypassed the proxy. The SQL queries of the Go checks, like Oracle, are captured by a wrapper of
+// their database/sql driver. The database connections of the Python checks are not recorded:
+// these checks still need their database to run.
+package replay
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check/fixtures"
+)
+
+// caBundlePaths are the usual locations of the bundle of the system certificate authorities
+var caBundlePaths = []string{
+	"/etc/ssl/certs/ca-certificates.crt",
+	"/etc/pki/tls/certs/ca-bundle.crt",
+	"/etc/ssl/ca-bundle.pem",
+	"/etc/pki/tls/cacert.pem",
+	"/etc/ssl/cert.pem",
+}
+
+// Session is a recording or a replay of the runs of a check
+type Session struct {
+	store     *fixtures.Store
+	proxy     *Proxy
+	caFile    string
+	tolerance float64
+	instances [][]Sample
+
+	// hosts are the hosts found in the configuration of the check instances
+	hosts []string
+	// restoreTransport restores the proxy of the default HTTP transport
+	restoreTransport func()
+}
+
+// Record starts recording the interactions of the check into dir
+func Record(dir string) (*Session, error) {
+	store, err := fixtures.NewRecorder(dir)
+	if err != nil {
+		return nil, err
+	}
+	return start(store, 0)
+}
+
+// Replay starts replaying the interactions recorded in dir. The values of the samples emitted
+// by the check are compared to the recorded ones with the given relative tolerance.
+func Replay(dir string, tolerance float64) (*Session, error) {
+	if tolerance < 0 {
+		return nil, errors.New("the replay tolerance can't be negative")
+	}
+	store, err := fixtures.Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	return start(store, tolerance)
+}
+
+// start starts the proxy and points the checks to it through the environment, which must be
+// done before the configuration of the agent is loaded and before the checks are loaded.
+func start(store *fixtures.Store, tolerance float64) (*Session, error) {
+	proxy, err := NewProxy(store)
+	if err != nil {
+		return nil, err
+	}
+	s := &Session{store: store, proxy: proxy, tolerance: tolerance}
+
+	if err := s.writeCABundle(); err != nil {
+		s.Close()
+		return nil, fmt.Errorf("unable to write the certificate bundle of the proxy: %w", err)
+	}
+	env := map[string]string{
+		"DD_PROXY_HTTP":      proxy.URL(),
+		"DD_PROXY_HTTPS":     proxy.URL(),
+		"HTTP_PROXY":         proxy.URL(),
+		"HTTPS_PROXY":        proxy.URL(),
+		"http_proxy":         proxy.URL(),
+		"https_proxy":        proxy.URL(),
+		"SSL_CERT_FILE":      s.caFile,
+		"REQUESTS_CA_BUNDLE": s.caFile,
+		"CURL_CA_BUNDLE":     s.caFile,
+	}
+	for k, v := range env {
+		if err := os.Setenv(k, v); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+	for _, k := range noProxyVariables {
+		if err := os.Unsetenv(k); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+	s.restoreTransport = forceProxy(proxy.URL())
+
+	fixtures.SetCurrent(store)
+	return s, nil
+}
+
+// writeCABundle writes the certificate of the proxy, followed by the certificates of the
+// system, to a temporary bundle trusted by the checks.
+func (s *Session) writeCABundle() error {
+	f, err := os.CreateTemp("", "datadog-check-fixtures-ca-*.pem")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	s.caFile = f.Name()
+
+	if _, err := f.Write(s.proxy.CACertificate()); err != nil {
+		return err
+	}
+	systemBundles := caBundlePaths
+	if bundle := os.Getenv("SSL_CERT_FILE"); bundle != "" {
+		systemBundles = []string{bundle}
+	}
+	for _, path := range systemBundles {
+		if content, err := os.ReadFile(path); err == nil {
+			_, err = f.Write(content)
+			return err
+		}
+	}
+	return nil
+}
+
+// AddInstanceConfig adds the configuration of a check instance, to report the hosts it refers
+// to which the check reached without going through the proxy
+func (s *Session) AddInstanceConfig(instanceConfig string) {
+	s.hosts = append(s.hosts, configuredHosts(instanceConfig)...)
+}
+
+// bypassedHosts returns the configured hosts without any recorded HTTP interaction
+func (s *Session) bypassedHosts() []string {
+	recorded := recordedHosts(s.store.Keys(httpKind))
+	var bypassed []string
+	for _, host := range s.hosts {
+		if _, ok := recorded[host]; !ok && !slices.Contains(bypassed, host) {
+			bypassed = append(bypassed, host)
+		}
+	}
+	return bypassed
+}
+
+// AddInstance adds the samples emitted by a check instance, as returned by the aggregator
+// printer
+func (s *Session) AddInstance(aggregatorData map[string]interface{}, agentHost string) error {
+	samples, err := Samples(aggregatorData, agentHost)
+	if err != nil {
+		return err
+	}
+	s.instances = append(s.instances, samples)
+	return nil
+}
+
+// Finish saves the recording, or compares the samples of the replayed runs to the recorded
+// ones and reports the differences. It returns an error when the replayed runs differ from the
+// recorded ones.
+func (s *Session) Finish(w io.Writer, checkName string) error {
+	if s.store.Mode() == fixtures.Record {
+		if err := s.store.Save(); err != nil {
+			return fmt.Errorf("unable to save the recorded interactions: %w", err)
+		}
+		if err := writeBaseline(s.store.Dir(), checkName, s.instances); err != nil {
+			return fmt.Errorf("unable to save the baseline: %w", err)
+		}
+		for _, host := range s.bypassedHosts() {
+			reason := "its requests may have bypassed the proxy"
+			if isLoopback(host) {
+				reason = "the HTTP clients which don't use the default Go transport never proxy loopback addresses"
+			}
+			fmt.Fprintf(w, "Warning: no HTTP interaction with %s was recorded: %s\n", host, reason)
+		}
+		fmt.Fprintf(w, "Recorded the interactions and the samples of %d instance(s) of %s in %s\n", len(s.instances), checkName, s.store.Dir())
+		return nil
+	}
+
+	expected, err := readBaseline(s.store.Dir(), checkName)
+	if err != nil {
+		return err
+	}
+	diffs := Compare(expected, s.instances, s.tolerance)
+	missing := s.store.Missing()
+
+	for _, request := range missing {
+		fmt.Fprintf(w, "No recorded response to %s\n", request)
+	}
+	for _, d := range diffs {
+		fmt.Fprintln(w, d)
+	}
+	if len(diffs) > 0 || len(missing) > 0 {
+		return fmt.Errorf("the replayed run of %s differs from the recording: %d sample difference(s), %d request(s) without a recorded response", checkName, len(diffs), len(missing))
+	}
+	fmt.Fprintf(w, "The replayed run of %s matches the recording\n", checkName)
+	return nil
+}
+
+// Close stops the proxy and disables the fixtures
+func (s *Session) Close() {
+	fixtures.SetCurrent(nil)
+	if s.restoreTransport != nil {
+		s.restoreTransport()
+	}
+	_ = s.proxy.Close()
+	if s.caFile != "" {
+		_ = os.Remove(s.caFile)
+	}
+}
@@ -37,7 +37,23 @@ func DefaultLabelSelectors(useNamespaceSelector bool, config LabelSelectorsConfi
 	}
 
 	applySelectorConfig(nsSelector, config)
+	applyAKSSelectors(nsSelector)
 
+	return nsSelector, objSelector
+}
+
+// NamespaceLabelSelector returns a namespace selector matching all the namespaces allowed by
+// the configuration, regardless of the admission labels of the namespaces and the objects.
+//
+// It is meant for the webhooks that must see every object, like the validating ones.
+func NamespaceLabelSelector(config LabelSelectorsConfig) *metav1.LabelSelector {
+	nsSelector := &metav1.LabelSelector{}
+	applySelectorConfig(nsSelector, config)
+	applyAKSSelectors(nsSelector)
+	return nsSelector
+}
+
+func applyAKSSelectors(nsSelector *metav1.LabelSelector) {
 	if pkgconfigsetup.Datadog().GetBool("admission_controller.add_aks_selectors") {
 		// AKS automatically adds some selector requirements if we don't
 		// so we need to add them to avoid conflicts when updating the webhook.
@@ -48,7 +64,6 @@ func DefaultLabelSelectors(useNamespaceSelector bool, config LabelSelectorsConfi
 			azureAKSLabelSelectorRequirement()...,
 		)
 	}
-	return nsSelector, objSelector
 }
 
 func applySelectorConfig(nsSelector *metav1.LabelSelector, config LabelSelectorsConfig) {
@@ -35,6 +35,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/clusteragent/admission/mutate/cwsinstrumentation"
 	"github.com/DataDog/datadog-agent/pkg/clusteragent/admission/mutate/tagsfromlabels"
 	"github.com/DataDog/datadog-agent/pkg/clusteragent/admission/validate/kubernetesadmissionevents"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/admission/validate/workloadpolicies"
 	"github.com/DataDog/datadog-agent/pkg/clusteragent/autoscaling/workload"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
@@ -110,6 +111,7 @@ func (c *controllerBase) generateWebhooks(wmeta workloadmeta.Component, pa workl
 		// Future validating webhooks can be added here.
 		validatingWebhooks = []Webhook{
 			kubernetesadmissionevents.NewWebhook(datadogConfig, demultiplexer, c.config.supportsMatchConditions()),
+			workloadpolicies.NewWebhook(datadogConfig),
 		}
 		webhooks = append(webhooks, validatingWebhooks...)
 	}
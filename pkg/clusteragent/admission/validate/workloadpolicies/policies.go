@@ -0,0 +1,191 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver
+
+package workloadpolicies
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/common/utils"
+	logsconfig "github.com/DataDog/datadog-agent/comp/logs/agent/config"
+)
+
+// Mode is the enforcement mode of the policies in a namespace
+type Mode string
+
+const (
+	// ModeWarn admits the requests violating the policies with warnings
+	ModeWarn Mode = "warn"
+	// ModeEnforce rejects the requests violating the policies
+	ModeEnforce Mode = "enforce"
+	// ModeDisabled doesn't evaluate the policies
+	ModeDisabled Mode = "disabled"
+)
+
+// parseMode parses an enforcement mode
+func parseMode(mode string) (Mode, error) {
+	switch m := Mode(strings.ToLower(strings.TrimSpace(mode))); m {
+	case ModeWarn, ModeEnforce, ModeDisabled:
+		return m, nil
+	default:
+		return "", fmt.Errorf("invalid mode %q, expected one of %s, %s or %s", mode, ModeWarn, ModeEnforce, ModeDisabled)
+	}
+}
+
+const (
+	legacyAnnotationPrefix = "service-discovery.datadoghq.com/"
+	logsAnnotationSuffix   = ".logs"
+)
+
+// checkAnnotationSuffixes are the suffixes of the autodiscovery annotations holding check
+// configurations
+var checkAnnotationSuffixes = []string{".checks", ".check_names", ".init_configs", ".instances"}
+
+// policies are the policies evaluated on workloads
+type policies struct {
+	requiredLabels           []string
+	validateCheckAnnotations bool
+	validateLogsAnnotations  bool
+}
+
+// result is the result of the evaluation of the policies on a workload
+type result struct {
+	// violations are rejected in enforce mode
+	violations []string
+	// warnings never cause a rejection
+	warnings []string
+}
+
+// podMetadataPaths are the paths of the metadata of the pods created by each kind of workload
+var podMetadataPaths = map[string][]string{
+	"Pod":         {"metadata"},
+	"Deployment":  {"spec", "template", "metadata"},
+	"StatefulSet": {"spec", "template", "metadata"},
+	"DaemonSet":   {"spec", "template", "metadata"},
+	"ReplicaSet":  {"spec", "template", "metadata"},
+	"Job":         {"spec", "template", "metadata"},
+	"CronJob":     {"spec", "jobTemplate", "spec", "template", "metadata"},
+}
+
+// evaluate evaluates the policies on the pods created by a workload
+func (p *policies) evaluate(obj *unstructured.Unstructured) result {
+	var res result
+
+	path, ok := podMetadataPaths[obj.GetKind()]
+	if !ok {
+		return res
+	}
+	labels, _, _ := unstructured.NestedStringMap(obj.Object, append(path, "labels")...)
+	annotations, _, _ := unstructured.NestedStringMap(obj.Object, append(path, "annotations")...)
+
+	var missing []string
+	for _, label := range p.requiredLabels {
+		if labels[label] == "" {
+			missing = append(missing, label)
+		}
+	}
+	if len(missing) > 0 {
+		res.violations = append(res.violations, fmt.Sprintf("missing required label(s) %s", strings.Join(missing, ", ")))
+	}
+
+	if p.validateCheckAnnotations {
+		res.violations = append(res.violations, checkAnnotationErrors(annotations)...)
+	}
+	if p.validateLogsAnnotations {
+		res.warnings = append(res.warnings, logsAnnotationErrors(annotations)...)
+	}
+	return res
+}
+
+// checkAnnotationErrors parses the autodiscovery check annotations with the parser of the
+// agent and returns the errors it reports
+func checkAnnotationErrors(annotations map[string]string) []string {
+	// logs annotations are validated separately, as they only cause warnings
+	checkAnnotations := map[string]string{}
+	for key, value := range annotations {
+		if !strings.HasSuffix(key, logsAnnotationSuffix) {
+			checkAnnotations[key] = value
+		}
+	}
+
+	var errs []string
+	for _, id := range adIdentifiers(checkAnnotations, checkAnnotationSuffixes) {
+		_, parseErrs := utils.ExtractTemplatesFromAnnotations(id, checkAnnotations, id)
+		for _, err := range parseErrs {
+			errs = append(errs, fmt.Sprintf("invalid check annotations for container %s: %s", id, err))
+		}
+	}
+	return errs
+}
+
+// logsAnnotationErrors parses the autodiscovery logs annotations and returns the errors
+// preventing them from being used
+func logsAnnotationErrors(annotations map[string]string) []string {
+	var errs []string
+	for _, id := range adIdentifiers(annotations, []string{logsAnnotationSuffix}) {
+		value, found := annotations[utils.KubeAnnotationPrefix+id+logsAnnotationSuffix]
+		if !found {
+			value = annotations[legacyAnnotationPrefix+id+logsAnnotationSuffix]
+		}
+		if err := validateLogsConfig(value); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid logs annotation for container %s: %s", id, err))
+		}
+	}
+	return errs
+}
+
+func validateLogsConfig(value string) error {
+	configs, err := logsconfig.ParseJSON([]byte(value))
+	if err != nil {
+		return err
+	}
+	for _, cfg := range configs {
+		if cfg == nil {
+			return fmt.Errorf("empty logs config")
+		}
+		if err := logsconfig.ValidateProcessingRules(cfg.ProcessingRules); err != nil {
+			return err
+		}
+		if err := logsconfig.CompileProcessingRules(cfg.ProcessingRules); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// adIdentifiers returns the sorted identifiers of the containers targeted by the
+// autodiscovery annotations with one of the given suffixes
+func adIdentifiers(annotations map[string]string, suffixes []string) []string {
+	ids := map[string]struct{}{}
+	for key := range annotations {
+		var rest string
+		switch {
+		case strings.HasPrefix(key, utils.KubeAnnotationPrefix):
+			rest = strings.TrimPrefix(key, utils.KubeAnnotationPrefix)
+		case strings.HasPrefix(key, legacyAnnotationPrefix):
+			rest = strings.TrimPrefix(key, legacyAnnotationPrefix)
+		default:
+			continue
+		}
+		for _, suffix := range suffixes {
+			if id, ok := strings.CutSuffix(rest, suffix); ok && id != "" {
+				ids[id] = struct{}{}
+			}
+		}
+	}
+
+	sorted := make([]string, 0, len(ids))
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
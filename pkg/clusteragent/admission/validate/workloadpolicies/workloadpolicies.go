@@ -0,0 +1,314 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver
+
+// Package workloadpolicies is a validating webhook checking that workloads follow the
+// Datadog tagging and autodiscovery configuration policies. Depending on the mode of their
+// namespace, the requests violating the policies are admitted with warnings or rejected.
+package workloadpolicies
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	admiv1 "k8s.io/api/admission/v1"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/DataDog/datadog-agent/cmd/cluster-agent/admission"
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/admission/common"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/admission/metrics"
+	apiServerCommon "github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/common"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	webhookName = "workload_policies"
+
+	// eventReasonWarned is the reason of the events of the requests admitted despite violating
+	// the policies
+	eventReasonWarned = "DatadogPolicyViolation"
+	// eventReasonRejected is the reason of the events of the rejected requests
+	eventReasonRejected = "DatadogPolicyRejected"
+)
+
+// Webhook is the WorkloadPolicies webhook.
+type Webhook struct {
+	name           string
+	isEnabled      bool
+	endpoint       string
+	resources      map[string][]string
+	operations     []admissionregistrationv1.OperationType
+	defaultMode    Mode
+	namespaceModes map[string]Mode
+	policies       policies
+
+	recorderOnce  sync.Once
+	eventRecorder record.EventRecorder
+}
+
+// NewWebhook returns a new WorkloadPolicies webhook.
+func NewWebhook(datadogConfig config.Component) *Webhook {
+	w := &Webhook{
+		name:      webhookName,
+		isEnabled: datadogConfig.GetBool("admission_controller.workload_policies.enabled"),
+		endpoint:  datadogConfig.GetString("admission_controller.workload_policies.endpoint"),
+		resources: map[string][]string{
+			"": {
+				"pods",
+			},
+			"apps": {
+				"deployments",
+				"statefulsets",
+				"daemonsets",
+				"replicasets",
+			},
+			"batch": {
+				"jobs",
+				"cronjobs",
+			},
+		},
+		operations: []admissionregistrationv1.OperationType{
+			admissionregistrationv1.Create,
+			admissionregistrationv1.Update,
+		},
+		defaultMode:    ModeWarn,
+		namespaceModes: map[string]Mode{},
+		policies: policies{
+			requiredLabels:           datadogConfig.GetStringSlice("admission_controller.workload_policies.required_labels"),
+			validateCheckAnnotations: datadogConfig.GetBool("admission_controller.workload_policies.validate_check_annotations"),
+			validateLogsAnnotations:  datadogConfig.GetBool("admission_controller.workload_policies.validate_logs_annotations"),
+		},
+	}
+
+	if mode, err := parseMode(datadogConfig.GetString("admission_controller.workload_policies.mode")); err != nil {
+		log.Errorf("Invalid admission_controller.workload_policies.mode, using %s: %v", ModeWarn, err)
+	} else {
+		w.defaultMode = mode
+	}
+	for namespace, value := range datadogConfig.GetStringMapString("admission_controller.workload_policies.namespace_modes") {
+		mode, err := parseMode(value)
+		if err != nil {
+			log.Errorf("Ignoring the workload policies mode of namespace %s: %v", namespace, err)
+			continue
+		}
+		w.namespaceModes[namespace] = mode
+	}
+
+	return w
+}
+
+// excludedNamespaces returns the namespaces for which the webhook isn't invoked: the system
+// namespaces, unless they have an explicit mode, and the namespaces whose mode is disabled.
+func (w *Webhook) excludedNamespaces() []string {
+	var excluded []string
+	for _, namespace := range []string{"kube-system", apiServerCommon.GetResourcesNamespace()} {
+		if _, ok := w.namespaceModes[namespace]; !ok && !slices.Contains(excluded, namespace) {
+			excluded = append(excluded, namespace)
+		}
+	}
+	for namespace, mode := range w.namespaceModes {
+		if mode == ModeDisabled {
+			excluded = append(excluded, namespace)
+		}
+	}
+	// the selector is part of the webhook configuration, keep it stable across reconciliations
+	slices.Sort(excluded)
+	return excluded
+}
+
+// Name returns the name of the webhook
+func (w *Webhook) Name() string {
+	return w.name
+}
+
+// WebhookType returns the type of the webhook
+func (w *Webhook) WebhookType() common.WebhookType {
+	return common.ValidatingWebhook
+}
+
+// IsEnabled returns whether the webhook is enabled
+func (w *Webhook) IsEnabled() bool {
+	return w.isEnabled
+}
+
+// Endpoint returns the endpoint of the webhook
+func (w *Webhook) Endpoint() string {
+	return w.endpoint
+}
+
+// Resources returns the kubernetes resources for which the webhook should
+// be invoked
+func (w *Webhook) Resources() map[string][]string {
+	return w.resources
+}
+
+// Timeout returns the timeout for the webhook
+func (w *Webhook) Timeout() int32 {
+	return 0
+}
+
+// Operations returns the operations on the resources specified for which
+// the webhook should be invoked
+func (w *Webhook) Operations() []admissionregistrationv1.OperationType {
+	return w.operations
+}
+
+// LabelSelectors returns the label selectors that specify when the webhook
+// should be invoked. Unlike the mutating webhooks, the policies apply to all the
+// workloads of the selected namespaces, labelled for admission or not.
+func (w *Webhook) LabelSelectors(_ bool) (namespaceSelector *metav1.LabelSelector, objectSelector *metav1.LabelSelector) {
+	return common.NamespaceLabelSelector(common.LabelSelectorsConfig{ExcludeNamespaces: w.excludedNamespaces()}), nil
+}
+
+// MatchConditions returns the Match Conditions used for fine-grained
+// request filtering
+func (w *Webhook) MatchConditions() []admissionregistrationv1.MatchCondition {
+	return []admissionregistrationv1.MatchCondition{}
+}
+
+// WebhookFunc returns the function that evaluates the policies on the admitted workload.
+func (w *Webhook) WebhookFunc() admission.WebhookFunc {
+	return func(request *admission.Request) *admiv1.AdmissionResponse {
+		response, err := w.validate(request)
+		if err != nil {
+			metrics.ValidationAttempts.Inc(w.Name(), metrics.StatusError, strconv.FormatBool(true), err.Error())
+			// the policies never block a request they can't evaluate
+			log.Warnf("Failed to evaluate the workload policies: %v", err)
+			return &admiv1.AdmissionResponse{Allowed: true}
+		}
+		metrics.ValidationAttempts.Inc(w.Name(), metrics.StatusSuccess, strconv.FormatBool(response.Allowed), "")
+		return response
+	}
+}
+
+// modeFor returns the enforcement mode of a namespace
+func (w *Webhook) modeFor(namespace string) Mode {
+	if mode, ok := w.namespaceModes[namespace]; ok {
+		return mode
+	}
+	return w.defaultMode
+}
+
+func (w *Webhook) validate(request *admission.Request) (*admiv1.AdmissionResponse, error) {
+	mode := w.modeFor(request.Namespace)
+	if mode == ModeDisabled {
+		return &admiv1.AdmissionResponse{Allowed: true}, nil
+	}
+
+	var obj unstructured.Unstructured
+	if err := json.Unmarshal(request.Object, &obj); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal object: %w", err)
+	}
+	// the pods and jobs created by controllers are validated through their owner
+	if metav1.GetControllerOf(&obj) != nil {
+		return &admiv1.AdmissionResponse{Allowed: true}, nil
+	}
+
+	res := w.policies.evaluate(&obj)
+
+	// an update doesn't get rejected for violations it didn't introduce, so that existing
+	// workloads can still be scaled or rolled back
+	if request.Operation == admissionregistrationv1.Update && len(request.OldObject) > 0 && len(res.violations) > 0 {
+		var oldObj unstructured.Unstructured
+		if err := json.Unmarshal(request.OldObject, &oldObj); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal oldObject: %w", err)
+		}
+		previous := map[string]struct{}{}
+		for _, violation := range w.policies.evaluate(&oldObj).violations {
+			previous[violation] = struct{}{}
+		}
+		var introduced []string
+		for _, violation := range res.violations {
+			if _, ok := previous[violation]; ok {
+				res.warnings = append(res.warnings, violation)
+			} else {
+				introduced = append(introduced, violation)
+			}
+		}
+		res.violations = introduced
+	}
+
+	if len(res.violations) == 0 && len(res.warnings) == 0 {
+		return &admiv1.AdmissionResponse{Allowed: true}, nil
+	}
+
+	all := append(append([]string{}, res.violations...), res.warnings...)
+	warnings := make([]string, 0, len(all))
+	for _, violation := range all {
+		warnings = append(warnings, "Datadog: "+violation)
+	}
+
+	if mode == ModeEnforce && len(res.violations) > 0 {
+		message := fmt.Sprintf("%s %s/%s violates the Datadog workload policies: %s", request.Kind.Kind, request.Namespace, objectName(&obj, request), strings.Join(res.violations, "; "))
+		w.emitEvent(request, &obj, corev1.EventTypeWarning, eventReasonRejected, message)
+		return &admiv1.AdmissionResponse{
+			Allowed:  false,
+			Warnings: warnings,
+			Result: &metav1.Status{
+				Status:  metav1.StatusFailure,
+				Reason:  metav1.StatusReasonForbidden,
+				Code:    403,
+				Message: message,
+			},
+		}, nil
+	}
+
+	message := fmt.Sprintf("%s %s/%s was admitted despite not following the Datadog workload policies: %s", request.Kind.Kind, request.Namespace, objectName(&obj, request), strings.Join(all, "; "))
+	w.emitEvent(request, &obj, corev1.EventTypeWarning, eventReasonWarned, message)
+	return &admiv1.AdmissionResponse{Allowed: true, Warnings: warnings}, nil
+}
+
+// objectName returns the name of the object, or its name prefix if it's generated
+func objectName(obj *unstructured.Unstructured, request *admission.Request) string {
+	if request.Name != "" {
+		return request.Name
+	}
+	if obj.GetName() != "" {
+		return obj.GetName()
+	}
+	return obj.GetGenerateName()
+}
+
+// emitEvent emits a Kubernetes event about the admitted object
+func (w *Webhook) emitEvent(request *admission.Request, obj *unstructured.Unstructured, eventType, reason, message string) {
+	recorder := w.recorder(request.APIClient)
+	if recorder == nil {
+		return
+	}
+	ref := &corev1.ObjectReference{
+		Kind:       request.Kind.Kind,
+		APIVersion: obj.GetAPIVersion(),
+		Namespace:  request.Namespace,
+		Name:       objectName(obj, request),
+		UID:        obj.GetUID(),
+	}
+	recorder.Event(ref, eventType, reason, message)
+}
+
+// recorder returns the event recorder, creating it with the client of the first request
+func (w *Webhook) recorder(client kubernetes.Interface) record.EventRecorder {
+	w.recorderOnce.Do(func() {
+		if w.eventRecorder != nil || client == nil {
+			return
+		}
+		broadcaster := record.NewBroadcaster()
+		broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: client.CoreV1().Events("")})
+		w.eventRecorder = broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: "datadog-cluster-agent"})
+	})
+	return w.eventRecorder
+}
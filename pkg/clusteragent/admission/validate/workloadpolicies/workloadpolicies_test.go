@@ -0,0 +1,281 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver
+
+package workloadpolicies
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/DataDog/datadog-agent/cmd/cluster-agent/admission"
+	"github.com/DataDog/datadog-agent/comp/core/config"
+)
+
+var ustLabels = map[string]interface{}{
+	"tags.datadoghq.com/env":     "prod",
+	"tags.datadoghq.com/service": "web",
+	"tags.datadoghq.com/version": "1.0",
+}
+
+func deployment(labels map[string]interface{}, annotations map[string]interface{}) []byte {
+	metadata := map[string]interface{}{}
+	if labels != nil {
+		metadata["labels"] = labels
+	}
+	if annotations != nil {
+		metadata["annotations"] = annotations
+	}
+	obj := map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "default"},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": metadata,
+			},
+		},
+	}
+	content, _ := json.Marshal(obj)
+	return content
+}
+
+func newTestWebhook(t *testing.T, overrides map[string]interface{}) (*Webhook, *record.FakeRecorder) {
+	datadogConfigMock := config.NewMock(t)
+	datadogConfigMock.SetWithoutSource("admission_controller.workload_policies.enabled", true)
+	datadogConfigMock.SetWithoutSource("kube_resources_namespace", "datadog")
+	for k, v := range overrides {
+		datadogConfigMock.SetWithoutSource(k, v)
+	}
+	w := NewWebhook(datadogConfigMock)
+	recorder := record.NewFakeRecorder(10)
+	w.eventRecorder = recorder
+	return w, recorder
+}
+
+func TestWorkloadPolicies(t *testing.T) {
+	tests := []struct {
+		name             string
+		namespace        string
+		operation        admissionregistrationv1.OperationType
+		object           []byte
+		oldObject        []byte
+		expectedAllowed  bool
+		expectedWarnings []string
+		expectedEvent    string
+	}{
+		{
+			name:            "compliant deployment",
+			namespace:       "default",
+			operation:       admissionregistrationv1.Create,
+			object:          deployment(ustLabels, nil),
+			expectedAllowed: true,
+		},
+		{
+			name:             "missing labels in warn mode",
+			namespace:        "default",
+			operation:        admissionregistrationv1.Create,
+			object:           deployment(map[string]interface{}{"tags.datadoghq.com/env": "prod"}, nil),
+			expectedAllowed:  true,
+			expectedWarnings: []string{"Datadog: missing required label(s) tags.datadoghq.com/service, tags.datadoghq.com/version"},
+			expectedEvent:    "Warning DatadogPolicyViolation Deployment default/web was admitted despite not following the Datadog workload policies: missing required label(s) tags.datadoghq.com/service, tags.datadoghq.com/version",
+		},
+		{
+			name:             "missing labels in enforce mode",
+			namespace:        "prod",
+			operation:        admissionregistrationv1.Create,
+			object:           deployment(nil, nil),
+			expectedAllowed:  false,
+			expectedWarnings: []string{"Datadog: missing required label(s) tags.datadoghq.com/env, tags.datadoghq.com/service, tags.datadoghq.com/version"},
+			expectedEvent:    "Warning DatadogPolicyRejected Deployment prod/web violates the Datadog workload policies: missing required label(s) tags.datadoghq.com/env, tags.datadoghq.com/service, tags.datadoghq.com/version",
+		},
+		{
+			name:            "disabled namespace",
+			namespace:       "kube-system",
+			operation:       admissionregistrationv1.Create,
+			object:          deployment(nil, nil),
+			expectedAllowed: true,
+		},
+		{
+			name:      "invalid check annotations in enforce mode",
+			namespace: "prod",
+			operation: admissionregistrationv1.Create,
+			object: deployment(ustLabels, map[string]interface{}{
+				"ad.datadoghq.com/redis.check_names":  `["redisdb"]`,
+				"ad.datadoghq.com/redis.init_configs": `[{}]`,
+				"ad.datadoghq.com/redis.instances":    `[{"host": "%%host%%"`,
+			}),
+			expectedAllowed:  false,
+			expectedWarnings: []string{"Datadog: invalid check annotations for container redis: could not extract checks config: in instances: failed to unmarshal JSON: unexpected end of JSON input"},
+			expectedEvent:    "Warning DatadogPolicyRejected Deployment prod/web violates the Datadog workload policies: invalid check annotations for container redis: could not extract checks config: in instances: failed to unmarshal JSON: unexpected end of JSON input",
+		},
+		{
+			name:      "valid check annotations",
+			namespace: "prod",
+			operation: admissionregistrationv1.Create,
+			object: deployment(ustLabels, map[string]interface{}{
+				"ad.datadoghq.com/redis.checks": `{"redisdb": {"instances": [{"host": "%%host%%", "port": 6379}]}}`,
+				"ad.datadoghq.com/redis.logs":   `[{"source": "redis", "service": "redis"}]`,
+			}),
+			expectedAllowed: true,
+		},
+		{
+			name:      "invalid logs annotations only warn",
+			namespace: "prod",
+			operation: admissionregistrationv1.Create,
+			object: deployment(ustLabels, map[string]interface{}{
+				"ad.datadoghq.com/redis.logs": `{"source": "redis"}`,
+			}),
+			expectedAllowed:  true,
+			expectedWarnings: []string{"Datadog: invalid logs annotation for container redis: could not parse JSON logs config: json: cannot unmarshal object into Go value of type []*config.LogsConfig"},
+			expectedEvent:    "Warning DatadogPolicyViolation Deployment prod/web was admitted despite not following the Datadog workload policies: invalid logs annotation for container redis: could not parse JSON logs config: json: cannot unmarshal object into Go value of type []*config.LogsConfig",
+		},
+		{
+			name:             "update keeping an existing violation",
+			namespace:        "prod",
+			operation:        admissionregistrationv1.Update,
+			object:           deployment(nil, nil),
+			oldObject:        deployment(nil, nil),
+			expectedAllowed:  true,
+			expectedWarnings: []string{"Datadog: missing required label(s) tags.datadoghq.com/env, tags.datadoghq.com/service, tags.datadoghq.com/version"},
+			expectedEvent:    "Warning DatadogPolicyViolation Deployment prod/web was admitted despite not following the Datadog workload policies: missing required label(s) tags.datadoghq.com/env, tags.datadoghq.com/service, tags.datadoghq.com/version",
+		},
+		{
+			name:             "update introducing a violation",
+			namespace:        "prod",
+			operation:        admissionregistrationv1.Update,
+			object:           deployment(nil, nil),
+			oldObject:        deployment(ustLabels, nil),
+			expectedAllowed:  false,
+			expectedWarnings: []string{"Datadog: missing required label(s) tags.datadoghq.com/env, tags.datadoghq.com/service, tags.datadoghq.com/version"},
+			expectedEvent:    "Warning DatadogPolicyRejected Deployment prod/web violates the Datadog workload policies: missing required label(s) tags.datadoghq.com/env, tags.datadoghq.com/service, tags.datadoghq.com/version",
+		},
+		{
+			name:      "pod owned by a controller",
+			namespace: "prod",
+			operation: admissionregistrationv1.Create,
+			object: func() []byte {
+				content, _ := json.Marshal(map[string]interface{}{
+					"apiVersion": "v1",
+					"kind":       "Pod",
+					"metadata": map[string]interface{}{
+						"generateName": "web-",
+						"ownerReferences": []interface{}{
+							map[string]interface{}{"apiVersion": "apps/v1", "kind": "ReplicaSet", "name": "web-123", "uid": "1", "controller": true},
+						},
+					},
+				})
+				return content
+			}(),
+			expectedAllowed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, recorder := newTestWebhook(t, map[string]interface{}{
+				"admission_controller.workload_policies.namespace_modes": map[string]string{
+					"prod":        "enforce",
+					"kube-system": "disabled",
+				},
+			})
+
+			response := w.WebhookFunc()(&admission.Request{
+				Name:      "web",
+				Namespace: tt.namespace,
+				Kind:      metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+				Operation: tt.operation,
+				Object:    tt.object,
+				OldObject: tt.oldObject,
+			})
+
+			require.NotNil(t, response)
+			assert.Equal(t, tt.expectedAllowed, response.Allowed)
+			assert.Equal(t, tt.expectedWarnings, response.Warnings)
+			if !tt.expectedAllowed {
+				require.NotNil(t, response.Result)
+				assert.Equal(t, metav1.StatusReasonForbidden, response.Result.Reason)
+			}
+
+			select {
+			case event := <-recorder.Events:
+				assert.Equal(t, tt.expectedEvent, event)
+			default:
+				assert.Empty(t, tt.expectedEvent, "expected an event")
+			}
+		})
+	}
+}
+
+func TestLabelSelectors(t *testing.T) {
+	w, _ := newTestWebhook(t, map[string]interface{}{
+		"admission_controller.workload_policies.namespace_modes": map[string]string{
+			"prod":    "enforce",
+			"sandbox": "disabled",
+		},
+	})
+
+	for _, useNamespaceSelector := range []bool{true, false} {
+		nsSelector, objSelector := w.LabelSelectors(useNamespaceSelector)
+		assert.Nil(t, objSelector, "the policies must apply to unlabelled objects")
+		require.NotNil(t, nsSelector)
+
+		selector, err := metav1.LabelSelectorAsSelector(nsSelector)
+		require.NoError(t, err)
+		for namespace, expected := range map[string]bool{"prod": true, "default": true, "sandbox": false, "kube-system": false, "datadog": false} {
+			assert.Equal(t, expected, selector.Matches(labels.Set{"kubernetes.io/metadata.name": namespace}), namespace)
+		}
+	}
+}
+
+func TestUnlabelledDeploymentRejected(t *testing.T) {
+	w, recorder := newTestWebhook(t, map[string]interface{}{
+		"admission_controller.workload_policies.mode": "enforce",
+	})
+
+	// neither the namespace nor the deployment carry the admission labels
+	nsSelector, objSelector := w.LabelSelectors(false)
+	selector, err := metav1.LabelSelectorAsSelector(nsSelector)
+	require.NoError(t, err)
+	require.True(t, selector.Matches(labels.Set{"kubernetes.io/metadata.name": "default"}))
+	require.Nil(t, objSelector)
+
+	response := w.WebhookFunc()(&admission.Request{
+		Name:      "web",
+		Namespace: "default",
+		Kind:      metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+		Operation: admissionregistrationv1.Create,
+		Object:    deployment(nil, nil),
+	})
+
+	require.NotNil(t, response)
+	assert.False(t, response.Allowed)
+	require.NotNil(t, response.Result)
+	assert.Equal(t, metav1.StatusReasonForbidden, response.Result.Reason)
+	assert.Len(t, recorder.Events, 1)
+}
+
+func TestParseMode(t *testing.T) {
+	for input, expected := range map[string]Mode{"warn": ModeWarn, " Enforce ": ModeEnforce, "disabled": ModeDisabled} {
+		mode, err := parseMode(input)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, mode)
+	}
+	_, err := parseMode("block")
+	assert.Error(t, err)
+}
+
+func TestInvalidDefaultMode(t *testing.T) {
+	w, _ := newTestWebhook(t, map[string]interface{}{"admission_controller.workload_policies.mode": "block"})
+	assert.Equal(t, ModeWarn, w.modeFor("default"))
+}
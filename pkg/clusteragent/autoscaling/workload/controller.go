@@ -323,6 +323,9 @@ func (c *Controller) syncPodAutoscaler(ctx context.Context, key, ns, name string
 	// Reaching this point, we had no errors in processing, clearing up global error
 	podAutoscalerInternal.SetError(nil)
 
+	// Annotations updates do not change the generation, refreshing the dry run mode at each sync
+	podAutoscalerInternal.UpdateDryRun(podAutoscaler.Annotations)
+
 	// Validate autoscaler requirements
 	validationErr := c.validateAutoscaler(podAutoscalerInternal)
 	if validationErr != nil {
@@ -572,6 +575,9 @@ func getActiveScalingSources(currentTime time.Time, podAutoscalerInternal *model
 	var activeVerticalSource *datadoghqcommon.DatadogPodAutoscalerValueSource
 	if podAutoscalerInternal.MainScalingValues().Vertical != nil {
 		activeVerticalSource = pointer.Ptr(podAutoscalerInternal.MainScalingValues().Vertical.Source)
+	} else if podAutoscalerInternal.FallbackScalingValues().Vertical != nil {
+		// Without product vertical recommendations, use the local ones if any
+		activeVerticalSource = pointer.Ptr(datadoghqcommon.DatadogPodAutoscalerLocalValueSource)
 	}
 
 	// Check if horizontal scaling is disabled; if disabled, always use main values as source
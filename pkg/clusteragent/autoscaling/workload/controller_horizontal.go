@@ -41,7 +41,7 @@ const (
 )
 
 type horizontalController struct {
-	clock         clock.Clock
+	clock         clock.PassiveClock
 	eventRecorder record.EventRecorder
 	scaler        scaler
 }
@@ -92,16 +92,7 @@ func (hr *horizontalController) performScaling(ctx context.Context, podAutoscale
 	replicasFromRec := scalingValues.Horizontal.Replicas
 
 	// Handling min/max replicas
-	specConstraints := autoscalerSpec.Constraints
-	minReplicas := defaultMinReplicas
-	if specConstraints != nil && specConstraints.MinReplicas != nil {
-		minReplicas = *specConstraints.MinReplicas
-	}
-
-	maxReplicas := defaultMaxReplicas
-	if specConstraints != nil && specConstraints.MaxReplicas >= minReplicas {
-		maxReplicas = specConstraints.MaxReplicas
-	}
+	minReplicas, maxReplicas := getReplicasConstraints(autoscalerSpec)
 
 	// Compute the desired number of replicas based on recommendations, rules and constraints
 	horizontalAction, nextEvalAfter, err := hr.computeScaleAction(autoscalerInternal, scalingValues.Horizontal.Source, currentDesiredReplicas, replicasFromRec, minReplicas, maxReplicas)
@@ -118,6 +109,15 @@ func (hr *horizontalController) performScaling(ctx context.Context, podAutoscale
 		return autoscaling.NoRequeue, nil
 	}
 
+	// In dry run, we only surface the action that would have been taken
+	if autoscalerInternal.DryRun() {
+		hr.recordDryRunAction(podAutoscaler, autoscalerInternal, scale, horizontalAction)
+		if nextEvalAfter > 0 {
+			return autoscaling.Requeue.After(nextEvalAfter), nil
+		}
+		return autoscaling.NoRequeue, nil
+	}
+
 	scale.Spec.Replicas = horizontalAction.ToReplicas
 	_, err = hr.scaler.update(ctx, gr, scale)
 	if err != nil {
@@ -147,6 +147,21 @@ func (hr *horizontalController) performScaling(ctx context.Context, podAutoscale
 	return autoscaling.NoRequeue, nil
 }
 
+func (hr *horizontalController) recordDryRunAction(podAutoscaler *datadoghq.DatadogPodAutoscaler, autoscalerInternal *model.PodAutoscalerInternal, scale *autoscalingv1.Scale, horizontalAction *datadoghqcommon.DatadogPodAutoscalerHorizontalAction) {
+	// Only generating an event when the action changes to avoid flooding events at each reconcile
+	previousAction := autoscalerInternal.HorizontalDryRunAction()
+	if previousAction == nil || previousAction.FromReplicas != horizontalAction.FromReplicas || previousAction.ToReplicas != horizontalAction.ToReplicas {
+		message := fmt.Sprintf("Dry run: would have scaled target: %s/%s from %d replicas to %d replicas", scale.Namespace, scale.Name, horizontalAction.FromReplicas, horizontalAction.ToReplicas)
+		if horizontalAction.LimitedReason != nil {
+			message += fmt.Sprintf(" (%s)", *horizontalAction.LimitedReason)
+		}
+		log.Infof("%s, autoscaler: %s", message, autoscalerInternal.ID())
+		hr.eventRecorder.Event(podAutoscaler, corev1.EventTypeNormal, model.DryRunScaleEventReason, message)
+	}
+
+	autoscalerInternal.UpdateFromHorizontalDryRunAction(horizontalAction)
+}
+
 func (hr *horizontalController) computeScaleAction(
 	autoscalerInternal *model.PodAutoscalerInternal,
 	source datadoghqcommon.DatadogPodAutoscalerValueSource,
@@ -273,6 +288,22 @@ func (hr *horizontalController) computeScaleAction(
 	return horizontalAction, evalAfter, nil
 }
 
+// getReplicasConstraints returns the min/max replicas from the autoscaler constraints, or the defaults
+func getReplicasConstraints(autoscalerSpec *datadoghq.DatadogPodAutoscalerSpec) (int32, int32) {
+	specConstraints := autoscalerSpec.Constraints
+	minReplicas := defaultMinReplicas
+	if specConstraints != nil && specConstraints.MinReplicas != nil {
+		minReplicas = *specConstraints.MinReplicas
+	}
+
+	maxReplicas := defaultMaxReplicas
+	if specConstraints != nil && specConstraints.MaxReplicas >= minReplicas {
+		maxReplicas = specConstraints.MaxReplicas
+	}
+
+	return minReplicas, maxReplicas
+}
+
 func isFallbackScalingDirectionEnabled(fallbackEnabledDirection datadoghq.DatadogPodAutoscalerFallbackDirection, scaleDirection common.ScaleDirection) bool {
 	if fallbackEnabledDirection == "" {
 		// Default to ScaleUp if not set
@@ -1518,3 +1518,61 @@ func TestHorizontalControllerSyncScaleWithBothStabilizationWindows(t *testing.T)
 	assert.Equal(t, autoscaling.NoRequeue, result)
 	assert.NoError(t, err)
 }
+
+func TestHorizontalControllerSyncDryRun(t *testing.T) {
+	testTime := time.Now()
+	f := newHorizontalControllerFixture(t, testTime)
+	autoscalerNamespace := "default"
+	autoscalerName := "test"
+
+	expectedGVK := schema.GroupVersionKind{
+		Group:   "apps",
+		Version: "v1",
+		Kind:    "Deployment",
+	}
+	fakePai := &model.FakePodAutoscalerInternal{
+		Namespace: autoscalerNamespace,
+		Name:      autoscalerName,
+		Spec: &datadoghq.DatadogPodAutoscalerSpec{
+			TargetRef: v2.CrossVersionObjectReference{
+				Name:       autoscalerName,
+				Kind:       expectedGVK.Kind,
+				APIVersion: expectedGVK.Group + "/" + expectedGVK.Version,
+			},
+		},
+		ScalingValues: model.ScalingValues{
+			Horizontal: &model.HorizontalScalingValues{
+				Source:    datadoghqcommon.DatadogPodAutoscalerAutoscalingValueSource,
+				Timestamp: testTime.Add(-30 * time.Second),
+				Replicas:  7,
+			},
+		},
+		TargetGVK:       expectedGVK,
+		CurrentReplicas: pointer.Ptr[int32](5),
+		DryRun:          true,
+	}
+
+	// Scale subresource is never updated in dry run
+	f.scaler.mockGet(*fakePai, 5, 5, nil)
+	autoscaler, result, err := f.runSync(fakePai)
+	assert.NoError(t, err)
+	assert.Equal(t, autoscaling.NoRequeue, result)
+	f.scaler.AssertNumberOfCalls(t, "update", 0)
+
+	expectedAction := &datadoghqcommon.DatadogPodAutoscalerHorizontalAction{
+		Time:                metav1.NewTime(testTime),
+		FromReplicas:        5,
+		ToReplicas:          7,
+		RecommendedReplicas: pointer.Ptr[int32](7),
+	}
+	assert.Equal(t, expectedAction, autoscaler.HorizontalDryRunAction())
+	assert.Empty(t, autoscaler.HorizontalLastActions())
+	assert.Equal(t, "Normal DryRunScale Dry run: would have scaled target: default/test from 5 replicas to 7 replicas", <-f.recorder.Events)
+
+	// Same action is not generating a new event
+	fakePai.HorizontalDryRunAction = autoscaler.HorizontalDryRunAction()
+	_, _, err = f.runSync(fakePai)
+	assert.NoError(t, err)
+	f.scaler.AssertNumberOfCalls(t, "update", 0)
+	assert.Empty(t, f.recorder.Events)
+}
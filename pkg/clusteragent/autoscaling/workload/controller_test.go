@@ -1241,6 +1241,47 @@ func TestGetActiveScalingSources(t *testing.T) {
 			wantHorizontalSource: nil,
 			wantVerticalSource:   nil,
 		},
+		{
+			name: "no main vertical values, local vertical values are available",
+			podAutoscalerInternal: model.FakePodAutoscalerInternal{
+				Namespace: "default",
+				Name:      "dpa-0",
+				Spec:      &datadoghq.DatadogPodAutoscalerSpec{},
+				MainScalingValues: model.ScalingValues{
+					Horizontal: &model.HorizontalScalingValues{
+						Source:    datadoghqcommon.DatadogPodAutoscalerAutoscalingValueSource,
+						Timestamp: currentTime,
+					},
+				},
+				FallbackScalingValues: model.ScalingValues{
+					Vertical: &model.VerticalScalingValues{
+						Source: datadoghqcommon.DatadogPodAutoscalerLocalValueSource,
+					},
+				},
+			},
+			wantHorizontalSource: pointer.Ptr(datadoghqcommon.DatadogPodAutoscalerAutoscalingValueSource),
+			wantVerticalSource:   pointer.Ptr(datadoghqcommon.DatadogPodAutoscalerLocalValueSource),
+		},
+		{
+			name: "main and local vertical values are available",
+			podAutoscalerInternal: model.FakePodAutoscalerInternal{
+				Namespace: "default",
+				Name:      "dpa-0",
+				Spec:      &datadoghq.DatadogPodAutoscalerSpec{},
+				MainScalingValues: model.ScalingValues{
+					Vertical: &model.VerticalScalingValues{
+						Source: datadoghqcommon.DatadogPodAutoscalerAutoscalingValueSource,
+					},
+				},
+				FallbackScalingValues: model.ScalingValues{
+					Vertical: &model.VerticalScalingValues{
+						Source: datadoghqcommon.DatadogPodAutoscalerLocalValueSource,
+					},
+				},
+			},
+			wantHorizontalSource: nil,
+			wantVerticalSource:   pointer.Ptr(datadoghqcommon.DatadogPodAutoscalerAutoscalingValueSource),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -26,7 +26,6 @@ import (
 	datadoghqcommon "github.com/DataDog/datadog-operator/api/datadoghq/common"
 	datadoghq "github.com/DataDog/datadog-operator/api/datadoghq/v1alpha2"
 
-	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
 	"github.com/DataDog/datadog-agent/pkg/clusteragent/autoscaling"
 	"github.com/DataDog/datadog-agent/pkg/clusteragent/autoscaling/workload/model"
 	k8sutil "github.com/DataDog/datadog-agent/pkg/util/kubernetes"
@@ -95,58 +94,115 @@ func (u *verticalController) sync(ctx context.Context, podAutoscaler *datadoghq.
 	// Update scaled replicas status
 	autoscalerInternal.SetScaledReplicas(podsPerRecomendationID[recomendationID])
 
-	// Check if we're allowed to rollout, we don't care about the source in this case, so passing most favorable source: manual
-	updateStrategy, reason := getVerticalPatchingStrategy(autoscalerInternal)
-	if updateStrategy == datadoghqcommon.DatadogPodAutoscalerDisabledUpdateStrategy {
+	rollout, reason := checkVerticalRollout(autoscalerInternal, targetGVK.Kind, u.clock.Now(), int32(len(pods)), podsPerRecomendationID[recomendationID], len(podsPerDirectOwner))
+	switch rollout {
+	case verticalRolloutDisabled, verticalRolloutUnsupported:
 		autoscalerInternal.UpdateFromVerticalAction(nil, errors.New(reason))
 		return autoscaling.NoRequeue, nil
-	}
-
-	// Check if last action was done in the `rolloutCheckRequeueDelay` window
-	if autoscalerInternal.VerticalLastAction() != nil && autoscalerInternal.VerticalLastAction().Time.Add(rolloutCheckRequeueDelay).After(u.clock.Now()) {
-		log.Debugf("Last action was done less than %s ago for autoscaler: %s, skipping", rolloutCheckRequeueDelay.String(), autoscalerInternal.ID())
+	case verticalRolloutPending, verticalRolloutOngoing:
+		log.Debugf("Skipping rollout for autoscaler: %s, gvk: %s, name: %s: %s", autoscalerInternal.ID(), targetGVK.String(), autoscalerInternal.Spec().TargetRef.Name, reason)
 		return autoscaling.ProcessResult{Requeue: true, RequeueAfter: rolloutCheckRequeueDelay}, nil
+	case verticalRolloutUpToDate:
+		autoscalerInternal.UpdateFromVerticalAction(nil, nil)
+		return autoscaling.NoRequeue, nil
 	}
 
 	switch targetGVK.Kind {
 	case k8sutil.DeploymentKind:
-		return u.syncDeploymentKind(ctx, podAutoscaler, autoscalerInternal, updateStrategy, target, targetGVK, recomendationID, pods, podsPerRecomendationID, podsPerDirectOwner)
+		return u.syncDeploymentKind(ctx, podAutoscaler, autoscalerInternal, target, targetGVK, recomendationID)
 	case k8sutil.RolloutKind:
-		return u.syncRolloutKind(ctx, podAutoscaler, autoscalerInternal, updateStrategy, target, targetGVK, recomendationID, pods, podsPerRecomendationID, podsPerDirectOwner)
+		return u.syncRolloutKind(ctx, podAutoscaler, autoscalerInternal, target, targetGVK, recomendationID)
 	default:
-		autoscalerInternal.UpdateFromVerticalAction(nil, fmt.Errorf("automic rollout not available for target Kind: %s. Applying to existing PODs require manual trigger", targetGVK.Kind))
+		// checkVerticalRollout only allows the kinds above
 		return autoscaling.NoRequeue, nil
 	}
 }
 
+// verticalRollout is the outcome of the checks preceding the rollout of a vertical recommendation
+type verticalRollout int
+
+const (
+	// verticalRolloutNeeded means a rollout must be triggered
+	verticalRolloutNeeded verticalRollout = iota
+	// verticalRolloutNoPods means the target has no pods
+	verticalRolloutNoPods
+	// verticalRolloutDisabled means the update strategy doesn't allow rollouts
+	verticalRolloutDisabled
+	// verticalRolloutPending means the last rollout is too recent to trigger a new one
+	verticalRolloutPending
+	// verticalRolloutUnsupported means the kind of the target can't be rolled out automatically
+	verticalRolloutUnsupported
+	// verticalRolloutUpToDate means all the pods already use the recommendation
+	verticalRolloutUpToDate
+	// verticalRolloutOngoing means the pods are spread across several owners, as during a rollout
+	verticalRolloutOngoing
+)
+
+// checkVerticalRollout checks whether a rollout of the vertical recommendation must be triggered, given the
+// number of pods of the target, how many use the recommendation and their number of direct owners. It returns
+// the reason why no rollout is needed otherwise. The checks are shared by the vertical controller and the
+// simulation.
+func checkVerticalRollout(autoscalerInternal *model.PodAutoscalerInternal, targetKind string, now time.Time, pods, podsWithRecommendation int32, directOwners int) (verticalRollout, string) {
+	if pods == 0 {
+		return verticalRolloutNoPods, "no pods found for target"
+	}
+
+	// Check if we're allowed to rollout, we don't care about the source in this case, so passing most favorable source: manual
+	updateStrategy, reason := getVerticalPatchingStrategy(autoscalerInternal)
+	if updateStrategy == datadoghqcommon.DatadogPodAutoscalerDisabledUpdateStrategy {
+		return verticalRolloutDisabled, reason
+	}
+
+	// Check if last action was done in the `rolloutCheckRequeueDelay` window
+	if autoscalerInternal.VerticalLastAction() != nil && autoscalerInternal.VerticalLastAction().Time.Add(rolloutCheckRequeueDelay).After(now) {
+		return verticalRolloutPending, fmt.Sprintf("last rollout was triggered less than %s ago", rolloutCheckRequeueDelay)
+	}
+
+	if targetKind != k8sutil.DeploymentKind && targetKind != k8sutil.RolloutKind {
+		return verticalRolloutUnsupported, fmt.Sprintf("automic rollout not available for target Kind: %s. Applying to existing PODs require manual trigger", targetKind)
+	}
+
+	// Check if we need to rollout, currently basic check with 100% match expected.
+	// TODO: Refine the logic and add backoff for stuck PODs.
+	if podsWithRecommendation == pods {
+		return verticalRolloutUpToDate, "all pods already use the recommended resources"
+	}
+
+	// Check if a rollout is already ongoing
+	// TODO: Refine the logic and add backoff for stuck PODs.
+	if directOwners > 1 {
+		return verticalRolloutOngoing, "rollout already ongoing"
+	}
+
+	return verticalRolloutNeeded, ""
+}
+
 func (u *verticalController) syncDeploymentKind(
 	ctx context.Context,
 	podAutoscaler *datadoghq.DatadogPodAutoscaler,
 	autoscalerInternal *model.PodAutoscalerInternal,
-	_ datadoghqcommon.DatadogPodAutoscalerUpdateStrategy,
 	target NamespacedPodOwner,
 	targetGVK schema.GroupVersionKind,
 	recommendationID string,
-	pods []*workloadmeta.KubernetesPod,
-	podsPerRecomendationID map[string]int32,
-	podsPerDirectOwner map[string]int32,
 ) (autoscaling.ProcessResult, error) {
-	// Check if we need to rollout, currently basic check with 100% match expected.
-	// TODO: Refine the logic and add backoff for stuck PODs.
-	if podsPerRecomendationID[recommendationID] == int32(len(pods)) {
-		autoscalerInternal.UpdateFromVerticalAction(nil, nil)
-		return autoscaling.NoRequeue, nil
-	}
+	// Normally we should check updateStrategy here, we currently only support one way, so not required for now.
 
-	// Check if a rollout is already ongoing
-	// TODO: Refine the logic and add backoff for stuck PODs.
-	if len(podsPerDirectOwner) > 1 {
-		log.Debugf("Rollout already ongoing for autoscaler: %s, gvk: %s, name: %s", autoscalerInternal.ID(), targetGVK.String(), autoscalerInternal.Spec().TargetRef.Name)
+	// In dry run, we only surface the rollout that would have been triggered
+	if autoscalerInternal.DryRun() {
+		previousAction := autoscalerInternal.VerticalDryRunAction()
+		if previousAction == nil || previousAction.Version != recommendationID {
+			log.Infof("Dry run: would have triggered rollout for autoscaler: %s, gvk: %s, name: %s, version: %s", autoscalerInternal.ID(), targetGVK.String(), autoscalerInternal.Spec().TargetRef.Name, recommendationID)
+			u.eventRecorder.Eventf(podAutoscaler, corev1.EventTypeNormal, model.DryRunTriggerRolloutEventReason, "Dry run: would have triggered rollout on target:%s/%s", targetGVK.String(), autoscalerInternal.Spec().TargetRef.Name)
+		}
+
+		autoscalerInternal.UpdateFromVerticalDryRunAction(&datadoghqcommon.DatadogPodAutoscalerVerticalAction{
+			Time:    metav1.NewTime(u.clock.Now()),
+			Version: recommendationID,
+			Type:    datadoghqcommon.DatadogPodAutoscalerRolloutTriggeredVerticalActionType,
+		})
 		return autoscaling.ProcessResult{Requeue: true, RequeueAfter: rolloutCheckRequeueDelay}, nil
 	}
 
-	// Normally we should check updateStrategy here, we currently only support one way, so not required for now.
-
 	// Generate the patch request which adds the scaling hash annotation to the pod template
 	gvr := targetGVK.GroupVersion().WithResource(fmt.Sprintf("%ss", strings.ToLower(targetGVK.Kind)))
 	patchTime := u.clock.Now()
@@ -196,17 +252,13 @@ func (u *verticalController) syncRolloutKind(
 	ctx context.Context,
 	podAutoscaler *datadoghq.DatadogPodAutoscaler,
 	autoscalerInternal *model.PodAutoscalerInternal,
-	updateStrategy datadoghqcommon.DatadogPodAutoscalerUpdateStrategy,
 	target NamespacedPodOwner,
 	targetGVK schema.GroupVersionKind,
 	recommendationID string,
-	pods []*workloadmeta.KubernetesPod,
-	podsPerRecomendationID map[string]int32,
-	podsPerDirectOwner map[string]int32,
 ) (autoscaling.ProcessResult, error) {
 	// Argo Rollouts use the same pod template structure as Deployments,
 	// so we can reuse the same rollout logic
-	return u.syncDeploymentKind(ctx, podAutoscaler, autoscalerInternal, updateStrategy, target, targetGVK, recommendationID, pods, podsPerRecomendationID, podsPerDirectOwner)
+	return u.syncDeploymentKind(ctx, podAutoscaler, autoscalerInternal, target, targetGVK, recommendationID)
 }
 
 // getVerticalPatchingStrategy applied policies to determine effective patching strategy.
@@ -17,6 +17,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/clusteragent/autoscaling/workload"
 	"github.com/DataDog/datadog-agent/pkg/clusteragent/autoscaling/workload/loadstore"
 	"github.com/DataDog/datadog-agent/pkg/clusteragent/autoscaling/workload/model"
+	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
@@ -28,17 +29,30 @@ const (
 // Recommender is the interface used to generate local recommendations
 type Recommender struct {
 	replicaCalculator replicaCalculator
-	store             *autoscaling.Store[model.PodAutoscalerInternal]
+	// verticalCalculator is nil when local vertical recommendations are disabled
+	verticalCalculator *verticalCalculator
+	store              *autoscaling.Store[model.PodAutoscalerInternal]
 }
 
 // NewRecommender creates a new Recommender to start generating local recommendations
 func NewRecommender(clock clock.Clock, podWatcher workload.PodWatcher, store *autoscaling.Store[model.PodAutoscalerInternal]) *Recommender {
 	replicaCalculator := newReplicaCalculator(clock, podWatcher)
 
-	return &Recommender{
+	recommender := &Recommender{
 		replicaCalculator: replicaCalculator,
 		store:             store,
 	}
+
+	if pkgconfigsetup.Datadog().GetBool(verticalConfigPrefix + "enabled") {
+		settings, err := newVerticalRecommenderSettings(pkgconfigsetup.Datadog())
+		if err != nil {
+			log.Errorf("Local vertical recommendations disabled due to invalid configuration: %v", err)
+		} else {
+			recommender.verticalCalculator = newVerticalCalculator(clock, podWatcher, settings)
+		}
+	}
+
+	return recommender
 }
 
 // Run starts the Recommender interface to generate local recommendations
@@ -70,37 +84,49 @@ func (r *Recommender) process(ctx context.Context) {
 	}
 
 	localFallbackFilter := func(podAutoscaler model.PodAutoscalerInternal) bool {
-		// Only return false if Fallback exists and Horizontal.Enabled is explicitly set to false
-		if podAutoscaler.Spec().Fallback != nil && !podAutoscaler.Spec().Fallback.Horizontal.Enabled {
-			return false
-		}
-		return true
+		return horizontalFallbackEnabled(podAutoscaler) || r.verticalCalculator != nil
 	}
 	podAutoscalers := r.store.GetFiltered(localFallbackFilter)
+	autoscalerIDs := make(map[string]struct{}, len(podAutoscalers))
 
 	for _, podAutoscaler := range podAutoscalers {
+		autoscalerIDs[podAutoscaler.ID()] = struct{}{}
+		recommendation := model.ScalingValues{}
+
 		// Generate local recommendations
-		horizontalRecommendation, err := r.replicaCalculator.calculateHorizontalRecommendations(podAutoscaler, lStore)
-		r.updateAutoscaler(podAutoscaler.ID(), horizontalRecommendation, err)
-		if err != nil {
-			log.Debugf("Got error calculating horizontal recommendation for pod autoscaler %s: %v", podAutoscaler.ID(), err)
-		} else {
-			log.Debugf("Updated local fallback values for pod autoscaler %s", podAutoscaler.ID())
+		if horizontalFallbackEnabled(podAutoscaler) {
+			recommendation.Horizontal, recommendation.HorizontalError = r.replicaCalculator.calculateHorizontalRecommendations(podAutoscaler, lStore)
+			if recommendation.HorizontalError != nil {
+				log.Debugf("Got error calculating horizontal recommendation for pod autoscaler %s: %v", podAutoscaler.ID(), recommendation.HorizontalError)
+			}
 		}
-	}
-}
 
-func (r *Recommender) updateAutoscaler(key string, horizontalRecommendation *model.HorizontalScalingValues, err error) {
-	recommendation := model.ScalingValues{}
+		if r.verticalCalculator != nil {
+			recommendation.Vertical, recommendation.VerticalError = r.verticalCalculator.calculateVerticalRecommendations(podAutoscaler, lStore)
+			if recommendation.VerticalError != nil {
+				// Keep the previous recommendation, so that new pods don't go back to the resources of the pod template
+				recommendation.Vertical = podAutoscaler.FallbackScalingValues().Vertical
+				log.Debugf("Got error calculating vertical recommendation for pod autoscaler %s: %v", podAutoscaler.ID(), recommendation.VerticalError)
+			}
+		}
 
-	if err != nil {
-		recommendation.HorizontalError = err
+		r.updateAutoscaler(podAutoscaler.ID(), recommendation)
+		if recommendation.HorizontalError == nil && recommendation.VerticalError == nil {
+			log.Debugf("Updated local fallback values for pod autoscaler %s", podAutoscaler.ID())
+		}
 	}
 
-	if horizontalRecommendation != nil {
-		recommendation.Horizontal = horizontalRecommendation
+	if r.verticalCalculator != nil {
+		r.verticalCalculator.prune(autoscalerIDs)
 	}
+}
+
+// horizontalFallbackEnabled returns false only if Fallback exists and Horizontal.Enabled is explicitly set to false
+func horizontalFallbackEnabled(podAutoscaler model.PodAutoscalerInternal) bool {
+	return podAutoscaler.Spec().Fallback == nil || podAutoscaler.Spec().Fallback.Horizontal.Enabled
+}
 
+func (r *Recommender) updateAutoscaler(key string, recommendation model.ScalingValues) {
 	podAutoscalerInternal, found := r.store.LockRead(key, true)
 	if !found { // In case the object is deleted in between when we start calculating
 		log.Debugf("Object %s not found in store; local recommendation values not updated", key)
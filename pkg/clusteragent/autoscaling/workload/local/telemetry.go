@@ -35,4 +35,13 @@ var (
 		"Tracks the utilization value reported by the local recommender",
 		commonOpts,
 	)
+
+	// telemetryVerticalLocalRecommendations tracks the local vertical scaling recommendation values
+	telemetryVerticalLocalRecommendations = telemetry.NewGaugeWithOpts(
+		subsystem,
+		"vertical_scaling_recommended_requests",
+		[]string{"namespace", "target_name", "autoscaler_name", "container_name", "resource_name", "source", le.JoinLeaderLabel},
+		"Tracks the value of the container requests recommended by the local recommender",
+		commonOpts,
+	)
 )
@@ -0,0 +1,115 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+//go:build kubeapiserver
+
+package local
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// histogramBucketGrowth is the ratio between the sizes of two consecutive buckets, which bounds the
+	// relative error of the percentiles to 5%
+	histogramBucketGrowth = 1.05
+	// maxDecayExponent is the decay exponent above which the weights are rescaled to avoid overflows
+	maxDecayExponent = 64
+)
+
+// usageHistogram is a histogram of resource usage samples with exponentially decaying weights.
+// Buckets grow exponentially, so that the memory used doesn't depend on the number of samples, and
+// a sample loses half of its weight every half-life, so that recent usage matters more than old usage.
+type usageHistogram struct {
+	firstBucketSize float64
+	halfLife        time.Duration
+
+	weights       []float64
+	totalWeight   float64
+	sampleCount   int
+	referenceTime time.Time
+}
+
+// newUsageHistogram returns a histogram whose first bucket is [0, firstBucketSize) and whose last
+// bucket starts at maxValue
+func newUsageHistogram(firstBucketSize, maxValue float64, halfLife time.Duration) *usageHistogram {
+	numBuckets := int(math.Ceil(math.Log(maxValue/firstBucketSize)/math.Log(histogramBucketGrowth))) + 1
+	return &usageHistogram{
+		firstBucketSize: firstBucketSize,
+		halfLife:        halfLife,
+		weights:         make([]float64, numBuckets),
+	}
+}
+
+// addSample adds a usage sample collected at the given time
+func (h *usageHistogram) addSample(value float64, timestamp time.Time) {
+	if value < 0 || math.IsNaN(value) {
+		return
+	}
+
+	if h.referenceTime.IsZero() {
+		h.referenceTime = timestamp
+	}
+	exponent := float64(timestamp.Sub(h.referenceTime)) / float64(h.halfLife)
+	if exponent > maxDecayExponent {
+		h.rescale(timestamp)
+		exponent = 0
+	}
+
+	weight := math.Exp2(exponent)
+	h.weights[h.bucket(value)] += weight
+	h.totalWeight += weight
+	h.sampleCount++
+}
+
+// rescale moves the reference time of the weights, dividing them by the decay between the two times
+func (h *usageHistogram) rescale(referenceTime time.Time) {
+	factor := math.Exp2(-float64(referenceTime.Sub(h.referenceTime)) / float64(h.halfLife))
+	h.totalWeight = 0
+	for i := range h.weights {
+		h.weights[i] *= factor
+		h.totalWeight += h.weights[i]
+	}
+	h.referenceTime = referenceTime
+}
+
+// percentile returns the upper bound of the bucket holding the given percentile (between 0 and 1) of
+// the samples, or 0 if the histogram is empty
+func (h *usageHistogram) percentile(p float64) float64 {
+	if h.totalWeight == 0 {
+		return 0
+	}
+
+	threshold := p * h.totalWeight
+	cumulated := 0.0
+	lastBucket := 0
+	for i, weight := range h.weights {
+		if weight == 0 {
+			continue
+		}
+		cumulated += weight
+		lastBucket = i
+		if cumulated >= threshold {
+			break
+		}
+	}
+	// Rounding errors may prevent the cumulated weight from reaching the total weight
+	return h.bucketEnd(lastBucket)
+}
+
+// bucket returns the index of the bucket holding a value
+func (h *usageHistogram) bucket(value float64) int {
+	if value < h.firstBucketSize {
+		return 0
+	}
+	index := int(math.Floor(math.Log(value/h.firstBucketSize)/math.Log(histogramBucketGrowth))) + 1
+	return min(index, len(h.weights)-1)
+}
+
+// bucketEnd returns the upper bound of a bucket
+func (h *usageHistogram) bucketEnd(index int) float64 {
+	return h.firstBucketSize * math.Pow(histogramBucketGrowth, float64(index))
+}
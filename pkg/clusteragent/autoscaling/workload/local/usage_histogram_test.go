@@ -0,0 +1,57 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+//go:build kubeapiserver
+
+package local
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsageHistogramPercentile(t *testing.T) {
+	h := newUsageHistogram(1, 1e6, time.Hour)
+	assert.Equal(t, 0.0, h.percentile(0.9))
+
+	testTime := time.Now()
+	for i := 1; i <= 100; i++ {
+		h.addSample(float64(i*100), testTime)
+	}
+	assert.Equal(t, 100, h.sampleCount)
+
+	// Percentiles are the upper bound of their bucket, within 5% of the value
+	assert.InEpsilon(t, 5000, h.percentile(0.5), 0.05)
+	assert.GreaterOrEqual(t, h.percentile(0.5), 5000.0)
+	assert.InEpsilon(t, 9000, h.percentile(0.9), 0.05)
+	assert.InEpsilon(t, 10000, h.percentile(1), 0.05)
+
+	// Out of range values go to the first and last buckets
+	h.addSample(0.5, testTime)
+	h.addSample(1e9, testTime)
+	h.addSample(-1, testTime)
+	assert.Equal(t, 102, h.sampleCount)
+	assert.Equal(t, 1.0, h.percentile(0))
+	assert.InEpsilon(t, 1e6, h.percentile(1), 0.05)
+}
+
+func TestUsageHistogramDecay(t *testing.T) {
+	h := newUsageHistogram(1, 1e6, time.Hour)
+	testTime := time.Now()
+
+	// Recent low usage weighs more than old high usage
+	h.addSample(1000, testTime)
+	h.addSample(1000, testTime)
+	h.addSample(10, testTime.Add(2*time.Hour))
+	assert.InEpsilon(t, 10, h.percentile(0.6), 0.05)
+	assert.InEpsilon(t, 1000, h.percentile(0.7), 0.05)
+
+	// Weights are rescaled when they get too large, without changing the percentiles
+	h.addSample(10, testTime.Add(100*time.Hour))
+	assert.Equal(t, testTime.Add(100*time.Hour), h.referenceTime)
+	assert.InEpsilon(t, 10, h.percentile(0.99), 0.05)
+}
@@ -0,0 +1,435 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+//go:build kubeapiserver
+
+package local
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/clock"
+
+	datadoghqcommon "github.com/DataDog/datadog-operator/api/datadoghq/common"
+
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/autoscaling"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/autoscaling/workload"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/autoscaling/workload/loadstore"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/autoscaling/workload/model"
+	le "github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/leaderelection/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	oomKilledReason = "OOMKilled"
+	// oomMemoryRetention is how long the memory of a container killed for running out of memory is
+	// used as a floor for its memory requests
+	oomMemoryRetention = 24 * time.Hour
+
+	// The histograms cover usages from 1 millicore to 1000 cores and from 1MiB to 1TiB
+	cpuHistogramFirstBucket    = 1e6
+	cpuHistogramMaxValue       = 1e12
+	memoryHistogramFirstBucket = 1 << 20
+	memoryHistogramMaxValue    = 1 << 40
+
+	mebibyte = 1 << 20
+)
+
+// verticalCalculator computes container requests and limits from the usage history of the containers
+type verticalCalculator struct {
+	podWatcher workload.PodWatcher
+	clock      clock.Clock
+	settings   verticalRecommenderSettings
+
+	// usage holds the usage history of the targets, by autoscaler ID
+	usage map[string]*workloadUsage
+}
+
+// workloadUsage is the usage history of the containers of the target of an autoscaler
+type workloadUsage struct {
+	containers map[string]*containerUsage
+	// lastSamples holds the timestamp of the last value read from the loadstore for each series
+	lastSamples map[seriesKey]loadstore.Timestamp
+	// lastOOMKills holds the time of the last OOM kill observed for each pod container
+	lastOOMKills map[seriesKey]time.Time
+}
+
+// seriesKey identifies the values of a metric for a pod container
+type seriesKey struct {
+	metricName    string
+	podName       string
+	containerName string
+}
+
+// containerUsage is the usage history of a container, aggregated over all the pods of the target
+type containerUsage struct {
+	cpu    *usageHistogram
+	memory *usageHistogram
+	// oomMemory is the memory floor set after the last OOM kill, at oomTime
+	oomMemory float64
+	oomTime   time.Time
+}
+
+func newVerticalCalculator(clock clock.Clock, podWatcher workload.PodWatcher, settings verticalRecommenderSettings) *verticalCalculator {
+	return &verticalCalculator{
+		podWatcher: podWatcher,
+		clock:      clock,
+		settings:   settings,
+		usage:      make(map[string]*workloadUsage),
+	}
+}
+
+// calculateVerticalRecommendations is the entrypoint to calculate the vertical recommendation for a given DatadogPodAutoscaler
+func (c *verticalCalculator) calculateVerticalRecommendations(dpai model.PodAutoscalerInternal, lStore loadstore.Store) (*model.VerticalScalingValues, error) {
+	currentTime := c.clock.Now()
+
+	targetGVK, err := dpai.TargetGVK()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get GVK for target: %s, %s", dpai.ID(), err)
+	}
+	namespace := dpai.Namespace()
+	podOwnerName := dpai.Spec().TargetRef.Name
+	pods := c.podWatcher.GetPodsForOwner(workload.NamespacedPodOwner{
+		Namespace: namespace,
+		Name:      podOwnerName,
+		Kind:      targetGVK.Kind,
+	})
+	if len(pods) == 0 {
+		return nil, fmt.Errorf("No pods found for autoscaler: %s, gvk: %s, name: %s", dpai.ID(), targetGVK.String(), podOwnerName)
+	}
+
+	usage, found := c.usage[dpai.ID()]
+	if !found {
+		usage = &workloadUsage{
+			containers:   make(map[string]*containerUsage),
+			lastSamples:  make(map[seriesKey]loadstore.Timestamp),
+			lastOOMKills: make(map[seriesKey]time.Time),
+		}
+		c.usage[dpai.ID()] = usage
+	}
+	c.observeUsage(usage, lStore, namespace, podOwnerName, pods)
+	oomKilled := c.observeOOMKills(currentTime, usage, pods)
+
+	containerResources := currentContainerResources(pods)
+	containerNames := make([]string, 0, len(containerResources))
+	for name := range containerResources {
+		containerNames = append(containerNames, name)
+	}
+	slices.Sort(containerNames)
+
+	recommendation := &model.VerticalScalingValues{
+		Source:    datadoghqcommon.DatadogPodAutoscalerLocalValueSource,
+		Timestamp: currentTime,
+	}
+	for _, name := range containerNames {
+		constraints := containerConstraints(dpai.Spec().Constraints, name)
+		if constraints != nil && constraints.Enabled != nil && !*constraints.Enabled {
+			continue
+		}
+		history, found := usage.containers[name]
+		if !found {
+			continue
+		}
+
+		if resources, ok := c.recommendContainer(currentTime, name, history, containerResources[name], constraints); ok {
+			recommendation.ContainerResources = append(recommendation.ContainerResources, resources)
+		}
+	}
+
+	if len(recommendation.ContainerResources) == 0 {
+		return nil, fmt.Errorf("Not enough usage data to recommend resources for autoscaler: %s", dpai.ID())
+	}
+
+	// Small changes are not worth a rollout, we keep the current recommendation unless an OOM kill requires more memory
+	previous := dpai.FallbackScalingValues().Vertical
+	if !oomKilled && previous != nil && previous.Source == datadoghqcommon.DatadogPodAutoscalerLocalValueSource &&
+		withinChangeRatio(previous.ContainerResources, recommendation.ContainerResources, c.settings.minChangeRatio) {
+		recommendation.ContainerResources = previous.ContainerResources
+	}
+
+	recommendation.ResourcesHash, err = autoscaling.ObjectHash(recommendation.ContainerResources)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash container resources: %w", err)
+	}
+
+	for _, resources := range recommendation.ContainerResources {
+		for resourceName, quantity := range resources.Requests {
+			telemetryVerticalLocalRecommendations.Set(
+				quantity.AsApproximateFloat64(),
+				namespace,
+				podOwnerName,
+				dpai.Name(),
+				resources.Name,
+				string(resourceName),
+				string(recommendation.Source),
+				le.JoinLeaderValue,
+			)
+		}
+	}
+
+	return recommendation, nil
+}
+
+// observeUsage adds the values of the loadstore that haven't been read yet to the usage history
+func (c *verticalCalculator) observeUsage(usage *workloadUsage, lStore loadstore.Store, namespace, podOwnerName string, pods []*workloadmeta.KubernetesPod) {
+	currentPods := make(map[string]struct{}, len(pods))
+	for _, pod := range pods {
+		currentPods[pod.Name] = struct{}{}
+	}
+
+	lastSamples := make(map[seriesKey]loadstore.Timestamp, len(usage.lastSamples))
+	for _, metricName := range []string{containerCPUUsageMetricName, containerMemoryUsageMetricName} {
+		queryResult := lStore.GetMetricsRaw(metricName, namespace, podOwnerName, "")
+		for _, result := range queryResult.Results {
+			if _, found := currentPods[result.PodName]; !found {
+				continue
+			}
+
+			for containerName, series := range result.ContainerValues {
+				key := seriesKey{metricName: metricName, podName: result.PodName, containerName: containerName}
+				last := usage.lastSamples[key]
+				for _, value := range series {
+					if value.Timestamp <= usage.lastSamples[key] {
+						continue
+					}
+					c.containerUsage(usage, containerName).addSample(metricName, float64(value.Value), convertTimestampToTime(value.Timestamp))
+					last = max(last, value.Timestamp)
+				}
+				lastSamples[key] = last
+			}
+		}
+	}
+	// Only keep the series of current pods
+	usage.lastSamples = lastSamples
+}
+
+// observeOOMKills raises the memory floor of the containers that were OOM killed since the last call,
+// it returns whether any new OOM kill was observed
+func (c *verticalCalculator) observeOOMKills(currentTime time.Time, usage *workloadUsage, pods []*workloadmeta.KubernetesPod) bool {
+	oomKilled := false
+	lastOOMKills := make(map[seriesKey]time.Time, len(usage.lastOOMKills))
+
+	for _, pod := range pods {
+		for _, status := range pod.ContainerStatuses {
+			terminated := status.LastTerminationState.Terminated
+			if terminated == nil || terminated.Reason != oomKilledReason {
+				continue
+			}
+
+			key := seriesKey{metricName: containerMemoryUsageMetricName, podName: pod.Name, containerName: status.Name}
+			lastOOMKills[key] = terminated.FinishedAt
+			if !terminated.FinishedAt.After(usage.lastOOMKills[key]) || currentTime.Sub(terminated.FinishedAt) >= oomMemoryRetention {
+				continue
+			}
+
+			// The container used at least its limit, or its requests when it has no limit
+			memory := 0.0
+			for _, container := range pod.Containers {
+				if container.Name != status.Name {
+					continue
+				}
+				if container.Resources.MemoryLimit != nil {
+					memory = float64(*container.Resources.MemoryLimit)
+				} else if container.Resources.MemoryRequest != nil {
+					memory = float64(*container.Resources.MemoryRequest)
+				}
+			}
+			history := c.containerUsage(usage, status.Name)
+			memory = max(memory, history.memory.percentile(1))
+			if memory == 0 {
+				continue
+			}
+
+			log.Debugf("Container %s of pod %s/%s was OOM killed at %s, raising its memory floor", status.Name, pod.Namespace, pod.Name, terminated.FinishedAt)
+			if currentTime.Sub(history.oomTime) >= oomMemoryRetention {
+				history.oomMemory = 0
+			}
+			history.oomMemory = max(history.oomMemory, memory*c.settings.oomBumpRatio)
+			history.oomTime = terminated.FinishedAt
+			oomKilled = true
+		}
+	}
+
+	usage.lastOOMKills = lastOOMKills
+	return oomKilled
+}
+
+// recommendContainer computes the resources of a container from its usage history
+func (c *verticalCalculator) recommendContainer(
+	currentTime time.Time,
+	name string,
+	history *containerUsage,
+	current workloadmeta.ContainerResources,
+	constraints *datadoghqcommon.DatadogPodAutoscalerContainerConstraints,
+) (datadoghqcommon.DatadogPodAutoscalerContainerResources, bool) {
+	resources := datadoghqcommon.DatadogPodAutoscalerContainerResources{
+		Name:     name,
+		Requests: corev1.ResourceList{},
+		Limits:   corev1.ResourceList{},
+	}
+
+	if history.cpu.sampleCount >= c.settings.minSamples {
+		cpu := history.cpu.percentile(c.settings.cpuPercentile) * (1 + c.settings.headroom)
+		cpu = c.bound(cpu, corev1.ResourceCPU, constraints)
+		resources.Requests[corev1.ResourceCPU] = cpuQuantity(cpu)
+		if current.CPULimit != nil && current.CPURequest != nil && *current.CPURequest > 0 {
+			resources.Limits[corev1.ResourceCPU] = cpuQuantity(cpu * max(*current.CPULimit / *current.CPURequest, 1))
+		}
+	}
+
+	memory := 0.0
+	if history.memory.sampleCount >= c.settings.minSamples {
+		memory = history.memory.percentile(c.settings.memoryPercentile) * (1 + c.settings.headroom)
+	}
+	if history.oomMemory > 0 && currentTime.Sub(history.oomTime) < oomMemoryRetention {
+		memory = max(memory, history.oomMemory)
+	}
+	if memory > 0 {
+		memory = c.bound(memory, corev1.ResourceMemory, constraints)
+		resources.Requests[corev1.ResourceMemory] = memoryQuantity(memory)
+		if current.MemoryLimit != nil && current.MemoryRequest != nil && *current.MemoryRequest > 0 {
+			resources.Limits[corev1.ResourceMemory] = memoryQuantity(memory * max(float64(*current.MemoryLimit)/float64(*current.MemoryRequest), 1))
+		}
+	}
+
+	if len(resources.Requests) == 0 {
+		return resources, false
+	}
+	if len(resources.Limits) == 0 {
+		resources.Limits = nil
+	}
+	return resources, true
+}
+
+// bound applies the bounds of the settings, then the ones of the autoscaler constraints, to a value
+func (c *verticalCalculator) bound(value float64, resourceName corev1.ResourceName, constraints *datadoghqcommon.DatadogPodAutoscalerContainerConstraints) float64 {
+	unit := 1.0
+	switch resourceName {
+	case corev1.ResourceCPU:
+		value = min(max(value, c.settings.minCPU), c.settings.maxCPU)
+		unit = 1e9
+	case corev1.ResourceMemory:
+		value = min(max(value, c.settings.minMemory), c.settings.maxMemory)
+	}
+
+	if constraints == nil || constraints.Requests == nil {
+		return value
+	}
+	if minAllowed, found := constraints.Requests.MinAllowed[resourceName]; found {
+		value = max(value, minAllowed.AsApproximateFloat64()*unit)
+	}
+	if maxAllowed, found := constraints.Requests.MaxAllowed[resourceName]; found {
+		value = min(value, maxAllowed.AsApproximateFloat64()*unit)
+	}
+	return value
+}
+
+// containerUsage returns the usage history of a container, creating it if needed
+func (c *verticalCalculator) containerUsage(usage *workloadUsage, containerName string) *containerUsage {
+	history, found := usage.containers[containerName]
+	if !found {
+		history = &containerUsage{
+			cpu:    newUsageHistogram(cpuHistogramFirstBucket, cpuHistogramMaxValue, c.settings.halfLife),
+			memory: newUsageHistogram(memoryHistogramFirstBucket, memoryHistogramMaxValue, c.settings.halfLife),
+		}
+		usage.containers[containerName] = history
+	}
+	return history
+}
+
+// prune removes the usage history of the autoscalers which are not in the given set
+func (c *verticalCalculator) prune(autoscalerIDs map[string]struct{}) {
+	for id := range c.usage {
+		if _, found := autoscalerIDs[id]; !found {
+			delete(c.usage, id)
+		}
+	}
+}
+
+func (h *containerUsage) addSample(metricName string, value float64, timestamp time.Time) {
+	switch metricName {
+	case containerCPUUsageMetricName:
+		h.cpu.addSample(value, timestamp)
+	case containerMemoryUsageMetricName:
+		h.memory.addSample(value, timestamp)
+	}
+}
+
+// currentContainerResources returns the resources of the containers of the target, as set on its pods
+func currentContainerResources(pods []*workloadmeta.KubernetesPod) map[string]workloadmeta.ContainerResources {
+	resources := make(map[string]workloadmeta.ContainerResources)
+	for _, pod := range pods {
+		for _, container := range pod.Containers {
+			if _, found := resources[container.Name]; !found {
+				resources[container.Name] = container.Resources
+			}
+		}
+	}
+	return resources
+}
+
+// containerConstraints returns the constraints of the autoscaler for a container, if any
+func containerConstraints(constraints *datadoghqcommon.DatadogPodAutoscalerConstraints, containerName string) *datadoghqcommon.DatadogPodAutoscalerContainerConstraints {
+	if constraints == nil {
+		return nil
+	}
+
+	var wildcard *datadoghqcommon.DatadogPodAutoscalerContainerConstraints
+	for i := range constraints.Containers {
+		switch constraints.Containers[i].Name {
+		case containerName:
+			return &constraints.Containers[i]
+		case "*":
+			wildcard = &constraints.Containers[i]
+		}
+	}
+	return wildcard
+}
+
+// withinChangeRatio returns whether all the resources of two recommendations differ by less than the given ratio
+func withinChangeRatio(previous, current []datadoghqcommon.DatadogPodAutoscalerContainerResources, ratio float64) bool {
+	if len(previous) != len(current) {
+		return false
+	}
+
+	closeEnough := func(previous, current corev1.ResourceList) bool {
+		if len(previous) != len(current) {
+			return false
+		}
+		for resourceName, currentQuantity := range current {
+			previousQuantity, found := previous[resourceName]
+			if !found || previousQuantity.IsZero() {
+				return false
+			}
+			if math.Abs(currentQuantity.AsApproximateFloat64()/previousQuantity.AsApproximateFloat64()-1) > ratio {
+				return false
+			}
+		}
+		return true
+	}
+
+	for i := range current {
+		if previous[i].Name != current[i].Name || !closeEnough(previous[i].Requests, current[i].Requests) || !closeEnough(previous[i].Limits, current[i].Limits) {
+			return false
+		}
+	}
+	return true
+}
+
+// cpuQuantity converts nanocores to a quantity, rounded up to the millicore
+func cpuQuantity(nanocores float64) resource.Quantity {
+	return *resource.NewMilliQuantity(int64(math.Ceil(nanocores/1e6)), resource.DecimalSI)
+}
+
+// memoryQuantity converts bytes to a quantity, rounded up to the mebibyte
+func memoryQuantity(bytes float64) resource.Quantity {
+	return *resource.NewQuantity(int64(math.Ceil(bytes/mebibyte))*mebibyte, resource.BinarySI)
+}
@@ -0,0 +1,291 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+//go:build kubeapiserver
+
+package local
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	clock "k8s.io/utils/clock/testing"
+
+	datadoghqcommon "github.com/DataDog/datadog-operator/api/datadoghq/common"
+
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/autoscaling"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/autoscaling/workload"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/autoscaling/workload/loadstore"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/autoscaling/workload/model"
+	"github.com/DataDog/datadog-agent/pkg/util/pointer"
+)
+
+const mib = 1024 * 1024
+
+func newTestVerticalSettings() verticalRecommenderSettings {
+	return verticalRecommenderSettings{
+		cpuPercentile:    0.9,
+		memoryPercentile: 0.95,
+		headroom:         0.1,
+		halfLife:         24 * time.Hour,
+		minSamples:       2,
+		oomBumpRatio:     1.2,
+		minChangeRatio:   0.1,
+		minCPU:           1e7,
+		maxCPU:           4e9,
+		minMemory:        32 * mib,
+		maxMemory:        8192 * mib,
+	}
+}
+
+// newTestPodWatcher returns a pod watcher with a pod of test-deployment, whose container-name1 has
+// 250m/500m of CPU requests/limits and 128Mi/256Mi of memory requests/limits
+func newTestPodWatcher(statuses ...workloadmeta.KubernetesContainerStatus) workload.PodWatcher {
+	event := newFakeWLMPodEvent("default", "test-deployment", "pod1", []string{"container-name1"})
+	pod := event.Entity.(*workloadmeta.KubernetesPod)
+	pod.Containers[0].Resources = workloadmeta.ContainerResources{
+		CPURequest:    pointer.Ptr(25.0),
+		CPULimit:      pointer.Ptr(50.0),
+		MemoryRequest: pointer.Ptr(uint64(128 * mib)),
+		MemoryLimit:   pointer.Ptr(uint64(256 * mib)),
+	}
+	pod.ContainerStatuses = statuses
+
+	pw := workload.NewPodWatcher(nil, nil)
+	pw.HandleEvent(event)
+	return pw
+}
+
+// quantities returns the string representations of the quantities of a resource list
+func quantities(resources corev1.ResourceList) map[corev1.ResourceName]string {
+	res := make(map[corev1.ResourceName]string, len(resources))
+	for name, quantity := range resources {
+		res[name] = quantity.String()
+	}
+	return res
+}
+
+func addUsage(lStore loadstore.Store, timestamp int64, cpu, memory float64) {
+	lStore.SetEntitiesValues(map[*loadstore.Entity]*loadstore.EntityValue{
+		newEntity("container.cpu.usage", "default", "test-deployment", "pod1", "container-name1"):    newEntityValue(timestamp, cpu),
+		newEntity("container.memory.usage", "default", "test-deployment", "pod1", "container-name1"): newEntityValue(timestamp, memory),
+	})
+}
+
+func TestCalculateVerticalRecommendations(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	defer resetWorkloadMetricStore()
+
+	testTime := time.Now()
+	fakeClock := clock.NewFakeClock(testTime)
+	lStore := loadstore.GetWorkloadMetricStore(ctx)
+	calculator := newVerticalCalculator(fakeClock, newTestPodWatcher(), newTestVerticalSettings())
+	dpai := newAutoscaler(true)
+
+	// Not enough samples yet
+	addUsage(lStore, testTime.Unix()-30, 2e8, 100*mib)
+	_, err := calculator.calculateVerticalRecommendations(dpai, lStore)
+	assert.EqualError(t, err, "Not enough usage data to recommend resources for autoscaler: default/autoscaler1")
+
+	// Values already read are not counted twice
+	_, err = calculator.calculateVerticalRecommendations(dpai, lStore)
+	assert.Error(t, err)
+
+	addUsage(lStore, testTime.Unix()-15, 2e8, 100*mib)
+	recommendation, err := calculator.calculateVerticalRecommendations(dpai, lStore)
+	require.NoError(t, err)
+	assert.Equal(t, datadoghqcommon.DatadogPodAutoscalerLocalValueSource, recommendation.Source)
+	assert.Equal(t, testTime, recommendation.Timestamp)
+	assert.NotEmpty(t, recommendation.ResourcesHash)
+	// The limits keep the ratio of the current resources, the memory is rounded up to the MiB
+	require.Len(t, recommendation.ContainerResources, 1)
+	assert.Equal(t, "container-name1", recommendation.ContainerResources[0].Name)
+	assert.Equal(t, map[corev1.ResourceName]string{corev1.ResourceCPU: "225m", corev1.ResourceMemory: "114Mi"}, quantities(recommendation.ContainerResources[0].Requests))
+	assert.Equal(t, map[corev1.ResourceName]string{corev1.ResourceCPU: "449m", corev1.ResourceMemory: "228Mi"}, quantities(recommendation.ContainerResources[0].Limits))
+
+	// A small change keeps the previous recommendation
+	dpai.UpdateFromLocalValues(model.ScalingValues{Vertical: recommendation})
+	fakeClock.Step(30 * time.Second)
+	addUsage(lStore, testTime.Unix()+15, 2.1e8, 101*mib)
+	newRecommendation, err := calculator.calculateVerticalRecommendations(dpai, lStore)
+	require.NoError(t, err)
+	assert.Equal(t, recommendation.ContainerResources, newRecommendation.ContainerResources)
+	assert.Equal(t, recommendation.ResourcesHash, newRecommendation.ResourcesHash)
+	assert.Equal(t, testTime.Add(30*time.Second), newRecommendation.Timestamp)
+
+	// A large change produces a new recommendation
+	fakeClock.Step(30 * time.Second)
+	for i := int64(1); i <= 10; i++ {
+		addUsage(lStore, testTime.Unix()+30+i, 1e9, 400*mib)
+		_, err = calculator.calculateVerticalRecommendations(dpai, lStore)
+		require.NoError(t, err)
+	}
+	newRecommendation, err = calculator.calculateVerticalRecommendations(dpai, lStore)
+	require.NoError(t, err)
+	assert.NotEqual(t, recommendation.ResourcesHash, newRecommendation.ResourcesHash)
+	assert.True(t, newRecommendation.ContainerResources[0].Requests.Cpu().Cmp(resource.MustParse("1")) > 0)
+}
+
+func TestCalculateVerticalRecommendationsOOMKill(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	defer resetWorkloadMetricStore()
+
+	testTime := time.Now()
+	lStore := loadstore.GetWorkloadMetricStore(ctx)
+	pw := newTestPodWatcher(workloadmeta.KubernetesContainerStatus{
+		Name:         "container-name1",
+		RestartCount: 1,
+		LastTerminationState: workloadmeta.KubernetesContainerState{
+			Terminated: &workloadmeta.KubernetesContainerStateTerminated{
+				Reason:     "OOMKilled",
+				ExitCode:   137,
+				FinishedAt: testTime.Add(-time.Minute),
+			},
+		},
+	})
+	calculator := newVerticalCalculator(clock.NewFakeClock(testTime), pw, newTestVerticalSettings())
+	dpai := newAutoscaler(true)
+
+	// Even without enough usage data, the memory is raised above the limit that was reached
+	recommendation, err := calculator.calculateVerticalRecommendations(dpai, lStore)
+	require.NoError(t, err)
+	require.Len(t, recommendation.ContainerResources, 1)
+	assert.Equal(t, map[corev1.ResourceName]string{corev1.ResourceMemory: "308Mi"}, quantities(recommendation.ContainerResources[0].Requests))
+	assert.Equal(t, map[corev1.ResourceName]string{corev1.ResourceMemory: "615Mi"}, quantities(recommendation.ContainerResources[0].Limits))
+
+	// The memory floor is kept while the usage stays low
+	addUsage(lStore, testTime.Unix()-30, 2e8, 100*mib)
+	addUsage(lStore, testTime.Unix()-15, 2e8, 100*mib)
+	recommendation, err = calculator.calculateVerticalRecommendations(dpai, lStore)
+	require.NoError(t, err)
+	assert.Equal(t, map[corev1.ResourceName]string{corev1.ResourceCPU: "225m", corev1.ResourceMemory: "308Mi"}, quantities(recommendation.ContainerResources[0].Requests))
+}
+
+func TestCalculateVerticalRecommendationsConstraints(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	defer resetWorkloadMetricStore()
+
+	testTime := time.Now()
+	lStore := loadstore.GetWorkloadMetricStore(ctx)
+	addUsage(lStore, testTime.Unix()-30, 2e8, 10*mib)
+	addUsage(lStore, testTime.Unix()-15, 2e8, 10*mib)
+
+	tests := []struct {
+		name        string
+		constraints *datadoghqcommon.DatadogPodAutoscalerConstraints
+		expected    map[corev1.ResourceName]string
+		expectedErr string
+	}{
+		{
+			name:     "global bounds",
+			expected: map[corev1.ResourceName]string{corev1.ResourceCPU: "225m", corev1.ResourceMemory: "32Mi"},
+		},
+		{
+			name: "container constraints",
+			constraints: &datadoghqcommon.DatadogPodAutoscalerConstraints{
+				Containers: []datadoghqcommon.DatadogPodAutoscalerContainerConstraints{
+					{
+						Name: "container-name1",
+						Requests: &datadoghqcommon.DatadogPodAutoscalerContainerResourceConstraints{
+							MinAllowed: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("64Mi")},
+							MaxAllowed: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+						},
+					},
+				},
+			},
+			expected: map[corev1.ResourceName]string{corev1.ResourceCPU: "100m", corev1.ResourceMemory: "64Mi"},
+		},
+		{
+			name: "wildcard constraints",
+			constraints: &datadoghqcommon.DatadogPodAutoscalerConstraints{
+				Containers: []datadoghqcommon.DatadogPodAutoscalerContainerConstraints{
+					{
+						Name: "*",
+						Requests: &datadoghqcommon.DatadogPodAutoscalerContainerResourceConstraints{
+							MinAllowed: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+						},
+					},
+				},
+			},
+			expected: map[corev1.ResourceName]string{corev1.ResourceCPU: "1", corev1.ResourceMemory: "32Mi"},
+		},
+		{
+			name: "disabled container",
+			constraints: &datadoghqcommon.DatadogPodAutoscalerConstraints{
+				Containers: []datadoghqcommon.DatadogPodAutoscalerContainerConstraints{
+					{Name: "container-name1", Enabled: pointer.Ptr(false)},
+				},
+			},
+			expectedErr: "Not enough usage data to recommend resources for autoscaler: default/autoscaler1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calculator := newVerticalCalculator(clock.NewFakeClock(testTime), newTestPodWatcher(), newTestVerticalSettings())
+			fakeAutoscaler := newAutoscaler(true)
+			fakeAutoscaler.Spec().Constraints = tt.constraints
+
+			recommendation, err := calculator.calculateVerticalRecommendations(fakeAutoscaler, lStore)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, quantities(recommendation.ContainerResources[0].Requests))
+		})
+	}
+}
+
+func TestProcessVerticalRecommendations(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	defer resetWorkloadMetricStore()
+
+	testTime := time.Now()
+	pw := newTestPodWatcher()
+
+	store := autoscaling.NewStore[model.PodAutoscalerInternal]()
+	fakeAutoscaler := newAutoscaler(true)
+	fakeAutoscaler.Spec().Fallback.Horizontal.Enabled = false
+	store.Set("default/autoscaler1", fakeAutoscaler, "")
+
+	lStore := loadstore.GetWorkloadMetricStore(ctx)
+	addUsage(lStore, testTime.Unix()-30, 2e8, 100*mib)
+	addUsage(lStore, testTime.Unix()-15, 2e8, 100*mib)
+
+	recommender := NewRecommender(clock.NewFakeClock(testTime), pw, store)
+	recommender.verticalCalculator = newVerticalCalculator(clock.NewFakeClock(testTime), pw, newTestVerticalSettings())
+	recommender.process(ctx)
+
+	// Autoscalers without horizontal fallback still get vertical recommendations
+	pai, found := store.Get("default/autoscaler1")
+	assert.True(t, found)
+	assert.Nil(t, pai.FallbackScalingValues().Horizontal)
+	assert.NoError(t, pai.FallbackScalingValues().VerticalError)
+	assert.NotNil(t, pai.FallbackScalingValues().Vertical)
+	assert.Contains(t, recommender.verticalCalculator.usage, "default/autoscaler1")
+
+	// The previous recommendation is kept on errors
+	pw.HandleEvent(workloadmeta.Event{Type: workloadmeta.EventTypeUnset, Entity: newFakeWLMPodEvent("default", "test-deployment", "pod1", nil).Entity})
+	recommender.process(ctx)
+	pai, _ = store.Get("default/autoscaler1")
+	assert.Error(t, pai.FallbackScalingValues().VerticalError)
+	assert.NotNil(t, pai.FallbackScalingValues().Vertical)
+
+	// The usage history of deleted autoscalers is dropped
+	store.Delete("default/autoscaler1", "")
+	recommender.process(ctx)
+	assert.Empty(t, recommender.verticalCalculator.usage)
+}
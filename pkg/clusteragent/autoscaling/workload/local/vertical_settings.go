@@ -0,0 +1,98 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+//go:build kubeapiserver
+
+package local
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	pkgconfigmodel "github.com/DataDog/datadog-agent/pkg/config/model"
+)
+
+const verticalConfigPrefix = "autoscaling.workload.local_vertical."
+
+// verticalRecommenderSettings holds the settings of the local vertical recommender
+type verticalRecommenderSettings struct {
+	// cpuPercentile and memoryPercentile are the usage percentiles (between 0 and 1) used as requests
+	cpuPercentile    float64
+	memoryPercentile float64
+	// headroom is the ratio added on top of the usage percentiles
+	headroom float64
+	// halfLife is the time after which a usage sample loses half of its weight
+	halfLife time.Duration
+	// minSamples is the number of samples required before recommending requests for a resource
+	minSamples int
+	// oomBumpRatio is applied to the memory of a container killed for running out of memory
+	oomBumpRatio float64
+	// minChangeRatio is the relative change of requests below which the current recommendation is kept
+	minChangeRatio float64
+	// the bounds of the recommended requests, in nanocores and bytes
+	minCPU    float64
+	maxCPU    float64
+	minMemory float64
+	maxMemory float64
+}
+
+// newVerticalRecommenderSettings reads the settings of the local vertical recommender from the configuration
+func newVerticalRecommenderSettings(cfg pkgconfigmodel.Reader) (verticalRecommenderSettings, error) {
+	settings := verticalRecommenderSettings{
+		cpuPercentile:    cfg.GetFloat64(verticalConfigPrefix + "cpu_percentile"),
+		memoryPercentile: cfg.GetFloat64(verticalConfigPrefix + "memory_percentile"),
+		headroom:         cfg.GetFloat64(verticalConfigPrefix + "headroom"),
+		halfLife:         cfg.GetDuration(verticalConfigPrefix + "history_half_life"),
+		minSamples:       cfg.GetInt(verticalConfigPrefix + "min_samples"),
+		oomBumpRatio:     cfg.GetFloat64(verticalConfigPrefix + "oom_bump_ratio"),
+		minChangeRatio:   cfg.GetFloat64(verticalConfigPrefix + "min_change_ratio"),
+	}
+
+	for name, percentile := range map[string]float64{"cpu_percentile": settings.cpuPercentile, "memory_percentile": settings.memoryPercentile} {
+		if percentile <= 0 || percentile > 1 {
+			return settings, fmt.Errorf("%s%s must be in (0, 1], got: %v", verticalConfigPrefix, name, percentile)
+		}
+	}
+	if settings.headroom < 0 {
+		return settings, fmt.Errorf("%sheadroom must be positive, got: %v", verticalConfigPrefix, settings.headroom)
+	}
+	if settings.halfLife <= 0 {
+		return settings, fmt.Errorf("%shistory_half_life must be positive, got: %s", verticalConfigPrefix, settings.halfLife)
+	}
+	if settings.oomBumpRatio < 1 {
+		return settings, fmt.Errorf("%soom_bump_ratio must be at least 1, got: %v", verticalConfigPrefix, settings.oomBumpRatio)
+	}
+	if settings.minChangeRatio < 0 {
+		return settings, fmt.Errorf("%smin_change_ratio must be positive, got: %v", verticalConfigPrefix, settings.minChangeRatio)
+	}
+
+	bounds := []struct {
+		key   string
+		value *float64
+		unit  float64
+	}{
+		{"min_cpu", &settings.minCPU, 1e9},
+		{"max_cpu", &settings.maxCPU, 1e9},
+		{"min_memory", &settings.minMemory, 1},
+		{"max_memory", &settings.maxMemory, 1},
+	}
+	for _, bound := range bounds {
+		quantity, err := resource.ParseQuantity(cfg.GetString(verticalConfigPrefix + bound.key))
+		if err != nil {
+			return settings, fmt.Errorf("invalid %s%s: %w", verticalConfigPrefix, bound.key, err)
+		}
+		*bound.value = quantity.AsApproximateFloat64() * bound.unit
+	}
+	if settings.minCPU <= 0 || settings.maxCPU < settings.minCPU {
+		return settings, fmt.Errorf("%smin_cpu must be positive and lower than %smax_cpu", verticalConfigPrefix, verticalConfigPrefix)
+	}
+	if settings.minMemory <= 0 || settings.maxMemory < settings.minMemory {
+		return settings, fmt.Errorf("%smin_memory must be positive and lower than %smax_memory", verticalConfigPrefix, verticalConfigPrefix)
+	}
+
+	return settings, nil
+}
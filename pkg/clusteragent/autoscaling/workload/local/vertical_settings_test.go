@@ -0,0 +1,57 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+//go:build kubeapiserver
+
+package local
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
+)
+
+func TestNewVerticalRecommenderSettings(t *testing.T) {
+	cfg := configmock.New(t)
+	settings, err := newVerticalRecommenderSettings(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, verticalRecommenderSettings{
+		cpuPercentile:    0.9,
+		memoryPercentile: 0.95,
+		headroom:         0.15,
+		halfLife:         24 * time.Hour,
+		minSamples:       60,
+		oomBumpRatio:     1.2,
+		minChangeRatio:   0.1,
+		minCPU:           1e7,
+		maxCPU:           16e9,
+		minMemory:        32 * mib,
+		maxMemory:        64 * 1024 * mib,
+	}, settings)
+
+	tests := []struct {
+		key         string
+		value       interface{}
+		expectedErr string
+	}{
+		{"cpu_percentile", 1.5, "autoscaling.workload.local_vertical.cpu_percentile must be in (0, 1], got: 1.5"},
+		{"headroom", -0.1, "autoscaling.workload.local_vertical.headroom must be positive, got: -0.1"},
+		{"oom_bump_ratio", 0.5, "autoscaling.workload.local_vertical.oom_bump_ratio must be at least 1, got: 0.5"},
+		{"max_cpu", "5m", "autoscaling.workload.local_vertical.min_cpu must be positive and lower than autoscaling.workload.local_vertical.max_cpu"},
+		{"min_memory", "lots", "invalid autoscaling.workload.local_vertical.min_memory: quantities must match the regular expression '^([+-]?[0-9.]+)([eEinumkKMGTP]*[-+]?[0-9]*)$'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			cfg := configmock.New(t)
+			cfg.SetWithoutSource(verticalConfigPrefix+tt.key, tt.value)
+			_, err := newVerticalRecommenderSettings(cfg)
+			assert.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
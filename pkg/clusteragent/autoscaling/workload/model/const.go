@@ -16,6 +16,8 @@ const (
 	RecommendationAppliedEventGeneratedAnnotation = "autoscaling.datadoghq.com/event"
 	// RolloutTimestampAnnotation is the annotation key used to store the rollout timestamp
 	RolloutTimestampAnnotation = "autoscaling.datadoghq.com/rolloutAt"
+	// DryRunAnnotation is the annotation key used to only compute scaling actions without applying them
+	DryRunAnnotation = "autoscaling.datadoghq.com/dry-run"
 
 	// RecommendationAppliedEventReason is the event reason when a recommendation is applied
 	RecommendationAppliedEventReason = "RecommendationApplied"
@@ -27,4 +29,8 @@ const (
 	SuccessfulTriggerRolloutEventReason = "SuccessfulTriggerRollout"
 	// FailedTriggerRolloutEventReason is the event reason when a trigger rollout fails
 	FailedTriggerRolloutEventReason = "FailedTriggerRollout"
+	// DryRunScaleEventReason is the event reason when a scale operation is skipped due to dry run
+	DryRunScaleEventReason = "DryRunScale"
+	// DryRunTriggerRolloutEventReason is the event reason when a trigger rollout is skipped due to dry run
+	DryRunTriggerRolloutEventReason = "DryRunTriggerRollout"
 )
@@ -12,6 +12,7 @@ import (
 	"errors"
 	"fmt"
 	"slices"
+	"strconv"
 	"time"
 
 	datadoghqcommon "github.com/DataDog/datadog-operator/api/datadoghq/common"
@@ -94,6 +95,12 @@ type PodAutoscalerInternal struct {
 	// verticalLastActionError is the last error encountered on vertical scaling
 	verticalLastActionError error
 
+	// horizontalDryRunAction is the last horizontal action that would have been taken without dry run
+	horizontalDryRunAction *datadoghqcommon.DatadogPodAutoscalerHorizontalAction
+
+	// verticalDryRunAction is the last vertical action that would have been taken without dry run
+	verticalDryRunAction *datadoghqcommon.DatadogPodAutoscalerVerticalAction
+
 	// currentReplicas is the current number of PODs for the targetRef
 	currentReplicas *int32
 
@@ -123,6 +130,10 @@ type PodAutoscalerInternal struct {
 	// customRecommenderConfiguration holds the configuration for custom recommenders,
 	// Parsed from annotations on the autoscaler
 	customRecommenderConfiguration *RecommenderConfiguration
+
+	// dryRun flags the PodAutoscaler as only computing scaling actions, without applying them
+	// Parsed from annotations on the autoscaler
+	dryRun bool
 }
 
 // NewPodAutoscalerInternal creates a new PodAutoscalerInternal from a Kubernetes CR
@@ -164,6 +175,8 @@ func (p *PodAutoscalerInternal) UpdateFromPodAutoscaler(podAutoscaler *datadoghq
 	p.horizontalEventsRetention, p.horizontalRecommendationsRetention = getHorizontalRetentionValues(podAutoscaler.Spec.ApplyPolicy)
 	// Compute recommender configuration again in case .Annotations has changed
 	p.updateCustomRecommenderConfiguration(podAutoscaler.Annotations)
+	// Compute dry run mode again in case .Annotations has changed
+	p.UpdateDryRun(podAutoscaler.Annotations)
 }
 
 // UpdateFromSettings updates the PodAutoscalerInternal from a new settings
@@ -277,6 +290,32 @@ func (p *PodAutoscalerInternal) UpdateFromVerticalAction(action *datadoghqcommon
 	}
 }
 
+// UpdateDryRun updates the dry run mode from the annotations of the PodAutoscaler.
+// The current mode is kept if the annotation is invalid.
+func (p *PodAutoscalerInternal) UpdateDryRun(annotations map[string]string) {
+	dryRun, err := parseDryRunAnnotation(annotations)
+	if err != nil {
+		p.error = err
+		return
+	}
+	// Dry run actions are meaningless once actions are applied
+	if !dryRun {
+		p.horizontalDryRunAction = nil
+		p.verticalDryRunAction = nil
+	}
+	p.dryRun = dryRun
+}
+
+// UpdateFromHorizontalDryRunAction updates the PodAutoscalerInternal from a horizontal action skipped due to dry run
+func (p *PodAutoscalerInternal) UpdateFromHorizontalDryRunAction(action *datadoghqcommon.DatadogPodAutoscalerHorizontalAction) {
+	p.horizontalDryRunAction = action
+}
+
+// UpdateFromVerticalDryRunAction updates the PodAutoscalerInternal from a vertical action skipped due to dry run
+func (p *PodAutoscalerInternal) UpdateFromVerticalDryRunAction(action *datadoghqcommon.DatadogPodAutoscalerVerticalAction) {
+	p.verticalDryRunAction = action
+}
+
 // SetGeneration sets the generation of the PodAutoscaler
 func (p *PodAutoscalerInternal) SetGeneration(generation int64) {
 	p.generation = generation
@@ -451,6 +490,21 @@ func (p *PodAutoscalerInternal) VerticalLastActionError() error {
 	return p.verticalLastActionError
 }
 
+// HorizontalDryRunAction returns the last horizontal action that would have been taken without dry run
+func (p *PodAutoscalerInternal) HorizontalDryRunAction() *datadoghqcommon.DatadogPodAutoscalerHorizontalAction {
+	return p.horizontalDryRunAction
+}
+
+// VerticalDryRunAction returns the last vertical action that would have been taken without dry run
+func (p *PodAutoscalerInternal) VerticalDryRunAction() *datadoghqcommon.DatadogPodAutoscalerVerticalAction {
+	return p.verticalDryRunAction
+}
+
+// DryRun returns true if scaling actions should only be computed, not applied
+func (p *PodAutoscalerInternal) DryRun() bool {
+	return p.dryRun
+}
+
 // CurrentReplicas returns the current number of PODs for the targetRef
 func (p *PodAutoscalerInternal) CurrentReplicas() *int32 {
 	return p.currentReplicas
@@ -783,3 +837,17 @@ func parseCustomConfigurationAnnotation(annotations map[string]string) (*Recomme
 
 	return &customConfiguration, nil
 }
+
+func parseDryRunAnnotation(annotations map[string]string) (bool, error) {
+	annotation, ok := annotations[DryRunAnnotation]
+	if !ok { // No annotation set
+		return false, nil
+	}
+
+	dryRun, err := strconv.ParseBool(annotation)
+	if err != nil {
+		return false, fmt.Errorf("Failed to parse annotation %s: %v", DryRunAnnotation, err)
+	}
+
+	return dryRun, nil
+}
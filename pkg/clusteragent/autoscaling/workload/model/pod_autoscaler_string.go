@@ -41,6 +41,9 @@ func (p *PodAutoscalerInternal) String(verbose bool) string {
 		_, _ = fmt.Fprintln(&sb, "----------- PodAutoscaler Spec -----------")
 		_, _ = fmt.Fprintln(&sb, "Target Ref:", p.Spec().TargetRef)
 		_, _ = fmt.Fprintln(&sb, "Owner:", p.Spec().Owner)
+		if p.DryRun() {
+			_, _ = fmt.Fprintln(&sb, "Dry Run:", p.DryRun())
+		}
 		if p.Spec().RemoteVersion != nil {
 			_, _ = fmt.Fprintln(&sb, "Remote Version:", *p.Spec().RemoteVersion)
 		}
@@ -103,6 +106,12 @@ func (p *PodAutoscalerInternal) String(verbose bool) string {
 	if p.VerticalLastAction() != nil {
 		_, _ = fmt.Fprintln(&sb, "Vertical Last Action:", formatVerticalAction(p.VerticalLastAction()))
 	}
+	if p.HorizontalDryRunAction() != nil {
+		_, _ = fmt.Fprintln(&sb, "Horizontal Dry Run Action:", formatHorizontalAction(p.HorizontalDryRunAction()))
+	}
+	if p.VerticalDryRunAction() != nil {
+		_, _ = fmt.Fprintln(&sb, "Vertical Dry Run Action:", formatVerticalAction(p.VerticalDryRunAction()))
+	}
 	_, _ = fmt.Fprintln(&sb)
 
 	if p.CustomRecommenderConfiguration() != nil {
@@ -324,6 +333,8 @@ func (p *PodAutoscalerInternal) MarshalJSON() ([]byte, error) {
 		"horizontal_last_action_error":             errorToString(p.horizontalLastActionError),
 		"vertical_last_action":                     p.verticalLastAction,
 		"vertical_last_action_error":               errorToString(p.verticalLastActionError),
+		"horizontal_dry_run_action":                p.horizontalDryRunAction,
+		"vertical_dry_run_action":                  p.verticalDryRunAction,
 		"current_replicas":                         p.currentReplicas,
 		"scaled_replicas":                          p.scaledReplicas,
 		"error":                                    errorToString(p.error),
@@ -332,6 +343,7 @@ func (p *PodAutoscalerInternal) MarshalJSON() ([]byte, error) {
 		"horizontal_events_retention":              p.horizontalEventsRetention,
 		"horizontal_recommendations_retention":     p.horizontalRecommendationsRetention,
 		"custom_recommender_configuration":         p.customRecommenderConfiguration,
+		"dry_run":                                  p.dryRun,
 	})
 }
 
@@ -363,6 +375,8 @@ func (p *PodAutoscalerInternal) UnmarshalJSON(data []byte) error {
 		HorizontalLastActionError            interface{}                                                    `json:"horizontal_last_action_error"`
 		VerticalLastAction                   *datadoghqcommon.DatadogPodAutoscalerVerticalAction            `json:"vertical_last_action"`
 		VerticalLastActionError              interface{}                                                    `json:"vertical_last_action_error"`
+		HorizontalDryRunAction               *datadoghqcommon.DatadogPodAutoscalerHorizontalAction          `json:"horizontal_dry_run_action"`
+		VerticalDryRunAction                 *datadoghqcommon.DatadogPodAutoscalerVerticalAction            `json:"vertical_dry_run_action"`
 		CurrentReplicas                      *int32                                                         `json:"current_replicas"`
 		ScaledReplicas                       *int32                                                         `json:"scaled_replicas"`
 		Error                                interface{}                                                    `json:"error"`
@@ -371,6 +385,7 @@ func (p *PodAutoscalerInternal) UnmarshalJSON(data []byte) error {
 		HorizontalEventsRetention            time.Duration                                                  `json:"horizontal_events_retention"`
 		HorizontalRecommendationsRetention   time.Duration                                                  `json:"horizontal_recommendations_retention"`
 		CustomRecommenderConfiguration       *RecommenderConfiguration                                      `json:"custom_recommender_configuration"`
+		DryRun                               bool                                                           `json:"dry_run"`
 	}
 
 	if err := json.Unmarshal(data, &temp); err != nil {
@@ -397,10 +412,13 @@ func (p *PodAutoscalerInternal) UnmarshalJSON(data []byte) error {
 	p.horizontalLastActionError = stringToError(temp.HorizontalLastActionError)
 	p.verticalLastAction = temp.VerticalLastAction
 	p.verticalLastActionError = stringToError(temp.VerticalLastActionError)
+	p.horizontalDryRunAction = temp.HorizontalDryRunAction
+	p.verticalDryRunAction = temp.VerticalDryRunAction
 	p.targetGVK = temp.TargetGVK
 	p.horizontalEventsRetention = temp.HorizontalEventsRetention
 	p.horizontalRecommendationsRetention = temp.HorizontalRecommendationsRetention
 	p.customRecommenderConfiguration = temp.CustomRecommenderConfiguration
+	p.dryRun = temp.DryRun
 	p.error = stringToError(temp.Error)
 	p.scalingValues = temp.ScalingValues
 	p.scalingValues.Error = stringToError(temp.ScalingValuesError)
@@ -422,6 +440,12 @@ func (p *PodAutoscalerInternal) UnmarshalJSON(data []byte) error {
 	if p.verticalLastAction != nil {
 		p.verticalLastAction.Time.Time = p.verticalLastAction.Time.Time.UTC()
 	}
+	if p.horizontalDryRunAction != nil {
+		p.horizontalDryRunAction.Time.Time = p.horizontalDryRunAction.Time.Time.UTC()
+	}
+	if p.verticalDryRunAction != nil {
+		p.verticalDryRunAction.Time.Time = p.verticalDryRunAction.Time.Time.UTC()
+	}
 	for i := range p.horizontalLastRecommendations {
 		p.horizontalLastRecommendations[i].GeneratedAt.Time = p.horizontalLastRecommendations[i].GeneratedAt.Time.UTC()
 	}
@@ -391,6 +391,49 @@ func TestParseCustomConfigurationAnnotation(t *testing.T) {
 	}
 }
 
+func TestParseDryRunAnnotation(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		expected    bool
+		err         string
+	}{
+		{
+			name:        "Empty annotations",
+			annotations: map[string]string{},
+			expected:    false,
+		},
+		{
+			name:        "Dry run enabled",
+			annotations: map[string]string{DryRunAnnotation: "true"},
+			expected:    true,
+		},
+		{
+			name:        "Dry run disabled",
+			annotations: map[string]string{DryRunAnnotation: "false"},
+			expected:    false,
+		},
+		{
+			name:        "Invalid annotation",
+			annotations: map[string]string{DryRunAnnotation: "maybe"},
+			expected:    false,
+			err:         "Failed to parse annotation autoscaling.datadoghq.com/dry-run: strconv.ParseBool: parsing \"maybe\": invalid syntax",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dryRun, err := parseDryRunAnnotation(tt.annotations)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+			assert.Equal(t, tt.expected, dryRun)
+		})
+	}
+}
+
 func TestUpdateFromStatus(t *testing.T) {
 	now := time.Now()
 	earlierActionTime := now.Add(-10 * time.Minute)
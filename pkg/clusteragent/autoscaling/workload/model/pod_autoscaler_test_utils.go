@@ -42,12 +42,15 @@ type FakePodAutoscalerInternal struct {
 	HorizontalRecommendationsRetention time.Duration
 	VerticalLastAction                 *datadoghqcommon.DatadogPodAutoscalerVerticalAction
 	VerticalLastActionError            error
+	HorizontalDryRunAction             *datadoghqcommon.DatadogPodAutoscalerHorizontalAction
+	VerticalDryRunAction               *datadoghqcommon.DatadogPodAutoscalerVerticalAction
 	CurrentReplicas                    *int32
 	ScaledReplicas                     *int32
 	Error                              error
 	Deleted                            bool
 	TargetGVK                          schema.GroupVersionKind
 	CustomRecommenderConfiguration     *RecommenderConfiguration
+	DryRun                             bool
 }
 
 // Build creates a PodAutoscalerInternal object from the FakePodAutoscalerInternal.
@@ -70,12 +73,15 @@ func (f FakePodAutoscalerInternal) Build() PodAutoscalerInternal {
 		horizontalRecommendationsRetention: f.HorizontalRecommendationsRetention,
 		verticalLastAction:                 f.VerticalLastAction,
 		verticalLastActionError:            f.VerticalLastActionError,
+		horizontalDryRunAction:             f.HorizontalDryRunAction,
+		verticalDryRunAction:               f.VerticalDryRunAction,
 		currentReplicas:                    f.CurrentReplicas,
 		scaledReplicas:                     f.ScaledReplicas,
 		error:                              f.Error,
 		deleted:                            f.Deleted,
 		targetGVK:                          f.TargetGVK,
 		customRecommenderConfiguration:     f.CustomRecommenderConfiguration,
+		dryRun:                             f.DryRun,
 	}
 }
 
@@ -67,6 +67,10 @@ func (pa podPatcher) ApplyRecommendations(pod *corev1.Pod) (bool, error) {
 		// This POD is not managed by an autoscaler
 		return false, nil
 	}
+	if autoscaler.DryRun() {
+		log.Debugf("Autoscaler %s is in dry run mode, not patching POD %s/%s", autoscaler.ID(), pod.Namespace, pod.Name)
+		return false, nil
+	}
 
 	// We're always adding annotation to Pods when a matching Autoscaler is found even if we do not have recommendations ATM
 	patched := false
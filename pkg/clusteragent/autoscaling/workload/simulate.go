@@ -0,0 +1,417 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+//go:build kubeapiserver
+
+package workload
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	datadoghqcommon "github.com/DataDog/datadog-operator/api/datadoghq/common"
+
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/autoscaling/workload/loadstore"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/autoscaling/workload/model"
+	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
+)
+
+const (
+	// DefaultSimulationDuration is the default duration over which scaling decisions are simulated
+	DefaultSimulationDuration = 30 * time.Minute
+	// DefaultSimulationStep is the default interval between two simulated decisions
+	DefaultSimulationStep = 30 * time.Second
+
+	// maxSimulationSteps caps the number of steps of a simulation
+	maxSimulationSteps = 1000
+)
+
+var simulationUsageMetrics = []string{"container.cpu.usage", "container.memory.usage"}
+
+// SimulationParams holds the parameters of a simulation
+type SimulationParams struct {
+	Duration time.Duration
+	Step     time.Duration
+}
+
+// Simulation holds the scaling decisions the controllers would take over time for an autoscaler
+type Simulation struct {
+	Autoscaler string            `json:"autoscaler"`
+	DryRun     bool              `json:"dry_run"`
+	Start      time.Time         `json:"start"`
+	Usage      []SimulationUsage `json:"usage,omitempty"`
+	Steps      []SimulationStep  `json:"steps"`
+}
+
+// SimulationUsage is the average usage of a container across the target pods, as found in the local metrics store
+type SimulationUsage struct {
+	Metric    string    `json:"metric"`
+	Container string    `json:"container"`
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+	Pods      int       `json:"pods"`
+}
+
+// SimulationStep holds the scaling decisions taken at a given time
+type SimulationStep struct {
+	Time       time.Time                  `json:"time"`
+	Horizontal *SimulatedHorizontalAction `json:"horizontal,omitempty"`
+	Vertical   *SimulatedVerticalAction   `json:"vertical,omitempty"`
+}
+
+// SimulatedHorizontalAction is a horizontal scaling decision
+type SimulatedHorizontalAction struct {
+	Source              string `json:"source"`
+	RecommendedReplicas int32  `json:"recommended_replicas"`
+	FromReplicas        int32  `json:"from_replicas"`
+	ToReplicas          int32  `json:"to_replicas"`
+	LimitedReason       string `json:"limited_reason,omitempty"`
+	Error               string `json:"error,omitempty"`
+}
+
+// SimulatedVerticalAction is a vertical scaling decision
+type SimulatedVerticalAction struct {
+	Source           string `json:"source"`
+	Version          string `json:"version"`
+	RolloutTriggered bool   `json:"rollout_triggered"`
+	Reason           string `json:"reason,omitempty"`
+}
+
+// Simulate replays the scaling decisions of an autoscaler from the store over time, without applying them.
+// Recommendations are kept at their current values for every step, the local recommender is not run again,
+// so that the simulation shows how stabilization windows, scaling rules, constraints and staleness of
+// recommendations impact the actions taken. Usage is only reported when the local metrics store is enabled
+// (`autoscaling.failover.enabled`).
+func Simulate(ctx context.Context, id string, params SimulationParams) (*Simulation, error) {
+	if !pkgconfigsetup.Datadog().GetBool("autoscaling.workload.enabled") {
+		return nil, errors.New("autoscaling is disabled")
+	}
+
+	if defaultDumper.store == nil {
+		return nil, errors.New("autoscaling store is not initialized")
+	}
+
+	podAutoscaler, found := defaultDumper.store.Get(id)
+	if !found {
+		return nil, fmt.Errorf("autoscaler %s not found", id)
+	}
+
+	// Working on a deep copy as the simulation modifies the autoscaler internal state
+	autoscalerCopy, err := copyPodAutoscaler(&podAutoscaler)
+	if err != nil {
+		return nil, err
+	}
+
+	simulation, err := simulate(autoscalerCopy, time.Now(), params)
+	if err != nil {
+		return nil, err
+	}
+
+	if pkgconfigsetup.Datadog().GetBool("autoscaling.failover.enabled") && autoscalerCopy.Spec() != nil {
+		simulation.Usage = getSimulationUsage(loadstore.GetWorkloadMetricStore(ctx), autoscalerCopy.Namespace(), autoscalerCopy.Spec().TargetRef.Name)
+	}
+
+	return simulation, nil
+}
+
+func simulate(podAutoscaler *model.PodAutoscalerInternal, start time.Time, params SimulationParams) (*Simulation, error) {
+	if podAutoscaler.Spec() == nil {
+		return nil, fmt.Errorf("autoscaler %s has no spec", podAutoscaler.ID())
+	}
+	if params.Step <= 0 || params.Duration < 0 {
+		return nil, fmt.Errorf("invalid simulation parameters, step: %s, duration: %s", params.Step, params.Duration)
+	}
+	if params.Duration/params.Step > maxSimulationSteps {
+		return nil, fmt.Errorf("simulation would have more than %d steps, increase the step or reduce the duration", maxSimulationSteps)
+	}
+
+	simulator := simulator{
+		clock:           &simulationClock{now: start},
+		podAutoscaler:   podAutoscaler,
+		currentReplicas: podAutoscaler.CurrentReplicas(),
+	}
+	simulator.horizontalController = &horizontalController{clock: simulator.clock}
+	if podAutoscaler.ScaledReplicas() != nil {
+		simulator.scaledReplicas = *podAutoscaler.ScaledReplicas()
+	}
+	if podAutoscaler.ScalingValues().Vertical != nil {
+		simulator.verticalVersion = podAutoscaler.ScalingValues().Vertical.ResourcesHash
+	}
+
+	simulation := &Simulation{
+		Autoscaler: podAutoscaler.ID(),
+		DryRun:     podAutoscaler.DryRun(),
+		Start:      start,
+	}
+	for currentTime := start; !currentTime.After(start.Add(params.Duration)); currentTime = currentTime.Add(params.Step) {
+		simulation.Steps = append(simulation.Steps, simulator.step(currentTime))
+	}
+
+	return simulation, nil
+}
+
+// simulator holds the state of the target between two simulated steps
+type simulator struct {
+	clock                *simulationClock
+	horizontalController *horizontalController
+	podAutoscaler        *model.PodAutoscalerInternal
+
+	// currentReplicas is the number of replicas of the target, nil if unknown
+	currentReplicas *int32
+	// scaledReplicas is the number of replicas using the vertical recommendation
+	scaledReplicas int32
+	// verticalVersion is the version of the vertical recommendation used by scaledReplicas
+	verticalVersion string
+}
+
+func (s *simulator) step(currentTime time.Time) SimulationStep {
+	s.clock.now = currentTime
+
+	horizontalSource, verticalSource := getActiveScalingSources(currentTime, s.podAutoscaler)
+	s.podAutoscaler.SetActiveScalingValues(currentTime, horizontalSource, verticalSource)
+
+	return SimulationStep{
+		Time:       currentTime,
+		Horizontal: s.horizontalStep(),
+		Vertical:   s.verticalStep(currentTime),
+	}
+}
+
+// horizontalStep mimics horizontalController.performScaling, assuming the scale subresource is updated right away
+func (s *simulator) horizontalStep() *SimulatedHorizontalAction {
+	scalingValues := s.podAutoscaler.ScalingValues()
+	if scalingValues.Horizontal == nil {
+		return nil
+	}
+
+	var currentReplicas int32
+	if s.currentReplicas != nil {
+		currentReplicas = *s.currentReplicas
+	}
+	result := &SimulatedHorizontalAction{
+		Source:              string(scalingValues.Horizontal.Source),
+		RecommendedReplicas: scalingValues.Horizontal.Replicas,
+		FromReplicas:        currentReplicas,
+		ToReplicas:          currentReplicas,
+	}
+
+	minReplicas, maxReplicas := getReplicasConstraints(s.podAutoscaler.Spec())
+	action, _, err := s.horizontalController.computeScaleAction(s.podAutoscaler, scalingValues.Horizontal.Source, currentReplicas, scalingValues.Horizontal.Replicas, minReplicas, maxReplicas)
+	if err != nil {
+		result.Error = err.Error()
+		s.podAutoscaler.UpdateFromHorizontalAction(nil, err)
+		return result
+	}
+
+	result.ToReplicas = action.ToReplicas
+	if action.LimitedReason != nil {
+		result.LimitedReason = *action.LimitedReason
+	}
+	s.podAutoscaler.UpdateFromHorizontalAction(action, nil)
+
+	// New pods are created with the vertical recommendation, removed pods may have any
+	if action.ToReplicas > currentReplicas {
+		s.scaledReplicas += action.ToReplicas - currentReplicas
+	}
+	s.scaledReplicas = min(s.scaledReplicas, action.ToReplicas)
+	s.currentReplicas = &action.ToReplicas
+	return result
+}
+
+// verticalStep mimics verticalController.sync, sharing its checks, assuming rollouts complete before the next step
+func (s *simulator) verticalStep(currentTime time.Time) *SimulatedVerticalAction {
+	scalingValues := s.podAutoscaler.ScalingValues()
+	if scalingValues.Vertical == nil || scalingValues.Vertical.ResourcesHash == "" {
+		return nil
+	}
+
+	result := &SimulatedVerticalAction{
+		Source:  string(scalingValues.Vertical.Source),
+		Version: scalingValues.Vertical.ResourcesHash,
+	}
+	// A new recommendation is not used by any pod yet
+	if s.verticalVersion != scalingValues.Vertical.ResourcesHash {
+		s.verticalVersion = scalingValues.Vertical.ResourcesHash
+		s.scaledReplicas = 0
+	}
+
+	targetGVK, err := s.podAutoscaler.TargetGVK()
+	if err != nil {
+		result.Reason = err.Error()
+		return result
+	}
+
+	var currentReplicas int32
+	if s.currentReplicas != nil {
+		currentReplicas = *s.currentReplicas
+	}
+	// rollouts complete before the next step, so that pods never have several owners
+	rollout, reason := checkVerticalRollout(s.podAutoscaler, targetGVK.Kind, currentTime, currentReplicas, min(s.scaledReplicas, currentReplicas), 1)
+	if rollout != verticalRolloutNeeded {
+		result.Reason = reason
+		return result
+	}
+
+	result.RolloutTriggered = true
+	s.podAutoscaler.UpdateFromVerticalAction(&datadoghqcommon.DatadogPodAutoscalerVerticalAction{
+		Time:    metav1.NewTime(currentTime),
+		Version: scalingValues.Vertical.ResourcesHash,
+		Type:    datadoghqcommon.DatadogPodAutoscalerRolloutTriggeredVerticalActionType,
+	}, nil)
+	s.scaledReplicas = currentReplicas
+	return result
+}
+
+// simulationClock is a clock.PassiveClock returning the time of the simulated step
+type simulationClock struct {
+	now time.Time
+}
+
+// Now returns the time of the simulated step
+func (c *simulationClock) Now() time.Time {
+	return c.now
+}
+
+// Since returns the time elapsed since t at the simulated step
+func (c *simulationClock) Since(t time.Time) time.Duration {
+	return c.now.Sub(t)
+}
+
+// getSimulationUsage returns the usage history of the target containers, averaged across pods
+func getSimulationUsage(lStore loadstore.Store, namespace, podOwnerName string) []SimulationUsage {
+	type usageKey struct {
+		metric    string
+		container string
+		timestamp loadstore.Timestamp
+	}
+
+	var usage []SimulationUsage
+	for _, metricName := range simulationUsageMetrics {
+		sums := make(map[usageKey]float64)
+		counts := make(map[usageKey]int)
+		for _, podResult := range lStore.GetMetricsRaw(metricName, namespace, podOwnerName, "").Results {
+			for containerName, values := range podResult.ContainerValues {
+				for _, value := range values {
+					key := usageKey{metric: metricName, container: containerName, timestamp: value.Timestamp}
+					sums[key] += float64(value.Value)
+					counts[key]++
+				}
+			}
+		}
+
+		for key, sum := range sums {
+			usage = append(usage, SimulationUsage{
+				Metric:    key.metric,
+				Container: key.container,
+				Timestamp: time.Unix(int64(key.timestamp), 0).UTC(),
+				Value:     sum / float64(counts[key]),
+				Pods:      counts[key],
+			})
+		}
+	}
+
+	slices.SortFunc(usage, func(a, b SimulationUsage) int {
+		if c := strings.Compare(a.Metric, b.Metric); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Container, b.Container); c != 0 {
+			return c
+		}
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+	return usage
+}
+
+func copyPodAutoscaler(podAutoscaler *model.PodAutoscalerInternal) (*model.PodAutoscalerInternal, error) {
+	content, err := json.Marshal(podAutoscaler)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy autoscaler %s: %w", podAutoscaler.ID(), err)
+	}
+
+	autoscalerCopy := &model.PodAutoscalerInternal{}
+	if err := json.Unmarshal(content, autoscalerCopy); err != nil {
+		return nil, fmt.Errorf("failed to copy autoscaler %s: %w", podAutoscaler.ID(), err)
+	}
+	return autoscalerCopy, nil
+}
+
+// Print writes the simulation to a given writer in a human-readable format
+func (s *Simulation) Print(writer io.Writer) {
+	if s == nil {
+		return
+	}
+
+	if writer != color.Output {
+		color.NoColor = true
+	}
+
+	fmt.Fprintf(writer, "\n=== Simulation of PodAutoscaler %s ===\n", color.GreenString(s.Autoscaler))
+	fmt.Fprintln(writer, "Start:", s.Start.Format(time.RFC3339))
+	if s.DryRun {
+		fmt.Fprintln(writer, "Dry Run: true")
+	}
+
+	if len(s.Usage) > 0 {
+		fmt.Fprintln(writer, "\n----------- Observed Usage (average per pod) -----------")
+		for _, usage := range s.Usage {
+			fmt.Fprintf(writer, "%s %s container: %s, value: %.0f, pods: %d\n", usage.Timestamp.Format(time.RFC3339), usage.Metric, usage.Container, usage.Value, usage.Pods)
+		}
+	}
+
+	fmt.Fprintln(writer, "\n----------- Simulated Actions -----------")
+	for _, step := range s.Steps {
+		// Only printing steps with a decision to avoid flooding the output
+		horizontalChanged := step.Horizontal != nil && (step.Horizontal.FromReplicas != step.Horizontal.ToReplicas || step.Horizontal.LimitedReason != "" || step.Horizontal.Error != "")
+		verticalChanged := step.Vertical != nil && step.Vertical.RolloutTriggered
+		if !horizontalChanged && !verticalChanged {
+			continue
+		}
+
+		offset := step.Time.Sub(s.Start)
+		if horizontalChanged {
+			h := step.Horizontal
+			fmt.Fprintf(writer, "[+%s] horizontal (%s): recommended %d replicas, %d -> %d", offset, h.Source, h.RecommendedReplicas, h.FromReplicas, h.ToReplicas)
+			if h.LimitedReason != "" {
+				fmt.Fprintf(writer, ", limited: %s", color.YellowString(h.LimitedReason))
+			}
+			if h.Error != "" {
+				fmt.Fprintf(writer, ", not scaling: %s", color.RedString(h.Error))
+			}
+			fmt.Fprintln(writer)
+		}
+		if verticalChanged {
+			fmt.Fprintf(writer, "[+%s] vertical (%s): rollout triggered for version %s\n", offset, step.Vertical.Source, step.Vertical.Version)
+		}
+	}
+
+	if len(s.Steps) > 0 {
+		last := s.Steps[len(s.Steps)-1]
+		fmt.Fprintf(writer, "\n----------- Final State (+%s) -----------\n", last.Time.Sub(s.Start))
+		if last.Horizontal != nil {
+			fmt.Fprintf(writer, "Replicas: %d (recommended: %d, source: %s)\n", last.Horizontal.ToReplicas, last.Horizontal.RecommendedReplicas, last.Horizontal.Source)
+		} else {
+			fmt.Fprintln(writer, "Replicas: no horizontal recommendation")
+		}
+		if last.Vertical != nil {
+			fmt.Fprintf(writer, "Resources version: %s (source: %s)", last.Vertical.Version, last.Vertical.Source)
+			if last.Vertical.Reason != "" {
+				fmt.Fprintf(writer, ", %s", last.Vertical.Reason)
+			}
+			fmt.Fprintln(writer)
+		} else {
+			fmt.Fprintln(writer, "Resources: no vertical recommendation")
+		}
+	}
+	fmt.Fprintln(writer, "===")
+}
@@ -0,0 +1,39 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+//go:build !kubeapiserver
+
+package workload
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+const (
+	// DefaultSimulationDuration is the default duration over which scaling decisions are simulated
+	DefaultSimulationDuration = 30 * time.Minute
+	// DefaultSimulationStep is the default interval between two simulated decisions
+	DefaultSimulationStep = 30 * time.Second
+)
+
+// SimulationParams is an empty placeholder struct
+type SimulationParams struct {
+	Duration time.Duration
+	Step     time.Duration
+}
+
+// Simulation is an empty placeholder struct
+type Simulation struct{}
+
+// Simulate is a noop function that returns an error
+func Simulate(context.Context, string, SimulationParams) (*Simulation, error) {
+	return nil, errors.New("autoscaling is not supported on this build")
+}
+
+// Print is a noop function that does nothing
+func (*Simulation) Print(io.Writer) {}
@@ -0,0 +1,227 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+//go:build kubeapiserver
+
+package workload
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	datadoghqcommon "github.com/DataDog/datadog-operator/api/datadoghq/common"
+	datadoghq "github.com/DataDog/datadog-operator/api/datadoghq/v1alpha2"
+
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/autoscaling/workload/model"
+	"github.com/DataDog/datadog-agent/pkg/util/pointer"
+)
+
+func TestSimulate(t *testing.T) {
+	testTime := time.Now()
+	autoscaler := model.FakePodAutoscalerInternal{
+		Namespace: "default",
+		Name:      "dpa-0",
+		Spec: &datadoghq.DatadogPodAutoscalerSpec{
+			TargetRef: autoscalingv2.CrossVersionObjectReference{
+				Kind:       "Deployment",
+				Name:       "app-0",
+				APIVersion: "apps/v1",
+			},
+			Owner: datadoghqcommon.DatadogPodAutoscalerLocalOwner,
+			ApplyPolicy: &datadoghq.DatadogPodAutoscalerApplyPolicy{
+				Mode: datadoghq.DatadogPodAutoscalerApplyModeApply,
+				ScaleUp: &datadoghqcommon.DatadogPodAutoscalerScalingPolicy{
+					Rules: []datadoghqcommon.DatadogPodAutoscalerScalingRule{
+						{
+							Type:          datadoghqcommon.DatadogPodAutoscalerPodsScalingRuleType,
+							Value:         2,
+							PeriodSeconds: 60,
+						},
+					},
+				},
+			},
+		},
+		MainScalingValues: model.ScalingValues{
+			Horizontal: &model.HorizontalScalingValues{
+				Source:    datadoghqcommon.DatadogPodAutoscalerAutoscalingValueSource,
+				Timestamp: testTime,
+				Replicas:  8,
+			},
+			Vertical: &model.VerticalScalingValues{
+				Source:        datadoghqcommon.DatadogPodAutoscalerAutoscalingValueSource,
+				Timestamp:     testTime,
+				ResourcesHash: "version-1",
+			},
+		},
+		HorizontalEventsRetention: time.Minute,
+		CurrentReplicas:           pointer.Ptr[int32](4),
+		ScaledReplicas:            pointer.Ptr[int32](0),
+		DryRun:                    true,
+	}.Build()
+
+	simulation, err := simulate(&autoscaler, testTime, SimulationParams{Duration: 2 * time.Minute, Step: 30 * time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, "default/dpa-0", simulation.Autoscaler)
+	assert.True(t, simulation.DryRun)
+	require.Len(t, simulation.Steps, 5)
+
+	// Scale up is limited by the scaling rule, rollout is triggered
+	assert.Equal(t, &SimulatedHorizontalAction{
+		Source:              "Autoscaling",
+		RecommendedReplicas: 8,
+		FromReplicas:        4,
+		ToReplicas:          6,
+		LimitedReason:       "desired replica count limited to 6 (originally 8) due to scaling policy",
+	}, simulation.Steps[0].Horizontal)
+	assert.Equal(t, &SimulatedVerticalAction{Source: "Autoscaling", Version: "version-1", RolloutTriggered: true}, simulation.Steps[0].Vertical)
+
+	// Scaling rule period has not expired yet
+	assert.Equal(t, int32(6), simulation.Steps[1].Horizontal.ToReplicas)
+	assert.Equal(t, "desired replica count limited to 6 (originally 8) due to scaling policy", simulation.Steps[1].Horizontal.LimitedReason)
+	assert.Equal(t, "last rollout was triggered less than 2m0s ago", simulation.Steps[1].Vertical.Reason)
+
+	// Scaling rule period has expired, reaching the recommendation
+	assert.Equal(t, &SimulatedHorizontalAction{
+		Source:              "Autoscaling",
+		RecommendedReplicas: 8,
+		FromReplicas:        6,
+		ToReplicas:          8,
+	}, simulation.Steps[2].Horizontal)
+
+	// New pods are created with the recommended resources
+	assert.Equal(t, int32(8), simulation.Steps[4].Horizontal.ToReplicas)
+	assert.Equal(t, &SimulatedVerticalAction{Source: "Autoscaling", Version: "version-1", Reason: "all pods already use the recommended resources"}, simulation.Steps[4].Vertical)
+
+	var buf bytes.Buffer
+	simulation.Print(&buf)
+	assert.Contains(t, buf.String(), "[+0s] horizontal (Autoscaling): recommended 8 replicas, 4 -> 6, limited: desired replica count limited to 6 (originally 8) due to scaling policy\n")
+	assert.Contains(t, buf.String(), "[+0s] vertical (Autoscaling): rollout triggered for version version-1\n")
+	assert.Contains(t, buf.String(), "[+1m0s] horizontal (Autoscaling): recommended 8 replicas, 6 -> 8\n")
+	assert.Contains(t, buf.String(), "Replicas: 8 (recommended: 8, source: Autoscaling)\n")
+}
+
+func TestSimulateInvalidParams(t *testing.T) {
+	autoscaler := model.FakePodAutoscalerInternal{
+		Namespace: "default",
+		Name:      "dpa-0",
+		Spec:      &datadoghq.DatadogPodAutoscalerSpec{},
+	}.Build()
+
+	_, err := simulate(&autoscaler, time.Now(), SimulationParams{Duration: time.Minute})
+	assert.EqualError(t, err, "invalid simulation parameters, step: 0s, duration: 1m0s")
+
+	_, err = simulate(&autoscaler, time.Now(), SimulationParams{Duration: 24 * time.Hour, Step: time.Second})
+	assert.EqualError(t, err, "simulation would have more than 1000 steps, increase the step or reduce the duration")
+}
+
+func TestCheckVerticalRollout(t *testing.T) {
+	testTime := time.Now()
+	build := func(lastAction *datadoghqcommon.DatadogPodAutoscalerVerticalAction) *model.PodAutoscalerInternal {
+		autoscaler := model.FakePodAutoscalerInternal{
+			Namespace: "default",
+			Name:      "dpa-0",
+			Spec:      &datadoghq.DatadogPodAutoscalerSpec{},
+			MainScalingValues: model.ScalingValues{
+				Vertical: &model.VerticalScalingValues{
+					Source:        datadoghqcommon.DatadogPodAutoscalerAutoscalingValueSource,
+					Timestamp:     testTime,
+					ResourcesHash: "version-1",
+				},
+			},
+			VerticalLastAction: lastAction,
+		}.Build()
+		return &autoscaler
+	}
+
+	tests := []struct {
+		name                   string
+		autoscaler             *model.PodAutoscalerInternal
+		targetKind             string
+		pods                   int32
+		podsWithRecommendation int32
+		directOwners           int
+		expected               verticalRollout
+		expectedReason         string
+	}{
+		{
+			name:           "no pods",
+			autoscaler:     build(nil),
+			targetKind:     "Deployment",
+			expected:       verticalRolloutNoPods,
+			expectedReason: "no pods found for target",
+		},
+		{
+			name: "disabled",
+			autoscaler: func() *model.PodAutoscalerInternal {
+				autoscaler := model.FakePodAutoscalerInternal{Namespace: "default", Name: "dpa-0", Spec: &datadoghq.DatadogPodAutoscalerSpec{}}.Build()
+				return &autoscaler
+			}(),
+			targetKind:     "Deployment",
+			pods:           2,
+			directOwners:   1,
+			expected:       verticalRolloutDisabled,
+			expectedReason: "no scaling values available",
+		},
+		{
+			name:           "recent rollout",
+			autoscaler:     build(&datadoghqcommon.DatadogPodAutoscalerVerticalAction{Time: metav1.NewTime(testTime.Add(-time.Minute)), Version: "version-0"}),
+			targetKind:     "Deployment",
+			pods:           2,
+			directOwners:   1,
+			expected:       verticalRolloutPending,
+			expectedReason: "last rollout was triggered less than 2m0s ago",
+		},
+		{
+			name:           "unsupported kind",
+			autoscaler:     build(nil),
+			targetKind:     "StatefulSet",
+			pods:           2,
+			directOwners:   1,
+			expected:       verticalRolloutUnsupported,
+			expectedReason: "automic rollout not available for target Kind: StatefulSet. Applying to existing PODs require manual trigger",
+		},
+		{
+			name:                   "up to date",
+			autoscaler:             build(nil),
+			targetKind:             "Deployment",
+			pods:                   2,
+			podsWithRecommendation: 2,
+			directOwners:           1,
+			expected:               verticalRolloutUpToDate,
+			expectedReason:         "all pods already use the recommended resources",
+		},
+		{
+			name:           "ongoing",
+			autoscaler:     build(nil),
+			targetKind:     "Deployment",
+			pods:           2,
+			directOwners:   2,
+			expected:       verticalRolloutOngoing,
+			expectedReason: "rollout already ongoing",
+		},
+		{
+			name:         "needed",
+			autoscaler:   build(&datadoghqcommon.DatadogPodAutoscalerVerticalAction{Time: metav1.NewTime(testTime.Add(-time.Hour)), Version: "version-0"}),
+			targetKind:   "Rollout",
+			pods:         2,
+			directOwners: 1,
+			expected:     verticalRolloutNeeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, reason := checkVerticalRollout(tt.autoscaler, tt.targetKind, testTime, tt.pods, tt.podsWithRecommendation, tt.directOwners)
+			assert.Equal(t, tt.expected, result)
+			assert.Equal(t, tt.expectedReason, reason)
+		})
+	}
+}
@@ -7,6 +7,7 @@
 package check
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -77,6 +78,14 @@ type Info interface {
 	InstanceConfig() string
 }
 
+// RunContextSetter is implemented by the checks which can abort a run. The worker sets the
+// context of each run before calling Run, cancels it when the run exceeds its timeout and
+// resets it with a nil context once the run is complete.
+type RunContextSetter interface {
+	// SetRunContext sets the context of the next run
+	SetRunContext(ctx context.Context)
+}
+
 // ErrSkipCheckInstance is returned from Configure() when a check is intentionally refusing to load a
 // check instance, and NOT due to an error. The distinction is important for deciding whether or not
 // to log the error and report it on the status page.
@@ -0,0 +1,195 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package fixtures records the responses received by checks from the systems they monitor,
+// and replays them to run checks offline.
+//
+// Interactions are identified by a kind (e.g. "http", "snmp" or "sql") and a request key. The
+// responses recorded for a request are replayed in order, the last one being repeated once
+// they are exhausted.
+package fixtures
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// interactionsFile is the name of the file holding the interactions in a fixtures directory
+	interactionsFile = "interactions.json"
+	// formatVersion is the version of the format of the interactions file
+	formatVersion = 1
+)
+
+// Mode is the mode of a fixtures store
+type Mode int
+
+const (
+	// Record records the responses received by the checks
+	Record Mode = iota + 1
+	// Replay replays the recorded responses instead of reaching the monitored systems
+	Replay
+)
+
+// ErrNotRecorded is returned when replaying a request which wasn't recorded
+var ErrNotRecorded = errors.New("no recorded response")
+
+var current atomic.Pointer[Store]
+
+// SetCurrent sets the store used by the checks. A nil store disables recording and replay.
+func SetCurrent(s *Store) {
+	current.Store(s)
+}
+
+// Current returns the store used by the checks, or nil if recording and replay are disabled.
+func Current() *Store {
+	return current.Load()
+}
+
+// Store holds the interactions of a fixtures directory
+type Store struct {
+	dir  string
+	mode Mode
+
+	mu           sync.Mutex
+	interactions map[string]map[string][]json.RawMessage // kind -> key -> responses
+	cursors      map[string]int
+	missing      map[string]struct{}
+}
+
+// interactionsFormat is the format of the interactions file
+type interactionsFormat struct {
+	Version      int                                     `json:"version"`
+	Interactions map[string]map[string][]json.RawMessage `json:"interactions"`
+}
+
+// NewRecorder returns a store recording interactions into dir, which is created if needed.
+// The interactions are written by Save.
+func NewRecorder(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("unable to create the fixtures directory: %w", err)
+	}
+	return &Store{
+		dir:          dir,
+		mode:         Record,
+		interactions: map[string]map[string][]json.RawMessage{},
+	}, nil
+}
+
+// Load returns a store replaying the interactions recorded in dir
+func Load(dir string) (*Store, error) {
+	content, err := os.ReadFile(filepath.Join(dir, interactionsFile))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the recorded interactions: %w", err)
+	}
+	var f interactionsFormat
+	if err := json.Unmarshal(content, &f); err != nil {
+		return nil, fmt.Errorf("invalid interactions file: %w", err)
+	}
+	if f.Version != formatVersion {
+		return nil, fmt.Errorf("unsupported interactions file version %d", f.Version)
+	}
+	if f.Interactions == nil {
+		f.Interactions = map[string]map[string][]json.RawMessage{}
+	}
+	return &Store{
+		dir:          dir,
+		mode:         Replay,
+		interactions: f.Interactions,
+		cursors:      map[string]int{},
+		missing:      map[string]struct{}{},
+	}, nil
+}
+
+// Mode returns the mode of the store
+func (s *Store) Mode() Mode {
+	return s.mode
+}
+
+// Dir returns the fixtures directory
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Record records a response to the request identified by kind and key
+func (s *Store) Record(kind, key string, response any) error {
+	raw, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("unable to encode the %s response to %s: %w", kind, key, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.interactions[kind] == nil {
+		s.interactions[kind] = map[string][]json.RawMessage{}
+	}
+	s.interactions[kind][key] = append(s.interactions[kind][key], raw)
+	return nil
+}
+
+// Replay decodes into response the next recorded response to the request identified by kind
+// and key. It returns ErrNotRecorded if no response was recorded for the request.
+func (s *Store) Replay(kind, key string, response any) error {
+	s.mu.Lock()
+	responses := s.interactions[kind][key]
+	if len(responses) == 0 {
+		s.missing[kind+" "+key] = struct{}{}
+		s.mu.Unlock()
+		return fmt.Errorf("%w for %s %s", ErrNotRecorded, kind, key)
+	}
+	cursorKey := kind + " " + key
+	i := s.cursors[cursorKey]
+	if i < len(responses)-1 {
+		s.cursors[cursorKey] = i + 1
+	}
+	raw := responses[i]
+	s.mu.Unlock()
+
+	return json.Unmarshal(raw, response)
+}
+
+// Keys returns the keys of the requests of the given kind which have recorded responses
+func (s *Store) Keys(kind string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.interactions[kind]))
+	for key := range s.interactions[kind] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Missing returns the requests which were replayed without a recorded response
+func (s *Store) Missing() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	missing := make([]string, 0, len(s.missing))
+	for request := range s.missing {
+		missing = append(missing, request)
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// Save writes the recorded interactions to the fixtures directory
+func (s *Store) Save() error {
+	s.mu.Lock()
+	content, err := json.MarshalIndent(interactionsFormat{
+		Version:      formatVersion,
+		Interactions: s.interactions,
+	}, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(s.dir, interactionsFile), content, 0o600)
+}
@@ -0,0 +1,59 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package fixtures
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordReplay(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "fixtures")
+
+	recorder, err := NewRecorder(dir)
+	require.NoError(t, err)
+	assert.Equal(t, Record, recorder.Mode())
+	require.NoError(t, recorder.Record("http", "GET /status", "first"))
+	require.NoError(t, recorder.Record("http", "GET /status", "second"))
+	require.NoError(t, recorder.Record("snmp", "10.0.0.1:161 get 1.3.6.1.2.1.1.2.0", map[string]int{"value": 1}))
+	require.NoError(t, recorder.Save())
+
+	store, err := Load(dir)
+	require.NoError(t, err)
+	assert.Equal(t, Replay, store.Mode())
+
+	var resp string
+	for _, expected := range []string{"first", "second", "second"} {
+		require.NoError(t, store.Replay("http", "GET /status", &resp))
+		assert.Equal(t, expected, resp)
+	}
+
+	var snmpResp map[string]int
+	require.NoError(t, store.Replay("snmp", "10.0.0.1:161 get 1.3.6.1.2.1.1.2.0", &snmpResp))
+	assert.Equal(t, map[string]int{"value": 1}, snmpResp)
+
+	assert.ErrorIs(t, store.Replay("http", "GET /other", &resp), ErrNotRecorded)
+	assert.ErrorIs(t, store.Replay("snmp", "GET /status", &resp), ErrNotRecorded)
+	assert.Equal(t, []string{"http GET /other", "snmp GET /status"}, store.Missing())
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	_, err := Load(dir)
+	assert.ErrorContains(t, err, "unable to read the recorded interactions")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, interactionsFile), []byte(`{"version": 2}`), 0o600))
+	_, err = Load(dir)
+	assert.ErrorContains(t, err, "unsupported interactions file version 2")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, interactionsFile), []byte(`{`), 0o600))
+	_, err = Load(dir)
+	assert.ErrorContains(t, err, "invalid interactions file")
+}
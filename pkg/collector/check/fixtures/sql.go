@@ -0,0 +1,441 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package fixtures
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// sqlKind is the kind of the SQL interactions
+	sqlKind = "sql"
+	// sqlDriverSuffix is appended to the name of a database/sql driver to name its wrapper
+	sqlDriverSuffix = "+fixtures"
+)
+
+// sqlDrivers holds the names of the registered driver wrappers
+var sqlDrivers sync.Map
+
+// SQLDriver returns the name of the database/sql driver the checks should open instead of the
+// driver name. When recording or replaying, it is a wrapper recording the results of the
+// queries, or replaying them without connecting to the database. Otherwise, name is returned.
+func SQLDriver(name string) string {
+	if Current() == nil {
+		return name
+	}
+	wrapper := name + sqlDriverSuffix
+	if _, registered := sqlDrivers.LoadOrStore(wrapper, struct{}{}); !registered {
+		sql.Register(wrapper, &sqlDriver{name: name})
+	}
+	return wrapper
+}
+
+// sqlResponse is the recorded result of a SQL query or statement
+type sqlResponse struct {
+	Columns      []string     `json:"columns,omitempty"`
+	Rows         [][]sqlValue `json:"rows,omitempty"`
+	RowsAffected int64        `json:"rows_affected,omitempty"`
+	Error        string       `json:"error,omitempty"`
+}
+
+// sqlValue is a driver.Value along with its type, which JSON doesn't preserve
+type sqlValue struct {
+	Type  string `json:"type"`
+	Value string `json:"value,omitempty"`
+}
+
+func encodeSQLValue(v driver.Value) sqlValue {
+	switch v := v.(type) {
+	case nil:
+		return sqlValue{Type: "null"}
+	case int64:
+		return sqlValue{Type: "int64", Value: strconv.FormatInt(v, 10)}
+	case float64:
+		return sqlValue{Type: "float64", Value: strconv.FormatFloat(v, 'g', -1, 64)}
+	case bool:
+		return sqlValue{Type: "bool", Value: strconv.FormatBool(v)}
+	case []byte:
+		return sqlValue{Type: "bytes", Value: base64.StdEncoding.EncodeToString(v)}
+	case string:
+		return sqlValue{Type: "string", Value: v}
+	case time.Time:
+		return sqlValue{Type: "time", Value: v.Format(time.RFC3339Nano)}
+	default:
+		// the values of types specific to a driver are replayed as strings
+		return sqlValue{Type: "string", Value: fmt.Sprint(v)}
+	}
+}
+
+func (v sqlValue) decode() (driver.Value, error) {
+	switch v.Type {
+	case "null":
+		return nil, nil
+	case "int64":
+		return strconv.ParseInt(v.Value, 10, 64)
+	case "float64":
+		return strconv.ParseFloat(v.Value, 64)
+	case "bool":
+		return strconv.ParseBool(v.Value)
+	case "bytes":
+		return base64.StdEncoding.DecodeString(v.Value)
+	case "string":
+		return v.Value, nil
+	case "time":
+		return time.Parse(time.RFC3339Nano, v.Value)
+	default:
+		return nil, fmt.Errorf("unknown SQL value type %q", v.Type)
+	}
+}
+
+// sqlKey returns the key of a SQL query or statement with the given arguments
+func sqlKey(query string, args []driver.NamedValue) string {
+	if len(args) == 0 {
+		return query
+	}
+	values := make([]sqlValue, len(args))
+	for i, arg := range args {
+		values[i] = encodeSQLValue(arg.Value)
+	}
+	encoded, _ := json.Marshal(values)
+	return query + " " + string(encoded)
+}
+
+// sqlDriver wraps the database/sql driver name
+type sqlDriver struct {
+	name string
+}
+
+// Open opens a connection recording the results of the queries, or replaying them without
+// connecting to the database.
+func (d *sqlDriver) Open(dsn string) (driver.Conn, error) {
+	store := Current()
+	if store != nil && store.Mode() == Replay {
+		return &sqlReplayConn{store: store}, nil
+	}
+
+	db, err := sql.Open(d.name, dsn)
+	if err != nil {
+		return nil, err
+	}
+	base := db.Driver()
+	_ = db.Close()
+	conn, err := base.Open(dsn)
+	if err != nil || store == nil {
+		return conn, err
+	}
+	return &sqlRecordConn{conn: conn, store: store}, nil
+}
+
+// sqlRecordConn records the results of the queries run on a connection
+type sqlRecordConn struct {
+	conn  driver.Conn
+	store *Store
+}
+
+func (c *sqlRecordConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *sqlRecordConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sqlRecordStmt{stmt: stmt, query: query, store: c.store}, nil
+}
+
+func (c *sqlRecordConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *sqlRecordConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *sqlRecordConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	//nolint:staticcheck // the driver doesn't support contexts
+	return c.conn.Begin()
+}
+
+func (c *sqlRecordConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// CheckNamedValue lets the driver convert its own argument types
+func (c *sqlRecordConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// sqlRecordStmt records the results of a prepared statement
+type sqlRecordStmt struct {
+	stmt  driver.Stmt
+	query string
+	store *Store
+}
+
+func (s *sqlRecordStmt) Close() error {
+	return s.stmt.Close()
+}
+
+func (s *sqlRecordStmt) NumInput() int {
+	return s.stmt.NumInput()
+}
+
+func (s *sqlRecordStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+func (s *sqlRecordStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+func (s *sqlRecordStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	var result driver.Result
+	var err error
+	if execer, ok := s.stmt.(driver.StmtExecContext); ok {
+		result, err = execer.ExecContext(ctx, args)
+	} else {
+		//nolint:staticcheck // the driver doesn't support contexts
+		result, err = s.stmt.Exec(values(args))
+	}
+
+	var resp sqlResponse
+	if err != nil {
+		resp.Error = err.Error()
+	} else if affected, affectedErr := result.RowsAffected(); affectedErr == nil {
+		resp.RowsAffected = affected
+	}
+	if recordErr := s.store.Record(sqlKind, sqlKey(s.query, args), resp); recordErr != nil {
+		return nil, recordErr
+	}
+	return result, err
+}
+
+func (s *sqlRecordStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	var rows driver.Rows
+	var err error
+	if queryer, ok := s.stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		//nolint:staticcheck // the driver doesn't support contexts
+		rows, err = s.stmt.Query(values(args))
+	}
+
+	var resp sqlResponse
+	var result *sqlRows
+	if err == nil {
+		// the rows are read entirely to be recorded
+		result, err = readRows(rows)
+		if err == nil {
+			resp.Columns = result.columns
+			for _, row := range result.rows {
+				encoded := make([]sqlValue, len(row))
+				for i, v := range row {
+					encoded[i] = encodeSQLValue(v)
+				}
+				resp.Rows = append(resp.Rows, encoded)
+			}
+		}
+	}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	if recordErr := s.store.Record(sqlKind, sqlKey(s.query, args), resp); recordErr != nil {
+		return nil, recordErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// readRows reads and closes rows
+func readRows(rows driver.Rows) (*sqlRows, error) {
+	defer rows.Close()
+	result := &sqlRows{columns: rows.Columns()}
+	for {
+		dest := make([]driver.Value, len(result.columns))
+		err := rows.Next(dest)
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		for i, v := range dest {
+			// drivers may reuse their buffers between rows
+			if b, ok := v.([]byte); ok {
+				dest[i] = bytes.Clone(b)
+			}
+		}
+		result.rows = append(result.rows, dest)
+	}
+}
+
+// sqlReplayConn replays the recorded results of the queries, without any database
+type sqlReplayConn struct {
+	store *Store
+}
+
+func (c *sqlReplayConn) Prepare(query string) (driver.Stmt, error) {
+	return &sqlReplayStmt{query: query, store: c.store}, nil
+}
+
+func (c *sqlReplayConn) Close() error {
+	return nil
+}
+
+func (c *sqlReplayConn) Begin() (driver.Tx, error) {
+	return sqlReplayTx{}, nil
+}
+
+func (c *sqlReplayConn) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// CheckNamedValue converts the standard argument types, and keeps the others as they are
+// since the driver which recorded them isn't used
+func (c *sqlReplayConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if v, err := driver.DefaultParameterConverter.ConvertValue(nv.Value); err == nil {
+		nv.Value = v
+	}
+	return nil
+}
+
+type sqlReplayTx struct{}
+
+func (sqlReplayTx) Commit() error   { return nil }
+func (sqlReplayTx) Rollback() error { return nil }
+
+// sqlReplayStmt replays the recorded results of a statement
+type sqlReplayStmt struct {
+	query string
+	store *Store
+}
+
+func (s *sqlReplayStmt) Close() error {
+	return nil
+}
+
+// NumInput returns -1 as the number of arguments is only known by the driver which recorded
+// the statement
+func (s *sqlReplayStmt) NumInput() int {
+	return -1
+}
+
+func (s *sqlReplayStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+func (s *sqlReplayStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+func (s *sqlReplayStmt) replay(ctx context.Context, args []driver.NamedValue) (sqlResponse, error) {
+	var resp sqlResponse
+	if err := ctx.Err(); err != nil {
+		return resp, err
+	}
+	if err := s.store.Replay(sqlKind, sqlKey(s.query, args), &resp); err != nil {
+		return resp, err
+	}
+	if resp.Error != "" {
+		return resp, errors.New(resp.Error)
+	}
+	return resp, nil
+}
+
+func (s *sqlReplayStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	resp, err := s.replay(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(resp.RowsAffected), nil
+}
+
+func (s *sqlReplayStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	resp, err := s.replay(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	rows := &sqlRows{columns: resp.Columns}
+	for _, encoded := range resp.Rows {
+		row := make([]driver.Value, len(encoded))
+		for i, v := range encoded {
+			if row[i], err = v.decode(); err != nil {
+				return nil, err
+			}
+		}
+		rows.rows = append(rows.rows, row)
+	}
+	return rows, nil
+}
+
+// sqlRows are rows read in memory
+type sqlRows struct {
+	columns []string
+	rows    [][]driver.Value
+	next    int
+}
+
+func (r *sqlRows) Columns() []string {
+	return r.columns
+}
+
+func (r *sqlRows) Close() error {
+	return nil
+}
+
+func (r *sqlRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return named
+}
+
+func values(args []driver.NamedValue) []driver.Value {
+	v := make([]driver.Value, len(args))
+	for i, arg := range args {
+		v[i] = arg.Value
+	}
+	return v
+}
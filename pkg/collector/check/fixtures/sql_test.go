@@ -0,0 +1,153 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package fixtures
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testDriver is a database with a single table of sessions
+type testDriver struct {
+	opens atomic.Int32
+}
+
+func (d *testDriver) Open(string) (driver.Conn, error) {
+	d.opens.Add(1)
+	return testConn{}, nil
+}
+
+type testConn struct{}
+
+func (testConn) Prepare(query string) (driver.Stmt, error) { return testStmt{query: query}, nil }
+func (testConn) Close() error                              { return nil }
+func (testConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+type testStmt struct{ query string }
+
+func (testStmt) Close() error  { return nil }
+func (testStmt) NumInput() int { return -1 }
+func (s testStmt) Exec([]driver.Value) (driver.Result, error) {
+	if s.query == "DELETE FROM missing" {
+		return nil, errors.New("ORA-00942: table or view does not exist")
+	}
+	return driver.RowsAffected(2), nil
+}
+func (s testStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &testRows{rows: [][]driver.Value{
+		{"db1", int64(12), 0.5, []byte("raw"), time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), nil},
+		{args[0], int64(3), 1.25, []byte{}, time.Date(2026, 1, 2, 3, 4, 6, 0, time.UTC), true},
+	}}, nil
+}
+
+type testRows struct {
+	rows [][]driver.Value
+	next int
+}
+
+func (r *testRows) Columns() []string {
+	return []string{"name", "count", "ratio", "raw", "ts", "flag"}
+}
+func (r *testRows) Close() error { return nil }
+func (r *testRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
+
+type testSession struct {
+	Name  string
+	Count int64
+	Ratio float64
+	Raw   []byte
+	TS    time.Time
+	Flag  sql.NullBool
+}
+
+func querySessions(t *testing.T, db *sql.DB) []testSession {
+	rows, err := db.QueryContext(context.Background(), "SELECT * FROM sessions WHERE name = :1", "db2")
+	require.NoError(t, err)
+	defer rows.Close()
+	var sessions []testSession
+	for rows.Next() {
+		var s testSession
+		require.NoError(t, rows.Scan(&s.Name, &s.Count, &s.Ratio, &s.Raw, &s.TS, &s.Flag))
+		sessions = append(sessions, s)
+	}
+	require.NoError(t, rows.Err())
+	return sessions
+}
+
+func TestSQLRecordReplay(t *testing.T) {
+	base := &testDriver{}
+	sql.Register("fixtures-test", base)
+	dir := filepath.Join(t.TempDir(), "fixtures")
+
+	// without fixtures, the driver isn't wrapped
+	assert.Equal(t, "fixtures-test", SQLDriver("fixtures-test"))
+
+	recorder, err := NewRecorder(dir)
+	require.NoError(t, err)
+	SetCurrent(recorder)
+	defer SetCurrent(nil)
+
+	db, err := sql.Open(SQLDriver("fixtures-test"), "user/password@db")
+	require.NoError(t, err)
+	recorded := querySessions(t, db)
+	require.Len(t, recorded, 2)
+	assert.Equal(t, "db2", recorded[1].Name)
+	result, err := db.Exec("UPDATE sessions SET count = 0")
+	require.NoError(t, err)
+	affected, err := result.RowsAffected()
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, affected)
+	_, err = db.Exec("DELETE FROM missing")
+	assert.EqualError(t, err, "ORA-00942: table or view does not exist")
+	require.NoError(t, db.Close())
+	require.NoError(t, recorder.Save())
+	assert.Equal(t, []string{
+		"DELETE FROM missing",
+		`SELECT * FROM sessions WHERE name = :1 [{"type":"string","value":"db2"}]`,
+		"UPDATE sessions SET count = 0",
+	}, recorder.Keys(sqlKind))
+
+	store, err := Load(dir)
+	require.NoError(t, err)
+	SetCurrent(store)
+	opens := base.opens.Load()
+
+	db, err = sql.Open(SQLDriver("fixtures-test"), "user/password@db")
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.Ping())
+	assert.Equal(t, recorded, querySessions(t, db))
+	result, err = db.Exec("UPDATE sessions SET count = 0")
+	require.NoError(t, err)
+	affected, err = result.RowsAffected()
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, affected)
+	_, err = db.Exec("DELETE FROM missing")
+	assert.EqualError(t, err, "ORA-00942: table or view does not exist")
+	_, err = db.Query("SELECT * FROM other")
+	assert.ErrorIs(t, err, ErrNotRecorded)
+	assert.Equal(t, []string{"sql SELECT * FROM other"}, store.Missing())
+
+	// the database isn't reached when replaying
+	assert.Equal(t, opens, base.opens.Load())
+}
@@ -52,6 +52,8 @@ var (
 		[]string{"check_name"}, "Service checks count")
 	tlmHistogramBuckets = telemetry.NewCounter("checks", "histogram_buckets",
 		[]string{"check_name"}, "Histogram buckets count")
+	tlmTimeouts = telemetry.NewCounter("checks", "timeouts",
+		[]string{"check_name"}, "Check runs which exceeded the run timeout")
 	tlmExecutionTime = telemetry.NewGauge("checks", "execution_time",
 		[]string{"check_name", "check_loader"}, "Check execution time")
 	tlmCheckDelay = telemetry.NewGauge("checks",
@@ -128,6 +130,11 @@ type Stats struct {
 	LastDelay                float64       // most recent check start time delay relative to the previous check run, in seconds
 	LastWarnings             []string      // warnings that occurred in the last run, if any
 	UpdateTimestamp          time.Time     // latest update to this instance, unix timestamp in seconds
+	TimedOut                 bool          // the current run exceeded the run timeout and hasn't completed yet
+	TimeoutDate              int64         // date at which the current run timed out, unix timestamp in seconds
+	TimeoutStack             string        // stack of the check when the current run timed out
+	RunTimeout               time.Duration // run timeout exceeded by the current run
+	TotalTimeouts            uint64
 	m                        sync.Mutex
 	Telemetry                bool // do we want telemetry on this Check
 	HASupported              bool
@@ -225,6 +232,8 @@ func (cs *Stats) Add(t time.Duration, err error, warnings []error, metricStats S
 		}
 	}
 	cs.UpdateTimestamp = time.Now()
+	cs.TimedOut = false
+	cs.TimeoutStack = ""
 
 	if metricStats.MetricSamples > 0 {
 		cs.MetricSamples = metricStats.MetricSamples
@@ -267,6 +276,21 @@ func (cs *Stats) Add(t time.Duration, err error, warnings []error, metricStats S
 	}
 }
 
+// SetTimedOut marks the current run as timed out after the run timeout t, with the
+// given stack of the check. The mark is cleared when the run completes.
+func (cs *Stats) SetTimedOut(t time.Duration, stack string) {
+	cs.m.Lock()
+	defer cs.m.Unlock()
+	cs.TimedOut = true
+	cs.TimeoutDate = time.Now().Unix()
+	cs.TimeoutStack = stack
+	cs.RunTimeout = t
+	cs.TotalTimeouts++
+	if cs.Telemetry {
+		tlmTimeouts.Inc(cs.CheckName)
+	}
+}
+
 // SetStateCancelling sets the check stats to be in a cancelling state
 func (cs *Stats) SetStateCancelling() {
 	cs.m.Lock()
@@ -110,6 +110,23 @@ func TestNewStatsStateTelemetryInitialized(t *testing.T) {
 	)
 }
 
+func TestSetTimedOut(t *testing.T) {
+	stats := NewStats(newMockCheck())
+
+	stats.SetTimedOut(30*time.Second, "goroutine 42 [select]:")
+	assert.True(t, stats.TimedOut)
+	assert.Equal(t, "goroutine 42 [select]:", stats.TimeoutStack)
+	assert.Equal(t, 30*time.Second, stats.RunTimeout)
+	assert.NotZero(t, stats.TimeoutDate)
+	assert.EqualValues(t, 1, stats.TotalTimeouts)
+
+	// the mark is cleared once the run completes, the count is kept
+	stats.Add(45*time.Second, nil, nil, SenderStats{}, nil)
+	assert.False(t, stats.TimedOut)
+	assert.Empty(t, stats.TimeoutStack)
+	assert.EqualValues(t, 1, stats.TotalTimeouts)
+}
+
 func TestTranslateEventPlatformEventTypes(t *testing.T) {
 	original := map[string]interface{}{
 		"EventPlatformEvents": map[string]interface{}{
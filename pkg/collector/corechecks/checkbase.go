@@ -7,6 +7,7 @@
 package corechecks
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -51,6 +52,7 @@ type CheckBase struct {
 	telemetry      bool
 	initConfig     string
 	instanceConfig string
+	runContext     context.Context
 }
 
 // NewCheckBase returns a check base struct with a given check name
@@ -181,6 +183,21 @@ func (c *CheckBase) Warnf(format string, params ...interface{}) error {
 	return w
 }
 
+// SetRunContext implements check.RunContextSetter. It is called by the worker before
+// each run, and with a nil context after it.
+func (c *CheckBase) SetRunContext(ctx context.Context) {
+	c.runContext = ctx
+}
+
+// RunContext returns the context of the current run. It is cancelled when the run exceeds
+// its timeout, so checks should pass it to the calls which may block.
+func (c *CheckBase) RunContext() context.Context {
+	if c.runContext == nil {
+		return context.Background()
+	}
+	return c.runContext
+}
+
 // Stop does nothing by default, you need to implement it in
 // long-running checks (persisting after Run() exits)
 func (c *CheckBase) Stop() {}
@@ -6,12 +6,14 @@
 package corechecks
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/collector/check/defaults"
 )
 
@@ -68,3 +70,17 @@ func TestCommonConfigureCustomID(t *testing.T) {
 	assert.Equal(t, string(mycheck.ID()), "test:foobar:a934df33209f45f4")
 	mockSender.AssertExpectations(t)
 }
+
+func TestRunContext(t *testing.T) {
+	mycheck := &dummyCheck{
+		CheckBase: NewCheckBase("test"),
+	}
+	var _ check.RunContextSetter = mycheck
+
+	assert.Equal(t, context.Background(), mycheck.RunContext())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	mycheck.SetRunContext(ctx)
+	cancel()
+	assert.ErrorIs(t, mycheck.RunContext().Err(), context.Canceled)
+}
@@ -9,6 +9,7 @@
 package networkconfigmanagement
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -21,6 +22,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
 	ncmconfig "github.com/DataDog/datadog-agent/pkg/networkconfigmanagement/config"
+	"github.com/DataDog/datadog-agent/pkg/networkconfigmanagement/drift"
 	ncmremote "github.com/DataDog/datadog-agent/pkg/networkconfigmanagement/remote"
 	ncmreport "github.com/DataDog/datadog-agent/pkg/networkconfigmanagement/report"
 	ncmsender "github.com/DataDog/datadog-agent/pkg/networkconfigmanagement/sender"
@@ -34,11 +36,12 @@ const CheckName = "network_config_management"
 // Check is the main struct for the network configuration management check
 type Check struct {
 	core.CheckBase
-	checkContext *ncmconfig.NcmCheckContext
-	sender       *ncmsender.NCMSender
-	agentConfig  config.Component
-	remoteClient ncmremote.Client
-	clock        clock.Clock
+	checkContext  *ncmconfig.NcmCheckContext
+	sender        *ncmsender.NCMSender
+	agentConfig   config.Component
+	remoteClient  ncmremote.Client
+	driftDetector *drift.Detector
+	clock         clock.Clock
 }
 
 // Run executes the check to retrieve network device configurations from a device
@@ -46,9 +49,16 @@ func (c *Check) Run() error {
 	var checkErr error
 	var configs []ncmreport.NetworkDeviceConfig
 
+	deviceID := fmt.Sprintf("%s:%s", c.checkContext.Namespace, c.checkContext.Device.IPAddress)
+
 	checkErr = c.remoteClient.Connect()
 	if checkErr != nil {
 		log.Errorf("unable to connect to remote device %s: %s", c.checkContext.Device.IPAddress, checkErr)
+		var mismatchErr *ncmremote.HostKeyMismatchError
+		if errors.As(checkErr, &mismatchErr) {
+			c.sender.SendHostKeyMismatchEvent(deviceID, c.checkContext.Device.IPAddress, mismatchErr.Fingerprint, mismatchErr.Expected, c.clock.Now().Unix())
+			c.sender.Commit()
+		}
 		return checkErr
 	}
 
@@ -77,35 +87,55 @@ func (c *Check) Run() error {
 	}
 
 	// TODO: confirm what tags should be associated for a device config and/or its metrics
-	deviceID := fmt.Sprintf("%s:%s", c.checkContext.Namespace, c.checkContext.Device.IPAddress)
 	tags := []string{
 		"device_ip:" + c.checkContext.Device.IPAddress,
 	}
 
-	runningConfig, metadata, checkErr := c.checkContext.ProfileCache.Profile.ProcessCommandOutput(profile.Running, rawRunningConfig)
-	if checkErr != nil {
-		log.Warnf("unable to process rules for running config for device %s, using agent collection ts: %s", deviceID, checkErr)
+	runningConfig, metadata, runningErr := c.checkContext.ProfileCache.Profile.ProcessCommandOutput(profile.Running, rawRunningConfig)
+	if runningErr != nil {
+		log.Warnf("unable to process rules for running config for device %s, using agent collection ts: %s", deviceID, runningErr)
 	}
 	// TODO: helper fn to take metadata that needs to be emitted as metrics + emit them
 	configs = append(configs, ncmreport.ToNetworkDeviceConfig(deviceID, c.checkContext.Device.IPAddress, ncmreport.RUNNING, metadata, tags, runningConfig))
 
+	// startupConfig stays nil if the startup config could not be retrieved or processed, not to be compared
+	var startupConfig []byte
 	rawStartupConfig, checkErr := c.remoteClient.RetrieveStartupConfig()
 	if checkErr != nil {
 		// If the startup config cannot be retrieved, log a warning but continue
 		log.Warnf("unable to retrieve startup config for %s, will not send: %s", deviceID, checkErr)
 	} else {
-		startupConfig, metadata, checkErr := c.checkContext.ProfileCache.Profile.ProcessCommandOutput(profile.Startup, rawStartupConfig)
+		processedStartupConfig, metadata, checkErr := c.checkContext.ProfileCache.Profile.ProcessCommandOutput(profile.Startup, rawStartupConfig)
 		if checkErr != nil {
 			log.Warnf("unable to process rules for startup config for device %s, using agent collection ts: %s", deviceID, checkErr)
+		} else {
+			startupConfig = processedStartupConfig
 		}
 		// add the startup config to the payload if it was retrieved successfully
-		configs = append(configs, ncmreport.ToNetworkDeviceConfig(deviceID, c.checkContext.Device.IPAddress, ncmreport.STARTUP, metadata, tags, startupConfig))
+		configs = append(configs, ncmreport.ToNetworkDeviceConfig(deviceID, c.checkContext.Device.IPAddress, ncmreport.STARTUP, metadata, tags, processedStartupConfig))
 	}
 
-	checkErr = c.sender.SendNCMConfig(ncmreport.ToNCMPayload(c.checkContext.Namespace, "", configs, c.clock.Now().Unix()))
+	var diffs []ncmreport.NetworkDeviceDiff
+	var driftState *drift.DeviceState
+	if runningErr == nil {
+		diffs, driftState = c.detectConfigChanges(deviceID, tags, runningConfig, startupConfig)
+	}
+
+	payload := ncmreport.ToNCMPayload(c.checkContext.Namespace, "", configs, c.clock.Now().Unix())
+	payload.Diffs = diffs
+	checkErr = c.sender.SendNCMConfig(payload)
 	if checkErr != nil {
 		return checkErr
 	}
+	for _, deviceDiff := range diffs {
+		c.sender.SendConfigDiffEvent(deviceDiff)
+	}
+	// the configs only become the last known ones once sent, for their changes to be reported again otherwise
+	if driftState != nil {
+		if err := c.driftDetector.Commit(deviceID, driftState); err != nil {
+			log.Warnf("unable to store the last known config for device %s: %s", deviceID, err)
+		}
+	}
 
 	// TODO: Send any metrics as well
 	//c.sender.SendNCMMetrics()
@@ -139,8 +169,14 @@ func (c *Check) Configure(senderManager sender.SenderManager, integrationConfigD
 	ncmSender := ncmsender.NewNCMSender(s, c.checkContext.Namespace)
 	c.sender = ncmSender
 
-	// TODO: add check to see the device's credentials type (SSH/Telnet) and create appropriate client factory
-	c.remoteClient = ncmremote.NewSSHClient(c.checkContext.Device)
+	// Create the client for the transport of the device (SSH, NETCONF or RESTCONF)
+	c.remoteClient, err = ncmremote.NewClient(c.checkContext.Device, c.agentConfig.GetString("run_path"))
+	if err != nil {
+		return err
+	}
+
+	// Initialize the drift detector, keeping the last known configs under the run path
+	c.driftDetector = drift.NewDetector(drift.NewStore(drift.StorePath(c.agentConfig.GetString("run_path"))))
 
 	// Initialize the clock
 	c.clock = clock.New()
@@ -168,12 +204,35 @@ func newCheck(agentConfig config.Component) check.Check {
 	}
 }
 
+// detectConfigChanges compares the configs retrieved from the device with its last known config, and its running config
+// with its startup config, ignoring the volatile lines declared in the profile. It returns the diffs to report and the
+// state to commit once they are sent.
+func (c *Check) detectConfigChanges(deviceID string, tags []string, runningConfig []byte, startupConfig []byte) ([]ncmreport.NetworkDeviceDiff, *drift.DeviceState) {
+	prof := c.checkContext.ProfileCache.Profile
+	runningConfig = prof.StripVolatileLines(profile.Running, runningConfig)
+	if startupConfig != nil {
+		startupConfig = prof.StripVolatileLines(profile.Startup, startupConfig)
+	}
+
+	ts := c.clock.Now().Unix()
+	changes, state := c.driftDetector.Detect(deviceID, runningConfig, startupConfig, ts)
+
+	var diffs []ncmreport.NetworkDeviceDiff
+	for _, change := range changes {
+		diffs = append(diffs, ncmreport.ToNetworkDeviceDiff(deviceID, c.checkContext.Device.IPAddress, ncmreport.DiffType(change.Type), change.FromHash, change.ToHash, change.Diff, tags, ts))
+	}
+	return diffs, state
+}
+
 // FindMatchingProfile supports testing profiles until one is found with a successful command for the device
 func (c *Check) FindMatchingProfile() (*profile.NCMProfile, error) {
 	for profName, prof := range c.checkContext.ProfileMap {
 		if c.checkContext.ProfileCache.HasTried(profName) {
 			continue
 		}
+		if !prof.SupportsTransport(string(c.checkContext.Device.Transport)) {
+			continue
+		}
 		c.remoteClient.SetProfile(prof)
 		_, err := c.remoteClient.RetrieveRunningConfig()
 		if err != nil {
@@ -12,6 +12,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -26,7 +27,9 @@ import (
 	agentconfig "github.com/DataDog/datadog-agent/comp/core/config"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
 	checkid "github.com/DataDog/datadog-agent/pkg/collector/check/id"
+	"github.com/DataDog/datadog-agent/pkg/metrics/event"
 	ncmremote "github.com/DataDog/datadog-agent/pkg/networkconfigmanagement/remote"
+	ncmreport "github.com/DataDog/datadog-agent/pkg/networkconfigmanagement/report"
 )
 
 // Test fixtures and mocks
@@ -130,7 +133,9 @@ func (m *MockRemoteSession) Close() error {
 // Test helper functions
 
 func createTestCheck(t *testing.T) *Check {
-	cfg := agentconfig.NewMock(t)
+	cfg := agentconfig.NewMockWithOverrides(t, map[string]interface{}{
+		"run_path": t.TempDir(),
+	})
 	return newCheck(cfg).(*Check)
 }
 
@@ -279,6 +284,80 @@ func TestCheck_Run_Success(t *testing.T) {
 	mockSender.AssertExpectations(t)
 }
 
+func TestCheck_Run_ConfigChanges(t *testing.T) {
+	check := createTestCheck(t)
+
+	id := checkid.BuildID(CheckName, integration.FakeConfigHash, validConfig, []byte(``))
+	senderManager := mocksender.CreateDefaultDemultiplexer()
+	mockSender := mocksender.NewMockSenderWithSenderManager(id, senderManager)
+	mockSender.On("EventPlatformEvent", mock.Anything, mock.Anything).Return()
+	mockSender.On("Event", mock.Anything).Return()
+	mockSender.On("Commit").Return()
+
+	// Configure the check
+	profile.SetConfdPathAndCleanProfiles()
+	err := check.Configure(senderManager, integration.FakeConfigHash, validConfig, []byte{}, "test")
+	require.NoError(t, err)
+
+	mockClock := clock.NewMock()
+	mockClock.Set(time.Date(2025, 8, 1, 10, 20, 0, 0, time.UTC))
+	check.clock = mockClock
+
+	mockClient := newMockRemoteClient()
+	check.remoteClient = mockClient
+
+	// the first run only records the configs, and the volatile lines of the running config are ignored
+	require.NoError(t, check.Run())
+	mockSender.AssertNumberOfCalls(t, "Event", 0)
+
+	// the running config is changed without being saved
+	mockClient.Session.OutputMap["show running-config"] = "Building configuration...\n! Last configuration change at 10:25:00 UTC Fri Aug 1 2025\ninterface GigabitEthernet0/1\n ip address 192.168.1.2 255.255.255.0"
+	mockClock.Add(5 * time.Minute)
+	require.NoError(t, check.Run())
+
+	mockSender.AssertNumberOfCalls(t, "EventPlatformEvent", 2)
+	mockSender.AssertNumberOfCalls(t, "Event", 2)
+	mockSender.AssertEventWithCompareFunc(t, event.Event{
+		Title:          "Running configuration changed on network device 10.0.0.1",
+		Ts:             mockClock.Now().Unix(),
+		Priority:       event.PriorityNormal,
+		AlertType:      event.AlertTypeInfo,
+		SourceTypeName: "network_config_management",
+		EventType:      "running_change",
+		AggregationKey: "default:10.0.0.1",
+		Tags:           []string{"device_namespace:default", "device_id:default:10.0.0.1", "device_ip:10.0.0.1"},
+	}, 0, func(expected, actual event.Event) bool {
+		return expected.Title == actual.Title && strings.Contains(actual.Text, "+ ip address 192.168.1.2 255.255.255.0")
+	})
+	mockSender.AssertEventWithCompareFunc(t, event.Event{
+		Title:          "Unsaved configuration changes on network device 10.0.0.1",
+		Ts:             mockClock.Now().Unix(),
+		Priority:       event.PriorityNormal,
+		AlertType:      event.AlertTypeWarning,
+		SourceTypeName: "network_config_management",
+		EventType:      "unsaved_changes",
+		AggregationKey: "default:10.0.0.1",
+		Tags:           []string{"device_namespace:default", "device_id:default:10.0.0.1", "device_ip:10.0.0.1"},
+	}, 0, func(expected, actual event.Event) bool {
+		return expected.Title == actual.Title && strings.Contains(actual.Text, "- ip address 192.168.1.1 255.255.255.0")
+	})
+
+	// the diffs are also sent along with the configs
+	var payloadBytes []byte
+	for _, call := range mockSender.Calls {
+		if call.Method == "EventPlatformEvent" {
+			payloadBytes = call.Arguments.Get(0).([]byte)
+		}
+	}
+	var payload ncmreport.NCMPayload
+	require.NoError(t, json.Unmarshal(payloadBytes, &payload))
+	require.Len(t, payload.Diffs, 2)
+	assert.Equal(t, string(ncmreport.RunningChange), payload.Diffs[0].DiffType)
+	assert.Equal(t, string(ncmreport.UnsavedChanges), payload.Diffs[1].DiffType)
+	assert.Equal(t, 1, payload.Diffs[0].Added)
+	assert.Equal(t, 1, payload.Diffs[0].Removed)
+}
+
 func TestCheck_Run_ConnectionFailure(t *testing.T) {
 	check := createTestCheck(t)
 	senderManager := mocksender.CreateDefaultDemultiplexer()
@@ -300,6 +379,53 @@ func TestCheck_Run_ConnectionFailure(t *testing.T) {
 	assert.Contains(t, err.Error(), "connection refused")
 }
 
+func TestCheck_Run_HostKeyMismatch(t *testing.T) {
+	check := createTestCheck(t)
+
+	id := checkid.BuildID(CheckName, integration.FakeConfigHash, validConfig, []byte(``))
+	senderManager := mocksender.CreateDefaultDemultiplexer()
+	mockSender := mocksender.NewMockSenderWithSenderManager(id, senderManager)
+	mockSender.On("Event", mock.Anything).Return().Once()
+	mockSender.On("Commit").Return()
+
+	// Configure the check
+	profile.SetConfdPathAndCleanProfiles()
+	err := check.Configure(senderManager, integration.FakeConfigHash, validConfig, []byte{}, "test")
+	require.NoError(t, err)
+
+	mockClock := clock.NewMock()
+	mockClock.Set(time.Date(2025, 8, 1, 10, 20, 0, 0, time.UTC))
+	check.clock = mockClock
+
+	// Set up a mock remote client for a device presenting another host key than the pinned one
+	mockClient := newMockRemoteClient()
+	mockClient.ConnectionError = fmt.Errorf("failed to connect to 10.0.0.1:22: %w", &ncmremote.HostKeyMismatchError{
+		Host:           "10.0.0.1:22",
+		KnownHostsFile: "/opt/datadog-agent/run/network_config_management/known_hosts",
+		Fingerprint:    "SHA256:new",
+		Expected:       []string{"SHA256:old"},
+	})
+	check.remoteClient = mockClient
+
+	err = check.Run()
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "host key verification failed for 10.0.0.1:22")
+	mockSender.AssertNumberOfCalls(t, "EventPlatformEvent", 0)
+	mockSender.AssertEventWithCompareFunc(t, event.Event{
+		Title:          "SSH host key changed for network device 10.0.0.1",
+		Ts:             mockClock.Now().Unix(),
+		Priority:       event.PriorityNormal,
+		AlertType:      event.AlertTypeError,
+		SourceTypeName: "network_config_management",
+		EventType:      "host_key_mismatch",
+		AggregationKey: "default:10.0.0.1",
+		Tags:           []string{"device_namespace:default", "device_id:default:10.0.0.1", "device_ip:10.0.0.1"},
+	}, 0, func(expected, actual event.Event) bool {
+		return expected.Title == actual.Title && strings.Contains(actual.Text, "SHA256:new")
+	})
+}
+
 func TestCheck_Run_ConfigRetrievalFailure(t *testing.T) {
 	check := createTestCheck(t)
 	senderManager := mocksender.CreateDefaultDemultiplexer()
@@ -7,6 +7,8 @@ package networkpath
 
 import (
 	"fmt"
+	"math"
+	"slices"
 	"strings"
 	"time"
 
@@ -19,6 +21,11 @@ import (
 
 const (
 	defaultCheckInterval time.Duration = 1 * time.Minute
+
+	// defaultUDPDestPort is the first port of the traceroute port range, used for the UDP flows of ECMP discovery
+	defaultUDPDestPort = 33434
+	// maxECMPFlows bounds the number of traceroutes run per protocol for ECMP discovery
+	maxECMPFlows = 16
 )
 
 // Number is a type that is used to make a generic version
@@ -42,8 +49,10 @@ type InstanceConfig struct {
 
 	DestPort uint16 `yaml:"port"`
 
-	Protocol  string `yaml:"protocol"`
-	TCPMethod string `yaml:"tcp_method"`
+	Protocol string `yaml:"protocol"`
+	// Protocols traces the target with each protocol, it takes precedence over Protocol
+	Protocols []string `yaml:"protocols"`
+	TCPMethod string   `yaml:"tcp_method"`
 	// TCPSynParisTracerouteMode makes TCP SYN traceroute act like paris traceroute (fixed packet ID, randomized seq)
 	TCPSynParisTracerouteMode bool `yaml:"tcp_syn_paris_traceroute_mode"`
 	// DisableWindowsDriver disables the use of Windows driver for traceroute
@@ -61,6 +70,11 @@ type InstanceConfig struct {
 	TracerouteQueries int `yaml:"traceroute_queries"`
 	E2eQueries        int `yaml:"e2e_queries"`
 
+	// SyntheticMode submits hop metrics and path change events in addition to the network paths
+	SyntheticMode bool `yaml:"synthetic_mode"`
+	// ECMPFlows is the number of flows traced per protocol to discover ECMP paths
+	ECMPFlows int `yaml:"ecmp_flows"`
+
 	Tags []string `yaml:"tags"`
 }
 
@@ -84,6 +98,10 @@ type CheckConfig struct {
 	E2eQueries            int
 	Tags                  []string
 	Namespace             string
+	// Protocols is set when several protocols are configured, Protocol is used otherwise
+	Protocols     []payload.Protocol
+	SyntheticMode bool
+	ECMPFlows     int
 }
 
 // NewCheckConfig builds a new check config
@@ -113,6 +131,15 @@ func NewCheckConfig(rawInstance integration.Data, rawInitConfig integration.Data
 	c.SourceService = instance.SourceService
 	c.DestinationService = instance.DestinationService
 	c.Protocol = payload.Protocol(strings.ToUpper(instance.Protocol))
+	for _, protocol := range instance.Protocols {
+		protocol := payload.Protocol(strings.ToUpper(protocol))
+		if protocol != payload.ProtocolTCP && protocol != payload.ProtocolUDP && protocol != payload.ProtocolICMP {
+			return nil, fmt.Errorf("invalid protocol `%s`, valid protocols are TCP, UDP and ICMP", protocol)
+		}
+		if !slices.Contains(c.Protocols, protocol) {
+			c.Protocols = append(c.Protocols, protocol)
+		}
+	}
 	c.TCPMethod = payload.MakeTCPMethod(instance.TCPMethod)
 	c.TCPSynParisTracerouteMode = instance.TCPSynParisTracerouteMode
 	c.DisableWindowsDriver = instance.DisableWindowsDriver
@@ -153,12 +180,59 @@ func NewCheckConfig(rawInstance integration.Data, rawInitConfig integration.Data
 		setup.DefaultNetworkPathStaticPathE2eQueries,
 	)
 
+	if instance.ECMPFlows < 0 || instance.ECMPFlows > maxECMPFlows {
+		return nil, fmt.Errorf("ecmp_flows must be between 0 and %d", maxECMPFlows)
+	}
+	c.ECMPFlows = instance.ECMPFlows
+	if slices.Contains(c.protocols(), payload.ProtocolUDP) && c.flowCount(payload.ProtocolUDP) > 1 {
+		// UDP flows use consecutive destination ports, which must not overflow
+		if lastPort := int(c.udpBasePort()) + c.ECMPFlows - 1; lastPort > math.MaxUint16 {
+			return nil, fmt.Errorf("ecmp_flows is too large for port %d, the UDP flows would use destination ports up to %d", c.udpBasePort(), lastPort)
+		}
+	}
+	c.SyntheticMode = instance.SyntheticMode
+
 	c.Tags = instance.Tags
 	c.Namespace = setup.Datadog().GetString("network_devices.namespace")
 
 	return c, nil
 }
 
+// protocols returns the protocols used to trace the target
+func (c *CheckConfig) protocols() []payload.Protocol {
+	if len(c.Protocols) > 0 {
+		return c.Protocols
+	}
+	return []payload.Protocol{c.Protocol}
+}
+
+// flowCount returns the number of traceroutes run for a protocol. ICMP is traced once, as ICMP probes
+// have no ports to vary and all its flows would hash to the same ECMP path.
+func (c *CheckConfig) flowCount(protocol payload.Protocol) int {
+	if protocol == payload.ProtocolICMP {
+		return 1
+	}
+	return max(c.ECMPFlows, 1)
+}
+
+// flowDestPort returns the destination port of the traceroute of a flow. Routers balance traffic across
+// ECMP paths with a hash of the flow tuple: UDP flows use consecutive destination ports, while TCP flows
+// keep the configured port and rely on the source port, which is randomized for each traceroute.
+func (c *CheckConfig) flowDestPort(protocol payload.Protocol, flow int) uint16 {
+	if protocol != payload.ProtocolUDP || c.flowCount(protocol) == 1 {
+		return c.DestPort
+	}
+	return c.udpBasePort() + uint16(flow)
+}
+
+// udpBasePort returns the destination port of the first UDP flow
+func (c *CheckConfig) udpBasePort() uint16 {
+	if c.DestPort == 0 {
+		return defaultUDPDestPort
+	}
+	return c.DestPort
+}
+
 func firstNonZero[T Number](values ...T) T {
 	for _, value := range values {
 		if value != 0 {
@@ -486,6 +486,82 @@ disable_windows_driver: true
 				DisableWindowsDriver:  true,
 			},
 		},
+		{
+			name: "multiple protocols",
+			rawInstance: []byte(`
+hostname: 1.2.3.4
+protocols:
+  - tcp
+  - UDP
+  - icmp
+  - udp
+`),
+			rawInitConfig: []byte(``),
+			expectedConfig: &CheckConfig{
+				DestHostname:          "1.2.3.4",
+				MinCollectionInterval: time.Duration(60) * time.Second,
+				Namespace:             "my-namespace",
+				Protocols:             []payload.Protocol{payload.ProtocolTCP, payload.ProtocolUDP, payload.ProtocolICMP},
+				Timeout:               setup.DefaultNetworkPathTimeout * time.Millisecond,
+				MaxTTL:                setup.DefaultNetworkPathMaxTTL,
+				TracerouteQueries:     setup.DefaultNetworkPathStaticPathTracerouteQueries,
+				E2eQueries:            setup.DefaultNetworkPathStaticPathE2eQueries,
+			},
+		},
+		{
+			name: "invalid protocol returns an error",
+			rawInstance: []byte(`
+hostname: 1.2.3.4
+protocols:
+  - tcp
+  - sctp
+`),
+			rawInitConfig:  []byte(``),
+			expectedConfig: nil,
+			expectedError:  "invalid protocol `SCTP`, valid protocols are TCP, UDP and ICMP",
+		},
+		{
+			name: "synthetic mode with ECMP discovery",
+			rawInstance: []byte(`
+hostname: 1.2.3.4
+synthetic_mode: true
+ecmp_flows: 4
+`),
+			rawInitConfig: []byte(``),
+			expectedConfig: &CheckConfig{
+				DestHostname:          "1.2.3.4",
+				MinCollectionInterval: time.Duration(60) * time.Second,
+				Namespace:             "my-namespace",
+				Timeout:               setup.DefaultNetworkPathTimeout * time.Millisecond,
+				MaxTTL:                setup.DefaultNetworkPathMaxTTL,
+				TracerouteQueries:     setup.DefaultNetworkPathStaticPathTracerouteQueries,
+				E2eQueries:            setup.DefaultNetworkPathStaticPathE2eQueries,
+				SyntheticMode:         true,
+				ECMPFlows:             4,
+			},
+		},
+		{
+			name: "too many ECMP flows returns an error",
+			rawInstance: []byte(`
+hostname: 1.2.3.4
+ecmp_flows: 17
+`),
+			rawInitConfig:  []byte(``),
+			expectedConfig: nil,
+			expectedError:  "ecmp_flows must be between 0 and 16",
+		},
+		{
+			name: "UDP flows exceeding the port range return an error",
+			rawInstance: []byte(`
+hostname: 1.2.3.4
+port: 65530
+protocol: udp
+ecmp_flows: 8
+`),
+			rawInitConfig:  []byte(``),
+			expectedConfig: nil,
+			expectedError:  "ecmp_flows is too large for port 65530, the UDP flows would use destination ports up to 65537",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -549,3 +625,25 @@ func TestFirstNonZero(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckConfig_flows(t *testing.T) {
+	config := &CheckConfig{Protocol: payload.ProtocolTCP, DestPort: 443}
+	assert.Equal(t, []payload.Protocol{payload.ProtocolTCP}, config.protocols())
+	assert.Equal(t, 1, config.flowCount(payload.ProtocolTCP))
+	assert.Equal(t, uint16(443), config.flowDestPort(payload.ProtocolTCP, 0))
+
+	config = &CheckConfig{Protocols: []payload.Protocol{payload.ProtocolUDP, payload.ProtocolTCP}, ECMPFlows: 3}
+	assert.Equal(t, []payload.Protocol{payload.ProtocolUDP, payload.ProtocolTCP}, config.protocols())
+	assert.Equal(t, 3, config.flowCount(payload.ProtocolUDP))
+	assert.Equal(t, 3, config.flowCount(payload.ProtocolTCP))
+	// ICMP probes have no ports, their flows would all follow the same path
+	assert.Equal(t, 1, config.flowCount(payload.ProtocolICMP))
+	// UDP flows vary the destination port, starting from the traceroute port range
+	assert.Equal(t, uint16(33434), config.flowDestPort(payload.ProtocolUDP, 0))
+	assert.Equal(t, uint16(33436), config.flowDestPort(payload.ProtocolUDP, 2))
+	// TCP flows keep the destination port
+	assert.Equal(t, uint16(0), config.flowDestPort(payload.ProtocolTCP, 2))
+
+	config.DestPort = 5000
+	assert.Equal(t, uint16(5001), config.flowDestPort(payload.ProtocolUDP, 1))
+}
@@ -10,6 +10,7 @@ package networkpath
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -39,9 +40,13 @@ type Check struct {
 	config        *CheckConfig
 	lastCheckTime time.Time
 	telemetryComp telemetryComp.Component
+	// previousPaths are the paths found by the previous run for each protocol, used to detect path changes
+	previousPaths map[payload.Protocol][][]string
 }
 
-// Run executes the check
+// Run executes the check. A failing protocol doesn't prevent tracing the other ones: the paths and
+// metrics of the protocols that succeeded are still sent and committed, and the errors of the ones that
+// failed are joined in the returned error, so that the check is reported in error.
 func (c *Check) Run() error {
 	startTime := time.Now()
 	senderInstance, err := c.GetSender()
@@ -49,56 +54,76 @@ func (c *Check) Run() error {
 		return err
 	}
 	metricSender := metricsender.NewMetricSenderAgent(senderInstance)
+	metricTags := append(utils.GetCommonAgentTags(), c.config.Tags...)
 
-	cfg := config.Config{
-		DestHostname:              c.config.DestHostname,
-		DestPort:                  c.config.DestPort,
-		MaxTTL:                    c.config.MaxTTL,
-		Timeout:                   c.config.Timeout,
-		Protocol:                  c.config.Protocol,
-		TCPMethod:                 c.config.TCPMethod,
-		TCPSynParisTracerouteMode: c.config.TCPSynParisTracerouteMode,
-		DisableWindowsDriver:      c.config.DisableWindowsDriver,
-		ReverseDNS:                true,
-		TracerouteQueries:         c.config.TracerouteQueries,
-		E2eQueries:                c.config.E2eQueries,
+	var errs []error
+	var paths []payload.NetworkPath
+	for _, protocol := range c.config.protocols() {
+		protocolPaths, err := c.tracePaths(senderInstance, protocol)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if c.config.SyntheticMode && len(protocolPaths) > 0 {
+			c.submitPathHealth(senderInstance, protocol, protocolPaths, metricTags)
+		}
+		paths = append(paths, protocolPaths...)
 	}
 
-	tr, err := traceroute.New(cfg, c.telemetryComp)
-	if err != nil {
-		return fmt.Errorf("failed to initialize traceroute: %w", err)
-	}
-	path, err := tr.Run(context.TODO())
-	if err != nil {
-		return fmt.Errorf("failed to trace path: %w", err)
-	}
+	// TODO: Remove static path telemetry code (separate PR)
+	c.submitTelemetry(metricSender, paths, metricTags, startTime)
 
-	err = payload.ValidateNetworkPath(&path)
-	if err != nil {
-		return fmt.Errorf("failed to validate network path: %w", err)
-	}
+	senderInstance.Commit()
+	return errors.Join(errs...)
+}
 
-	path.Namespace = c.config.Namespace
-	path.Origin = payload.PathOriginNetworkPathIntegration
+// tracePaths traces the target with a protocol, once per flow, and sends the paths to EP
+func (c *Check) tracePaths(senderInstance sender.Sender, protocol payload.Protocol) ([]payload.NetworkPath, error) {
+	var paths []payload.NetworkPath
+	for flow := 0; flow < c.config.flowCount(protocol); flow++ {
+		cfg := config.Config{
+			DestHostname:              c.config.DestHostname,
+			DestPort:                  c.config.flowDestPort(protocol, flow),
+			MaxTTL:                    c.config.MaxTTL,
+			Timeout:                   c.config.Timeout,
+			Protocol:                  protocol,
+			TCPMethod:                 c.config.TCPMethod,
+			TCPSynParisTracerouteMode: c.config.TCPSynParisTracerouteMode,
+			DisableWindowsDriver:      c.config.DisableWindowsDriver,
+			ReverseDNS:                true,
+			TracerouteQueries:         c.config.TracerouteQueries,
+			E2eQueries:                c.config.E2eQueries,
+		}
 
-	// Add tags to path
-	path.Source.Service = c.config.SourceService
-	path.Destination.Service = c.config.DestinationService
-	path.Tags = append(path.Tags, c.config.Tags...)
+		tr, err := traceroute.New(cfg, c.telemetryComp)
+		if err != nil {
+			return paths, fmt.Errorf("failed to initialize traceroute: %w", err)
+		}
+		path, err := tr.Run(context.TODO())
+		if err != nil {
+			return paths, fmt.Errorf("failed to trace path: %w", err)
+		}
 
-	// send to EP
-	err = c.SendNetPathMDToEP(senderInstance, path)
-	if err != nil {
-		return fmt.Errorf("failed to send network path metadata: %w", err)
-	}
+		err = payload.ValidateNetworkPath(&path)
+		if err != nil {
+			return paths, fmt.Errorf("failed to validate network path: %w", err)
+		}
 
-	metricTags := append(utils.GetCommonAgentTags(), c.config.Tags...)
+		path.Namespace = c.config.Namespace
+		path.Origin = payload.PathOriginNetworkPathIntegration
 
-	// TODO: Remove static path telemetry code (separate PR)
-	c.submitTelemetry(metricSender, path, metricTags, startTime)
+		// Add tags to path
+		path.Source.Service = c.config.SourceService
+		path.Destination.Service = c.config.DestinationService
+		path.Tags = append(path.Tags, c.config.Tags...)
 
-	senderInstance.Commit()
-	return nil
+		// send to EP
+		err = c.SendNetPathMDToEP(senderInstance, path)
+		if err != nil {
+			return paths, fmt.Errorf("failed to send network path metadata: %w", err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
 }
 
 // SendNetPathMDToEP sends a traced network path to EP
@@ -112,7 +137,7 @@ func (c *Check) SendNetPathMDToEP(sender sender.Sender, path payload.NetworkPath
 	return nil
 }
 
-func (c *Check) submitTelemetry(metricSender metricsender.MetricSender, path payload.NetworkPath, metricTags []string, startTime time.Time) {
+func (c *Check) submitTelemetry(metricSender metricsender.MetricSender, paths []payload.NetworkPath, metricTags []string, startTime time.Time) {
 	var checkInterval time.Duration
 	if !c.lastCheckTime.IsZero() {
 		checkInterval = startTime.Sub(c.lastCheckTime)
@@ -120,7 +145,9 @@ func (c *Check) submitTelemetry(metricSender metricsender.MetricSender, path pay
 	c.lastCheckTime = startTime
 	checkDuration := time.Since(startTime)
 
-	telemetry.SubmitNetworkPathTelemetry(metricSender, path, checkDuration, checkInterval, metricTags)
+	for _, path := range paths {
+		telemetry.SubmitNetworkPathTelemetry(metricSender, path, checkDuration, checkInterval, metricTags)
+	}
 }
 
 // Interval returns the scheduling time for the check
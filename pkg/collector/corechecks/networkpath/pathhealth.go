@@ -0,0 +1,218 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package networkpath
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
+	"github.com/DataDog/datadog-agent/pkg/metrics/event"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/payload"
+)
+
+const (
+	pathChangeEventSource = "network_path"
+	pathChangeEventType   = "network_path.path_change"
+
+	// unknownHop stands for the hops which didn't respond in a path
+	unknownHop = "*"
+)
+
+// pathHealth summarizes the traceroutes of a target with one protocol over a check run
+type pathHealth struct {
+	hops []*hopStats
+	// paths are the distinct sequences of hop IPs, several paths are found behind ECMP routers
+	paths [][]string
+}
+
+// hopStats contains the responses of the hops at a TTL
+type hopStats struct {
+	ttl    int
+	probes int
+	// rtts contains the RTTs of each IP which responded at this TTL
+	rtts map[string][]float64
+}
+
+// lossPercentage returns the percentage of probes without response at this TTL
+func (h *hopStats) lossPercentage() float64 {
+	responses := 0
+	for _, rtts := range h.rtts {
+		responses += len(rtts)
+	}
+	return 100 * float64(h.probes-responses) / float64(h.probes)
+}
+
+// newPathHealth gathers the hops of all the traceroute runs of the paths
+func newPathHealth(paths []payload.NetworkPath) *pathHealth {
+	health := &pathHealth{}
+	hopsByTTL := make(map[int]*hopStats)
+	for _, path := range paths {
+		for _, run := range path.Traceroute.Runs {
+			hops := slices.Clone(run.Hops)
+			slices.SortStableFunc(hops, func(a, b payload.TracerouteHop) int { return a.TTL - b.TTL })
+
+			route := make([]string, 0, len(hops))
+			for _, hop := range hops {
+				stats, ok := hopsByTTL[hop.TTL]
+				if !ok {
+					stats = &hopStats{ttl: hop.TTL, rtts: make(map[string][]float64)}
+					hopsByTTL[hop.TTL] = stats
+				}
+				stats.probes++
+				if !hop.Reachable || hop.IPAddress == nil || hop.IPAddress.IsUnspecified() {
+					route = append(route, unknownHop)
+					continue
+				}
+				ip := hop.IPAddress.String()
+				stats.rtts[ip] = append(stats.rtts[ip], hop.RTT)
+				route = append(route, ip)
+			}
+			health.addPath(route)
+		}
+	}
+	for _, ttl := range slices.Sorted(maps.Keys(hopsByTTL)) {
+		health.hops = append(health.hops, hopsByTTL[ttl])
+	}
+	slices.SortFunc(health.paths, func(a, b []string) int { return slices.Compare(a, b) })
+	return health
+}
+
+// addPath records a route unless a known path matches it, keeping the route with the most responding hops
+func (h *pathHealth) addPath(route []string) {
+	if len(route) == 0 {
+		return
+	}
+	i := slices.IndexFunc(h.paths, func(path []string) bool { return routesMatch(path, route) })
+	if i < 0 {
+		h.paths = append(h.paths, route)
+		return
+	}
+	if unknownHops(route) < unknownHops(h.paths[i]) {
+		h.paths[i] = route
+	}
+}
+
+func unknownHops(route []string) int {
+	count := 0
+	for _, hop := range route {
+		if hop == unknownHop {
+			count++
+		}
+	}
+	return count
+}
+
+// jitters returns the variations between consecutive RTTs
+func jitters(rtts []float64) []float64 {
+	var result []float64
+	for i := 1; i < len(rtts); i++ {
+		result = append(result, math.Abs(rtts[i]-rtts[i-1]))
+	}
+	return result
+}
+
+// submitPathHealth submits the latency, jitter and loss of each hop, the number of paths found, and an event
+// when the paths differ from the previous run
+func (c *Check) submitPathHealth(sender sender.Sender, protocol payload.Protocol, paths []payload.NetworkPath, tags []string) {
+	tags = append(slices.Clone(tags),
+		"protocol:"+strings.ToLower(string(protocol)),
+		"destination_hostname:"+c.config.DestHostname,
+		"destination_port:"+strconv.Itoa(int(c.config.DestPort)),
+	)
+	health := newPathHealth(paths)
+	for _, hop := range health.hops {
+		ttlTags := append(slices.Clone(tags), "hop_ttl:"+strconv.Itoa(hop.ttl))
+		sender.Gauge("network_path.hop.packet_loss_percentage", hop.lossPercentage(), "", ttlTags)
+		for _, ip := range slices.Sorted(maps.Keys(hop.rtts)) {
+			hopTags := append(slices.Clone(ttlTags), "hop_ip:"+ip)
+			for _, rtt := range hop.rtts[ip] {
+				sender.Distribution("network_path.hop.rtt", rtt, "", hopTags)
+			}
+			for _, jitter := range jitters(hop.rtts[ip]) {
+				sender.Distribution("network_path.hop.jitter", jitter, "", hopTags)
+			}
+		}
+	}
+	sender.Gauge("network_path.path.count", float64(len(health.paths)), "", tags)
+
+	if c.previousPaths == nil {
+		c.previousPaths = make(map[payload.Protocol][][]string)
+	}
+	if len(health.paths) == 0 {
+		return
+	}
+	previousPaths, ok := c.previousPaths[protocol]
+	if ok && samePaths(previousPaths, health.paths) {
+		return
+	}
+	if ok {
+		sender.Event(c.buildPathChangeEvent(protocol, previousPaths, health.paths, tags))
+	}
+	c.previousPaths[protocol] = health.paths
+}
+
+// samePaths returns whether every path of a set matches a path of the other set, hops which didn't
+// respond matching any hop so that probes lost along a path aren't reported as path changes
+func samePaths(previous [][]string, current [][]string) bool {
+	containsMatch := func(paths [][]string, path []string) bool {
+		return slices.ContainsFunc(paths, func(other []string) bool { return routesMatch(other, path) })
+	}
+	for _, path := range current {
+		if !containsMatch(previous, path) {
+			return false
+		}
+	}
+	for _, path := range previous {
+		if !containsMatch(current, path) {
+			return false
+		}
+	}
+	return true
+}
+
+func routesMatch(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] && a[i] != unknownHop && b[i] != unknownHop {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Check) buildPathChangeEvent(protocol payload.Protocol, previous [][]string, current [][]string, tags []string) event.Event {
+	var text strings.Builder
+	text.WriteString("%%% \n")
+	text.WriteString("Previous paths:\n")
+	for _, path := range previous {
+		fmt.Fprintf(&text, "- %s\n", strings.Join(path, " > "))
+	}
+	text.WriteString("\nCurrent paths:\n")
+	for _, path := range current {
+		fmt.Fprintf(&text, "- %s\n", strings.Join(path, " > "))
+	}
+	text.WriteString("\n %%%")
+
+	return event.Event{
+		Title:          fmt.Sprintf("Network path to %s (%s) changed", c.config.DestHostname, protocol),
+		Text:           text.String(),
+		Ts:             time.Now().Unix(),
+		Priority:       event.PriorityNormal,
+		Tags:           tags,
+		AlertType:      event.AlertTypeInfo,
+		AggregationKey: fmt.Sprintf("%s:%d:%s", c.config.DestHostname, c.config.DestPort, protocol),
+		SourceTypeName: pathChangeEventSource,
+		EventType:      pathChangeEventType,
+	}
+}
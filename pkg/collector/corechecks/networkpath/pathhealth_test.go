@@ -0,0 +1,130 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package networkpath
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics/event"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/payload"
+)
+
+// testPath builds a network path with a traceroute run per route, an empty IP standing for a hop without response
+func testPath(routes ...[]string) payload.NetworkPath {
+	var path payload.NetworkPath
+	for i, route := range routes {
+		var run payload.TracerouteRun
+		for ttl, ip := range route {
+			hop := payload.TracerouteHop{TTL: ttl + 1}
+			if ip != "" {
+				hop.IPAddress = net.ParseIP(ip)
+				hop.RTT = float64(10*(ttl+1) + i)
+				hop.Reachable = true
+			}
+			run.Hops = append(run.Hops, hop)
+		}
+		path.Traceroute.Runs = append(path.Traceroute.Runs, run)
+	}
+	return path
+}
+
+func TestNewPathHealth(t *testing.T) {
+	health := newPathHealth([]payload.NetworkPath{
+		testPath([]string{"10.0.0.1", "10.0.1.1", "8.8.8.8"}, []string{"10.0.0.1", "", "8.8.8.8"}),
+		testPath([]string{"10.0.0.1", "10.0.2.1", "8.8.8.8"}),
+	})
+
+	// the route with a lost probe matches a known path
+	assert.Equal(t, [][]string{
+		{"10.0.0.1", "10.0.1.1", "8.8.8.8"},
+		{"10.0.0.1", "10.0.2.1", "8.8.8.8"},
+	}, health.paths)
+	assert.Len(t, health.hops, 3)
+	assert.Equal(t, map[string][]float64{"10.0.0.1": {10, 11, 10}}, health.hops[0].rtts)
+	assert.Equal(t, map[string][]float64{"10.0.1.1": {20}, "10.0.2.1": {20}}, health.hops[1].rtts)
+	assert.InDelta(t, 0, health.hops[0].lossPercentage(), 0.01)
+	assert.InDelta(t, 33.33, health.hops[1].lossPercentage(), 0.01)
+}
+
+func TestJitters(t *testing.T) {
+	assert.Nil(t, jitters([]float64{10}))
+	assert.Equal(t, []float64{2, 3, 0}, jitters([]float64{10, 12, 9, 9}))
+}
+
+func TestSamePaths(t *testing.T) {
+	path := []string{"10.0.0.1", "10.0.1.1", "8.8.8.8"}
+	assert.True(t, samePaths([][]string{path}, [][]string{path}))
+	// hops without response match any hop
+	assert.True(t, samePaths([][]string{path}, [][]string{{"10.0.0.1", "*", "8.8.8.8"}}))
+	assert.False(t, samePaths([][]string{path}, [][]string{{"10.0.0.1", "10.0.2.1", "8.8.8.8"}}))
+	assert.False(t, samePaths([][]string{path}, [][]string{{"10.0.0.1", "8.8.8.8"}}))
+	// a new ECMP path is a change
+	assert.False(t, samePaths([][]string{path}, [][]string{path, {"10.0.0.1", "10.0.2.1", "8.8.8.8"}}))
+}
+
+func TestSubmitPathHealth(t *testing.T) {
+	check := &Check{config: &CheckConfig{DestHostname: "8.8.8.8", DestPort: 443}}
+	sender := mocksender.NewMockSender("")
+	sender.SetupAcceptAll()
+
+	check.submitPathHealth(sender, payload.ProtocolTCP, []payload.NetworkPath{
+		testPath([]string{"10.0.0.1", "8.8.8.8"}, []string{"10.0.0.1", ""}),
+	}, []string{"foo:bar"})
+
+	tags := []string{"foo:bar", "protocol:tcp", "destination_hostname:8.8.8.8", "destination_port:443"}
+	sender.AssertMetric(t, "Gauge", "network_path.hop.packet_loss_percentage", 0, "", append(tags, "hop_ttl:1"))
+	sender.AssertMetric(t, "Gauge", "network_path.hop.packet_loss_percentage", 50, "", append(tags, "hop_ttl:2"))
+	sender.AssertMetric(t, "Distribution", "network_path.hop.rtt", 10, "", append(tags, "hop_ttl:1", "hop_ip:10.0.0.1"))
+	sender.AssertMetric(t, "Distribution", "network_path.hop.rtt", 11, "", append(tags, "hop_ttl:1", "hop_ip:10.0.0.1"))
+	sender.AssertMetric(t, "Distribution", "network_path.hop.jitter", 1, "", append(tags, "hop_ttl:1", "hop_ip:10.0.0.1"))
+	sender.AssertMetric(t, "Distribution", "network_path.hop.rtt", 20, "", append(tags, "hop_ttl:2", "hop_ip:8.8.8.8"))
+	sender.AssertMetric(t, "Gauge", "network_path.path.count", 1, "", tags)
+	// the first run doesn't report a path change
+	sender.AssertNotCalled(t, "Event", mock.Anything)
+
+	// a lost probe isn't a path change
+	sender.ResetCalls()
+	check.submitPathHealth(sender, payload.ProtocolTCP, []payload.NetworkPath{
+		testPath([]string{"10.0.0.1", ""}),
+	}, []string{"foo:bar"})
+	sender.AssertNotCalled(t, "Event", mock.Anything)
+
+	sender.ResetCalls()
+	check.submitPathHealth(sender, payload.ProtocolTCP, []payload.NetworkPath{
+		testPath([]string{"10.0.0.2", "8.8.8.8"}),
+	}, []string{"foo:bar"})
+	sender.AssertEventWithCompareFunc(t, event.Event{
+		Title: "Network path to 8.8.8.8 (TCP) changed",
+		Text: "%%% \n" +
+			"Previous paths:\n" +
+			"- 10.0.0.1 > 8.8.8.8\n" +
+			"\nCurrent paths:\n" +
+			"- 10.0.0.2 > 8.8.8.8\n" +
+			"\n %%%",
+		Ts:             time.Now().Unix(),
+		Priority:       event.PriorityNormal,
+		Tags:           tags,
+		AlertType:      event.AlertTypeInfo,
+		AggregationKey: "8.8.8.8:443:TCP",
+		SourceTypeName: "network_path",
+		EventType:      "network_path.path_change",
+	}, 10*time.Second, func(expected, actual event.Event) bool {
+		return expected.Title == actual.Title && expected.Text == actual.Text
+	})
+
+	// paths are tracked per protocol
+	sender.ResetCalls()
+	check.submitPathHealth(sender, payload.ProtocolUDP, []payload.NetworkPath{
+		testPath([]string{"10.0.0.3", "8.8.8.8"}),
+	}, []string{"foo:bar"})
+	sender.AssertNotCalled(t, "Event", mock.Anything)
+}
@@ -9,6 +9,6 @@ package oracle
 
 func (c *Check) connectionTest() error {
 	var n float64
-	err := c.db.Get(&n, "SELECT 1 FROM dual")
+	err := c.db.GetContext(c.RunContext(), &n, "SELECT 1 FROM dual")
 	return err
 }
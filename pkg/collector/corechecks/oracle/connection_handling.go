@@ -10,6 +10,7 @@ package oracle
 import (
 	"fmt"
 
+	"github.com/DataDog/datadog-agent/pkg/collector/check/fixtures"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/oracle/common"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/jmoiron/sqlx"
@@ -63,12 +64,18 @@ func (c *Check) Connect() (*sqlx.DB, error) {
 
 	log.Infof("%s driver: %s", c.logPrompt, oracleDriver)
 
-	db, err := sqlx.Open(oracleDriver, connStr)
+	// When recording or replaying fixtures, the queries go through a wrapper of the driver
+	driverName := fixtures.SQLDriver(oracleDriver)
+	if driverName != oracleDriver {
+		sqlx.BindDriver(driverName, sqlx.BindType(oracleDriver))
+	}
+
+	db, err := sqlx.Open(driverName, connStr)
 	if err != nil {
 		_, err := handleRefusedConnection(c, db, err)
 		return nil, fmt.Errorf("failed to connect to oracle instance: %w", err)
 	}
-	err = db.Ping()
+	err = db.PingContext(c.RunContext())
 	if err != nil {
 		_, err := handleRefusedConnection(c, db, err)
 		return nil, fmt.Errorf("failed to ping oracle instance: %w", err)
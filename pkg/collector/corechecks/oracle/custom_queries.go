@@ -93,14 +93,14 @@ func (c *Check) CustomQueries() error {
 			if pdb == "" {
 				pdb = "cdb$root"
 			}
-			_, err := c.dbCustomQueries.Exec(fmt.Sprintf("alter session set container = %s", pdb))
+			_, err := c.dbCustomQueries.ExecContext(c.RunContext(), fmt.Sprintf("alter session set container = %s", pdb))
 			if err != nil {
 				allErrors = concatenateError(allErrors, fmt.Sprintf("failed to set container %s %s", pdb, err))
 				reconnectOnConnectionError(c, &c.dbCustomQueries, err)
 				continue
 			}
 		}
-		rows, err := c.dbCustomQueries.Queryx(q.Query)
+		rows, err := c.dbCustomQueries.QueryxContext(c.RunContext(), q.Query)
 		if rows != nil {
 			defer rows.Close()
 		}
@@ -18,6 +18,7 @@ import (
 	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/check/fixtures"
 	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/oracle/common"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/oracle/config"
@@ -189,7 +190,8 @@ func (c *Check) Run() error {
 		}
 	}
 
-	if c.driver == "oracle" && c.connection == nil {
+	// the go-ora connection bypasses database/sql, so it isn't opened with fixtures
+	if c.driver == "oracle" && c.connection == nil && fixtures.Current() == nil {
 		conn, err := connectGoOra(c)
 		if err != nil {
 			return fmt.Errorf("%s failed to connect with go-ora %w", c.logPrompt, err)
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/DataDog/datadog-agent/pkg/collector/check/fixtures"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/oracle/common"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	go_ora "github.com/sijms/go-ora/v2"
@@ -23,7 +24,12 @@ func getFullSQLText(c *Check, SQLStatement *string, key string, value string) er
 	 */
 	var err error
 	var sql string
-	switch c.driver {
+	driver := c.driver
+	if c.connection == nil && fixtures.Current() != nil {
+		// the go-ora connection isn't opened with fixtures, the text is queried through database/sql
+		driver = common.Godror
+	}
+	switch driver {
 	case common.Godror:
 		sql = fmt.Sprintf("SELECT /* DD */ sql_fulltext FROM v$sql WHERE %s = :v AND rownum = 1", key)
 		err = c.db.Get(SQLStatement, sql, value)
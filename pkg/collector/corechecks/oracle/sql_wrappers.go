@@ -24,7 +24,7 @@ func selectWrapper[T any](c *Check, s T, sql string, binds ...interface{}) error
 			return err
 		}
 	}
-	err := c.db.Select(s, sql, binds...)
+	err := c.db.SelectContext(c.RunContext(), s, sql, binds...)
 	err = handleError(c, &c.db, err)
 	if err != nil {
 		err = fmt.Errorf("%w %s", err, sql)
@@ -39,7 +39,7 @@ func getWrapper[T any](c *Check, s T, sql string, binds ...interface{}) error {
 			return err
 		}
 	}
-	err := c.db.Get(s, sql, binds...)
+	err := c.db.GetContext(c.RunContext(), s, sql, binds...)
 	err = handleError(c, &c.db, err)
 	if err != nil {
 		err = fmt.Errorf("%w %s", err, sql)
@@ -218,6 +218,13 @@ func (c *Check) Run() error {
 	metricTicker := time.NewTicker(metricPeriod)
 	defer metricTicker.Stop()
 
+	var vulnRefreshCh <-chan time.Time // default value to listen to nothing
+	if c.processor.vulnMatcher != nil {
+		vulnRefreshTicker := time.NewTicker(c.cfg.GetDuration("sbom.vulnerabilities.refresh_interval"))
+		defer vulnRefreshTicker.Stop()
+		vulnRefreshCh = vulnRefreshTicker.C
+	}
+
 	defer c.processor.stop()
 	for {
 		select {
@@ -242,6 +249,8 @@ func (c *Check) Run() error {
 			c.processor.triggerHostScan()
 		case <-metricTicker.C:
 			c.sendUsageMetrics()
+		case <-vulnRefreshCh:
+			c.processor.refreshVulnerabilityDatabase()
 		case <-c.stopCh:
 			return nil
 		}
@@ -28,6 +28,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/sbom/bomconvert"
 	"github.com/DataDog/datadog-agent/pkg/sbom/collectors/host"
 	"github.com/DataDog/datadog-agent/pkg/sbom/collectors/procfs"
+	"github.com/DataDog/datadog-agent/pkg/sbom/osv"
 	sbomscanner "github.com/DataDog/datadog-agent/pkg/sbom/scanner"
 	queue "github.com/DataDog/datadog-agent/pkg/util/aggregatingqueue"
 	"github.com/DataDog/datadog-agent/pkg/util/fargate"
@@ -53,6 +54,9 @@ type processor struct {
 	imageRepoDigests      map[string]string              // Map where keys are image repo digest and values are image ID
 	imageUsers            map[string]map[string]struct{} // Map where keys are image repo digest and values are set of container IDs
 	sbomScanner           *sbomscanner.Scanner
+	sender                sender.Sender
+	vulnMatcher           *osv.Matcher
+	reportedVulns         map[string]map[string]struct{} // Map where keys are entity IDs and values are set of reported vulnerabilities
 	contImageSBOM         bool
 	hostSBOM              bool
 	procfsSBOM            bool
@@ -102,6 +106,9 @@ func newProcessor(workloadmetaStore workloadmeta.Component, filterStore workload
 		imageRepoDigests:      make(map[string]string),
 		imageUsers:            make(map[string]map[string]struct{}),
 		sbomScanner:           sbomScanner,
+		sender:                sender,
+		vulnMatcher:           newVulnerabilityMatcher(cfg),
+		reportedVulns:         make(map[string]map[string]struct{}),
 		contImageSBOM:         contImageSBOM,
 		hostSBOM:              hostSBOM,
 		procfsSBOM:            procfsSBOM,
@@ -181,6 +188,7 @@ func (p *processor) registerImage(img *workloadmeta.ContainerImageMetadata) {
 }
 
 func (p *processor) unregisterImage(img *workloadmeta.ContainerImageMetadata) {
+	p.forgetVulnerabilities(img.ID)
 	for _, repoDigest := range img.RepoDigests {
 		delete(p.imageUsers, repoDigest)
 		if p.imageRepoDigests[repoDigest] == img.ID {
@@ -245,6 +253,7 @@ func (p *processor) processHostScanResult(result sbom.ScanResult) {
 		sbom.Status = model.SBOMStatus_FAILED
 	} else {
 		log.Infof("Successfully generated SBOM for host: %v, %v", result.CreatedAt, result.Duration)
+		p.reportVulnerabilities(p.hostname, nil, result.Report.ToCycloneDX())
 
 		if p.hostCache != "" && p.hostCache == result.Report.ID() && result.CreatedAt.Sub(p.hostLastFullSBOM) < p.hostHeartbeatValidity {
 			sbom.Heartbeat = true
@@ -366,6 +375,8 @@ func (p *processor) processImageSBOM(img *workloadmeta.ContainerImageMetadata) {
 	cyclosbom, err := sbomutil.UncompressSBOM(img.SBOM)
 	if err != nil {
 		log.Errorf("Failed to uncompress SBOM for image %s: %v", img.ID, err)
+	} else if cyclosbom.Status == workloadmeta.Success {
+		p.reportVulnerabilities(img.ID, ddTags, cyclosbom.CycloneDXBOM)
 	}
 
 	for repo := range repos {
@@ -0,0 +1,151 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+//go:build trivy || (windows && wmi)
+
+package sbom
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/DataDog/agent-payload/v5/cyclonedx_v1_4"
+
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics/event"
+	"github.com/DataDog/datadog-agent/pkg/sbom/osv"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	vulnerabilitiesMetric    = "datadog.agent.sbom.vulnerabilities"
+	vulnerabilityEventType   = "sbom_vulnerability"
+	vulnerabilityEventSource = "sbom"
+	// maxVulnerabilitiesPerEvent bounds the size of the events text
+	maxVulnerabilitiesPerEvent = 50
+)
+
+// newVulnerabilityMatcher loads the offline vulnerability database, if enabled.
+// The SBOM check keeps running without vulnerability matching if the database cannot be loaded.
+func newVulnerabilityMatcher(cfg config.Component) *osv.Matcher {
+	if !cfg.GetBool("sbom.vulnerabilities.enabled") {
+		return nil
+	}
+
+	matcher, err := osv.NewMatcher(cfg.GetString("sbom.vulnerabilities.db_path"))
+	if err != nil {
+		log.Errorf("Unable to load the vulnerability database, vulnerabilities will not be reported: %v", err)
+		return nil
+	}
+
+	return matcher
+}
+
+func (p *processor) refreshVulnerabilityDatabase() {
+	if p.vulnMatcher == nil {
+		return
+	}
+
+	if _, err := p.vulnMatcher.Refresh(); err != nil {
+		log.Warnf("Unable to refresh the vulnerability database: %v", err)
+	}
+}
+
+// reportVulnerabilities matches an SBOM against the vulnerability database. It sends the number of
+// vulnerabilities by severity as metrics, and an event listing the vulnerabilities which were not
+// reported yet for this entity.
+func (p *processor) reportVulnerabilities(entityID string, tags []string, bom *cyclonedx_v1_4.Bom) {
+	if p.vulnMatcher == nil || bom == nil {
+		return
+	}
+
+	findings := p.vulnMatcher.MatchBOM(bom)
+
+	counts := make(map[osv.SeverityLevel]int, len(osv.SeverityLevels))
+	for _, finding := range findings {
+		counts[finding.Severity]++
+	}
+	for _, level := range osv.SeverityLevels {
+		p.sender.Gauge(vulnerabilitiesMetric, float64(counts[level]), "", append(slices.Clone(tags), "severity:"+string(level)))
+	}
+
+	reported := p.reportedVulns[entityID]
+	current := make(map[string]struct{}, len(findings))
+	var newFindings []osv.Finding
+	for _, finding := range findings {
+		key := finding.VulnerabilityID + "/" + finding.PackageName
+		if _, found := current[key]; found {
+			continue
+		}
+		current[key] = struct{}{}
+
+		if _, found := reported[key]; !found {
+			newFindings = append(newFindings, finding)
+		}
+	}
+	// Vulnerabilities which are no longer found are forgotten so that they are reported again if they reappear
+	p.reportedVulns[entityID] = current
+
+	if len(newFindings) > 0 {
+		p.sender.Event(buildVulnerabilityEvent(entityID, tags, newFindings))
+	}
+
+	p.sender.Commit()
+}
+
+func (p *processor) forgetVulnerabilities(entityID string) {
+	delete(p.reportedVulns, entityID)
+}
+
+func buildVulnerabilityEvent(entityID string, tags []string, findings []osv.Finding) event.Event {
+	slices.SortStableFunc(findings, func(a, b osv.Finding) int {
+		switch {
+		case a.Severity.MoreSevereThan(b.Severity):
+			return -1
+		case b.Severity.MoreSevereThan(a.Severity):
+			return 1
+		default:
+			return strings.Compare(a.VulnerabilityID, b.VulnerabilityID)
+		}
+	})
+
+	var text strings.Builder
+	text.WriteString("%%% \n")
+	for i, finding := range findings {
+		if i == maxVulnerabilitiesPerEvent {
+			fmt.Fprintf(&text, "- and %d more\n", len(findings)-i)
+			break
+		}
+
+		fmt.Fprintf(&text, "- **%s** (%s): %s %s", finding.VulnerabilityID, finding.Severity, finding.PackageName, finding.PackageVersion)
+		if finding.FixedVersion != "" {
+			fmt.Fprintf(&text, ", fixed in %s", finding.FixedVersion)
+		}
+		text.WriteString("\n")
+	}
+	text.WriteString("\n %%%")
+
+	alertType := event.AlertTypeInfo
+	switch findings[0].Severity {
+	case osv.SeverityCritical, osv.SeverityHigh:
+		alertType = event.AlertTypeError
+	case osv.SeverityMedium:
+		alertType = event.AlertTypeWarning
+	}
+
+	return event.Event{
+		Title:          fmt.Sprintf("%d new vulnerabilities found in %s", len(findings), entityID),
+		Text:           text.String(),
+		Ts:             time.Now().Unix(),
+		Priority:       event.PriorityNormal,
+		Tags:           tags,
+		AlertType:      alertType,
+		AggregationKey: entityID,
+		SourceTypeName: vulnerabilityEventSource,
+		EventType:      vulnerabilityEventType,
+	}
+}
@@ -0,0 +1,96 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+//go:build trivy || windows
+
+package sbom
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DataDog/agent-payload/v5/cyclonedx_v1_4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	configcomp "github.com/DataDog/datadog-agent/comp/core/config"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics/event"
+	"github.com/DataDog/datadog-agent/pkg/util/pointer"
+)
+
+const testOSVEntry = `{
+  "id": "GHSA-j8r2-6x86-q33q",
+  "database_specific": {"severity": "MODERATE"},
+  "affected": [
+    {
+      "package": {"ecosystem": "PyPI", "name": "requests"},
+      "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "2.3.0"}, {"fixed": "2.31.0"}]}]
+    }
+  ]
+}`
+
+func TestReportVulnerabilities(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "osv")
+	require.NoError(t, os.MkdirAll(dbPath, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dbPath, "GHSA-j8r2-6x86-q33q.json"), []byte(testOSVEntry), 0644))
+
+	cfg := configcomp.NewMockWithOverrides(t, map[string]interface{}{
+		"sbom.vulnerabilities.enabled": true,
+		"sbom.vulnerabilities.db_path": dbPath,
+	})
+
+	sender := mocksender.NewMockSender("")
+	sender.SetupAcceptAll()
+
+	p := &processor{
+		sender:        sender,
+		vulnMatcher:   newVulnerabilityMatcher(cfg),
+		reportedVulns: make(map[string]map[string]struct{}),
+	}
+	require.NotNil(t, p.vulnMatcher)
+
+	bom := &cyclonedx_v1_4.Bom{
+		Components: []*cyclonedx_v1_4.Component{
+			{Name: "requests", Version: "2.28.1", Purl: pointer.Ptr("pkg:pypi/requests@2.28.1")},
+			{Name: "urllib3", Version: "2.0.7", Purl: pointer.Ptr("pkg:pypi/urllib3@2.0.7")},
+		},
+	}
+	tags := []string{"image_name:app"}
+
+	p.reportVulnerabilities("sha256:1234", tags, bom)
+
+	sender.AssertMetric(t, "Gauge", vulnerabilitiesMetric, 1, "", []string{"image_name:app", "severity:medium"})
+	sender.AssertMetric(t, "Gauge", vulnerabilitiesMetric, 0, "", []string{"image_name:app", "severity:critical"})
+	sender.AssertEvent(t, event.Event{
+		Priority:       event.PriorityNormal,
+		AggregationKey: "sha256:1234",
+		SourceTypeName: vulnerabilityEventSource,
+		EventType:      vulnerabilityEventType,
+		Ts:             time.Now().Unix(),
+	}, time.Minute)
+	sender.AssertNumberOfCalls(t, "Event", 1)
+
+	ev := sender.Calls[len(sender.Calls)-2].Arguments.Get(0).(event.Event)
+	assert.Equal(t, "1 new vulnerabilities found in sha256:1234", ev.Title)
+	assert.Equal(t, event.AlertTypeWarning, ev.AlertType)
+	assert.Contains(t, ev.Text, "- **GHSA-j8r2-6x86-q33q** (medium): requests 2.28.1, fixed in 2.31.0\n")
+	assert.Equal(t, tags, ev.Tags)
+
+	// Already reported vulnerabilities do not generate new events
+	p.reportVulnerabilities("sha256:1234", tags, bom)
+	sender.AssertNumberOfCalls(t, "Event", 1)
+
+	// Fixed vulnerabilities are forgotten
+	bom.Components[0].Version = "2.31.0"
+	bom.Components[0].Purl = pointer.Ptr("pkg:pypi/requests@2.31.0")
+	p.reportVulnerabilities("sha256:1234", tags, bom)
+	assert.Empty(t, p.reportedVulns["sha256:1234"])
+
+	p.forgetVulnerabilities("sha256:1234")
+	assert.NotContains(t, p.reportedVulns, "sha256:1234")
+}
@@ -0,0 +1,238 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package session
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+	"go.uber.org/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check/fixtures"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/checkconfig"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// fixturesKind is the kind of the SNMP interactions in check fixtures
+const fixturesKind = "snmp"
+
+// WithFixtures wraps a session factory to record the responses of the devices into store,
+// or replay them from it instead of reaching the devices (see `agent check --record/--replay`).
+func WithFixtures(factory Factory, store *fixtures.Store) Factory {
+	return func(config *checkconfig.CheckConfig) (Session, error) {
+		s, err := factory(config)
+		if err != nil {
+			return nil, err
+		}
+		return &fixturesSession{
+			Session:          s,
+			store:            store,
+			device:           fmt.Sprintf("%s:%d", config.IPAddress, config.Port),
+			snmpGetCount:     atomic.NewUint32(0),
+			snmpGetBulkCount: atomic.NewUint32(0),
+			snmpGetNextCount: atomic.NewUint32(0),
+		}, nil
+	}
+}
+
+// fixturesSession records the responses of the wrapped session, or replays them without
+// using it
+type fixturesSession struct {
+	Session
+	store  *fixtures.Store
+	device string
+
+	// request counts in replay mode, the wrapped session counts them in record mode
+	snmpGetCount     *atomic.Uint32
+	snmpGetBulkCount *atomic.Uint32
+	snmpGetNextCount *atomic.Uint32
+}
+
+// fixtureResponse is a recorded response
+type fixtureResponse struct {
+	Variables []fixtureVariable `json:"variables,omitempty"`
+	Error     string            `json:"error,omitempty"`
+}
+
+// fixtureVariable is a recorded variable binding. Binary values are encoded in base64 and
+// numbers in decimal.
+type fixtureVariable struct {
+	Name  string         `json:"name"`
+	Type  gosnmp.Asn1BER `json:"type"`
+	Value string         `json:"value,omitempty"`
+}
+
+func (s *fixturesSession) replaying() bool {
+	return s.store.Mode() == fixtures.Replay
+}
+
+// Connect connects the wrapped session, unless replaying
+func (s *fixturesSession) Connect() error {
+	if s.replaying() {
+		return nil
+	}
+	return s.Session.Connect()
+}
+
+// Close closes the wrapped session, unless replaying
+func (s *fixturesSession) Close() error {
+	if s.replaying() {
+		return nil
+	}
+	return s.Session.Close()
+}
+
+// Get will send a SNMPGET command
+func (s *fixturesSession) Get(oids []string) (*gosnmp.SnmpPacket, error) {
+	if s.replaying() {
+		s.snmpGetCount.Inc()
+	}
+	return s.do("get", oids, func() (*gosnmp.SnmpPacket, error) { return s.Session.Get(oids) })
+}
+
+// GetBulk will send a SNMP BULKGET command
+func (s *fixturesSession) GetBulk(oids []string, bulkMaxRepetitions uint32) (*gosnmp.SnmpPacket, error) {
+	if s.replaying() {
+		s.snmpGetBulkCount.Inc()
+	}
+	method := "getbulk/" + strconv.FormatUint(uint64(bulkMaxRepetitions), 10)
+	return s.do(method, oids, func() (*gosnmp.SnmpPacket, error) { return s.Session.GetBulk(oids, bulkMaxRepetitions) })
+}
+
+// GetNext will send a SNMP GETNEXT command
+func (s *fixturesSession) GetNext(oids []string) (*gosnmp.SnmpPacket, error) {
+	if s.replaying() {
+		s.snmpGetNextCount.Inc()
+	}
+	return s.do("getnext", oids, func() (*gosnmp.SnmpPacket, error) { return s.Session.GetNext(oids) })
+}
+
+// GetSnmpGetCount returns the number of SNMPGET request that has been done
+func (s *fixturesSession) GetSnmpGetCount() uint32 {
+	if s.replaying() {
+		return s.snmpGetCount.Load()
+	}
+	return s.Session.GetSnmpGetCount()
+}
+
+// GetSnmpGetBulkCount returns the number of SNMP BULKGET request that has been done
+func (s *fixturesSession) GetSnmpGetBulkCount() uint32 {
+	if s.replaying() {
+		return s.snmpGetBulkCount.Load()
+	}
+	return s.Session.GetSnmpGetBulkCount()
+}
+
+// GetSnmpGetNextCount returns the number of SNMP GETNEXT request that has been done
+func (s *fixturesSession) GetSnmpGetNextCount() uint32 {
+	if s.replaying() {
+		return s.snmpGetNextCount.Load()
+	}
+	return s.Session.GetSnmpGetNextCount()
+}
+
+// do records the response of a request, or replays it
+func (s *fixturesSession) do(method string, oids []string, request func() (*gosnmp.SnmpPacket, error)) (*gosnmp.SnmpPacket, error) {
+	key := s.device + " " + method + " " + strings.Join(oids, ",")
+
+	if s.replaying() {
+		var resp fixtureResponse
+		if err := s.store.Replay(fixturesKind, key, &resp); err != nil {
+			return nil, err
+		}
+		if resp.Error != "" {
+			return nil, errors.New(resp.Error)
+		}
+		packet := &gosnmp.SnmpPacket{Version: s.GetVersion(), Variables: make([]gosnmp.SnmpPDU, 0, len(resp.Variables))}
+		for _, v := range resp.Variables {
+			pdu, err := v.decode()
+			if err != nil {
+				return nil, fmt.Errorf("invalid recorded variable %s: %w", v.Name, err)
+			}
+			packet.Variables = append(packet.Variables, pdu)
+		}
+		return packet, nil
+	}
+
+	packet, err := request()
+	var resp fixtureResponse
+	if err != nil {
+		resp.Error = err.Error()
+	} else if packet != nil {
+		for _, pdu := range packet.Variables {
+			resp.Variables = append(resp.Variables, encodeFixtureVariable(pdu))
+		}
+	}
+	if recordErr := s.store.Record(fixturesKind, key, resp); recordErr != nil {
+		log.Warnf("Unable to record the SNMP response of %s: %s", s.device, recordErr)
+	}
+	return packet, err
+}
+
+func encodeFixtureVariable(pdu gosnmp.SnmpPDU) fixtureVariable {
+	v := fixtureVariable{Name: pdu.Name, Type: pdu.Type}
+	switch value := pdu.Value.(type) {
+	case nil:
+	case []byte:
+		v.Value = base64.StdEncoding.EncodeToString(value)
+	case string:
+		v.Value = value
+	case float32:
+		v.Value = strconv.FormatFloat(float64(value), 'g', -1, 32)
+	case float64:
+		v.Value = strconv.FormatFloat(value, 'g', -1, 64)
+	default:
+		v.Value = gosnmp.ToBigInt(value).String()
+	}
+	return v
+}
+
+// decode returns the variable binding with a value of the Go type gosnmp decodes its type to
+func (v fixtureVariable) decode() (gosnmp.SnmpPDU, error) {
+	pdu := gosnmp.SnmpPDU{Name: v.Name, Type: v.Type}
+	switch v.Type {
+	case gosnmp.Null, gosnmp.NoSuchObject, gosnmp.NoSuchInstance, gosnmp.EndOfMibView:
+		return pdu, nil
+	case gosnmp.OctetString, gosnmp.BitString, gosnmp.Opaque:
+		value, err := base64.StdEncoding.DecodeString(v.Value)
+		pdu.Value = value
+		return pdu, err
+	case gosnmp.ObjectIdentifier, gosnmp.IPAddress:
+		pdu.Value = v.Value
+		return pdu, nil
+	case gosnmp.OpaqueFloat:
+		value, err := strconv.ParseFloat(v.Value, 32)
+		pdu.Value = float32(value)
+		return pdu, err
+	case gosnmp.OpaqueDouble:
+		value, err := strconv.ParseFloat(v.Value, 64)
+		pdu.Value = value
+		return pdu, err
+	}
+
+	value, ok := new(big.Int).SetString(v.Value, 10)
+	if !ok {
+		return pdu, fmt.Errorf("invalid %s value %q", v.Type, v.Value)
+	}
+	switch v.Type {
+	case gosnmp.Integer:
+		pdu.Value = int(value.Int64())
+	case gosnmp.Counter32, gosnmp.Gauge32:
+		pdu.Value = uint(value.Uint64())
+	case gosnmp.TimeTicks, gosnmp.Uinteger32:
+		pdu.Value = uint32(value.Uint64())
+	case gosnmp.Counter64:
+		pdu.Value = value.Uint64()
+	default:
+		return pdu, fmt.Errorf("unsupported type %s", v.Type)
+	}
+	return pdu, nil
+}
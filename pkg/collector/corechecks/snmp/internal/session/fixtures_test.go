@@ -0,0 +1,72 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build test
+
+package session
+
+import (
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check/fixtures"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/checkconfig"
+)
+
+func TestWithFixtures(t *testing.T) {
+	dir := t.TempDir()
+	config := &checkconfig.CheckConfig{IPAddress: "10.0.0.1", Port: 161}
+
+	device := CreateFakeSession()
+	device.SetMany(
+		gosnmp.SnmpPDU{Name: "1.3.6.1.2.1.1.1.0", Type: gosnmp.OctetString, Value: []byte("my device\x00")},
+		gosnmp.SnmpPDU{Name: "1.3.6.1.2.1.1.2.0", Type: gosnmp.ObjectIdentifier, Value: "1.3.6.1.4.1.9.1.1"},
+		gosnmp.SnmpPDU{Name: "1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(42)},
+		gosnmp.SnmpPDU{Name: "1.3.6.1.2.1.2.2.1.7.1", Type: gosnmp.Integer, Value: -1},
+		gosnmp.SnmpPDU{Name: "1.3.6.1.2.1.2.2.1.10.1", Type: gosnmp.Counter32, Value: uint(4000000000)},
+		gosnmp.SnmpPDU{Name: "1.3.6.1.2.1.31.1.1.1.6.1", Type: gosnmp.Counter64, Value: uint64(18000000000000000000)},
+		gosnmp.SnmpPDU{Name: "1.3.6.1.2.1.4.20.1.1.1", Type: gosnmp.IPAddress, Value: "10.0.0.1"},
+		gosnmp.SnmpPDU{Name: "1.3.6.1.4.1.2021.10.1.6.1", Type: gosnmp.OpaqueFloat, Value: float32(0.5)},
+	)
+	oids := []string{"1.3.6.1.2.1.1.1.0", "1.3.6.1.2.1.1.2.0", "1.3.6.1.2.1.1.3.0", "1.3.6.1.2.1.1.4.0"}
+
+	// record
+	recorder, err := fixtures.NewRecorder(dir)
+	require.NoError(t, err)
+
+	s, err := WithFixtures(func(*checkconfig.CheckConfig) (Session, error) { return device, nil }, recorder)(config)
+	require.NoError(t, err)
+	require.NoError(t, s.Connect())
+	recordedGet, err := s.Get(oids)
+	require.NoError(t, err)
+	recordedBulk, err := s.GetBulk([]string{"1.3.6.1.2.1.2"}, 10)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), s.GetSnmpGetCount())
+	require.NoError(t, recorder.Save())
+
+	// replay, without any data on the device
+	store, err := fixtures.Load(dir)
+	require.NoError(t, err)
+
+	s, err = WithFixtures(func(*checkconfig.CheckConfig) (Session, error) { return CreateFakeSession(), nil }, store)(config)
+	require.NoError(t, err)
+	require.NoError(t, s.Connect())
+
+	replayedGet, err := s.Get(oids)
+	require.NoError(t, err)
+	assert.Equal(t, recordedGet.Variables, replayedGet.Variables)
+	replayedBulk, err := s.GetBulk([]string{"1.3.6.1.2.1.2"}, 10)
+	require.NoError(t, err)
+	assert.Equal(t, recordedBulk.Variables, replayedBulk.Variables)
+	assert.Equal(t, uint32(1), s.GetSnmpGetCount())
+	assert.Equal(t, uint32(1), s.GetSnmpGetBulkCount())
+
+	_, err = s.GetBulk([]string{"1.3.6.1.2.1.2"}, 20)
+	assert.ErrorIs(t, err, fixtures.ErrNotRecorded)
+	assert.Equal(t, []string{"snmp 10.0.0.1:161 getbulk/20 1.3.6.1.2.1.2"}, store.Missing())
+}
@@ -0,0 +1,214 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package profiletest runs the SNMP check with a profile against a recorded walk, to test
+// profiles without a device at hand.
+package profiletest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/checkconfig"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/devicecheck"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/report"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/session"
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/profile/profiledefinition"
+	"github.com/DataDog/datadog-agent/pkg/snmp/snmprec"
+)
+
+// sysObjectIDOID is the OID of sysObjectID.0, which selects the profile of a device
+const sysObjectIDOID = "1.3.6.1.2.1.1.2.0"
+
+// Metric is a metric submitted by the check
+type Metric struct {
+	Type  string   `json:"type"`
+	Name  string   `json:"name"`
+	Value float64  `json:"value"`
+	Tags  []string `json:"tags"`
+}
+
+// ServiceCheck is a service check submitted by the check
+type ServiceCheck struct {
+	Name    string   `json:"name"`
+	Status  string   `json:"status"`
+	Tags    []string `json:"tags"`
+	Message string   `json:"message,omitempty"`
+}
+
+// Result holds what the check submitted when run with a profile against a walk
+type Result struct {
+	Profile       string            `json:"profile"`
+	SysObjectID   string            `json:"sysobjectid,omitempty"`
+	Metrics       []Metric          `json:"metrics"`
+	ServiceChecks []ServiceCheck    `json:"service_checks"`
+	Metadata      []json.RawMessage `json:"metadata"`
+	// Warnings lists what the profile requests that the walk cannot serve,
+	// e.g. OIDs missing from the walk, or a sysObjectID the profile doesn't match
+	Warnings []string `json:"warnings"`
+}
+
+// Run runs the SNMP check with the profile defined in profilePath against the walk recorded
+// in walkPath. The profile can extend the default profiles.
+func Run(profilePath string, walkPath string, agentConfig config.Component) (*Result, error) {
+	pdus, err := snmprec.ReadFile(walkPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read walk: %w", err)
+	}
+	profilePath, err = filepath.Abs(profilePath)
+	if err != nil {
+		return nil, err
+	}
+	profileName := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(profilePath), ".yaml"), ".yml")
+	// the check only logs the profiles it fails to load, validate it first to report why
+	if err := validateProfile(profilePath); err != nil {
+		return nil, err
+	}
+
+	checkConfig, err := newCheckConfig(profileName, profilePath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid check configuration: %w", err)
+	}
+	if !checkConfig.ProfileProvider.HasProfile(profileName) {
+		return nil, fmt.Errorf("unable to load profile %q from %s", profileName, profilePath)
+	}
+
+	sess := session.CreateFakeSession()
+	sess.SetMany(pdus...)
+	sessionFactory := func(*checkconfig.CheckConfig) (session.Session, error) {
+		return sess, nil
+	}
+	deviceCk, err := devicecheck.NewDeviceCheck(checkConfig, checkConfig.IPAddress, sessionFactory, agentConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create device check: %w", err)
+	}
+	hostname, err := deviceCk.GetDeviceHostname()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device hostname: %w", err)
+	}
+
+	sender := &recordingSender{}
+	deviceCk.SetSender(report.NewMetricSender(sender, hostname, checkConfig.InterfaceConfigs, deviceCk.GetInterfaceBandwidthState()))
+
+	result := &Result{
+		Profile:  profileName,
+		Warnings: []string{},
+	}
+	if err := deviceCk.Run(time.Now()); err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("check run failed: %s", err))
+	}
+
+	sort.SliceStable(sender.metrics, func(i, j int) bool {
+		if sender.metrics[i].Name != sender.metrics[j].Name {
+			return sender.metrics[i].Name < sender.metrics[j].Name
+		}
+		return strings.Join(sender.metrics[i].Tags, ",") < strings.Join(sender.metrics[j].Tags, ",")
+	})
+	result.Metrics = sender.metrics
+	result.ServiceChecks = sender.serviceChecks
+	result.Metadata = sender.metadata
+
+	definition := checkConfig.ProfileProvider.GetProfile(profileName).Definition
+	result.SysObjectID, result.Warnings = checkWalk(&definition, pdus, result.Warnings)
+	return result, nil
+}
+
+func validateProfile(profilePath string) error {
+	buf, err := os.ReadFile(profilePath)
+	if err != nil {
+		return fmt.Errorf("unable to read profile: %w", err)
+	}
+	if err := yaml.Unmarshal(buf, profiledefinition.NewProfileDefinition()); err != nil {
+		return fmt.Errorf("invalid profile %s: %w", profilePath, err)
+	}
+	return nil
+}
+
+// newCheckConfig configures the check to use the profile and collect everything it defines.
+// The address is never reached, the check fetches the walk instead.
+func newCheckConfig(profileName string, profilePath string) (*checkconfig.CheckConfig, error) {
+	rawInstance, err := yaml.Marshal(map[string]any{
+		"ip_address":              "127.0.0.1",
+		"community_string":        "public",
+		"profile":                 profileName,
+		"collect_device_metadata": true,
+		"ping": map[string]any{
+			"enabled": false,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	rawInitConfig, err := yaml.Marshal(map[string]any{
+		"loader": "core",
+		"profiles": map[string]any{
+			profileName: map[string]any{
+				"definition_file": profilePath,
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return checkconfig.NewCheckConfig(rawInstance, rawInitConfig, nil)
+}
+
+// checkWalk returns the sysObjectID of the walk, along with warnings about what the profile
+// requests but the walk doesn't hold
+func checkWalk(definition *profiledefinition.ProfileDefinition, pdus []gosnmp.SnmpPDU, warnings []string) (string, []string) {
+	oids := make([]string, 0, len(pdus))
+	for _, pdu := range pdus {
+		oids = append(oids, pdu.Name)
+	}
+	sort.Strings(oids)
+
+	scalars, columns := definition.SplitOIDs(true)
+	for _, oid := range scalars {
+		oid = strings.TrimLeft(oid, ".")
+		if i := sort.SearchStrings(oids, oid); i == len(oids) || oids[i] != oid {
+			warnings = append(warnings, fmt.Sprintf("scalar OID %s is not in the walk", oid))
+		}
+	}
+	for _, oid := range columns {
+		// the walk is sorted as strings, the rows of a column still share its prefix
+		oid = strings.TrimLeft(oid, ".")
+		prefix := oid + "."
+		if i := sort.SearchStrings(oids, prefix); i == len(oids) || !strings.HasPrefix(oids[i], prefix) {
+			warnings = append(warnings, fmt.Sprintf("column OID %s has no rows in the walk", oid))
+		}
+	}
+
+	var sysObjectID string
+	for _, pdu := range pdus {
+		if pdu.Name == sysObjectIDOID {
+			sysObjectID, _ = pdu.Value.(string)
+			sysObjectID = strings.TrimLeft(sysObjectID, ".")
+		}
+	}
+	if sysObjectID == "" {
+		warnings = append(warnings, fmt.Sprintf("the walk has no sysObjectID (%s)", sysObjectIDOID))
+	} else if len(definition.SysObjectIDs) > 0 && !matchesSysObjectID(definition.SysObjectIDs, sysObjectID) {
+		warnings = append(warnings, fmt.Sprintf("the sysObjectID of the walk %s matches none of the profile sysobjectid patterns %v", sysObjectID, []string(definition.SysObjectIDs)))
+	}
+	return sysObjectID, warnings
+}
+
+func matchesSysObjectID(patterns []string, sysObjectID string) bool {
+	for _, pattern := range patterns {
+		if found, err := filepath.Match(pattern, sysObjectID); err == nil && found {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package profiletest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	agentconfig "github.com/DataDog/datadog-agent/comp/core/config"
+	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
+)
+
+// language=yaml
+const testProfile = `
+sysobjectid: 1.3.6.1.4.1.8072.3.2.*
+metadata:
+  device:
+    fields:
+      name:
+        symbol:
+          OID: 1.3.6.1.2.1.1.5.0
+          name: sysName
+metrics:
+  - MIB: UCD-SNMP-MIB
+    symbol:
+      OID: 1.3.6.1.4.1.2021.10.1.6.1
+      name: laLoadFloat
+  - MIB: UCD-SNMP-MIB
+    symbol:
+      OID: 1.3.6.1.4.1.2021.4.5.0
+      name: memTotalReal
+  - MIB: IF-MIB
+    table:
+      OID: 1.3.6.1.2.1.2.2
+      name: ifTable
+    symbols:
+      - OID: 1.3.6.1.2.1.2.2.1.14
+        name: ifInErrors
+      - OID: 1.3.6.1.2.1.2.2.1.20
+        name: ifOutErrors
+    metric_tags:
+      - tag: interface
+        symbol:
+          OID: 1.3.6.1.2.1.2.2.1.2
+          name: ifDescr
+`
+
+const testWalk = `1.3.6.1.2.1.1.1.0|4|Linux test 5.10
+1.3.6.1.2.1.1.2.0|6|1.3.6.1.4.1.9.1.1
+1.3.6.1.2.1.1.5.0|4|test-device
+1.3.6.1.2.1.2.2.1.2.1|4|eth0
+1.3.6.1.2.1.2.2.1.2.2|4|eth1
+1.3.6.1.2.1.2.2.1.14.1|65|10
+1.3.6.1.2.1.2.2.1.14.2|65|20
+1.3.6.1.4.1.2021.10.1.6.1|68x|9f78043e8a3d71
+`
+
+func writeFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestRun(t *testing.T) {
+	// no default profiles, the test profile is self-contained
+	configmock.New(t).SetWithoutSource("confd_path", t.TempDir())
+
+	result, err := Run(writeFile(t, "test-device.yaml", testProfile), writeFile(t, "test-device.snmprec", testWalk), agentconfig.NewMock(t))
+	require.NoError(t, err)
+
+	assert.Equal(t, "test-device", result.Profile)
+	assert.Equal(t, "1.3.6.1.4.1.9.1.1", result.SysObjectID)
+
+	metrics := map[string]float64{}
+	for _, metric := range result.Metrics {
+		name := metric.Name
+		for _, tag := range metric.Tags {
+			if strings.HasPrefix(tag, "interface:") {
+				name += ":" + tag
+			}
+		}
+		metrics[name] = metric.Value
+	}
+	assert.InDelta(t, 0.27, metrics["snmp.laLoadFloat"], 0.001)
+	assert.Equal(t, float64(10), metrics["snmp.ifInErrors:interface:eth0"])
+	assert.Equal(t, float64(20), metrics["snmp.ifInErrors:interface:eth1"])
+	assert.Equal(t, float64(1), metrics["snmp.devices_monitored"])
+	assert.NotContains(t, metrics, "snmp.memTotalReal")
+
+	require.NotEmpty(t, result.ServiceChecks)
+	assert.Equal(t, "snmp.can_check", result.ServiceChecks[0].Name)
+	assert.Equal(t, "OK", result.ServiceChecks[0].Status)
+	require.Len(t, result.Metadata, 1)
+	assert.Contains(t, string(result.Metadata[0]), `"name":"test-device"`)
+
+	assert.ElementsMatch(t, []string{
+		"scalar OID 1.3.6.1.4.1.2021.4.5.0 is not in the walk",
+		"column OID 1.3.6.1.2.1.2.2.1.20 has no rows in the walk",
+		"the sysObjectID of the walk 1.3.6.1.4.1.9.1.1 matches none of the profile sysobjectid patterns [1.3.6.1.4.1.8072.3.2.*]",
+	}, result.Warnings)
+}
+
+func TestRun_Errors(t *testing.T) {
+	configmock.New(t).SetWithoutSource("confd_path", t.TempDir())
+	walk := writeFile(t, "test-device.snmprec", testWalk)
+
+	_, err := Run(writeFile(t, "test-device.yaml", "metrics: [not a metric]"), walk, agentconfig.NewMock(t))
+	assert.ErrorContains(t, err, "invalid profile")
+
+	_, err = Run(writeFile(t, "test-device.yaml", testProfile), writeFile(t, "bad.snmprec", "1.3.6.1.2.1.1.5.0|4"), agentconfig.NewMock(t))
+	assert.ErrorContains(t, err, "unable to read walk")
+}
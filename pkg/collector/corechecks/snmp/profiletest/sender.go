@@ -0,0 +1,161 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package profiletest
+
+import (
+	"encoding/json"
+	"slices"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/comp/forwarder/eventplatform"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
+	"github.com/DataDog/datadog-agent/pkg/collector/check/stats"
+	"github.com/DataDog/datadog-agent/pkg/metrics/event"
+	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
+	"github.com/DataDog/datadog-agent/pkg/serializer/types"
+)
+
+// recordingSender implements sender.Sender by recording what the check submits
+type recordingSender struct {
+	mu            sync.Mutex
+	metrics       []Metric
+	serviceChecks []ServiceCheck
+	metadata      []json.RawMessage
+}
+
+var _ sender.Sender = (*recordingSender)(nil)
+
+func (s *recordingSender) addMetric(metricType string, metric string, value float64, tags []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metrics = append(s.metrics, Metric{
+		Type:  metricType,
+		Name:  metric,
+		Value: value,
+		Tags:  slices.Clone(tags),
+	})
+}
+
+// Commit does nothing, metrics are recorded as soon as they are submitted
+func (s *recordingSender) Commit() {}
+
+// Gauge records a gauge
+func (s *recordingSender) Gauge(metric string, value float64, _ string, tags []string) {
+	s.addMetric("gauge", metric, value, tags)
+}
+
+// GaugeNoIndex records a gauge
+func (s *recordingSender) GaugeNoIndex(metric string, value float64, _ string, tags []string) {
+	s.addMetric("gauge", metric, value, tags)
+}
+
+// Rate records a rate
+func (s *recordingSender) Rate(metric string, value float64, _ string, tags []string) {
+	s.addMetric("rate", metric, value, tags)
+}
+
+// Count records a count
+func (s *recordingSender) Count(metric string, value float64, _ string, tags []string) {
+	s.addMetric("count", metric, value, tags)
+}
+
+// MonotonicCount records a monotonic count
+func (s *recordingSender) MonotonicCount(metric string, value float64, _ string, tags []string) {
+	s.addMetric("monotonic_count", metric, value, tags)
+}
+
+// MonotonicCountWithFlushFirstValue records a monotonic count
+func (s *recordingSender) MonotonicCountWithFlushFirstValue(metric string, value float64, _ string, tags []string, _ bool) {
+	s.addMetric("monotonic_count", metric, value, tags)
+}
+
+// Counter records a counter
+func (s *recordingSender) Counter(metric string, value float64, _ string, tags []string) {
+	s.addMetric("counter", metric, value, tags)
+}
+
+// Histogram records a histogram
+func (s *recordingSender) Histogram(metric string, value float64, _ string, tags []string) {
+	s.addMetric("histogram", metric, value, tags)
+}
+
+// Historate records a historate
+func (s *recordingSender) Historate(metric string, value float64, _ string, tags []string) {
+	s.addMetric("historate", metric, value, tags)
+}
+
+// Distribution records a distribution
+func (s *recordingSender) Distribution(metric string, value float64, _ string, tags []string) {
+	s.addMetric("distribution", metric, value, tags)
+}
+
+// ServiceCheck records a service check
+func (s *recordingSender) ServiceCheck(checkName string, status servicecheck.ServiceCheckStatus, _ string, tags []string, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.serviceChecks = append(s.serviceChecks, ServiceCheck{
+		Name:    checkName,
+		Status:  status.String(),
+		Tags:    slices.Clone(tags),
+		Message: message,
+	})
+}
+
+// HistogramBucket records a histogram bucket
+func (s *recordingSender) HistogramBucket(metric string, value int64, _, _ float64, _ bool, _ string, tags []string, _ bool) {
+	s.addMetric("histogram_bucket", metric, float64(value), tags)
+}
+
+// GaugeWithTimestamp records a gauge, ignoring its timestamp
+func (s *recordingSender) GaugeWithTimestamp(metric string, value float64, _ string, tags []string, _ float64) error {
+	s.addMetric("gauge", metric, value, tags)
+	return nil
+}
+
+// CountWithTimestamp records a count, ignoring its timestamp
+func (s *recordingSender) CountWithTimestamp(metric string, value float64, _ string, tags []string, _ float64) error {
+	s.addMetric("count", metric, value, tags)
+	return nil
+}
+
+// Event ignores events, which profiles don't send
+func (s *recordingSender) Event(_ event.Event) {}
+
+// EventPlatformEvent records the network devices metadata payloads and ignores the other events
+func (s *recordingSender) EventPlatformEvent(rawEvent []byte, eventType string) {
+	if eventType != eventplatform.EventTypeNetworkDevicesMetadata {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metadata = append(s.metadata, json.RawMessage(slices.Clone(rawEvent)))
+}
+
+// GetSenderStats returns empty stats
+func (s *recordingSender) GetSenderStats() stats.SenderStats {
+	return stats.NewSenderStats()
+}
+
+// DisableDefaultHostname does nothing
+func (s *recordingSender) DisableDefaultHostname(_ bool) {}
+
+// SetCheckCustomTags does nothing
+func (s *recordingSender) SetCheckCustomTags(_ []string) {}
+
+// SetCheckService does nothing
+func (s *recordingSender) SetCheckService(_ string) {}
+
+// SetNoIndex does nothing
+func (s *recordingSender) SetNoIndex(_ bool) {}
+
+// FinalizeCheckServiceTag does nothing
+func (s *recordingSender) FinalizeCheckServiceTag() {}
+
+// OrchestratorMetadata does nothing
+func (s *recordingSender) OrchestratorMetadata(_ []types.ProcessMessageBody, _ string, _ int) {}
+
+// OrchestratorManifest does nothing
+func (s *recordingSender) OrchestratorManifest(_ []types.ProcessMessageBody, _ string) {}
@@ -21,6 +21,7 @@ import (
 	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/check/fixtures"
 	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/checkconfig"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/common"
@@ -212,10 +213,15 @@ func Factory(agentConfig config.Component, rcClient rcclient.Component) option.O
 }
 
 func newCheck(agentConfig config.Component, rcClient rcclient.Component) check.Check {
+	sessionFactory := session.NewGosnmpSession
+	// the responses of the devices are only recorded or replayed by `agent check --record/--replay`
+	if store := fixtures.Current(); store != nil {
+		sessionFactory = session.WithFixtures(sessionFactory, store)
+	}
 	return &Check{
 		rcClient:                   rcClient,
 		CheckBase:                  core.NewCheckBase(common.SnmpIntegrationName),
-		sessionFactory:             session.NewGosnmpSession,
+		sessionFactory:             sessionFactory,
 		workerRunDeviceCheckErrors: atomic.NewUint64(0),
 		agentConfig:                agentConfig,
 	}
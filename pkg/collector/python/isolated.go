@@ -0,0 +1,37 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build python
+
+package python
+
+import (
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	tagger "github.com/DataDog/datadog-agent/comp/core/tagger/def"
+	workloadfilter "github.com/DataDog/datadog-agent/comp/core/workloadfilter/def"
+	integrations "github.com/DataDog/datadog-agent/comp/logs/integrations/def"
+	"github.com/DataDog/datadog-agent/pkg/collector/python/isolation"
+	"github.com/DataDog/datadog-agent/pkg/util/option"
+)
+
+// RunIsolatedCheckWorker runs the worker process of a Python check instance configured with
+// `isolation: process`: it connects to the agent listening on socketPath, then loads and runs
+// the check in its own interpreter until the agent disconnects.
+func RunIsolatedCheckWorker(socketPath string, tagger tagger.Component, filterStore workloadfilter.Component) error {
+	worker, err := isolation.Dial(socketPath)
+	if err != nil {
+		return err
+	}
+
+	pythonOnce.Do(func() {
+		InitPython(common.GetPythonPaths()...)
+	})
+	// Logs sent by the integration aren't forwarded to the agent
+	loader, err := NewPythonCheckLoader(worker.SenderManager(), option.None[integrations.Component](), tagger, filterStore)
+	if err != nil {
+		return err
+	}
+	return worker.Serve(loader.Load)
+}
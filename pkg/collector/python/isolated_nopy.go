@@ -0,0 +1,20 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !python
+
+package python
+
+import (
+	"errors"
+
+	tagger "github.com/DataDog/datadog-agent/comp/core/tagger/def"
+	workloadfilter "github.com/DataDog/datadog-agent/comp/core/workloadfilter/def"
+)
+
+// RunIsolatedCheckWorker returns an error when the build tag is not set
+func RunIsolatedCheckWorker(_ string, _ tagger.Component, _ workloadfilter.Component) error {
+	return errors.New("python checks are not supported by this build")
+}
@@ -0,0 +1,345 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package isolation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+	diagnose "github.com/DataDog/datadog-agent/comp/core/diagnose/def"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/check/defaults"
+	checkid "github.com/DataDog/datadog-agent/pkg/collector/check/id"
+	"github.com/DataDog/datadog-agent/pkg/collector/check/stats"
+	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
+	"github.com/DataDog/datadog-agent/pkg/config/utils"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Check is a check instance run in a supervised worker process. The worker is started when
+// the check is configured, and restarted with an exponential backoff when it crashes, exceeds
+// its resource limits or times out.
+type Check struct {
+	senderManager sender.SenderManager
+	name          string
+	loader        string
+	command       commandFunc
+
+	// set by Configure
+	id             checkid.ID
+	interval       time.Duration
+	source         string
+	initConfig     string
+	instanceConfig string
+	telemetry      bool
+	settings       Settings
+
+	mu           sync.Mutex // protects the fields below
+	worker       *process
+	version      string
+	haSupported  bool
+	restarts     int // consecutive failures of the worker
+	restartAt    time.Time
+	runCtx       context.Context
+	lastWarnings []error
+	cancelled    bool
+}
+
+var (
+	_ check.Check            = (*Check)(nil)
+	_ check.RunContextSetter = (*Check)(nil)
+)
+
+// NewCheck returns an isolated check instance of the check name, loaded by the given loader in
+// the worker process.
+func NewCheck(senderManager sender.SenderManager, name string, loader string) *Check {
+	return &Check{
+		senderManager: senderManager,
+		name:          name,
+		loader:        loader,
+		command:       defaultCommand,
+		interval:      defaults.DefaultCheckInterval,
+		telemetry:     utils.IsCheckTelemetryEnabled(name, pkgconfigsetup.Datadog()),
+	}
+}
+
+// Configure starts the worker and configures the check in it
+func (c *Check) Configure(_ sender.SenderManager, integrationConfigDigest uint64, data integration.Data, initConfig integration.Data, source string) error {
+	c.id = checkid.BuildID(c.name, integrationConfigDigest, data, initConfig)
+
+	commonOptions := integration.CommonInstanceConfig{}
+	if err := yaml.Unmarshal(data, &commonOptions); err != nil {
+		log.Errorf("invalid instance section for check %s: %s", string(c.id), err)
+		return err
+	}
+	if commonOptions.MinCollectionInterval > 0 {
+		c.interval = time.Duration(commonOptions.MinCollectionInterval) * time.Second
+	}
+
+	settings, err := GetSettings(data, pkgconfigsetup.Datadog())
+	if err != nil {
+		return fmt.Errorf("invalid isolation settings: %w", err)
+	}
+	c.settings = settings
+	c.source = source
+	c.initConfig = string(initConfig)
+	c.instanceConfig = string(data)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err = c.startWorker()
+	return err
+}
+
+// Run runs the check in the worker, starting it first if needed
+func (c *Check) Run() error {
+	c.mu.Lock()
+	ctx := c.runCtx
+	c.runCtx = nil
+	w, err := c.ensureWorker()
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if ctx != nil {
+		stop := context.AfterFunc(ctx, func() { w.kill("its run was aborted") })
+		defer stop()
+	}
+
+	reply, err := w.request(&message{Kind: kindRun})
+	if err != nil {
+		c.workerFailed(w, err)
+		return err
+	}
+
+	warnings := make([]error, 0, len(reply.Warnings))
+	for _, warning := range reply.Warnings {
+		warnings = append(warnings, errors.New(warning))
+	}
+
+	c.mu.Lock()
+	c.lastWarnings = warnings
+	c.restarts = 0
+	c.mu.Unlock()
+
+	if reply.Error != "" {
+		return errors.New(reply.Error)
+	}
+	return nil
+}
+
+// ensureWorker returns the running worker, restarting it if its restart backoff has elapsed.
+// It must be called with c.mu held.
+func (c *Check) ensureWorker() (*process, error) {
+	if c.cancelled {
+		return nil, fmt.Errorf("check %s is already cancelled", c.name)
+	}
+	if c.worker != nil && c.worker.alive() {
+		return c.worker, nil
+	}
+	if wait := time.Until(c.restartAt); wait > 0 {
+		return nil, fmt.Errorf("the check worker is restarting after %d failure(s), next attempt in %s", c.restarts, wait.Round(time.Second))
+	}
+	w, err := c.startWorker()
+	if err != nil {
+		c.scheduleRestart(err)
+	}
+	return w, err
+}
+
+// startWorker starts a worker and configures the check in it. It must be called with c.mu
+// held.
+func (c *Check) startWorker() (*process, error) {
+	w, err := startProcess(c.name, c.command, c.settings, c.forward)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := w.request(&message{
+		Kind: kindConfigure,
+		Configure: &configureRequest{
+			Name:       c.name,
+			InitConfig: c.initConfig,
+			Instance:   c.instanceConfig,
+			Source:     c.source,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reply.Error != "" {
+		w.stop()
+		err := errors.New(reply.Error)
+		if reply.Skip {
+			return nil, fmt.Errorf("%w: %w", check.ErrSkipCheckInstance, err)
+		}
+		return nil, err
+	}
+
+	c.worker = w
+	c.version = reply.Version
+	c.haSupported = reply.HASupported
+	return w, nil
+}
+
+// workerFailed schedules the restart of a worker which failed during a run
+func (c *Check) workerFailed(w *process, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.worker != w || c.cancelled {
+		return
+	}
+	c.worker = nil
+	c.scheduleRestart(err)
+}
+
+// scheduleRestart sets the delay before the next start of the worker, doubling it after each
+// consecutive failure. It must be called with c.mu held.
+func (c *Check) scheduleRestart(err error) {
+	c.restarts++
+	backoff := c.settings.MaxRestartBackoff
+	if c.restarts < 32 && minRestartBackoff<<(c.restarts-1) < backoff {
+		backoff = minRestartBackoff << (c.restarts - 1)
+	}
+	c.restartAt = time.Now().Add(backoff)
+	log.Warnf("Check worker of %s failed (%d consecutive failure(s)), restarting it in %s: %s", c.id, c.restarts, backoff, err)
+}
+
+// forward replays a sender call made by the worker
+func (c *Check) forward(call *senderCall) {
+	s, err := c.senderManager.GetSender(c.id)
+	if err != nil {
+		log.Warnf("Failed to retrieve a sender for check %s: %s", c.id, err)
+		return
+	}
+	if err := call.apply(s); err != nil {
+		log.Warnf("Invalid %s sender call from the check worker of %s: %s", call.Method, c.id, err)
+	}
+}
+
+// SetRunContext sets the context of the next run: the worker is killed if the context is
+// cancelled during the run.
+func (c *Check) SetRunContext(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.runCtx = ctx
+}
+
+// Stop does nothing, like for embedded Python checks
+func (c *Check) Stop() {}
+
+// Cancel stops the worker, which cancels the check
+func (c *Check) Cancel() {
+	c.mu.Lock()
+	w := c.worker
+	c.worker = nil
+	c.cancelled = true
+	c.mu.Unlock()
+
+	if w != nil {
+		w.stop()
+	}
+}
+
+// String representation (for debug and logging)
+func (c *Check) String() string {
+	return c.name
+}
+
+// Loader returns the name of the loader of the check
+func (c *Check) Loader() string {
+	return c.loader
+}
+
+// Interval returns the scheduling time for the check
+func (c *Check) Interval() time.Duration {
+	return c.interval
+}
+
+// ID returns the ID of the check
+func (c *Check) ID() checkid.ID {
+	return c.id
+}
+
+// GetWarnings returns the warnings of the last run
+func (c *Check) GetWarnings() []error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	warnings := c.lastWarnings
+	c.lastWarnings = []error{}
+	return warnings
+}
+
+// GetSenderStats returns the stats from the last run of the check
+func (c *Check) GetSenderStats() (stats.SenderStats, error) {
+	s, err := c.senderManager.GetSender(c.ID())
+	if err != nil {
+		return stats.SenderStats{}, fmt.Errorf("Failed to retrieve a Sender instance: %v", err)
+	}
+	return s.GetSenderStats(), nil
+}
+
+// Version returns the version of the check reported by the worker
+func (c *Check) Version() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.version
+}
+
+// ConfigSource returns the source of the configuration for this check
+func (c *Check) ConfigSource() string {
+	return c.source
+}
+
+// IsTelemetryEnabled returns if the telemetry is enabled for this check
+func (c *Check) IsTelemetryEnabled() bool {
+	return c.telemetry
+}
+
+// InitConfig returns the init_config configuration for the check
+func (c *Check) InitConfig() string {
+	return c.initConfig
+}
+
+// InstanceConfig returns the instance configuration for the check
+func (c *Check) InstanceConfig() string {
+	return c.instanceConfig
+}
+
+// GetDiagnoses returns the diagnoses of the check, if its worker is running
+func (c *Check) GetDiagnoses() ([]diagnose.Diagnosis, error) {
+	c.mu.Lock()
+	w := c.worker
+	c.mu.Unlock()
+	if w == nil || !w.alive() {
+		return nil, nil
+	}
+
+	reply, err := w.request(&message{Kind: kindDiagnose})
+	if err != nil {
+		return nil, err
+	}
+	if reply.Error != "" {
+		return reply.Diagnoses, errors.New(reply.Error)
+	}
+	return reply.Diagnoses, nil
+}
+
+// IsHASupported returns whether the check supports High Availability, as reported by the
+// worker
+func (c *Check) IsHASupported() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.haSupported
+}
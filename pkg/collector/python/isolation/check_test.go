@@ -0,0 +1,227 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build test && !windows
+
+package isolation
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	checkid "github.com/DataDog/datadog-agent/pkg/collector/check/id"
+	"github.com/DataDog/datadog-agent/pkg/collector/check/stub"
+	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
+	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
+)
+
+// helperSocketEnv is set when the test binary is started as a check worker
+const helperSocketEnv = "DD_TEST_ISOLATION_WORKER_SOCKET"
+
+// TestHelperWorker isn't a real test: it runs the worker process started by the other tests.
+func TestHelperWorker(t *testing.T) {
+	socketPath := os.Getenv(helperSocketEnv)
+	if socketPath == "" {
+		return
+	}
+	w, err := Dial(socketPath)
+	require.NoError(t, err)
+	require.NoError(t, w.Serve(loadFakeCheck))
+	os.Exit(0)
+}
+
+// fakeCheck behaves as configured by its instance
+type fakeCheck struct {
+	stub.StubCheck
+	senderManager sender.SenderManager
+	behavior      string
+	crashOnce     string
+	warnings      []error
+}
+
+func loadFakeCheck(senderManager sender.SenderManager, config integration.Config, instance integration.Data, _ int) (check.Check, error) {
+	conf := struct {
+		Behavior  string `yaml:"behavior"`
+		CrashOnce string `yaml:"crash_once"`
+	}{}
+	if err := yaml.Unmarshal(instance, &conf); err != nil {
+		return nil, err
+	}
+	switch conf.Behavior {
+	case "skip":
+		return nil, check.ErrSkipCheckInstance
+	case "fail_load":
+		return nil, errors.New("unable to load check " + config.Name)
+	}
+	return &fakeCheck{senderManager: senderManager, behavior: conf.Behavior, crashOnce: conf.CrashOnce}, nil
+}
+
+func (c *fakeCheck) Version() string { return "1.2.3" }
+
+func (c *fakeCheck) IsHASupported() bool { return true }
+
+func (c *fakeCheck) Run() error {
+	if c.crashOnce != "" {
+		if _, err := os.Stat(c.crashOnce); os.IsNotExist(err) {
+			_ = os.WriteFile(c.crashOnce, nil, 0o600)
+			os.Exit(3)
+		}
+	}
+
+	switch c.behavior {
+	case "error":
+		return errors.New("check failed")
+	case "hang":
+		select {}
+	case "memory":
+		var buffers [][]byte
+		for i := 0; i < 64; i++ {
+			buf := make([]byte, 8<<20)
+			for j := range buf {
+				buf[j] = byte(j)
+			}
+			buffers = append(buffers, buf)
+			time.Sleep(50 * time.Millisecond)
+		}
+		_ = buffers
+		return nil
+	}
+
+	s, _ := c.senderManager.GetSender(c.ID())
+	s.Gauge("test.metric", 42, "", []string{"foo:bar"})
+	s.ServiceCheck("test.can_connect", servicecheck.ServiceCheckOK, "", nil, "")
+	s.Commit()
+	c.warnings = []error{errors.New("careful")}
+	return nil
+}
+
+func (c *fakeCheck) GetWarnings() []error {
+	warnings := c.warnings
+	c.warnings = nil
+	return warnings
+}
+
+func newTestCheck(t *testing.T, instance string) (*Check, *mocksender.MockSender, error) {
+	configmock.New(t)
+
+	id := checkid.BuildID("fake", 0, integration.Data(instance), nil)
+	s := mocksender.NewMockSender(id)
+	s.SetupAcceptAll()
+
+	c := NewCheck(s.GetSenderManager(), "fake", "python")
+	c.command = func(socketPath string) (*exec.Cmd, error) {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestHelperWorker$")
+		cmd.Env = append(os.Environ(), helperSocketEnv+"="+socketPath)
+		return cmd, nil
+	}
+	err := c.Configure(nil, 0, integration.Data(instance), nil, "test")
+	if err == nil {
+		t.Cleanup(c.Cancel)
+	}
+	return c, s, err
+}
+
+func TestCheckRun(t *testing.T) {
+	c, s, err := newTestCheck(t, "isolation: process")
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.3", c.Version())
+	assert.True(t, c.IsHASupported())
+	assert.Equal(t, "python", c.Loader())
+
+	require.NoError(t, c.Run())
+	s.AssertMetric(t, "Gauge", "test.metric", 42, "", []string{"foo:bar"})
+	s.AssertServiceCheck(t, "test.can_connect", servicecheck.ServiceCheckOK, "", nil, "")
+	s.AssertNumberOfCalls(t, "Commit", 1)
+	assert.Equal(t, []error{errors.New("careful")}, c.GetWarnings())
+
+	require.NoError(t, c.Run())
+	s.AssertNumberOfCalls(t, "Commit", 2)
+}
+
+func TestCheckRunError(t *testing.T) {
+	c, _, err := newTestCheck(t, "isolation: process\nbehavior: error")
+	require.NoError(t, err)
+	assert.EqualError(t, c.Run(), "check failed")
+}
+
+func TestCheckConfigureError(t *testing.T) {
+	_, _, err := newTestCheck(t, "isolation: process\nbehavior: fail_load")
+	assert.EqualError(t, err, "unable to load check fake")
+
+	_, _, err = newTestCheck(t, "isolation: process\nbehavior: skip")
+	assert.ErrorIs(t, err, check.ErrSkipCheckInstance)
+}
+
+func TestCheckRestart(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "crashed")
+	c, s, err := newTestCheck(t, "isolation: process\ncrash_once: "+marker)
+	require.NoError(t, err)
+
+	err = c.Run()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "the check worker exited: exit status 3")
+
+	err = c.Run()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "the check worker is restarting after 1 failure(s)")
+
+	// skip the backoff
+	c.mu.Lock()
+	c.restartAt = time.Now()
+	c.mu.Unlock()
+
+	require.NoError(t, c.Run())
+	s.AssertMetric(t, "Gauge", "test.metric", 42, "", []string{"foo:bar"})
+	assert.Equal(t, 0, c.restarts)
+}
+
+func TestCheckRunContext(t *testing.T) {
+	c, _, err := newTestCheck(t, "isolation: process\nbehavior: hang")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	c.SetRunContext(ctx)
+
+	err = c.Run()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "killed because its run was aborted")
+}
+
+func TestCheckMemoryLimit(t *testing.T) {
+	c, _, err := newTestCheck(t, "isolation: process\nbehavior: memory\nisolation_memory_limit_mb: 256")
+	require.NoError(t, err)
+
+	err = c.Run()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exceeded its limit of 256 MiB")
+}
+
+func TestCheckCancel(t *testing.T) {
+	c, _, err := newTestCheck(t, "isolation: process")
+	require.NoError(t, err)
+
+	w := c.worker
+	c.Cancel()
+	select {
+	case <-w.exited:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "the worker didn't exit")
+	}
+	assert.EqualError(t, c.Run(), "check fake is already cancelled")
+}
@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package isolation runs Python check instances configured with `isolation: process` in a
+// supervised worker process with its own interpreter, so that a crash or a leak in an
+// integration doesn't affect the agent.
+//
+// The agent side is a Check which starts the worker, configures and runs the check through a
+// local socket, and forwards the sender calls made by the worker to the aggregator. The worker
+// side is a Worker which loads the check with the embedded Python loader and serves the
+// requests of the agent.
+package isolation
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/atomic"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/config/model"
+)
+
+const (
+	// ModeProcess is the `isolation` setting running a check instance in a worker process
+	ModeProcess = "process"
+)
+
+// isWorkerProcess is set when the current process is an isolated check worker
+var isWorkerProcess = atomic.NewBool(false)
+
+// IsWorkerProcess returns whether the current process is an isolated check worker. Checks
+// loaded by a worker must run embedded.
+func IsWorkerProcess() bool {
+	return isWorkerProcess.Load()
+}
+
+// Settings holds the isolation settings of a check instance
+type Settings struct {
+	// MemoryLimit is the maximum resident memory of the worker, in bytes. 0 means no limit.
+	MemoryLimit uint64
+	// CPULimit is the maximum CPU usage of the worker, in percent of a core. 0 means no limit.
+	CPULimit float64
+	// MaxRestartBackoff is the maximum delay before restarting a failed worker
+	MaxRestartBackoff time.Duration
+	// StartTimeout is the maximum duration for the worker to connect to the agent
+	StartTimeout time.Duration
+}
+
+// Requested returns whether the check instance is configured to run in a worker process.
+func Requested(instance integration.Data) bool {
+	common := integration.CommonInstanceConfig{}
+	if err := yaml.Unmarshal(instance, &common); err != nil {
+		return false
+	}
+	return common.Isolation == ModeProcess
+}
+
+// GetSettings returns the isolation settings of a check instance: the limits of its instance
+// configuration, or else the `python_check_isolation` settings of the agent.
+func GetSettings(instance integration.Data, cfg model.Reader) (Settings, error) {
+	common := integration.CommonInstanceConfig{}
+	if err := yaml.Unmarshal(instance, &common); err != nil {
+		return Settings{}, err
+	}
+
+	settings := Settings{
+		MemoryLimit:       uint64(cfg.GetInt64("python_check_isolation.memory_limit_mb")) << 20,
+		CPULimit:          cfg.GetFloat64("python_check_isolation.cpu_limit"),
+		MaxRestartBackoff: cfg.GetDuration("python_check_isolation.max_restart_backoff"),
+		StartTimeout:      cfg.GetDuration("python_check_isolation.start_timeout"),
+	}
+	if common.IsolationMemoryLimitMB > 0 {
+		settings.MemoryLimit = uint64(common.IsolationMemoryLimitMB) << 20
+	}
+	if common.IsolationCPULimit > 0 {
+		settings.CPULimit = common.IsolationCPULimit
+	}
+
+	if settings.MemoryLimit > 0 && settings.MemoryLimit < minMemoryLimit {
+		return Settings{}, fmt.Errorf("isolation memory limit must be at least %d MiB", minMemoryLimit>>20)
+	}
+	if settings.MaxRestartBackoff < minRestartBackoff {
+		settings.MaxRestartBackoff = minRestartBackoff
+	}
+	if settings.StartTimeout <= 0 {
+		settings.StartTimeout = defaultStartTimeout
+	}
+	return settings, nil
+}
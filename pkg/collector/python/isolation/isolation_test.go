@@ -0,0 +1,50 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package isolation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
+)
+
+func TestRequested(t *testing.T) {
+	assert.True(t, Requested(integration.Data("isolation: process")))
+	assert.False(t, Requested(integration.Data("isolation: none")))
+	assert.False(t, Requested(integration.Data("host: localhost")))
+	assert.False(t, Requested(integration.Data("{")))
+}
+
+func TestGetSettings(t *testing.T) {
+	cfg := configmock.New(t)
+
+	settings, err := GetSettings(integration.Data("isolation: process"), cfg)
+	require.NoError(t, err)
+	assert.Equal(t, Settings{
+		MaxRestartBackoff: 5 * time.Minute,
+		StartTimeout:      30 * time.Second,
+	}, settings)
+
+	cfg.SetWithoutSource("python_check_isolation.memory_limit_mb", 512)
+	cfg.SetWithoutSource("python_check_isolation.cpu_limit", 50)
+	settings, err = GetSettings(integration.Data("isolation: process"), cfg)
+	require.NoError(t, err)
+	assert.EqualValues(t, 512<<20, settings.MemoryLimit)
+	assert.Equal(t, 50.0, settings.CPULimit)
+
+	settings, err = GetSettings(integration.Data("isolation: process\nisolation_memory_limit_mb: 256\nisolation_cpu_limit: 25"), cfg)
+	require.NoError(t, err)
+	assert.EqualValues(t, 256<<20, settings.MemoryLimit)
+	assert.Equal(t, 25.0, settings.CPULimit)
+
+	_, err = GetSettings(integration.Data("isolation: process\nisolation_memory_limit_mb: 8"), cfg)
+	assert.EqualError(t, err, "isolation memory limit must be at least 32 MiB")
+}
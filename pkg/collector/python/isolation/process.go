@@ -0,0 +1,338 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package isolation
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	gopsprocess "github.com/shirou/gopsutil/v4/process"
+
+	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// minMemoryLimit is the minimum memory limit of a worker, below which the interpreter
+	// can't even start
+	minMemoryLimit = 32 << 20
+	// minRestartBackoff is the delay before the first restart of a failed worker
+	minRestartBackoff = time.Second
+	// defaultStartTimeout is the default maximum duration for a worker to connect to the agent
+	defaultStartTimeout = 30 * time.Second
+	// monitorInterval is the interval at which the resource usage of a worker is checked
+	monitorInterval = time.Second
+	// cpuLimitGracePeriod is how long the CPU usage of a worker can exceed its limit before the
+	// worker is killed
+	cpuLimitGracePeriod = 10 * time.Second
+	// exitTimeout is how long to wait for a worker to exit once its connection is closed
+	exitTimeout = 2 * time.Second
+)
+
+// commandFunc returns the command starting a worker connecting to socketPath
+type commandFunc func(socketPath string) (*exec.Cmd, error)
+
+// defaultCommand starts a worker by running the `check-worker` subcommand of the current
+// executable with the configuration of the agent.
+func defaultCommand(socketPath string) (*exec.Cmd, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("unable to find the agent executable: %w", err)
+	}
+	args := []string{"check-worker", "--socket", socketPath, "--log_level", pkgconfigsetup.Datadog().GetString("log_level")}
+	if configFile := pkgconfigsetup.Datadog().ConfigFileUsed(); configFile != "" {
+		args = append(args, "--cfgpath", filepath.Dir(configFile))
+	}
+	return exec.Command(exe, args...), nil
+}
+
+// process is a running worker
+type process struct {
+	name string
+	cmd  *exec.Cmd
+	conn *conn
+
+	replies chan *message // replies of the worker, calls to the sender are dispatched directly
+	reqMu   sync.Mutex    // serializes the requests
+	closed  chan struct{} // closed when the connection is lost
+	exited  chan struct{} // closed when the process has exited
+	exitErr error
+
+	mu         sync.Mutex // protects killReason
+	killReason string
+}
+
+// startProcess starts a worker and waits for it to connect. The calls to the sender made by
+// the worker are passed to onCall.
+func startProcess(name string, command commandFunc, settings Settings, onCall func(*senderCall)) (*process, error) {
+	dir, err := os.MkdirTemp("", "dd-check-worker-")
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the socket directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	socketPath := filepath.Join(dir, "worker.sock")
+	ln, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to listen on %s: %w", socketPath, err)
+	}
+	defer ln.Close()
+
+	cmd, err := command(socketPath)
+	if err != nil {
+		return nil, err
+	}
+	logs := newWorkerLogWriter(name)
+	cmd.Stdout = logs
+	cmd.Stderr = logs
+	if err := cmd.Start(); err != nil {
+		logs.Close()
+		return nil, fmt.Errorf("unable to start the check worker: %w", err)
+	}
+
+	p := &process{
+		name:    name,
+		cmd:     cmd,
+		replies: make(chan *message),
+		closed:  make(chan struct{}),
+		exited:  make(chan struct{}),
+	}
+	go func() {
+		p.exitErr = cmd.Wait()
+		logs.Close()
+		close(p.exited)
+	}()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if c, err := ln.Accept(); err == nil {
+			accepted <- c
+		}
+	}()
+
+	timeout := time.NewTimer(settings.StartTimeout)
+	defer timeout.Stop()
+	select {
+	case c := <-accepted:
+		p.conn = newConn(c)
+	case <-p.exited:
+		return nil, fmt.Errorf("the check worker exited before connecting to the agent: %s", p.exitReason())
+	case <-timeout.C:
+		p.kill("it didn't connect to the agent in time")
+		return nil, fmt.Errorf("the check worker didn't connect to the agent within %s", settings.StartTimeout)
+	}
+
+	go p.readLoop(onCall)
+	if settings.MemoryLimit > 0 || settings.CPULimit > 0 {
+		go p.monitor(settings)
+	}
+	log.Debugf("Started check worker %d for %s", cmd.Process.Pid, name)
+	return p, nil
+}
+
+// readLoop dispatches the messages of the worker until the connection is lost
+func (p *process) readLoop(onCall func(*senderCall)) {
+	defer close(p.closed)
+	for {
+		m, err := p.conn.receive()
+		if err != nil {
+			return
+		}
+		if m.Kind == kindSenderCall {
+			if m.Call != nil {
+				onCall(m.Call)
+			}
+			continue
+		}
+		select {
+		case p.replies <- m:
+		case <-p.exited:
+			return
+		}
+	}
+}
+
+// request sends a request to the worker and waits for its reply
+func (p *process) request(req *message) (*message, error) {
+	p.reqMu.Lock()
+	defer p.reqMu.Unlock()
+
+	if err := p.conn.send(req); err != nil {
+		return nil, p.failure()
+	}
+	for {
+		select {
+		case reply := <-p.replies:
+			if reply.Kind != replyKinds[req.Kind] {
+				log.Warnf("Ignoring unexpected %q message from the check worker of %s", reply.Kind, p.name)
+				continue
+			}
+			return reply, nil
+		case <-p.closed:
+			return nil, p.failure()
+		}
+	}
+}
+
+// alive returns whether the worker is still connected
+func (p *process) alive() bool {
+	select {
+	case <-p.closed:
+		return false
+	default:
+		return true
+	}
+}
+
+// kill kills the worker, recording the reason for the exit if it's still running
+func (p *process) kill(reason string) {
+	p.mu.Lock()
+	select {
+	case <-p.exited:
+	default:
+		if p.killReason == "" {
+			p.killReason = reason
+		}
+	}
+	p.mu.Unlock()
+
+	_ = p.cmd.Process.Kill()
+	if p.conn != nil {
+		_ = p.conn.close()
+	}
+}
+
+// stop closes the connection, which makes the worker cancel the check and exit, and kills
+// the worker if it doesn't exit in time.
+func (p *process) stop() {
+	_ = p.conn.close()
+	select {
+	case <-p.exited:
+	case <-time.After(exitTimeout):
+		p.kill("it didn't exit in time")
+		<-p.exited
+	}
+}
+
+// failure waits for a worker which lost its connection to exit, killing it if it doesn't,
+// and returns an error describing why it exited.
+func (p *process) failure() error {
+	select {
+	case <-p.exited:
+	case <-time.After(exitTimeout):
+		p.kill("its connection to the agent was lost")
+		<-p.exited
+	}
+	return fmt.Errorf("the check worker exited: %s", p.exitReason())
+}
+
+// exitReason describes why the worker exited. It must be called once the worker has exited.
+func (p *process) exitReason() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.killReason != "" {
+		return "killed because " + p.killReason
+	}
+	if p.exitErr != nil {
+		return p.exitErr.Error()
+	}
+	return "exit status 0"
+}
+
+// monitor kills the worker when it exceeds its memory or CPU limit
+func (p *process) monitor(settings Settings) {
+	proc, err := gopsprocess.NewProcess(int32(p.cmd.Process.Pid))
+	if err != nil {
+		log.Warnf("Unable to monitor the resource usage of the check worker of %s: %s", p.name, err)
+		return
+	}
+	cpu := &cpuLimiter{limit: settings.CPULimit}
+
+	ticker := time.NewTicker(monitorInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.exited:
+			return
+		case now := <-ticker.C:
+			if settings.MemoryLimit > 0 {
+				if mem, err := proc.MemoryInfo(); err == nil && mem.RSS > settings.MemoryLimit {
+					p.kill(fmt.Sprintf("its memory usage of %s exceeded its limit of %s",
+						humanize.IBytes(mem.RSS), humanize.IBytes(settings.MemoryLimit)))
+					return
+				}
+			}
+			if settings.CPULimit > 0 {
+				if times, err := proc.Times(); err == nil && cpu.exceeded(now, times.User+times.System) {
+					p.kill(fmt.Sprintf("its CPU usage exceeded its limit of %.0f%% for more than %s",
+						settings.CPULimit, cpuLimitGracePeriod))
+					return
+				}
+			}
+		}
+	}
+}
+
+// cpuLimiter tracks whether the CPU usage of a process exceeds its limit for longer than
+// cpuLimitGracePeriod.
+type cpuLimiter struct {
+	limit     float64 // percent of a core
+	lastTime  time.Time
+	lastCPU   float64 // cumulated CPU time, in seconds
+	overSince time.Time
+}
+
+// exceeded records a sample of the cumulated CPU time of the process and returns whether
+// its usage has exceeded the limit for too long.
+func (l *cpuLimiter) exceeded(now time.Time, cpuSeconds float64) bool {
+	defer func() {
+		l.lastTime = now
+		l.lastCPU = cpuSeconds
+	}()
+	if l.lastTime.IsZero() || !now.After(l.lastTime) {
+		return false
+	}
+
+	usage := (cpuSeconds - l.lastCPU) / now.Sub(l.lastTime).Seconds() * 100
+	if usage <= l.limit {
+		l.overSince = time.Time{}
+		return false
+	}
+	if l.overSince.IsZero() {
+		l.overSince = l.lastTime
+	}
+	return now.Sub(l.overSince) >= cpuLimitGracePeriod
+}
+
+// workerLogWriter logs the output of a worker, line by line
+type workerLogWriter struct {
+	*io.PipeWriter
+}
+
+func newWorkerLogWriter(name string) workerLogWriter {
+	r, w := io.Pipe()
+	go func() {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			log.Infof("check worker %s | %s", name, scanner.Text())
+		}
+		if err := scanner.Err(); err != nil {
+			log.Debugf("Stopped logging the output of the check worker of %s: %s", name, err)
+		}
+		// keep draining the output so that the worker never blocks on it
+		_, _ = io.Copy(io.Discard, r)
+		_ = r.Close()
+	}()
+	return workerLogWriter{w}
+}
@@ -0,0 +1,37 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package isolation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCPULimiter(t *testing.T) {
+	l := &cpuLimiter{limit: 50}
+	start := time.Now()
+	at := func(seconds int) time.Time { return start.Add(time.Duration(seconds) * time.Second) }
+
+	assert.False(t, l.exceeded(at(0), 10))
+	// 30% of a core
+	assert.False(t, l.exceeded(at(1), 10.3))
+	// 100% of a core, for less than the grace period
+	cpu := 10.3
+	for i := 2; i < 11; i++ {
+		cpu++
+		assert.False(t, l.exceeded(at(i), cpu), "second %d", i)
+	}
+	// back under the limit: the grace period restarts
+	assert.False(t, l.exceeded(at(11), cpu+0.1))
+	for i := 12; i < 21; i++ {
+		cpu++
+		assert.False(t, l.exceeded(at(i), cpu), "second %d", i)
+	}
+	cpu++
+	assert.True(t, l.exceeded(at(21), cpu))
+}
@@ -0,0 +1,115 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package isolation
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"sync"
+
+	diagnose "github.com/DataDog/datadog-agent/comp/core/diagnose/def"
+)
+
+// messageKind is the kind of a message exchanged between the agent and a worker
+type messageKind string
+
+const (
+	// kindConfigure asks the worker to load and configure the check
+	kindConfigure messageKind = "configure"
+	// kindConfigured answers kindConfigure
+	kindConfigured messageKind = "configured"
+	// kindRun asks the worker to run the check
+	kindRun messageKind = "run"
+	// kindRunDone answers kindRun
+	kindRunDone messageKind = "run_done"
+	// kindDiagnose asks the worker for the diagnoses of the check
+	kindDiagnose messageKind = "diagnose"
+	// kindDiagnoses answers kindDiagnose
+	kindDiagnoses messageKind = "diagnoses"
+	// kindSenderCall is a call to the sender of the check made by the worker
+	kindSenderCall messageKind = "sender_call"
+)
+
+// replyKinds maps each request to the kind of its reply
+var replyKinds = map[messageKind]messageKind{
+	kindConfigure: kindConfigured,
+	kindRun:       kindRunDone,
+	kindDiagnose:  kindDiagnoses,
+}
+
+// message is a message exchanged between the agent and a worker. Only the fields relevant
+// to its kind are set.
+type message struct {
+	Kind messageKind `json:"kind"`
+
+	// kindConfigure
+	Configure *configureRequest `json:"configure,omitempty"`
+
+	// kindSenderCall
+	Call *senderCall `json:"call,omitempty"`
+
+	// replies
+	Error       string               `json:"error,omitempty"`
+	Skip        bool                 `json:"skip,omitempty"`
+	Version     string               `json:"version,omitempty"`
+	HASupported bool                 `json:"ha_supported,omitempty"`
+	Warnings    []string             `json:"warnings,omitempty"`
+	Diagnoses   []diagnose.Diagnosis `json:"diagnoses,omitempty"`
+}
+
+// configureRequest holds the configuration of the check instance to run in the worker
+type configureRequest struct {
+	Name       string `json:"name"`
+	InitConfig string `json:"init_config"`
+	Instance   string `json:"instance"`
+	Source     string `json:"source"`
+}
+
+// conn exchanges newline-delimited JSON messages over a local socket. Messages can be sent
+// concurrently but must be received from a single goroutine.
+type conn struct {
+	c   net.Conn
+	dec *json.Decoder
+
+	mu  sync.Mutex // protects enc and w
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func newConn(c net.Conn) *conn {
+	w := bufio.NewWriter(c)
+	return &conn{
+		c:   c,
+		dec: json.NewDecoder(bufio.NewReader(c)),
+		w:   w,
+		enc: json.NewEncoder(w),
+	}
+}
+
+// send sends a message
+func (c *conn) send(m *message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.enc.Encode(m); err != nil {
+		return err
+	}
+	return c.w.Flush()
+}
+
+// receive waits for the next message
+func (c *conn) receive() (*message, error) {
+	m := &message{}
+	if err := c.dec.Decode(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// close closes the connection, unblocking receive
+func (c *conn) close() error {
+	return c.c.Close()
+}
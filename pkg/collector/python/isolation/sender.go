@@ -0,0 +1,305 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package isolation
+
+import (
+	"errors"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
+	checkid "github.com/DataDog/datadog-agent/pkg/collector/check/id"
+	"github.com/DataDog/datadog-agent/pkg/collector/check/stats"
+	"github.com/DataDog/datadog-agent/pkg/metrics/event"
+	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
+	"github.com/DataDog/datadog-agent/pkg/serializer/types"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// senderMethod is the sender method called by a worker
+type senderMethod string
+
+const (
+	methodCommit                            senderMethod = "commit"
+	methodGauge                             senderMethod = "gauge"
+	methodGaugeNoIndex                      senderMethod = "gauge_no_index"
+	methodRate                              senderMethod = "rate"
+	methodCount                             senderMethod = "count"
+	methodMonotonicCount                    senderMethod = "monotonic_count"
+	methodMonotonicCountWithFlushFirstValue senderMethod = "monotonic_count_with_flush_first_value"
+	methodCounter                           senderMethod = "counter"
+	methodHistogram                         senderMethod = "histogram"
+	methodHistorate                         senderMethod = "historate"
+	methodDistribution                      senderMethod = "distribution"
+	methodServiceCheck                      senderMethod = "service_check"
+	methodHistogramBucket                   senderMethod = "histogram_bucket"
+	methodGaugeWithTimestamp                senderMethod = "gauge_with_timestamp"
+	methodCountWithTimestamp                senderMethod = "count_with_timestamp"
+	methodEvent                             senderMethod = "event"
+	methodEventPlatformEvent                senderMethod = "event_platform_event"
+	methodDisableDefaultHostname            senderMethod = "disable_default_hostname"
+	methodSetCheckCustomTags                senderMethod = "set_check_custom_tags"
+	methodSetCheckService                   senderMethod = "set_check_service"
+	methodSetNoIndex                        senderMethod = "set_no_index"
+	methodFinalizeCheckServiceTag           senderMethod = "finalize_check_service_tag"
+)
+
+// senderCall is a call to the sender of a check, made by the worker running it and replayed
+// by the agent on the actual sender
+type senderCall struct {
+	Method          senderMethod                    `json:"method"`
+	Metric          string                          `json:"metric,omitempty"`
+	Value           float64                         `json:"value,omitempty"`
+	BucketValue     int64                           `json:"bucket_value,omitempty"`
+	LowerBound      float64                         `json:"lower_bound,omitempty"`
+	UpperBound      float64                         `json:"upper_bound,omitempty"`
+	Monotonic       bool                            `json:"monotonic,omitempty"`
+	FlushFirstValue bool                            `json:"flush_first_value,omitempty"`
+	Hostname        string                          `json:"hostname,omitempty"`
+	Tags            []string                        `json:"tags,omitempty"`
+	Timestamp       float64                         `json:"timestamp,omitempty"`
+	Status          servicecheck.ServiceCheckStatus `json:"status,omitempty"`
+	Message         string                          `json:"message,omitempty"`
+	Event           *event.Event                    `json:"event,omitempty"`
+	RawEvent        []byte                          `json:"raw_event,omitempty"`
+	EventType       string                          `json:"event_type,omitempty"`
+	Flag            bool                            `json:"flag,omitempty"`
+	Service         string                          `json:"service,omitempty"`
+}
+
+// apply replays the call on the given sender
+func (call *senderCall) apply(s sender.Sender) error {
+	switch call.Method {
+	case methodCommit:
+		s.Commit()
+	case methodGauge:
+		s.Gauge(call.Metric, call.Value, call.Hostname, call.Tags)
+	case methodGaugeNoIndex:
+		s.GaugeNoIndex(call.Metric, call.Value, call.Hostname, call.Tags)
+	case methodRate:
+		s.Rate(call.Metric, call.Value, call.Hostname, call.Tags)
+	case methodCount:
+		s.Count(call.Metric, call.Value, call.Hostname, call.Tags)
+	case methodMonotonicCount:
+		s.MonotonicCount(call.Metric, call.Value, call.Hostname, call.Tags)
+	case methodMonotonicCountWithFlushFirstValue:
+		s.MonotonicCountWithFlushFirstValue(call.Metric, call.Value, call.Hostname, call.Tags, call.FlushFirstValue)
+	case methodCounter:
+		s.Counter(call.Metric, call.Value, call.Hostname, call.Tags)
+	case methodHistogram:
+		s.Histogram(call.Metric, call.Value, call.Hostname, call.Tags)
+	case methodHistorate:
+		s.Historate(call.Metric, call.Value, call.Hostname, call.Tags)
+	case methodDistribution:
+		s.Distribution(call.Metric, call.Value, call.Hostname, call.Tags)
+	case methodServiceCheck:
+		s.ServiceCheck(call.Metric, call.Status, call.Hostname, call.Tags, call.Message)
+	case methodHistogramBucket:
+		s.HistogramBucket(call.Metric, call.BucketValue, call.LowerBound, call.UpperBound, call.Monotonic, call.Hostname, call.Tags, call.FlushFirstValue)
+	case methodGaugeWithTimestamp:
+		return s.GaugeWithTimestamp(call.Metric, call.Value, call.Hostname, call.Tags, call.Timestamp)
+	case methodCountWithTimestamp:
+		return s.CountWithTimestamp(call.Metric, call.Value, call.Hostname, call.Tags, call.Timestamp)
+	case methodEvent:
+		if call.Event == nil {
+			return errors.New("missing event")
+		}
+		s.Event(*call.Event)
+	case methodEventPlatformEvent:
+		s.EventPlatformEvent(call.RawEvent, call.EventType)
+	case methodDisableDefaultHostname:
+		s.DisableDefaultHostname(call.Flag)
+	case methodSetCheckCustomTags:
+		s.SetCheckCustomTags(call.Tags)
+	case methodSetCheckService:
+		s.SetCheckService(call.Service)
+	case methodSetNoIndex:
+		s.SetNoIndex(call.Flag)
+	case methodFinalizeCheckServiceTag:
+		s.FinalizeCheckServiceTag()
+	default:
+		return errors.New("unknown sender method " + string(call.Method))
+	}
+	return nil
+}
+
+// forwardingSender is the sender of the check run by a worker: it forwards the calls to the
+// agent.
+type forwardingSender struct {
+	conn *conn
+}
+
+var _ sender.Sender = (*forwardingSender)(nil)
+
+func (s *forwardingSender) forward(call *senderCall) {
+	if err := s.conn.send(&message{Kind: kindSenderCall, Call: call}); err != nil {
+		log.Warnf("Unable to forward the %s sender call to the agent: %s", call.Method, err)
+	}
+}
+
+func (s *forwardingSender) forwardMetric(method senderMethod, metric string, value float64, hostname string, tags []string) {
+	s.forward(&senderCall{Method: method, Metric: metric, Value: value, Hostname: hostname, Tags: tags})
+}
+
+// Commit forwards a Commit call
+func (s *forwardingSender) Commit() {
+	s.forward(&senderCall{Method: methodCommit})
+}
+
+// Gauge forwards a Gauge call
+func (s *forwardingSender) Gauge(metric string, value float64, hostname string, tags []string) {
+	s.forwardMetric(methodGauge, metric, value, hostname, tags)
+}
+
+// GaugeNoIndex forwards a GaugeNoIndex call
+func (s *forwardingSender) GaugeNoIndex(metric string, value float64, hostname string, tags []string) {
+	s.forwardMetric(methodGaugeNoIndex, metric, value, hostname, tags)
+}
+
+// Rate forwards a Rate call
+func (s *forwardingSender) Rate(metric string, value float64, hostname string, tags []string) {
+	s.forwardMetric(methodRate, metric, value, hostname, tags)
+}
+
+// Count forwards a Count call
+func (s *forwardingSender) Count(metric string, value float64, hostname string, tags []string) {
+	s.forwardMetric(methodCount, metric, value, hostname, tags)
+}
+
+// MonotonicCount forwards a MonotonicCount call
+func (s *forwardingSender) MonotonicCount(metric string, value float64, hostname string, tags []string) {
+	s.forwardMetric(methodMonotonicCount, metric, value, hostname, tags)
+}
+
+// MonotonicCountWithFlushFirstValue forwards a MonotonicCountWithFlushFirstValue call
+func (s *forwardingSender) MonotonicCountWithFlushFirstValue(metric string, value float64, hostname string, tags []string, flushFirstValue bool) {
+	s.forward(&senderCall{Method: methodMonotonicCountWithFlushFirstValue, Metric: metric, Value: value, Hostname: hostname, Tags: tags, FlushFirstValue: flushFirstValue})
+}
+
+// Counter forwards a Counter call
+func (s *forwardingSender) Counter(metric string, value float64, hostname string, tags []string) {
+	s.forwardMetric(methodCounter, metric, value, hostname, tags)
+}
+
+// Histogram forwards a Histogram call
+func (s *forwardingSender) Histogram(metric string, value float64, hostname string, tags []string) {
+	s.forwardMetric(methodHistogram, metric, value, hostname, tags)
+}
+
+// Historate forwards a Historate call
+func (s *forwardingSender) Historate(metric string, value float64, hostname string, tags []string) {
+	s.forwardMetric(methodHistorate, metric, value, hostname, tags)
+}
+
+// Distribution forwards a Distribution call
+func (s *forwardingSender) Distribution(metric string, value float64, hostname string, tags []string) {
+	s.forwardMetric(methodDistribution, metric, value, hostname, tags)
+}
+
+// ServiceCheck forwards a ServiceCheck call
+func (s *forwardingSender) ServiceCheck(checkName string, status servicecheck.ServiceCheckStatus, hostname string, tags []string, message string) {
+	s.forward(&senderCall{Method: methodServiceCheck, Metric: checkName, Status: status, Hostname: hostname, Tags: tags, Message: message})
+}
+
+// HistogramBucket forwards a HistogramBucket call
+func (s *forwardingSender) HistogramBucket(metric string, value int64, lowerBound, upperBound float64, monotonic bool, hostname string, tags []string, flushFirstValue bool) {
+	s.forward(&senderCall{
+		Method:          methodHistogramBucket,
+		Metric:          metric,
+		BucketValue:     value,
+		LowerBound:      lowerBound,
+		UpperBound:      upperBound,
+		Monotonic:       monotonic,
+		Hostname:        hostname,
+		Tags:            tags,
+		FlushFirstValue: flushFirstValue,
+	})
+}
+
+// GaugeWithTimestamp forwards a GaugeWithTimestamp call. Errors are reported by the agent.
+func (s *forwardingSender) GaugeWithTimestamp(metric string, value float64, hostname string, tags []string, timestamp float64) error {
+	s.forward(&senderCall{Method: methodGaugeWithTimestamp, Metric: metric, Value: value, Hostname: hostname, Tags: tags, Timestamp: timestamp})
+	return nil
+}
+
+// CountWithTimestamp forwards a CountWithTimestamp call. Errors are reported by the agent.
+func (s *forwardingSender) CountWithTimestamp(metric string, value float64, hostname string, tags []string, timestamp float64) error {
+	s.forward(&senderCall{Method: methodCountWithTimestamp, Metric: metric, Value: value, Hostname: hostname, Tags: tags, Timestamp: timestamp})
+	return nil
+}
+
+// Event forwards an Event call
+func (s *forwardingSender) Event(e event.Event) {
+	s.forward(&senderCall{Method: methodEvent, Event: &e})
+}
+
+// EventPlatformEvent forwards an EventPlatformEvent call
+func (s *forwardingSender) EventPlatformEvent(rawEvent []byte, eventType string) {
+	s.forward(&senderCall{Method: methodEventPlatformEvent, RawEvent: rawEvent, EventType: eventType})
+}
+
+// GetSenderStats returns empty stats: the stats of the check are kept by the agent
+func (s *forwardingSender) GetSenderStats() stats.SenderStats {
+	return stats.NewSenderStats()
+}
+
+// DisableDefaultHostname forwards a DisableDefaultHostname call
+func (s *forwardingSender) DisableDefaultHostname(disable bool) {
+	s.forward(&senderCall{Method: methodDisableDefaultHostname, Flag: disable})
+}
+
+// SetCheckCustomTags forwards a SetCheckCustomTags call
+func (s *forwardingSender) SetCheckCustomTags(tags []string) {
+	s.forward(&senderCall{Method: methodSetCheckCustomTags, Tags: tags})
+}
+
+// SetCheckService forwards a SetCheckService call
+func (s *forwardingSender) SetCheckService(service string) {
+	s.forward(&senderCall{Method: methodSetCheckService, Service: service})
+}
+
+// SetNoIndex forwards a SetNoIndex call
+func (s *forwardingSender) SetNoIndex(noIndex bool) {
+	s.forward(&senderCall{Method: methodSetNoIndex, Flag: noIndex})
+}
+
+// FinalizeCheckServiceTag forwards a FinalizeCheckServiceTag call
+func (s *forwardingSender) FinalizeCheckServiceTag() {
+	s.forward(&senderCall{Method: methodFinalizeCheckServiceTag})
+}
+
+// OrchestratorMetadata isn't supported by isolated checks
+func (s *forwardingSender) OrchestratorMetadata(_ []types.ProcessMessageBody, _ string, _ int) {
+	log.Warn("Orchestrator metadata can't be sent by isolated checks")
+}
+
+// OrchestratorManifest isn't supported by isolated checks
+func (s *forwardingSender) OrchestratorManifest(_ []types.ProcessMessageBody, _ string) {
+	log.Warn("Orchestrator manifests can't be sent by isolated checks")
+}
+
+// forwardingSenderManager provides the forwarding sender to the check run by a worker
+type forwardingSenderManager struct {
+	sender *forwardingSender
+}
+
+var _ sender.SenderManager = (*forwardingSenderManager)(nil)
+
+// GetSender returns the forwarding sender: a worker runs a single check
+func (m *forwardingSenderManager) GetSender(_ checkid.ID) (sender.Sender, error) {
+	return m.sender, nil
+}
+
+// SetSender isn't supported by workers
+func (m *forwardingSenderManager) SetSender(_ sender.Sender, _ checkid.ID) error {
+	return errors.New("the sender of an isolated check can't be replaced")
+}
+
+// DestroySender does nothing: the sender is destroyed by the agent
+func (m *forwardingSenderManager) DestroySender(_ checkid.ID) {}
+
+// GetDefaultSender returns the forwarding sender
+func (m *forwardingSenderManager) GetDefaultSender() (sender.Sender, error) {
+	return m.sender, nil
+}
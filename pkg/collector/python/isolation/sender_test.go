@@ -0,0 +1,71 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build test
+
+package isolation
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics/event"
+	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
+)
+
+func TestForwardingSender(t *testing.T) {
+	agentSide, workerSide := net.Pipe()
+	agent := newConn(agentSide)
+	w := newWorker(newConn(workerSide))
+
+	s := mocksender.NewMockSender("fake:1")
+	s.SetupAcceptAll()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			m, err := agent.receive()
+			if err != nil {
+				return
+			}
+			if assert.Equal(t, kindSenderCall, m.Kind) {
+				assert.NoError(t, m.Call.apply(s))
+			}
+		}
+	}()
+
+	fs, err := w.SenderManager().GetSender("fake:1")
+	require.NoError(t, err)
+	fs.Gauge("test.gauge", 1.5, "host", []string{"foo:bar"})
+	fs.MonotonicCountWithFlushFirstValue("test.count", 10, "", []string{"foo:bar"}, true)
+	fs.HistogramBucket("test.bucket", 3, 0, 10, true, "", []string{"foo:bar"}, false)
+	require.NoError(t, fs.GaugeWithTimestamp("test.timestamped", 2, "", []string{"foo:bar"}, 1700000000.5))
+	fs.ServiceCheck("test.can_connect", servicecheck.ServiceCheckCritical, "", []string{"foo:bar"}, "unreachable")
+	fs.Event(event.Event{Title: "title", Text: "text", Tags: []string{"foo:bar"}})
+	fs.SetCheckService("service")
+	fs.Commit()
+	require.NoError(t, workerSide.Close())
+	<-done
+
+	s.AssertMetric(t, "Gauge", "test.gauge", 1.5, "host", []string{"foo:bar"})
+	s.AssertMonotonicCount(t, "MonotonicCountWithFlushFirstValue", "test.count", 10, "", []string{"foo:bar"}, true)
+	s.AssertHistogramBucket(t, "HistogramBucket", "test.bucket", 3, 0, 10, true, "", []string{"foo:bar"}, false)
+	s.AssertMetricWithTimestamp(t, "GaugeWithTimestamp", "test.timestamped", 2, "", []string{"foo:bar"}, 1700000000.5)
+	s.AssertServiceCheck(t, "test.can_connect", servicecheck.ServiceCheckCritical, "", []string{"foo:bar"}, "unreachable")
+	s.AssertCalled(t, "Event", event.Event{Title: "title", Text: "text", Tags: []string{"foo:bar"}})
+	s.AssertCalled(t, "SetCheckService", "service")
+	s.AssertNumberOfCalls(t, "Commit", 1)
+}
+
+func TestSenderCallUnknownMethod(t *testing.T) {
+	s := mocksender.NewMockSender("fake:1")
+	assert.EqualError(t, (&senderCall{Method: "unknown"}).apply(s), "unknown sender method unknown")
+	assert.EqualError(t, (&senderCall{Method: methodEvent}).apply(s), "missing event")
+}
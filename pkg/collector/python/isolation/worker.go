@@ -0,0 +1,150 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package isolation
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// LoadFunc loads a check instance, as check.Loader.Load
+type LoadFunc func(senderManager sender.SenderManager, config integration.Config, instance integration.Data, instanceIndex int) (check.Check, error)
+
+// Worker serves the requests of the agent in a worker process
+type Worker struct {
+	conn          *conn
+	senderManager *forwardingSenderManager
+	check         check.Check
+}
+
+// Dial connects the worker to the agent listening on socketPath. It marks the current process
+// as a worker process.
+func Dial(socketPath string) (*Worker, error) {
+	c, err := net.Dial("unix", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to the agent: %w", err)
+	}
+	isWorkerProcess.Store(true)
+	return newWorker(newConn(c)), nil
+}
+
+func newWorker(c *conn) *Worker {
+	return &Worker{
+		conn:          c,
+		senderManager: &forwardingSenderManager{sender: &forwardingSender{conn: c}},
+	}
+}
+
+// SenderManager returns the sender manager to give to the check loader: the calls to its
+// senders are forwarded to the agent.
+func (w *Worker) SenderManager() sender.SenderManager {
+	return w.senderManager
+}
+
+// Serve serves the requests of the agent until it closes the connection, loading the check
+// with load when asked to configure it. The check is cancelled before returning.
+func (w *Worker) Serve(load LoadFunc) error {
+	defer w.conn.close()
+	defer func() {
+		if w.check != nil {
+			w.check.Cancel()
+		}
+	}()
+
+	for {
+		m, err := w.conn.receive()
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return fmt.Errorf("unable to receive the requests of the agent: %w", err)
+		}
+
+		var reply *message
+		switch m.Kind {
+		case kindConfigure:
+			reply = w.configure(m.Configure, load)
+		case kindRun:
+			reply = w.run()
+		case kindDiagnose:
+			reply = w.diagnose()
+		default:
+			log.Warnf("Ignoring unknown request %q from the agent", m.Kind)
+			continue
+		}
+		if err := w.conn.send(reply); err != nil {
+			return fmt.Errorf("unable to reply to the agent: %w", err)
+		}
+	}
+}
+
+func (w *Worker) configure(req *configureRequest, load LoadFunc) *message {
+	reply := &message{Kind: kindConfigured}
+	if req == nil {
+		reply.Error = "missing check configuration"
+		return reply
+	}
+	if w.check != nil {
+		reply.Error = "the check is already configured"
+		return reply
+	}
+
+	config := integration.Config{
+		Name:       req.Name,
+		InitConfig: integration.Data(req.InitConfig),
+		Instances:  []integration.Data{integration.Data(req.Instance)},
+		Source:     req.Source,
+	}
+	c, err := load(w.senderManager, config, config.Instances[0], -1)
+	if err != nil {
+		reply.Error = err.Error()
+		reply.Skip = errors.Is(err, check.ErrSkipCheckInstance)
+		return reply
+	}
+
+	w.check = c
+	reply.Version = c.Version()
+	reply.HASupported = c.IsHASupported()
+	return reply
+}
+
+func (w *Worker) run() *message {
+	reply := &message{Kind: kindRunDone}
+	if w.check == nil {
+		reply.Error = "the check isn't configured"
+		return reply
+	}
+
+	if err := w.check.Run(); err != nil {
+		reply.Error = err.Error()
+	}
+	for _, warning := range w.check.GetWarnings() {
+		reply.Warnings = append(reply.Warnings, warning.Error())
+	}
+	return reply
+}
+
+func (w *Worker) diagnose() *message {
+	reply := &message{Kind: kindDiagnoses}
+	if w.check == nil {
+		reply.Error = "the check isn't configured"
+		return reply
+	}
+
+	diagnoses, err := w.check.GetDiagnoses()
+	if err != nil {
+		reply.Error = err.Error()
+	}
+	reply.Diagnoses = diagnoses
+	return reply
+}
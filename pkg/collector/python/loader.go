@@ -26,6 +26,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/collector/loaders"
+	"github.com/DataDog/datadog-agent/pkg/collector/python/isolation"
 	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/tagset"
@@ -120,6 +121,11 @@ func (*PythonCheckLoader) Name() string {
 // Load tries to import a Python module with the same name found in config.Name, searches for
 // subclasses of the AgentCheck class and returns the corresponding Check
 func (cl *PythonCheckLoader) Load(senderManager sender.SenderManager, config integration.Config, instance integration.Data, instanceIndex int) (check.Check, error) {
+	// Instances configured with `isolation: process` are loaded by a worker process
+	if isolation.Requested(instance) && !isolation.IsWorkerProcess() {
+		return cl.loadIsolated(senderManager, config, instance, instanceIndex)
+	}
+
 	if pkgconfigsetup.Datadog().GetBool("python_lazy_loading") {
 		pythonOnce.Do(func() {
 			InitPython(common.GetPythonPaths()...)
@@ -269,6 +275,27 @@ func (cl *PythonCheckLoader) Load(senderManager sender.SenderManager, config int
 	return c, nil
 }
 
+// loadIsolated returns a check running the instance in a supervised worker process
+func (cl *PythonCheckLoader) loadIsolated(senderManager sender.SenderManager, config integration.Config, instance integration.Data, instanceIndex int) (check.Check, error) {
+	configSource := config.Source
+	if instanceIndex >= 0 {
+		configSource = fmt.Sprintf("%s[%d]", configSource, instanceIndex)
+	}
+
+	c := isolation.NewCheck(senderManager, config.Name, PythonCheckLoaderName)
+	if err := c.Configure(senderManager, config.FastDigest(), instance, config.InitConfig, configSource); err != nil {
+		if errors.Is(err, check.ErrSkipCheckInstance) {
+			return nil, err
+		}
+
+		addExpvarConfigureError(config.Name, err.Error())
+		return c, fmt.Errorf("could not configure isolated check instance for python check %s: %s", config.Name, err.Error())
+	}
+
+	log.Debugf("python loader: done loading isolated check %s (version %s)", config.Name, c.Version())
+	return c, nil
+}
+
 func (cl *PythonCheckLoader) String() string {
 	return "Python Check Loader"
 }
@@ -114,13 +114,26 @@ func AddCheckStats(c check.Check,
 	haagent haagent.Component,
 ) {
 
-	var s *checkstats.Stats
-
 	checkStats.statsLock.Lock()
 	defer checkStats.statsLock.Unlock()
 
 	log.Tracef("Adding stats for %s", string(c.ID()))
 
+	getOrCreateCheckStats(c).Add(execTime, err, warnings, mStats, haagent)
+}
+
+// SetCheckTimedOut marks the current run of the check as timed out after the run timeout,
+// with the given stack of the check
+func SetCheckTimedOut(c check.Check, timeout time.Duration, stack string) {
+	checkStats.statsLock.Lock()
+	defer checkStats.statsLock.Unlock()
+
+	getOrCreateCheckStats(c).SetTimedOut(timeout, stack)
+}
+
+// getOrCreateCheckStats returns the stats of the check, creating them if needed. It must be
+// called with checkStats.statsLock held.
+func getOrCreateCheckStats(c check.Check) *checkstats.Stats {
 	checkName := checkid.IDToCheckName(c.ID())
 	stats, found := checkStats.stats[checkName]
 	if !found {
@@ -128,13 +141,13 @@ func AddCheckStats(c check.Check,
 		checkStats.stats[checkName] = stats
 	}
 
-	s, found = stats[c.ID()]
+	s, found := stats[c.ID()]
 	if !found {
 		s = checkstats.NewStats(c)
 		stats[c.ID()] = s
 	}
 
-	s.Add(execTime, err, warnings, mStats, haagent)
+	return s
 }
 
 // RemoveCheckStats removes a check from the check stats map
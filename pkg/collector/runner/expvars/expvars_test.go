@@ -307,6 +307,28 @@ func TestExpvarsCheckStats(t *testing.T) {
 	}
 }
 
+func TestExpvarsSetCheckTimedOut(t *testing.T) {
+	setUp()
+
+	testCheck := newTestCheck("testcheck:1")
+
+	// the stats are created if the first run of the check times out
+	SetCheckTimedOut(testCheck, time.Minute, "goroutine 42 [IO wait]:")
+	actualStats, found := CheckStats(testCheck.ID())
+	require.True(t, found)
+	assert.True(t, actualStats.TimedOut)
+	assert.Equal(t, "goroutine 42 [IO wait]:", actualStats.TimeoutStack)
+	assert.Equal(t, time.Minute, actualStats.RunTimeout)
+	assert.EqualValues(t, 0, actualStats.TotalRuns)
+
+	AddCheckStats(testCheck, 2*time.Minute, fmt.Errorf("timed out"), nil, stats.SenderStats{}, haagentmock.NewMockHaAgent())
+	actualStats, found = CheckStats(testCheck.ID())
+	require.True(t, found)
+	assert.False(t, actualStats.TimedOut)
+	assert.EqualValues(t, 1, actualStats.TotalTimeouts)
+	assert.EqualValues(t, 1, actualStats.TotalRuns)
+}
+
 func TestExpvarsGetChecksStatsClone(t *testing.T) {
 	numCheckNames := 3
 	numCheckInstances := 5
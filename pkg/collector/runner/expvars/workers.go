@@ -17,12 +17,14 @@ const (
 	// instancesExpvarKey - Nested key for the instances expvar
 	instancesExpvarKey = "Instances"
 
-	countExpvarKey = "Count"
+	countExpvarKey         = "Count"
+	timedOutCountExpvarKey = "TimedOutCount"
 )
 
 var (
 	workerInstancesStats *expvar.Map
 	workersStats         *expvar.Map
+	timedOutWorkers      map[string]struct{}
 	workersStatsLock     sync.Mutex
 )
 
@@ -42,9 +44,11 @@ func newWorkersExpvar(parent *expvar.Map) {
 	defer workersStatsLock.Unlock()
 
 	workerInstancesStats = &expvar.Map{}
+	timedOutWorkers = make(map[string]struct{})
 
 	workersStats = &expvar.Map{}
 	workersStats.Add(countExpvarKey, 0)
+	workersStats.Add(timedOutCountExpvarKey, 0)
 	workersStats.Set(instancesExpvarKey, workerInstancesStats)
 
 	parent.Set(workersExpvarKey, workersStats)
@@ -74,7 +78,12 @@ func DeleteWorkerStats(name string) {
 	workersStatsLock.Lock()
 
 	if workerInstancesStats.Get(name) != nil {
-		workersStats.Add(countExpvarKey, -1)
+		if _, timedOut := timedOutWorkers[name]; timedOut {
+			workersStats.Add(timedOutCountExpvarKey, -1)
+			delete(timedOutWorkers, name)
+		} else {
+			workersStats.Add(countExpvarKey, -1)
+		}
 	}
 
 	workerInstancesStats.Delete(name)
@@ -82,6 +91,24 @@ func DeleteWorkerStats(name string) {
 	workersStatsLock.Unlock()
 }
 
+// SetWorkerTimedOut is used to move a worker blocked by a timed out check from the count
+// of the running workers to the count of the timed out workers, until it is deleted
+func SetWorkerTimedOut(name string) {
+	workersStatsLock.Lock()
+	defer workersStatsLock.Unlock()
+
+	if workerInstancesStats.Get(name) == nil {
+		return
+	}
+	if _, timedOut := timedOutWorkers[name]; timedOut {
+		return
+	}
+
+	timedOutWorkers[name] = struct{}{}
+	workersStats.Add(countExpvarKey, -1)
+	workersStats.Add(timedOutCountExpvarKey, 1)
+}
+
 // GetWorkerCount is used to get the value of 'Workers'->'Count' expvar
 func GetWorkerCount() int {
 	count := workersStats.Get(countExpvarKey)
@@ -92,6 +119,16 @@ func GetWorkerCount() int {
 	return int(count.(*expvar.Int).Value())
 }
 
+// GetTimedOutWorkerCount is used to get the value of 'Workers'->'TimedOutCount' expvar
+func GetTimedOutWorkerCount() int {
+	count := workersStats.Get(timedOutCountExpvarKey)
+	if count == nil {
+		return 0
+	}
+
+	return int(count.(*expvar.Int).Value())
+}
+
 // GetWorkers returns the workers expvar Map from the runner
 func GetWorkers() *expvar.Map {
 	runner := GetRunner()
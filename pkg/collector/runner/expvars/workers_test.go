@@ -48,7 +48,7 @@ func getWorkerInstancesStats(t *testing.T) string {
 func assertExpectedInitialState(t *testing.T) {
 	workersStats := getWorkersStatsExpvarMap(t)
 	keys := getExpvarMapKeys(workersStats)
-	assert.Equal(t, []string{"Count", "Instances"}, keys)
+	assert.Equal(t, []string{"Count", "Instances", "TimedOutCount"}, keys)
 
 	instances := workersStats.Get("Instances")
 	require.NotNil(t, instances)
@@ -59,12 +59,13 @@ func assertExpectedInitialState(t *testing.T) {
 	assert.Equal(t, "0", count.String())
 
 	assert.Equal(t, 0, GetWorkerCount())
+	assert.Equal(t, 0, GetTimedOutWorkerCount())
 }
 
 func assertWorkerInstanceStats(t *testing.T, stats map[string]*WorkerStats) {
 	workersStats := getWorkersStatsExpvarMap(t)
 	keys := getExpvarMapKeys(workersStats)
-	assert.Equal(t, []string{"Count", "Instances"}, keys)
+	assert.Equal(t, []string{"Count", "Instances", "TimedOutCount"}, keys)
 
 	instances := workersStats.Get("Instances")
 	require.NotNil(t, instances)
@@ -276,3 +277,33 @@ func TestWorkersCountAsync(t *testing.T) {
 
 	require.Equal(t, 2, GetWorkerCount())
 }
+
+func TestWorkersTimedOut(t *testing.T) {
+	setUp()
+
+	SetWorkerStats("worker1", &WorkerStats{})
+	SetWorkerStats("worker2", &WorkerStats{})
+
+	SetWorkerTimedOut("worker1")
+	assert.Equal(t, 1, GetWorkerCount())
+	assert.Equal(t, 1, GetTimedOutWorkerCount())
+
+	// Timing out twice or updating the stats of a timed out worker does not change the counts
+	SetWorkerTimedOut("worker1")
+	SetWorkerStats("worker1", &WorkerStats{Utilization: 1})
+	assert.Equal(t, 1, GetWorkerCount())
+	assert.Equal(t, 1, GetTimedOutWorkerCount())
+
+	// Unknown workers are ignored
+	SetWorkerTimedOut("unknown")
+	assert.Equal(t, 1, GetWorkerCount())
+	assert.Equal(t, 1, GetTimedOutWorkerCount())
+
+	DeleteWorkerStats("worker1")
+	assert.Equal(t, 1, GetWorkerCount())
+	assert.Equal(t, 0, GetTimedOutWorkerCount())
+
+	DeleteWorkerStats("worker2")
+	assert.Equal(t, 0, GetWorkerCount())
+	assert.Equal(t, 0, GetTimedOutWorkerCount())
+}
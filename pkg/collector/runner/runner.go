@@ -45,6 +45,7 @@ type Runner struct {
 	isRunning           *atomic.Bool
 	id                  int                           // Globally unique identifier for the Runner
 	workers             map[int]*worker.Worker        // Workers currrently under this Runner's management
+	timedOutWorkers     map[int]*worker.Worker        // Workers blocked by a timed out check, exiting once it returns
 	workersLock         sync.Mutex                    // Lock to prevent concurrent worker changes
 	isStaticWorkerCount bool                          // Flag indicating if numWorkers is dynamically updated
 	pendingChecksChan   chan check.Check              // The channel where checks come from
@@ -65,6 +66,7 @@ func NewRunner(senderManager sender.SenderManager, haAgent haagent.Component) *R
 		id:                  int(runnerIDGenerator.Inc()),
 		isRunning:           atomic.NewBool(true),
 		workers:             make(map[int]*worker.Worker),
+		timedOutWorkers:     make(map[int]*worker.Worker),
 		isStaticWorkerCount: numWorkers != 0,
 		pendingChecksChan:   make(chan check.Check),
 		checksTracker:       tracker.NewRunningChecksTracker(),
@@ -125,14 +127,16 @@ func (r *Runner) AddWorker() {
 
 // newWorker adds a new worker running in a separate goroutine
 func (r *Runner) newWorker() (*worker.Worker, error) {
+	workerID := int(workerIDGenerator.Inc())
 	worker, err := worker.NewWorker(
 		r.senderManager,
 		r.haAgent,
 		r.id,
-		int(workerIDGenerator.Inc()),
+		workerID,
 		r.pendingChecksChan,
 		r.checksTracker,
 		r.ShouldAddCheckStats,
+		func() { r.replaceTimedOutWorker(workerID) },
 	)
 	if err != nil {
 		log.Errorf("Runner %d was unable to instantiate a worker: %s", r.id, err)
@@ -148,11 +152,35 @@ func (r *Runner) newWorker() (*worker.Worker, error) {
 	return worker, nil
 }
 
+// replaceTimedOutWorker starts a new worker to replace the worker blocked by a check which
+// exceeded its run timeout, so that the other checks keep running. The blocked worker no
+// longer counts as one of the runner workers until it exits.
+func (r *Runner) replaceTimedOutWorker(id int) {
+	r.workersLock.Lock()
+	defer r.workersLock.Unlock()
+
+	if timedOutWorker, found := r.workers[id]; found {
+		delete(r.workers, id)
+		r.timedOutWorkers[id] = timedOutWorker
+	}
+
+	if !r.isRunning.Load() {
+		return
+	}
+
+	log.Infof("Runner %d is adding a worker to replace worker %d, blocked by a timed out check", r.id, id)
+	worker, err := r.newWorker()
+	if err == nil {
+		r.workers[worker.ID] = worker
+	}
+}
+
 func (r *Runner) removeWorker(id int) {
 	r.workersLock.Lock()
 	defer r.workersLock.Unlock()
 
 	delete(r.workers, id)
+	delete(r.timedOutWorkers, id)
 }
 
 // UpdateNumWorkers checks if the current number of workers is reasonable,
@@ -186,6 +186,47 @@ func TestRunnerAddWorker(t *testing.T) {
 	assertAsyncWorkerCount(t, 4)
 }
 
+func TestRunnerReplaceTimedOutWorker(t *testing.T) {
+	mockConfig := testSetUp(t)
+	mockConfig.SetWithoutSource("check_runners", "1")
+	mockConfig.SetWithoutSource("check_run_timeout", "50ms")
+
+	r := NewRunner(aggregator.NewNoOpSenderManager(), haagentmock.NewMockHaAgent())
+	require.NotNil(t, r)
+	defer r.Stop()
+
+	assertAsyncWorkerCount(t, 1)
+
+	release := make(chan struct{})
+	hungCheck := newCheck(t, "hungcheck:123", false, func(checkid.ID) { <-release })
+	r.GetChan() <- hungCheck
+
+	countWorkers := func() (int, int) {
+		r.workersLock.Lock()
+		defer r.workersLock.Unlock()
+		return len(r.workers), len(r.timedOutWorkers)
+	}
+
+	// the blocked worker is replaced and no longer counts as a runner worker
+	require.Eventually(t, func() bool {
+		workers, timedOutWorkers := countWorkers()
+		return workers == 1 && timedOutWorkers == 1 && expvars.GetTimedOutWorkerCount() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assertAsyncWorkerCount(t, 1)
+
+	goodCheck := newCheck(t, "goodcheck:123", false, nil)
+	r.GetChan() <- goodCheck
+	require.Eventually(t, func() bool { return goodCheck.RunCount() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	// the blocked worker exits once the hung check returns
+	close(release)
+	require.Eventually(t, func() bool {
+		workers, timedOutWorkers := countWorkers()
+		return workers == 1 && timedOutWorkers == 0 && expvars.GetTimedOutWorkerCount() == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	assertAsyncWorkerCount(t, 1)
+}
+
 func TestRunnerStaticUpdateNumWorkers(t *testing.T) {
 	mockConfig := testSetUp(t)
 	mockConfig.SetWithoutSource("check_runners", "2")
@@ -0,0 +1,159 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package worker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime"
+	"strconv"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/runner/expvars"
+	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
+	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
+	"github.com/DataDog/datadog-agent/pkg/util/hostname"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// maxStackDumpSize is the maximum size of the dump of all the goroutine stacks used to
+	// find the stack of a timed out check
+	maxStackDumpSize = 64 << 20
+)
+
+// runTimeout returns the maximum duration of a run of the check: the `run_timeout` of its
+// instance configuration, or else of its `init_config`, or else `check_run_timeout`. A zero
+// duration means that the run isn't limited.
+func runTimeout(c check.Check) time.Duration {
+	for _, conf := range []string{c.InstanceConfig(), c.InitConfig()} {
+		if conf == "" {
+			continue
+		}
+		common := integration.CommonInstanceConfig{}
+		if err := yaml.Unmarshal([]byte(conf), &common); err == nil && common.RunTimeout > 0 {
+			return time.Duration(common.RunTimeout) * time.Second
+		}
+	}
+	return pkgconfigsetup.Datadog().GetDuration("check_run_timeout")
+}
+
+// runWatchdog calls onTimeout with the stack of the goroutine running a check if the run
+// lasts longer than its timeout.
+type runWatchdog struct {
+	timer  *time.Timer
+	fired  chan struct{} // closed once onTimeout returns
+	cancel context.CancelFunc
+}
+
+// startRunWatchdog starts watching a check run. It must be called from the goroutine
+// running the check. The returned context is cancelled when the run times out or when the
+// watchdog is stopped. A zero timeout disables the watchdog.
+func startRunWatchdog(timeout time.Duration, onTimeout func(stack string)) (*runWatchdog, context.Context) {
+	ctx, cancel := context.WithCancel(context.Background())
+	wd := &runWatchdog{
+		fired:  make(chan struct{}),
+		cancel: cancel,
+	}
+	if timeout <= 0 {
+		return wd, ctx
+	}
+	goid := currentGoroutineID()
+	wd.timer = time.AfterFunc(timeout, func() {
+		defer close(wd.fired)
+		onTimeout(goroutineStack(goid))
+		cancel()
+	})
+	return wd, ctx
+}
+
+// stop stops the watchdog once the run is complete, and reports whether the run timed out.
+func (wd *runWatchdog) stop() bool {
+	defer wd.cancel()
+	if wd.timer == nil || wd.timer.Stop() {
+		return false
+	}
+	// the timer fired; wait for onTimeout to return
+	<-wd.fired
+	return true
+}
+
+// setRunContext sets the context of the next run of the check, if it supports it. A nil
+// context resets it once the run is complete.
+func setRunContext(c check.Check, ctx context.Context) {
+	if setter, ok := c.(check.RunContextSetter); ok {
+		setter.SetRunContext(ctx)
+	}
+}
+
+// checkTimedOut reports that the current run of the check exceeded its timeout: the check is
+// marked as timed out in its stats, a critical service check is sent and checkTimeoutFunc is
+// called so that the runner starts a replacement worker.
+func (w *Worker) checkTimedOut(c check.Check, timeout time.Duration, stack string) {
+	log.Warnf("Runner %d, worker %d: Check %s has been running for more than its timeout of %s, aborting it. Stack:\n%s",
+		w.runnerID, w.ID, c, timeout, stack)
+
+	expvars.SetCheckTimedOut(c, timeout, stack)
+	if w.checkTimeoutFunc != nil {
+		// the worker exits once the hung check returns, it no longer counts as a running worker
+		expvars.SetWorkerTimedOut(w.Name)
+	}
+
+	if sender, err := w.getDefaultSenderFunc(); err != nil {
+		log.Errorf("Error getting default sender: %v. Not sending timeout status check for %s", err, c)
+	} else {
+		hname, _ := hostname.Get(context.TODO())
+		serviceCheckTags := []string{fmt.Sprintf("check:%s", c.String()), "dd_enable_check_intake:true"}
+		message := fmt.Sprintf("Check run exceeded its timeout of %s", timeout)
+		sender.ServiceCheck(serviceCheckStatusKey, servicecheck.ServiceCheckCritical, hname, serviceCheckTags, message)
+		sender.Commit()
+	}
+
+	if w.checkTimeoutFunc != nil {
+		w.checkTimeoutFunc()
+	}
+}
+
+// currentGoroutineID returns the ID of the calling goroutine, as shown in stack dumps.
+func currentGoroutineID() uint64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	// the dump starts with "goroutine <id> [<state>]:"
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	if i := bytes.IndexByte(buf, ' '); i >= 0 {
+		buf = buf[:i]
+	}
+	id, _ := strconv.ParseUint(string(buf), 10, 64)
+	return id
+}
+
+// goroutineStack returns the stack of the goroutine with the given ID, or an empty string if
+// it can't be found.
+func goroutineStack(id uint64) string {
+	size := 1 << 20
+	var buf []byte
+	for {
+		buf = make([]byte, size)
+		n := runtime.Stack(buf, true)
+		if n < size || size >= maxStackDumpSize {
+			buf = buf[:n]
+			break
+		}
+		size *= 2
+	}
+	header := []byte(fmt.Sprintf("goroutine %d ", id))
+	for _, stack := range bytes.Split(buf, []byte("\n\n")) {
+		if bytes.HasPrefix(stack, header) {
+			return string(bytes.TrimSpace(stack))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check/stub"
+	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
+)
+
+type configuredCheck struct {
+	stub.StubCheck
+	initConfig     string
+	instanceConfig string
+}
+
+func (c *configuredCheck) InitConfig() string     { return c.initConfig }
+func (c *configuredCheck) InstanceConfig() string { return c.instanceConfig }
+
+func TestRunTimeout(t *testing.T) {
+	mockConfig := configmock.New(t)
+
+	for name, tc := range map[string]struct {
+		initConfig     string
+		instanceConfig string
+		expected       time.Duration
+	}{
+		"default":         {expected: 0},
+		"init config":     {initConfig: "run_timeout: 20", instanceConfig: "host: localhost", expected: 20 * time.Second},
+		"instance config": {initConfig: "run_timeout: 20", instanceConfig: "run_timeout: 5", expected: 5 * time.Second},
+		"invalid":         {instanceConfig: "run_timeout: soon", expected: 0},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := &configuredCheck{initConfig: tc.initConfig, instanceConfig: tc.instanceConfig}
+			assert.Equal(t, tc.expected, runTimeout(c))
+		})
+	}
+
+	mockConfig.SetWithoutSource("check_run_timeout", "1m")
+	assert.Equal(t, time.Minute, runTimeout(&configuredCheck{}))
+	assert.Equal(t, 5*time.Second, runTimeout(&configuredCheck{instanceConfig: "run_timeout: 5"}))
+}
+
+func TestRunWatchdog(t *testing.T) {
+	t.Run("completed", func(t *testing.T) {
+		watchdog, ctx := startRunWatchdog(time.Hour, func(string) { t.Error("unexpected timeout") })
+		assert.False(t, watchdog.stop())
+		assert.Error(t, ctx.Err())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		watchdog, ctx := startRunWatchdog(0, func(string) { t.Error("unexpected timeout") })
+		assert.NoError(t, ctx.Err())
+		assert.False(t, watchdog.stop())
+	})
+
+	t.Run("timed out", func(t *testing.T) {
+		stacks := make(chan string, 1)
+		watchdog, ctx := startRunWatchdog(10*time.Millisecond, func(stack string) { stacks <- stack })
+		<-ctx.Done()
+		assert.True(t, watchdog.stop())
+
+		// the stack is the one of the goroutine which started the watchdog
+		stack := <-stacks
+		require.NotEmpty(t, stack)
+		assert.Contains(t, stack, "TestRunWatchdog")
+	})
+}
+
+func TestGoroutineStack(t *testing.T) {
+	id := currentGoroutineID()
+	require.NotZero(t, id)
+
+	stack := goroutineStack(id)
+	assert.Contains(t, stack, "TestGoroutineStack")
+	assert.Empty(t, goroutineStack(0))
+}
@@ -55,9 +55,13 @@ type Worker struct {
 	shouldAddCheckStatsFunc func(id checkid.ID) bool
 	utilizationTickInterval time.Duration
 	haAgent                 haagent.Component
+	checkTimeoutFunc        func()
 }
 
-// NewWorker returns an instance of a `Worker` after parameter sanity checks are passed
+// NewWorker returns an instance of a `Worker` after parameter sanity checks are passed.
+// When a check run exceeds its timeout, the worker calls `checkTimeoutFunc`, if set, so that
+// a replacement worker can be started, and it stops processing checks once the hung check
+// eventually returns.
 func NewWorker(
 	senderManager sender.SenderManager,
 	haAgent haagent.Component,
@@ -66,6 +70,7 @@ func NewWorker(
 	pendingChecksChan chan check.Check,
 	checksTracker *tracker.RunningChecksTracker,
 	shouldAddCheckStatsFunc func(id checkid.ID) bool,
+	checkTimeoutFunc func(),
 ) (*Worker, error) {
 
 	if checksTracker == nil {
@@ -89,6 +94,7 @@ func NewWorker(
 		senderManager.GetDefaultSender,
 		haAgent,
 		pollingInterval,
+		checkTimeoutFunc,
 	)
 }
 
@@ -104,6 +110,7 @@ func newWorkerWithOptions(
 	getDefaultSenderFunc func() (sender.Sender, error),
 	haAgent haagent.Component,
 	utilizationTickInterval time.Duration,
+	checkTimeoutFunc func(),
 ) (*Worker, error) {
 
 	if getDefaultSenderFunc == nil {
@@ -122,6 +129,7 @@ func newWorkerWithOptions(
 		getDefaultSenderFunc:    getDefaultSenderFunc,
 		haAgent:                 haAgent,
 		utilizationTickInterval: utilizationTickInterval,
+		checkTimeoutFunc:        checkTimeoutFunc,
 	}, nil
 }
 
@@ -161,8 +169,21 @@ func (w *Worker) Run() {
 
 		utilizationTracker.Started()
 
-		// Run the check
+		// Run the check, under the watch of a watchdog if it has a run timeout
+		timeout := time.Duration(0)
+		if !longRunning {
+			timeout = runTimeout(check)
+		}
+		watchdog, runCtx := startRunWatchdog(timeout, func(stack string) {
+			w.checkTimedOut(check, timeout, stack)
+		})
+		setRunContext(check, runCtx)
 		checkErr := check.Run()
+		timedOut := watchdog.stop()
+		setRunContext(check, nil)
+		if timedOut && checkErr == nil {
+			checkErr = fmt.Errorf("check run exceeded its timeout of %s", timeout)
+		}
 
 		utilizationTracker.Finished()
 
@@ -218,6 +239,12 @@ func (w *Worker) Run() {
 		}
 
 		checkLogger.CheckFinished()
+
+		if timedOut && w.checkTimeoutFunc != nil {
+			// a replacement worker was started when the check timed out
+			log.Infof("Runner %d, worker %d: Exiting after the completion of timed out check %s", w.runnerID, w.ID, check)
+			return
+		}
 	}
 
 	log.Debugf("Runner %d, worker %d: Finished processing checks.", w.runnerID, w.ID)
@@ -6,8 +6,11 @@
 package worker
 
 import (
+	"context"
 	"expvar"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"sync"
 	"testing"
 	"time"
@@ -16,6 +19,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/atomic"
 
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/comp/core/config"
 	"github.com/DataDog/datadog-agent/comp/core/hostname/hostnameimpl"
 	logmock "github.com/DataDog/datadog-agent/comp/core/log/mock"
@@ -27,6 +31,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	checkid "github.com/DataDog/datadog-agent/pkg/collector/check/id"
 	"github.com/DataDog/datadog-agent/pkg/collector/check/stub"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks"
 	"github.com/DataDog/datadog-agent/pkg/collector/runner/expvars"
 	"github.com/DataDog/datadog-agent/pkg/collector/runner/tracker"
 	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
@@ -147,16 +152,16 @@ func TestWorkerInit(t *testing.T) {
 	mockShouldAddStatsFunc := func(checkid.ID) bool { return true }
 
 	senderManager := aggregator.NewNoOpSenderManager()
-	_, err := NewWorker(senderManager, haagentmock.NewMockHaAgent(), 1, 2, nil, checksTracker, mockShouldAddStatsFunc)
+	_, err := NewWorker(senderManager, haagentmock.NewMockHaAgent(), 1, 2, nil, checksTracker, mockShouldAddStatsFunc, nil)
 	require.NotNil(t, err)
 
-	_, err = NewWorker(senderManager, haagentmock.NewMockHaAgent(), 1, 2, pendingChecksChan, nil, mockShouldAddStatsFunc)
+	_, err = NewWorker(senderManager, haagentmock.NewMockHaAgent(), 1, 2, pendingChecksChan, nil, mockShouldAddStatsFunc, nil)
 	require.NotNil(t, err)
 
-	_, err = NewWorker(senderManager, haagentmock.NewMockHaAgent(), 1, 2, pendingChecksChan, checksTracker, nil)
+	_, err = NewWorker(senderManager, haagentmock.NewMockHaAgent(), 1, 2, pendingChecksChan, checksTracker, nil, nil)
 	require.NotNil(t, err)
 
-	worker, err := NewWorker(senderManager, haagentmock.NewMockHaAgent(), 1, 2, pendingChecksChan, checksTracker, mockShouldAddStatsFunc)
+	worker, err := NewWorker(senderManager, haagentmock.NewMockHaAgent(), 1, 2, pendingChecksChan, checksTracker, mockShouldAddStatsFunc, nil)
 	assert.Nil(t, err)
 	assert.NotNil(t, worker)
 }
@@ -178,7 +183,7 @@ func TestWorkerInitExpvarStats(t *testing.T) {
 		go func(idx int) {
 			defer wg.Done()
 
-			worker, err := NewWorker(aggregator.NewNoOpSenderManager(), haagentmock.NewMockHaAgent(), 1, idx, pendingChecksChan, checksTracker, mockShouldAddStatsFunc)
+			worker, err := NewWorker(aggregator.NewNoOpSenderManager(), haagentmock.NewMockHaAgent(), 1, idx, pendingChecksChan, checksTracker, mockShouldAddStatsFunc, nil)
 			assert.Nil(t, err)
 
 			worker.Run()
@@ -200,7 +205,7 @@ func TestWorkerName(t *testing.T) {
 
 	for _, id := range []int{1, 100, 500} {
 		expectedName := fmt.Sprintf("worker_%d", id)
-		worker, err := NewWorker(aggregator.NewNoOpSenderManager(), haagentmock.NewMockHaAgent(), 1, id, pendingChecksChan, checksTracker, mockShouldAddStatsFunc)
+		worker, err := NewWorker(aggregator.NewNoOpSenderManager(), haagentmock.NewMockHaAgent(), 1, id, pendingChecksChan, checksTracker, mockShouldAddStatsFunc, nil)
 		assert.Nil(t, err)
 		assert.NotNil(t, worker)
 
@@ -253,7 +258,7 @@ func TestWorker(t *testing.T) {
 	pendingChecksChan <- testCheck1
 	close(pendingChecksChan)
 
-	worker, err := NewWorker(aggregator.NewNoOpSenderManager(), haagentmock.NewMockHaAgent(), 100, 200, pendingChecksChan, checksTracker, mockShouldAddStatsFunc)
+	worker, err := NewWorker(aggregator.NewNoOpSenderManager(), haagentmock.NewMockHaAgent(), 100, 200, pendingChecksChan, checksTracker, mockShouldAddStatsFunc, nil)
 	require.Nil(t, err)
 
 	wg.Add(1)
@@ -316,6 +321,7 @@ func TestWorkerUtilizationExpvars(t *testing.T) {
 		func() (sender.Sender, error) { return nil, nil },
 		haagentmock.NewMockHaAgent(),
 		100*time.Millisecond,
+		nil,
 	)
 	require.Nil(t, err)
 
@@ -389,7 +395,7 @@ func TestWorkerErrorAndWarningHandling(t *testing.T) {
 	}
 	close(pendingChecksChan)
 
-	worker, err := NewWorker(aggregator.NewNoOpSenderManager(), haagentmock.NewMockHaAgent(), 100, 200, pendingChecksChan, checksTracker, mockShouldAddStatsFunc)
+	worker, err := NewWorker(aggregator.NewNoOpSenderManager(), haagentmock.NewMockHaAgent(), 100, 200, pendingChecksChan, checksTracker, mockShouldAddStatsFunc, nil)
 	require.Nil(t, err)
 	AssertAsyncWorkerCount(t, 0)
 
@@ -436,7 +442,7 @@ func TestWorkerConcurrentCheckScheduling(t *testing.T) {
 	pendingChecksChan <- testCheck
 	close(pendingChecksChan)
 
-	worker, err := NewWorker(aggregator.NewNoOpSenderManager(), haagentmock.NewMockHaAgent(), 100, 200, pendingChecksChan, checksTracker, mockShouldAddStatsFunc)
+	worker, err := NewWorker(aggregator.NewNoOpSenderManager(), haagentmock.NewMockHaAgent(), 100, 200, pendingChecksChan, checksTracker, mockShouldAddStatsFunc, nil)
 	require.Nil(t, err)
 
 	worker.Run()
@@ -492,7 +498,7 @@ func TestWorkerStatsAddition(t *testing.T) {
 	pendingChecksChan <- squelchedStatsCheck
 	close(pendingChecksChan)
 
-	worker, err := NewWorker(aggregator.NewNoOpSenderManager(), haagentmock.NewMockHaAgent(), 100, 200, pendingChecksChan, checksTracker, shouldAddStatsFunc)
+	worker, err := NewWorker(aggregator.NewNoOpSenderManager(), haagentmock.NewMockHaAgent(), 100, 200, pendingChecksChan, checksTracker, shouldAddStatsFunc, nil)
 	require.Nil(t, err)
 
 	worker.Run()
@@ -547,6 +553,7 @@ func TestWorkerServiceCheckSending(t *testing.T) {
 		},
 		haagentmock.NewMockHaAgent(),
 		pollingInterval,
+		nil,
 	)
 	require.Nil(t, err)
 
@@ -596,6 +603,223 @@ func TestWorkerServiceCheckSending(t *testing.T) {
 	mockSender.AssertNumberOfCalls(t, "ServiceCheck", 3)
 }
 
+type cancellableCheck struct {
+	testCheck
+	ctx context.Context
+}
+
+func (c *cancellableCheck) SetRunContext(ctx context.Context) { c.ctx = ctx }
+
+func (c *cancellableCheck) Run() error {
+	c.runCount.Inc()
+	<-c.ctx.Done()
+	return c.ctx.Err()
+}
+
+func TestWorkerRunTimeout(t *testing.T) {
+	mockConfig := configmock.New(t)
+	expvars.Reset()
+	mockConfig.SetWithoutSource("hostname", "myhost")
+	mockConfig.SetWithoutSource("check_run_timeout", "50ms")
+
+	checksTracker := tracker.NewRunningChecksTracker()
+	pendingChecksChan := make(chan check.Check, 10)
+	mockShouldAddStatsFunc := func(checkid.ID) bool { return true }
+
+	release := make(chan struct{})
+	hungCheck := newCheck(t, "hungcheck:123", false, func(checkid.ID) { <-release })
+	goodCheck := newCheck(t, "goodcheck:123", false, nil)
+	pendingChecksChan <- hungCheck
+	pendingChecksChan <- goodCheck
+
+	mockSender := mocksender.NewMockSender("")
+	mockSender.On("Commit").Return()
+	mockSender.On(
+		"ServiceCheck",
+		serviceCheckStatusKey,
+		servicecheck.ServiceCheckCritical,
+		"myhost",
+		[]string{"check:hungcheck", "dd_enable_check_intake:true"},
+		"Check run exceeded its timeout of 50ms",
+	).Return().Times(1)
+
+	var wg sync.WaitGroup
+	var newTestWorker func(id int) *Worker
+	newTestWorker = func(id int) *Worker {
+		worker, err := newWorkerWithOptions(
+			100,
+			id,
+			pendingChecksChan,
+			checksTracker,
+			mockShouldAddStatsFunc,
+			func() (sender.Sender, error) { return mockSender, nil },
+			haagentmock.NewMockHaAgent(),
+			pollingInterval,
+			func() {
+				// start a replacement worker
+				replacement := newTestWorker(id + 1)
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					replacement.Run()
+				}()
+			},
+		)
+		require.Nil(t, err)
+		return worker
+	}
+
+	hungWorkerDone := make(chan struct{})
+	go func() {
+		defer close(hungWorkerDone)
+		newTestWorker(1).Run()
+	}()
+
+	// the replacement worker keeps running the other checks
+	require.Eventually(t, func() bool { return goodCheck.RunCount() == 1 }, 5*time.Second, 10*time.Millisecond)
+	hungStats, found := expvars.CheckStats(hungCheck.ID())
+	require.True(t, found)
+	assert.True(t, hungStats.TimedOut)
+	assert.Contains(t, hungStats.TimeoutStack, "(*testCheck).Run")
+	assert.Equal(t, 50*time.Millisecond, hungStats.RunTimeout)
+	assert.EqualValues(t, 0, hungStats.TotalRuns)
+
+	// the blocked worker exits once the hung check returns
+	close(release)
+	select {
+	case <-hungWorkerDone:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "the worker of the timed out check didn't exit")
+	}
+	close(pendingChecksChan)
+	wg.Wait()
+
+	hungStats, found = expvars.CheckStats(hungCheck.ID())
+	require.True(t, found)
+	assert.False(t, hungStats.TimedOut)
+	assert.EqualValues(t, 1, hungStats.TotalTimeouts)
+	assert.EqualValues(t, 1, hungStats.TotalErrors)
+	assert.Equal(t, "check run exceeded its timeout of 50ms", hungStats.LastError)
+	mockSender.AssertExpectations(t)
+}
+
+func TestWorkerRunTimeoutCancelsContext(t *testing.T) {
+	mockConfig := configmock.New(t)
+	expvars.Reset()
+	mockConfig.SetWithoutSource("hostname", "myhost")
+
+	checksTracker := tracker.NewRunningChecksTracker()
+	pendingChecksChan := make(chan check.Check, 10)
+	mockShouldAddStatsFunc := func(checkid.ID) bool { return true }
+
+	goCheck := &cancellableCheck{testCheck: testCheck{t: t, id: "gocheck:123", runCount: atomic.NewUint64(0)}}
+	pendingChecksChan <- goCheck
+	close(pendingChecksChan)
+
+	timeouts := atomic.NewInt32(0)
+	worker, err := newWorkerWithOptions(
+		100,
+		200,
+		pendingChecksChan,
+		checksTracker,
+		mockShouldAddStatsFunc,
+		func() (sender.Sender, error) { return nil, fmt.Errorf("testerr") },
+		haagentmock.NewMockHaAgent(),
+		pollingInterval,
+		func() { timeouts.Inc() },
+	)
+	require.Nil(t, err)
+
+	mockConfig.SetWithoutSource("check_run_timeout", "10ms")
+	worker.Run()
+
+	assert.EqualValues(t, 1, timeouts.Load())
+	stats, found := expvars.CheckStats(goCheck.ID())
+	require.True(t, found)
+	assert.EqualValues(t, 1, stats.TotalTimeouts)
+	assert.Equal(t, context.Canceled.Error(), stats.LastError)
+}
+
+// httpCheck is a Go check fetching a URL with the context of its run
+type httpCheck struct {
+	corechecks.CheckBase
+	url string
+}
+
+func (c *httpCheck) Run() error {
+	req, err := http.NewRequestWithContext(c.RunContext(), http.MethodGet, c.url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func TestWorkerRunTimeoutStopsGoCheck(t *testing.T) {
+	mockConfig := configmock.New(t)
+	expvars.Reset()
+	mockConfig.SetWithoutSource("hostname", "myhost")
+	mockConfig.SetWithoutSource("check_run_timeout", "50ms")
+
+	// the server never answers, as a dead database socket would
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	goCheck := &httpCheck{CheckBase: corechecks.NewCheckBase("httpcheck"), url: server.URL}
+	require.NoError(t, goCheck.Configure(aggregator.NewNoOpSenderManager(), 0, integration.Data("{}"), integration.Data("{}"), "test"))
+
+	checksTracker := tracker.NewRunningChecksTracker()
+	pendingChecksChan := make(chan check.Check, 10)
+	pendingChecksChan <- goCheck
+	close(pendingChecksChan)
+
+	timeouts := atomic.NewInt32(0)
+	worker, err := newWorkerWithOptions(
+		100,
+		200,
+		pendingChecksChan,
+		checksTracker,
+		func(checkid.ID) bool { return true },
+		func() (sender.Sender, error) { return nil, fmt.Errorf("testerr") },
+		haagentmock.NewMockHaAgent(),
+		pollingInterval,
+		func() { timeouts.Inc() },
+	)
+	require.Nil(t, err)
+
+	workerDone := make(chan struct{})
+	go func() {
+		defer close(workerDone)
+		worker.Run()
+	}()
+
+	// the check stops running as soon as the watchdog cancels its context
+	select {
+	case <-workerDone:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "the timed out Go check is still running")
+	}
+
+	assert.EqualValues(t, 1, timeouts.Load())
+	stats, found := expvars.CheckStats(goCheck.ID())
+	require.True(t, found)
+	assert.EqualValues(t, 1, stats.TotalTimeouts)
+	assert.Contains(t, stats.LastError, context.Canceled.Error())
+
+	// the context of the next run isn't the cancelled one
+	assert.NoError(t, goCheck.RunContext().Err())
+}
+
 func TestWorkerSenderNil(t *testing.T) {
 	mockConfig := configmock.New(t)
 	expvars.Reset()
@@ -619,6 +843,7 @@ func TestWorkerSenderNil(t *testing.T) {
 		},
 		haagentmock.NewMockHaAgent(),
 		pollingInterval,
+		nil,
 	)
 	require.Nil(t, err)
 
@@ -661,6 +886,7 @@ func TestWorkerServiceCheckSendingLongRunningTasks(t *testing.T) {
 		},
 		haagentmock.NewMockHaAgent(),
 		pollingInterval,
+		nil,
 	)
 	require.Nil(t, err)
 
@@ -743,7 +969,7 @@ func TestWorker_HaIntegration(t *testing.T) {
 			haagentcomp, _ := haagentimpl.NewComponent(requires)
 			haagentcomp.Comp.SetLeader(tt.setLeaderValue)
 
-			worker, err := NewWorker(aggregator.NewNoOpSenderManager(), haagentcomp.Comp, 100, 200, pendingChecksChan, checksTracker, mockShouldAddStatsFunc)
+			worker, err := NewWorker(aggregator.NewNoOpSenderManager(), haagentcomp.Comp, 100, 200, pendingChecksChan, checksTracker, mockShouldAddStatsFunc, nil)
 			require.Nil(t, err)
 
 			wg.Add(1)
@@ -0,0 +1,30 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package report
+
+import (
+	_ "embed"
+	"encoding/json"
+	"html/template"
+	"io"
+)
+
+//go:embed templates/report.html.tmpl
+var htmlTemplateContent string
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"toJSON": func(v interface{}) string {
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err.Error()
+		}
+		return string(b)
+	},
+}).Parse(htmlTemplateContent))
+
+func writeHTML(w io.Writer, report *Report) error {
+	return htmlTemplate.Execute(w, report)
+}
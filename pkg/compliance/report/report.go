@@ -0,0 +1,203 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package report implements standalone reports of compliance benchmarks
+// results, to be used as local evidence of the compliance of a host.
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/DataDog/datadog-go/v5/statsd"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/version"
+)
+
+// Format is the output format of a report.
+type Format string
+
+const (
+	// FormatJSON outputs the report as JSON.
+	FormatJSON Format = "json"
+	// FormatXCCDF outputs the report as a XCCDF 1.2 benchmark holding the
+	// test results.
+	FormatXCCDF Format = "xccdf"
+	// FormatARF outputs the report as an Asset Reporting Format 1.1
+	// collection wrapping the XCCDF results.
+	FormatARF Format = "arf"
+	// FormatHTML outputs the report as a HTML summary.
+	FormatHTML Format = "html"
+)
+
+// Formats lists the supported report formats.
+var Formats = []Format{FormatJSON, FormatXCCDF, FormatARF, FormatHTML}
+
+// Report holds the results of the evaluation of a set of benchmarks.
+type Report struct {
+	Hostname     string             `json:"hostname"`
+	AgentVersion string             `json:"agent_version"`
+	StartTime    time.Time          `json:"start_time"`
+	EndTime      time.Time          `json:"end_time"`
+	Benchmarks   []*BenchmarkReport `json:"benchmarks"`
+}
+
+// BenchmarkReport holds the results of the rules of a benchmark.
+type BenchmarkReport struct {
+	Name        string        `json:"name"`
+	FrameworkID string        `json:"framework"`
+	Version     string        `json:"version"`
+	Rules       []*RuleReport `json:"rules"`
+}
+
+// RuleReport holds the results of a rule, along with the inputs it was
+// evaluated against.
+type RuleReport struct {
+	ID          string                 `json:"id"`
+	Description string                 `json:"description,omitempty"`
+	Evaluator   compliance.Evaluator   `json:"evaluator"`
+	Results     []*RuleResult          `json:"results"`
+	Evidence    map[string]interface{} `json:"evidence,omitempty"`
+}
+
+// RuleResult holds the result of a rule for a given resource.
+type RuleResult struct {
+	Result       compliance.CheckResult `json:"result"`
+	ResourceType string                 `json:"resource_type,omitempty"`
+	ResourceID   string                 `json:"resource_id,omitempty"`
+	Data         map[string]interface{} `json:"data,omitempty"`
+}
+
+// Summary counts the results of a benchmark by result type.
+type Summary struct {
+	Passed  int
+	Failed  int
+	Errors  int
+	Skipped int
+}
+
+// Total returns the total number of results.
+func (s Summary) Total() int {
+	return s.Passed + s.Failed + s.Errors + s.Skipped
+}
+
+// Score returns the percentage of passed results among the evaluated ones.
+func (s Summary) Score() float64 {
+	if evaluated := s.Passed + s.Failed; evaluated > 0 {
+		return float64(s.Passed) * 100 / float64(evaluated)
+	}
+	return 0
+}
+
+// Summary counts the results of the rules of the benchmark.
+func (b *BenchmarkReport) Summary() Summary {
+	var s Summary
+	for _, rule := range b.Rules {
+		for _, result := range rule.Results {
+			switch result.Result {
+			case compliance.CheckPassed:
+				s.Passed++
+			case compliance.CheckFailed:
+				s.Failed++
+			case compliance.CheckError:
+				s.Errors++
+			case compliance.CheckSkipped:
+				s.Skipped++
+			}
+		}
+	}
+	return s
+}
+
+// Options holds the parameters of the evaluation of the benchmarks.
+type Options struct {
+	Hostname     string
+	Resolver     compliance.Resolver
+	StatsdClient statsd.ClientInterface
+}
+
+// Run evaluates the rules of the given benchmarks, and returns a report of
+// their results.
+func Run(ctx context.Context, benchmarks []*compliance.Benchmark, opts Options) *Report {
+	report := &Report{
+		Hostname:     opts.Hostname,
+		AgentVersion: version.AgentVersion,
+		StartTime:    time.Now().UTC(),
+	}
+
+	for _, benchmark := range benchmarks {
+		benchmarkReport := &BenchmarkReport{
+			Name:        benchmark.Name,
+			FrameworkID: benchmark.FrameworkID,
+			Version:     benchmark.Version,
+		}
+		for _, rule := range benchmark.Rules {
+			log.Infof("Running check: %s: %s [version=%s]", rule.ID, rule.Description, benchmark.Version)
+			benchmarkReport.Rules = append(benchmarkReport.Rules, evaluateRule(ctx, benchmark, rule, opts))
+		}
+		compliance.FinishXCCDFBenchmark(ctx, benchmark)
+		report.Benchmarks = append(report.Benchmarks, benchmarkReport)
+	}
+
+	report.EndTime = time.Now().UTC()
+	return report
+}
+
+func evaluateRule(ctx context.Context, benchmark *compliance.Benchmark, rule *compliance.Rule, opts Options) *RuleReport {
+	ruleReport := &RuleReport{
+		ID:          rule.ID,
+		Description: rule.Description,
+	}
+
+	var events []*compliance.CheckEvent
+	switch {
+	case rule.IsXCCDF():
+		ruleReport.Evaluator = compliance.XCCDFEvaluator
+		events = compliance.EvaluateXCCDFRule(ctx, opts.Hostname, opts.StatsdClient, benchmark, rule)
+	case rule.IsRego():
+		ruleReport.Evaluator = compliance.RegoEvaluator
+		inputs, err := opts.Resolver.ResolveInputs(ctx, rule)
+		if err != nil {
+			events = append(events, compliance.CheckEventFromError(compliance.RegoEvaluator, rule, benchmark, err))
+		} else {
+			ruleReport.Evidence = inputs
+			events = compliance.EvaluateRegoRule(ctx, inputs, benchmark, rule)
+		}
+	}
+
+	for _, event := range events {
+		ruleReport.Results = append(ruleReport.Results, &RuleResult{
+			Result:       event.Result,
+			ResourceType: event.ResourceType,
+			ResourceID:   event.ResourceID,
+			Data:         event.Data,
+		})
+	}
+
+	return ruleReport
+}
+
+// Write writes the report in the given format.
+func Write(w io.Writer, report *Report, format Format) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case FormatXCCDF:
+		return writeXCCDF(w, report)
+	case FormatARF:
+		return writeARF(w, report)
+	case FormatHTML:
+		return writeHTML(w, report)
+	default:
+		return fmt.Errorf("unsupported report format %q", format)
+	}
+}
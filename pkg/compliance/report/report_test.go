@@ -0,0 +1,137 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+)
+
+func newTestReport() *Report {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	return &Report{
+		Hostname:     "my-host",
+		AgentVersion: "7.60.0",
+		StartTime:    start,
+		EndTime:      start.Add(time.Minute),
+		Benchmarks: []*BenchmarkReport{
+			{
+				Name:        "CIS Docker",
+				FrameworkID: "cis-docker",
+				Version:     "1.2.0",
+				Rules: []*RuleReport{
+					{
+						ID:          "cis-docker-1.2.0-2.1",
+						Description: "Restrict network traffic between containers",
+						Evaluator:   compliance.RegoEvaluator,
+						Results: []*RuleResult{
+							{Result: compliance.CheckPassed, ResourceType: "docker_daemon", ResourceID: "my-host"},
+						},
+						Evidence: map[string]interface{}{
+							"process": map[string]interface{}{"name": "dockerd", "flags": map[string]interface{}{"--icc": "false"}},
+						},
+					},
+					{
+						ID:          "cis-docker-1.2.0-3.1",
+						Description: "Ensure that docker.service file ownership is set to root:root",
+						Evaluator:   compliance.RegoEvaluator,
+						Results: []*RuleResult{
+							{Result: compliance.CheckFailed, ResourceType: "docker_daemon", ResourceID: "my-host", Data: map[string]interface{}{"file.user": "nobody"}},
+							{Result: compliance.CheckError, Data: map[string]interface{}{"error": "input resolution error"}},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestSummary(t *testing.T) {
+	summary := newTestReport().Benchmarks[0].Summary()
+	assert.Equal(t, Summary{Passed: 1, Failed: 1, Errors: 1}, summary)
+	assert.Equal(t, 3, summary.Total())
+	assert.InDelta(t, 50.0, summary.Score(), 0.001)
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, newTestReport(), FormatJSON))
+
+	var decoded Report
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	require.Len(t, decoded.Benchmarks, 1)
+	require.Len(t, decoded.Benchmarks[0].Rules, 2)
+	assert.Equal(t, "dockerd", decoded.Benchmarks[0].Rules[0].Evidence["process"].(map[string]interface{})["name"])
+}
+
+func TestWriteXCCDF(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, newTestReport(), FormatXCCDF))
+
+	var benchmark xccdfBenchmark
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &benchmark))
+	assert.Equal(t, xccdfNamespace, benchmark.XMLName.Space)
+	require.Len(t, benchmark.Groups, 1)
+	assert.Equal(t, "xccdf_com.datadoghq_group_cis-docker_1.2.0", benchmark.Groups[0].ID)
+	require.Len(t, benchmark.Groups[0].Rules, 2)
+	assert.Equal(t, "xccdf_com.datadoghq_rule_cis-docker-1.2.0-2.1", benchmark.Groups[0].Rules[0].ID)
+
+	require.NotNil(t, benchmark.TestResult)
+	assert.Equal(t, "my-host", benchmark.TestResult.Target)
+	require.Len(t, benchmark.TestResult.RuleResults, 3)
+	assert.Equal(t, "pass", benchmark.TestResult.RuleResults[0].Result)
+	require.NotNil(t, benchmark.TestResult.RuleResults[0].Check)
+	assert.Contains(t, benchmark.TestResult.RuleResults[0].Check.Content, `"dockerd"`)
+	assert.Equal(t, "fail", benchmark.TestResult.RuleResults[1].Result)
+	assert.Equal(t, "error", benchmark.TestResult.RuleResults[2].Result)
+	require.NotNil(t, benchmark.TestResult.RuleResults[2].Message)
+	assert.Equal(t, "input resolution error", benchmark.TestResult.RuleResults[2].Message.Text)
+	assert.InDelta(t, 50.0, benchmark.TestResult.Score.Value, 0.001)
+}
+
+func TestWriteARF(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, newTestReport(), FormatARF))
+
+	output := buf.String()
+	assert.Contains(t, output, `<arf:asset-report-collection xmlns:arf="`+arfNamespace+`"`)
+	assert.Contains(t, output, `<ai:hostname>my-host</ai:hostname>`)
+	assert.Contains(t, output, `<Benchmark xmlns="`+xccdfNamespace+`"`)
+
+	var collection struct {
+		Reports []struct {
+			ID        string         `xml:"id,attr"`
+			Benchmark xccdfBenchmark `xml:"content>Benchmark"`
+		} `xml:"reports>report"`
+	}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &collection))
+	require.Len(t, collection.Reports, 1)
+	assert.Len(t, collection.Reports[0].Benchmark.TestResult.RuleResults, 3)
+}
+
+func TestWriteHTML(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, newTestReport(), FormatHTML))
+
+	output := buf.String()
+	assert.Contains(t, output, "<strong>my-host</strong>")
+	assert.Contains(t, output, `<td class="failed">1</td>`)
+	assert.Contains(t, output, "50.0%")
+	assert.Contains(t, output, "cis-docker-1.2.0-3.1")
+	assert.Contains(t, output, "&#34;dockerd&#34;")
+}
+
+func TestWriteUnsupportedFormat(t *testing.T) {
+	assert.Error(t, Write(&bytes.Buffer{}, newTestReport(), Format("pdf")))
+}
@@ -0,0 +1,286 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"regexp"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+)
+
+const (
+	xccdfNamespace = "http://checklists.nist.gov/xccdf/1.2"
+	arfNamespace   = "http://scap.nist.gov/schema/asset-reporting-format/1.1"
+	aiNamespace    = "http://scap.nist.gov/schema/asset-identification/1.1"
+	coreNamespace  = "http://scap.nist.gov/schema/reporting-core/1.1"
+	arfVocabulary  = "http://scap.nist.gov/specifications/arf/vocabulary/relationships/1.0#"
+
+	// xccdfIDPrefix is the reverse DNS prefix of the XCCDF 1.2 identifiers
+	xccdfIDPrefix = "xccdf_com.datadoghq_"
+
+	// evidenceCheckSystem identifies the checks embedding the evidence of a rule result
+	evidenceCheckSystem = "https://docs.datadoghq.com/security/cspm/evidence"
+)
+
+var invalidXCCDFIDChars = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+func xccdfID(kind, id string) string {
+	return xccdfIDPrefix + kind + "_" + invalidXCCDFIDChars.ReplaceAllString(id, "_")
+}
+
+type xccdfText struct {
+	Text string `xml:",chardata"`
+}
+
+type xccdfBenchmark struct {
+	XMLName    xml.Name         `xml:"Benchmark"`
+	Namespace  string           `xml:"xmlns,attr,omitempty"`
+	ID         string           `xml:"id,attr"`
+	Resolved   bool             `xml:"resolved,attr"`
+	Style      string           `xml:"style,attr"`
+	Status     string           `xml:"status"`
+	Title      xccdfText        `xml:"title"`
+	Version    string           `xml:"version"`
+	Groups     []xccdfGroup     `xml:"Group"`
+	TestResult *xccdfTestResult `xml:"TestResult"`
+}
+
+type xccdfGroup struct {
+	ID          string      `xml:"id,attr"`
+	Title       xccdfText   `xml:"title"`
+	Description string      `xml:"description,omitempty"`
+	Rules       []xccdfRule `xml:"Rule"`
+}
+
+type xccdfRule struct {
+	ID       string    `xml:"id,attr"`
+	Selected bool      `xml:"selected,attr"`
+	Title    xccdfText `xml:"title"`
+}
+
+type xccdfTestResult struct {
+	ID          string            `xml:"id,attr"`
+	StartTime   string            `xml:"start-time,attr"`
+	EndTime     string            `xml:"end-time,attr"`
+	TestSystem  string            `xml:"test-system,attr"`
+	Version     string            `xml:"version,attr"`
+	Title       xccdfText         `xml:"title"`
+	Target      string            `xml:"target"`
+	RuleResults []xccdfRuleResult `xml:"rule-result"`
+	Score       xccdfScore        `xml:"score"`
+}
+
+type xccdfRuleResult struct {
+	IDRef    string         `xml:"idref,attr"`
+	Time     string         `xml:"time,attr"`
+	Result   string         `xml:"result"`
+	Message  *xccdfMessage  `xml:"message"`
+	Instance *xccdfInstance `xml:"instance"`
+	Check    *xccdfCheck    `xml:"check"`
+}
+
+type xccdfInstance struct {
+	Context string `xml:"context,attr"`
+	Value   string `xml:",chardata"`
+}
+
+type xccdfMessage struct {
+	Severity string `xml:"severity,attr"`
+	Text     string `xml:",chardata"`
+}
+
+type xccdfCheck struct {
+	System  string `xml:"system,attr"`
+	Content string `xml:"check-content"`
+}
+
+type xccdfScore struct {
+	System  string  `xml:"system,attr"`
+	Maximum string  `xml:"maximum,attr"`
+	Value   float64 `xml:",chardata"`
+}
+
+// xccdfResult maps the result of a check to the XCCDF result values.
+func xccdfResult(result compliance.CheckResult) string {
+	switch result {
+	case compliance.CheckPassed:
+		return "pass"
+	case compliance.CheckFailed:
+		return "fail"
+	case compliance.CheckSkipped:
+		return "notapplicable"
+	case compliance.CheckError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// toXCCDF builds a XCCDF benchmark holding a group per benchmark of the
+// report, and a single test result.
+func toXCCDF(report *Report) *xccdfBenchmark {
+	var summary Summary
+
+	benchmark := &xccdfBenchmark{
+		Namespace: xccdfNamespace,
+		ID:        xccdfID("benchmark", "compliance"),
+		Resolved:  true,
+		Style:     "SCAP_1.2",
+		Status:    "accepted",
+		Title:     xccdfText{Text: "Datadog Compliance Report"},
+		Version:   report.AgentVersion,
+	}
+	testResult := &xccdfTestResult{
+		ID:         xccdfID("testresult", report.Hostname),
+		StartTime:  report.StartTime.Format(time.RFC3339),
+		EndTime:    report.EndTime.Format(time.RFC3339),
+		TestSystem: "cpe:/a:datadoghq:datadog-agent:" + report.AgentVersion,
+		Version:    report.AgentVersion,
+		Title:      xccdfText{Text: "Datadog Compliance Report for " + report.Hostname},
+		Target:     report.Hostname,
+	}
+
+	for _, b := range report.Benchmarks {
+		group := xccdfGroup{
+			ID:          xccdfID("group", b.FrameworkID+"_"+b.Version),
+			Title:       xccdfText{Text: b.Name},
+			Description: b.FrameworkID + " " + b.Version,
+		}
+
+		for _, rule := range b.Rules {
+			ruleID := xccdfID("rule", rule.ID)
+			group.Rules = append(group.Rules, xccdfRule{
+				ID:       ruleID,
+				Selected: true,
+				Title:    xccdfText{Text: rule.Description},
+			})
+
+			var check *xccdfCheck
+			if len(rule.Evidence) > 0 {
+				if evidence, err := json.MarshalIndent(rule.Evidence, "", "  "); err == nil {
+					check = &xccdfCheck{System: evidenceCheckSystem, Content: string(evidence)}
+				}
+			}
+
+			for _, result := range rule.Results {
+				ruleResult := xccdfRuleResult{
+					IDRef:  ruleID,
+					Time:   report.EndTime.Format(time.RFC3339),
+					Result: xccdfResult(result.Result),
+					Check:  check,
+				}
+				if result.ResourceID != "" {
+					ruleResult.Instance = &xccdfInstance{Context: result.ResourceType, Value: result.ResourceID}
+				}
+				if errMsg, ok := result.Data["error"].(string); ok {
+					ruleResult.Message = &xccdfMessage{Severity: "error", Text: errMsg}
+				}
+				testResult.RuleResults = append(testResult.RuleResults, ruleResult)
+			}
+		}
+
+		benchmarkSummary := b.Summary()
+		summary.Passed += benchmarkSummary.Passed
+		summary.Failed += benchmarkSummary.Failed
+		benchmark.Groups = append(benchmark.Groups, group)
+	}
+
+	testResult.Score = xccdfScore{
+		System:  "urn:xccdf:scoring:default",
+		Maximum: "100",
+		Value:   summary.Score(),
+	}
+	benchmark.TestResult = testResult
+
+	return benchmark
+}
+
+func writeXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func writeXCCDF(w io.Writer, report *Report) error {
+	return writeXML(w, toXCCDF(report))
+}
+
+type arfAssetReportCollection struct {
+	XMLName        xml.Name         `xml:"arf:asset-report-collection"`
+	ARFNamespace   string           `xml:"xmlns:arf,attr"`
+	AINamespace    string           `xml:"xmlns:ai,attr"`
+	CoreNamespace  string           `xml:"xmlns:core,attr"`
+	VocabNamespace string           `xml:"xmlns:arfvocab,attr"`
+	Relationships  arfRelationships `xml:"core:relationships"`
+	Assets         []arfAsset       `xml:"arf:assets>arf:asset"`
+	Reports        []arfReport      `xml:"arf:reports>arf:report"`
+}
+
+type arfRelationships struct {
+	Relationships []arfRelationship `xml:"core:relationship"`
+}
+
+type arfRelationship struct {
+	Type    string `xml:"type,attr"`
+	Subject string `xml:"subject,attr"`
+	Ref     string `xml:"core:ref"`
+}
+
+type arfAsset struct {
+	ID              string             `xml:"id,attr"`
+	ComputingDevice arfComputingDevice `xml:"ai:computing-device"`
+}
+
+type arfComputingDevice struct {
+	Hostname string `xml:"ai:hostname"`
+}
+
+type arfReport struct {
+	ID      string          `xml:"id,attr"`
+	Content *xccdfBenchmark `xml:"arf:content>Benchmark"`
+}
+
+func writeARF(w io.Writer, report *Report) error {
+	const (
+		assetID  = "asset0"
+		reportID = "xccdf1"
+	)
+
+	collection := &arfAssetReportCollection{
+		ARFNamespace:   arfNamespace,
+		AINamespace:    aiNamespace,
+		CoreNamespace:  coreNamespace,
+		VocabNamespace: arfVocabulary,
+		Relationships: arfRelationships{
+			Relationships: []arfRelationship{{
+				Type:    "arfvocab:isAbout",
+				Subject: reportID,
+				Ref:     assetID,
+			}},
+		},
+		Assets: []arfAsset{{
+			ID:              assetID,
+			ComputingDevice: arfComputingDevice{Hostname: report.Hostname},
+		}},
+		Reports: []arfReport{{
+			ID:      reportID,
+			Content: toXCCDF(report),
+		}},
+	}
+
+	return writeXML(w, collection)
+}
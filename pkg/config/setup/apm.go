@@ -46,6 +46,12 @@ func setupAPM(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("apm_config.obfuscation.valkey.remove_all_args", false, "DD_APM_OBFUSCATION_VALKEY_REMOVE_ALL_ARGS")
 	config.BindEnvAndSetDefault("apm_config.obfuscation.memcached.enabled", true, "DD_APM_OBFUSCATION_MEMCACHED_ENABLED")
 	config.BindEnvAndSetDefault("apm_config.obfuscation.memcached.keep_command", false, "DD_APM_OBFUSCATION_MEMCACHED_KEEP_COMMAND")
+	config.BindEnvAndSetDefault("apm_config.obfuscation.graphql.enabled", true, "DD_APM_OBFUSCATION_GRAPHQL_ENABLED")
+	config.BindEnvAndSetDefault("apm_config.obfuscation.graphql.keep_values", []string{}, "DD_APM_OBFUSCATION_GRAPHQL_KEEP_VALUES")
+	config.BindEnvAndSetDefault("apm_config.obfuscation.cql.enabled", false, "DD_APM_OBFUSCATION_CQL_ENABLED")
+	config.BindEnvAndSetDefault("apm_config.obfuscation.cql.replace_digits", false, "DD_APM_OBFUSCATION_CQL_REPLACE_DIGITS")
+	config.BindEnvAndSetDefault("apm_config.obfuscation.dynamodb.enabled", true, "DD_APM_OBFUSCATION_DYNAMODB_ENABLED")
+	config.BindEnvAndSetDefault("apm_config.obfuscation.dynamodb.replace_digits", false, "DD_APM_OBFUSCATION_DYNAMODB_REPLACE_DIGITS")
 	config.BindEnvAndSetDefault("apm_config.obfuscation.cache.enabled", true, "DD_APM_OBFUSCATION_CACHE_ENABLED")
 	config.BindEnvAndSetDefault("apm_config.obfuscation.cache.max_size", 5000000, "DD_APM_OBFUSCATION_CACHE_MAX_SIZE")
 	config.SetKnown("apm_config.filter_tags.require")             //nolint:forbidigo // TODO: replace by 'SetDefaultAndBindEnv'
@@ -133,6 +139,31 @@ func setupAPM(config pkgconfigmodel.Setup) {
 	config.BindEnv("apm_config.probabilistic_sampler.sampling_percentage", "DD_APM_PROBABILISTIC_SAMPLER_SAMPLING_PERCENTAGE") //nolint:forbidigo // TODO: replace by 'SetDefaultAndBindEnv'
 	config.BindEnv("apm_config.probabilistic_sampler.hash_seed", "DD_APM_PROBABILISTIC_SAMPLER_HASH_SEED")                     //nolint:forbidigo // TODO: replace by 'SetDefaultAndBindEnv'
 	config.BindEnvAndSetDefault("apm_config.error_tracking_standalone.enabled", false, "DD_APM_ERROR_TRACKING_STANDALONE_ENABLED")
+	config.BindEnvAndSetDefault("apm_config.disk_buffer.enabled", false, "DD_APM_DISK_BUFFER_ENABLED")
+	config.BindEnv("apm_config.disk_buffer.path", "DD_APM_DISK_BUFFER_PATH")                     //nolint:forbidigo // TODO: replace by 'SetDefaultAndBindEnv'
+	config.BindEnv("apm_config.disk_buffer.max_size_bytes", "DD_APM_DISK_BUFFER_MAX_SIZE_BYTES") //nolint:forbidigo // TODO: replace by 'SetDefaultAndBindEnv'
+	config.BindEnv("apm_config.disk_buffer.max_age", "DD_APM_DISK_BUFFER_MAX_AGE")               //nolint:forbidigo // TODO: replace by 'SetDefaultAndBindEnv'
+	config.BindEnvAndSetDefault("apm_config.tail_sampling.enabled", false, "DD_APM_TAIL_SAMPLING_ENABLED")
+	config.BindEnv("apm_config.tail_sampling.decision_wait", "DD_APM_TAIL_SAMPLING_DECISION_WAIT")       //nolint:forbidigo // TODO: replace by 'SetDefaultAndBindEnv'
+	config.BindEnv("apm_config.tail_sampling.max_traces", "DD_APM_TAIL_SAMPLING_MAX_TRACES")             //nolint:forbidigo // TODO: replace by 'SetDefaultAndBindEnv'
+	config.BindEnv("apm_config.tail_sampling.max_memory_bytes", "DD_APM_TAIL_SAMPLING_MAX_MEMORY_BYTES") //nolint:forbidigo // TODO: replace by 'SetDefaultAndBindEnv'
+	config.BindEnv("apm_config.tail_sampling.policies", "DD_APM_TAIL_SAMPLING_POLICIES")                 //nolint:forbidigo // TODO: replace by 'SetDefaultAndBindEnv'
+	config.ParseEnvAsSlice("apm_config.tail_sampling.policies", func(in string) []interface{} {
+		var policies []interface{}
+		if err := json.Unmarshal([]byte(in), &policies); err != nil {
+			log.Errorf(`"apm_config.tail_sampling.policies" can not be parsed: %v`, err)
+		}
+		return policies
+	})
+
+	config.BindEnv("apm_config.span_filters", "DD_APM_SPAN_FILTERS") //nolint:forbidigo // TODO: replace by 'SetDefaultAndBindEnv'
+	config.ParseEnvAsSlice("apm_config.span_filters", func(in string) []interface{} {
+		var rules []interface{}
+		if err := json.Unmarshal([]byte(in), &rules); err != nil {
+			log.Errorf(`"apm_config.span_filters" can not be parsed: %v`, err)
+		}
+		return rules
+	})
 
 	config.BindEnv("apm_config.max_memory", "DD_APM_MAX_MEMORY")                                    //nolint:forbidigo // TODO: replace by 'SetDefaultAndBindEnv'
 	config.BindEnv("apm_config.max_cpu_percent", "DD_APM_MAX_CPU_PERCENT")                          //nolint:forbidigo // TODO: replace by 'SetDefaultAndBindEnv'
@@ -188,6 +219,8 @@ func setupAPM(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("apm_config.debug.port", 5012, "DD_APM_DEBUG_PORT")
 	config.BindEnvAndSetDefault("apm_config.debug_v1_payloads", false, "DD_APM_DEBUG_V1_PAYLOADS")
 	config.BindEnvAndSetDefault("apm_config.enable_v1_trace_endpoint", false, "DD_APM_ENABLE_V1_TRACE_ENDPOINT")
+	config.BindEnvAndSetDefault("apm_config.zipkin_receiver.enabled", false, "DD_APM_ZIPKIN_RECEIVER_ENABLED")
+	config.BindEnvAndSetDefault("apm_config.jaeger_receiver.enabled", false, "DD_APM_JAEGER_RECEIVER_ENABLED")
 	config.BindEnvAndSetDefault("apm_config.send_all_internal_stats", false, "DD_APM_SEND_ALL_INTERNAL_STATS")
 	config.BindEnv("apm_config.features", "DD_APM_FEATURES") //nolint:forbidigo // TODO: replace by 'SetDefaultAndBindEnv'
 	config.ParseEnvAsStringSlice("apm_config.features", func(s string) []string {
@@ -402,6 +402,20 @@ func InitConfig(config pkgconfigmodel.Setup) {
 
 	config.BindEnvAndSetDefault("flare.rc_streamlogs.duration", 60*time.Second)
 
+	// flare encryption and upload destination
+	config.BindEnvAndSetDefault("flare.encryption.type", "")
+	config.BindEnvAndSetDefault("flare.encryption.public_key_file", "")
+	config.BindEnvAndSetDefault("flare.destination.type", "datadog")
+	config.BindEnvAndSetDefault("flare.destination.local.directory", "")
+	config.BindEnvAndSetDefault("flare.destination.http.url", "")
+	config.BindEnvAndSetDefault("flare.destination.s3.endpoint", "")
+	config.BindEnvAndSetDefault("flare.destination.s3.bucket", "")
+	config.BindEnvAndSetDefault("flare.destination.s3.region", "us-east-1")
+	config.BindEnvAndSetDefault("flare.destination.s3.prefix", "")
+	config.BindEnvAndSetDefault("flare.destination.s3.access_key_id", "")
+	config.BindEnvAndSetDefault("flare.destination.s3.secret_access_key", "")
+	config.BindEnvAndSetDefault("flare.destination.s3.session_token", "")
+
 	// Docker
 	config.BindEnvAndSetDefault("docker_query_timeout", int64(5))
 	config.BindEnvAndSetDefault("docker_labels_as_tags", map[string]string{})
@@ -488,6 +502,7 @@ func InitConfig(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("network_devices.snmp_traps.bind_host", "0.0.0.0")
 	config.BindEnvAndSetDefault("network_devices.snmp_traps.stop_timeout", 5) // in seconds
 	config.SetKnown("network_devices.snmp_traps.users")                       //nolint:forbidigo // TODO: replace by 'SetDefaultAndBindEnv'
+	config.BindEnvAndSetDefault("network_devices.snmp_traps.mibs_path", "")
 
 	// NetFlow
 	config.SetKnown("network_devices.netflow.listeners")                                  //nolint:forbidigo // TODO: replace by 'SetDefaultAndBindEnv'
@@ -497,9 +512,15 @@ func InitConfig(config pkgconfigmodel.Setup) {
 	config.SetKnown("network_devices.netflow.aggregator_flow_context_ttl")                //nolint:forbidigo // TODO: replace by 'SetDefaultAndBindEnv'
 	config.SetKnown("network_devices.netflow.aggregator_port_rollup_threshold")           //nolint:forbidigo // TODO: replace by 'SetDefaultAndBindEnv'
 	config.SetKnown("network_devices.netflow.aggregator_rollup_tracker_refresh_interval") //nolint:forbidigo // TODO: replace by 'SetDefaultAndBindEnv'
+	config.SetKnown("network_devices.netflow.sinks")                                      //nolint:forbidigo // TODO: replace by 'SetDefaultAndBindEnv'
 	config.BindEnvAndSetDefault("network_devices.netflow.enabled", "false")
 	bindEnvAndSetLogsConfigKeys(config, "network_devices.netflow.forwarder.")
 	config.BindEnvAndSetDefault("network_devices.netflow.reverse_dns_enrichment_enabled", false)
+	config.BindEnvAndSetDefault("network_devices.netflow.geoip_enrichment.enabled", false)
+	config.BindEnvAndSetDefault("network_devices.netflow.geoip_enrichment.location_db_path", "")
+	config.BindEnvAndSetDefault("network_devices.netflow.geoip_enrichment.asn_db_path", "")
+	config.BindEnvAndSetDefault("network_devices.netflow.geoip_enrichment.cache_size", 10000)
+	config.BindEnvAndSetDefault("network_devices.netflow.geoip_enrichment.reload_interval", 60) // in seconds
 
 	// Network Path
 	config.BindEnvAndSetDefault("network_path.connections_monitoring.enabled", false)
@@ -910,6 +931,13 @@ func InitConfig(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("admission_controller.agent_sidecar.kubelet_api_logging.enabled", false)
 
 	config.BindEnvAndSetDefault("admission_controller.kubernetes_admission_events.enabled", false)
+	config.BindEnvAndSetDefault("admission_controller.workload_policies.enabled", false)
+	config.BindEnvAndSetDefault("admission_controller.workload_policies.endpoint", "/validate-workload-policies")
+	config.BindEnvAndSetDefault("admission_controller.workload_policies.mode", "warn")                         // possible values: warn / enforce / disabled
+	config.BindEnvAndSetDefault("admission_controller.workload_policies.namespace_modes", map[string]string{}) // per-namespace override of the mode
+	config.BindEnvAndSetDefault("admission_controller.workload_policies.required_labels", []string{"tags.datadoghq.com/env", "tags.datadoghq.com/service", "tags.datadoghq.com/version"})
+	config.BindEnvAndSetDefault("admission_controller.workload_policies.validate_check_annotations", true)
+	config.BindEnvAndSetDefault("admission_controller.workload_policies.validate_logs_annotations", true)
 
 	// Declare other keys that don't have a default/env var.
 	// Mostly, keys we use IsSet() on, because IsSet always returns true if a key has a default.
@@ -996,6 +1024,11 @@ func InitConfig(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("sbom.host.analyzers", []string{"os"})
 	config.BindEnvAndSetDefault("sbom.host.additional_directories", []string{})
 
+	// Offline vulnerability matching configuration
+	config.BindEnvAndSetDefault("sbom.vulnerabilities.enabled", false)
+	config.BindEnvAndSetDefault("sbom.vulnerabilities.db_path", "")
+	config.BindEnvAndSetDefault("sbom.vulnerabilities.refresh_interval", "1h")
+
 	// Service discovery configuration
 	bindEnvAndSetLogsConfigKeys(config, "service_discovery.forwarder.")
 
@@ -1258,6 +1291,7 @@ func agent(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("inventories_diagnostics_enabled", true)
 	config.BindEnvAndSetDefault("check_runners", int64(4))
 	config.BindEnvAndSetDefault("check_cancel_timeout", 500*time.Millisecond)
+	config.BindEnvAndSetDefault("check_run_timeout", time.Duration(0))
 	config.BindEnvAndSetDefault("check_runner_utilization_threshold", 0.95)
 	config.BindEnvAndSetDefault("check_runner_utilization_monitor_interval", 60*time.Second)
 	config.BindEnvAndSetDefault("check_runner_utilization_warning_cooldown", 10*time.Minute)
@@ -1271,6 +1305,10 @@ func agent(config pkgconfigmodel.Setup) {
 	config.BindEnv("bind_host") //nolint:forbidigo // TODO: replace by 'SetDefaultAndBindEnv'
 	config.BindEnvAndSetDefault("health_port", int64(0))
 	config.BindEnvAndSetDefault("disable_py3_validation", false)
+	config.BindEnvAndSetDefault("python_check_isolation.memory_limit_mb", int64(0))
+	config.BindEnvAndSetDefault("python_check_isolation.cpu_limit", 0.0)
+	config.BindEnvAndSetDefault("python_check_isolation.max_restart_backoff", 5*time.Minute)
+	config.BindEnvAndSetDefault("python_check_isolation.start_timeout", 30*time.Second)
 	config.BindEnvAndSetDefault("win_skip_com_init", false)
 	config.BindEnvAndSetDefault("allow_arbitrary_tags", false)
 	config.BindEnvAndSetDefault("use_proxy_for_cloud_metadata", false)
@@ -1335,6 +1373,19 @@ func autoscaling(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("autoscaling.failover.enabled", false)
 	config.BindEnvAndSetDefault("autoscaling.workload.limit", 1000)
 	config.BindEnvAndSetDefault("autoscaling.failover.metrics", []string{"container.memory.usage", "container.cpu.usage"})
+	// Local vertical recommendations, computed from the failover metrics
+	config.BindEnvAndSetDefault("autoscaling.workload.local_vertical.enabled", false)
+	config.BindEnvAndSetDefault("autoscaling.workload.local_vertical.cpu_percentile", 0.9)
+	config.BindEnvAndSetDefault("autoscaling.workload.local_vertical.memory_percentile", 0.95)
+	config.BindEnvAndSetDefault("autoscaling.workload.local_vertical.headroom", 0.15)
+	config.BindEnvAndSetDefault("autoscaling.workload.local_vertical.history_half_life", 24*time.Hour)
+	config.BindEnvAndSetDefault("autoscaling.workload.local_vertical.min_samples", 60)
+	config.BindEnvAndSetDefault("autoscaling.workload.local_vertical.oom_bump_ratio", 1.2)
+	config.BindEnvAndSetDefault("autoscaling.workload.local_vertical.min_change_ratio", 0.1)
+	config.BindEnvAndSetDefault("autoscaling.workload.local_vertical.min_cpu", "10m")
+	config.BindEnvAndSetDefault("autoscaling.workload.local_vertical.max_cpu", "16")
+	config.BindEnvAndSetDefault("autoscaling.workload.local_vertical.min_memory", "32Mi")
+	config.BindEnvAndSetDefault("autoscaling.workload.local_vertical.max_memory", "64Gi")
 }
 
 func fips(config pkgconfigmodel.Setup) {
@@ -28,6 +28,41 @@ import (
 var checkName = "network_config_management"
 var defaultCheckInterval = 15 * time.Minute
 
+// HostKeyPolicy is the policy used to verify the SSH host key of a network device
+type HostKeyPolicy string
+
+const (
+	// HostKeyPolicyStrict only accepts the host keys present in a user-provided known_hosts file
+	HostKeyPolicyStrict HostKeyPolicy = "strict"
+	// HostKeyPolicyTOFU trusts the host key of a device on first use, and rejects any later change of it
+	HostKeyPolicyTOFU HostKeyPolicy = "tofu"
+	// HostKeyPolicyInsecure accepts any host key
+	HostKeyPolicyInsecure HostKeyPolicy = "insecure"
+)
+
+var defaultHostKeyPolicy = HostKeyPolicyTOFU
+
+// Transport is the protocol used to retrieve the configurations of a network device
+type Transport string
+
+const (
+	// TransportSSH runs the CLI commands of the device profile over SSH
+	TransportSSH Transport = "ssh"
+	// TransportNETCONF sends NETCONF RPCs over SSH (RFC 6241)
+	TransportNETCONF Transport = "netconf"
+	// TransportRESTCONF sends RESTCONF requests over HTTPS (RFC 8040)
+	TransportRESTCONF Transport = "restconf"
+)
+
+var defaultTransport = TransportSSH
+
+// defaultPorts are the default ports of the transports, registered by IANA
+var defaultPorts = map[Transport]string{
+	TransportSSH:      "22",
+	TransportNETCONF:  "830",
+	TransportRESTCONF: "443",
+}
+
 // AuthCredentials holds the authentication credentials to connect to a network device.
 type AuthCredentials struct { // auth_credentials
 	Username string `yaml:"username"`
@@ -39,6 +74,11 @@ type AuthCredentials struct { // auth_credentials
 	SSHCiphers      []string `yaml:"ssh_ciphers"`
 	SSHKeyExchanges []string `yaml:"ssh_key_exchanges"`
 	SSHHostKeyAlgos []string `yaml:"ssh_host_key_algorithms"`
+	// SSHHostKeyPolicy is the policy used to verify the host key of the device: strict, tofu or insecure
+	SSHHostKeyPolicy HostKeyPolicy `yaml:"ssh_host_key_policy"`
+	// SSHKnownHostsFile is the known_hosts file the host key of the device is verified against,
+	// required for the strict policy (the tofu policy defaults to a file under the agent run path)
+	SSHKnownHostsFile string `yaml:"ssh_known_hosts_file"`
 	// TODO: Uncomment and implement SSH key support
 	//SshKeyPath       string `yaml:"sshKeyPath"`       // path to the SSH key file
 	//SshKeyPassphrase string `yaml:"sshKeyPassphrase"` // passphrase for SSH key if needed
@@ -46,17 +86,29 @@ type AuthCredentials struct { // auth_credentials
 	//EnablePassword   string `yaml:"enable_password"`  // to be able to use privileged exec mode
 }
 
+// RESTCONFConfig holds the settings specific to the RESTCONF transport
+type RESTCONFConfig struct {
+	RootPath              string `yaml:"root_path"`                // RESTCONF API root, discovered from the device if not set, e.g., "/restconf"
+	TLSCAFile             string `yaml:"tls_ca_file"`              // CA certificates the certificate of the device is verified against
+	TLSInsecureSkipVerify bool   `yaml:"tls_insecure_skip_verify"` // if true, the certificate of the device is not verified
+}
+
 // DeviceInstance holds the initial config to connect to a network device, including its IP address and authentication credentials.
 type DeviceInstance struct {
 	IPAddress string          `yaml:"ip_address"` // ip address of the network device, e.g., "10.0.0.1"
 	Profile   string          `yaml:"profile"`    // device profile name, e.g., "cisco-ios"
+	Transport Transport       `yaml:"transport"`  // protocol used to retrieve the configurations: ssh, netconf or restconf
 	Auth      AuthCredentials `yaml:"auth"`
+	RESTCONF  RESTCONFConfig  `yaml:"restconf"`
 }
 
 // InitConfig holds the initial configuration for the NCM component, including the namespace and check interval.
 type InitConfig struct {
 	Namespace             string `yaml:"namespace"`               // Namespace for the NCM devices where configs are retrieved from, to help match a device on DD
 	MinCollectionInterval int    `yaml:"min_collection_interval"` // Interval in seconds to check for config changes
+	// Defaults for the SSH host key verification of the devices, overridden by the device auth settings
+	SSHHostKeyPolicy  HostKeyPolicy `yaml:"ssh_host_key_policy"`
+	SSHKnownHostsFile string        `yaml:"ssh_known_hosts_file"`
 }
 
 // NcmCheckContext holds the processed config needed for an integration instance to run
@@ -83,17 +135,6 @@ func NewNcmCheckContext(rawInstance integration.Data, rawInitConfig integration.
 	var deviceInstance DeviceInstance
 	var initConfig InitConfig
 
-	// Parse instance (for device)
-	err = yaml.Unmarshal(rawInstance, &deviceInstance)
-	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal device config: %s", err)
-	}
-	err = deviceInstance.ValidateDeviceInstance()
-	if err != nil {
-		return nil, fmt.Errorf("invalid device config for device %s: %w", deviceInstance.IPAddress, err)
-	}
-	ncc.Device = &deviceInstance
-
 	//Parse init config
 	err = yaml.Unmarshal(rawInitConfig, &initConfig)
 	if err != nil {
@@ -107,6 +148,18 @@ func NewNcmCheckContext(rawInstance integration.Data, rawInitConfig integration.
 	ncc.Namespace = initConfig.Namespace
 	ncc.MinCollectionInterval = time.Duration(initConfig.MinCollectionInterval) * time.Second
 
+	// Parse instance (for device)
+	err = yaml.Unmarshal(rawInstance, &deviceInstance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal device config: %s", err)
+	}
+	deviceInstance.applyInitConfig(&initConfig)
+	err = deviceInstance.ValidateDeviceInstance()
+	if err != nil {
+		return nil, fmt.Errorf("invalid device config for device %s: %w", deviceInstance.IPAddress, err)
+	}
+	ncc.Device = &deviceInstance
+
 	// Populate the profiles map (from defaults/OOTB)
 	profMap, err := profile.GetProfileMap("default_profiles")
 	if err != nil {
@@ -153,6 +206,19 @@ func GetNCMContextFromCoreCheck(client ipc.HTTPClient) (*NcmComponentContext, er
 	var deviceInstances []DeviceInstance
 	for _, c := range cr.Configs {
 		if c.Config.Name == checkName { // Check name for NCM
+			// Parse init config if exists
+			var initConfig InitConfig
+			if c.Config.InitConfig != nil {
+				err := yaml.Unmarshal(c.Config.InitConfig, &initConfig)
+				if err != nil {
+					return nil, fmt.Errorf("failed to unmarshal init config: %s", err)
+				}
+				err = initConfig.ValidateInitConfig()
+				if err != nil {
+					return nil, err
+				}
+				ncc.Namespace = initConfig.Namespace
+			}
 			// Parse each instance / device
 			for _, instance := range c.Config.Instances {
 				var deviceInstance DeviceInstance
@@ -160,25 +226,13 @@ func GetNCMContextFromCoreCheck(client ipc.HTTPClient) (*NcmComponentContext, er
 				if err != nil {
 					return nil, fmt.Errorf("failed to unmarshal NCM device config: %s", err)
 				}
+				deviceInstance.applyInitConfig(&initConfig)
 				err = deviceInstance.ValidateDeviceInstance()
 				if err != nil {
 					return nil, fmt.Errorf("invalid device config for device %s: %w", deviceInstance.IPAddress, err)
 				}
 				deviceInstances = append(deviceInstances, deviceInstance)
 			}
-			// Parse init config if exists
-			if c.Config.InitConfig != nil {
-				var initConfig InitConfig
-				err := yaml.Unmarshal(c.Config.InitConfig, &initConfig)
-				if err != nil {
-					return nil, fmt.Errorf("failed to unmarshal init config: %s", err)
-				}
-				err = initConfig.ValidateInitConfig()
-				if err != nil {
-					return nil, err
-				}
-				ncc.Namespace = initConfig.Namespace
-			}
 		}
 	}
 	// Make device map to easily reference from component when retrieving configs upon event of config change
@@ -239,6 +293,12 @@ func (dc *DeviceInstance) ValidateDeviceInstance() error {
 		return fmt.Errorf(authBaseString, "password", dc.IPAddress)
 	}
 
+	switch dc.Transport {
+	case TransportSSH, TransportNETCONF, TransportRESTCONF:
+	default:
+		return fmt.Errorf("invalid transport %q, expected one of %s, %s or %s", dc.Transport, TransportSSH, TransportNETCONF, TransportRESTCONF)
+	}
+
 	// TODO: Protocol/network check? Are customers aware of what's possible?
 	ip := net.ParseIP(dc.IPAddress)
 	if ip == nil {
@@ -252,17 +312,60 @@ func (dc *DeviceInstance) ValidateDeviceInstance() error {
 	if !(port >= 0 && port <= 65535) { // max value for 16-bit unsigned int
 		return fmt.Errorf("invalid port, out of range: %s", dc.Auth.Port)
 	}
+	if dc.Transport == TransportRESTCONF {
+		if dc.RESTCONF.TLSInsecureSkipVerify {
+			log.Warnf("TLS certificate verification is disabled for device %s, its configuration could be retrieved from an impersonated device", dc.IPAddress)
+		}
+		// the SSH host key settings do not apply to RESTCONF
+		return nil
+	}
+	// Host key verification validation
+	switch dc.Auth.SSHHostKeyPolicy {
+	case HostKeyPolicyStrict:
+		if dc.Auth.SSHKnownHostsFile == "" {
+			return fmt.Errorf("ssh_known_hosts_file is required with the %s ssh_host_key_policy for device %s", HostKeyPolicyStrict, dc.IPAddress)
+		}
+	case HostKeyPolicyTOFU:
+	case HostKeyPolicyInsecure:
+		log.Warnf("SSH host key verification is disabled for device %s, its configuration could be retrieved from an impersonated device", dc.IPAddress)
+	default:
+		return fmt.Errorf("invalid ssh_host_key_policy %q, expected one of %s, %s or %s", dc.Auth.SSHHostKeyPolicy, HostKeyPolicyStrict, HostKeyPolicyTOFU, HostKeyPolicyInsecure)
+	}
 	return nil
 }
 
+// applyInitConfig applies the settings of the init config as defaults for the device
+func (dc *DeviceInstance) applyInitConfig(ic *InitConfig) {
+	if dc.Auth.SSHHostKeyPolicy == "" {
+		dc.Auth.SSHHostKeyPolicy = ic.SSHHostKeyPolicy
+	}
+	if dc.Auth.SSHKnownHostsFile == "" {
+		dc.Auth.SSHKnownHostsFile = ic.SSHKnownHostsFile
+	}
+}
+
 // applyDefaults set default values for any optional fields that are not set + not required
 func (dc *DeviceInstance) applyDefaults() {
+	if dc.Transport == "" {
+		dc.Transport = defaultTransport
+	}
 	if dc.Auth.Port == "" {
-		log.Debugf("Applying default port for device %s: %s", dc.IPAddress, "22")
-		dc.Auth.Port = "22"
+		if port, ok := defaultPorts[dc.Transport]; ok {
+			log.Debugf("Applying default port for device %s: %s", dc.IPAddress, port)
+			dc.Auth.Port = port
+		}
+	}
+	// the structured transports retrieve the configurations with the generic profile of the transport
+	if dc.Profile == "" && (dc.Transport == TransportNETCONF || dc.Transport == TransportRESTCONF) {
+		log.Debugf("Applying default profile for device %s: %s", dc.IPAddress, dc.Transport)
+		dc.Profile = string(dc.Transport)
 	}
 	if dc.Auth.Protocol == "" {
 		log.Debugf("Applying default protocol for device %s: %s", dc.IPAddress, "tcp")
 		dc.Auth.Protocol = "tcp"
 	}
+	if dc.Auth.SSHHostKeyPolicy == "" {
+		log.Debugf("Applying default SSH host key policy for device %s: %s", dc.IPAddress, defaultHostKeyPolicy)
+		dc.Auth.SSHHostKeyPolicy = defaultHostKeyPolicy
+	}
 }
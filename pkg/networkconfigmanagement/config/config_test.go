@@ -116,6 +116,83 @@ func TestDeviceInstance_Validation(t *testing.T) {
 			expectValid: false,
 			errorMsg:    "invalid port, out of range",
 		},
+		{
+			name: "strict host key policy with known_hosts file",
+			config: DeviceInstance{
+				IPAddress: "100.1.1.1",
+				Auth: AuthCredentials{
+					Username:          "admin",
+					Password:          "password",
+					SSHHostKeyPolicy:  HostKeyPolicyStrict,
+					SSHKnownHostsFile: "/etc/ssh/ssh_known_hosts",
+				},
+			},
+			expectValid: true,
+		},
+		{
+			name: "strict host key policy without known_hosts file",
+			config: DeviceInstance{
+				IPAddress: "100.1.1.1",
+				Auth: AuthCredentials{
+					Username:         "admin",
+					Password:         "password",
+					SSHHostKeyPolicy: HostKeyPolicyStrict,
+				},
+			},
+			expectValid: false,
+			errorMsg:    "ssh_known_hosts_file is required with the strict ssh_host_key_policy",
+		},
+		{
+			name: "invalid host key policy",
+			config: DeviceInstance{
+				IPAddress: "100.1.1.1",
+				Auth: AuthCredentials{
+					Username:         "admin",
+					Password:         "password",
+					SSHHostKeyPolicy: "trust-me",
+				},
+			},
+			expectValid: false,
+			errorMsg:    "invalid ssh_host_key_policy",
+		},
+		{
+			name: "netconf transport",
+			config: DeviceInstance{
+				IPAddress: "100.1.1.1",
+				Transport: TransportNETCONF,
+				Auth: AuthCredentials{
+					Username: "admin",
+					Password: "password",
+				},
+			},
+			expectValid: true,
+		},
+		{
+			name: "restconf transport ignores the host key policy",
+			config: DeviceInstance{
+				IPAddress: "100.1.1.1",
+				Transport: TransportRESTCONF,
+				Auth: AuthCredentials{
+					Username:         "admin",
+					Password:         "password",
+					SSHHostKeyPolicy: HostKeyPolicyStrict,
+				},
+			},
+			expectValid: true,
+		},
+		{
+			name: "invalid transport",
+			config: DeviceInstance{
+				IPAddress: "100.1.1.1",
+				Transport: "telnet",
+				Auth: AuthCredentials{
+					Username: "admin",
+					Password: "password",
+				},
+			},
+			expectValid: false,
+			errorMsg:    "invalid transport",
+		},
 	}
 
 	for _, tt := range tests {
@@ -170,4 +247,75 @@ func TestAuthCredentials_DefaultValues(t *testing.T) {
 	config.applyDefaults()
 	assert.Equal(t, "22", config.Auth.Port)
 	assert.Equal(t, "tcp", config.Auth.Protocol)
+	assert.Equal(t, HostKeyPolicyTOFU, config.Auth.SSHHostKeyPolicy)
+	assert.Equal(t, TransportSSH, config.Transport)
+	assert.Empty(t, config.Profile)
+}
+
+func TestDeviceInstance_TransportDefaults(t *testing.T) {
+	tests := []struct {
+		transport       Transport
+		profile         string
+		expectedPort    string
+		expectedProfile string
+	}{
+		{transport: TransportSSH, expectedPort: "22", expectedProfile: ""},
+		{transport: TransportNETCONF, expectedPort: "830", expectedProfile: "netconf"},
+		{transport: TransportRESTCONF, expectedPort: "443", expectedProfile: "restconf"},
+		{transport: TransportNETCONF, profile: "junos-netconf", expectedPort: "830", expectedProfile: "junos-netconf"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.transport)+"/"+tt.profile, func(t *testing.T) {
+			var device DeviceInstance
+			require.NoError(t, yaml.Unmarshal([]byte(`
+ip_address: 10.0.0.1
+transport: `+string(tt.transport)+`
+profile: "`+tt.profile+`"
+auth:
+  username: admin
+  password: password
+restconf:
+  root_path: /restconf
+  tls_insecure_skip_verify: true
+`), &device))
+			require.NoError(t, device.ValidateDeviceInstance())
+			assert.Equal(t, tt.expectedPort, device.Auth.Port)
+			assert.Equal(t, tt.expectedProfile, device.Profile)
+			assert.Equal(t, "/restconf", device.RESTCONF.RootPath)
+			assert.True(t, device.RESTCONF.TLSInsecureSkipVerify)
+		})
+	}
+}
+
+func TestDeviceInstance_InitConfigHostKeyDefaults(t *testing.T) {
+	initConfig := InitConfig{
+		SSHHostKeyPolicy:  HostKeyPolicyStrict,
+		SSHKnownHostsFile: "/etc/ssh/ssh_known_hosts",
+	}
+
+	// the device inherits the init config settings
+	var device DeviceInstance
+	require.NoError(t, yaml.Unmarshal([]byte(`
+ip_address: 10.0.0.1
+auth:
+  username: admin
+  password: password
+`), &device))
+	device.applyInitConfig(&initConfig)
+	require.NoError(t, device.ValidateDeviceInstance())
+	assert.Equal(t, HostKeyPolicyStrict, device.Auth.SSHHostKeyPolicy)
+	assert.Equal(t, "/etc/ssh/ssh_known_hosts", device.Auth.SSHKnownHostsFile)
+
+	// the device settings override the init config ones
+	device = DeviceInstance{}
+	require.NoError(t, yaml.Unmarshal([]byte(`
+ip_address: 10.0.0.2
+auth:
+  username: admin
+  password: password
+  ssh_host_key_policy: tofu
+`), &device))
+	device.applyInitConfig(&initConfig)
+	require.NoError(t, device.ValidateDeviceInstance())
+	assert.Equal(t, HostKeyPolicyTOFU, device.Auth.SSHHostKeyPolicy)
 }
@@ -0,0 +1,156 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+//go:build ncm
+
+// Package diff computes line-based unified diffs between network device configurations.
+//
+// The differences are computed by github.com/pmezard/go-difflib, which also groups them in hunks;
+// this package exposes them in a structured form for the payloads, in addition to the text format.
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// DefaultContextLines is the number of unchanged lines shown around the changes of a hunk
+const DefaultContextLines = 3
+
+// Op is the operation applied to a line
+type Op string
+
+const (
+	// Equal represents a line present in both configurations
+	Equal Op = " "
+	// Insert represents a line only present in the new configuration
+	Insert Op = "+"
+	// Delete represents a line only present in the old configuration
+	Delete Op = "-"
+)
+
+// Line is a line of a hunk
+type Line struct {
+	Op   Op     `json:"op"`
+	Text string `json:"text"`
+}
+
+// Hunk is a group of changed lines, along with the unchanged lines surrounding them
+type Hunk struct {
+	OldStart int    `json:"old_start"`
+	OldLines int    `json:"old_lines"`
+	NewStart int    `json:"new_start"`
+	NewLines int    `json:"new_lines"`
+	Lines    []Line `json:"lines"`
+}
+
+// Diff holds the differences between two configurations
+type Diff struct {
+	Added   int    `json:"added"`
+	Removed int    `json:"removed"`
+	Hunks   []Hunk `json:"hunks"`
+}
+
+// Unified computes the differences between the old and the new configurations, grouped in hunks with
+// the given number of context lines
+func Unified(oldContent, newContent []byte, contextLines int) *Diff {
+	a, b := splitLines(oldContent), splitLines(newContent)
+	matcher := difflib.NewMatcher(a, b)
+
+	d := &Diff{}
+	for _, op := range matcher.GetOpCodes() {
+		switch op.Tag {
+		case 'r':
+			d.Removed += op.I2 - op.I1
+			d.Added += op.J2 - op.J1
+		case 'd':
+			d.Removed += op.I2 - op.I1
+		case 'i':
+			d.Added += op.J2 - op.J1
+		}
+	}
+	if d.Added == 0 && d.Removed == 0 {
+		return d
+	}
+	for _, group := range matcher.GetGroupedOpCodes(contextLines) {
+		d.Hunks = append(d.Hunks, buildHunk(a, b, group))
+	}
+	return d
+}
+
+// Empty returns true if there is no difference between the configurations
+func (d *Diff) Empty() bool {
+	return d == nil || len(d.Hunks) == 0
+}
+
+// Format renders the diff in the unified format, with the given names for the old and new configurations
+func (d *Diff) Format(oldName, newName string) string {
+	if d.Empty() {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+	for _, h := range d.Hunks {
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", h.OldStart, h.OldLines, h.NewStart, h.NewLines)
+		for _, l := range h.Lines {
+			b.WriteString(string(l.Op))
+			b.WriteString(l.Text)
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+func splitLines(content []byte) []string {
+	s := strings.TrimSuffix(string(content), "\n")
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(s, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSuffix(lines[i], "\r")
+	}
+	return lines
+}
+
+// buildHunk builds a hunk from a group of operations turning a into b
+func buildHunk(a, b []string, group []difflib.OpCode) Hunk {
+	first, last := group[0], group[len(group)-1]
+	h := Hunk{
+		OldStart: first.I1 + 1,
+		OldLines: last.I2 - first.I1,
+		NewStart: first.J1 + 1,
+		NewLines: last.J2 - first.J1,
+	}
+	// by convention, empty ranges start at the line before them
+	if h.OldLines == 0 {
+		h.OldStart--
+	}
+	if h.NewLines == 0 {
+		h.NewStart--
+	}
+
+	for _, op := range group {
+		if op.Tag == 'e' {
+			for _, l := range a[op.I1:op.I2] {
+				h.Lines = append(h.Lines, Line{Op: Equal, Text: l})
+			}
+			continue
+		}
+		if op.Tag == 'r' || op.Tag == 'd' {
+			for _, l := range a[op.I1:op.I2] {
+				h.Lines = append(h.Lines, Line{Op: Delete, Text: l})
+			}
+		}
+		if op.Tag == 'r' || op.Tag == 'i' {
+			for _, l := range b[op.J1:op.J2] {
+				h.Lines = append(h.Lines, Line{Op: Insert, Text: l})
+			}
+		}
+	}
+	return h
+}
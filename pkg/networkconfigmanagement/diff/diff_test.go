@@ -0,0 +1,144 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+//go:build test && ncm
+
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const oldConfig = `hostname qa-device
+!
+interface GigabitEthernet0/1
+ ip address 192.168.1.1 255.255.255.0
+ no shutdown
+!
+interface GigabitEthernet0/2
+ shutdown
+!
+line vty 0 4
+ transport input ssh
+!
+end
+`
+
+const newConfig = `hostname qa-device
+!
+interface GigabitEthernet0/1
+ ip address 192.168.1.2 255.255.255.0
+ no shutdown
+!
+interface GigabitEthernet0/2
+ shutdown
+!
+line vty 0 4
+ transport input ssh
+!
+ntp server 10.0.0.1
+end
+`
+
+func TestUnified(t *testing.T) {
+	d := Unified([]byte(oldConfig), []byte(newConfig), DefaultContextLines)
+
+	assert.Equal(t, 2, d.Added)
+	assert.Equal(t, 1, d.Removed)
+	require.Len(t, d.Hunks, 2)
+	assert.Equal(t, Hunk{
+		OldStart: 1, OldLines: 7, NewStart: 1, NewLines: 7,
+		Lines: []Line{
+			{Op: Equal, Text: "hostname qa-device"},
+			{Op: Equal, Text: "!"},
+			{Op: Equal, Text: "interface GigabitEthernet0/1"},
+			{Op: Delete, Text: " ip address 192.168.1.1 255.255.255.0"},
+			{Op: Insert, Text: " ip address 192.168.1.2 255.255.255.0"},
+			{Op: Equal, Text: " no shutdown"},
+			{Op: Equal, Text: "!"},
+			{Op: Equal, Text: "interface GigabitEthernet0/2"},
+		},
+	}, d.Hunks[0])
+	assert.Equal(t, 10, d.Hunks[1].OldStart)
+	assert.Equal(t, 4, d.Hunks[1].OldLines)
+	assert.Equal(t, 10, d.Hunks[1].NewStart)
+	assert.Equal(t, 5, d.Hunks[1].NewLines)
+
+	expected := `--- old
++++ new
+@@ -1,7 +1,7 @@
+ hostname qa-device
+ !
+ interface GigabitEthernet0/1
+- ip address 192.168.1.1 255.255.255.0
++ ip address 192.168.1.2 255.255.255.0
+  no shutdown
+ !
+ interface GigabitEthernet0/2
+@@ -10,4 +10,5 @@
+ line vty 0 4
+  transport input ssh
+ !
++ntp server 10.0.0.1
+ end
+`
+	assert.Equal(t, expected, d.Format("old", "new"))
+}
+
+func TestUnified_MergesCloseChanges(t *testing.T) {
+	d := Unified([]byte("a\nb\nc\nd\ne\n"), []byte("a\nB\nc\nD\ne\n"), 1)
+	require.Len(t, d.Hunks, 1)
+	assert.Equal(t, "@@ -1,5 +1,5 @@", strings.SplitN(d.Format("old", "new"), "\n", 4)[2])
+}
+
+func TestUnified_Identical(t *testing.T) {
+	d := Unified([]byte(oldConfig), []byte(oldConfig), DefaultContextLines)
+	assert.True(t, d.Empty())
+	assert.Equal(t, "", d.Format("old", "new"))
+}
+
+func TestUnified_EmptyOld(t *testing.T) {
+	d := Unified(nil, []byte("a\nb\n"), DefaultContextLines)
+	assert.Equal(t, 2, d.Added)
+	require.Len(t, d.Hunks, 1)
+	assert.Equal(t, 0, d.Hunks[0].OldStart)
+	assert.Equal(t, 0, d.Hunks[0].OldLines)
+	assert.Equal(t, 1, d.Hunks[0].NewStart)
+}
+
+func TestUnified_ReconstructsConfigs(t *testing.T) {
+	var oldLines, newLines []string
+	for i := 0; i < 500; i++ {
+		oldLines = append(oldLines, fmt.Sprintf("line %d", i))
+		if i%7 == 0 {
+			newLines = append(newLines, fmt.Sprintf("changed line %d", i))
+		} else if i%11 != 0 {
+			newLines = append(newLines, fmt.Sprintf("line %d", i))
+		}
+	}
+	oldContent := strings.Join(oldLines, "\n")
+	newContent := strings.Join(newLines, "\n")
+
+	// with enough context, a single hunk holds both configurations
+	d := Unified([]byte(oldContent), []byte(newContent), len(oldLines))
+	require.Len(t, d.Hunks, 1)
+	var gotOld, gotNew []string
+	for _, l := range d.Hunks[0].Lines {
+		if l.Op != Insert {
+			gotOld = append(gotOld, l.Text)
+		}
+		if l.Op != Delete {
+			gotNew = append(gotNew, l.Text)
+		}
+	}
+	assert.Equal(t, oldLines, gotOld)
+	assert.Equal(t, newLines, gotNew)
+	assert.Equal(t, len(oldLines)-len(newLines), d.Removed-d.Added)
+}
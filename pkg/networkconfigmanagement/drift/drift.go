@@ -0,0 +1,208 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+//go:build ncm
+
+// Package drift detects the changes of network device configurations between check runs, and the
+// divergences between their running and startup configurations
+package drift
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/networkconfigmanagement/diff"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// ChangeType is the type of a detected configuration change
+type ChangeType string
+
+const (
+	// RunningChange represents a change of the running configuration since the last check run
+	RunningChange ChangeType = "running_change"
+	// UnsavedChanges represents a running configuration diverging from the startup configuration
+	UnsavedChanges ChangeType = "unsaved_changes"
+)
+
+var invalidFilenameChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// StorePath returns the directory where the last known configurations of the devices are stored, under the
+// given run path
+func StorePath(runPath string) string {
+	return filepath.Join(runPath, "network_config_management", "configs")
+}
+
+// DeviceState holds the last known configuration of a device
+type DeviceState struct {
+	RunningHash   string `json:"running_hash"`
+	RunningConfig []byte `json:"running_config"`
+	StartupHash   string `json:"startup_hash,omitempty"`
+	// UnsavedChangesHash identifies the last reported divergence between the running and startup configurations
+	UnsavedChangesHash string `json:"unsaved_changes_hash,omitempty"`
+	Timestamp          int64  `json:"timestamp"`
+}
+
+// Change holds a detected configuration change
+type Change struct {
+	Type     ChangeType
+	FromHash string
+	ToHash   string
+	Diff     *diff.Diff
+}
+
+// Store keeps the last known configuration of the devices, persisted on disk to survive agent restarts
+type Store struct {
+	mu     sync.Mutex
+	dir    string
+	states map[string]*DeviceState
+}
+
+// NewStore creates a store persisting the device states in the given directory, or only in memory if the
+// directory is empty
+func NewStore(dir string) *Store {
+	return &Store{
+		dir:    dir,
+		states: make(map[string]*DeviceState),
+	}
+}
+
+func (s *Store) path(deviceID string) string {
+	return filepath.Join(s.dir, invalidFilenameChars.ReplaceAllString(deviceID, "_")+".json")
+}
+
+// Get returns the last known state of the device, or nil if unknown
+func (s *Store) Get(deviceID string) *DeviceState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if state, ok := s.states[deviceID]; ok {
+		return state
+	}
+	if s.dir == "" {
+		return nil
+	}
+
+	content, err := os.ReadFile(s.path(deviceID))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warnf("unable to read the last known configuration of device %s: %s", deviceID, err)
+		}
+		return nil
+	}
+	var state DeviceState
+	if err := json.Unmarshal(content, &state); err != nil {
+		log.Warnf("unable to parse the last known configuration of device %s, ignoring it: %s", deviceID, err)
+		return nil
+	}
+	s.states[deviceID] = &state
+	return &state
+}
+
+// Set stores the state of the device
+func (s *Store) Set(deviceID string, state *DeviceState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.states[deviceID] = state
+	if s.dir == "" {
+		return nil
+	}
+
+	content, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return fmt.Errorf("unable to create the configurations directory: %w", err)
+	}
+	path := s.path(deviceID)
+	if err := os.WriteFile(path+".tmp", content, 0o600); err != nil {
+		return fmt.Errorf("unable to store the configuration of device %s: %w", deviceID, err)
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// Hash returns the hash identifying a configuration
+func Hash(config []byte) string {
+	sum := sha256.Sum256(config)
+	return hex.EncodeToString(sum[:])
+}
+
+// Detector compares the configurations retrieved from the devices with their last known configuration
+type Detector struct {
+	store        *Store
+	contextLines int
+}
+
+// NewDetector creates a detector keeping the last known configurations in the given store
+func NewDetector(store *Store) *Detector {
+	return &Detector{
+		store:        store,
+		contextLines: diff.DefaultContextLines,
+	}
+}
+
+// Detect compares the running configuration with the last known one and with the startup configuration
+// (which is nil if it could not be retrieved). The configurations are expected to be redacted and stripped
+// of their volatile lines.
+//
+// It returns the detected changes and the new state of the device, which only becomes the last known
+// configuration once committed, so that changes which couldn't be reported are detected again.
+func (d *Detector) Detect(deviceID string, running []byte, startup []byte, ts int64) ([]Change, *DeviceState) {
+	var changes []Change
+
+	previous := d.store.Get(deviceID)
+	state := &DeviceState{
+		RunningHash:   Hash(running),
+		RunningConfig: running,
+		Timestamp:     ts,
+	}
+
+	if previous != nil && previous.RunningHash != state.RunningHash {
+		changes = append(changes, Change{
+			Type:     RunningChange,
+			FromHash: previous.RunningHash,
+			ToHash:   state.RunningHash,
+			Diff:     diff.Unified(previous.RunningConfig, running, d.contextLines),
+		})
+	}
+
+	if startup == nil {
+		// keep reporting the divergence as is until the startup configuration can be retrieved again
+		if previous != nil {
+			state.StartupHash = previous.StartupHash
+			state.UnsavedChangesHash = previous.UnsavedChangesHash
+		}
+	} else {
+		state.StartupHash = Hash(startup)
+		if state.StartupHash != state.RunningHash {
+			state.UnsavedChangesHash = Hash([]byte(state.StartupHash + state.RunningHash))
+			// only report a divergence once, until either configuration changes
+			if previous == nil || previous.UnsavedChangesHash != state.UnsavedChangesHash {
+				changes = append(changes, Change{
+					Type:     UnsavedChanges,
+					FromHash: state.StartupHash,
+					ToHash:   state.RunningHash,
+					Diff:     diff.Unified(startup, running, d.contextLines),
+				})
+			}
+		}
+	}
+
+	return changes, state
+}
+
+// Commit stores the state returned by Detect as the last known configuration of the device, once its
+// changes have been reported
+func (d *Detector) Commit(deviceID string, state *DeviceState) error {
+	return d.store.Set(deviceID, state)
+}
@@ -0,0 +1,113 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+//go:build test && ncm
+
+package drift
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	deviceID = "default:10.0.0.1"
+	config1  = "hostname qa-device\n!\ninterface GigabitEthernet0/1\n ip address 192.168.1.1 255.255.255.0\n!\nend\n"
+	config2  = "hostname qa-device\n!\ninterface GigabitEthernet0/1\n ip address 192.168.1.2 255.255.255.0\n!\nend\n"
+)
+
+// detect detects the changes of the device configuration and commits its new state
+func detect(t *testing.T, detector *Detector, running []byte, startup []byte, ts int64) []Change {
+	changes, state := detector.Detect(deviceID, running, startup, ts)
+	require.NoError(t, detector.Commit(deviceID, state))
+	return changes
+}
+
+func TestDetector_RunningChange(t *testing.T) {
+	detector := NewDetector(NewStore(StorePath(t.TempDir())))
+
+	// the first run only records the configuration
+	changes := detect(t, detector, []byte(config1), []byte(config1), 1000)
+	assert.Empty(t, changes)
+
+	changes = detect(t, detector, []byte(config1), []byte(config1), 2000)
+	assert.Empty(t, changes)
+
+	changes = detect(t, detector, []byte(config2), []byte(config2), 3000)
+	require.Len(t, changes, 1)
+	assert.Equal(t, RunningChange, changes[0].Type)
+	assert.Equal(t, Hash([]byte(config1)), changes[0].FromHash)
+	assert.Equal(t, Hash([]byte(config2)), changes[0].ToHash)
+	assert.Equal(t, 1, changes[0].Diff.Added)
+	assert.Equal(t, 1, changes[0].Diff.Removed)
+}
+
+func TestDetector_UnsavedChanges(t *testing.T) {
+	detector := NewDetector(NewStore(""))
+
+	detect(t, detector, []byte(config1), []byte(config1), 1000)
+
+	// the running configuration changes without being saved
+	changes := detect(t, detector, []byte(config2), []byte(config1), 2000)
+	require.Len(t, changes, 2)
+	assert.Equal(t, RunningChange, changes[0].Type)
+	assert.Equal(t, UnsavedChanges, changes[1].Type)
+	assert.Equal(t, Hash([]byte(config1)), changes[1].FromHash)
+	assert.Equal(t, Hash([]byte(config2)), changes[1].ToHash)
+
+	// the divergence is only reported once
+	changes = detect(t, detector, []byte(config2), []byte(config1), 3000)
+	assert.Empty(t, changes)
+
+	// nor while the startup configuration cannot be retrieved
+	changes = detect(t, detector, []byte(config2), nil, 4000)
+	assert.Empty(t, changes)
+
+	// the changes are saved
+	changes = detect(t, detector, []byte(config2), []byte(config2), 5000)
+	assert.Empty(t, changes)
+
+	// and unsaved again
+	changes = detect(t, detector, []byte(config1), []byte(config2), 6000)
+	require.Len(t, changes, 2)
+	assert.Equal(t, UnsavedChanges, changes[1].Type)
+}
+
+func TestDetector_Uncommitted(t *testing.T) {
+	detector := NewDetector(NewStore(StorePath(t.TempDir())))
+	detect(t, detector, []byte(config1), nil, 1000)
+
+	// the changes which couldn't be reported are detected again on the next run
+	changes, _ := detector.Detect(deviceID, []byte(config2), nil, 2000)
+	require.Len(t, changes, 1)
+	changes, state := detector.Detect(deviceID, []byte(config2), nil, 3000)
+	require.Len(t, changes, 1)
+	assert.Equal(t, Hash([]byte(config1)), changes[0].FromHash)
+
+	require.NoError(t, detector.Commit(deviceID, state))
+	changes, _ = detector.Detect(deviceID, []byte(config2), nil, 4000)
+	assert.Empty(t, changes)
+}
+
+func TestStore_Persistence(t *testing.T) {
+	dir := StorePath(t.TempDir())
+
+	detect(t, NewDetector(NewStore(dir)), []byte(config1), nil, 1000)
+
+	// a new store, as after an agent restart, loads the last known configuration
+	state := NewStore(dir).Get(deviceID)
+	require.NotNil(t, state)
+	assert.Equal(t, Hash([]byte(config1)), state.RunningHash)
+	assert.Equal(t, []byte(config1), state.RunningConfig)
+	assert.Equal(t, int64(1000), state.Timestamp)
+
+	changes := detect(t, NewDetector(NewStore(dir)), []byte(config2), nil, 2000)
+	require.Len(t, changes, 1)
+	assert.Equal(t, RunningChange, changes[0].Type)
+
+	assert.Nil(t, NewStore(dir).Get("default:10.0.0.2"))
+}
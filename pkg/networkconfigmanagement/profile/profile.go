@@ -64,13 +64,15 @@ func (p *BaseProfile) SetName(name string) { p.Name = name }
 // NCMProfileRaw represents the exact profile to unmarshal from the YAML or JSON file
 type NCMProfileRaw struct {
 	BaseProfile
-	Commands []Commands `json:"commands" yaml:"commands"`
+	Transport string     `json:"transport" yaml:"transport"` // transport the commands are sent with, ssh if not set
+	Commands  []Commands `json:"commands" yaml:"commands"`
 }
 
 // NCMProfile represents the profile with transformed variables such as the commands map for easy access to commands
 type NCMProfile struct {
 	BaseProfile
-	Commands map[CommandType]*Commands
+	Transport string
+	Commands  map[CommandType]*Commands
 }
 
 // ParseProfileFromFile is a base function to easily unmarshal YAML for any type T given a file path
@@ -105,6 +107,7 @@ func ParseNCMProfileFromFile(filePath string) (*NCMProfile, error) {
 		return nil, err
 	}
 	var np NCMProfile
+	np.Transport = ncmRawProfile.Transport
 	np.Commands = make(map[CommandType]*Commands)
 	for i := range ncmRawProfile.Commands {
 		cmd := &ncmRawProfile.Commands[i]
@@ -115,6 +118,14 @@ func ParseNCMProfileFromFile(filePath string) (*NCMProfile, error) {
 	return &np, nil
 }
 
+// SupportsTransport returns true if the commands of the profile are meant to be sent with the given transport
+func (np *NCMProfile) SupportsTransport(transport string) bool {
+	if np.Transport == "" {
+		return transport == "" || transport == "ssh"
+	}
+	return np.Transport == transport
+}
+
 // GetCommandValues retrieves the list of CLI commands corresponding to the main command (e.g. running) intended for the device
 func (np *NCMProfile) GetCommandValues(command CommandType) ([]string, error) {
 	cmds, ok := np.Commands[command]
@@ -8,6 +8,7 @@
 package profile
 
 import (
+	"bytes"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -22,6 +23,7 @@ type ProcessingRules struct {
 	MetadataRules   []MetadataRule   `json:"metadata" yaml:"metadata"`
 	ValidationRules []ValidationRule `json:"validation" yaml:"validation"`
 	RedactionRules  []RedactionRule  `json:"redaction" yaml:"redaction"`
+	VolatileRules   []VolatileRule   `json:"volatile" yaml:"volatile"`
 }
 
 // MetadataRule represents the rules for parsing metadata from a network device's command
@@ -53,6 +55,12 @@ type RedactionRule struct {
 	Replacement string         `json:"replacement" yaml:"replacement"`
 }
 
+// VolatileRule represents patterns of lines that change without the configuration changing (e.g. timestamps),
+// that are ignored when comparing configurations
+type VolatileRule struct {
+	Regex *regexp.Regexp `json:"regex" yaml:"regex"`
+}
+
 // ExtractedMetadata is a means to hold metadata to be emitted as metrics or sent as part of the payload
 type ExtractedMetadata struct {
 	Timestamp  int64
@@ -133,6 +141,33 @@ func (p *NCMProfile) ValidateOutput(ct CommandType, output []byte) error {
 	return nil
 }
 
+// StripVolatileLines removes the lines matching the volatile rules of the command from its output, for the
+// output to be compared with other configurations
+func (p *NCMProfile) StripVolatileLines(ct CommandType, output []byte) []byte {
+	commandInfo, ok := p.Commands[ct]
+	if !ok || len(commandInfo.ProcessingRules.VolatileRules) == 0 {
+		return output
+	}
+	result := make([]byte, 0, len(output))
+	for _, line := range bytes.SplitAfter(output, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		content := bytes.TrimRight(line, "\r\n")
+		volatile := false
+		for _, rule := range commandInfo.ProcessingRules.VolatileRules {
+			if rule.Regex.Match(content) {
+				volatile = true
+				break
+			}
+		}
+		if !volatile {
+			result = append(result, line...)
+		}
+	}
+	return result
+}
+
 func (p *NCMProfile) applyRedactions(ct CommandType, output []byte) ([]byte, error) {
 	commandInfo, ok := p.Commands[ct]
 	if !ok {
@@ -11,6 +11,7 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -174,3 +175,20 @@ func Test_compileProcessingRules(t *testing.T) {
 		})
 	}
 }
+
+func Test_StripVolatileLines(t *testing.T) {
+	profile := newTestProfile()
+	profile.Commands[Running].ProcessingRules.VolatileRules = []VolatileRule{
+		{Regex: regexp.MustCompile(`^! Last configuration change at`)},
+		{Regex: regexp.MustCompile(`^Current configuration : \d+ bytes$`)},
+	}
+
+	actual := profile.StripVolatileLines(Running, []byte(exampleConfig))
+	assert.NotContains(t, string(actual), "Last configuration change")
+	assert.NotContains(t, string(actual), "Current configuration")
+	assert.Contains(t, string(actual), "hostname qa-device\n")
+	assert.Equal(t, strings.Count(exampleConfig, "\n")-2, strings.Count(string(actual), "\n"))
+
+	// outputs of commands without volatile rules are left untouched
+	assert.Equal(t, []byte(exampleConfig), profile.StripVolatileLines(Version, []byte(exampleConfig)))
+}
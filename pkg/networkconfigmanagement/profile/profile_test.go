@@ -123,6 +123,18 @@ func Test_GetCommandValues(t *testing.T) {
 	}
 }
 
+func Test_SupportsTransport(t *testing.T) {
+	cliProfile := &NCMProfile{BaseProfile: BaseProfile{Name: "cisco-ios"}}
+	assert.True(t, cliProfile.SupportsTransport(""))
+	assert.True(t, cliProfile.SupportsTransport("ssh"))
+	assert.False(t, cliProfile.SupportsTransport("netconf"))
+
+	netconfProfile := &NCMProfile{BaseProfile: BaseProfile{Name: "netconf"}, Transport: "netconf"}
+	assert.True(t, netconfProfile.SupportsTransport("netconf"))
+	assert.False(t, netconfProfile.SupportsTransport("ssh"))
+	assert.False(t, netconfProfile.SupportsTransport("restconf"))
+}
+
 func Test_ParseProfileFromFile(t *testing.T) {
 	mockConfig := configmock.New(t)
 	defaultTestConfdPath, _ := filepath.Abs(filepath.Join("..", "test", "conf.d"))
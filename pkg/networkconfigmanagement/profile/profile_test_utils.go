@@ -143,6 +143,11 @@ var testProfile = &NCMProfile{
 				RedactionRules: []RedactionRule{
 					{Regex: regexp.MustCompile(`(username .+ (password|secret) \d) .+`), Replacement: "$1 <redacted secret>"},
 				},
+				VolatileRules: []VolatileRule{
+					{Regex: regexp.MustCompile(`^Building configuration\.\.\.`)},
+					{Regex: regexp.MustCompile(`^Current configuration : \d+ bytes`)},
+					{Regex: regexp.MustCompile(`^! Last configuration change at`)},
+				},
 			},
 		},
 	},
@@ -175,6 +180,11 @@ var runningCommandsWithCompiledRegex = &Commands{
 				Replacement: `$1 <redacted secret>`,
 			},
 		},
+		VolatileRules: []VolatileRule{
+			{Regex: regexp.MustCompile(`^Building configuration\.\.\.`)},
+			{Regex: regexp.MustCompile(`^Current configuration : \d+ bytes`)},
+			{Regex: regexp.MustCompile(`^! Last configuration change at`)},
+		},
 	},
 	Scrubber: getRunningScrubber(),
 }
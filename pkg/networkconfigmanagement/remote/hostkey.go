@@ -0,0 +1,251 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+//go:build ncm
+
+package remote
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	ncmconfig "github.com/DataDog/datadog-agent/pkg/networkconfigmanagement/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// knownHostsStoreMu serializes the writes to the known_hosts files managed by the agent
+var knownHostsStoreMu sync.Mutex
+
+// errHostKeyCaptured is used to abort the SSH handshake once the host key of a device has been captured
+var errHostKeyCaptured = errors.New("host key captured")
+
+// KnownHostsStorePath returns the path of the known_hosts file where the agent pins the device host keys
+// trusted on first use, under the given run path
+func KnownHostsStorePath(runPath string) string {
+	return filepath.Join(runPath, "network_config_management", "known_hosts")
+}
+
+// HostKeyMismatchError is returned when a device presents a host key different from the one pinned for it
+type HostKeyMismatchError struct {
+	Host           string
+	KnownHostsFile string
+	Fingerprint    string   // SHA256 fingerprint of the key presented by the device
+	Expected       []string // SHA256 fingerprints of the keys pinned for the device
+}
+
+func (e *HostKeyMismatchError) Error() string {
+	return fmt.Sprintf("host key verification failed for %s: the device presented key %s but %s is pinned in %s; "+
+		"if the device key was legitimately changed, re-pin it with `datadog-agent network-config-management host-keys repin %s`",
+		e.Host, e.Fingerprint, strings.Join(e.Expected, ", "), e.KnownHostsFile, e.Host)
+}
+
+// UnknownHostKeyError is returned by the strict policy when no key is pinned for a device
+type UnknownHostKeyError struct {
+	Host           string
+	KnownHostsFile string
+	Fingerprint    string
+}
+
+func (e *UnknownHostKeyError) Error() string {
+	return fmt.Sprintf("host key verification failed for %s: no host key found in %s for key %s", e.Host, e.KnownHostsFile, e.Fingerprint)
+}
+
+// NewHostKeyCallback builds the SSH host key callback enforcing the given policy. The known_hosts file is read
+// when the callback is built, so a new callback should be built for every connection.
+func NewHostKeyCallback(policy ncmconfig.HostKeyPolicy, knownHostsFile string) (ssh.HostKeyCallback, error) {
+	if policy != ncmconfig.HostKeyPolicyInsecure && knownHostsFile == "" {
+		return nil, fmt.Errorf("no known_hosts file set for the %q SSH host key policy", policy)
+	}
+
+	switch policy {
+	case ncmconfig.HostKeyPolicyInsecure:
+		return ssh.InsecureIgnoreHostKey(), nil
+	case ncmconfig.HostKeyPolicyStrict:
+		check, err := knownhosts.New(knownHostsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load known_hosts file %s: %w", knownHostsFile, err)
+		}
+		return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			return wrapKnownHostsError(check(hostname, remote, key), hostname, knownHostsFile, key)
+		}, nil
+	case ncmconfig.HostKeyPolicyTOFU, "":
+		if err := ensureKnownHostsFile(knownHostsFile); err != nil {
+			return nil, err
+		}
+		check, err := knownhosts.New(knownHostsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load known_hosts file %s: %w", knownHostsFile, err)
+		}
+		return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			err := check(hostname, remote, key)
+			var keyErr *knownhosts.KeyError
+			if errors.As(err, &keyErr) && len(keyErr.Want) == 0 {
+				log.Infof("Trusting host key %s for %s on first use, pinning it in %s", ssh.FingerprintSHA256(key), hostname, knownHostsFile)
+				return PinHostKey(knownHostsFile, hostname, key)
+			}
+			return wrapKnownHostsError(err, hostname, knownHostsFile, key)
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown SSH host key policy %q", policy)
+	}
+}
+
+// wrapKnownHostsError converts the errors of the knownhosts package to errors describing the failed verification
+func wrapKnownHostsError(err error, hostname string, knownHostsFile string, key ssh.PublicKey) error {
+	var keyErr *knownhosts.KeyError
+	if !errors.As(err, &keyErr) {
+		return err
+	}
+	if len(keyErr.Want) == 0 {
+		return &UnknownHostKeyError{
+			Host:           hostname,
+			KnownHostsFile: knownHostsFile,
+			Fingerprint:    ssh.FingerprintSHA256(key),
+		}
+	}
+	expected := make([]string, 0, len(keyErr.Want))
+	for _, want := range keyErr.Want {
+		expected = append(expected, ssh.FingerprintSHA256(want.Key))
+	}
+	return &HostKeyMismatchError{
+		Host:           hostname,
+		KnownHostsFile: knownHostsFile,
+		Fingerprint:    ssh.FingerprintSHA256(key),
+		Expected:       expected,
+	}
+}
+
+// ensureKnownHostsFile creates the known_hosts file and its parent directory if they do not exist yet
+func ensureKnownHostsFile(knownHostsFile string) error {
+	knownHostsStoreMu.Lock()
+	defer knownHostsStoreMu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(knownHostsFile), 0o700); err != nil {
+		return fmt.Errorf("failed to create known_hosts directory: %w", err)
+	}
+	f, err := os.OpenFile(knownHostsFile, os.O_CREATE|os.O_RDONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create known_hosts file %s: %w", knownHostsFile, err)
+	}
+	return f.Close()
+}
+
+// PinnedHostKey is a host key pinned in a known_hosts file
+type PinnedHostKey struct {
+	Hosts       []string
+	KeyType     string
+	Fingerprint string
+}
+
+// ListPinnedHostKeys returns the host keys pinned in the given known_hosts file
+func ListPinnedHostKeys(knownHostsFile string) ([]PinnedHostKey, error) {
+	content, err := os.ReadFile(knownHostsFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var keys []PinnedHostKey
+	rest := content
+	for len(rest) > 0 {
+		var hosts []string
+		var key ssh.PublicKey
+		_, hosts, key, _, rest, err = ssh.ParseKnownHosts(rest)
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("failed to parse known_hosts file %s: %w", knownHostsFile, err)
+		}
+		keys = append(keys, PinnedHostKey{
+			Hosts:       hosts,
+			KeyType:     key.Type(),
+			Fingerprint: ssh.FingerprintSHA256(key),
+		})
+	}
+	return keys, nil
+}
+
+// PinHostKey replaces the keys pinned for the given host in the known_hosts file by the given key
+func PinHostKey(knownHostsFile string, hostname string, key ssh.PublicKey) error {
+	knownHostsStoreMu.Lock()
+	defer knownHostsStoreMu.Unlock()
+
+	address := knownhosts.Normalize(hostname)
+	content, err := os.ReadFile(knownHostsFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	var updated bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if lineMatchesHost(line, address) {
+			continue
+		}
+		updated.WriteString(line)
+		updated.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	updated.WriteString(knownhosts.Line([]string{address}, key))
+	updated.WriteByte('\n')
+
+	if err := os.MkdirAll(filepath.Dir(knownHostsFile), 0o700); err != nil {
+		return fmt.Errorf("failed to create known_hosts directory: %w", err)
+	}
+	tmpFile := knownHostsFile + ".tmp"
+	if err := os.WriteFile(tmpFile, updated.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("failed to write known_hosts file %s: %w", knownHostsFile, err)
+	}
+	return os.Rename(tmpFile, knownHostsFile)
+}
+
+// lineMatchesHost returns true if the known_hosts line holds a plain host pattern equal to the given address
+func lineMatchesHost(line string, address string) bool {
+	_, hosts, _, _, _, err := ssh.ParseKnownHosts([]byte(line))
+	if err != nil {
+		return false
+	}
+	for _, h := range hosts {
+		if h == address {
+			return true
+		}
+	}
+	return false
+}
+
+// FetchHostKey connects to the device to retrieve its host key, aborting the connection before authenticating
+func FetchHostKey(device *ncmconfig.DeviceInstance) (ssh.PublicKey, error) {
+	var hostKey ssh.PublicKey
+	config := DefaultSSHClientConfig("")
+	config.HostKeyCallback = func(_ string, _ net.Addr, key ssh.PublicKey) error {
+		hostKey = key
+		return errHostKeyCaptured
+	}
+	_, err := connectToHost(device.IPAddress, device.Auth, config)
+	if hostKey == nil {
+		if err == nil {
+			err = errors.New("no host key presented")
+		}
+		return nil, err
+	}
+	return hostKey, nil
+}
@@ -0,0 +1,148 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+//go:build test && ncm
+
+package remote
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	ncmconfig "github.com/DataDog/datadog-agent/pkg/networkconfigmanagement/config"
+)
+
+var testRemoteAddr = &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 22}
+
+func newTestHostKey(t *testing.T) ssh.PublicKey {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	key, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+	return key
+}
+
+func TestHostKeyCallback_TOFU(t *testing.T) {
+	knownHostsFile := KnownHostsStorePath(t.TempDir())
+	key := newTestHostKey(t)
+
+	// the first connection pins the key
+	callback, err := NewHostKeyCallback(ncmconfig.HostKeyPolicyTOFU, knownHostsFile)
+	require.NoError(t, err)
+	require.NoError(t, callback("10.0.0.1:22", testRemoteAddr, key))
+
+	keys, err := ListPinnedHostKeys(knownHostsFile)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, []string{"10.0.0.1"}, keys[0].Hosts)
+	assert.Equal(t, ssh.FingerprintSHA256(key), keys[0].Fingerprint)
+
+	// the next connections accept the same key only
+	callback, err = NewHostKeyCallback(ncmconfig.HostKeyPolicyTOFU, knownHostsFile)
+	require.NoError(t, err)
+	require.NoError(t, callback("10.0.0.1:22", testRemoteAddr, key))
+
+	otherKey := newTestHostKey(t)
+	err = callback("10.0.0.1:22", testRemoteAddr, otherKey)
+	var mismatchErr *HostKeyMismatchError
+	require.ErrorAs(t, err, &mismatchErr)
+	assert.Equal(t, ssh.FingerprintSHA256(otherKey), mismatchErr.Fingerprint)
+	assert.Equal(t, []string{ssh.FingerprintSHA256(key)}, mismatchErr.Expected)
+	assert.Contains(t, err.Error(), "host-keys repin 10.0.0.1:22")
+
+	// re-pinning accepts the new key
+	require.NoError(t, PinHostKey(knownHostsFile, "10.0.0.1:22", otherKey))
+	callback, err = NewHostKeyCallback(ncmconfig.HostKeyPolicyTOFU, knownHostsFile)
+	require.NoError(t, err)
+	require.NoError(t, callback("10.0.0.1:22", testRemoteAddr, otherKey))
+	assert.Error(t, callback("10.0.0.1:22", testRemoteAddr, key))
+
+	keys, err = ListPinnedHostKeys(knownHostsFile)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, ssh.FingerprintSHA256(otherKey), keys[0].Fingerprint)
+}
+
+func TestHostKeyCallback_Strict(t *testing.T) {
+	knownHostsFile := filepath.Join(t.TempDir(), "known_hosts")
+	key := newTestHostKey(t)
+
+	// the strict policy requires an existing known_hosts file
+	_, err := NewHostKeyCallback(ncmconfig.HostKeyPolicyStrict, knownHostsFile)
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(knownHostsFile, nil, 0o600))
+	callback, err := NewHostKeyCallback(ncmconfig.HostKeyPolicyStrict, knownHostsFile)
+	require.NoError(t, err)
+	err = callback("10.0.0.1:22", testRemoteAddr, key)
+	var unknownErr *UnknownHostKeyError
+	require.ErrorAs(t, err, &unknownErr)
+
+	// unknown keys are never pinned by the strict policy
+	keys, err := ListPinnedHostKeys(knownHostsFile)
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	require.NoError(t, PinHostKey(knownHostsFile, "10.0.0.1:22", key))
+	callback, err = NewHostKeyCallback(ncmconfig.HostKeyPolicyStrict, knownHostsFile)
+	require.NoError(t, err)
+	assert.NoError(t, callback("10.0.0.1:22", testRemoteAddr, key))
+
+	var mismatchErr *HostKeyMismatchError
+	assert.ErrorAs(t, callback("10.0.0.1:22", testRemoteAddr, newTestHostKey(t)), &mismatchErr)
+}
+
+func TestHostKeyCallback_Insecure(t *testing.T) {
+	callback, err := NewHostKeyCallback(ncmconfig.HostKeyPolicyInsecure, "")
+	require.NoError(t, err)
+	assert.NoError(t, callback("10.0.0.1:22", testRemoteAddr, newTestHostKey(t)))
+}
+
+func TestHostKeyCallback_MissingKnownHostsFile(t *testing.T) {
+	_, err := NewHostKeyCallback(ncmconfig.HostKeyPolicyTOFU, "")
+	assert.ErrorContains(t, err, "no known_hosts file set")
+}
+
+func TestPinHostKey_KeepsOtherHosts(t *testing.T) {
+	knownHostsFile := filepath.Join(t.TempDir(), "known_hosts")
+	key1, key2 := newTestHostKey(t), newTestHostKey(t)
+
+	require.NoError(t, PinHostKey(knownHostsFile, "10.0.0.1:22", key1))
+	require.NoError(t, PinHostKey(knownHostsFile, "10.0.0.2:2222", key2))
+	require.NoError(t, PinHostKey(knownHostsFile, "10.0.0.1:22", key2))
+
+	keys, err := ListPinnedHostKeys(knownHostsFile)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, []string{"[10.0.0.2]:2222"}, keys[0].Hosts)
+	assert.Equal(t, []string{"10.0.0.1"}, keys[1].Hosts)
+	assert.Equal(t, ssh.FingerprintSHA256(key2), keys[1].Fingerprint)
+}
+
+func TestNewSSHClientConfig(t *testing.T) {
+	device := &ncmconfig.DeviceInstance{
+		IPAddress: "10.0.0.1",
+		Auth: ncmconfig.AuthCredentials{
+			SSHHostKeyPolicy: ncmconfig.HostKeyPolicyTOFU,
+		},
+	}
+	config := NewSSHClientConfig(device, "/opt/datadog-agent/run")
+	assert.Equal(t, ncmconfig.HostKeyPolicyTOFU, config.HostKeyPolicy)
+	assert.Equal(t, "/opt/datadog-agent/run/network_config_management/known_hosts", config.KnownHostsFile)
+
+	device.Auth.SSHHostKeyPolicy = ncmconfig.HostKeyPolicyStrict
+	device.Auth.SSHKnownHostsFile = "/etc/ssh/ssh_known_hosts"
+	config = NewSSHClientConfig(device, "/opt/datadog-agent/run")
+	assert.Equal(t, ncmconfig.HostKeyPolicyStrict, config.HostKeyPolicy)
+	assert.Equal(t, "/etc/ssh/ssh_known_hosts", config.KnownHostsFile)
+}
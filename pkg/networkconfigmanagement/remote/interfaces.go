@@ -5,10 +5,15 @@
 
 //go:build ncm
 
-// Package remote provides interfaces for remote device communications (SSH/Telnet) to retrieve configurations
+// Package remote provides interfaces for remote device communications (SSH, NETCONF, RESTCONF) to retrieve configurations
 package remote
 
-import "github.com/DataDog/datadog-agent/pkg/networkconfigmanagement/profile"
+import (
+	"fmt"
+
+	ncmconfig "github.com/DataDog/datadog-agent/pkg/networkconfigmanagement/config"
+	"github.com/DataDog/datadog-agent/pkg/networkconfigmanagement/profile"
+)
 
 // Client defines the interface for a remote client that can create sessions to execute commands on a device
 type Client interface {
@@ -25,3 +30,19 @@ type Session interface {
 	CombinedOutput(cmd string) ([]byte, error)
 	Close() error
 }
+
+// NewClient creates the client for the transport of the device, the SSH host keys being pinned in the store of
+// the agent under the given run path unless set otherwise for the device
+func NewClient(device *ncmconfig.DeviceInstance, runPath string) (Client, error) {
+	sshConfig := NewSSHClientConfig(device, runPath)
+	switch device.Transport {
+	case ncmconfig.TransportSSH, "":
+		return NewSSHClient(device, sshConfig), nil
+	case ncmconfig.TransportNETCONF:
+		return NewNETCONFClient(device, sshConfig), nil
+	case ncmconfig.TransportRESTCONF:
+		return NewRESTCONFClient(device, sshConfig.Timeout)
+	default:
+		return nil, fmt.Errorf("unsupported transport %q for device %s", device.Transport, device.IPAddress)
+	}
+}
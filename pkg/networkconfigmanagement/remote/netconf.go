@@ -0,0 +1,364 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+//go:build ncm
+
+package remote
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+
+	ncmconfig "github.com/DataDog/datadog-agent/pkg/networkconfigmanagement/config"
+	"github.com/DataDog/datadog-agent/pkg/networkconfigmanagement/profile"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	netconfBaseNamespace = "urn:ietf:params:xml:ns:netconf:base:1.0"
+	netconfBase10        = "urn:ietf:params:netconf:base:1.0"
+	netconfBase11        = "urn:ietf:params:netconf:base:1.1"
+	// netconfEndOfMessage delimits the messages with the base:1.0 framing (RFC 6242 section 4.3)
+	netconfEndOfMessage = "]]>]]>"
+)
+
+// NETCONFClient implements Client using NETCONF over SSH (RFC 6241, RFC 6242), the commands of the profile being
+// the operations of the RPCs sent to the device (e.g. <get-config>)
+type NETCONFClient struct {
+	client  *ssh.Client
+	device  *ncmconfig.DeviceInstance
+	config  *SSHClientConfig
+	prof    *profile.NCMProfile
+	session *ssh.Session
+	conn    *netconfConn
+}
+
+// NETCONFSession implements Session by sending RPCs on the NETCONF session of the client
+type NETCONFSession struct {
+	conn *netconfConn
+}
+
+// netconfConn handles the framing of the messages exchanged on a NETCONF session
+type netconfConn struct {
+	mu           sync.Mutex
+	w            io.Writer
+	r            *bufio.Reader
+	chunked      bool
+	messageID    int
+	capabilities []string
+}
+
+type netconfHello struct {
+	XMLName      xml.Name `xml:"hello"`
+	Capabilities []string `xml:"capabilities>capability"`
+	SessionID    int      `xml:"session-id,omitempty"`
+}
+
+type netconfRPCReply struct {
+	XMLName   xml.Name          `xml:"rpc-reply"`
+	MessageID string            `xml:"message-id,attr"`
+	Errors    []NETCONFRPCError `xml:"rpc-error"`
+	Data      struct {
+		Content []byte `xml:",innerxml"`
+	} `xml:"data"`
+}
+
+// NETCONFRPCError is an error returned by a device in reply to a NETCONF RPC
+type NETCONFRPCError struct {
+	Type     string `xml:"error-type"`
+	Tag      string `xml:"error-tag"`
+	Severity string `xml:"error-severity"`
+	Message  string `xml:"error-message"`
+}
+
+func (e *NETCONFRPCError) Error() string {
+	msg := strings.TrimSpace(e.Message)
+	if msg == "" {
+		msg = e.Tag
+	}
+	return fmt.Sprintf("netconf rpc error (type: %s, tag: %s): %s", e.Type, e.Tag, msg)
+}
+
+// NewNETCONFClient creates a new NETCONF client for the given device configuration, connecting to the device
+// with the given SSH client configuration
+func NewNETCONFClient(device *ncmconfig.DeviceInstance, config *SSHClientConfig) *NETCONFClient {
+	return &NETCONFClient{
+		device: device,
+		config: config,
+	}
+}
+
+// SetProfile sets the NCM profile for the device for the client to know which RPCs to send
+func (c *NETCONFClient) SetProfile(profile *profile.NCMProfile) {
+	c.prof = profile
+}
+
+// Connect establishes a new SSH connection to the device, and opens a NETCONF session by exchanging hellos
+func (c *NETCONFClient) Connect() error {
+	client, err := connectToHost(c.device.IPAddress, c.device.Auth, c.config)
+	if err != nil {
+		return err
+	}
+	session, err := client.NewSession()
+	if err != nil {
+		_ = client.Close()
+		return fmt.Errorf("failed to create SSH session: %w", err)
+	}
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		_ = client.Close()
+		return err
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		_ = client.Close()
+		return err
+	}
+	if err := session.RequestSubsystem("netconf"); err != nil {
+		_ = client.Close()
+		return fmt.Errorf("failed to start the netconf subsystem on %s: %w", c.device.IPAddress, err)
+	}
+
+	conn := newNETCONFConn(stdin, stdout)
+	if err := conn.hello(); err != nil {
+		_ = client.Close()
+		return fmt.Errorf("failed to open a netconf session on %s: %w", c.device.IPAddress, err)
+	}
+	log.Debugf("NETCONF session opened on %s (chunked framing: %t)", c.device.IPAddress, conn.chunked)
+
+	c.client = client
+	c.session = session
+	c.conn = conn
+	return nil
+}
+
+// NewSession returns a session sending RPCs on the NETCONF session of the client
+func (c *NETCONFClient) NewSession() (Session, error) {
+	if c.conn == nil {
+		return nil, fmt.Errorf("netconf session not opened")
+	}
+	return &NETCONFSession{conn: c.conn}, nil
+}
+
+// Capabilities returns the capabilities advertised by the device in its hello
+func (c *NETCONFClient) Capabilities() []string {
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.capabilities
+}
+
+// RetrieveRunningConfig retrieves the running configuration of the device with the RPCs of the profile
+func (c *NETCONFClient) RetrieveRunningConfig() ([]byte, error) {
+	return c.retrieveConfig(profile.Running)
+}
+
+// RetrieveStartupConfig retrieves the startup configuration of the device with the RPCs of the profile
+func (c *NETCONFClient) RetrieveStartupConfig() ([]byte, error) {
+	return c.retrieveConfig(profile.Startup)
+}
+
+func (c *NETCONFClient) retrieveConfig(ct profile.CommandType) ([]byte, error) {
+	operations, err := c.prof.GetCommandValues(ct)
+	if err != nil {
+		return []byte{}, err
+	}
+	session, err := c.NewSession()
+	if err != nil {
+		return []byte{}, err
+	}
+	defer session.Close()
+
+	var config []byte
+	for _, operation := range operations {
+		log.Debugf("Sending NETCONF RPC: %s", operation)
+		output, err := session.CombinedOutput(operation)
+		if err != nil {
+			return []byte{}, fmt.Errorf("rpc %s failed: %w", operation, err)
+		}
+		config = append(config, output...)
+		config = append(config, '\n')
+	}
+	if err := c.prof.ValidateOutput(ct, config); err != nil {
+		return []byte{}, err
+	}
+	return config, nil
+}
+
+// Close closes the NETCONF session and the SSH connection
+func (c *NETCONFClient) Close() error {
+	if c.conn != nil {
+		// best effort, the device closes the session anyway once the connection is closed
+		if _, err := c.conn.rpc("<close-session/>"); err != nil {
+			log.Debugf("unable to close the netconf session on %s: %s", c.device.IPAddress, err)
+		}
+		c.conn = nil
+	}
+	if c.session != nil {
+		_ = c.session.Close()
+		c.session = nil
+	}
+	if c.client != nil {
+		return c.client.Close()
+	}
+	return nil
+}
+
+// CombinedOutput sends the operation in an RPC and returns the content of the data of the reply
+func (s *NETCONFSession) CombinedOutput(operation string) ([]byte, error) {
+	return s.conn.rpc(operation)
+}
+
+// Close is a no-op, the NETCONF session is closed along with the client
+func (s *NETCONFSession) Close() error {
+	return nil
+}
+
+func newNETCONFConn(w io.Writer, r io.Reader) *netconfConn {
+	return &netconfConn{
+		w: w,
+		r: bufio.NewReader(r),
+	}
+}
+
+// hello exchanges the hellos with the device, and switches to the chunked framing if both peers support it
+func (n *netconfConn) hello() error {
+	hello, err := xml.Marshal(&netconfHello{
+		XMLName:      xml.Name{Space: netconfBaseNamespace, Local: "hello"},
+		Capabilities: []string{netconfBase10, netconfBase11},
+	})
+	if err != nil {
+		return err
+	}
+	if err := n.writeMessage(append([]byte(xml.Header), hello...)); err != nil {
+		return err
+	}
+
+	msg, err := n.readMessage()
+	if err != nil {
+		return err
+	}
+	var serverHello netconfHello
+	if err := xml.Unmarshal(msg, &serverHello); err != nil {
+		return fmt.Errorf("invalid hello: %w", err)
+	}
+	n.capabilities = serverHello.Capabilities
+	for _, capability := range serverHello.Capabilities {
+		if strings.TrimSpace(capability) == netconfBase11 {
+			n.chunked = true
+		}
+	}
+	return nil
+}
+
+// rpc sends the operation in an RPC, and returns the content of the data of the reply
+func (n *netconfConn) rpc(operation string) ([]byte, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.messageID++
+	messageID := strconv.Itoa(n.messageID)
+	rpc := fmt.Sprintf(`%s<rpc message-id="%s" xmlns="%s">%s</rpc>`, xml.Header, messageID, netconfBaseNamespace, operation)
+	if err := n.writeMessage([]byte(rpc)); err != nil {
+		return nil, err
+	}
+
+	msg, err := n.readMessage()
+	if err != nil {
+		return nil, err
+	}
+	var reply netconfRPCReply
+	if err := xml.Unmarshal(msg, &reply); err != nil {
+		return nil, fmt.Errorf("invalid rpc reply: %w", err)
+	}
+	if reply.MessageID != messageID {
+		return nil, fmt.Errorf("unexpected rpc reply message-id %q, expected %q", reply.MessageID, messageID)
+	}
+	var errs []error
+	for i := range reply.Errors {
+		rpcErr := &reply.Errors[i]
+		if rpcErr.Severity == "warning" {
+			log.Debugf("netconf rpc warning: %s", rpcErr)
+			continue
+		}
+		errs = append(errs, rpcErr)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return bytes.TrimSpace(reply.Data.Content), nil
+}
+
+func (n *netconfConn) writeMessage(msg []byte) error {
+	var err error
+	if n.chunked {
+		_, err = fmt.Fprintf(n.w, "\n#%d\n%s\n##\n", len(msg), msg)
+	} else {
+		_, err = fmt.Fprintf(n.w, "%s%s", msg, netconfEndOfMessage)
+	}
+	return err
+}
+
+func (n *netconfConn) readMessage() ([]byte, error) {
+	if n.chunked {
+		return n.readChunkedMessage()
+	}
+	return n.readEndOfMessageDelimited()
+}
+
+func (n *netconfConn) readEndOfMessageDelimited() ([]byte, error) {
+	var msg []byte
+	for {
+		b, err := n.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		msg = append(msg, b)
+		if b == netconfEndOfMessage[len(netconfEndOfMessage)-1] && bytes.HasSuffix(msg, []byte(netconfEndOfMessage)) {
+			return msg[:len(msg)-len(netconfEndOfMessage)], nil
+		}
+	}
+}
+
+func (n *netconfConn) readChunkedMessage() ([]byte, error) {
+	var msg bytes.Buffer
+	for {
+		// every chunk starts with "\n#<size>\n", and the message ends with "\n##\n" (RFC 6242 section 4.2)
+		header, err := n.r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		if header != "\n" {
+			return nil, fmt.Errorf("invalid chunk header %q", header)
+		}
+		header, err = n.r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(header, "#") {
+			return nil, fmt.Errorf("invalid chunk header %q", header)
+		}
+		sizeString := strings.TrimSuffix(header[1:], "\n")
+		if sizeString == "#" {
+			return msg.Bytes(), nil
+		}
+		size, err := strconv.ParseUint(sizeString, 10, 32)
+		if err != nil || size == 0 {
+			return nil, fmt.Errorf("invalid chunk size %q", sizeString)
+		}
+		if _, err := io.CopyN(&msg, n.r, int64(size)); err != nil {
+			return nil, err
+		}
+	}
+}
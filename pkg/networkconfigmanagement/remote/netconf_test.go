@@ -0,0 +1,259 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+//go:build test && ncm
+
+package remote
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/xml"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	ncmconfig "github.com/DataDog/datadog-agent/pkg/networkconfigmanagement/config"
+	"github.com/DataDog/datadog-agent/pkg/networkconfigmanagement/profile"
+)
+
+const netconfRunningConfig = `<configuration xmlns="http://xml.juniper.net/xnm/1.1/xnm">
+  <system>
+    <host-name>qa-device</host-name>
+  </system>
+</configuration>`
+
+// netconfTestServer is an in-process stand-in for a device serving NETCONF over SSH
+type netconfTestServer struct {
+	listener net.Listener
+	chunked  bool
+	// operations holds the operations of the RPCs received by the server
+	operations []string
+}
+
+func newNETCONFTestServer(t *testing.T, chunked bool) *netconfTestServer {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(priv)
+	require.NoError(t, err)
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if conn.User() == "admin" && string(password) == "password" {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("invalid credentials")
+		},
+	}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	server := &netconfTestServer{listener: listener, chunked: chunked}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.handleConn(conn, config)
+		}
+	}()
+	return server
+}
+
+func (s *netconfTestServer) port() string {
+	return strconv.Itoa(s.listener.Addr().(*net.TCPAddr).Port)
+}
+
+func (s *netconfTestServer) handleConn(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range requests {
+				// the subsystem name is sent as an SSH string, prefixed by its length
+				isNETCONF := req.Type == "subsystem" && len(req.Payload) > 4 && string(req.Payload[4:]) == "netconf"
+				_ = req.Reply(isNETCONF, nil)
+				if isNETCONF {
+					go s.serve(channel)
+				}
+			}
+		}()
+	}
+}
+
+func (s *netconfTestServer) serve(channel ssh.Channel) {
+	defer channel.Close()
+	conn := newNETCONFConn(channel, channel)
+
+	capabilities := []string{netconfBase10}
+	if s.chunked {
+		capabilities = append(capabilities, netconfBase11)
+	}
+	hello, _ := xml.Marshal(&netconfHello{Capabilities: capabilities, SessionID: 1})
+	if err := conn.writeMessage(hello); err != nil {
+		return
+	}
+	if _, err := conn.readMessage(); err != nil {
+		return
+	}
+	conn.chunked = s.chunked
+
+	for {
+		msg, err := conn.readMessage()
+		if err != nil {
+			return
+		}
+		var rpc struct {
+			MessageID string `xml:"message-id,attr"`
+		}
+		if err := xml.Unmarshal(msg, &rpc); err != nil {
+			return
+		}
+		// the operation is the content of the rpc element
+		start := bytes.Index(msg, []byte("<rpc "))
+		operation := string(msg[start+bytes.IndexByte(msg[start:], '>')+1 : bytes.LastIndex(msg, []byte("</rpc>"))])
+		s.operations = append(s.operations, operation)
+
+		var reply string
+		switch {
+		case strings.Contains(operation, "<running/>"):
+			reply = "<data>" + netconfRunningConfig + "</data>"
+		case strings.Contains(operation, "<close-session/>"):
+			reply = "<ok/>"
+		default:
+			reply = `<rpc-error><error-type>protocol</error-type><error-tag>operation-not-supported</error-tag><error-severity>error</error-severity><error-message>startup datastore not supported</error-message></rpc-error>`
+		}
+		err = conn.writeMessage([]byte(fmt.Sprintf(`<rpc-reply message-id="%s" xmlns="%s">%s</rpc-reply>`, rpc.MessageID, netconfBaseNamespace, reply)))
+		if err != nil || strings.Contains(operation, "<close-session/>") {
+			return
+		}
+	}
+}
+
+func newNETCONFTestProfile() *profile.NCMProfile {
+	return &profile.NCMProfile{
+		BaseProfile: profile.BaseProfile{Name: "netconf"},
+		Commands: map[profile.CommandType]*profile.Commands{
+			profile.Running: {
+				CommandType: profile.Running,
+				Values:      []string{"<get-config><source><running/></source></get-config>"},
+			},
+			profile.Startup: {
+				CommandType: profile.Startup,
+				Values:      []string{"<get-config><source><startup/></source></get-config>"},
+			},
+		},
+	}
+}
+
+func TestNETCONFClient_RetrieveConfig(t *testing.T) {
+	for _, chunked := range []bool{false, true} {
+		t.Run(fmt.Sprintf("chunked=%t", chunked), func(t *testing.T) {
+			server := newNETCONFTestServer(t, chunked)
+			device := &ncmconfig.DeviceInstance{
+				IPAddress: "127.0.0.1",
+				Transport: ncmconfig.TransportNETCONF,
+				Auth: ncmconfig.AuthCredentials{
+					Username:         "admin",
+					Password:         "password",
+					Port:             server.port(),
+					Protocol:         "tcp",
+					SSHHostKeyPolicy: ncmconfig.HostKeyPolicyTOFU,
+				},
+			}
+
+			client, err := NewClient(device, t.TempDir())
+			require.NoError(t, err)
+			require.IsType(t, &NETCONFClient{}, client)
+			client.SetProfile(newNETCONFTestProfile())
+
+			require.NoError(t, client.Connect())
+			netconfClient := client.(*NETCONFClient)
+			assert.Contains(t, netconfClient.Capabilities(), netconfBase10)
+			assert.Equal(t, chunked, netconfClient.conn.chunked)
+
+			config, err := client.RetrieveRunningConfig()
+			require.NoError(t, err)
+			assert.Equal(t, netconfRunningConfig+"\n", string(config))
+
+			_, err = client.RetrieveStartupConfig()
+			var rpcErr *NETCONFRPCError
+			require.ErrorAs(t, err, &rpcErr)
+			assert.Equal(t, "operation-not-supported", rpcErr.Tag)
+			assert.Contains(t, err.Error(), "startup datastore not supported")
+
+			require.NoError(t, client.Close())
+			assert.Equal(t, []string{
+				"<get-config><source><running/></source></get-config>",
+				"<get-config><source><startup/></source></get-config>",
+				"<close-session/>",
+			}, server.operations)
+		})
+	}
+}
+
+func TestNETCONFClient_AuthenticationFailure(t *testing.T) {
+	server := newNETCONFTestServer(t, false)
+	device := &ncmconfig.DeviceInstance{
+		IPAddress: "127.0.0.1",
+		Transport: ncmconfig.TransportNETCONF,
+		Auth: ncmconfig.AuthCredentials{
+			Username:         "admin",
+			Password:         "wrong",
+			Port:             server.port(),
+			Protocol:         "tcp",
+			SSHHostKeyPolicy: ncmconfig.HostKeyPolicyInsecure,
+		},
+	}
+	client, err := NewClient(device, t.TempDir())
+	require.NoError(t, err)
+	assert.ErrorContains(t, client.Connect(), "unable to authenticate")
+
+	_, err = client.NewSession()
+	assert.Error(t, err)
+}
+
+func TestNETCONFConn_ChunkedFraming(t *testing.T) {
+	var buf bytes.Buffer
+	conn := newNETCONFConn(&buf, &buf)
+	conn.chunked = true
+
+	// messages may be split in several chunks
+	buf.WriteString("\n#6\n<rpc-r\n#11\neply/>\n</a>\n##\n")
+	msg, err := conn.readMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "<rpc-reply/>\n</a>", string(msg))
+
+	require.NoError(t, conn.writeMessage([]byte("<rpc/>")))
+	assert.Equal(t, "\n#6\n<rpc/>\n##\n", buf.String())
+	msg, err = conn.readMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "<rpc/>", string(msg))
+
+	buf.WriteString("\n#0\n\n##\n")
+	_, err = conn.readMessage()
+	assert.ErrorContains(t, err, "invalid chunk size")
+}
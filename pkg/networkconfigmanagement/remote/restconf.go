@@ -0,0 +1,228 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+//go:build ncm
+
+package remote
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	ncmconfig "github.com/DataDog/datadog-agent/pkg/networkconfigmanagement/config"
+	"github.com/DataDog/datadog-agent/pkg/networkconfigmanagement/profile"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	restconfMediaTypeJSON = "application/yang-data+json"
+	restconfMediaTypeXML  = "application/yang-data+xml"
+	// restconfDefaultRoot is the RESTCONF API root used when the device does not advertise it
+	restconfDefaultRoot = "/restconf"
+	// restconfMaxResponseSize bounds the size of the configurations read from the devices
+	restconfMaxResponseSize = 64 * 1024 * 1024
+)
+
+// RESTCONFClient implements Client using RESTCONF (RFC 8040), the commands of the profile being the paths of the
+// resources to retrieve, relative to the RESTCONF API root of the device (e.g. data?content=config)
+type RESTCONFClient struct {
+	httpClient *http.Client
+	device     *ncmconfig.DeviceInstance
+	prof       *profile.NCMProfile
+	baseURL    string
+	root       string
+}
+
+// RESTCONFSession implements Session by sending GET requests to the RESTCONF API of the device
+type RESTCONFSession struct {
+	client *RESTCONFClient
+}
+
+type restconfHostMeta struct {
+	XMLName xml.Name `xml:"XRD"`
+	Links   []struct {
+		Rel  string `xml:"rel,attr"`
+		Href string `xml:"href,attr"`
+	} `xml:"Link"`
+}
+
+// RESTCONFStatusError is returned when the device replies to a request with an error status
+type RESTCONFStatusError struct {
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *RESTCONFStatusError) Error() string {
+	return fmt.Sprintf("GET %s returned %d: %s", e.URL, e.StatusCode, e.Body)
+}
+
+// NewRESTCONFClient creates a new RESTCONF client for the given device configuration
+func NewRESTCONFClient(device *ncmconfig.DeviceInstance, timeout time.Duration) (*RESTCONFClient, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: device.RESTCONF.TLSInsecureSkipVerify,
+	}
+	if device.RESTCONF.TLSCAFile != "" {
+		caCert, err := os.ReadFile(device.RESTCONF.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the CA file of device %s: %w", device.IPAddress, err)
+		}
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no valid certificate found in the CA file %s of device %s", device.RESTCONF.TLSCAFile, device.IPAddress)
+		}
+		tlsConfig.RootCAs = certPool
+	}
+
+	return &RESTCONFClient{
+		httpClient: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				TLSClientConfig:     tlsConfig,
+				TLSHandshakeTimeout: timeout,
+			},
+		},
+		device:  device,
+		baseURL: "https://" + net.JoinHostPort(device.IPAddress, device.Auth.Port),
+		root:    device.RESTCONF.RootPath,
+	}, nil
+}
+
+// SetProfile sets the NCM profile for the device for the client to know which resources to retrieve
+func (c *RESTCONFClient) SetProfile(profile *profile.NCMProfile) {
+	c.prof = profile
+}
+
+// Connect discovers the RESTCONF API root of the device (RFC 8040 section 3.1), unless it is configured
+func (c *RESTCONFClient) Connect() error {
+	if c.root != "" {
+		return nil
+	}
+	body, err := c.get(c.baseURL+"/.well-known/host-meta", "application/xrd+xml")
+	var statusErr *RESTCONFStatusError
+	if err != nil && !errors.As(err, &statusErr) {
+		return fmt.Errorf("failed to connect to %s: %w", c.device.IPAddress, err)
+	}
+	var hostMeta restconfHostMeta
+	if err == nil && xml.Unmarshal(body, &hostMeta) == nil {
+		for _, link := range hostMeta.Links {
+			if link.Rel == "restconf" && link.Href != "" {
+				c.root = link.Href
+				return nil
+			}
+		}
+	}
+	log.Debugf("no restconf root advertised by %s, using %s", c.device.IPAddress, restconfDefaultRoot)
+	c.root = restconfDefaultRoot
+	return nil
+}
+
+// NewSession returns a session sending requests to the RESTCONF API of the device
+func (c *RESTCONFClient) NewSession() (Session, error) {
+	if c.root == "" {
+		return nil, fmt.Errorf("restconf client not connected")
+	}
+	return &RESTCONFSession{client: c}, nil
+}
+
+// RetrieveRunningConfig retrieves the running configuration of the device from the resources of the profile
+func (c *RESTCONFClient) RetrieveRunningConfig() ([]byte, error) {
+	return c.retrieveConfig(profile.Running)
+}
+
+// RetrieveStartupConfig retrieves the startup configuration of the device from the resources of the profile
+func (c *RESTCONFClient) RetrieveStartupConfig() ([]byte, error) {
+	return c.retrieveConfig(profile.Startup)
+}
+
+func (c *RESTCONFClient) retrieveConfig(ct profile.CommandType) ([]byte, error) {
+	paths, err := c.prof.GetCommandValues(ct)
+	if err != nil {
+		return []byte{}, err
+	}
+	session, err := c.NewSession()
+	if err != nil {
+		return []byte{}, err
+	}
+	defer session.Close()
+
+	var config []byte
+	for _, path := range paths {
+		log.Debugf("Retrieving RESTCONF resource: %s", path)
+		output, err := session.CombinedOutput(path)
+		if err != nil {
+			return []byte{}, fmt.Errorf("resource %s retrieval failed: %w", path, err)
+		}
+		config = append(config, output...)
+		config = append(config, '\n')
+	}
+	if err := c.prof.ValidateOutput(ct, config); err != nil {
+		return []byte{}, err
+	}
+	return config, nil
+}
+
+// Close closes the idle connections to the device
+func (c *RESTCONFClient) Close() error {
+	c.httpClient.CloseIdleConnections()
+	return nil
+}
+
+// CombinedOutput retrieves the resource at the given path, relative to the RESTCONF API root, and returns it
+// indented for the configurations to be compared line by line
+func (s *RESTCONFSession) CombinedOutput(path string) ([]byte, error) {
+	url := s.client.baseURL + strings.TrimSuffix(s.client.root, "/") + "/" + strings.TrimPrefix(path, "/")
+	body, err := s.client.get(url, restconfMediaTypeJSON+", "+restconfMediaTypeXML+";q=0.5")
+	if err != nil {
+		return nil, err
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, body, "", "  "); err != nil {
+		// the device did not reply with JSON, keep the resource as is
+		return body, nil
+	}
+	return indented.Bytes(), nil
+}
+
+// Close is a no-op, the requests are sent with the HTTP client of the RESTCONF client
+func (s *RESTCONFSession) Close() error {
+	return nil
+}
+
+func (c *RESTCONFClient) get(url string, accept string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	req.SetBasicAuth(c.device.Auth.Username, c.device.Auth.Password)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, restconfMaxResponseSize))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &RESTCONFStatusError{URL: url, StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+	}
+	return body, nil
+}
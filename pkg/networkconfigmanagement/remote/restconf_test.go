@@ -0,0 +1,168 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+//go:build test && ncm
+
+package remote
+
+import (
+	"encoding/pem"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ncmconfig "github.com/DataDog/datadog-agent/pkg/networkconfigmanagement/config"
+	"github.com/DataDog/datadog-agent/pkg/networkconfigmanagement/profile"
+)
+
+func newRESTCONFTestServer(t *testing.T, advertiseRoot bool) (*httptest.Server, *ncmconfig.DeviceInstance) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/host-meta", func(w http.ResponseWriter, _ *http.Request) {
+		if !advertiseRoot {
+			http.NotFound(w, nil)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xrd+xml")
+		_, _ = w.Write([]byte(`<XRD xmlns="http://docs.oasis-open.org/ns/xri/xrd-1.0"><Link rel="restconf" href="/top/restconf"/></XRD>`))
+	})
+	handleData := func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "admin" || password != "password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("content") != "config" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", restconfMediaTypeJSON)
+		_, _ = w.Write([]byte(`{"ietf-system:system":{"hostname":"qa-device","authentication":{"user":[{"name":"admin","password":"$6$secret"}]}}}`))
+	}
+	mux.HandleFunc("/top/restconf/data", handleData)
+	mux.HandleFunc("/restconf/data", handleData)
+	mux.HandleFunc("/restconf/ds/ietf-datastores:startup", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"ietf-restconf:errors":{"error":[{"error-tag":"invalid-value"}]}}`))
+	})
+
+	server := httptest.NewTLSServer(mux)
+	t.Cleanup(server.Close)
+
+	// trust the certificate of the server
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600))
+
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	device := &ncmconfig.DeviceInstance{
+		IPAddress: host,
+		Transport: ncmconfig.TransportRESTCONF,
+		Auth: ncmconfig.AuthCredentials{
+			Username: "admin",
+			Password: "password",
+			Port:     port,
+		},
+		RESTCONF: ncmconfig.RESTCONFConfig{
+			TLSCAFile: caFile,
+		},
+	}
+	return server, device
+}
+
+func newRESTCONFTestProfile() *profile.NCMProfile {
+	return &profile.NCMProfile{
+		BaseProfile: profile.BaseProfile{Name: "restconf"},
+		Commands: map[profile.CommandType]*profile.Commands{
+			profile.Running: {
+				CommandType: profile.Running,
+				Values:      []string{"data?content=config"},
+			},
+			profile.Startup: {
+				CommandType: profile.Startup,
+				Values:      []string{"ds/ietf-datastores:startup"},
+			},
+		},
+	}
+}
+
+func TestRESTCONFClient_RetrieveConfig(t *testing.T) {
+	tests := []struct {
+		name          string
+		advertiseRoot bool
+		expectedRoot  string
+	}{
+		{name: "advertised root", advertiseRoot: true, expectedRoot: "/top/restconf"},
+		{name: "default root", advertiseRoot: false, expectedRoot: "/restconf"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, device := newRESTCONFTestServer(t, tt.advertiseRoot)
+
+			client, err := NewClient(device, t.TempDir())
+			require.NoError(t, err)
+			require.IsType(t, &RESTCONFClient{}, client)
+			client.SetProfile(newRESTCONFTestProfile())
+
+			require.NoError(t, client.Connect())
+			assert.Equal(t, tt.expectedRoot, client.(*RESTCONFClient).root)
+
+			// the configuration is indented to be compared line by line
+			config, err := client.RetrieveRunningConfig()
+			require.NoError(t, err)
+			assert.Equal(t, `{
+  "ietf-system:system": {
+    "hostname": "qa-device",
+    "authentication": {
+      "user": [
+        {
+          "name": "admin",
+          "password": "$6$secret"
+        }
+      ]
+    }
+  }
+}
+`, string(config))
+			require.NoError(t, client.Close())
+		})
+	}
+}
+
+func TestRESTCONFClient_ErrorStatus(t *testing.T) {
+	_, device := newRESTCONFTestServer(t, false)
+
+	client, err := NewClient(device, t.TempDir())
+	require.NoError(t, err)
+	client.SetProfile(newRESTCONFTestProfile())
+	require.NoError(t, client.Connect())
+
+	_, err = client.RetrieveStartupConfig()
+	var statusErr *RESTCONFStatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+
+	device.Auth.Password = "wrong"
+	_, err = client.RetrieveRunningConfig()
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusUnauthorized, statusErr.StatusCode)
+}
+
+func TestRESTCONFClient_UntrustedCertificate(t *testing.T) {
+	_, device := newRESTCONFTestServer(t, true)
+	device.RESTCONF.TLSCAFile = ""
+
+	client, err := NewClient(device, t.TempDir())
+	require.NoError(t, err)
+	assert.ErrorContains(t, client.Connect(), "certificate")
+
+	device.RESTCONF.TLSCAFile = filepath.Join(t.TempDir(), "missing.pem")
+	_, err = NewClient(device, t.TempDir())
+	assert.ErrorContains(t, err, "unable to read the CA file")
+}
@@ -24,6 +24,7 @@ import (
 type SSHClient struct {
 	client *ssh.Client
 	device *ncmconfig.DeviceInstance // Device configuration for authentication
+	config *SSHClientConfig
 	prof   *profile.NCMProfile
 }
 
@@ -35,16 +36,19 @@ type SSHSession struct {
 // SSHClientConfig holds configuration for SSH client
 type SSHClientConfig struct {
 	Timeout           time.Duration
-	HostKeyCallback   ssh.HostKeyCallback
-	Ciphers           []string // configurable ciphers
-	KeyExchanges      []string // configurable key exchanges
-	HostKeyAlgorithms []string // configurable host key algorithms
+	HostKeyCallback   ssh.HostKeyCallback     // if not set, a callback is built from the host key policy on every connection
+	HostKeyPolicy     ncmconfig.HostKeyPolicy // policy used to verify the host key of the device
+	KnownHostsFile    string                  // known_hosts file the host keys are verified against (and pinned to for tofu)
+	Ciphers           []string                // configurable ciphers
+	KeyExchanges      []string                // configurable key exchanges
+	HostKeyAlgorithms []string                // configurable host key algorithms
 }
 
-// NewSSHClient creates a new SSH client for the given device configuration
-func NewSSHClient(device *ncmconfig.DeviceInstance) *SSHClient {
+// NewSSHClient creates a new SSH client for the given device configuration and SSH client configuration
+func NewSSHClient(device *ncmconfig.DeviceInstance, config *SSHClientConfig) *SSHClient {
 	return &SSHClient{
 		device: device,
+		config: config,
 	}
 }
 
@@ -58,7 +62,7 @@ func (c *SSHClient) redial() error {
 	if c.client != nil {
 		_ = c.client.Close()
 	}
-	newClient, err := connectToHost(c.device.IPAddress, c.device.Auth, nil)
+	newClient, err := connectToHost(c.device.IPAddress, c.device.Auth, c.config)
 	if err != nil {
 		return err
 	}
@@ -68,7 +72,7 @@ func (c *SSHClient) redial() error {
 
 // Connect establishes a new SSH connection to the specified IP address using the provided authentication credentials
 func (c *SSHClient) Connect() error {
-	client, err := connectToHost(c.device.IPAddress, c.device.Auth, nil)
+	client, err := connectToHost(c.device.IPAddress, c.device.Auth, c.config)
 	if err != nil {
 		return err
 	}
@@ -76,15 +80,31 @@ func (c *SSHClient) Connect() error {
 	return nil
 }
 
-// DefaultSSHClientConfig returns a default SSH client configuration
-// TODO: To accept custom SSH configurations, we can pass the config as an argument to connectToHost
-func DefaultSSHClientConfig() *SSHClientConfig {
+// DefaultSSHClientConfig returns a default SSH client configuration, that trusts the host keys on first use
+// and pins them in the given known_hosts file
+func DefaultSSHClientConfig(knownHostsFile string) *SSHClientConfig {
 	return &SSHClientConfig{
-		Timeout:         30 * time.Second,
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(), // ⚠️ TODO: Use proper host key validation in production
+		Timeout:        30 * time.Second,
+		HostKeyPolicy:  ncmconfig.HostKeyPolicyTOFU,
+		KnownHostsFile: knownHostsFile,
 	}
 }
 
+// NewSSHClientConfig returns the SSH client configuration for the device, verifying its host key with the
+// policy set for the device. Unless a known_hosts file is set for the device, the host keys are pinned in
+// the store of the agent under the given run path.
+func NewSSHClientConfig(device *ncmconfig.DeviceInstance, runPath string) *SSHClientConfig {
+	knownHostsFile := device.Auth.SSHKnownHostsFile
+	if knownHostsFile == "" {
+		knownHostsFile = KnownHostsStorePath(runPath)
+	}
+	config := DefaultSSHClientConfig(knownHostsFile)
+	if device.Auth.SSHHostKeyPolicy != "" {
+		config.HostKeyPolicy = device.Auth.SSHHostKeyPolicy
+	}
+	return config
+}
+
 // NewSession creates a new SSH session for the client (needed for every command execution)
 func (c *SSHClient) NewSession() (Session, error) {
 	sess, err := c.client.NewSession()
@@ -193,13 +213,22 @@ func (s *SSHSession) Close() error {
 // connectToHost establishes an SSH connection to the specified IP address using the provided authentication credentials
 func connectToHost(ipAddress string, auth ncmconfig.AuthCredentials, config *SSHClientConfig) (*ssh.Client, error) {
 	if config == nil {
-		config = DefaultSSHClientConfig()
+		return nil, fmt.Errorf("no SSH configuration set to connect to %s", ipAddress)
+	}
+
+	hostKeyCallback := config.HostKeyCallback
+	if hostKeyCallback == nil {
+		var err error
+		hostKeyCallback, err = NewHostKeyCallback(config.HostKeyPolicy, config.KnownHostsFile)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	sshConfig := &ssh.ClientConfig{
 		User:            auth.Username,
 		Auth:            []ssh.AuthMethod{ssh.Password(auth.Password)},
-		HostKeyCallback: config.HostKeyCallback,
+		HostKeyCallback: hostKeyCallback,
 		Timeout:         config.Timeout,
 		Config: ssh.Config{
 			Ciphers:      auth.SSHCiphers,
@@ -9,6 +9,7 @@
 package report
 
 import (
+	"github.com/DataDog/datadog-agent/pkg/networkconfigmanagement/diff"
 	"github.com/DataDog/datadog-agent/pkg/networkconfigmanagement/profile"
 	"github.com/DataDog/datadog-agent/pkg/networkdevice/integrations"
 )
@@ -28,6 +29,7 @@ type NCMPayload struct {
 	Namespace        string                   `json:"namespace"`
 	Integration      integrations.Integration `json:"integration"`
 	Configs          []NetworkDeviceConfig    `json:"configs"`
+	Diffs            []NetworkDeviceDiff      `json:"diffs,omitempty"`
 	CollectTimestamp int64                    `json:"collect_timestamp"`
 }
 
@@ -41,6 +43,31 @@ type NetworkDeviceConfig struct {
 	Content    []byte   `json:"content"`
 }
 
+// DiffType defines the type of difference between two network device configurations
+type DiffType string
+
+const (
+	// RunningChange represents a change of the running configuration since it was last retrieved
+	RunningChange DiffType = "running_change"
+	// UnsavedChanges represents differences between the running configuration and the startup configuration
+	UnsavedChanges DiffType = "unsaved_changes"
+)
+
+// NetworkDeviceDiff contains the differences between two configurations of a single device, ignoring their volatile lines
+type NetworkDeviceDiff struct {
+	DeviceID    string      `json:"device_id"`
+	DeviceIP    string      `json:"device_ip"`
+	DiffType    string      `json:"diff_type"`
+	FromHash    string      `json:"from_hash"`
+	ToHash      string      `json:"to_hash"`
+	Timestamp   int64       `json:"timestamp"`
+	Tags        []string    `json:"tags"`
+	Added       int         `json:"added_lines"`
+	Removed     int         `json:"removed_lines"`
+	Hunks       []diff.Hunk `json:"hunks"`
+	UnifiedDiff string      `json:"unified_diff"`
+}
+
 // ToNCMPayload converts the given parameters into a NCMPayload (sent to event platform / backend).
 func ToNCMPayload(namespace string, integration integrations.Integration, configs []NetworkDeviceConfig, timestamp int64) NCMPayload {
 	return NCMPayload{
@@ -68,3 +95,34 @@ func ToNetworkDeviceConfig(deviceID, deviceIP string, configType ConfigType, ext
 		Content:    content,
 	}
 }
+
+// ToNetworkDeviceDiff converts the given parameters into a NetworkDeviceDiff, representing the differences between two configurations of a single device.
+func ToNetworkDeviceDiff(deviceID, deviceIP string, diffType DiffType, fromHash, toHash string, d *diff.Diff, tags []string, timestamp int64) NetworkDeviceDiff {
+	var fromName, toName string
+	switch diffType {
+	case UnsavedChanges:
+		fromName, toName = string(STARTUP), string(RUNNING)
+	default:
+		fromName, toName = string(RUNNING)+"@"+shortHash(fromHash), string(RUNNING)+"@"+shortHash(toHash)
+	}
+	return NetworkDeviceDiff{
+		DeviceID:    deviceID,
+		DeviceIP:    deviceIP,
+		DiffType:    string(diffType),
+		FromHash:    fromHash,
+		ToHash:      toHash,
+		Timestamp:   timestamp,
+		Tags:        tags,
+		Added:       d.Added,
+		Removed:     d.Removed,
+		Hunks:       d.Hunks,
+		UnifiedDiff: d.Format(fromName, toName),
+	}
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
@@ -12,6 +12,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/networkconfigmanagement/diff"
 	"github.com/DataDog/datadog-agent/pkg/networkconfigmanagement/profile"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -115,3 +116,20 @@ func TestNetworkDevicesConfigPayload_EmptyConfigs(t *testing.T) {
 	require.NoError(t, err)
 	assert.Contains(t, string(jsonData), "\"configs\":[]")
 }
+
+func TestNetworkDeviceDiff_Creation(t *testing.T) {
+	d := diff.Unified([]byte("hostname r1\nntp server 10.0.0.1\n"), []byte("hostname r1\nntp server 10.0.0.2\n"), diff.DefaultContextLines)
+
+	deviceDiff := ToNetworkDeviceDiff("default:10.0.0.1", "10.0.0.1", UnsavedChanges, "aaa", "bbb", d, []string{"device_ip:10.0.0.1"}, 1754043600)
+
+	assert.Equal(t, "unsaved_changes", deviceDiff.DiffType)
+	assert.Equal(t, "aaa", deviceDiff.FromHash)
+	assert.Equal(t, "bbb", deviceDiff.ToHash)
+	assert.Equal(t, 1, deviceDiff.Added)
+	assert.Equal(t, 1, deviceDiff.Removed)
+	assert.Len(t, deviceDiff.Hunks, 1)
+	assert.Equal(t, "--- startup\n+++ running\n@@ -1,2 +1,2 @@\n hostname r1\n-ntp server 10.0.0.1\n+ntp server 10.0.0.2\n", deviceDiff.UnifiedDiff)
+
+	deviceDiff = ToNetworkDeviceDiff("default:10.0.0.1", "10.0.0.1", RunningChange, "0123456789abcdef", "fedcba9876543210", d, nil, 1754043600)
+	assert.Contains(t, deviceDiff.UnifiedDiff, "--- running@0123456789ab\n+++ running@fedcba987654\n")
+}
@@ -11,11 +11,20 @@ package sender
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
+
 	"github.com/DataDog/datadog-agent/comp/forwarder/eventplatform"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
+	"github.com/DataDog/datadog-agent/pkg/metrics/event"
 	ncmreport "github.com/DataDog/datadog-agent/pkg/networkconfigmanagement/report"
 )
 
+// eventSourceType is the source type of the events sent for the network devices
+const eventSourceType = "network_config_management"
+
+// maxEventDiffLength bounds the size of the diffs embedded in the events, the full diffs being sent in the NCM payload
+const maxEventDiffLength = 3000
+
 // NCMSender is a wrapper around the sender.Sender to send network device configuration data
 type NCMSender struct {
 	Sender    sender.Sender
@@ -50,6 +59,61 @@ func (s *NCMSender) SendNCMConfig(payload ncmreport.NCMPayload) error {
 	return nil
 }
 
+// SendHostKeyMismatchEvent sends an event reporting that a network device presented an SSH host key that
+// differs from the one pinned for it
+func (s *NCMSender) SendHostKeyMismatchEvent(deviceID string, deviceIP string, presented string, expected []string, ts int64) {
+	s.Sender.Event(event.Event{
+		Title:          fmt.Sprintf("SSH host key changed for network device %s", deviceIP),
+		Text:           fmt.Sprintf("%%%%%% \nThe network device `%s` presented the SSH host key `%s`, which does not match the pinned key(s) `%s`. Its configuration was not retrieved.\n\nIf the key was legitimately changed, re-pin it with `datadog-agent network-config-management host-keys repin %s`.\n %%%%%%", deviceIP, presented, strings.Join(expected, ", "), deviceIP),
+		Ts:             ts,
+		Priority:       event.PriorityNormal,
+		AlertType:      event.AlertTypeError,
+		SourceTypeName: eventSourceType,
+		EventType:      "host_key_mismatch",
+		AggregationKey: deviceID,
+		Tags: []string{
+			"device_namespace:" + s.namespace,
+			"device_id:" + deviceID,
+			"device_ip:" + deviceIP,
+		},
+	})
+}
+
+// SendConfigDiffEvent sends an event reporting a change of the configuration of a network device
+func (s *NCMSender) SendConfigDiffEvent(deviceDiff ncmreport.NetworkDeviceDiff) {
+	var title, description string
+	alertType := event.AlertTypeInfo
+	switch ncmreport.DiffType(deviceDiff.DiffType) {
+	case ncmreport.UnsavedChanges:
+		title = fmt.Sprintf("Unsaved configuration changes on network device %s", deviceDiff.DeviceIP)
+		description = "The running configuration of the network device `%s` differs from its startup configuration, these changes will be lost on reboot"
+		alertType = event.AlertTypeWarning
+	default:
+		title = fmt.Sprintf("Running configuration changed on network device %s", deviceDiff.DeviceIP)
+		description = "The running configuration of the network device `%s` changed"
+	}
+
+	unifiedDiff := deviceDiff.UnifiedDiff
+	if len(unifiedDiff) > maxEventDiffLength {
+		unifiedDiff = unifiedDiff[:maxEventDiffLength] + "\n[...]"
+	}
+
+	s.Sender.Event(event.Event{
+		Title:          title,
+		Text:           fmt.Sprintf("%%%%%% \n"+description+" (%d lines added, %d lines removed):\n```\n%s\n```\n %%%%%%", deviceDiff.DeviceIP, deviceDiff.Added, deviceDiff.Removed, strings.TrimSuffix(unifiedDiff, "\n")),
+		Ts:             deviceDiff.Timestamp,
+		Priority:       event.PriorityNormal,
+		AlertType:      alertType,
+		SourceTypeName: eventSourceType,
+		EventType:      deviceDiff.DiffType,
+		AggregationKey: deviceDiff.DeviceID,
+		Tags: append([]string{
+			"device_namespace:" + s.namespace,
+			"device_id:" + deviceDiff.DeviceID,
+		}, deviceDiff.Tags...),
+	})
+}
+
 // Commit commits the sender (important to ensure data is flushed/sent
 func (s *NCMSender) Commit() {
 	s.Sender.Commit()
@@ -19,6 +19,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/comp/forwarder/eventplatform"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics/event"
 	ncmreport "github.com/DataDog/datadog-agent/pkg/networkconfigmanagement/report"
 	"github.com/stretchr/testify/assert"
 )
@@ -80,3 +81,34 @@ func TestNCMSender_SendNCMConfig_Success(t *testing.T) {
 	mockSender.AssertEventPlatformEvent(t, compactEvent.Bytes(), eventplatform.EventTypeNetworkConfigManagement)
 	mockSender.AssertExpectations(t)
 }
+
+func TestNCMSender_SendConfigDiffEvent(t *testing.T) {
+	mockSender := &mocksender.MockSender{}
+	ncmSender := NewNCMSender(mockSender, "default")
+	mockSender.On("Event", mock.Anything).Return().Once()
+
+	ncmSender.SendConfigDiffEvent(ncmreport.NetworkDeviceDiff{
+		DeviceID:    "default:10.0.0.1",
+		DeviceIP:    "10.0.0.1",
+		DiffType:    string(ncmreport.UnsavedChanges),
+		Timestamp:   1754043600,
+		Tags:        []string{"device_ip:10.0.0.1"},
+		Added:       1,
+		Removed:     1,
+		UnifiedDiff: "--- startup\n+++ running\n@@ -1,1 +1,1 @@\n-ntp server 10.0.0.1\n+ntp server 10.0.0.2\n",
+	})
+
+	mockSender.AssertEvent(t, event.Event{
+		Title:          "Unsaved configuration changes on network device 10.0.0.1",
+		Ts:             1754043600,
+		Priority:       event.PriorityNormal,
+		AlertType:      event.AlertTypeWarning,
+		SourceTypeName: "network_config_management",
+		EventType:      "unsaved_changes",
+		AggregationKey: "default:10.0.0.1",
+		Tags:           []string{"device_namespace:default", "device_id:default:10.0.0.1", "device_ip:10.0.0.1"},
+	}, 0)
+	sentEvent := mockSender.Calls[0].Arguments.Get(0).(event.Event)
+	assert.Contains(t, sentEvent.Text, "(1 lines added, 1 lines removed)")
+	assert.Contains(t, sentEvent.Text, "+ntp server 10.0.0.2\n```")
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/outcaste-io/ristretto"
+	"github.com/outcaste-io/ristretto/z"
 )
 
 // measuredCache is a wrapper on top of *ristretto.Cache which additionally
@@ -84,3 +85,27 @@ func newMeasuredCache(opts cacheOptions) *measuredCache {
 	go c.statsLoop()
 	return &c
 }
+
+// cachedString is an obfuscated string stored in the cache. It has its own type so that it
+// can't be mistaken for a different kind of cached value in the unlikely event of a key collision.
+type cachedString string
+
+// cachedStringOverhead is the fixed cost of storing a cachedString in the cache.
+const cachedStringOverhead = 64
+
+// obfuscateString returns obfuscate(in), reusing the result of a previous call with the same input
+// and kind when the cache is enabled. kind identifies the obfuscation applied and its options.
+func (c *measuredCache) obfuscateString(kind, in string, obfuscate func(string) string) string {
+	if c.Cache == nil {
+		return obfuscate(in)
+	}
+	key := z.MemHashString(in) ^ z.MemHashString(kind)
+	if v, ok := c.Get(key); ok {
+		if s, ok := v.(cachedString); ok {
+			return string(s)
+		}
+	}
+	out := obfuscate(in)
+	c.Set(key, cachedString(out), int64(len(out))+cachedStringOverhead)
+	return out
+}
@@ -0,0 +1,31 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+// cqlDialect describes the Cassandra Query Language.
+// See https://cassandra.apache.org/doc/latest/cassandra/developing/cql/definitions.html
+var cqlDialect = statementDialect{
+	dollarStrings:   true,
+	slashComments:   true,
+	hexBlobs:        true,
+	uuids:           true,
+	namedMarkers:    true,
+	literalKeywords: map[string]bool{"TRUE": true, "FALSE": true, "NAN": true, "INFINITY": true},
+}
+
+// ObfuscateCQLString obfuscates the Cassandra CQL statement in. String, number, blob, UUID,
+// duration and boolean values are replaced with "?", as are list, set, map and tuple literals
+// made only of such values, and comments are removed. Bind markers are kept.
+func (o *Obfuscator) ObfuscateCQLString(in string) string {
+	replaceDigits := o.opts.CQL.ReplaceDigits
+	kind := "cql"
+	if replaceDigits {
+		kind = "cql:replace_digits"
+	}
+	return o.queryCache.obfuscateString(kind, in, func(in string) string {
+		return obfuscateStatement(in, &cqlDialect, replaceDigits)
+	})
+}
@@ -0,0 +1,75 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObfuscateCQL(t *testing.T) {
+	for _, tt := range []struct {
+		in, out       string
+		replaceDigits bool
+	}{
+		{
+			"SELECT * FROM ks.users WHERE id = 42",
+			"SELECT * FROM ks.users WHERE id = ?",
+			false,
+		},
+		{
+			"SELECT name FROM users WHERE email = 'jane@example.com' AND age > -3 ALLOW FILTERING;",
+			"SELECT name FROM users WHERE email = ? AND age > ? ALLOW FILTERING;",
+			false,
+		},
+		{
+			"INSERT INTO users (id, name, tags, prefs, active) VALUES (123e4567-e89b-12d3-a456-426614174000, 'O''Brien', {'a', 'b'}, {'theme': 'dark', 'lang': 'en'}, true) USING TTL 86400",
+			"INSERT INTO users ( id, name, tags, prefs, active ) VALUES ( ? ) USING TTL ?",
+			false,
+		},
+		{
+			"UPDATE users SET scores = scores + [1, 2, 3], avatar = 0xCAFE, delay = 1h30m WHERE id IN (1, 2, 3) IF EXISTS",
+			"UPDATE users SET scores = scores + ?, avatar = ?, delay = ? WHERE id IN ( ? ) IF EXISTS",
+			false,
+		},
+		{
+			"SELECT * FROM \"Users\" WHERE id = ? AND org = :org AND score = NaN -- trailing comment",
+			"SELECT * FROM \"Users\" WHERE id = ? AND org = :org AND score = ?",
+			false,
+		},
+		{
+			"/* app=web */ SELECT token(id), count(*) FROM events_2024 // by day\nWHERE day = '2024-01-01' LIMIT 10",
+			"SELECT token ( id ), count ( * ) FROM events_? WHERE day = ? LIMIT ?",
+			true,
+		},
+		{
+			"SELECT * FROM events_2024 WHERE body = $$some\n'text'$$ AND a = b - 1",
+			"SELECT * FROM events_2024 WHERE body = ? AND a = b - ?",
+			false,
+		},
+		{
+			"INSERT INTO t (k, v) VALUES (1, ['a', fn(2)]) ",
+			"INSERT INTO t ( k, v ) VALUES ( ?, [ ?, fn ( ? ) ] )",
+			false,
+		},
+		{
+			"SELECT * FROM t WHERE a = 'unterminated",
+			"SELECT * FROM t WHERE a = ?",
+			false,
+		},
+		{
+			"SELECT * FROM t WHERE a = 1) AND b = é",
+			"SELECT * FROM t WHERE a = ? ) AND b = é",
+			false,
+		},
+	} {
+		t.Run(tt.in, func(t *testing.T) {
+			o := NewObfuscator(Config{CQL: CQLConfig{Enabled: true, ReplaceDigits: tt.replaceDigits}})
+			assert.Equal(t, tt.out, o.ObfuscateCQLString(tt.in))
+		})
+	}
+}
@@ -0,0 +1,27 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+// partiQLDialect describes the PartiQL subset supported by DynamoDB.
+// See https://docs.aws.amazon.com/amazondynamodb/latest/developerguide/ql-reference.html
+var partiQLDialect = statementDialect{
+	bags:            true,
+	literalKeywords: map[string]bool{"TRUE": true, "FALSE": true},
+}
+
+// ObfuscateDynamoDBString obfuscates the DynamoDB PartiQL statement in. String, number and
+// boolean values are replaced with "?", as are list, map and set literals made only of such
+// values, and comments are removed. Parameter placeholders are kept.
+func (o *Obfuscator) ObfuscateDynamoDBString(in string) string {
+	replaceDigits := o.opts.DynamoDB.ReplaceDigits
+	kind := "partiql"
+	if replaceDigits {
+		kind = "partiql:replace_digits"
+	}
+	return o.queryCache.obfuscateString(kind, in, func(in string) string {
+		return obfuscateStatement(in, &partiQLDialect, replaceDigits)
+	})
+}
@@ -0,0 +1,60 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObfuscateDynamoDB(t *testing.T) {
+	for _, tt := range []struct {
+		in, out       string
+		replaceDigits bool
+	}{
+		{
+			`SELECT * FROM "Music" WHERE Artist = 'Acme Band' AND SongTitle = 'PartiQL Rocks'`,
+			`SELECT * FROM "Music" WHERE Artist = ? AND SongTitle = ?`,
+			false,
+		},
+		{
+			`INSERT INTO "Music" VALUE {'Artist': 'Acme Band', 'Awards': 1, 'Tags': <<'a', 'b'>>, 'Albums': [{'Year': 2020}]}`,
+			`INSERT INTO "Music" VALUE ?`,
+			false,
+		},
+		{
+			`UPDATE "Music" SET AwardsWon = 1 SET AwardDetail = {'Grammys': [2020, 2018]} WHERE Artist = ? AND SongTitle = ?`,
+			`UPDATE "Music" SET AwardsWon = ? SET AwardDetail = ? WHERE Artist = ? AND SongTitle = ?`,
+			false,
+		},
+		{
+			`SELECT OrderID, Total FROM "Orders_2023"."CustomerIndex" WHERE CustomerID IN ['c1', 'c2'] AND Total >= 10.5 AND Shipped = false`,
+			`SELECT OrderID, Total FROM "Orders_?"."CustomerIndex" WHERE CustomerID IN ? AND Total >= ? AND Shipped = ?`,
+			true,
+		},
+		{
+			"DELETE FROM \"Music\" -- remove the song\nWHERE Artist = 'Acme Band' AND Price < -1",
+			`DELETE FROM "Music" WHERE Artist = ? AND Price < ?`,
+			false,
+		},
+		{
+			`SELECT * FROM "Music" WHERE Attr IS MISSING OR Other = NULL`,
+			`SELECT * FROM "Music" WHERE Attr IS MISSING OR Other = NULL`,
+			false,
+		},
+		{
+			`UPDATE "Music" SET Tags = set_add(Tags, <<'x'>>) WHERE Artist = 'y'`,
+			`UPDATE "Music" SET Tags = set_add ( Tags, ? ) WHERE Artist = ?`,
+			false,
+		},
+	} {
+		t.Run(tt.in, func(t *testing.T) {
+			o := NewObfuscator(Config{DynamoDB: DynamoDBConfig{Enabled: true, ReplaceDigits: tt.replaceDigits}})
+			assert.Equal(t, tt.out, o.ObfuscateDynamoDBString(tt.in))
+		})
+	}
+}
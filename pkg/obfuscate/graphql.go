@@ -0,0 +1,164 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"strings"
+)
+
+// ObfuscateGraphQLString obfuscates the GraphQL document doc. String and number values,
+// including the default values of variables, are replaced with "?" and comments are removed.
+// Names, enum values, booleans and variables are kept, as well as the values of the arguments
+// and input object fields listed in GraphQLConfig.KeepValues.
+func (o *Obfuscator) ObfuscateGraphQLString(doc string) string {
+	return o.queryCache.obfuscateString("graphql", doc, o.obfuscateGraphQLString)
+}
+
+func (o *Obfuscator) obfuscateGraphQLString(doc string) string {
+	var out strings.Builder
+	out.Grow(len(doc))
+	// keep reports whether the value following the last argument or field name is kept, until
+	// this value ends: keepDepth is the bracket depth of that name and depth the current one.
+	keep := false
+	depth, keepDepth := 0, 0
+	// endToken resets keep once a token ends the kept value, or is not a value at all, like
+	// the field name following an alias or the type of a variable.
+	endToken := func() {
+		if depth <= keepDepth {
+			keep = false
+		}
+	}
+	writeValue := func(v string) {
+		if keep {
+			out.WriteString(v)
+		} else {
+			out.WriteByte('?')
+		}
+		endToken()
+	}
+	for i := 0; i < len(doc); {
+		c := doc[i]
+		switch {
+		case c == '#':
+			// comments run until the end of the line
+			for i < len(doc) && doc[i] != '\n' && doc[i] != '\r' {
+				i++
+			}
+		case c == '"':
+			end := graphQLStringEnd(doc, i)
+			writeValue(doc[i:end])
+			i = end
+		case isDigit(rune(c)) || (c == '-' && i+1 < len(doc) && isDigit(rune(doc[i+1]))):
+			end := graphQLNameEnd(doc, graphQLNumberEnd(doc, i))
+			writeValue(doc[i:end])
+			i = end
+		case isGraphQLNameStart(c):
+			end := graphQLNameEnd(doc, i)
+			if graphQLNextIsColon(doc, end) && (i == 0 || doc[i-1] != '$') {
+				keep = o.graphQLKeepValues[doc[i:end]]
+				keepDepth = depth
+			} else {
+				endToken()
+			}
+			out.WriteString(doc[i:end])
+			i = end
+		default:
+			switch c {
+			case '(', '[', '{':
+				depth++
+			case ')', ']', '}':
+				depth--
+				endToken()
+			}
+			out.WriteByte(c)
+			i++
+		}
+	}
+	return out.String()
+}
+
+// graphQLStringEnd returns the position following the string starting at position i of doc.
+// An unterminated block string runs until the end of doc and an unterminated string until
+// the end of the line.
+func graphQLStringEnd(doc string, i int) int {
+	if strings.HasPrefix(doc[i:], `"""`) {
+		for j := i + 3; j < len(doc); j++ {
+			switch {
+			case doc[j] == '\\' && strings.HasPrefix(doc[j+1:], `"""`):
+				j += 3
+			case strings.HasPrefix(doc[j:], `"""`):
+				return j + 3
+			}
+		}
+		return len(doc)
+	}
+	for j := i + 1; j < len(doc); j++ {
+		switch doc[j] {
+		case '\\':
+			j++
+		case '"':
+			return j + 1
+		case '\n', '\r':
+			return j
+		}
+	}
+	return len(doc)
+}
+
+// graphQLNumberEnd returns the position following the integer or float value starting at
+// position i of doc.
+func graphQLNumberEnd(doc string, i int) int {
+	if doc[i] == '-' {
+		i++
+	}
+	digits := func() {
+		for i < len(doc) && isDigit(rune(doc[i])) {
+			i++
+		}
+	}
+	digits()
+	if i+1 < len(doc) && doc[i] == '.' && isDigit(rune(doc[i+1])) {
+		i++
+		digits()
+	}
+	if i < len(doc) && (doc[i] == 'e' || doc[i] == 'E') {
+		j := i + 1
+		if j < len(doc) && (doc[j] == '+' || doc[j] == '-') {
+			j++
+		}
+		if j < len(doc) && isDigit(rune(doc[j])) {
+			i = j
+			digits()
+		}
+	}
+	return i
+}
+
+// graphQLNameEnd returns the position following the name characters starting at position i of doc.
+func graphQLNameEnd(doc string, i int) int {
+	for i < len(doc) && (isGraphQLNameStart(doc[i]) || isDigit(rune(doc[i]))) {
+		i++
+	}
+	return i
+}
+
+// graphQLNextIsColon reports whether the next token of doc after position i is a colon.
+func graphQLNextIsColon(doc string, i int) bool {
+	for ; i < len(doc); i++ {
+		switch doc[i] {
+		case ' ', '\t', '\n', '\r', ',':
+		case ':':
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+func isGraphQLNameStart(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
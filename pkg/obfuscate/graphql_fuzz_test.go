@@ -0,0 +1,61 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func FuzzObfuscateGraphQL(f *testing.F) {
+	// Add seed corpus with various documents, chosen from unit test examples.
+	docs := []string{
+		`query { user(id: 42) { name } }`,
+		`query GetUser($id: ID! = "u-1", $limit: Int = 10) { user(id: $id) { posts(first: $limit) { title } } }`,
+		`mutation { login(email: "jane@example.com", password: "hunter2", remember: true, role: ADMIN, score: -1.5e3) { token } }`,
+		"{\n  # fetch the user named John\n  users(filter: {name: \"John\", ids: [1, 2, 3]}) { id }\n}",
+		`{ search(text: """multi "line" \""" text""", first: 5) { id } }`,
+		`fragment f on User @include(if: $flag) { ...on Admin { level } }`,
+		`subscription { event(level: - ) { id } }`,
+		"{ user(name: \"unterminated\n) { id } }",
+		`{ user(bio: """unterminated) { id } }`,
+		`"\"`,
+		`"""\"""`,
+		"",
+		"#",
+		"-",
+		"1e",
+		"\xc3\x28",                 // invalid UTF-8
+		string([]byte{0x00, 0x22}), // null byte and quote
+	}
+	for _, doc := range docs {
+		f.Add(doc, false)
+		f.Add(doc, true)
+	}
+
+	o := NewObfuscator(Config{GraphQL: GraphQLConfig{Enabled: true}})
+	keep := NewObfuscator(Config{GraphQL: GraphQLConfig{Enabled: true, KeepValues: []string{"id", "first", "text"}}})
+
+	f.Fuzz(func(t *testing.T, doc string, keepValues bool) {
+		obfuscator := o
+		if keepValues {
+			obfuscator = keep
+		}
+		// No panics
+		result := obfuscator.ObfuscateGraphQLString(doc)
+
+		if len(result) > len(doc) {
+			t.Errorf("ObfuscateGraphQLString(%q): result %q is longer than the input", doc, result)
+		}
+		if utf8.ValidString(doc) && !utf8.ValidString(result) {
+			t.Errorf("ObfuscateGraphQLString(%q): result %q is not valid UTF-8", doc, result)
+		}
+		// Obfuscating an obfuscated document has no effect.
+		if again := o.ObfuscateGraphQLString(result); !keepValues && again != result {
+			t.Errorf("ObfuscateGraphQLString(%q) = %q, which obfuscates to %q", doc, result, again)
+		}
+	})
+}
@@ -0,0 +1,95 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObfuscateGraphQL(t *testing.T) {
+	for _, tt := range []struct {
+		in, out    string
+		keepValues []string
+	}{
+		{
+			`query { user(id: 42) { name } }`,
+			`query { user(id: ?) { name } }`,
+			nil,
+		},
+		{
+			`query GetUser($id: ID! = "u-1", $limit: Int = 10) { user(id: $id) { posts(first: $limit) { title } } }`,
+			`query GetUser($id: ID! = ?, $limit: Int = ?) { user(id: $id) { posts(first: $limit) { title } } }`,
+			nil,
+		},
+		{
+			`mutation { login(email: "jane@example.com", password: "hunter2", remember: true, role: ADMIN, score: -1.5e3) { token } }`,
+			`mutation { login(email: ?, password: ?, remember: true, role: ADMIN, score: ?) { token } }`,
+			nil,
+		},
+		{
+			"{\n  # fetch the user named John\n  users(filter: {name: \"John\", ids: [1, 2, 3]}) { id }\n}",
+			"{\n  \n  users(filter: {name: ?, ids: [?, ?, ?]}) { id }\n}",
+			nil,
+		},
+		{
+			`{ search(text: """multi "line" \""" text""", first: 5) { id } }`,
+			`{ search(text: ?, first: ?) { id } }`,
+			nil,
+		},
+		{
+			`{ search(text: "secret", first: 5, after: "abc") { id } alias: node(id: 3) { id } }`,
+			`{ search(text: ?, first: 5, after: "abc") { id } alias: node(id: ?) { id } }`,
+			[]string{"first", "after"},
+		},
+		{
+			`{ first: node(id: 3) { id } last: user { id } users(first: 10, ids: [1, 2], filter: {first: 4, name: "x"}, tag: "y") { id } }`,
+			`{ first: node(id: ?) { id } last: user { id } users(first: 10, ids: [1, 2], filter: {first: 4, name: ?}, tag: ?) { id } }`,
+			[]string{"first", "last", "ids"},
+		},
+		{
+			`query Users($first: Int = 10, $ids: [ID!] = ["a"], $role: Role = ADMIN, $name: String = "jane") { users(first: $first, name: "john") { id } }`,
+			`query Users($first: Int = ?, $ids: [ID!] = [?], $role: Role = ADMIN, $name: String = ?) { users(first: $first, name: ?) { id } }`,
+			[]string{"first", "ids", "role"},
+		},
+		{
+			`{ field1: node(id2: 12abc) { id } }`,
+			`{ field1: node(id2: ?) { id } }`,
+			nil,
+		},
+		{
+			"{ user(name: \"unterminated\n) { id } }",
+			"{ user(name: ?\n) { id } }",
+			nil,
+		},
+		{
+			`{ user(bio: """unterminated) { id } }`,
+			`{ user(bio: ?`,
+			nil,
+		},
+		{
+			`subscription { event(level: - ) { id } }`,
+			`subscription { event(level: - ) { id } }`,
+			nil,
+		},
+	} {
+		t.Run(tt.in, func(t *testing.T) {
+			o := NewObfuscator(Config{GraphQL: GraphQLConfig{Enabled: true, KeepValues: tt.keepValues}})
+			assert.Equal(t, tt.out, o.ObfuscateGraphQLString(tt.in))
+		})
+	}
+}
+
+func TestObfuscateGraphQLCache(t *testing.T) {
+	o := NewObfuscator(Config{Cache: CacheConfig{Enabled: true, MaxSize: 1000000}})
+	defer o.Stop()
+	in := `query { user(id: 42) { name } }`
+	assert.Equal(t, `query { user(id: ?) { name } }`, o.ObfuscateGraphQLString(in))
+	o.queryCache.Wait()
+	assert.Equal(t, `query { user(id: ?) { name } }`, o.ObfuscateGraphQLString(in))
+	assert.EqualValues(t, 1, o.queryCache.Metrics.Hits())
+}
@@ -36,6 +36,7 @@ type Obfuscator struct {
 	sqlExecPlan          *jsonObfuscator // nil if disabled
 	sqlExecPlanNormalize *jsonObfuscator // nil if disabled
 	ccObfuscator         *creditCard     // nil if disabled
+	graphQLKeepValues    map[string]bool // argument names whose values are kept by the GraphQL obfuscator
 	// sqlLiteralEscapes reports whether we should treat escape characters literally or as escape characters.
 	// Different SQL engines behave in different ways and the tokenizer needs to be generic.
 	sqlLiteralEscapes *atomic.Bool
@@ -130,6 +131,15 @@ type Config struct {
 	// Memcached holds the obfuscation settings for obfuscation of CC numbers in meta.
 	CreditCard CreditCardsConfig `mapstructure:"credit_cards"`
 
+	// GraphQL holds the obfuscation settings for GraphQL documents.
+	GraphQL GraphQLConfig `mapstructure:"graphql"`
+
+	// CQL holds the obfuscation settings for Cassandra CQL statements.
+	CQL CQLConfig `mapstructure:"cql"`
+
+	// DynamoDB holds the obfuscation settings for DynamoDB PartiQL statements.
+	DynamoDB DynamoDBConfig `mapstructure:"dynamodb"`
+
 	// Statsd specifies the statsd client to use for reporting metrics.
 	Statsd StatsClient
 
@@ -142,7 +152,7 @@ type Config struct {
 	// If unset, no logs will be outputted.
 	FullLogger FullLogger
 
-	// Cache enables the query cache for obfuscation for SQL and MongoDB queries.
+	// Cache enables the query cache for obfuscation for SQL, MongoDB, GraphQL, CQL and PartiQL queries.
 	Cache CacheConfig `mapstructure:"cache"`
 }
 
@@ -294,6 +304,36 @@ type MemcachedConfig struct {
 	KeepCommand bool `mapstructure:"keep_command"`
 }
 
+// GraphQLConfig holds the configuration settings for GraphQL obfuscation.
+type GraphQLConfig struct {
+	// Enabled specifies whether this feature should be enabled.
+	Enabled bool `mapstructure:"enabled"`
+
+	// KeepValues specifies a set of argument and input object field names
+	// for which the values will not be obfuscated.
+	KeepValues []string `mapstructure:"keep_values"`
+}
+
+// CQLConfig holds the configuration settings for Cassandra CQL obfuscation.
+type CQLConfig struct {
+	// Enabled specifies whether this feature should be enabled.
+	Enabled bool `mapstructure:"enabled"`
+
+	// ReplaceDigits specifies whether digits in keyspace, table and column
+	// names should be obfuscated.
+	ReplaceDigits bool `mapstructure:"replace_digits"`
+}
+
+// DynamoDBConfig holds the configuration settings for DynamoDB PartiQL obfuscation.
+type DynamoDBConfig struct {
+	// Enabled specifies whether this feature should be enabled.
+	Enabled bool `mapstructure:"enabled"`
+
+	// ReplaceDigits specifies whether digits in table, index and attribute
+	// names should be obfuscated.
+	ReplaceDigits bool `mapstructure:"replace_digits"`
+}
+
 // JSONConfig holds the obfuscation configuration for sensitive
 // data found in JSON objects.
 type JSONConfig struct {
@@ -376,6 +416,12 @@ func NewObfuscator(cfg Config) *Obfuscator {
 	if cfg.CreditCard.Enabled {
 		o.ccObfuscator = newCCObfuscator(&cfg.CreditCard)
 	}
+	if len(cfg.GraphQL.KeepValues) > 0 {
+		o.graphQLKeepValues = make(map[string]bool, len(cfg.GraphQL.KeepValues))
+		for _, k := range cfg.GraphQL.KeepValues {
+			o.graphQLKeepValues[k] = true
+		}
+	}
 	if cfg.Statsd == nil {
 		cfg.Statsd = &statsd.NoOpClient{}
 	}
@@ -0,0 +1,296 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// statementDialect describes the lexical specificities of one of the SQL-like query
+// languages obfuscated by obfuscateStatement.
+type statementDialect struct {
+	// dollarStrings enables $$...$$ string literals.
+	dollarStrings bool
+	// slashComments enables // line comments.
+	slashComments bool
+	// hexBlobs enables 0x... blob literals.
+	hexBlobs bool
+	// uuids enables unquoted UUID literals.
+	uuids bool
+	// namedMarkers enables :name bind markers.
+	namedMarkers bool
+	// bags enables << and >> bag delimiters.
+	bags bool
+	// literalKeywords holds the upper-cased keywords which are literal values.
+	literalKeywords map[string]bool
+}
+
+type statementTokenKind int
+
+const (
+	statementLiteral statementTokenKind = iota
+	statementName
+	statementOpen
+	statementClose
+	statementComma
+	statementColon
+	statementOther
+)
+
+type statementToken struct {
+	kind statementTokenKind
+	text string
+}
+
+// statementOpeners maps closing brackets to the corresponding opening ones.
+var statementOpeners = map[string]string{")": "(", "]": "[", "}": "{", ">>": "<<"}
+
+// obfuscateStatement obfuscates the statement in written in the language described by d.
+// Literal values are replaced with "?", comments are removed, consecutive literals in a list
+// are collapsed into a single "?", as are collection literals made only of literal values.
+// Bind markers, names and keywords are kept. The tokens of the result are separated by a
+// single space.
+func obfuscateStatement(in string, d *statementDialect, replaceDigitsInNames bool) string {
+	var (
+		out   []statementToken
+		opens []int // positions in out of the unclosed brackets
+	)
+	appendToken := func(kind statementTokenKind, text string) {
+		out = append(out, statementToken{kind: kind, text: text})
+	}
+	appendLiteral := func() {
+		if n := len(out); n >= 2 && out[n-1].kind == statementComma && out[n-2].kind == statementLiteral {
+			out = out[:n-1]
+			return
+		}
+		appendToken(statementLiteral, "?")
+	}
+	appendName := func(name string) {
+		if replaceDigitsInNames {
+			name = string(replaceDigits([]byte(name)))
+		}
+		appendToken(statementName, name)
+	}
+	for i := 0; i < len(in); {
+		c := in[i]
+		next := byte(0)
+		if i+1 < len(in) {
+			next = in[i+1]
+		}
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			i++
+		case c == '/' && next == '*':
+			if end := strings.Index(in[i+2:], "*/"); end >= 0 {
+				i += end + 4
+			} else {
+				i = len(in)
+			}
+		case isStatementCommentAt(in, i, d):
+			for i < len(in) && in[i] != '\n' && in[i] != '\r' {
+				i++
+			}
+		case c == '\'':
+			i = statementQuotedEnd(in, i)
+			appendLiteral()
+		case d.dollarStrings && c == '$' && next == '$':
+			if end := strings.Index(in[i+2:], "$$"); end >= 0 {
+				i += end + 4
+			} else {
+				i = len(in)
+			}
+			appendLiteral()
+		case d.hexBlobs && c == '0' && (next == 'x' || next == 'X'):
+			i = statementNameEnd(in, i+2)
+			appendLiteral()
+		case d.uuids && isUUIDAt(in, i):
+			i += 36
+			appendLiteral()
+		case isDigit(rune(c)) || (c == '.' && isDigit(rune(next))),
+			c == '-' && (isDigit(rune(next)) || next == '.') && !statementPrevIsOperand(out):
+			i = statementNumberEnd(in, i+1)
+			appendLiteral()
+		case c == '"' || isStatementNameStart(c):
+			end := statementNameEnd(in, i)
+			if word := in[i:end]; d.literalKeywords[strings.ToUpper(word)] {
+				appendLiteral()
+			} else {
+				appendName(word)
+			}
+			i = end
+		case d.namedMarkers && c == ':' && isStatementNameStart(next) && !statementPrevIsOperand(out):
+			end := statementNameEnd(in, i+1)
+			appendToken(statementOther, in[i:end])
+			i = end
+		case c == '(' || c == '[' || c == '{' || (d.bags && c == '<' && next == '<'):
+			n := 1
+			if c == '<' {
+				n = 2
+			}
+			opens = append(opens, len(out))
+			appendToken(statementOpen, in[i:i+n])
+			i += n
+		case c == ')' || c == ']' || c == '}' || (d.bags && c == '>' && next == '>'):
+			n := 1
+			if c == '>' {
+				n = 2
+			}
+			text := in[i : i+n]
+			i += n
+			if len(opens) == 0 || out[opens[len(opens)-1]].text != statementOpeners[text] {
+				appendToken(statementClose, text)
+				continue
+			}
+			open := opens[len(opens)-1]
+			opens = opens[:len(opens)-1]
+			if out[open].text != "(" && statementOnlyLiterals(out[open+1:]) {
+				// the whole collection is a literal value
+				out = out[:open]
+				appendLiteral()
+				continue
+			}
+			appendToken(statementClose, text)
+		case c == ',':
+			appendToken(statementComma, ",")
+			i++
+		case c == ':':
+			appendToken(statementColon, ":")
+			i++
+		case strings.IndexByte(statementOperatorChars, c) >= 0:
+			end := i + 1
+			for end < len(in) && strings.IndexByte(statementOperatorChars, in[end]) >= 0 && !isStatementCommentAt(in, end, d) {
+				end++
+			}
+			appendToken(statementOther, in[i:end])
+			i = end
+		default:
+			_, n := utf8.DecodeRuneInString(in[i:])
+			appendToken(statementOther, in[i:i+n])
+			i += n
+		}
+	}
+	var b strings.Builder
+	b.Grow(len(in))
+	for i, t := range out {
+		if i > 0 && t.kind != statementComma && t.text != ";" {
+			b.WriteByte(' ')
+		}
+		b.WriteString(t.text)
+	}
+	return b.String()
+}
+
+const statementOperatorChars = "=<>!+-*/%|&^~"
+
+// statementQuotedEnd returns the position following the quoted string or identifier starting at
+// position i of in. Quotes are escaped by doubling them. An unterminated string runs until the
+// end of in.
+func statementQuotedEnd(in string, i int) int {
+	q := in[i]
+	for j := i + 1; j < len(in); j++ {
+		if in[j] != q {
+			continue
+		}
+		if j+1 < len(in) && in[j+1] == q {
+			j++
+			continue
+		}
+		return j + 1
+	}
+	return len(in)
+}
+
+// statementNameEnd returns the position following the possibly quoted and dot-separated name
+// starting at position i of in.
+func statementNameEnd(in string, i int) int {
+	for i < len(in) {
+		if in[i] == '"' {
+			i = statementQuotedEnd(in, i)
+		} else {
+			for i < len(in) && (isStatementNameStart(in[i]) || isDigit(rune(in[i])) || in[i] == '$') {
+				i++
+			}
+		}
+		if i+1 >= len(in) || in[i] != '.' || (in[i+1] != '"' && !isStatementNameStart(in[i+1])) {
+			return i
+		}
+		i++
+	}
+	return i
+}
+
+// statementNumberEnd returns the position following the number whose first character precedes
+// position i of in. Letters are consumed as part of the number to cover exponents and durations
+// such as 1h30m.
+func statementNumberEnd(in string, i int) int {
+	for ; i < len(in); i++ {
+		c := in[i]
+		switch {
+		case isDigit(rune(c)), isStatementNameStart(c), c == '.':
+		case (c == '+' || c == '-') && (in[i-1] == 'e' || in[i-1] == 'E'):
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// statementPrevIsOperand reports whether the last token of out can be followed by a binary
+// operator, in which case a minus sign isn't the sign of a number.
+func statementPrevIsOperand(out []statementToken) bool {
+	if len(out) == 0 {
+		return false
+	}
+	switch last := out[len(out)-1]; last.kind {
+	case statementLiteral, statementName, statementClose:
+		return true
+	case statementOther:
+		return last.text == "?" || last.text[0] == ':'
+	}
+	return false
+}
+
+// statementOnlyLiterals reports whether tokens only hold literal values and their separators.
+func statementOnlyLiterals(tokens []statementToken) bool {
+	for _, t := range tokens {
+		if t.kind != statementLiteral && t.kind != statementComma && t.kind != statementColon {
+			return false
+		}
+	}
+	return true
+}
+
+// isUUIDAt reports whether an unquoted UUID starts at position i of in.
+func isUUIDAt(in string, i int) bool {
+	if len(in)-i < 36 || (i > 0 && isStatementNameStart(in[i-1])) {
+		return false
+	}
+	for j := 0; j < 36; j++ {
+		c := in[i+j]
+		switch j {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if digitVal(rune(c)) > 15 {
+				return false
+			}
+		}
+	}
+	return i+36 == len(in) || !(isStatementNameStart(in[i+36]) || isDigit(rune(in[i+36])))
+}
+
+// isStatementCommentAt reports whether a comment starts at position i of in.
+func isStatementCommentAt(in string, i int, d *statementDialect) bool {
+	rest := in[i:]
+	return strings.HasPrefix(rest, "--") || strings.HasPrefix(rest, "/*") || (d.slashComments && strings.HasPrefix(rest, "//"))
+}
+
+func isStatementNameStart(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
@@ -0,0 +1,72 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+// statementFuzzSeeds holds the seed corpus of the CQL and PartiQL fuzz tests, chosen from unit test examples.
+var statementFuzzSeeds = []string{
+	"SELECT * FROM ks.users WHERE id = 42",
+	"SELECT name FROM users WHERE email = 'jane@example.com' AND age > -3 ALLOW FILTERING;",
+	"INSERT INTO users (id, name, tags, prefs, active) VALUES (123e4567-e89b-12d3-a456-426614174000, 'O''Brien', {'a', 'b'}, {'theme': 'dark'}, true) USING TTL 86400",
+	"UPDATE users SET scores = scores + [1, 2, 3], avatar = 0xCAFE, delay = 1h30m WHERE id IN (1, 2, 3) IF EXISTS",
+	"SELECT * FROM \"Users\" WHERE id = ? AND org = :org AND score = NaN -- trailing comment",
+	"/* app=web */ SELECT token(id), count(*) FROM events_2024 // by day\nWHERE day = '2024-01-01' LIMIT 10",
+	"SELECT * FROM events WHERE body = $$some\n'text'$$ AND a = b - 1",
+	`INSERT INTO "Music" VALUE {'Artist': 'Acme Band', 'Awards': 1, 'Tags': <<'a', 'b'>>, 'Albums': [{'Year': 2020}]}`,
+	`SELECT OrderID FROM "Orders_2023"."CustomerIndex" WHERE CustomerID IN ['c1', 'c2'] AND Total >= 10.5`,
+	`UPDATE "Music" SET Tags = set_add(Tags, <<'x'>>) WHERE Artist = 'y'`,
+	"SELECT * FROM t WHERE a = 'unterminated",
+	"SELECT * FROM t WHERE a = 1) AND b = é",
+	"a =-- comment",
+	"a >>= <<",
+	`"unterminated`,
+	"'",
+	"$$",
+	"0x",
+	"-",
+	".5e-3",
+	"",
+	"\xc3\x28",                 // invalid UTF-8
+	string([]byte{0x00, 0x27}), // null byte and quote
+}
+
+// fuzzStatementObfuscator checks the invariants of the obfuscation of a statement by obfuscate.
+func fuzzStatementObfuscator(t *testing.T, in string, obfuscate func(string) string) {
+	// No panics
+	result := obfuscate(in)
+
+	if utf8.ValidString(in) && !utf8.ValidString(result) {
+		t.Errorf("obfuscating %q: result %q is not valid UTF-8", in, result)
+	}
+	// Obfuscating an obfuscated statement has no effect.
+	if again := obfuscate(result); again != result {
+		t.Errorf("obfuscating %q gives %q, which obfuscates to %q", in, result, again)
+	}
+}
+
+func FuzzObfuscateCQL(f *testing.F) {
+	for _, seed := range statementFuzzSeeds {
+		f.Add(seed)
+	}
+	o := NewObfuscator(Config{CQL: CQLConfig{Enabled: true}})
+	f.Fuzz(func(t *testing.T, in string) {
+		fuzzStatementObfuscator(t, in, o.ObfuscateCQLString)
+	})
+}
+
+func FuzzObfuscateDynamoDB(f *testing.F) {
+	for _, seed := range statementFuzzSeeds {
+		f.Add(seed)
+	}
+	o := NewObfuscator(Config{DynamoDB: DynamoDBConfig{Enabled: true}})
+	f.Fuzz(func(t *testing.T, in string) {
+		fuzzStatementObfuscator(t, in, o.ObfuscateDynamoDBString)
+	})
+}
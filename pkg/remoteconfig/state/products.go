@@ -16,6 +16,7 @@ var validProducts = map[string]struct{}{
 	ProductAgentTask:                    {},
 	ProductAgentIntegrations:            {},
 	ProductAPMSampling:                  {},
+	ProductAPMSpanFilters:               {},
 	ProductCWSDD:                        {},
 	ProductCWSCustom:                    {},
 	ProductCWSProfiles:                  {},
@@ -66,6 +67,8 @@ const (
 	ProductAgentTask = "AGENT_TASK"
 	// ProductAPMSampling is the apm sampling product
 	ProductAPMSampling = "APM_SAMPLING"
+	// ProductAPMSpanFilters is the apm span filtering rules product
+	ProductAPMSpanFilters = "APM_SPAN_FILTERS"
 	// ProductCWSDD is the cloud workload security product managed by datadog employees
 	ProductCWSDD = "CWS_DD"
 	// ProductCWSCustom is the cloud workload security product managed by datadog customers
@@ -0,0 +1,371 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package bomconvert
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/DataDog/agent-payload/v5/cyclonedx_v1_4"
+	"github.com/google/uuid"
+)
+
+const (
+	spdxVersion         = "SPDX-2.3"
+	spdxDataLicense     = "CC0-1.0"
+	spdxDocumentID      = "SPDXRef-DOCUMENT"
+	spdxNoAssertion     = "NOASSERTION"
+	spdxNamespacePrefix = "https://datadoghq.com/spdxdocs/"
+)
+
+// SPDX relationship types used by the conversion
+const (
+	SPDXRelationshipDescribes = "DESCRIBES"
+	SPDXRelationshipContains  = "CONTAINS"
+	SPDXRelationshipDependsOn = "DEPENDS_ON"
+)
+
+var spdxInvalidIDChars = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+// SPDXDocument is an SPDX 2.3 document, as serialized in the JSON format
+type SPDXDocument struct {
+	SPDXVersion                string                   `json:"spdxVersion"`
+	DataLicense                string                   `json:"dataLicense"`
+	SPDXID                     string                   `json:"SPDXID"`
+	Name                       string                   `json:"name"`
+	DocumentNamespace          string                   `json:"documentNamespace"`
+	CreationInfo               SPDXCreationInfo         `json:"creationInfo"`
+	Packages                   []SPDXPackage            `json:"packages,omitempty"`
+	Relationships              []SPDXRelationship       `json:"relationships,omitempty"`
+	HasExtractedLicensingInfos []SPDXExtractedLicensing `json:"hasExtractedLicensingInfos,omitempty"`
+}
+
+// SPDXCreationInfo describes how and when an SPDX document was created
+type SPDXCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+// SPDXPackage is an SPDX package
+type SPDXPackage struct {
+	SPDXID                string            `json:"SPDXID"`
+	Name                  string            `json:"name"`
+	VersionInfo           string            `json:"versionInfo,omitempty"`
+	Supplier              string            `json:"supplier,omitempty"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         bool              `json:"filesAnalyzed"`
+	Checksums             []SPDXChecksum    `json:"checksums,omitempty"`
+	LicenseConcluded      string            `json:"licenseConcluded"`
+	LicenseDeclared       string            `json:"licenseDeclared"`
+	CopyrightText         string            `json:"copyrightText"`
+	Description           string            `json:"description,omitempty"`
+	ExternalRefs          []SPDXExternalRef `json:"externalRefs,omitempty"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose,omitempty"`
+}
+
+// SPDXChecksum is the checksum of an SPDX package
+type SPDXChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+// SPDXExternalRef is a reference from an SPDX package to an external identifier
+type SPDXExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+// SPDXRelationship is a relationship between two SPDX elements
+type SPDXRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// SPDXExtractedLicensing declares a license which is not in the SPDX license list
+type SPDXExtractedLicensing struct {
+	LicenseID     string `json:"licenseId"`
+	ExtractedText string `json:"extractedText"`
+	Name          string `json:"name"`
+}
+
+type spdxConverter struct {
+	doc         *SPDXDocument
+	idsByRef    map[string]string
+	licenseRefs map[string]string
+	licenseIDs  map[string]struct{}
+}
+
+// ConvertBOMToSPDX converts a CycloneDX v1.4 BOM to an SPDX 2.3 document.
+// Components are converted to packages, the component hierarchy to CONTAINS
+// relationships and the dependency graph to DEPENDS_ON relationships.
+func ConvertBOMToSPDX(in *cyclonedx_v1_4.Bom) *SPDXDocument {
+	if in == nil {
+		return nil
+	}
+
+	c := &spdxConverter{
+		doc: &SPDXDocument{
+			SPDXVersion: spdxVersion,
+			DataLicense: spdxDataLicense,
+			SPDXID:      spdxDocumentID,
+		},
+		idsByRef:    make(map[string]string),
+		licenseRefs: make(map[string]string),
+		licenseIDs:  make(map[string]struct{}),
+	}
+
+	metadata := in.GetMetadata()
+	c.doc.CreationInfo = convertSPDXCreationInfo(metadata)
+
+	root := metadata.GetComponent()
+	c.doc.Name = root.GetName()
+	if c.doc.Name == "" {
+		c.doc.Name = "unknown"
+	}
+	c.doc.DocumentNamespace = spdxNamespacePrefix + spdxIDSanitize(c.doc.Name) + "-" + spdxNamespaceUUID(in.GetSerialNumber())
+
+	if root != nil {
+		rootID := c.addPackage(root)
+		c.addRelationship(spdxDocumentID, SPDXRelationshipDescribes, rootID)
+		for _, component := range in.GetComponents() {
+			c.addRelationship(rootID, SPDXRelationshipContains, c.addPackage(component))
+		}
+	} else {
+		for _, component := range in.GetComponents() {
+			c.addRelationship(spdxDocumentID, SPDXRelationshipDescribes, c.addPackage(component))
+		}
+	}
+
+	for _, dependency := range in.GetDependencies() {
+		fromID, ok := c.idsByRef[dependency.GetRef()]
+		if !ok {
+			continue
+		}
+
+		for _, dependsOn := range dependency.GetDependencies() {
+			if toID, ok := c.idsByRef[dependsOn.GetRef()]; ok {
+				c.addRelationship(fromID, SPDXRelationshipDependsOn, toID)
+			}
+		}
+	}
+
+	return c.doc
+}
+
+func (c *spdxConverter) addPackage(in *cyclonedx_v1_4.Component) string {
+	id := fmt.Sprintf("SPDXRef-Package-%d", len(c.doc.Packages))
+	if ref := in.GetBomRef(); ref != "" {
+		c.idsByRef[ref] = id
+	}
+
+	pkg := SPDXPackage{
+		SPDXID:                id,
+		Name:                  in.GetName(),
+		VersionInfo:           in.GetVersion(),
+		Supplier:              spdxNoAssertion,
+		DownloadLocation:      spdxNoAssertion,
+		LicenseConcluded:      spdxNoAssertion,
+		LicenseDeclared:       c.convertLicenses(in.GetLicenses()),
+		CopyrightText:         spdxNoAssertion,
+		Description:           in.GetDescription(),
+		PrimaryPackagePurpose: convertSPDXPurpose(in.GetType()),
+	}
+
+	if supplier := in.GetSupplier().GetName(); supplier != "" {
+		pkg.Supplier = "Organization: " + supplier
+	}
+
+	if copyright := in.GetCopyright(); copyright != "" {
+		pkg.CopyrightText = copyright
+	}
+
+	for _, hash := range in.GetHashes() {
+		if algorithm := convertSPDXChecksumAlgorithm(hash.GetAlg()); algorithm != "" {
+			pkg.Checksums = append(pkg.Checksums, SPDXChecksum{Algorithm: algorithm, ChecksumValue: hash.GetValue()})
+		}
+	}
+
+	if purl := in.GetPurl(); purl != "" {
+		pkg.ExternalRefs = append(pkg.ExternalRefs, SPDXExternalRef{
+			ReferenceCategory: "PACKAGE-MANAGER",
+			ReferenceType:     "purl",
+			ReferenceLocator:  purl,
+		})
+	}
+
+	if cpe := in.GetCpe(); cpe != "" {
+		pkg.ExternalRefs = append(pkg.ExternalRefs, SPDXExternalRef{
+			ReferenceCategory: "SECURITY",
+			ReferenceType:     "cpe23Type",
+			ReferenceLocator:  cpe,
+		})
+	}
+
+	c.doc.Packages = append(c.doc.Packages, pkg)
+
+	for _, component := range in.GetComponents() {
+		c.addRelationship(id, SPDXRelationshipContains, c.addPackage(component))
+	}
+
+	return id
+}
+
+func (c *spdxConverter) addRelationship(from, relationshipType, to string) {
+	c.doc.Relationships = append(c.doc.Relationships, SPDXRelationship{
+		SPDXElementID:      from,
+		RelationshipType:   relationshipType,
+		RelatedSPDXElement: to,
+	})
+}
+
+// convertLicenses builds an SPDX license expression from the CycloneDX license choices.
+// Licenses which are only known by their name are declared as extracted licenses.
+func (c *spdxConverter) convertLicenses(in []*cyclonedx_v1_4.LicenseChoice) string {
+	var expressions []string
+	for _, choice := range in {
+		if expression := choice.GetExpression(); expression != "" {
+			expressions = append(expressions, expression)
+			continue
+		}
+
+		license := choice.GetLicense()
+		if id := license.GetId(); id != "" {
+			expressions = append(expressions, id)
+		} else if name := license.GetName(); name != "" {
+			expressions = append(expressions, c.licenseRefForName(name))
+		}
+	}
+
+	switch len(expressions) {
+	case 0:
+		return spdxNoAssertion
+	case 1:
+		return expressions[0]
+	default:
+		for i, expression := range expressions {
+			if strings.Contains(expression, " ") {
+				expressions[i] = "(" + expression + ")"
+			}
+		}
+		return strings.Join(expressions, " AND ")
+	}
+}
+
+func (c *spdxConverter) licenseRefForName(name string) string {
+	if ref, ok := c.licenseRefs[name]; ok {
+		return ref
+	}
+
+	ref := "LicenseRef-" + spdxIDSanitize(name)
+	if _, collision := c.licenseIDs[ref]; collision {
+		ref = fmt.Sprintf("%s-%d", ref, len(c.licenseRefs))
+	}
+
+	c.licenseRefs[name] = ref
+	c.licenseIDs[ref] = struct{}{}
+	c.doc.HasExtractedLicensingInfos = append(c.doc.HasExtractedLicensingInfos, SPDXExtractedLicensing{
+		LicenseID:     ref,
+		ExtractedText: name,
+		Name:          name,
+	})
+
+	return ref
+}
+
+func convertSPDXCreationInfo(in *cyclonedx_v1_4.Metadata) SPDXCreationInfo {
+	created := time.Now()
+	if in.GetTimestamp() != nil {
+		created = in.GetTimestamp().AsTime()
+	}
+
+	info := SPDXCreationInfo{
+		Created:  created.UTC().Format(time.RFC3339),
+		Creators: []string{"Organization: Datadog"},
+	}
+
+	for _, tool := range in.GetTools() {
+		if tool.GetName() == "" {
+			continue
+		}
+
+		creator := "Tool: " + tool.GetName()
+		if tool.GetVersion() != "" {
+			creator += "-" + tool.GetVersion()
+		}
+		info.Creators = append(info.Creators, creator)
+	}
+
+	return info
+}
+
+func convertSPDXPurpose(in cyclonedx_v1_4.Classification) string {
+	switch in {
+	case cyclonedx_v1_4.Classification_CLASSIFICATION_APPLICATION:
+		return "APPLICATION"
+	case cyclonedx_v1_4.Classification_CLASSIFICATION_CONTAINER:
+		return "CONTAINER"
+	case cyclonedx_v1_4.Classification_CLASSIFICATION_DEVICE:
+		return "DEVICE"
+	case cyclonedx_v1_4.Classification_CLASSIFICATION_FILE:
+		return "FILE"
+	case cyclonedx_v1_4.Classification_CLASSIFICATION_FIRMWARE:
+		return "FIRMWARE"
+	case cyclonedx_v1_4.Classification_CLASSIFICATION_FRAMEWORK:
+		return "FRAMEWORK"
+	case cyclonedx_v1_4.Classification_CLASSIFICATION_LIBRARY:
+		return "LIBRARY"
+	case cyclonedx_v1_4.Classification_CLASSIFICATION_OPERATING_SYSTEM:
+		return "OPERATING-SYSTEM"
+	default:
+		return ""
+	}
+}
+
+func convertSPDXChecksumAlgorithm(in cyclonedx_v1_4.HashAlg) string {
+	switch in {
+	case cyclonedx_v1_4.HashAlg_HASH_ALG_MD_5:
+		return "MD5"
+	case cyclonedx_v1_4.HashAlg_HASH_ALG_SHA_1:
+		return "SHA1"
+	case cyclonedx_v1_4.HashAlg_HASH_ALG_SHA_256:
+		return "SHA256"
+	case cyclonedx_v1_4.HashAlg_HASH_ALG_SHA_384:
+		return "SHA384"
+	case cyclonedx_v1_4.HashAlg_HASH_ALG_SHA_512:
+		return "SHA512"
+	case cyclonedx_v1_4.HashAlg_HASH_ALG_SHA_3_256:
+		return "SHA3-256"
+	case cyclonedx_v1_4.HashAlg_HASH_ALG_SHA_3_512:
+		return "SHA3-512"
+	case cyclonedx_v1_4.HashAlg_HASH_ALG_BLAKE_2_B_256:
+		return "BLAKE2b-256"
+	case cyclonedx_v1_4.HashAlg_HASH_ALG_BLAKE_2_B_384:
+		return "BLAKE2b-384"
+	case cyclonedx_v1_4.HashAlg_HASH_ALG_BLAKE_2_B_512:
+		return "BLAKE2b-512"
+	case cyclonedx_v1_4.HashAlg_HASH_ALG_BLAKE_3:
+		return "BLAKE3"
+	default:
+		return ""
+	}
+}
+
+func spdxIDSanitize(in string) string {
+	return strings.Trim(spdxInvalidIDChars.ReplaceAllString(in, "-"), "-")
+}
+
+// spdxNamespaceUUID reuses the UUID of the BOM serial number so that converting
+// the same BOM twice yields the same document namespace.
+func spdxNamespaceUUID(serialNumber string) string {
+	if id, err := uuid.Parse(strings.TrimPrefix(serialNumber, "urn:uuid:")); err == nil {
+		return id.String()
+	}
+	return uuid.NewString()
+}
@@ -0,0 +1,134 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package bomconvert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DataDog/agent-payload/v5/cyclonedx_v1_4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/DataDog/datadog-agent/pkg/util/pointer"
+)
+
+func TestConvertBOMToSPDX(t *testing.T) {
+	bom := &cyclonedx_v1_4.Bom{
+		SpecVersion:  "1.4",
+		SerialNumber: pointer.Ptr("urn:uuid:5c2f0f6e-0d8c-4b7a-9c0c-1c8d0a8f6e11"),
+		Metadata: &cyclonedx_v1_4.Metadata{
+			Timestamp: timestamppb.New(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+			Tools: []*cyclonedx_v1_4.Tool{
+				{Name: pointer.Ptr("trivy"), Version: pointer.Ptr("0.49.1")},
+			},
+			Component: &cyclonedx_v1_4.Component{
+				BomRef: pointer.Ptr("image"),
+				Type:   cyclonedx_v1_4.Classification_CLASSIFICATION_CONTAINER,
+				Name:   "docker.io/library/alpine:3.20",
+			},
+		},
+		Components: []*cyclonedx_v1_4.Component{
+			{
+				BomRef: pointer.Ptr("os"),
+				Type:   cyclonedx_v1_4.Classification_CLASSIFICATION_OPERATING_SYSTEM,
+				Name:   "alpine",
+				Components: []*cyclonedx_v1_4.Component{
+					{
+						BomRef:  pointer.Ptr("pkg:apk/alpine/musl@1.2.5-r0"),
+						Type:    cyclonedx_v1_4.Classification_CLASSIFICATION_LIBRARY,
+						Name:    "musl",
+						Version: "1.2.5-r0",
+						Purl:    pointer.Ptr("pkg:apk/alpine/musl@1.2.5-r0"),
+						Hashes: []*cyclonedx_v1_4.Hash{
+							{Alg: cyclonedx_v1_4.HashAlg_HASH_ALG_SHA_1, Value: "abc"},
+						},
+						Licenses: []*cyclonedx_v1_4.LicenseChoice{
+							{Choice: &cyclonedx_v1_4.LicenseChoice_License{License: &cyclonedx_v1_4.License{License: &cyclonedx_v1_4.License_Id{Id: "MIT"}}}},
+						},
+					},
+				},
+			},
+			{
+				BomRef:  pointer.Ptr("pkg:apk/alpine/busybox@1.36.1-r29"),
+				Type:    cyclonedx_v1_4.Classification_CLASSIFICATION_LIBRARY,
+				Name:    "busybox",
+				Version: "1.36.1-r29",
+				Licenses: []*cyclonedx_v1_4.LicenseChoice{
+					{Choice: &cyclonedx_v1_4.LicenseChoice_License{License: &cyclonedx_v1_4.License{License: &cyclonedx_v1_4.License_Name{Name: "GPL-2.0 only"}}}},
+					{Choice: &cyclonedx_v1_4.LicenseChoice_Expression{Expression: "MIT OR Apache-2.0"}},
+				},
+			},
+		},
+		Dependencies: []*cyclonedx_v1_4.Dependency{
+			{
+				Ref: "pkg:apk/alpine/busybox@1.36.1-r29",
+				Dependencies: []*cyclonedx_v1_4.Dependency{
+					{Ref: "pkg:apk/alpine/musl@1.2.5-r0"},
+					{Ref: "unknown"},
+				},
+			},
+		},
+	}
+
+	doc := ConvertBOMToSPDX(bom)
+	require.NotNil(t, doc)
+
+	assert.Equal(t, "SPDX-2.3", doc.SPDXVersion)
+	assert.Equal(t, "docker.io/library/alpine:3.20", doc.Name)
+	assert.Equal(t, "https://datadoghq.com/spdxdocs/docker.io-library-alpine-3.20-5c2f0f6e-0d8c-4b7a-9c0c-1c8d0a8f6e11", doc.DocumentNamespace)
+	assert.Equal(t, SPDXCreationInfo{
+		Created:  "2025-01-02T03:04:05Z",
+		Creators: []string{"Organization: Datadog", "Tool: trivy-0.49.1"},
+	}, doc.CreationInfo)
+
+	require.Len(t, doc.Packages, 4)
+	assert.Equal(t, "CONTAINER", doc.Packages[0].PrimaryPackagePurpose)
+	assert.Equal(t, "OPERATING-SYSTEM", doc.Packages[1].PrimaryPackagePurpose)
+	assert.Equal(t, SPDXPackage{
+		SPDXID:           "SPDXRef-Package-2",
+		Name:             "musl",
+		VersionInfo:      "1.2.5-r0",
+		Supplier:         "NOASSERTION",
+		DownloadLocation: "NOASSERTION",
+		Checksums:        []SPDXChecksum{{Algorithm: "SHA1", ChecksumValue: "abc"}},
+		LicenseConcluded: "NOASSERTION",
+		LicenseDeclared:  "MIT",
+		CopyrightText:    "NOASSERTION",
+		ExternalRefs: []SPDXExternalRef{
+			{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: "pkg:apk/alpine/musl@1.2.5-r0"},
+		},
+		PrimaryPackagePurpose: "LIBRARY",
+	}, doc.Packages[2])
+	assert.Equal(t, "LicenseRef-GPL-2.0-only AND (MIT OR Apache-2.0)", doc.Packages[3].LicenseDeclared)
+	assert.Equal(t, []SPDXExtractedLicensing{
+		{LicenseID: "LicenseRef-GPL-2.0-only", ExtractedText: "GPL-2.0 only", Name: "GPL-2.0 only"},
+	}, doc.HasExtractedLicensingInfos)
+
+	assert.Equal(t, []SPDXRelationship{
+		{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: SPDXRelationshipDescribes, RelatedSPDXElement: "SPDXRef-Package-0"},
+		{SPDXElementID: "SPDXRef-Package-1", RelationshipType: SPDXRelationshipContains, RelatedSPDXElement: "SPDXRef-Package-2"},
+		{SPDXElementID: "SPDXRef-Package-0", RelationshipType: SPDXRelationshipContains, RelatedSPDXElement: "SPDXRef-Package-1"},
+		{SPDXElementID: "SPDXRef-Package-0", RelationshipType: SPDXRelationshipContains, RelatedSPDXElement: "SPDXRef-Package-3"},
+		{SPDXElementID: "SPDXRef-Package-3", RelationshipType: SPDXRelationshipDependsOn, RelatedSPDXElement: "SPDXRef-Package-2"},
+	}, doc.Relationships)
+}
+
+func TestConvertBOMToSPDXWithoutMetadata(t *testing.T) {
+	doc := ConvertBOMToSPDX(&cyclonedx_v1_4.Bom{
+		Components: []*cyclonedx_v1_4.Component{
+			{Name: "musl", Version: "1.2.5-r0"},
+		},
+	})
+	require.NotNil(t, doc)
+
+	assert.Equal(t, "unknown", doc.Name)
+	assert.Equal(t, []SPDXRelationship{
+		{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: SPDXRelationshipDescribes, RelatedSPDXElement: "SPDXRef-Package-0"},
+	}, doc.Relationships)
+	assert.Nil(t, ConvertBOMToSPDX(nil))
+}
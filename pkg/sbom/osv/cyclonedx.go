@@ -0,0 +1,61 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package osv
+
+import (
+	"github.com/DataDog/agent-payload/v5/cyclonedx_v1_4"
+
+	"github.com/DataDog/datadog-agent/pkg/util/pointer"
+)
+
+// AddToBOM adds findings to the vulnerabilities of a CycloneDX BOM. Findings of the same
+// vulnerability on several components are merged into a single vulnerability.
+func AddToBOM(bom *cyclonedx_v1_4.Bom, findings []Finding) {
+	vulnerabilities := make(map[string]*cyclonedx_v1_4.Vulnerability)
+	for _, finding := range findings {
+		vuln, ok := vulnerabilities[finding.VulnerabilityID]
+		if !ok {
+			vuln = &cyclonedx_v1_4.Vulnerability{
+				Id:     pointer.Ptr(finding.VulnerabilityID),
+				Source: &cyclonedx_v1_4.Source{Name: pointer.Ptr("OSV"), Url: pointer.Ptr("https://osv.dev/vulnerability/" + finding.VulnerabilityID)},
+				Ratings: []*cyclonedx_v1_4.VulnerabilityRating{
+					{Severity: pointer.Ptr(convertSeverityLevel(finding.Severity))},
+				},
+			}
+			if finding.Summary != "" {
+				vuln.Description = pointer.Ptr(finding.Summary)
+			}
+			for _, alias := range finding.Aliases {
+				vuln.References = append(vuln.References, &cyclonedx_v1_4.VulnerabilityReference{Id: pointer.Ptr(alias)})
+			}
+
+			vulnerabilities[finding.VulnerabilityID] = vuln
+			bom.Vulnerabilities = append(bom.Vulnerabilities, vuln)
+		}
+
+		if finding.BOMRef != "" {
+			vuln.Affects = append(vuln.Affects, &cyclonedx_v1_4.VulnerabilityAffects{Ref: finding.BOMRef})
+		}
+		if finding.FixedVersion != "" && vuln.Recommendation == nil {
+			vuln.Recommendation = pointer.Ptr("Upgrade " + finding.PackageName + " to " + finding.FixedVersion)
+		}
+	}
+}
+
+func convertSeverityLevel(in SeverityLevel) cyclonedx_v1_4.Severity {
+	switch in {
+	case SeverityLow:
+		return cyclonedx_v1_4.Severity_SEVERITY_LOW
+	case SeverityMedium:
+		return cyclonedx_v1_4.Severity_SEVERITY_MEDIUM
+	case SeverityHigh:
+		return cyclonedx_v1_4.Severity_SEVERITY_HIGH
+	case SeverityCritical:
+		return cyclonedx_v1_4.Severity_SEVERITY_CRITICAL
+	default:
+		return cyclonedx_v1_4.Severity_SEVERITY_UNKNOWN
+	}
+}
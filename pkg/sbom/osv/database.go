@@ -0,0 +1,315 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package osv
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+type packageKey struct {
+	ecosystem string
+	name      string
+}
+
+type affectedEntry struct {
+	vuln     *Vulnerability
+	affected *Affected
+	release  string
+}
+
+// Database is an in-memory index of OSV vulnerabilities, by ecosystem and package name
+type Database struct {
+	packages map[packageKey][]affectedEntry
+	count    int
+	modTime  time.Time
+}
+
+// LoadDatabase loads an OSV database from disk. The path can either be a directory containing
+// OSV JSON files, or ZIP archives of such files as published by https://osv.dev, or a single
+// ZIP archive. Directories are walked recursively.
+func LoadDatabase(path string) (*Database, error) {
+	db := &Database{
+		packages: make(map[packageKey][]affectedEntry),
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open vulnerability database: %w", err)
+	}
+
+	if !info.IsDir() {
+		db.modTime = info.ModTime()
+		if err := db.loadFile(path); err != nil {
+			return nil, err
+		}
+		return db, nil
+	}
+
+	err = filepath.WalkDir(path, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(db.modTime) {
+			db.modTime = info.ModTime()
+		}
+
+		return db.loadFile(filePath)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to load vulnerability database %s: %w", path, err)
+	}
+
+	return db, nil
+}
+
+func (db *Database) loadFile(path string) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".zip":
+		return db.loadZip(path)
+	case ".json":
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return db.loadEntry(path, f)
+	default:
+		return nil
+	}
+}
+
+func (db *Database) loadZip(path string) error {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("unable to open %s: %w", path, err)
+	}
+	defer archive.Close()
+
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() || !strings.EqualFold(filepath.Ext(file.Name), ".json") {
+			continue
+		}
+
+		f, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("unable to open %s in %s: %w", file.Name, path, err)
+		}
+		err = db.loadEntry(path+":"+file.Name, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (db *Database) loadEntry(name string, r io.Reader) error {
+	var vuln Vulnerability
+	if err := json.NewDecoder(r).Decode(&vuln); err != nil {
+		// A single malformed entry should not prevent using the rest of the database
+		log.Warnf("Skipping invalid OSV entry %s: %v", name, err)
+		return nil
+	}
+
+	db.add(&vuln)
+	return nil
+}
+
+func (db *Database) add(vuln *Vulnerability) {
+	if vuln.ID == "" || vuln.Withdrawn != "" {
+		return
+	}
+
+	db.count++
+	for i := range vuln.Affected {
+		affected := &vuln.Affected[i]
+		ecosystem, release := splitEcosystem(affected.Package.Ecosystem)
+		key := packageKey{
+			ecosystem: ecosystem,
+			name:      normalizePackageName(ecosystem, affected.Package.Name),
+		}
+		db.packages[key] = append(db.packages[key], affectedEntry{
+			vuln:     vuln,
+			affected: affected,
+			release:  release,
+		})
+	}
+}
+
+// Len returns the number of vulnerabilities in the database
+func (db *Database) Len() int {
+	return db.count
+}
+
+// ModTime returns the most recent modification time of the database files
+func (db *Database) ModTime() time.Time {
+	return db.modTime
+}
+
+// Finding is a vulnerability affecting a package
+type Finding struct {
+	VulnerabilityID string        `json:"vulnerability_id"`
+	Aliases         []string      `json:"aliases,omitempty"`
+	Summary         string        `json:"summary,omitempty"`
+	Severity        SeverityLevel `json:"severity"`
+	Ecosystem       string        `json:"ecosystem"`
+	PackageName     string        `json:"package_name"`
+	PackageVersion  string        `json:"package_version"`
+	FixedVersion    string        `json:"fixed_version,omitempty"`
+	BOMRef          string        `json:"bom_ref,omitempty"`
+}
+
+// Query describes a package to look up in the database
+type Query struct {
+	Ecosystem string
+	Name      string
+	Version   string
+	// Release is the release of the distribution the package belongs to, if any
+	Release string
+}
+
+// Match returns the vulnerabilities affecting a package
+func (db *Database) Match(q Query) []Finding {
+	key := packageKey{
+		ecosystem: q.Ecosystem,
+		name:      normalizePackageName(q.Ecosystem, q.Name),
+	}
+	compare := comparerForEcosystem(q.Ecosystem)
+
+	var findings []Finding
+	for _, entry := range db.packages[key] {
+		if !releaseMatches(entry.release, q.Release) {
+			continue
+		}
+
+		affected, fixedVersion := isAffected(entry.affected, q.Version, compare)
+		if !affected {
+			continue
+		}
+
+		// The same vulnerability may be reported for several releases of a distribution
+		if slices.ContainsFunc(findings, func(f Finding) bool { return f.VulnerabilityID == entry.vuln.ID }) {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			VulnerabilityID: entry.vuln.ID,
+			Aliases:         entry.vuln.Aliases,
+			Summary:         entry.vuln.Summary,
+			Severity:        severityOf(entry.vuln, entry.affected),
+			Ecosystem:       q.Ecosystem,
+			PackageName:     q.Name,
+			PackageVersion:  q.Version,
+			FixedVersion:    fixedVersion,
+		})
+	}
+
+	return findings
+}
+
+// isAffected evaluates the affected versions and ranges of a package, as described in
+// https://ossf.github.io/osv-schema/#evaluation. It also returns the version fixing the
+// vulnerability, if any. `SEMVER` ranges are evaluated as semantic versions and `ECOSYSTEM`
+// ranges with ecosystemCompare, or skipped when it is nil.
+func isAffected(affected *Affected, version string, ecosystemCompare compareFunc) (bool, string) {
+	if version == "" {
+		return false, ""
+	}
+
+	if slices.Contains(affected.Versions, version) {
+		return true, ""
+	}
+
+	for _, r := range affected.Ranges {
+		compare := ecosystemCompare
+		switch {
+		case r.Type == RangeTypeSemver:
+			compare = compareGeneric
+		case r.Type != RangeTypeEcosystem || compare == nil:
+			// The versions of ecosystems without a supported version scheme can only be
+			// matched against the list of affected versions
+			continue
+		}
+
+		events := slices.Clone(r.Events)
+		slices.SortStableFunc(events, func(a, b Event) int {
+			return compareEventVersions(eventVersion(a), eventVersion(b), compare)
+		})
+
+		vulnerable := false
+		fixedVersion := ""
+		for _, event := range events {
+			switch {
+			case event.Introduced != "":
+				if event.Introduced == "0" || compare(version, event.Introduced) >= 0 {
+					vulnerable = true
+				}
+			case event.Fixed != "":
+				if compare(version, event.Fixed) >= 0 {
+					vulnerable = false
+				} else if vulnerable && fixedVersion == "" {
+					fixedVersion = event.Fixed
+				}
+			case event.LastAffected != "":
+				if compare(version, event.LastAffected) > 0 {
+					vulnerable = false
+				}
+			}
+		}
+
+		if vulnerable {
+			return true, fixedVersion
+		}
+	}
+
+	return false, ""
+}
+
+func eventVersion(e Event) string {
+	switch {
+	case e.Introduced != "":
+		return e.Introduced
+	case e.Fixed != "":
+		return e.Fixed
+	case e.LastAffected != "":
+		return e.LastAffected
+	default:
+		return e.Limit
+	}
+}
+
+func compareEventVersions(a, b string, compare compareFunc) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "0":
+		return -1
+	case b == "0":
+		return 1
+	default:
+		return compare(a, b)
+	}
+}
@@ -0,0 +1,205 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package osv
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DataDog/agent-payload/v5/cyclonedx_v1_4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/util/pointer"
+)
+
+const debianEntry = `{
+  "id": "DSA-5532-1",
+  "summary": "openssl - security update",
+  "aliases": ["CVE-2023-5363"],
+  "severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N"}],
+  "affected": [
+    {
+      "package": {"ecosystem": "Debian:12", "name": "openssl"},
+      "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "3.0.11-1~deb12u2"}]}]
+    },
+    {
+      "package": {"ecosystem": "Debian:11", "name": "openssl"},
+      "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "1.1.1w-0+deb11u1"}]}]
+    }
+  ]
+}`
+
+const pypiEntry = `{
+  "id": "GHSA-j8r2-6x86-q33q",
+  "summary": "Unintended leak of Proxy-Authorization header in requests",
+  "database_specific": {"severity": "MODERATE"},
+  "affected": [
+    {
+      "package": {"ecosystem": "PyPI", "name": "requests"},
+      "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "2.3.0"}, {"fixed": "2.31.0"}]}],
+      "versions": ["2.3.0"]
+    }
+  ]
+}`
+
+const withdrawnEntry = `{
+  "id": "GHSA-withdrawn",
+  "withdrawn": "2024-01-01T00:00:00Z",
+  "affected": [
+    {
+      "package": {"ecosystem": "PyPI", "name": "requests"},
+      "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}]}]
+    }
+  ]
+}`
+
+func writeTestDatabase(t *testing.T) string {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "Debian"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Debian", "DSA-5532-1.json"), []byte(debianEntry), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "invalid.json"), []byte("{"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("OSV mirror"), 0644))
+
+	f, err := os.Create(filepath.Join(dir, "PyPI.zip"))
+	require.NoError(t, err)
+	archive := zip.NewWriter(f)
+	for name, content := range map[string]string{"GHSA-j8r2-6x86-q33q.json": pypiEntry, "GHSA-withdrawn.json": withdrawnEntry} {
+		w, err := archive.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, archive.Close())
+	require.NoError(t, f.Close())
+
+	return dir
+}
+
+func TestDatabaseMatch(t *testing.T) {
+	db, err := LoadDatabase(writeTestDatabase(t))
+	require.NoError(t, err)
+	assert.Equal(t, 2, db.Len())
+
+	assert.Equal(t, []Finding{{
+		VulnerabilityID: "DSA-5532-1",
+		Aliases:         []string{"CVE-2023-5363"},
+		Summary:         "openssl - security update",
+		Severity:        SeverityHigh,
+		Ecosystem:       "Debian",
+		PackageName:     "openssl",
+		PackageVersion:  "3.0.11-1~deb12u1",
+		FixedVersion:    "3.0.11-1~deb12u2",
+	}}, db.Match(Query{Ecosystem: "Debian", Name: "openssl", Version: "3.0.11-1~deb12u1", Release: "12.5"}))
+
+	// Fixed version
+	assert.Empty(t, db.Match(Query{Ecosystem: "Debian", Name: "openssl", Version: "3.0.11-1~deb12u2", Release: "12.5"}))
+	// Fixed in the matching release only
+	assert.Empty(t, db.Match(Query{Ecosystem: "Debian", Name: "openssl", Version: "1.1.1w-0+deb11u1", Release: "11.8"}))
+	// Unknown release matches all releases, without duplicated findings
+	assert.Len(t, db.Match(Query{Ecosystem: "Debian", Name: "openssl", Version: "1.1.1a-1"}), 1)
+
+	findings := db.Match(Query{Ecosystem: "PyPI", Name: "Requests", Version: "2.28.1"})
+	require.Len(t, findings, 1)
+	assert.Equal(t, SeverityMedium, findings[0].Severity)
+	assert.Equal(t, "2.31.0", findings[0].FixedVersion)
+
+	assert.Empty(t, db.Match(Query{Ecosystem: "PyPI", Name: "requests", Version: "2.2.0"}))
+	assert.Empty(t, db.Match(Query{Ecosystem: "npm", Name: "requests", Version: "2.28.1"}))
+}
+
+func TestMatcher(t *testing.T) {
+	dir := writeTestDatabase(t)
+	matcher, err := NewMatcher(dir)
+	require.NoError(t, err)
+
+	bom := &cyclonedx_v1_4.Bom{
+		Components: []*cyclonedx_v1_4.Component{
+			{
+				BomRef:  pointer.Ptr("pkg:deb/debian/libssl3@3.0.11-1~deb12u1?arch=amd64&distro=debian-12.5"),
+				Name:    "libssl3",
+				Version: "3.0.11-1~deb12u1",
+				Purl:    pointer.Ptr("pkg:deb/debian/libssl3@3.0.11-1~deb12u1?arch=amd64&distro=debian-12.5"),
+				Properties: []*cyclonedx_v1_4.Property{
+					{Name: "aquasecurity:trivy:SrcName", Value: pointer.Ptr("openssl")},
+					{Name: "aquasecurity:trivy:SrcVersion", Value: pointer.Ptr("3.0.11")},
+					{Name: "aquasecurity:trivy:SrcRelease", Value: pointer.Ptr("1~deb12u1")},
+				},
+			},
+			{
+				Name: "python",
+				Components: []*cyclonedx_v1_4.Component{
+					{
+						BomRef:  pointer.Ptr("requests-ref"),
+						Name:    "requests",
+						Version: "2.28.1",
+						Purl:    pointer.Ptr("pkg:pypi/requests@2.28.1"),
+					},
+				},
+			},
+		},
+	}
+
+	findings := matcher.MatchBOM(bom)
+	require.Len(t, findings, 2)
+	assert.Equal(t, "DSA-5532-1", findings[0].VulnerabilityID)
+	assert.Equal(t, "libssl3", findings[0].PackageName)
+	assert.Equal(t, "pkg:deb/debian/libssl3@3.0.11-1~deb12u1?arch=amd64&distro=debian-12.5", findings[0].BOMRef)
+	assert.Equal(t, "GHSA-j8r2-6x86-q33q", findings[1].VulnerabilityID)
+	assert.Equal(t, "requests-ref", findings[1].BOMRef)
+
+	AddToBOM(bom, findings)
+	require.Len(t, bom.Vulnerabilities, 2)
+	assert.Equal(t, "DSA-5532-1", bom.Vulnerabilities[0].GetId())
+	assert.Equal(t, cyclonedx_v1_4.Severity_SEVERITY_HIGH, bom.Vulnerabilities[0].GetRatings()[0].GetSeverity())
+	assert.Equal(t, "Upgrade libssl3 to 3.0.11-1~deb12u2", bom.Vulnerabilities[0].GetRecommendation())
+
+	// Nothing changed on disk
+	refreshed, err := matcher.Refresh()
+	require.NoError(t, err)
+	assert.False(t, refreshed)
+
+	// The Debian advisory is removed by the mirroring job
+	require.NoError(t, os.Remove(filepath.Join(dir, "Debian", "DSA-5532-1.json")))
+	future := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "PyPI.zip"), future, future))
+
+	refreshed, err = matcher.Refresh()
+	require.NoError(t, err)
+	assert.True(t, refreshed)
+	assert.Len(t, matcher.MatchBOM(bom), 1)
+}
+
+func TestParsePackageURL(t *testing.T) {
+	purl, ok := parsePackageURL("pkg:golang/github.com/DataDog/datadog-agent@v7.50.0?type=module")
+	require.True(t, ok)
+	ecosystem, name, release := purl.ecosystem()
+	assert.Equal(t, "Go", ecosystem)
+	assert.Equal(t, "github.com/DataDog/datadog-agent", name)
+	assert.Empty(t, release)
+	assert.Equal(t, "v7.50.0", purl.Version)
+
+	purl, ok = parsePackageURL("pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1")
+	require.True(t, ok)
+	ecosystem, name, _ = purl.ecosystem()
+	assert.Equal(t, "Maven", ecosystem)
+	assert.Equal(t, "org.apache.logging.log4j:log4j-core", name)
+
+	purl, ok = parsePackageURL("pkg:apk/alpine/musl@1.2.5-r0?arch=x86_64&distro=3.20.3")
+	require.True(t, ok)
+	ecosystem, _, release = purl.ecosystem()
+	assert.Equal(t, "Alpine", ecosystem)
+	assert.True(t, releaseMatches("v3.20", release))
+	assert.False(t, releaseMatches("v3.2", release))
+
+	assert.True(t, releaseMatches("Pro:22.04:LTS", "22.04"))
+
+	_, ok = parsePackageURL("not-a-purl")
+	assert.False(t, ok)
+}
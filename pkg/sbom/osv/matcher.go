@@ -0,0 +1,186 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package osv
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/DataDog/agent-payload/v5/cyclonedx_v1_4"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Properties set by trivy on OS packages to describe the source package they are built from.
+// Distribution advisories are usually published for source packages.
+const (
+	trivySrcNameProperty    = "aquasecurity:trivy:SrcName"
+	trivySrcVersionProperty = "aquasecurity:trivy:SrcVersion"
+	trivySrcReleaseProperty = "aquasecurity:trivy:SrcRelease"
+	trivySrcEpochProperty   = "aquasecurity:trivy:SrcEpoch"
+)
+
+// Matcher matches SBOMs against an OSV database stored on disk. The database can be
+// refreshed when its files are updated, for instance by a mirroring job.
+type Matcher struct {
+	path string
+
+	mu sync.RWMutex
+	db *Database
+}
+
+// NewMatcher creates a matcher and loads the database stored at path
+func NewMatcher(path string) (*Matcher, error) {
+	db, err := LoadDatabase(path)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Loaded %d vulnerabilities from %s", db.Len(), path)
+	return &Matcher{
+		path: path,
+		db:   db,
+	}, nil
+}
+
+// Refresh reloads the database if its files were modified since it was loaded.
+// The current database is kept if the new one cannot be loaded.
+func (m *Matcher) Refresh() (bool, error) {
+	modTime, err := latestModTime(m.path)
+	if err != nil {
+		return false, err
+	}
+
+	m.mu.RLock()
+	upToDate := !modTime.After(m.db.ModTime())
+	m.mu.RUnlock()
+	if upToDate {
+		return false, nil
+	}
+
+	db, err := LoadDatabase(m.path)
+	if err != nil {
+		return false, err
+	}
+
+	m.mu.Lock()
+	m.db = db
+	m.mu.Unlock()
+
+	log.Infof("Reloaded %d vulnerabilities from %s", db.Len(), m.path)
+	return true, nil
+}
+
+// MatchBOM returns the vulnerabilities affecting the components of an SBOM
+func (m *Matcher) MatchBOM(bom *cyclonedx_v1_4.Bom) []Finding {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return MatchBOM(m.db, bom)
+}
+
+// MatchBOM returns the vulnerabilities of db affecting the components of an SBOM
+func MatchBOM(db *Database, bom *cyclonedx_v1_4.Bom) []Finding {
+	var findings []Finding
+	var walk func(components []*cyclonedx_v1_4.Component)
+	walk = func(components []*cyclonedx_v1_4.Component) {
+		for _, component := range components {
+			findings = append(findings, matchComponent(db, component)...)
+			walk(component.GetComponents())
+		}
+	}
+	walk(bom.GetComponents())
+
+	return findings
+}
+
+func matchComponent(db *Database, component *cyclonedx_v1_4.Component) []Finding {
+	purl, ok := parsePackageURL(component.GetPurl())
+	if !ok {
+		return nil
+	}
+
+	ecosystem, name, release := purl.ecosystem()
+	if ecosystem == "" {
+		return nil
+	}
+
+	version := purl.Version
+	if version == "" {
+		version = component.GetVersion()
+	}
+
+	queries := []Query{{Ecosystem: ecosystem, Name: name, Version: version, Release: release}}
+	if source := sourcePackageQuery(component, ecosystem, release); source != nil && source.Name != name {
+		queries = append(queries, *source)
+	}
+
+	var findings []Finding
+	for _, q := range queries {
+		for _, finding := range db.Match(q) {
+			if slices.ContainsFunc(findings, func(f Finding) bool { return f.VulnerabilityID == finding.VulnerabilityID }) {
+				continue
+			}
+
+			// Findings are reported against the installed package even when
+			// matched through its source package
+			finding.PackageName = name
+			finding.PackageVersion = version
+			finding.BOMRef = component.GetBomRef()
+			findings = append(findings, finding)
+		}
+	}
+
+	return findings
+}
+
+func sourcePackageQuery(component *cyclonedx_v1_4.Component, ecosystem string, release string) *Query {
+	properties := make(map[string]string)
+	for _, property := range component.GetProperties() {
+		properties[property.GetName()] = property.GetValue()
+	}
+
+	name := properties[trivySrcNameProperty]
+	version := properties[trivySrcVersionProperty]
+	if name == "" || version == "" {
+		return nil
+	}
+
+	if srcRelease := properties[trivySrcReleaseProperty]; srcRelease != "" {
+		version += "-" + srcRelease
+	}
+	if epoch := properties[trivySrcEpochProperty]; epoch != "" && epoch != "0" {
+		version = epoch + ":" + version
+	}
+
+	return &Query{Ecosystem: ecosystem, Name: name, Version: version, Release: release}
+}
+
+func latestModTime(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unable to open vulnerability database: %w", err)
+	}
+	if !info.IsDir() {
+		return info.ModTime(), nil
+	}
+
+	var modTime time.Time
+	err = filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+		return nil
+	})
+	return modTime, err
+}
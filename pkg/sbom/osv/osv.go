@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+// Package osv matches SBOM components against an offline vulnerability database
+// in the OSV format (https://ossf.github.io/osv-schema/).
+package osv
+
+// Range types defined by the OSV schema
+const (
+	RangeTypeSemver    = "SEMVER"
+	RangeTypeEcosystem = "ECOSYSTEM"
+	RangeTypeGit       = "GIT"
+)
+
+// Vulnerability is an OSV vulnerability entry
+type Vulnerability struct {
+	ID               string           `json:"id"`
+	Summary          string           `json:"summary,omitempty"`
+	Details          string           `json:"details,omitempty"`
+	Aliases          []string         `json:"aliases,omitempty"`
+	Modified         string           `json:"modified,omitempty"`
+	Withdrawn        string           `json:"withdrawn,omitempty"`
+	Severity         []Severity       `json:"severity,omitempty"`
+	Affected         []Affected       `json:"affected,omitempty"`
+	DatabaseSpecific DatabaseSpecific `json:"database_specific,omitempty"`
+}
+
+// Severity is a severity score attached to an OSV vulnerability
+type Severity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+// DatabaseSpecific holds the database specific fields used by the matcher
+type DatabaseSpecific struct {
+	Severity string `json:"severity,omitempty"`
+}
+
+// Affected describes a package affected by an OSV vulnerability
+type Affected struct {
+	Package          Package          `json:"package"`
+	Severity         []Severity       `json:"severity,omitempty"`
+	Ranges           []Range          `json:"ranges,omitempty"`
+	Versions         []string         `json:"versions,omitempty"`
+	DatabaseSpecific DatabaseSpecific `json:"database_specific,omitempty"`
+}
+
+// Package identifies an affected package
+type Package struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+	Purl      string `json:"purl,omitempty"`
+}
+
+// Range is a range of affected versions
+type Range struct {
+	Type   string  `json:"type"`
+	Events []Event `json:"events"`
+}
+
+// Event is a version boundary of an affected range
+type Event struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
@@ -0,0 +1,178 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package osv
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var pypiNameSeparators = regexp.MustCompile(`[-_.]+`)
+
+// packageURL holds the parts of a package URL (https://github.com/package-url/purl-spec)
+// needed to find the OSV ecosystem of a package
+type packageURL struct {
+	Type       string
+	Namespace  string
+	Name       string
+	Version    string
+	Qualifiers url.Values
+}
+
+func parsePackageURL(purl string) (packageURL, bool) {
+	remainder, ok := strings.CutPrefix(purl, "pkg:")
+	if !ok {
+		return packageURL{}, false
+	}
+
+	remainder, _, _ = strings.Cut(remainder, "#")
+
+	var p packageURL
+	remainder, rawQualifiers, _ := strings.Cut(remainder, "?")
+	if rawQualifiers != "" {
+		qualifiers, err := url.ParseQuery(rawQualifiers)
+		if err != nil {
+			return packageURL{}, false
+		}
+		p.Qualifiers = qualifiers
+	}
+
+	if i := strings.LastIndexByte(remainder, '@'); i >= 0 {
+		p.Version, _ = url.PathUnescape(remainder[i+1:])
+		remainder = remainder[:i]
+	}
+
+	parts := strings.Split(strings.Trim(remainder, "/"), "/")
+	if len(parts) < 2 {
+		return packageURL{}, false
+	}
+
+	p.Type = strings.ToLower(parts[0])
+	p.Name, _ = url.PathUnescape(parts[len(parts)-1])
+	namespace := make([]string, 0, len(parts)-2)
+	for _, part := range parts[1 : len(parts)-1] {
+		unescaped, _ := url.PathUnescape(part)
+		namespace = append(namespace, unescaped)
+	}
+	p.Namespace = strings.Join(namespace, "/")
+
+	return p, p.Name != ""
+}
+
+// ecosystem returns the OSV ecosystem and package name matching the package URL,
+// along with the distribution release when known
+func (p packageURL) ecosystem() (ecosystem string, name string, release string) {
+	name = p.Name
+	switch p.Type {
+	case "deb":
+		switch strings.ToLower(p.Namespace) {
+		case "debian":
+			ecosystem = "Debian"
+		case "ubuntu":
+			ecosystem = "Ubuntu"
+		}
+	case "apk":
+		switch strings.ToLower(p.Namespace) {
+		case "alpine":
+			ecosystem = "Alpine"
+		case "wolfi":
+			ecosystem = "Wolfi"
+		case "chainguard":
+			ecosystem = "Chainguard"
+		}
+	case "rpm":
+		switch strings.ToLower(p.Namespace) {
+		case "redhat":
+			ecosystem = "Red Hat"
+		case "rocky":
+			ecosystem = "Rocky Linux"
+		case "almalinux", "alma":
+			ecosystem = "AlmaLinux"
+		case "opensuse":
+			ecosystem = "openSUSE"
+		case "suse", "sles":
+			ecosystem = "SUSE"
+		}
+	case "pypi":
+		ecosystem = "PyPI"
+	case "npm":
+		ecosystem = "npm"
+		if p.Namespace != "" {
+			name = p.Namespace + "/" + p.Name
+		}
+	case "golang":
+		ecosystem = "Go"
+		if p.Namespace != "" {
+			name = p.Namespace + "/" + p.Name
+		}
+	case "maven":
+		ecosystem = "Maven"
+		if p.Namespace != "" {
+			name = p.Namespace + ":" + p.Name
+		}
+	case "cargo":
+		ecosystem = "crates.io"
+	case "gem":
+		ecosystem = "RubyGems"
+	case "nuget":
+		ecosystem = "NuGet"
+	case "composer":
+		ecosystem = "Packagist"
+		if p.Namespace != "" {
+			name = p.Namespace + "/" + p.Name
+		}
+	case "hex":
+		ecosystem = "Hex"
+	case "pub":
+		ecosystem = "Pub"
+	}
+
+	if distro := p.Qualifiers.Get("distro"); distro != "" {
+		// Distro qualifiers are either `<distro>-<release>` or only `<release>`
+		if i := strings.LastIndexByte(distro, '-'); i >= 0 {
+			distro = distro[i+1:]
+		}
+		release = distro
+	}
+
+	return ecosystem, name, release
+}
+
+// normalizePackageName normalizes package names for ecosystems where
+// several spellings designate the same package
+func normalizePackageName(ecosystem string, name string) string {
+	switch ecosystem {
+	case "PyPI":
+		return pypiNameSeparators.ReplaceAllString(strings.ToLower(name), "-")
+	case "NuGet", "Packagist":
+		return strings.ToLower(name)
+	default:
+		return name
+	}
+}
+
+// releaseMatches returns whether the release of an OSV ecosystem such as `Debian:12`, `Alpine:v3.20`
+// or `Ubuntu:Pro:22.04:LTS` matches the release of a distribution such as `12.5`, `3.20.3` or `22.04`.
+// Ecosystems without a release, or packages without a known release, always match.
+func releaseMatches(osvRelease string, release string) bool {
+	if osvRelease == "" || release == "" {
+		return true
+	}
+
+	osvRelease = strings.TrimPrefix(osvRelease, "Pro:")
+	osvRelease = strings.TrimSuffix(osvRelease, ":LTS")
+	osvRelease = strings.TrimPrefix(osvRelease, "v")
+	release = strings.TrimPrefix(release, "v")
+
+	return release == osvRelease || strings.HasPrefix(release, osvRelease+".")
+}
+
+// splitEcosystem splits an OSV ecosystem into its base name and its release
+func splitEcosystem(ecosystem string) (string, string) {
+	base, release, _ := strings.Cut(ecosystem, ":")
+	return base, release
+}
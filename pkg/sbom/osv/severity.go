@@ -0,0 +1,187 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package osv
+
+import (
+	"math"
+	"strings"
+)
+
+// SeverityLevel is the severity of a finding
+type SeverityLevel string
+
+// Severity levels, from the least to the most severe
+const (
+	SeverityUnknown  SeverityLevel = "unknown"
+	SeverityLow      SeverityLevel = "low"
+	SeverityMedium   SeverityLevel = "medium"
+	SeverityHigh     SeverityLevel = "high"
+	SeverityCritical SeverityLevel = "critical"
+)
+
+// SeverityLevels lists all severity levels, from the least to the most severe
+var SeverityLevels = []SeverityLevel{SeverityUnknown, SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+func (s SeverityLevel) rank() int {
+	for i, level := range SeverityLevels {
+		if level == s {
+			return i
+		}
+	}
+	return 0
+}
+
+// MoreSevereThan returns whether s is more severe than other
+func (s SeverityLevel) MoreSevereThan(other SeverityLevel) bool {
+	return s.rank() > other.rank()
+}
+
+// severityOf computes the severity of a vulnerability affecting a package. The database specific
+// severity (used by GitHub advisories) is preferred, then the highest CVSS v3 score and finally
+// the textual severities published by some distributions.
+func severityOf(vuln *Vulnerability, affected *Affected) SeverityLevel {
+	for _, databaseSpecific := range []DatabaseSpecific{affected.DatabaseSpecific, vuln.DatabaseSpecific} {
+		if level := parseSeverityLevel(databaseSpecific.Severity); level != SeverityUnknown {
+			return level
+		}
+	}
+
+	severities := append(append([]Severity{}, affected.Severity...), vuln.Severity...)
+
+	score := -1.0
+	for _, severity := range severities {
+		if severity.Type != "CVSS_V3" {
+			continue
+		}
+		if s, ok := cvss3BaseScore(severity.Score); ok && s > score {
+			score = s
+		}
+	}
+	if score >= 0 {
+		return cvssScoreToLevel(score)
+	}
+
+	level := SeverityUnknown
+	for _, severity := range severities {
+		if l := parseSeverityLevel(severity.Score); l.MoreSevereThan(level) {
+			level = l
+		}
+	}
+	return level
+}
+
+func parseSeverityLevel(s string) SeverityLevel {
+	switch strings.ToLower(s) {
+	case "negligible", "low", "unimportant":
+		return SeverityLow
+	case "medium", "moderate":
+		return SeverityMedium
+	case "high", "important":
+		return SeverityHigh
+	case "critical":
+		return SeverityCritical
+	default:
+		return SeverityUnknown
+	}
+}
+
+func cvssScoreToLevel(score float64) SeverityLevel {
+	switch {
+	case score >= 9.0:
+		return SeverityCritical
+	case score >= 7.0:
+		return SeverityHigh
+	case score >= 4.0:
+		return SeverityMedium
+	case score > 0:
+		return SeverityLow
+	default:
+		return SeverityUnknown
+	}
+}
+
+var cvss3Weights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+// cvss3BaseScore computes the base score of a CVSS v3.x vector such as
+// `CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H`
+func cvss3BaseScore(vector string) (float64, bool) {
+	parts := strings.Split(vector, "/")
+	if len(parts) == 0 || !strings.HasPrefix(parts[0], "CVSS:3") {
+		return 0, false
+	}
+
+	metrics := make(map[string]string, len(parts)-1)
+	for _, part := range parts[1:] {
+		if name, value, ok := strings.Cut(part, ":"); ok {
+			metrics[name] = value
+		}
+	}
+
+	scopeChanged := metrics["S"] == "C"
+	if !scopeChanged && metrics["S"] != "U" {
+		return 0, false
+	}
+
+	weights := make(map[string]float64, len(cvss3Weights))
+	for name, values := range cvss3Weights {
+		weight, ok := values[metrics[name]]
+		if !ok {
+			return 0, false
+		}
+		weights[name] = weight
+	}
+
+	var privileges float64
+	switch metrics["PR"] {
+	case "N":
+		privileges = 0.85
+	case "L":
+		privileges = 0.62
+		if scopeChanged {
+			privileges = 0.68
+		}
+	case "H":
+		privileges = 0.27
+		if scopeChanged {
+			privileges = 0.5
+		}
+	default:
+		return 0, false
+	}
+
+	iss := 1 - (1-weights["C"])*(1-weights["I"])*(1-weights["A"])
+	var impact float64
+	if scopeChanged {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	} else {
+		impact = 6.42 * iss
+	}
+	exploitability := 8.22 * weights["AV"] * weights["AC"] * privileges * weights["UI"]
+
+	if impact <= 0 {
+		return 0, true
+	}
+	if scopeChanged {
+		return cvssRoundUp(math.Min(1.08*(impact+exploitability), 10)), true
+	}
+	return cvssRoundUp(math.Min(impact+exploitability, 10)), true
+}
+
+// cvssRoundUp rounds up to one decimal, as defined in the CVSS v3.1 specification
+func cvssRoundUp(value float64) float64 {
+	intValue := int64(math.Round(value * 100000))
+	if intValue%10000 == 0 {
+		return float64(intValue) / 100000
+	}
+	return (math.Floor(float64(intValue)/10000) + 1) / 10
+}
@@ -0,0 +1,54 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package osv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCVSS3BaseScore(t *testing.T) {
+	tests := []struct {
+		vector   string
+		expected float64
+		valid    bool
+	}{
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 9.8, true},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H", 10.0, true},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N", 6.1, true},
+		{"CVSS:3.0/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:N/A:N", 5.5, true},
+		{"CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:N/I:N/A:N", 0, true},
+		{"CVSS:2.0/AV:N/AC:L/Au:N/C:P/I:P/A:P", 0, false},
+		{"CVSS:3.1/AV:N/AC:L", 0, false},
+	}
+
+	for _, test := range tests {
+		score, ok := cvss3BaseScore(test.vector)
+		assert.Equal(t, test.valid, ok, test.vector)
+		assert.InDelta(t, test.expected, score, 0.001, test.vector)
+	}
+}
+
+func TestSeverityOf(t *testing.T) {
+	assert.Equal(t, SeverityMedium, severityOf(&Vulnerability{
+		DatabaseSpecific: DatabaseSpecific{Severity: "MODERATE"},
+		Severity:         []Severity{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}},
+	}, &Affected{}))
+
+	assert.Equal(t, SeverityCritical, severityOf(&Vulnerability{
+		Severity: []Severity{
+			{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N"},
+			{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"},
+		},
+	}, &Affected{}))
+
+	assert.Equal(t, SeverityLow, severityOf(&Vulnerability{
+		Severity: []Severity{{Type: "Ubuntu", Score: "negligible"}},
+	}, &Affected{}))
+
+	assert.Equal(t, SeverityUnknown, severityOf(&Vulnerability{}, &Affected{}))
+}
@@ -0,0 +1,573 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package osv
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// compareFunc returns a negative number if a < b, zero if a == b and a positive number if a > b
+type compareFunc func(a, b string) int
+
+// comparerForEcosystem returns the version comparison function of an OSV ecosystem, or nil
+// when the ecosystem has no supported version scheme, in which case its `ECOSYSTEM` ranges
+// cannot be evaluated. The ecosystem is expected without its release suffix (`Debian` and
+// not `Debian:12`).
+func comparerForEcosystem(ecosystem string) compareFunc {
+	switch ecosystem {
+	case "Debian", "Ubuntu":
+		return compareDpkg
+	case "Red Hat", "Rocky Linux", "AlmaLinux", "openSUSE", "SUSE", "Mageia", "openEuler":
+		return compareRpm
+	case "Alpine", "Wolfi", "Chainguard":
+		return compareApk
+	case "PyPI":
+		return comparePEP440
+	case "Maven":
+		return compareMaven
+	case "npm", "Go", "crates.io", "Hex", "Pub", "NuGet", "RubyGems", "SwiftURL":
+		// Semantic versions, or schemes where letters only denote pre-releases
+		return compareGeneric
+	default:
+		return nil
+	}
+}
+
+// compareDpkg compares two Debian versions, as described in deb-version(7)
+func compareDpkg(a, b string) int {
+	aEpoch, aUpstream, aRevision := splitDpkgVersion(a)
+	bEpoch, bUpstream, bRevision := splitDpkgVersion(b)
+
+	if aEpoch != bEpoch {
+		return aEpoch - bEpoch
+	}
+	if c := compareDpkgPart(aUpstream, bUpstream); c != 0 {
+		return c
+	}
+	return compareDpkgPart(aRevision, bRevision)
+}
+
+func splitDpkgVersion(v string) (epoch int, upstream string, revision string) {
+	if i := strings.IndexByte(v, ':'); i >= 0 {
+		epoch, _ = strconv.Atoi(v[:i])
+		v = v[i+1:]
+	}
+	if i := strings.LastIndexByte(v, '-'); i >= 0 {
+		return epoch, v[:i], v[i+1:]
+	}
+	return epoch, v, ""
+}
+
+func dpkgOrder(c byte) int {
+	switch {
+	case c == '~':
+		return -1
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		return int(c)
+	default:
+		return int(c) + 256
+	}
+}
+
+func compareDpkgPart(a, b string) int {
+	for a != "" || b != "" {
+		// Non digit prefixes are compared character by character, letters sort before non-letters
+		// and `~` sorts before everything, even the end of the part.
+		for (a != "" && !isDigit(a[0])) || (b != "" && !isDigit(b[0])) {
+			ac, bc := 0, 0
+			if a != "" && !isDigit(a[0]) {
+				ac = dpkgOrder(a[0])
+			}
+			if b != "" && !isDigit(b[0]) {
+				bc = dpkgOrder(b[0])
+			}
+			if ac != bc {
+				return ac - bc
+			}
+			a, b = a[1:], b[1:]
+		}
+
+		var aNum, bNum string
+		aNum, a = splitDigits(a)
+		bNum, b = splitDigits(b)
+		if c := compareNumeric(aNum, bNum); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// compareRpm compares two RPM versions, following rpmvercmp
+func compareRpm(a, b string) int {
+	aEpoch, aVersion := splitRpmEpoch(a)
+	bEpoch, bVersion := splitRpmEpoch(b)
+	if aEpoch != bEpoch {
+		return aEpoch - bEpoch
+	}
+
+	aVersion, aRelease, _ := strings.Cut(aVersion, "-")
+	bVersion, bRelease, _ := strings.Cut(bVersion, "-")
+	if c := rpmvercmp(aVersion, bVersion); c != 0 {
+		return c
+	}
+	if aRelease == "" || bRelease == "" {
+		return 0
+	}
+	return rpmvercmp(aRelease, bRelease)
+}
+
+func splitRpmEpoch(v string) (int, string) {
+	if i := strings.IndexByte(v, ':'); i >= 0 {
+		epoch, _ := strconv.Atoi(v[:i])
+		return epoch, v[i+1:]
+	}
+	return 0, v
+}
+
+func rpmvercmp(a, b string) int {
+	for a != "" || b != "" {
+		a = strings.TrimLeftFunc(a, isRpmSeparator)
+		b = strings.TrimLeftFunc(b, isRpmSeparator)
+
+		// `~` sorts before everything, `^` sorts after the end of the version only
+		if strings.HasPrefix(a, "~") || strings.HasPrefix(b, "~") {
+			if !strings.HasPrefix(a, "~") {
+				return 1
+			}
+			if !strings.HasPrefix(b, "~") {
+				return -1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+		if strings.HasPrefix(a, "^") || strings.HasPrefix(b, "^") {
+			if a == "" {
+				return -1
+			}
+			if b == "" {
+				return 1
+			}
+			if !strings.HasPrefix(a, "^") {
+				return 1
+			}
+			if !strings.HasPrefix(b, "^") {
+				return -1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+
+		if a == "" || b == "" {
+			break
+		}
+
+		var aSegment, bSegment string
+		if isDigit(a[0]) {
+			aSegment, a = splitDigits(a)
+			bSegment, b = splitDigits(b)
+			if bSegment == "" {
+				// Numeric segments are newer than alpha segments
+				return 1
+			}
+			if c := compareNumeric(aSegment, bSegment); c != 0 {
+				return c
+			}
+		} else {
+			aSegment, a = splitLetters(a)
+			bSegment, b = splitLetters(b)
+			if bSegment == "" {
+				return -1
+			}
+			if c := strings.Compare(aSegment, bSegment); c != 0 {
+				return c
+			}
+		}
+	}
+
+	switch {
+	case a == "" && b == "":
+		return 0
+	case a == "":
+		return -1
+	default:
+		return 1
+	}
+}
+
+func isRpmSeparator(r rune) bool {
+	return r != '~' && r != '^' && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// compareApk compares two Alpine package versions. The `-rN` package release
+// is compared after the upstream version.
+func compareApk(a, b string) int {
+	aVersion, aRelease := splitApkRelease(a)
+	bVersion, bRelease := splitApkRelease(b)
+	if c := compareGeneric(aVersion, bVersion); c != 0 {
+		return c
+	}
+	return aRelease - bRelease
+}
+
+func splitApkRelease(v string) (string, int) {
+	if i := strings.LastIndex(v, "-r"); i >= 0 {
+		if release, err := strconv.Atoi(v[i+2:]); err == nil {
+			return v[:i], release
+		}
+	}
+	return v, 0
+}
+
+// compareGeneric compares versions made of numeric and alphabetic segments, such as semantic
+// versions and most language ecosystems versions. Segments are compared pairwise, numerically
+// when both are numbers. When one version is a prefix of the other, the longer one is older if
+// its next segment is alphabetic (a pre-release such as `1.0.0-rc1`) and newer otherwise.
+func compareGeneric(a, b string) int {
+	aSegments := versionSegments(a)
+	bSegments := versionSegments(b)
+
+	for i := 0; i < len(aSegments) && i < len(bSegments); i++ {
+		aSegment, bSegment := aSegments[i], bSegments[i]
+		aNumeric, bNumeric := isDigit(aSegment[0]), isDigit(bSegment[0])
+
+		switch {
+		case aNumeric && bNumeric:
+			if c := compareNumeric(aSegment, bSegment); c != 0 {
+				return c
+			}
+		case aNumeric:
+			return 1
+		case bNumeric:
+			return -1
+		default:
+			if c := strings.Compare(strings.ToLower(aSegment), strings.ToLower(bSegment)); c != 0 {
+				return c
+			}
+		}
+	}
+
+	switch {
+	case len(aSegments) == len(bSegments):
+		return 0
+	case len(aSegments) > len(bSegments):
+		if isDigit(aSegments[len(bSegments)][0]) {
+			return 1
+		}
+		return -1
+	default:
+		if isDigit(bSegments[len(aSegments)][0]) {
+			return -1
+		}
+		return 1
+	}
+}
+
+// pep440Version is a Python version, as described in PEP 440. Pre-release, post-release and
+// development release numbers are -1 when absent.
+type pep440Version struct {
+	epoch   int
+	release []string
+	preTag  int
+	pre     int
+	post    int
+	dev     int
+}
+
+// pep440PreTags ranks the pre-release tags, including their alternate spellings
+var pep440PreTags = map[string]int{
+	"a": 0, "alpha": 0,
+	"b": 1, "beta": 1,
+	"c": 2, "rc": 2, "pre": 2, "preview": 2,
+}
+
+// comparePEP440 compares two Python versions, following PEP 440: development releases sort
+// before pre-releases, which sort before the final release, which sorts before post-releases.
+// Local version labels (`+local`) are ignored.
+func comparePEP440(a, b string) int {
+	av, bv := parsePEP440(a), parsePEP440(b)
+	if av.epoch != bv.epoch {
+		return av.epoch - bv.epoch
+	}
+
+	for i := 0; i < len(av.release) || i < len(bv.release); i++ {
+		aPart, bPart := "0", "0"
+		if i < len(av.release) {
+			aPart = av.release[i]
+		}
+		if i < len(bv.release) {
+			bPart = bv.release[i]
+		}
+		if c := compareNumeric(aPart, bPart); c != 0 {
+			return c
+		}
+	}
+
+	aPre, bPre := av.preKey(), bv.preKey()
+	for i := range aPre {
+		if aPre[i] != bPre[i] {
+			return aPre[i] - bPre[i]
+		}
+	}
+	if av.post != bv.post {
+		return av.post - bv.post
+	}
+	return av.devKey() - bv.devKey()
+}
+
+// preKey orders the pre-release of a version: a development release without pre-release or
+// post-release sorts before every pre-release, and a version without pre-release after them.
+func (v pep440Version) preKey() [2]int {
+	switch {
+	case v.preTag >= 0:
+		return [2]int{v.preTag, v.pre}
+	case v.dev >= 0 && v.post < 0:
+		return [2]int{-1, 0}
+	default:
+		return [2]int{len(pep440PreTags), 0}
+	}
+}
+
+// devKey orders the development release of a version, which sorts before the version itself
+func (v pep440Version) devKey() int {
+	if v.dev < 0 {
+		return math.MaxInt
+	}
+	return v.dev
+}
+
+func parsePEP440(v string) pep440Version {
+	version := pep440Version{preTag: -1, pre: -1, post: -1, dev: -1}
+
+	v = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(v)), "v")
+	v, _, _ = strings.Cut(v, "+")
+	if epoch, rest, ok := strings.Cut(v, "!"); ok {
+		version.epoch, _ = strconv.Atoi(epoch)
+		v = rest
+	}
+
+	for v != "" {
+		var part string
+		part, v = splitDigits(v)
+		if part == "" {
+			break
+		}
+		version.release = append(version.release, part)
+		if !strings.HasPrefix(v, ".") || len(v) < 2 || !isDigit(v[1]) {
+			break
+		}
+		v = v[1:]
+	}
+
+	for v != "" {
+		v = strings.TrimLeft(v, ".-_")
+		if v == "" {
+			break
+		}
+		if isDigit(v[0]) {
+			// An implicit post-release, such as `1.0-1`
+			var number string
+			number, v = splitDigits(v)
+			version.post, _ = strconv.Atoi(number)
+			continue
+		}
+
+		var tag, number string
+		tag, v = splitLetters(v)
+		if tag == "" {
+			// Not a valid version, the rest is ignored
+			break
+		}
+		number, v = splitDigits(strings.TrimLeft(v, ".-_"))
+		n, _ := strconv.Atoi(number)
+		switch tag {
+		case "post", "rev", "r":
+			version.post = n
+		case "dev":
+			version.dev = n
+		default:
+			if rank, ok := pep440PreTags[tag]; ok {
+				version.preTag, version.pre = rank, n
+			}
+		}
+	}
+	return version
+}
+
+// mavenItem is a segment of a Maven version, either a number or a qualifier
+type mavenItem struct {
+	number    string
+	qualifier string
+}
+
+// mavenQualifiers ranks the well-known Maven qualifiers. Unknown qualifiers sort after all of
+// them, in lexical order.
+var mavenQualifiers = map[string]int{
+	"alpha": 0, "a": 0,
+	"beta": 1, "b": 1,
+	"milestone": 2, "m": 2,
+	"rc": 3, "cr": 3,
+	"snapshot": 4,
+	"":         5, "ga": 5, "final": 5, "release": 5,
+	"sp": 6,
+}
+
+// compareMaven compares two Maven versions, following the ordering of Maven's ComparableVersion:
+// numbers are compared numerically and sort after qualifiers, missing segments are equivalent to
+// `0` or to the release, and qualifiers are ordered alpha < beta < milestone < rc < snapshot <
+// release < sp < unknown qualifiers. Nested lists of the Maven ordering are not distinguished.
+func compareMaven(a, b string) int {
+	aItems, bItems := mavenItems(a), mavenItems(b)
+	for i := 0; i < len(aItems) || i < len(bItems); i++ {
+		var aItem, bItem mavenItem
+		if i < len(aItems) {
+			aItem = aItems[i]
+		} else {
+			aItem = mavenPadding(bItems[i])
+		}
+		if i < len(bItems) {
+			bItem = bItems[i]
+		} else {
+			bItem = mavenPadding(aItems[i])
+		}
+		if c := compareMavenItems(aItem, bItem); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// mavenPadding returns the item compared to other when the version has fewer items
+func mavenPadding(other mavenItem) mavenItem {
+	if other.number != "" {
+		return mavenItem{number: "0"}
+	}
+	return mavenItem{}
+}
+
+func compareMavenItems(a, b mavenItem) int {
+	switch {
+	case a.number != "" && b.number != "":
+		return compareNumeric(a.number, b.number)
+	case a.number != "":
+		return 1
+	case b.number != "":
+		return -1
+	}
+
+	aRank, aKnown := mavenQualifiers[a.qualifier]
+	bRank, bKnown := mavenQualifiers[b.qualifier]
+	switch {
+	case aKnown && bKnown:
+		return aRank - bRank
+	case aKnown:
+		return -1
+	case bKnown:
+		return 1
+	default:
+		return strings.Compare(a.qualifier, b.qualifier)
+	}
+}
+
+func mavenItems(v string) []mavenItem {
+	v = strings.ToLower(strings.TrimSpace(v))
+
+	var items []mavenItem
+	for v != "" {
+		var segment string
+		switch {
+		case isDigit(v[0]):
+			segment, v = splitDigits(v)
+			items = append(items, mavenItem{number: segment})
+		case v[0] == '.' || v[0] == '-' || v[0] == '_':
+			v = v[1:]
+		default:
+			i := 0
+			for i < len(v) && !isDigit(v[i]) && v[i] != '.' && v[i] != '-' && v[i] != '_' {
+				i++
+			}
+			segment, v = v[:i], v[i:]
+			items = append(items, mavenItem{qualifier: segment})
+		}
+	}
+
+	// Zeros before a qualifier or at the end, and trailing release qualifiers, don't change
+	// the version: `1.0.0-alpha` is `1-alpha` and `1.0.ga` is `1`.
+	var trimmed []mavenItem
+	for i := len(items) - 1; i >= 0; i-- {
+		item := items[i]
+		isZero := item.number != "" && strings.Trim(item.number, "0") == ""
+		nextIsQualifier := len(trimmed) == 0 || trimmed[len(trimmed)-1].number == ""
+		if len(trimmed) == 0 && item.number == "" && mavenQualifiers[item.qualifier] == mavenQualifiers[""] {
+			continue
+		}
+		if isZero && nextIsQualifier {
+			continue
+		}
+		trimmed = append(trimmed, item)
+	}
+	slices.Reverse(trimmed)
+	items = trimmed
+	return items
+}
+
+func versionSegments(v string) []string {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	// Build metadata does not take part in the comparison
+	v, _, _ = strings.Cut(v, "+")
+
+	var segments []string
+	for v != "" {
+		var segment string
+		switch {
+		case isDigit(v[0]):
+			segment, v = splitDigits(v)
+		case isLetter(v[0]):
+			segment, v = splitLetters(v)
+		default:
+			v = v[1:]
+			continue
+		}
+		segments = append(segments, segment)
+	}
+	return segments
+}
+
+func compareNumeric(a, b string) int {
+	a = strings.TrimLeft(a, "0")
+	b = strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+	return strings.Compare(a, b)
+}
+
+func splitDigits(v string) (string, string) {
+	i := 0
+	for i < len(v) && isDigit(v[i]) {
+		i++
+	}
+	return v[:i], v[i:]
+}
+
+func splitLetters(v string) (string, string) {
+	i := 0
+	for i < len(v) && isLetter(v[i]) {
+		i++
+	}
+	return v[:i], v[i:]
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,108 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package osv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sign(v int) int {
+	switch {
+	case v < 0:
+		return -1
+	case v > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		ecosystem string
+		a         string
+		b         string
+		expected  int
+	}{
+		{"Debian", "1.2.3-1", "1.2.3-1", 0},
+		{"Debian", "1.2.3-1", "1.2.3-2", -1},
+		{"Debian", "1.2.10-1", "1.2.9-1", 1},
+		{"Debian", "1:1.0-1", "2.0-1", 1},
+		{"Debian", "1.0~rc1-1", "1.0-1", -1},
+		{"Debian", "3.0.11-1~deb12u2", "3.0.11-1", -1},
+		{"Debian", "2.36-9+deb12u4", "2.36-9+deb12u10", -1},
+		{"Debian", "1.0a", "1.0+", -1},
+		{"Red Hat", "1.1.1k-7.el8", "1.1.1k-12.el8", -1},
+		{"Red Hat", "1:3.0.7-24.el9", "3.0.7-27.el9", 1},
+		{"Red Hat", "1.0~rc1-1", "1.0-1", -1},
+		{"Red Hat", "1.0a", "1.0.1", -1},
+		{"Red Hat", "1.0^git1", "1.0", 1},
+		{"Red Hat", "2.28", "2.28-1.el9", 0},
+		{"Alpine", "1.2.5-r0", "1.2.5-r1", -1},
+		{"Alpine", "1.2.10-r0", "1.2.5-r9", 1},
+		{"Alpine", "3.3.2-r0", "3.3.2-r0", 0},
+		{"PyPI", "1.0.0", "1.0.0rc1", 1},
+		{"PyPI", "1.0.0.post1", "1.0.0", 1},
+		{"PyPI", "1.0.post1", "1.0.1", -1},
+		{"PyPI", "1.0-1", "1.0", 1},
+		{"PyPI", "1.0.dev1", "1.0a1", -1},
+		{"PyPI", "1.0a1", "1.0b2", -1},
+		{"PyPI", "1.0b2", "1.0rc1", -1},
+		{"PyPI", "1.0rc1.dev2", "1.0rc1", -1},
+		{"PyPI", "1.0.post1.dev1", "1.0.post1", -1},
+		{"PyPI", "1.0.Alpha1", "1.0a1", 0},
+		{"PyPI", "1.0", "1.0.0", 0},
+		{"PyPI", "1.0+ubuntu1", "1.0", 0},
+		{"PyPI", "1!0.1", "2.0", 1},
+		{"npm", "1.0.0-alpha", "1.0.0", -1},
+		{"npm", "1.0.0-alpha.1", "1.0.0-alpha.2", -1},
+		{"npm", "1.10.0", "1.9.9", 1},
+		{"Go", "v0.17.0", "0.17.0", 0},
+		{"Go", "1.2.3+incompatible", "1.2.3", 0},
+		{"Maven", "2.17.1", "2.17.1.1", -1},
+		{"Maven", "1.0-SP1", "1.0", 1},
+		{"Maven", "1.0-SP1", "1.0.1", -1},
+		{"Maven", "1.0-alpha-1", "1.0", -1},
+		{"Maven", "1.0-alpha1", "1.0-beta1", -1},
+		{"Maven", "1.0-RC1", "1.0-SNAPSHOT", -1},
+		{"Maven", "1.0-SNAPSHOT", "1.0", -1},
+		{"Maven", "1.0.0", "1", 0},
+		{"Maven", "1.0.Final", "1.0", 0},
+		{"Maven", "1.0.0-alpha", "1-alpha", 0},
+		{"Maven", "2.0.0.RELEASE", "2.0.0", 0},
+		{"Maven", "1.0-foo", "1.0-SP1", 1},
+	}
+
+	for _, test := range tests {
+		compare := comparerForEcosystem(test.ecosystem)
+		assert.Equal(t, test.expected, sign(compare(test.a, test.b)), "%s: %s vs %s", test.ecosystem, test.a, test.b)
+		assert.Equal(t, -test.expected, sign(compare(test.b, test.a)), "%s: %s vs %s", test.ecosystem, test.b, test.a)
+	}
+}
+
+func TestIsAffectedRangeTypes(t *testing.T) {
+	affected := &Affected{
+		Versions: []string{"1.0.1"},
+		Ranges: []Range{
+			{Type: RangeTypeEcosystem, Events: []Event{{Introduced: "0"}, {Fixed: "1.2.0"}}},
+		},
+	}
+
+	// Ecosystems without a supported version scheme only match the listed versions
+	assert.Nil(t, comparerForEcosystem("Packagist"))
+	affectedVersion, _ := isAffected(affected, "1.0.1", nil)
+	assert.True(t, affectedVersion)
+	affectedVersion, _ = isAffected(affected, "1.1.0", nil)
+	assert.False(t, affectedVersion)
+
+	// SEMVER ranges don't depend on the ecosystem
+	affected.Ranges[0].Type = RangeTypeSemver
+	affectedVersion, fixedVersion := isAffected(affected, "1.1.0", nil)
+	assert.True(t, affectedVersion)
+	assert.Equal(t, "1.2.0", fixedVersion)
+}
@@ -95,6 +95,26 @@ func evalRule(provider rules.PolicyProvider, decoder *json.Decoder, evalArgs Eva
 		return report, err
 	}
 
+	if err := setTestVariables(evalOpts, event, variables); err != nil {
+		return report, err
+	}
+
+	if !evalArgs.UseWindowsModel {
+		approvers, _, _, err := ruleSet.GetApprovers(kfilters.GetCapababilities())
+		if err != nil {
+			report.Error = err
+		} else {
+			report.Approvers = approvers
+		}
+	}
+
+	report.Succeeded = ruleSet.Evaluate(event)
+
+	return report, nil
+}
+
+// setTestVariables sets the value of the variables provided by the test data
+func setTestVariables(evalOpts *eval.Opts, event eval.Event, variables map[string]any) error {
 	ctx := eval.NewContext(event)
 
 	for varName, value := range variables {
@@ -104,33 +124,22 @@ func evalRule(provider rules.PolicyProvider, decoder *json.Decoder, evalArgs Eva
 			evalOpts.VariableStore.IterVariableDefinitions(func(definition eval.VariableDefinition) {
 				definedVariableNames = append(definedVariableNames, definition.VariableName(true))
 			})
-			return report, fmt.Errorf("no variable named `%s` was found in current ruleset (found: %q)", varName, definedVariableNames)
+			return fmt.Errorf("no variable named `%s` was found in current ruleset (found: %q)", varName, definedVariableNames)
 		}
 		instance, added, err := definition.AddNewInstance(ctx)
 		if err != nil {
-			return report, fmt.Errorf("failed to register new variable instance `%s`: %s", varName, err)
+			return fmt.Errorf("failed to register new variable instance `%s`: %s", varName, err)
 		}
 		if !added {
-			return report, fmt.Errorf("failed to add new variable instance `%s`", varName)
+			return fmt.Errorf("failed to add new variable instance `%s`", varName)
 		}
 		err = instance.Set(value)
 		if err != nil {
-			return report, fmt.Errorf("failed to set value of variable `%s` to value `%v`: %s", varName, value, err)
-		}
-	}
-
-	if !evalArgs.UseWindowsModel {
-		approvers, _, _, err := ruleSet.GetApprovers(kfilters.GetCapababilities())
-		if err != nil {
-			report.Error = err
-		} else {
-			report.Approvers = approvers
+			return fmt.Errorf("failed to set value of variable `%s` to value `%v`: %s", varName, value, err)
 		}
 	}
 
-	report.Succeeded = ruleSet.Evaluate(event)
-
-	return report, nil
+	return nil
 }
 
 // EvalRule evaluates a rule against an event
@@ -220,14 +229,12 @@ func variablesFromTestData(testData TestData) (map[string]any, error) {
 		switch value := value.(type) {
 		case string:
 			variables[varName] = value
-		case json.Number:
-			v, err := value.Int64()
+		case json.Number, int, float64:
+			v, err := intVariable(varName, value)
 			if err != nil {
-				return nil, fmt.Errorf("failed to convert %s to int: %w", varName, err)
+				return nil, err
 			}
-			variables[varName] = int(v)
-		case float64:
-			variables[varName] = int(value)
+			variables[varName] = v
 		case bool:
 			variables[varName] = value
 		case []any:
@@ -238,23 +245,21 @@ func variablesFromTestData(testData TestData) (map[string]any, error) {
 			case string:
 				values := make([]string, 0, len(value))
 				for _, v := range value {
-					values = append(values, v.(string))
+					s, ok := v.(string)
+					if !ok {
+						return nil, fmt.Errorf("test variable `%s` mixes strings with values of type %T", varName, v)
+					}
+					values = append(values, s)
 				}
 				variables[varName] = values
-			case json.Number:
+			case json.Number, int, float64:
 				values := make([]int, 0, len(value))
 				for _, v := range value {
-					v, err := v.(json.Number).Int64()
+					i, err := intVariable(varName, v)
 					if err != nil {
-						return nil, fmt.Errorf("failed to convert %s to int: %w", varName, err)
+						return nil, err
 					}
-					values = append(values, int(v))
-				}
-				variables[varName] = values
-			case float64:
-				values := make([]int, 0, len(value))
-				for _, v := range value {
-					values = append(values, int(v.(float64)))
+					values = append(values, i)
 				}
 				variables[varName] = values
 			default:
@@ -268,6 +273,24 @@ func variablesFromTestData(testData TestData) (map[string]any, error) {
 	return variables, nil
 }
 
+// intVariable converts a numeric test variable value to an int
+func intVariable(varName string, value any) (int, error) {
+	switch value := value.(type) {
+	case json.Number:
+		v, err := value.Int64()
+		if err != nil {
+			return 0, fmt.Errorf("failed to convert %s to int: %w", varName, err)
+		}
+		return int(v), nil
+	case int:
+		return value, nil
+	case float64:
+		return int(value), nil
+	default:
+		return 0, fmt.Errorf("test variable `%s` mixes numbers with values of type %T", varName, value)
+	}
+}
+
 func dataFromJSON(decoder *json.Decoder) (eval.Event, map[string]any, error) {
 	var testData TestData
 	if err := decoder.Decode(&testData); err != nil {
@@ -126,3 +126,32 @@ rules:
 	})
 
 }
+
+func TestVariablesFromTestData(t *testing.T) {
+	variables, err := variablesFromTestData(TestData{Variables: map[string]any{
+		"name":  "curl",
+		"count": json.Number("3"),
+		"ports": []any{json.Number("80"), 443, float64(8080)},
+		"names": []any{"curl", "wget"},
+	}})
+	if err != nil {
+		t.Fatalf("error converting variables: %s", err)
+	}
+	if ports, ok := variables["ports"].([]int); !ok || len(ports) != 3 || ports[2] != 8080 {
+		t.Fatalf("unexpected ports variable: %v", variables["ports"])
+	}
+	if count, ok := variables["count"].(int); !ok || count != 3 {
+		t.Fatalf("unexpected count variable: %v", variables["count"])
+	}
+
+	for _, value := range []any{
+		[]any{"curl", json.Number("1")},
+		[]any{json.Number("1"), "curl"},
+		[]any{1, true},
+		[]any{},
+	} {
+		if _, err := variablesFromTestData(TestData{Variables: map[string]any{"mixed": value}}); err == nil {
+			t.Fatalf("expected an error for %v", value)
+		}
+	}
+}
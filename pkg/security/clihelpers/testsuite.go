@@ -0,0 +1,416 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux || windows
+
+package clihelpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/DataDog/datadog-agent/pkg/security/rules/filtermodel"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/rules"
+	"github.com/DataDog/datadog-agent/pkg/security/seclog"
+	winmodel "github.com/DataDog/datadog-agent/pkg/security/seclwin/model"
+)
+
+// policyTestFileSuffixes are the suffixes of the files holding policy test suites
+var policyTestFileSuffixes = []string{".test.yaml", ".test.yml"}
+
+// PolicyTestSuite defines the content of a policy test file
+type PolicyTestSuite struct {
+	Tests []PolicyTestCase `yaml:"tests"`
+}
+
+// PolicyTestCase defines an event and the rules it is expected to match, or not to match
+type PolicyTestCase struct {
+	Name    string   `yaml:"name"`
+	Event   TestData `yaml:"event"`
+	Match   []string `yaml:"match"`
+	NoMatch []string `yaml:"no_match"`
+}
+
+// PolicyTestResult defines the result of a policy test case
+type PolicyTestResult struct {
+	File      string
+	Name      string
+	Succeeded bool
+	Matched   []string
+	Failures  []string `json:",omitempty"`
+}
+
+// PolicyTestCoverage defines the coverage of the rules, or of the fields they use, by the test cases
+type PolicyTestCoverage struct {
+	Covered   int
+	Total     int
+	Uncovered []string `json:",omitempty"`
+}
+
+// Percent returns the coverage as a percentage
+func (c PolicyTestCoverage) Percent() float64 {
+	if c.Total == 0 {
+		return 100
+	}
+	return float64(c.Covered) * 100 / float64(c.Total)
+}
+
+// PolicyTestReport defines a report of the execution of policy test suites
+type PolicyTestReport struct {
+	Succeeded     bool
+	Results       []PolicyTestResult
+	RuleCoverage  PolicyTestCoverage
+	FieldCoverage PolicyTestCoverage
+}
+
+// Failed returns the number of failed test cases
+func (r *PolicyTestReport) Failed() int {
+	var failed int
+	for _, result := range r.Results {
+		if !result.Succeeded {
+			failed++
+		}
+	}
+	return failed
+}
+
+// RunPolicyTestsParams are parameters to the RunPolicyTests function
+type RunPolicyTestsParams struct {
+	Dir             string
+	TestsDir        string
+	UseWindowsModel bool
+	JSON            bool
+}
+
+type matchedRulesListener struct {
+	matched []string
+}
+
+// RuleMatch is called when a rule matches the tested event
+func (l *matchedRulesListener) RuleMatch(_ *eval.Context, rule *rules.Rule, _ eval.Event) bool {
+	l.matched = append(l.matched, rule.ID)
+	return true
+}
+
+// EventDiscarderFound is called when a discarder is found for the tested event
+func (l *matchedRulesListener) EventDiscarderFound(_ *rules.RuleSet, _ eval.Event, _ eval.Field, _ eval.EventType) {
+}
+
+type policyTester struct {
+	provider        rules.PolicyProvider
+	useWindowsModel bool
+	loaderOpts      rules.PolicyLoaderOpts
+}
+
+func newPolicyTester(provider rules.PolicyProvider, useWindowsModel bool) (*policyTester, error) {
+	agentVersionFilter, err := newAgentVersionFilter()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create agent version filter: %w", err)
+	}
+
+	osName := runtime.GOOS
+	if useWindowsModel {
+		osName = "windows"
+	}
+
+	seclRuleFilter := rules.NewSECLRuleFilter(filtermodel.NewOSOnlyFilterModel(osName))
+
+	return &policyTester{
+		provider:        provider,
+		useWindowsModel: useWindowsModel,
+		loaderOpts: rules.PolicyLoaderOpts{
+			MacroFilters: []rules.MacroFilter{
+				agentVersionFilter,
+				seclRuleFilter,
+			},
+			RuleFilters: []rules.RuleFilter{
+				agentVersionFilter,
+				seclRuleFilter,
+			},
+		},
+	}, nil
+}
+
+// loadRuleSet returns a new rule set with the tested policies. A new rule set is used for every test case
+// so that the variables set by a test case, or by the actions of the rules, do not leak into the next ones.
+func (pt *policyTester) loadRuleSet() (*rules.RuleSet, *eval.Opts, error) {
+	// enabled all the rules
+	enabled := map[eval.EventType]bool{"*": true}
+
+	ruleOpts := rules.NewRuleOpts(enabled)
+	evalOpts := newEvalOpts(pt.useWindowsModel)
+	ruleOpts.WithLogger(seclog.DefaultLogger)
+
+	var ruleSet *rules.RuleSet
+	if pt.useWindowsModel {
+		ruleSet = rules.NewRuleSet(&winmodel.Model{}, newFakeWindowsEvent, ruleOpts, evalOpts)
+	} else {
+		ruleSet = rules.NewRuleSet(&model.Model{}, newFakeEvent, ruleOpts, evalOpts)
+	}
+
+	if _, err := ruleSet.LoadPolicies(rules.NewPolicyLoader(pt.provider), pt.loaderOpts); err.ErrorOrNil() != nil {
+		return nil, nil, err
+	}
+
+	return ruleSet, evalOpts, nil
+}
+
+func (pt *policyTester) runTestCase(file string, test PolicyTestCase, ruleIDs map[string]bool) PolicyTestResult {
+	result := PolicyTestResult{
+		File: file,
+		Name: test.Name,
+	}
+
+	fail := func(format string, args ...any) PolicyTestResult {
+		result.Failures = append(result.Failures, fmt.Sprintf(format, args...))
+		return result
+	}
+
+	for _, ruleID := range append(slices.Clone(test.Match), test.NoMatch...) {
+		if !ruleIDs[ruleID] {
+			result.Failures = append(result.Failures, fmt.Sprintf("rule `%s` not found in the tested policies", ruleID))
+		}
+	}
+	if len(result.Failures) > 0 {
+		return result
+	}
+
+	ruleSet, evalOpts, err := pt.loadRuleSet()
+	if err != nil {
+		return fail("failed to load policies: %s", err)
+	}
+
+	event, err := eventFromTestData(test.Event)
+	if err != nil {
+		return fail("invalid event: %s", err)
+	}
+
+	variables, err := variablesFromTestData(test.Event)
+	if err != nil {
+		return fail("invalid variables: %s", err)
+	}
+
+	if err := setTestVariables(evalOpts, event, variables); err != nil {
+		return fail("%s", err)
+	}
+
+	listener := &matchedRulesListener{}
+	ruleSet.AddListener(listener)
+	ruleSet.Evaluate(event)
+
+	sort.Strings(listener.matched)
+	result.Matched = listener.matched
+
+	for _, ruleID := range test.Match {
+		if !slices.Contains(result.Matched, ruleID) {
+			result.Failures = append(result.Failures, fmt.Sprintf("expected rule `%s` to match", ruleID))
+		}
+	}
+	for _, ruleID := range test.NoMatch {
+		if slices.Contains(result.Matched, ruleID) {
+			result.Failures = append(result.Failures, fmt.Sprintf("expected rule `%s` not to match", ruleID))
+		}
+	}
+
+	result.Succeeded = len(result.Failures) == 0
+	return result
+}
+
+// ruleFields returns the fields used by a rule, including the fields used by the macros it references
+func ruleFields(rule *rules.Rule) []eval.Field {
+	fields := slices.Clone(rule.GetEvaluator().GetFields())
+
+	for _, macro := range rule.Opts.MacroStore.List() {
+		macroRef := regexp.MustCompile(`(^|[^\w.])` + regexp.QuoteMeta(macro.ID) + `($|[^\w.])`)
+		if macroRef.MatchString(rule.Expression) {
+			fields = append(fields, macro.GetFields()...)
+		}
+	}
+
+	return fields
+}
+
+func evaluatePolicyTestSuites(provider rules.PolicyProvider, suites map[string]*PolicyTestSuite, useWindowsModel bool) (*PolicyTestReport, error) {
+	tester, err := newPolicyTester(provider, useWindowsModel)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleSet, _, err := tester.loadRuleSet()
+	if err != nil {
+		return nil, err
+	}
+
+	ruleIDs := make(map[string]bool)
+	usedFields := make(map[string]bool)
+	for ruleID, rule := range ruleSet.GetRules() {
+		ruleIDs[ruleID] = true
+		for _, field := range ruleFields(rule) {
+			usedFields[field] = true
+		}
+	}
+
+	files := make([]string, 0, len(suites))
+	for file := range suites {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	report := &PolicyTestReport{Succeeded: true}
+	testedRules := make(map[string]bool)
+	testedFields := make(map[string]bool)
+
+	for _, file := range files {
+		for _, test := range suites[file].Tests {
+			result := tester.runTestCase(file, test, ruleIDs)
+			report.Results = append(report.Results, result)
+			report.Succeeded = report.Succeeded && result.Succeeded
+
+			// a rule is only covered by the test cases expecting it to match
+			for _, ruleID := range test.Match {
+				testedRules[ruleID] = true
+			}
+			for field := range test.Event.Values {
+				testedFields[field] = true
+			}
+		}
+	}
+
+	report.RuleCoverage = computeCoverage(ruleIDs, testedRules)
+	report.FieldCoverage = computeCoverage(usedFields, testedFields)
+
+	return report, nil
+}
+
+func computeCoverage(all map[string]bool, covered map[string]bool) PolicyTestCoverage {
+	coverage := PolicyTestCoverage{
+		Total: len(all),
+	}
+
+	for key := range all {
+		if covered[key] {
+			coverage.Covered++
+		} else {
+			coverage.Uncovered = append(coverage.Uncovered, key)
+		}
+	}
+	sort.Strings(coverage.Uncovered)
+
+	return coverage
+}
+
+// LoadPolicyTestSuites loads the policy test suites found in a directory
+func LoadPolicyTestSuites(dir string) (map[string]*PolicyTestSuite, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	suites := make(map[string]*PolicyTestSuite)
+	for _, entry := range entries {
+		if entry.IsDir() || !slices.ContainsFunc(policyTestFileSuffixes, func(suffix string) bool {
+			return strings.HasSuffix(entry.Name(), suffix)
+		}) {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		var suite PolicyTestSuite
+		if err := yaml.Unmarshal(content, &suite); err != nil {
+			return nil, fmt.Errorf("failed to parse test file %s: %w", entry.Name(), err)
+		}
+		suites[entry.Name()] = &suite
+	}
+
+	if len(suites) == 0 {
+		return nil, fmt.Errorf("no test file (%s) found in %s", strings.Join(policyTestFileSuffixes, ", "), dir)
+	}
+
+	return suites, nil
+}
+
+// RunPolicyTests runs the policy test suites against the policies of a directory
+func RunPolicyTests(args RunPolicyTestsParams, writer io.Writer) (*PolicyTestReport, error) {
+	testsDir := args.TestsDir
+	if testsDir == "" {
+		testsDir = args.Dir
+	}
+
+	suites, err := LoadPolicyTestSuites(testsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := rules.NewPoliciesDirProvider(args.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := evaluatePolicyTestSuites(provider, suites, args.UseWindowsModel)
+	if err != nil {
+		return nil, err
+	}
+
+	if args.JSON {
+		content, _ := json.MarshalIndent(report, "", "\t")
+		if _, err := fmt.Fprintf(writer, "%s\n", string(content)); err != nil {
+			return nil, fmt.Errorf("unable to write out report: %w", err)
+		}
+		return report, nil
+	}
+
+	if err := writePolicyTestReport(report, writer); err != nil {
+		return nil, fmt.Errorf("unable to write out report: %w", err)
+	}
+
+	return report, nil
+}
+
+func writePolicyTestReport(report *PolicyTestReport, writer io.Writer) error {
+	var b strings.Builder
+
+	for _, result := range report.Results {
+		status := "PASS"
+		if !result.Succeeded {
+			status = "FAIL"
+		}
+		fmt.Fprintf(&b, "%s  %s: %s\n", status, result.File, result.Name)
+		for _, failure := range result.Failures {
+			fmt.Fprintf(&b, "      %s\n", failure)
+		}
+		if !result.Succeeded && len(result.Matched) > 0 {
+			fmt.Fprintf(&b, "      matched rules: %s\n", strings.Join(result.Matched, ", "))
+		}
+	}
+
+	fmt.Fprintf(&b, "\n%d tests, %d failed\n", len(report.Results), report.Failed())
+	fmt.Fprintf(&b, "Rule coverage: %d/%d (%.1f%%)\n", report.RuleCoverage.Covered, report.RuleCoverage.Total, report.RuleCoverage.Percent())
+	if len(report.RuleCoverage.Uncovered) > 0 {
+		fmt.Fprintf(&b, "  rules without matching test: %s\n", strings.Join(report.RuleCoverage.Uncovered, ", "))
+	}
+	fmt.Fprintf(&b, "Field coverage: %d/%d (%.1f%%)\n", report.FieldCoverage.Covered, report.FieldCoverage.Total, report.FieldCoverage.Percent())
+	if len(report.FieldCoverage.Uncovered) > 0 {
+		fmt.Fprintf(&b, "  fields never set by a test: %s\n", strings.Join(report.FieldCoverage.Uncovered, ", "))
+	}
+
+	_, err := io.WriteString(writer, b.String())
+	return err
+}
@@ -0,0 +1,96 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+
+package clihelpers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluatePolicyTestSuites(t *testing.T) {
+	var policy = `
+macros:
+  - id: download_tools
+    expression: process.file.name in ["curl", "wget"]
+rules:
+  - id: IMDS
+    expression: imds.cloud_provider == "aws" && process.file.name not in ${imds_v1_usage_services}
+    actions:
+      - set:
+          name: imds_v1_usage_services
+          field: process.file.name
+          append: true
+  - id: IMDS_DOWNLOAD
+    expression: imds.cloud_provider == "aws" && download_tools
+  - id: untested
+    expression: open.file.path == "/etc/shadow"
+`
+
+	var tests = `
+tests:
+  - name: unknown service
+    event:
+      type: imds
+      values:
+        imds.cloud_provider: aws
+        process.file.name: curl
+      variables:
+        imds_v1_usage_services: ["wget"]
+    match: [IMDS, IMDS_DOWNLOAD]
+  - name: known service
+    event:
+      type: imds
+      values:
+        imds.cloud_provider: aws
+        process.file.name: python
+      variables:
+        imds_v1_usage_services: ["python"]
+    no_match: [IMDS, IMDS_DOWNLOAD]
+  - name: wrong expectation
+    event:
+      type: imds
+      values:
+        imds.cloud_provider: aws
+        process.file.name: wget
+    no_match: [IMDS_DOWNLOAD]
+  - name: unknown rule
+    event:
+      type: imds
+    match: [unknown]
+`
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "default.test.yaml"), []byte(tests), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("policy tests"), 0644))
+
+	suites, err := LoadPolicyTestSuites(dir)
+	require.NoError(t, err)
+	require.Contains(t, suites, "default.test.yaml")
+
+	report, err := evaluatePolicyTestSuites(&fakeProvider{data: []byte(policy)}, suites, false)
+	require.NoError(t, err)
+
+	assert.False(t, report.Succeeded)
+	require.Len(t, report.Results, 4)
+	assert.True(t, report.Results[0].Succeeded, report.Results[0].Failures)
+	assert.Equal(t, []string{"IMDS", "IMDS_DOWNLOAD"}, report.Results[0].Matched)
+	assert.True(t, report.Results[1].Succeeded, report.Results[1].Failures)
+	assert.False(t, report.Results[2].Succeeded)
+	assert.Equal(t, []string{"expected rule `IMDS_DOWNLOAD` not to match"}, report.Results[2].Failures)
+	assert.False(t, report.Results[3].Succeeded)
+	assert.Equal(t, []string{"rule `unknown` not found in the tested policies"}, report.Results[3].Failures)
+	assert.Equal(t, 2, report.Failed())
+
+	assert.Equal(t, PolicyTestCoverage{Covered: 2, Total: 3, Uncovered: []string{"untested"}}, report.RuleCoverage)
+	assert.Equal(t, 2, report.FieldCoverage.Covered)
+	assert.Equal(t, []string{"open.file.path"}, report.FieldCoverage.Uncovered)
+}
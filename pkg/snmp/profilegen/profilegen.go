@@ -0,0 +1,401 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package profilegen generates starter SNMP profiles from device walks.
+//
+// Without the MIBs of the device, tables are detected from the shape of the walk: the rows of a
+// table are <table>.1.<column>.<index>, and the columns of a table share the same indexes.
+// The metrics are named after their OIDs and are meant to be renamed when reviewing the profile.
+package profilegen
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/profile/profiledefinition"
+	"github.com/DataDog/datadog-agent/pkg/snmp/gosnmplib"
+)
+
+const (
+	sysObjectIDOID = "1.3.6.1.2.1.1.2.0"
+	mib2OID        = "1.3.6.1.2.1"
+	enterprisesOID = "1.3.6.1.4.1"
+
+	baseProfile = "_base.yaml"
+)
+
+// genericProfile is a default profile extended when the walk holds the MIB tables it covers
+type genericProfile struct {
+	file string
+	oids []string
+}
+
+// genericProfiles are checked in order, which is the order of the extends of the generated profile
+var genericProfiles = []genericProfile{
+	{file: "_generic-if.yaml", oids: []string{
+		"1.3.6.1.2.1.2.2",    // IF-MIB::ifTable
+		"1.3.6.1.2.1.31.1.1", // IF-MIB::ifXTable
+	}},
+	{file: "_generic-entity-sensor.yaml", oids: []string{
+		"1.3.6.1.2.1.99.1.1", // ENTITY-SENSOR-MIB::entPhySensorTable
+	}},
+	{file: "_generic-host-resources-base.yaml", oids: []string{
+		"1.3.6.1.2.1.25.2.3", // HOST-RESOURCES-MIB::hrStorageTable
+		"1.3.6.1.2.1.25.3.2", // HOST-RESOURCES-MIB::hrDeviceTable
+		"1.3.6.1.2.1.25.3.3", // HOST-RESOURCES-MIB::hrProcessorTable
+	}},
+}
+
+// ignoredOIDs are the subtrees no metric is generated for: the system group, which _base.yaml
+// covers, and the SNMP engine statistics, which describe the agent rather than the device
+var ignoredOIDs = []string{
+	"1.3.6.1.2.1.1",  // SNMPv2-MIB::system
+	"1.3.6.1.2.1.11", // SNMPv2-MIB::snmp
+	"1.3.6.1.6",      // snmpV2
+}
+
+// vendors maps the private enterprise numbers of common vendors to the vendor names used by the
+// default profiles
+var vendors = map[string]string{
+	"9":     "cisco",
+	"11":    "hp",
+	"43":    "3com",
+	"674":   "dell",
+	"1588":  "brocade",
+	"1991":  "brocade",
+	"2011":  "huawei",
+	"2620":  "checkpoint",
+	"2636":  "juniper",
+	"3375":  "f5",
+	"4526":  "netgear",
+	"6027":  "dell",
+	"6486":  "alcatel-lucent",
+	"6527":  "nokia",
+	"6876":  "vmware",
+	"8072":  "net-snmp",
+	"9148":  "cisco",
+	"12356": "fortinet",
+	"14179": "cisco",
+	"14823": "aruba",
+	"14988": "mikrotik",
+	"25053": "ruckus",
+	"25461": "paloaltonetworks",
+	"30065": "arista",
+	"41112": "ubiquiti",
+}
+
+// column is a column of a table holding numeric values
+type column struct {
+	oid        string
+	metricType profiledefinition.ProfileMetricType
+}
+
+// table is a table detected in the walk, identified by the OID of its entry
+type table struct {
+	entry    string
+	columns  map[string]*column
+	indexLen int
+}
+
+// Generate returns a starter profile for the device the walk was recorded from
+func Generate(pdus []gosnmp.SnmpPDU) (*profiledefinition.ProfileDefinition, error) {
+	if len(pdus) == 0 {
+		return nil, errors.New("the walk is empty")
+	}
+	profile := profiledefinition.NewProfileDefinition()
+	profile.Extends = []string{baseProfile}
+
+	byOID := make(map[string]gosnmp.SnmpPDU, len(pdus))
+	for _, pdu := range pdus {
+		byOID[strings.TrimLeft(pdu.Name, ".")] = pdu
+	}
+	oids := make([]string, 0, len(byOID))
+	for oid := range byOID {
+		oids = append(oids, oid)
+	}
+	sortOIDs(oids)
+
+	if pdu, ok := byOID[sysObjectIDOID]; ok {
+		if sysObjectID, ok := pdu.Value.(string); ok {
+			sysObjectID = strings.TrimLeft(sysObjectID, ".")
+			profile.SysObjectIDs = profiledefinition.StringArray{sysObjectID}
+			profile.Description = "Starter profile generated from a walk of a " + sysObjectID + " device"
+			if vendor := vendorName(sysObjectID); vendor != "" {
+				profile.Metadata[profiledefinition.MetadataDeviceResource] = profiledefinition.MetadataResourceConfig{
+					Fields: profiledefinition.ListMap[profiledefinition.MetadataField]{
+						"vendor": {Value: vendor},
+					},
+				}
+			}
+		}
+	}
+
+	ignored := slices.Clone(ignoredOIDs)
+	for _, generic := range genericProfiles {
+		if hasAnyPrefix(oids, generic.oids) {
+			profile.Extends = append(profile.Extends, generic.file)
+		}
+		// the OIDs of the generic profiles are left to them even if they aren't extended, as
+		// they are only partially in the walk
+		ignored = append(ignored, generic.oids...)
+	}
+
+	var columnOIDs []string
+	for _, oid := range oids {
+		if isUnder(oid, ignored) {
+			continue
+		}
+		metricType, ok := metricTypeOf(byOID[oid].Type)
+		if !ok {
+			continue
+		}
+		if strings.HasSuffix(oid, ".0") {
+			scalar := strings.TrimSuffix(oid, ".0")
+			profile.Metrics = append(profile.Metrics, profiledefinition.MetricsConfig{
+				Symbol: profiledefinition.SymbolConfig{
+					OID:  oid,
+					Name: symbolName(scalar),
+				},
+				MetricType: metricType,
+			})
+			continue
+		}
+		columnOIDs = append(columnOIDs, oid)
+	}
+	profile.Metrics = append(profile.Metrics, tableMetrics(detectTables(oids, byOID, columnOIDs))...)
+	return profile, nil
+}
+
+// Marshal returns the profile in the YAML format of the profiles
+func Marshal(profile *profiledefinition.ProfileDefinition) ([]byte, error) {
+	content, err := yaml.Marshal(profile)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteString("# Generated by `agent snmp profile generate` from a device walk.\n")
+	buf.WriteString("# The metrics are named after their OIDs: review them, rename them after their MIB objects\n")
+	buf.WriteString("# and remove the ones that aren't useful, then test the profile with `agent snmp profile test`.\n")
+	buf.Write(content)
+	return buf.Bytes(), nil
+}
+
+// split is one way to read the OID of a row as <entry>.<column>.<index>
+type split struct {
+	entry  string
+	column string
+	index  string
+}
+
+// detectTables groups the rows of the walk in tables. An OID can be split in several ways, since
+// any of its sub-identifiers equal to 1 could be the entry: the split retained is the one where
+// the index is shared by the most columns of the entry, preferring the deepest entry on ties.
+func detectTables(oids []string, byOID map[string]gosnmp.SnmpPDU, columnOIDs []string) []*table {
+	// all the rows of the walk, numeric or not, show which indexes the columns share
+	candidates := make(map[string][]split)
+	indexes := make(map[string]map[string]map[string]struct{})
+	for _, oid := range oids {
+		if strings.HasSuffix(oid, ".0") {
+			continue
+		}
+		splits := splitRow(oid)
+		candidates[oid] = splits
+		for _, s := range splits {
+			entryColumns, ok := indexes[s.entry]
+			if !ok {
+				entryColumns = make(map[string]map[string]struct{})
+				indexes[s.entry] = entryColumns
+			}
+			columnIndexes, ok := entryColumns[s.column]
+			if !ok {
+				columnIndexes = make(map[string]struct{})
+				entryColumns[s.column] = columnIndexes
+			}
+			columnIndexes[s.index] = struct{}{}
+		}
+	}
+
+	tables := make(map[string]*table)
+	for _, oid := range columnOIDs {
+		splits := candidates[oid]
+		if len(splits) == 0 {
+			continue
+		}
+		best, bestScore := splits[0], 0
+		for _, s := range splits {
+			score := 0
+			for _, columnIndexes := range indexes[s.entry] {
+				if _, ok := columnIndexes[s.index]; ok {
+					score++
+				}
+			}
+			// splits are ordered from the deepest entry
+			if score > bestScore {
+				best, bestScore = s, score
+			}
+		}
+
+		t, ok := tables[best.entry]
+		if !ok {
+			t = &table{entry: best.entry, columns: make(map[string]*column)}
+			tables[best.entry] = t
+		}
+		if indexLen := strings.Count(best.index, ".") + 1; indexLen > t.indexLen {
+			t.indexLen = indexLen
+		}
+		columnOID := best.entry + "." + best.column
+		if _, ok := t.columns[columnOID]; !ok {
+			metricType, _ := metricTypeOf(byOID[oid].Type)
+			t.columns[columnOID] = &column{oid: columnOID, metricType: metricType}
+		}
+	}
+
+	entries := make([]string, 0, len(tables))
+	for entry := range tables {
+		entries = append(entries, entry)
+	}
+	sortOIDs(entries)
+	result := make([]*table, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, tables[entry])
+	}
+	return result
+}
+
+// splitRow returns the ways to read the OID as <entry>.<column>.<index>, where the entry ends with
+// the sub-identifier 1, from the deepest entry
+func splitRow(oid string) []split {
+	parts := strings.Split(oid, ".")
+	var splits []split
+	// an entry is at least <table>.1, a table at least two sub-identifiers deep
+	for i := len(parts) - 3; i >= 3; i-- {
+		if parts[i] != "1" {
+			continue
+		}
+		splits = append(splits, split{
+			entry:  strings.Join(parts[:i+1], "."),
+			column: parts[i+1],
+			index:  strings.Join(parts[i+2:], "."),
+		})
+	}
+	return splits
+}
+
+func tableMetrics(tables []*table) []profiledefinition.MetricsConfig {
+	var metrics []profiledefinition.MetricsConfig
+	for _, t := range tables {
+		tableOID := t.entry[:strings.LastIndex(t.entry, ".")]
+		metric := profiledefinition.MetricsConfig{
+			Table: profiledefinition.SymbolConfig{
+				OID:  tableOID,
+				Name: symbolName(tableOID),
+			},
+			MetricTags: indexTags(t.indexLen),
+		}
+		columnOIDs := make([]string, 0, len(t.columns))
+		for oid := range t.columns {
+			columnOIDs = append(columnOIDs, oid)
+		}
+		sortOIDs(columnOIDs)
+		for _, oid := range columnOIDs {
+			metric.Symbols = append(metric.Symbols, profiledefinition.SymbolConfig{
+				OID:        oid,
+				Name:       symbolName(oid),
+				MetricType: t.columns[oid].metricType,
+			})
+		}
+		metrics = append(metrics, metric)
+	}
+	return metrics
+}
+
+// indexTags tags the rows with the sub-identifiers of their index
+func indexTags(indexLen int) profiledefinition.MetricTagConfigList {
+	if indexLen == 1 {
+		return profiledefinition.MetricTagConfigList{{Tag: "index", Index: 1}}
+	}
+	tags := make(profiledefinition.MetricTagConfigList, 0, indexLen)
+	for i := 1; i <= indexLen; i++ {
+		tags = append(tags, profiledefinition.MetricTagConfig{Tag: "index" + strconv.Itoa(i), Index: uint(i)})
+	}
+	return tags
+}
+
+// metricTypeOf guesses the metric type from the SMI type of the values. Non-numeric values and
+// TimeTicks, which mostly hold points in time such as ifLastChange, aren't metrics.
+func metricTypeOf(typ gosnmp.Asn1BER) (profiledefinition.ProfileMetricType, bool) {
+	switch typ {
+	case gosnmp.Counter32, gosnmp.Counter64:
+		return profiledefinition.ProfileMetricTypeMonotonicCount, true
+	case gosnmp.Integer, gosnmp.Gauge32, gosnmp.Uinteger32, gosnmp.OpaqueFloat, gosnmp.OpaqueDouble:
+		return profiledefinition.ProfileMetricTypeGauge, true
+	}
+	return "", false
+}
+
+// symbolName names a symbol after its OID, relative to the vendor subtree when there is one
+func symbolName(oid string) string {
+	switch {
+	case strings.HasPrefix(oid, enterprisesOID+"."):
+		parts := strings.SplitN(strings.TrimPrefix(oid, enterprisesOID+"."), ".", 2)
+		prefix := vendors[parts[0]]
+		if prefix == "" {
+			prefix = "enterprise" + parts[0]
+		}
+		if len(parts) == 1 {
+			return prefix
+		}
+		return prefix + "_" + strings.ReplaceAll(parts[1], ".", "_")
+	case strings.HasPrefix(oid, mib2OID+"."):
+		return "mib2_" + strings.ReplaceAll(strings.TrimPrefix(oid, mib2OID+"."), ".", "_")
+	}
+	return "oid_" + strings.ReplaceAll(oid, ".", "_")
+}
+
+// vendorName returns the vendor of the private enterprise the sysObjectID belongs to
+func vendorName(sysObjectID string) string {
+	if !strings.HasPrefix(sysObjectID, enterprisesOID+".") {
+		return ""
+	}
+	enterprise, _, _ := strings.Cut(strings.TrimPrefix(sysObjectID, enterprisesOID+"."), ".")
+	return vendors[enterprise]
+}
+
+func isUnder(oid string, subtrees []string) bool {
+	for _, subtree := range subtrees {
+		if oid == subtree || strings.HasPrefix(oid, subtree+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAnyPrefix returns whether any of the OIDs is under one of the subtrees
+func hasAnyPrefix(oids []string, subtrees []string) bool {
+	for _, oid := range oids {
+		if isUnder(oid, subtrees) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortOIDs sorts the OIDs in the order of a walk
+func sortOIDs(oids []string) {
+	ints := make(map[string][]int, len(oids))
+	for _, oid := range oids {
+		ints[oid], _ = gosnmplib.OIDToInts(oid)
+	}
+	sort.Slice(oids, func(i, j int) bool {
+		return gosnmplib.CmpOIDs(ints[oids[i]], ints[oids[j]]).IsBefore()
+	})
+}
@@ -0,0 +1,117 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package profilegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/profile/profiledefinition"
+	"github.com/DataDog/datadog-agent/pkg/snmp/snmprec"
+)
+
+const testWalk = `1.3.6.1.2.1.1.1.0|4|Cisco IOS Software
+1.3.6.1.2.1.1.2.0|6|1.3.6.1.4.1.9.1.1208
+1.3.6.1.2.1.1.3.0|67|4226041
+1.3.6.1.2.1.2.2.1.2.1|4|Gi0/1
+1.3.6.1.2.1.2.2.1.10.1|65|1000
+1.3.6.1.2.1.11.1.0|65|42
+1.3.6.1.2.1.25.2.3.1.5.1|2|1024
+1.3.6.1.4.1.9.2.1.57.0|2|12
+1.3.6.1.4.1.9.2.1.58.0|4|not a metric
+1.3.6.1.4.1.9.9.109.1.1.1.1.2.1|2|22
+1.3.6.1.4.1.9.9.109.1.1.1.1.2.2|2|23
+1.3.6.1.4.1.9.9.109.1.1.1.1.7.1|66|10
+1.3.6.1.4.1.9.9.109.1.1.1.1.7.2|66|20
+1.3.6.1.4.1.9.9.109.1.1.1.1.10.1|67|424242
+1.3.6.1.4.1.9.9.600.1.1.2.1.7.9|66|1
+1.3.6.1.4.1.9.9.600.1.1.2.2.7.9|66|2
+1.3.6.1.4.1.9.9.600.1.1.3.1.7.9|70|3
+1.3.6.1.4.1.9.9.600.1.1.3.2.7.9|70|4
+1.3.6.1.4.1.9.9.600.1.1.4.1.7.9|4|first
+`
+
+func TestGenerate(t *testing.T) {
+	pdus, err := snmprec.Parse(strings.NewReader(testWalk))
+	require.NoError(t, err)
+
+	profile, err := Generate(pdus)
+	require.NoError(t, err)
+
+	expected := profiledefinition.NewProfileDefinition()
+	expected.Description = "Starter profile generated from a walk of a 1.3.6.1.4.1.9.1.1208 device"
+	expected.SysObjectIDs = profiledefinition.StringArray{"1.3.6.1.4.1.9.1.1208"}
+	expected.Extends = []string{"_base.yaml", "_generic-if.yaml", "_generic-host-resources-base.yaml"}
+	expected.Metadata = profiledefinition.MetadataConfig{
+		"device": {Fields: profiledefinition.ListMap[profiledefinition.MetadataField]{"vendor": {Value: "cisco"}}},
+	}
+	expected.Metrics = []profiledefinition.MetricsConfig{
+		{
+			Symbol:     profiledefinition.SymbolConfig{OID: "1.3.6.1.4.1.9.2.1.57.0", Name: "cisco_2_1_57"},
+			MetricType: profiledefinition.ProfileMetricTypeGauge,
+		},
+		{
+			Table: profiledefinition.SymbolConfig{OID: "1.3.6.1.4.1.9.9.109.1.1.1", Name: "cisco_9_109_1_1_1"},
+			Symbols: []profiledefinition.SymbolConfig{
+				{OID: "1.3.6.1.4.1.9.9.109.1.1.1.1.2", Name: "cisco_9_109_1_1_1_1_2", MetricType: profiledefinition.ProfileMetricTypeGauge},
+				{OID: "1.3.6.1.4.1.9.9.109.1.1.1.1.7", Name: "cisco_9_109_1_1_1_1_7", MetricType: profiledefinition.ProfileMetricTypeGauge},
+			},
+			MetricTags: profiledefinition.MetricTagConfigList{{Tag: "index", Index: 1}},
+		},
+		{
+			// the first index sub-identifier is 1, which doesn't make 1.3.6.1.4.1.9.9.600.1.1.2.1 an entry
+			Table: profiledefinition.SymbolConfig{OID: "1.3.6.1.4.1.9.9.600.1", Name: "cisco_9_600_1"},
+			Symbols: []profiledefinition.SymbolConfig{
+				{OID: "1.3.6.1.4.1.9.9.600.1.1.2", Name: "cisco_9_600_1_1_2", MetricType: profiledefinition.ProfileMetricTypeGauge},
+				{OID: "1.3.6.1.4.1.9.9.600.1.1.3", Name: "cisco_9_600_1_1_3", MetricType: profiledefinition.ProfileMetricTypeMonotonicCount},
+			},
+			MetricTags: profiledefinition.MetricTagConfigList{
+				{Tag: "index1", Index: 1},
+				{Tag: "index2", Index: 2},
+				{Tag: "index3", Index: 3},
+			},
+		},
+	}
+	assert.Equal(t, expected, profile)
+}
+
+func TestMarshal(t *testing.T) {
+	pdus, err := snmprec.Parse(strings.NewReader(testWalk))
+	require.NoError(t, err)
+	profile, err := Generate(pdus)
+	require.NoError(t, err)
+
+	content, err := Marshal(profile)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(content), "# Generated by `agent snmp profile generate`"))
+
+	// the profile reads back the way the check parses profiles
+	parsed := profiledefinition.NewProfileDefinition()
+	require.NoError(t, yaml.Unmarshal(content, parsed))
+	assert.Empty(t, profiledefinition.ValidateEnrichProfile(parsed))
+	assert.Equal(t, profile, parsed)
+}
+
+func TestGenerate_NoVendor(t *testing.T) {
+	profile, err := Generate([]gosnmp.SnmpPDU{
+		{Name: "1.3.6.1.2.1.1.2.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.99999.1"},
+		{Name: "1.3.6.1.4.1.99999.2.0", Type: gosnmp.Counter32, Value: uint(1)},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, profile.Metadata)
+	assert.Equal(t, []string{"_base.yaml"}, profile.Extends)
+	require.Len(t, profile.Metrics, 1)
+	assert.Equal(t, "enterprise99999_2", profile.Metrics[0].Symbol.Name)
+	assert.Equal(t, profiledefinition.ProfileMetricTypeMonotonicCount, profile.Metrics[0].MetricType)
+
+	_, err = Generate(nil)
+	assert.EqualError(t, err, "the walk is empty")
+}
@@ -0,0 +1,252 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package snmprec reads SNMP walks recorded in the snmprec format used by snmpsim,
+// where every line holds an OID, a type tag and a value: `1.3.6.1.2.1.1.5.0|4|my-device`.
+package snmprec
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// maxLineSize bounds the size of the lines, which can hold large hex-encoded values
+const maxLineSize = 1024 * 1024
+
+// tags maps the snmprec type tags, which are the BER tags of the types, to the gosnmp types
+var tags = map[string]gosnmp.Asn1BER{
+	"2":   gosnmp.Integer,
+	"4":   gosnmp.OctetString,
+	"5":   gosnmp.Null,
+	"6":   gosnmp.ObjectIdentifier,
+	"64":  gosnmp.IPAddress,
+	"65":  gosnmp.Counter32,
+	"66":  gosnmp.Gauge32,
+	"67":  gosnmp.TimeTicks,
+	"68":  gosnmp.Opaque,
+	"70":  gosnmp.Counter64,
+	"128": gosnmp.NoSuchObject,
+	"129": gosnmp.NoSuchInstance,
+	"130": gosnmp.EndOfMibView,
+}
+
+// ReadFile reads the walk recorded in the given snmprec file
+func ReadFile(path string) ([]gosnmp.SnmpPDU, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	pdus, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return pdus, nil
+}
+
+// Parse reads a walk in the snmprec format. The PDUs are returned in the order of the records,
+// with the values of the Go types gosnmp decodes them to, and OIDs without leading dot.
+func Parse(r io.Reader) ([]gosnmp.SnmpPDU, error) {
+	var pdus []gosnmp.SnmpPDU
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pdu, err := parseRecord(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		pdus = append(pdus, pdu)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return pdus, nil
+}
+
+func parseRecord(line string) (gosnmp.SnmpPDU, error) {
+	// values may contain the separator, so only the first two are meaningful
+	fields := strings.SplitN(line, "|", 3)
+	if len(fields) != 3 {
+		return gosnmp.SnmpPDU{}, fmt.Errorf("invalid record %q, expected <oid>|<type>|<value>", line)
+	}
+	oid := strings.TrimLeft(strings.TrimSpace(fields[0]), ".")
+	if !isOID(oid) {
+		return gosnmp.SnmpPDU{}, fmt.Errorf("invalid OID %q", fields[0])
+	}
+
+	tag := fields[1]
+	if strings.Contains(tag, ":") {
+		return gosnmp.SnmpPDU{}, fmt.Errorf("OID %s: variation modules are not supported (type %q)", oid, tag)
+	}
+	// the type tag may be suffixed with the encoding of the value: hex (x) or escaped (e)
+	encoding := byte(0)
+	if last := tag[len(tag)-1]; last == 'x' || last == 'e' {
+		encoding = last
+		tag = tag[:len(tag)-1]
+	}
+	typ, ok := tags[tag]
+	if !ok {
+		return gosnmp.SnmpPDU{}, fmt.Errorf("OID %s: unsupported type %q", oid, fields[1])
+	}
+
+	raw := []byte(fields[2])
+	switch encoding {
+	case 'x':
+		decoded, err := hex.DecodeString(fields[2])
+		if err != nil {
+			return gosnmp.SnmpPDU{}, fmt.Errorf("OID %s: invalid hex value: %w", oid, err)
+		}
+		raw = decoded
+	case 'e':
+		decoded, err := unescape(fields[2])
+		if err != nil {
+			return gosnmp.SnmpPDU{}, fmt.Errorf("OID %s: invalid escaped value: %w", oid, err)
+		}
+		raw = decoded
+	}
+
+	pdu := gosnmp.SnmpPDU{Name: oid, Type: typ}
+	var err error
+	switch typ {
+	case gosnmp.Null, gosnmp.NoSuchObject, gosnmp.NoSuchInstance, gosnmp.EndOfMibView:
+	case gosnmp.OctetString:
+		pdu.Value = raw
+	case gosnmp.ObjectIdentifier:
+		value := strings.TrimLeft(string(raw), ".")
+		if !isOID(value) {
+			return pdu, fmt.Errorf("OID %s: invalid OID value %q", oid, raw)
+		}
+		pdu.Value = "." + value
+	case gosnmp.IPAddress:
+		pdu.Value, err = parseIPAddress(raw, encoding != 0)
+	case gosnmp.Opaque:
+		pdu = parseOpaque(pdu, raw)
+	case gosnmp.Integer:
+		var value int64
+		value, err = strconv.ParseInt(string(raw), 10, 32)
+		pdu.Value = int(value)
+	case gosnmp.Counter32, gosnmp.Gauge32:
+		var value uint64
+		value, err = strconv.ParseUint(string(raw), 10, 32)
+		pdu.Value = uint(value)
+	case gosnmp.TimeTicks:
+		var value uint64
+		value, err = strconv.ParseUint(string(raw), 10, 32)
+		pdu.Value = uint32(value)
+	case gosnmp.Counter64:
+		pdu.Value, err = strconv.ParseUint(string(raw), 10, 64)
+	}
+	if err != nil {
+		return pdu, fmt.Errorf("OID %s: invalid %s value %q: %w", oid, typ, raw, err)
+	}
+	return pdu, nil
+}
+
+func isOID(oid string) bool {
+	if oid == "" {
+		return false
+	}
+	for _, part := range strings.Split(oid, ".") {
+		if _, err := strconv.ParseUint(part, 10, 32); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// parseIPAddress parses an IP address either in its binary form or dotted notation
+func parseIPAddress(raw []byte, binaryForm bool) (string, error) {
+	if binaryForm {
+		if len(raw) != net.IPv4len {
+			return "", fmt.Errorf("expected %d bytes, got %d", net.IPv4len, len(raw))
+		}
+		return net.IP(raw).String(), nil
+	}
+	ip := net.ParseIP(string(raw)).To4()
+	if ip == nil {
+		return "", fmt.Errorf("not an IPv4 address")
+	}
+	return ip.String(), nil
+}
+
+// parseOpaque decodes the floats wrapped in opaque values (RFC draft-perkins-opaque-01),
+// the way gosnmp does when it receives them
+func parseOpaque(pdu gosnmp.SnmpPDU, raw []byte) gosnmp.SnmpPDU {
+	if len(raw) > 3 && raw[0] == gosnmp.AsnExtensionTag && int(raw[2]) == len(raw)-3 {
+		switch gosnmp.Asn1BER(raw[1]) {
+		case gosnmp.OpaqueFloat:
+			if raw[2] == 4 {
+				pdu.Type = gosnmp.OpaqueFloat
+				pdu.Value = math.Float32frombits(binary.BigEndian.Uint32(raw[3:]))
+				return pdu
+			}
+		case gosnmp.OpaqueDouble:
+			if raw[2] == 8 {
+				pdu.Type = gosnmp.OpaqueDouble
+				pdu.Value = math.Float64frombits(binary.BigEndian.Uint64(raw[3:]))
+				return pdu
+			}
+		}
+	}
+	pdu.Value = raw
+	return pdu
+}
+
+// unescape decodes the values escaped the way Python escapes bytes, e.g. `\x02\t\\`
+func unescape(value string) ([]byte, error) {
+	result := make([]byte, 0, len(value))
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c != '\\' {
+			result = append(result, c)
+			continue
+		}
+		i++
+		if i >= len(value) {
+			return nil, fmt.Errorf("trailing backslash")
+		}
+		switch value[i] {
+		case 'x':
+			if i+2 >= len(value) {
+				return nil, fmt.Errorf("truncated escape sequence at offset %d", i-1)
+			}
+			b, err := strconv.ParseUint(value[i+1:i+3], 16, 8)
+			if err != nil {
+				return nil, fmt.Errorf("invalid escape sequence at offset %d", i-1)
+			}
+			result = append(result, byte(b))
+			i += 2
+		case 'n':
+			result = append(result, '\n')
+		case 'r':
+			result = append(result, '\r')
+		case 't':
+			result = append(result, '\t')
+		case '0':
+			result = append(result, 0)
+		case '\\', '\'', '"':
+			result = append(result, value[i])
+		default:
+			return nil, fmt.Errorf("unknown escape sequence \\%c", value[i])
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package snmprec
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	walk := `# recorded from a test device
+1.3.6.1.2.1.1.1.0|4|Linux 5.10 x86_64|with a separator
+1.3.6.1.2.1.1.2.0|6|1.3.6.1.4.1.8072.3.2.10
+.1.3.6.1.2.1.1.3.0|67|4226041
+1.3.6.1.2.1.1.5.0|4x|6d792d646576696365
+
+1.3.6.1.2.1.2.2.1.7.1|2|-1
+1.3.6.1.2.1.2.2.1.10.1|65|4294967295
+1.3.6.1.2.1.2.2.1.5.1|66|1000
+1.3.6.1.2.1.31.1.1.1.6.1|70|18446744073709551615
+1.3.6.1.2.1.4.20.1.1.10.0.0.1|64|10.0.0.1
+1.3.6.1.2.1.4.20.1.1.10.0.0.2|64x|0a000002
+1.3.6.1.4.1.29671.1.1.4.1.1.2|4e|\x02\t\\a
+1.3.6.1.4.1.2021.10.1.6.1|68x|9f78043e8a3d71
+1.3.6.1.4.1.2021.10.1.6.2|68x|0102
+1.3.6.1.4.1.99.1.0|5|
+1.3.6.1.4.1.99.2.0|129|
+`
+	pdus, err := Parse(strings.NewReader(walk))
+	require.NoError(t, err)
+	assert.Equal(t, []gosnmp.SnmpPDU{
+		{Name: "1.3.6.1.2.1.1.1.0", Type: gosnmp.OctetString, Value: []byte("Linux 5.10 x86_64|with a separator")},
+		{Name: "1.3.6.1.2.1.1.2.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.8072.3.2.10"},
+		{Name: "1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(4226041)},
+		{Name: "1.3.6.1.2.1.1.5.0", Type: gosnmp.OctetString, Value: []byte("my-device")},
+		{Name: "1.3.6.1.2.1.2.2.1.7.1", Type: gosnmp.Integer, Value: -1},
+		{Name: "1.3.6.1.2.1.2.2.1.10.1", Type: gosnmp.Counter32, Value: uint(4294967295)},
+		{Name: "1.3.6.1.2.1.2.2.1.5.1", Type: gosnmp.Gauge32, Value: uint(1000)},
+		{Name: "1.3.6.1.2.1.31.1.1.1.6.1", Type: gosnmp.Counter64, Value: uint64(18446744073709551615)},
+		{Name: "1.3.6.1.2.1.4.20.1.1.10.0.0.1", Type: gosnmp.IPAddress, Value: "10.0.0.1"},
+		{Name: "1.3.6.1.2.1.4.20.1.1.10.0.0.2", Type: gosnmp.IPAddress, Value: "10.0.0.2"},
+		{Name: "1.3.6.1.4.1.29671.1.1.4.1.1.2", Type: gosnmp.OctetString, Value: []byte{0x02, '\t', '\\', 'a'}},
+		{Name: "1.3.6.1.4.1.2021.10.1.6.1", Type: gosnmp.OpaqueFloat, Value: float32(0.27)},
+		{Name: "1.3.6.1.4.1.2021.10.1.6.2", Type: gosnmp.Opaque, Value: []byte{0x01, 0x02}},
+		{Name: "1.3.6.1.4.1.99.1.0", Type: gosnmp.Null},
+		{Name: "1.3.6.1.4.1.99.2.0", Type: gosnmp.NoSuchInstance},
+	}, pdus)
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		walk          string
+		expectedError string
+	}{
+		{name: "missing fields", walk: "1.3.6.1.2.1.1.5.0|4", expectedError: "line 1: invalid record"},
+		{name: "invalid OID", walk: "iso.3.6|4|value", expectedError: `invalid OID "iso.3.6"`},
+		{name: "unsupported type", walk: "1.3.6.1.2.1.1.5.0|63|1", expectedError: `unsupported type "63"`},
+		{name: "variation module", walk: "1.3.6.1.2.1.1.5.0|2:delay|value=1,wait=10", expectedError: "variation modules are not supported"},
+		{name: "invalid integer", walk: "\n1.3.6.1.2.1.1.7.0|2|seventy", expectedError: "line 2: OID 1.3.6.1.2.1.1.7.0: invalid Integer value"},
+		{name: "integer overflow", walk: "1.3.6.1.2.1.1.7.0|2|2147483648", expectedError: "invalid Integer value"},
+		{name: "invalid hex", walk: "1.3.6.1.2.1.1.5.0|4x|6d7", expectedError: "invalid hex value"},
+		{name: "invalid escape", walk: `1.3.6.1.2.1.1.5.0|4e|\x0`, expectedError: "truncated escape sequence"},
+		{name: "invalid IP address", walk: "1.3.6.1.2.1.4.20.1.1.10.0.0.1|64|10.0.0", expectedError: "not an IPv4 address"},
+		{name: "invalid OID value", walk: "1.3.6.1.2.1.1.2.0|6|enterprises.8072", expectedError: "invalid OID value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.walk))
+			assert.ErrorContains(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "device.snmprec")
+	require.NoError(t, os.WriteFile(path, []byte("1.3.6.1.2.1.1.5.0|4|my-device\n1.3.6.1.2.1.1.7.0|2|x\n"), 0o600))
+
+	_, err := ReadFile(path)
+	assert.ErrorContains(t, err, path+": line 2")
+
+	_, err = ReadFile(filepath.Join(t.TempDir(), "missing.snmprec"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
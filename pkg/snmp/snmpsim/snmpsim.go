@@ -0,0 +1,433 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package snmpsim serves recorded SNMP walks as a local SNMP v2c/v3 agent, to test the SNMP
+// integrations end-to-end without real devices.
+package snmpsim
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/DataDog/datadog-agent/pkg/snmp/gosnmplib"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// maxMessageSize is the largest payload of a UDP datagram
+	maxMessageSize = 65507
+	// maxBulkVariables bounds the number of variables returned in reply to a GetBulk request
+	maxBulkVariables = 2000
+
+	usmStatsUnsupportedSecLevels = ".1.3.6.1.6.3.15.1.1.1.0"
+	usmStatsUnknownUserNames     = ".1.3.6.1.6.3.15.1.1.3.0"
+	usmStatsUnknownEngineIDs     = ".1.3.6.1.6.3.15.1.1.4.0"
+)
+
+// Config holds the credentials accepted by the agent. SNMPv2c requests are served if Community is
+// set, and SNMPv3 requests if User is set.
+type Config struct {
+	Community    string
+	User         string
+	AuthProtocol gosnmp.SnmpV3AuthProtocol
+	AuthKey      string
+	PrivProtocol gosnmp.SnmpV3PrivProtocol
+	PrivKey      string
+	// EngineID is the authoritative engine ID of the agent, a random one is generated if empty
+	EngineID string
+}
+
+// Agent is an SNMP agent serving a walk
+type Agent struct {
+	config    Config
+	walk      *walk
+	engineID  string
+	msgFlags  gosnmp.SnmpV3MsgFlags
+	usm       *gosnmp.UsmSecurityParameters
+	decoder   *gosnmp.GoSNMP
+	startTime time.Time
+
+	mu     sync.Mutex
+	conn   net.PacketConn
+	closed bool
+	// reports counts the reports sent per cause, reported as the values of the usmStats counters
+	reports map[string]uint32
+}
+
+// walk holds the PDUs of a walk sorted by OID
+type walk struct {
+	pdus map[string]gosnmp.SnmpPDU
+	oids [][]int
+}
+
+// New returns an agent serving the given PDUs
+func New(pdus []gosnmp.SnmpPDU, config Config) (*Agent, error) {
+	if config.Community == "" && config.User == "" {
+		return nil, errors.New("either a community string or an SNMPv3 user is required")
+	}
+	w, err := newWalk(pdus)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Agent{
+		config:    config,
+		walk:      w,
+		engineID:  config.EngineID,
+		startTime: time.Now(),
+		reports:   make(map[string]uint32),
+	}
+	if a.engineID == "" {
+		a.engineID, err = newEngineID()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case config.PrivProtocol > gosnmp.NoPriv:
+		if config.AuthProtocol <= gosnmp.NoAuth {
+			return nil, errors.New("an authentication protocol is required along with the privacy protocol")
+		}
+		a.msgFlags = gosnmp.AuthPriv
+	case config.AuthProtocol > gosnmp.NoAuth:
+		a.msgFlags = gosnmp.AuthNoPriv
+	default:
+		a.msgFlags = gosnmp.NoAuthNoPriv
+	}
+	a.usm = &gosnmp.UsmSecurityParameters{
+		AuthoritativeEngineID:    a.engineID,
+		AuthoritativeEngineBoots: 1,
+		UserName:                 config.User,
+		AuthenticationProtocol:   config.AuthProtocol,
+		AuthenticationPassphrase: config.AuthKey,
+		PrivacyProtocol:          config.PrivProtocol,
+		PrivacyPassphrase:        config.PrivKey,
+	}
+	if err := a.usm.InitSecurityKeys(); err != nil {
+		return nil, fmt.Errorf("invalid SNMPv3 credentials: %w", err)
+	}
+	a.decoder = &gosnmp.GoSNMP{
+		Version:            gosnmp.Version3,
+		SecurityModel:      gosnmp.UserSecurityModel,
+		MsgFlags:           a.msgFlags,
+		SecurityParameters: a.usm,
+	}
+	return a, nil
+}
+
+// newEngineID generates an engine ID in the format of RFC 3411, holding random octets
+func newEngineID() (string, error) {
+	random := make([]byte, 8)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	// enterprise 8072 (net-snmp) with the "octets" format, as generated by most agents
+	return string(append([]byte{0x80, 0x00, 0x1f, 0x88, 0x05}, random...)), nil
+}
+
+// Listen binds the agent to the given UDP address, e.g. 127.0.0.1:1161
+func (a *Agent) Listen(address string) error {
+	conn, err := net.ListenPacket("udp", address)
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.conn = conn
+	return nil
+}
+
+// Addr returns the address the agent is bound to
+func (a *Agent) Addr() net.Addr {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.conn == nil {
+		return nil
+	}
+	return a.conn.LocalAddr()
+}
+
+// Serve replies to the requests until the agent is closed
+func (a *Agent) Serve() error {
+	a.mu.Lock()
+	conn := a.conn
+	a.mu.Unlock()
+	if conn == nil {
+		return errors.New("the agent is not listening")
+	}
+
+	buf := make([]byte, 65536)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			a.mu.Lock()
+			closed := a.closed
+			a.mu.Unlock()
+			if closed {
+				return nil
+			}
+			return err
+		}
+		msg := make([]byte, n)
+		copy(msg, buf[:n])
+		response, err := a.handle(msg)
+		if err != nil {
+			log.Debugf("dropping the request from %s: %s", addr, err)
+			continue
+		}
+		if _, err := conn.WriteTo(response, addr); err != nil {
+			log.Debugf("unable to reply to %s: %s", addr, err)
+		}
+	}
+}
+
+// Close stops the agent
+func (a *Agent) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.closed = true
+	if a.conn == nil {
+		return nil
+	}
+	return a.conn.Close()
+}
+
+// handle returns the reply to the given message
+func (a *Agent) handle(msg []byte) ([]byte, error) {
+	request, err := a.decoder.UnmarshalTrap(msg, true)
+	if err != nil {
+		return nil, err
+	}
+
+	switch request.Version {
+	case gosnmp.Version2c:
+		if a.config.Community == "" || request.Community != a.config.Community {
+			return nil, errors.New("unknown community")
+		}
+		response := a.respond(request)
+		response.Version = gosnmp.Version2c
+		response.Community = request.Community
+		return a.marshal(response, request)
+	case gosnmp.Version3:
+		sp, ok := request.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+		if !ok {
+			return nil, errors.New("unsupported security model")
+		}
+		switch {
+		case sp.AuthoritativeEngineID != a.engineID:
+			// engine discovery (RFC 3414 section 4)
+			return a.report(request, usmStatsUnknownEngineIDs)
+		case a.config.User == "" || sp.UserName != a.config.User:
+			return a.report(request, usmStatsUnknownUserNames)
+		case request.MsgFlags&gosnmp.AuthPriv != a.msgFlags:
+			return a.report(request, usmStatsUnsupportedSecLevels)
+		}
+		response := a.respond(request)
+		response.MsgFlags = a.msgFlags
+		response.SecurityParameters = a.securityParameters(sp.UserName)
+		if a.msgFlags&gosnmp.AuthPriv > gosnmp.AuthNoPriv {
+			// generates the salt of the encryption
+			if err := a.usm.InitPacket(response); err != nil {
+				return nil, err
+			}
+		}
+		return a.marshal(response, request)
+	default:
+		return nil, fmt.Errorf("unsupported SNMP version %s", request.Version)
+	}
+}
+
+// respond returns the response to the PDU of the request
+func (a *Agent) respond(request *gosnmp.SnmpPacket) *gosnmp.SnmpPacket {
+	response := &gosnmp.SnmpPacket{
+		Version:         request.Version,
+		SecurityModel:   request.SecurityModel,
+		MsgID:           request.MsgID,
+		ContextEngineID: a.engineID,
+		ContextName:     request.ContextName,
+		PDUType:         gosnmp.GetResponse,
+		RequestID:       request.RequestID,
+	}
+
+	switch request.PDUType {
+	case gosnmp.GetRequest:
+		for _, variable := range request.Variables {
+			response.Variables = append(response.Variables, a.walk.get(variable.Name))
+		}
+	case gosnmp.GetNextRequest:
+		for _, variable := range request.Variables {
+			response.Variables = append(response.Variables, a.walk.next(variable.Name))
+		}
+	case gosnmp.GetBulkRequest:
+		response.Variables = a.walk.bulk(request.Variables, int(request.NonRepeaters), int(request.MaxRepetitions))
+	default:
+		// the walk is read-only
+		response.Error = gosnmp.NotWritable
+		response.ErrorIndex = 1
+		response.Variables = request.Variables
+	}
+	return response
+}
+
+// marshal encodes the response, truncating the variables of GetBulk responses to fit in a message
+func (a *Agent) marshal(response *gosnmp.SnmpPacket, request *gosnmp.SnmpPacket) ([]byte, error) {
+	maxSize := maxMessageSize
+	if request.MsgMaxSize > 0 && int(request.MsgMaxSize) < maxSize {
+		maxSize = int(request.MsgMaxSize)
+	}
+	for {
+		msg, err := response.MarshalMsg()
+		if err != nil {
+			return nil, err
+		}
+		if len(msg) <= maxSize {
+			return msg, nil
+		}
+		if request.PDUType == gosnmp.GetBulkRequest && len(response.Variables) > 1 {
+			response.Variables = response.Variables[:len(response.Variables)/2]
+			continue
+		}
+		response.Error = gosnmp.TooBig
+		response.ErrorIndex = 0
+		response.Variables = nil
+	}
+}
+
+// report returns an unauthenticated report PDU for the given usmStats counter
+func (a *Agent) report(request *gosnmp.SnmpPacket, counterOID string) ([]byte, error) {
+	if request.MsgFlags&gosnmp.Reportable == 0 {
+		return nil, fmt.Errorf("request not reportable (%s)", counterOID)
+	}
+	a.mu.Lock()
+	a.reports[counterOID]++
+	count := a.reports[counterOID]
+	a.mu.Unlock()
+
+	sp := a.securityParameters("")
+	if usm, ok := request.SecurityParameters.(*gosnmp.UsmSecurityParameters); ok {
+		sp.UserName = usm.UserName
+	}
+	report := &gosnmp.SnmpPacket{
+		Version:            gosnmp.Version3,
+		MsgFlags:           gosnmp.NoAuthNoPriv,
+		SecurityModel:      gosnmp.UserSecurityModel,
+		SecurityParameters: sp,
+		MsgID:              request.MsgID,
+		ContextEngineID:    a.engineID,
+		ContextName:        request.ContextName,
+		PDUType:            gosnmp.Report,
+		RequestID:          request.RequestID,
+		Variables: []gosnmp.SnmpPDU{{
+			Name:  counterOID,
+			Type:  gosnmp.Counter32,
+			Value: count,
+		}},
+	}
+	return report.MarshalMsg()
+}
+
+// securityParameters returns the security parameters of the messages sent by the agent
+func (a *Agent) securityParameters(userName string) *gosnmp.UsmSecurityParameters {
+	sp := a.usm.Copy().(*gosnmp.UsmSecurityParameters)
+	sp.UserName = userName
+	sp.AuthoritativeEngineTime = uint32(time.Since(a.startTime).Seconds())
+	sp.AuthenticationParameters = ""
+	sp.PrivacyParameters = nil
+	return sp
+}
+
+func newWalk(pdus []gosnmp.SnmpPDU) (*walk, error) {
+	w := &walk{pdus: make(map[string]gosnmp.SnmpPDU, len(pdus))}
+	for _, pdu := range pdus {
+		oid := strings.TrimLeft(pdu.Name, ".")
+		nums, err := gosnmplib.OIDToInts(oid)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := w.pdus[oid]; !ok {
+			w.oids = append(w.oids, nums)
+		}
+		pdu.Name = oid
+		w.pdus[oid] = pdu
+	}
+	sort.Slice(w.oids, func(i, j int) bool {
+		return gosnmplib.CmpOIDs(w.oids[i], w.oids[j]).IsBefore()
+	})
+	return w, nil
+}
+
+func oidString(nums []int) string {
+	parts := make([]string, len(nums))
+	for i, num := range nums {
+		parts[i] = fmt.Sprint(num)
+	}
+	return strings.Join(parts, ".")
+}
+
+// get returns the PDU of the given OID, or a noSuchObject exception
+func (w *walk) get(oid string) gosnmp.SnmpPDU {
+	if pdu, ok := w.pdus[strings.TrimLeft(oid, ".")]; ok {
+		return pdu
+	}
+	return gosnmp.SnmpPDU{Name: oid, Type: gosnmp.NoSuchObject}
+}
+
+// next returns the PDU following the given OID, or an endOfMibView exception
+func (w *walk) next(oid string) gosnmp.SnmpPDU {
+	nums, err := gosnmplib.OIDToInts(oid)
+	if err != nil {
+		return gosnmp.SnmpPDU{Name: oid, Type: gosnmp.EndOfMibView}
+	}
+	index := sort.Search(len(w.oids), func(i int) bool {
+		return gosnmplib.CmpOIDs(w.oids[i], nums).IsAfter()
+	})
+	if index == len(w.oids) {
+		return gosnmp.SnmpPDU{Name: oid, Type: gosnmp.EndOfMibView}
+	}
+	return w.pdus[oidString(w.oids[index])]
+}
+
+// bulk returns the variables of the response to a GetBulk request (RFC 3416 section 4.2.3)
+func (w *walk) bulk(variables []gosnmp.SnmpPDU, nonRepeaters int, maxRepetitions int) []gosnmp.SnmpPDU {
+	nonRepeaters = min(nonRepeaters, len(variables))
+	var result []gosnmp.SnmpPDU
+	for _, variable := range variables[:nonRepeaters] {
+		result = append(result, w.next(variable.Name))
+	}
+
+	repeaters := variables[nonRepeaters:]
+	if len(repeaters) == 0 {
+		return result
+	}
+	maxRepetitions = min(maxRepetitions, maxBulkVariables/len(repeaters))
+	last := make([]string, len(repeaters))
+	for i, variable := range repeaters {
+		last[i] = variable.Name
+	}
+	for repetition := 0; repetition < maxRepetitions; repetition++ {
+		ended := true
+		for i := range repeaters {
+			pdu := w.next(last[i])
+			if pdu.Type != gosnmp.EndOfMibView {
+				ended = false
+			}
+			last[i] = pdu.Name
+			result = append(result, pdu)
+		}
+		if ended {
+			break
+		}
+	}
+	return result
+}
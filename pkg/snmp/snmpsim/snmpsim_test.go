@@ -0,0 +1,213 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package snmpsim
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPDUs() []gosnmp.SnmpPDU {
+	pdus := []gosnmp.SnmpPDU{
+		{Name: "1.3.6.1.2.1.1.1.0", Type: gosnmp.OctetString, Value: []byte("test device")},
+		{Name: "1.3.6.1.2.1.1.2.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.8072.3.2.10"},
+		{Name: "1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(4226041)},
+		{Name: "1.3.6.1.4.1.2021.10.1.6.1", Type: gosnmp.OpaqueFloat, Value: float32(0.5)},
+	}
+	// the interfaces are out of order, as they can be in a walk file
+	for i := 12; i >= 1; i-- {
+		pdus = append(pdus,
+			gosnmp.SnmpPDU{Name: fmt.Sprintf("1.3.6.1.2.1.2.2.1.2.%d", i), Type: gosnmp.OctetString, Value: []byte(fmt.Sprintf("eth%d", i))},
+			gosnmp.SnmpPDU{Name: fmt.Sprintf("1.3.6.1.2.1.2.2.1.10.%d", i), Type: gosnmp.Counter32, Value: uint(i * 1000)},
+		)
+	}
+	return pdus
+}
+
+func startAgent(t *testing.T, config Config) *Agent {
+	agent, err := New(testPDUs(), config)
+	require.NoError(t, err)
+	require.NoError(t, agent.Listen("127.0.0.1:0"))
+	go func() {
+		assert.NoError(t, agent.Serve())
+	}()
+	t.Cleanup(func() { agent.Close() })
+	return agent
+}
+
+func newClient(t *testing.T, agent *Agent) *gosnmp.GoSNMP {
+	_, port, err := net.SplitHostPort(agent.Addr().String())
+	require.NoError(t, err)
+	portNumber, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	return &gosnmp.GoSNMP{
+		Target:         "127.0.0.1",
+		Port:           uint16(portNumber),
+		Transport:      "udp",
+		Version:        gosnmp.Version2c,
+		Community:      "public",
+		Timeout:        time.Second,
+		MaxOids:        gosnmp.MaxOids,
+		MaxRepetitions: 10,
+	}
+}
+
+func assertWalkServed(t *testing.T, client *gosnmp.GoSNMP) {
+	require.NoError(t, client.Connect())
+	defer client.Conn.Close()
+
+	result, err := client.Get([]string{"1.3.6.1.2.1.1.1.0", "1.3.6.1.2.1.1.3.0", "1.3.6.1.2.1.1.4.0"})
+	require.NoError(t, err)
+	require.Len(t, result.Variables, 3)
+	assert.Equal(t, []byte("test device"), result.Variables[0].Value)
+	assert.Equal(t, uint32(4226041), result.Variables[1].Value)
+	assert.Equal(t, gosnmp.NoSuchObject, result.Variables[2].Type)
+
+	result, err = client.GetNext([]string{"1.3.6.1.2.1.1.2", "1.3.6.1.4.1.2021.10.1.6"})
+	require.NoError(t, err)
+	assert.Equal(t, ".1.3.6.1.2.1.1.2.0", result.Variables[0].Name)
+	assert.Equal(t, ".1.3.6.1.4.1.8072.3.2.10", result.Variables[0].Value)
+	assert.Equal(t, gosnmp.OpaqueFloat, result.Variables[1].Type)
+	assert.Equal(t, float32(0.5), result.Variables[1].Value)
+
+	// the walk of a table is sorted by OID
+	pdus, err := client.BulkWalkAll("1.3.6.1.2.1.2.2.1.2")
+	require.NoError(t, err)
+	require.Len(t, pdus, 12)
+	for i, pdu := range pdus {
+		assert.Equal(t, fmt.Sprintf(".1.3.6.1.2.1.2.2.1.2.%d", i+1), pdu.Name)
+	}
+
+	pdus, err = client.WalkAll("1.3.6.1.2.1.2.2.1.10")
+	require.NoError(t, err)
+	require.Len(t, pdus, 12)
+	assert.Equal(t, uint(12000), pdus[11].Value)
+}
+
+func TestAgent_V2c(t *testing.T) {
+	agent := startAgent(t, Config{Community: "public"})
+	assertWalkServed(t, newClient(t, agent))
+
+	client := newClient(t, agent)
+	client.Community = "private"
+	client.Retries = 0
+	client.Timeout = 200 * time.Millisecond
+	require.NoError(t, client.Connect())
+	defer client.Conn.Close()
+	_, err := client.Get([]string{"1.3.6.1.2.1.1.1.0"})
+	assert.Error(t, err, "requests with an unknown community are dropped")
+}
+
+func TestAgent_V3(t *testing.T) {
+	tests := []struct {
+		name         string
+		authProtocol gosnmp.SnmpV3AuthProtocol
+		privProtocol gosnmp.SnmpV3PrivProtocol
+		msgFlags     gosnmp.SnmpV3MsgFlags
+	}{
+		{name: "noAuthNoPriv", msgFlags: gosnmp.NoAuthNoPriv},
+		{name: "authNoPriv", authProtocol: gosnmp.SHA, msgFlags: gosnmp.AuthNoPriv},
+		{name: "authPriv", authProtocol: gosnmp.SHA256, privProtocol: gosnmp.AES, msgFlags: gosnmp.AuthPriv},
+		{name: "authPriv des", authProtocol: gosnmp.MD5, privProtocol: gosnmp.DES, msgFlags: gosnmp.AuthPriv},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := startAgent(t, Config{
+				User:         "datadog",
+				AuthProtocol: tt.authProtocol,
+				AuthKey:      "authentication-key",
+				PrivProtocol: tt.privProtocol,
+				PrivKey:      "privacy-key",
+			})
+			client := newClient(t, agent)
+			client.Version = gosnmp.Version3
+			client.SecurityModel = gosnmp.UserSecurityModel
+			client.MsgFlags = tt.msgFlags
+			client.SecurityParameters = &gosnmp.UsmSecurityParameters{
+				UserName:                 "datadog",
+				AuthenticationProtocol:   tt.authProtocol,
+				AuthenticationPassphrase: "authentication-key",
+				PrivacyProtocol:          tt.privProtocol,
+				PrivacyPassphrase:        "privacy-key",
+			}
+			assertWalkServed(t, client)
+		})
+	}
+}
+
+func TestAgent_V3Errors(t *testing.T) {
+	agent := startAgent(t, Config{
+		User:         "datadog",
+		AuthProtocol: gosnmp.SHA,
+		AuthKey:      "authentication-key",
+	})
+
+	newV3Client := func(userName string, msgFlags gosnmp.SnmpV3MsgFlags) *gosnmp.GoSNMP {
+		client := newClient(t, agent)
+		client.Version = gosnmp.Version3
+		client.Retries = 0
+		client.SecurityModel = gosnmp.UserSecurityModel
+		client.MsgFlags = msgFlags
+		client.SecurityParameters = &gosnmp.UsmSecurityParameters{
+			UserName:                 userName,
+			AuthenticationProtocol:   gosnmp.SHA,
+			AuthenticationPassphrase: "authentication-key",
+		}
+		require.NoError(t, client.Connect())
+		t.Cleanup(func() { client.Conn.Close() })
+		return client
+	}
+
+	_, err := newV3Client("unknown", gosnmp.AuthNoPriv).Get([]string{"1.3.6.1.2.1.1.1.0"})
+	assert.ErrorIs(t, err, gosnmp.ErrUnknownUsername)
+
+	_, err = newV3Client("datadog", gosnmp.NoAuthNoPriv).Get([]string{"1.3.6.1.2.1.1.1.0"})
+	assert.ErrorIs(t, err, gosnmp.ErrUnknownSecurityLevel)
+
+	client := newV3Client("datadog", gosnmp.AuthNoPriv)
+	client.Timeout = 200 * time.Millisecond
+	client.SecurityParameters.(*gosnmp.UsmSecurityParameters).AuthenticationPassphrase = "wrong-authentication-key"
+	_, err = client.Get([]string{"1.3.6.1.2.1.1.1.0"})
+	assert.Error(t, err, "requests failing authentication are dropped")
+}
+
+func TestAgent_GetBulkTruncated(t *testing.T) {
+	var pdus []gosnmp.SnmpPDU
+	for i := 1; i <= 1000; i++ {
+		pdus = append(pdus, gosnmp.SnmpPDU{Name: fmt.Sprintf("1.3.6.1.2.1.2.2.1.2.%d", i), Type: gosnmp.OctetString, Value: make([]byte, 200)})
+	}
+	agent, err := New(pdus, Config{Community: "public"})
+	require.NoError(t, err)
+
+	request := &gosnmp.SnmpPacket{
+		Version:        gosnmp.Version2c,
+		PDUType:        gosnmp.GetBulkRequest,
+		MaxRepetitions: 1000,
+		Variables:      []gosnmp.SnmpPDU{{Name: "1.3.6.1.2.1.2.2.1.2", Type: gosnmp.Null}},
+	}
+	response := agent.respond(request)
+	require.Len(t, response.Variables, 1000)
+
+	msg, err := agent.marshal(response, request)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(msg), maxMessageSize)
+	assert.Len(t, response.Variables, 250)
+}
+
+func TestNew_Errors(t *testing.T) {
+	_, err := New(testPDUs(), Config{})
+	assert.ErrorContains(t, err, "either a community string or an SNMPv3 user is required")
+
+	_, err = New(testPDUs(), Config{User: "datadog", PrivProtocol: gosnmp.AES, PrivKey: "privacy-key"})
+	assert.ErrorContains(t, err, "an authentication protocol is required")
+}
@@ -107,11 +107,13 @@ type Agent struct {
 	ClientStatsAggregator *stats.ClientStatsAggregator
 	Blacklister           *filters.Blacklister
 	Replacer              *filters.Replacer
+	SpanFilter            *filters.SpanFilter
 	PrioritySampler       *sampler.PrioritySampler
 	ErrorsSampler         *sampler.ErrorsSampler
 	RareSampler           *sampler.RareSampler
 	NoPrioritySampler     *sampler.NoPrioritySampler
 	ProbabilisticSampler  *sampler.ProbabilisticSampler
+	TailSampler           *sampler.TailSampler
 	SamplerMetrics        *sampler.Metrics
 	EventProcessor        *event.Processor
 	TraceWriter           TraceWriter
@@ -186,6 +188,7 @@ func NewAgent(ctx context.Context, conf *config.AgentConfig, telemetryCollector
 		ClientStatsAggregator: stats.NewClientStatsAggregator(conf, statsWriter, statsd),
 		Blacklister:           filters.NewBlacklister(conf.Ignore["resource"]),
 		Replacer:              filters.NewReplacer(conf.ReplaceTags),
+		SpanFilter:            filters.NewSpanFilter(conf.SpanFilters),
 		PrioritySampler:       sampler.NewPrioritySampler(conf, dynConf),
 		ErrorsSampler:         sampler.NewErrorsSampler(conf),
 		RareSampler:           sampler.NewRareSampler(conf),
@@ -204,10 +207,11 @@ func NewAgent(ctx context.Context, conf *config.AgentConfig, telemetryCollector
 		Timing:                timing,
 		processWg:             &sync.WaitGroup{},
 	}
-	agnt.SamplerMetrics.Add(agnt.PrioritySampler, agnt.ErrorsSampler, agnt.NoPrioritySampler, agnt.RareSampler)
+	agnt.TailSampler = sampler.NewTailSampler(conf, agnt.flushTailTrace)
+	agnt.SamplerMetrics.Add(agnt.PrioritySampler, agnt.ErrorsSampler, agnt.NoPrioritySampler, agnt.RareSampler, agnt.TailSampler)
 	agnt.Receiver = api.NewHTTPReceiver(conf, dynConf, in, inV1, agnt, telemetryCollector, statsd, timing)
 	agnt.OTLPReceiver = api.NewOTLPReceiver(in, conf, statsd, timing)
-	agnt.RemoteConfigHandler = remoteconfighandler.New(conf, agnt.PrioritySampler, agnt.RareSampler, agnt.ErrorsSampler)
+	agnt.RemoteConfigHandler = remoteconfighandler.New(conf, agnt.PrioritySampler, agnt.RareSampler, agnt.ErrorsSampler, agnt.SpanFilter)
 	agnt.TraceWriter = writer.NewTraceWriter(conf, agnt.PrioritySampler, agnt.ErrorsSampler, agnt.RareSampler, telemetryCollector, statsd, timing, comp)
 	agnt.TraceWriterV1 = writer.NewTraceWriterV1(conf, agnt.PrioritySampler, agnt.ErrorsSampler, agnt.RareSampler, telemetryCollector, statsd, timing, comp)
 	return agnt
@@ -222,6 +226,7 @@ func (a *Agent) Run() {
 		a.Concentrator,
 		a.ClientStatsAggregator,
 		a.SamplerMetrics,
+		a.TailSampler,
 		a.EventProcessor,
 		a.OTLPReceiver,
 		a.RemoteConfigHandler,
@@ -323,6 +328,7 @@ func (a *Agent) loop() {
 	for _, stopper := range []interface{ Stop() }{
 		a.Concentrator,
 		a.ClientStatsAggregator,
+		a.TailSampler, // flushes the buffered traces to the TraceWriter
 		a.TraceWriter,
 		a.StatsWriter,
 		a.SamplerMetrics,
@@ -430,6 +436,9 @@ func (a *Agent) Process(p *api.Payload) {
 
 	a.discardSpans(p)
 
+	// tailPayload holds the payload metadata shared by all the chunks handed to the TailSampler.
+	var tailPayload *pb.TracerPayload
+
 	for i := 0; i < len(p.Chunks()); {
 		chunk := p.Chunk(i)
 		if len(chunk.Spans) == 0 {
@@ -467,6 +476,17 @@ func (a *Agent) Process(p *api.Payload) {
 			continue
 		}
 
+		if dropped := a.SpanFilter.Apply(chunk); dropped > 0 {
+			ts.SpansFiltered.Add(int64(dropped))
+			if len(chunk.Spans) == 0 {
+				log.Debugf("Trace rejected as all its spans were dropped by span filter rules. root: %v", root)
+				ts.TracesFiltered.Inc()
+				p.RemoveChunk(i)
+				continue
+			}
+			root = traceutil.GetRoot(chunk.Spans)
+		}
+
 		// Extra sanitization steps of the trace.
 		for _, span := range chunk.Spans {
 			for k, v := range a.conf.GlobalTags {
@@ -501,7 +521,37 @@ func (a *Agent) Process(p *api.Payload) {
 			statsInput.Traces = append(statsInput.Traces, *pt.Clone())
 		}
 
+		var tailChunk *pb.TraceChunk
+		if a.tailSampling() {
+			// the samplers may replace the spans of the chunk, keep them for the tail sampler
+			tailChunk = pt.TraceChunk.ShallowCopy()
+		}
 		keep, numEvents := a.sample(now, ts, pt)
+		if tailChunk != nil {
+			traceID := tailChunk.Spans[0].TraceID
+			if keep {
+				a.TailSampler.MarkKept(now, traceID)
+			} else if priority, _ := sampler.GetSamplingPriority(tailChunk); priority != sampler.PriorityUserDrop {
+				// The trace was dropped by the head samplers: the tail sampler may still keep it
+				// once it is complete. What the head samplers kept of it is only sent if it doesn't.
+				if tailPayload == nil {
+					tailPayload = tracerPayloadMetadata(p.TracerPayload)
+				}
+				if len(statsInput.Traces) > 0 {
+					// The tail sampler modifies the spans of the trace once it is decided, from
+					// another goroutine: their stats must be computed before it gets them.
+					a.Concentrator.Add(statsInput)
+					statsInput.Traces = nil
+				}
+				tc := sampler.TailChunk{Payload: tailPayload, Chunk: tailChunk, Env: pt.TracerEnv, Events: numEvents}
+				if len(pt.TraceChunk.Spans) > 0 {
+					tc.Dropped = pt.TraceChunk
+				}
+				a.TailSampler.Add(now, tc)
+				p.RemoveChunk(i)
+				continue
+			}
+		}
 		if !keep && len(pt.TraceChunk.Spans) == 0 {
 			// The entire trace was dropped and no spans were kept.
 			p.RemoveChunk(i)
@@ -609,6 +659,17 @@ func (a *Agent) ProcessV1(p *api.PayloadV1) {
 			continue
 		}
 
+		if dropped := a.SpanFilter.ApplyV1(chunk); dropped > 0 {
+			ts.SpansFiltered.Add(int64(dropped))
+			if len(chunk.Spans) == 0 {
+				log.Debugf("Trace rejected as all its spans were dropped by span filter rules. root: %v", root)
+				ts.TracesFiltered.Inc()
+				p.TracerPayload.RemoveChunk(i)
+				continue
+			}
+			root = traceutil.GetRootV1(chunk)
+		}
+
 		// Extra sanitization steps of the trace.
 		for _, span := range chunk.Spans {
 			for k, v := range a.conf.GlobalTags {
@@ -637,7 +698,34 @@ func (a *Agent) ProcessV1(p *api.PayloadV1) {
 			statsInput.Traces = append(statsInput.Traces, *pt.Clone())
 		}
 
+		var tailChunk *idx.InternalTraceChunk
+		if a.tailSampling() {
+			// the samplers may replace the spans of the chunk, keep them for the tail sampler
+			tailChunk = pt.TraceChunk.ShallowCopy()
+		}
 		keep, numEvents := a.sampleV1(now, ts, pt)
+		if tailChunk != nil {
+			if keep {
+				a.TailSampler.MarkKept(now, tailChunk.LegacyTraceID())
+			} else if priority, _ := sampler.GetSamplingPriorityV1(tailChunk); priority != sampler.PriorityUserDrop {
+				// The trace was dropped by the head samplers: the tail sampler may still keep it
+				// once it is complete. What the head samplers kept of it is only sent if it doesn't.
+				if len(statsInput.Traces) > 0 {
+					// The tail sampler modifies the spans of the trace once it is decided, from
+					// another goroutine: their stats must be computed before it gets them.
+					a.Concentrator.AddV1(statsInput)
+					statsInput.Traces = nil
+				}
+				tc := sampler.TailChunk{ChunkV1: tailChunk, Env: pt.TracerEnv, Events: numEvents}
+				if len(pt.TraceChunk.Spans) > 0 {
+					tc.DroppedV1 = pt.TraceChunk
+				}
+				tc.PayloadV1 = tracerPayloadMetadataV1(p.TracerPayload, tc.ChunkV1, tc.DroppedV1)
+				a.TailSampler.Add(now, tc)
+				p.TracerPayload.RemoveChunk(i)
+				continue
+			}
+		}
 		if !keep && len(pt.TraceChunk.Spans) == 0 {
 			// The entire trace was dropped and no spans were kept.
 			p.TracerPayload.RemoveChunk(i)
@@ -730,6 +818,94 @@ func processedTraceV1(p *api.PayloadV1, chunk *idx.InternalTraceChunk, root *idx
 	return pt
 }
 
+// tracerPayloadMetadata returns a copy of tp without its chunks.
+func tracerPayloadMetadata(tp *pb.TracerPayload) *pb.TracerPayload {
+	return &pb.TracerPayload{
+		ContainerID:     tp.ContainerID,
+		LanguageName:    tp.LanguageName,
+		LanguageVersion: tp.LanguageVersion,
+		TracerVersion:   tp.TracerVersion,
+		RuntimeID:       tp.RuntimeID,
+		Tags:            tp.Tags,
+		Env:             tp.Env,
+		Hostname:        tp.Hostname,
+		AppVersion:      tp.AppVersion,
+	}
+}
+
+// tracerPayloadMetadataV1 returns a copy of tp without its chunks, and with its own string table
+// which the given chunks are moved to, so that they can outlive tp.
+func tracerPayloadMetadataV1(tp *idx.InternalTracerPayload, chunks ...*idx.InternalTraceChunk) *idx.InternalTracerPayload {
+	metadata := tp.Cut(0)
+	metadata.Chunks = nil
+	for _, c := range chunks {
+		if c == nil {
+			continue
+		}
+		c.Strings = metadata.Strings
+		for _, span := range c.Spans {
+			span.Strings = metadata.Strings
+		}
+	}
+	return metadata
+}
+
+// tailSampling reports whether the chunks dropped by the samplers are handed to the TailSampler.
+// Error Tracking Standalone only keeps traces with errors, so it doesn't use it.
+func (a *Agent) tailSampling() bool {
+	return a.TailSampler.IsEnabled() && !a.conf.ErrorTrackingStandalone
+}
+
+// flushTailTrace writes the chunks of a trace decided by the TailSampler and records the sampling
+// decision in the sampler telemetry. The chunks of dropped traces are written only if the head
+// samplers kept some of their spans.
+func (a *Agent) flushTailTrace(t *sampler.TailTrace) {
+	a.SamplerMetrics.RecordMetricsKey(t.Keep, sampler.NewMetricsKey(t.Service, t.Env, sampler.NameTail, sampler.PriorityNone))
+	for _, c := range t.Chunks {
+		if c.ChunkV1 != nil {
+			chunk := c.ChunkV1
+			if !t.Keep {
+				chunk = c.DroppedV1
+			}
+			if chunk == nil {
+				continue
+			}
+			c.PayloadV1.Chunks = []*idx.InternalTraceChunk{chunk}
+			a.TraceWriterV1.WriteChunksV1(&writer.SampledChunksV1{
+				TracerPayload: c.PayloadV1,
+				Size:          chunk.Msgsize(),
+				SpanCount:     sampledSpanCount(chunk.DroppedTrace, len(chunk.Spans)),
+				EventCount:    int64(c.Events),
+			})
+			continue
+		}
+		chunk := c.Chunk
+		if !t.Keep {
+			chunk = c.Dropped
+		}
+		if chunk == nil {
+			continue
+		}
+		tp := tracerPayloadMetadata(c.Payload)
+		tp.Chunks = []*pb.TraceChunk{chunk}
+		a.TraceWriter.WriteChunks(&writer.SampledChunks{
+			TracerPayload: tp,
+			Size:          chunk.Msgsize(),
+			SpanCount:     sampledSpanCount(chunk.DroppedTrace, len(chunk.Spans)),
+			EventCount:    int64(c.Events),
+		})
+	}
+}
+
+// sampledSpanCount returns the number of sampled spans of a chunk: spans of dropped traces
+// are only sent as analytics events.
+func sampledSpanCount(droppedTrace bool, spans int) int64 {
+	if droppedTrace {
+		return 0
+	}
+	return int64(spans)
+}
+
 // newChunksArray creates a new array which will point only to sampled chunks.
 // The underlying array behind TracePayload.Chunks points to unsampled chunks
 // preventing them from being collected by the GC.
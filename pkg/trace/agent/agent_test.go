@@ -373,6 +373,75 @@ func TestProcess(t *testing.T) {
 		assert.EqualValues(2, want.SpansFiltered.Load())
 	})
 
+	t.Run("SpanFilter", func(t *testing.T) {
+		cfg := config.New()
+		cfg.Endpoints[0].APIKey = "test"
+		cfg.SpanFilters = []*config.SpanFilterRule{
+			{Name: "health", Action: "drop", Condition: `meta["http.url"] matches "/health" && duration < 5ms`},
+			{Name: "internal", Action: "drop", Condition: `service == "internal"`},
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		agnt := NewTestAgent(ctx, cfg, telemetry.NewNoopCollector())
+		defer cancel()
+
+		now := time.Now()
+		root := &pb.Span{
+			TraceID:  1,
+			SpanID:   1,
+			Service:  "web",
+			Resource: "GET /users",
+			Start:    now.Add(-time.Second).UnixNano(),
+			Duration: (500 * time.Millisecond).Nanoseconds(),
+			Metrics:  map[string]float64{"_sampling_priority_v1": 2},
+		}
+		health := &pb.Span{
+			TraceID:  1,
+			SpanID:   2,
+			ParentID: 1,
+			Service:  "web",
+			Resource: "GET /health",
+			Start:    now.Add(-time.Second).UnixNano(),
+			Duration: (time.Millisecond).Nanoseconds(),
+			Meta:     map[string]string{"http.url": "http://localhost/health"},
+		}
+		child := &pb.Span{
+			TraceID:  1,
+			SpanID:   3,
+			ParentID: 2,
+			Service:  "web",
+			Resource: "SELECT 1",
+			Start:    now.Add(-time.Second).UnixNano(),
+			Duration: (time.Millisecond).Nanoseconds(),
+		}
+
+		want := agnt.Receiver.Stats.GetTagStats(info.Tags{})
+		assert := assert.New(t)
+
+		chunk := testutil.TraceChunkWithSpans([]*pb.Span{root, health, child})
+		agnt.Process(&api.Payload{
+			TracerPayload: testutil.TracerPayloadWithChunk(chunk),
+			Source:        want,
+		})
+		assert.EqualValues(0, want.TracesFiltered.Load())
+		assert.EqualValues(1, want.SpansFiltered.Load())
+		assert.Equal([]*pb.Span{root, child}, chunk.Spans)
+		assert.EqualValues(1, child.ParentID)
+
+		agnt.Process(&api.Payload{
+			TracerPayload: testutil.TracerPayloadWithChunk(testutil.TraceChunkWithSpan(&pb.Span{
+				TraceID:  2,
+				SpanID:   1,
+				Service:  "internal",
+				Resource: "GET /",
+				Start:    now.Add(-time.Second).UnixNano(),
+				Duration: (500 * time.Millisecond).Nanoseconds(),
+			})),
+			Source: want,
+		})
+		assert.EqualValues(1, want.TracesFiltered.Load())
+		assert.EqualValues(2, want.SpansFiltered.Load())
+	})
+
 	t.Run("Block-all", func(t *testing.T) {
 		cfg := config.New()
 		cfg.Endpoints[0].APIKey = "test"
@@ -1535,6 +1604,96 @@ func TestClientComputedStats(t *testing.T) {
 	})
 }
 
+func TestTailSampling(t *testing.T) {
+	newAgent := func(t *testing.T) *Agent {
+		cfg := config.New()
+		cfg.Endpoints[0].APIKey = "test"
+		cfg.TailSampling.Enabled = true
+		cfg.TailSampling.Policies = []*config.TailSamplingPolicy{{Name: "slow", Type: "latency", ThresholdMs: 400}}
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		agnt := NewTestAgent(ctx, cfg, telemetry.NewNoopCollector())
+		agnt.TailSampler.Start()
+		return agnt
+	}
+
+	t.Run("v0.x", func(t *testing.T) {
+		agnt := newAgent(t)
+		process := func(span *pb.Span, priority int32, duration time.Duration) {
+			span.Start = time.Now().Add(-time.Second).UnixNano()
+			span.Duration = duration.Nanoseconds()
+			agnt.Process(&api.Payload{
+				TracerPayload: testutil.TracerPayloadWithChunk(testutil.TraceChunkWithSpanAndPriority(span, priority)),
+				Source:        agnt.Receiver.Stats.GetTagStats(info.Tags{}),
+			})
+		}
+		// trace 1 is slow, its root is received after a child span; trace 2 is fast
+		process(&pb.Span{Service: "db", Name: "query", Resource: "q", TraceID: 1, SpanID: 2, ParentID: 1}, 0, 10*time.Millisecond)
+		process(&pb.Span{Service: "web", Name: "request", Resource: "GET /", TraceID: 1, SpanID: 1}, 0, 500*time.Millisecond)
+		process(&pb.Span{Service: "web", Name: "request", Resource: "GET /", TraceID: 2, SpanID: 3}, 0, 10*time.Millisecond)
+		// trace 3 is kept by the head samplers, trace 4 is dropped by the user
+		process(&pb.Span{Service: "web", Name: "request", Resource: "GET /", TraceID: 3, SpanID: 4}, 1, 10*time.Millisecond)
+		process(&pb.Span{Service: "web", Name: "request", Resource: "GET /", TraceID: 4, SpanID: 5}, -1, 500*time.Millisecond)
+
+		mtw := agnt.TraceWriter.(*mockTraceWriter)
+		// the chunks kept by the head samplers are written right away
+		require.Len(t, mtw.payloads, 1)
+		assert.EqualValues(t, 3, mtw.payloads[0].TracerPayload.Chunks[0].Spans[0].TraceID)
+		// stats are computed on all the chunks as they arrive
+		assert.Len(t, agnt.Concentrator.(*mockConcentrator).stats, 5)
+		// the head samplers ran on all the chunks
+		priorities := agnt.Receiver.Stats.GetTagStats(info.Tags{}).TracesPerSamplingPriority.TagValues()
+		assert.EqualValues(t, 3, priorities["0"])
+		assert.EqualValues(t, 1, priorities["1"])
+		assert.EqualValues(t, 1, priorities["-1"])
+
+		agnt.TailSampler.Stop()
+		require.Len(t, mtw.payloads, 3)
+		for _, p := range mtw.payloads[1:] {
+			chunk := p.TracerPayload.Chunks[0]
+			assert.EqualValues(t, 1, chunk.Spans[0].TraceID)
+			assert.False(t, chunk.DroppedTrace)
+			assert.EqualValues(t, sampler.PriorityAutoKeep, chunk.Priority)
+		}
+	})
+
+	t.Run("v1", func(t *testing.T) {
+		agnt := newAgent(t)
+		process := func(id byte, duration time.Duration) {
+			strings := idx.NewStringTable()
+			span := idx.NewInternalSpan(strings, &idx.Span{
+				ServiceRef:  strings.Add("web"),
+				NameRef:     strings.Add("request"),
+				ResourceRef: strings.Add("GET /"),
+				SpanID:      uint64(id),
+				Start:       uint64(time.Now().Add(-time.Second).UnixNano()),
+				Duration:    uint64(duration.Nanoseconds()),
+			})
+			chunk := testutil.TraceChunkV1WithSpanAndPriority(span, 0)
+			chunk.TraceID = make([]byte, 16)
+			chunk.TraceID[15] = id
+			agnt.ProcessV1(&api.PayloadV1{
+				TracerPayload: testutil.TracerPayloadV1WithChunk(chunk),
+				Source:        agnt.Receiver.Stats.GetTagStats(info.Tags{}),
+			})
+		}
+		process(1, 500*time.Millisecond)
+		process(2, 10*time.Millisecond)
+
+		mtw := agnt.TraceWriterV1.(*mockTraceWriter)
+		assert.Empty(t, mtw.payloadsV1)
+		// stats are computed on the chunks before they are handed to the tail sampler
+		assert.Len(t, agnt.Concentrator.(*mockConcentrator).statsV1, 2)
+		agnt.TailSampler.Stop()
+		require.Len(t, mtw.payloadsV1, 1)
+		chunk := mtw.payloadsV1[0].TracerPayload.Chunks[0]
+		assert.EqualValues(t, 1, chunk.LegacyTraceID())
+		assert.False(t, chunk.DroppedTrace)
+		policy, _ := chunk.Spans[0].GetAttributeAsString("_dd.tail_sampling.policy")
+		assert.Equal(t, "slow", policy)
+	})
+}
+
 func TestSampling(t *testing.T) {
 	// agentConfig allows the test to customize how the agent is configured.
 	type agentConfig struct {
@@ -25,6 +25,9 @@ const (
 	tagSQLQuery         = transform.TagSQLQuery
 	tagHTTPURL          = transform.TagHTTPURL
 	tagDBMS             = transform.TagDBMS
+	tagDBStatement      = transform.TagDBStatement
+	tagDBQueryText      = transform.TagDBQueryText
+	tagGraphQLSource    = transform.TagGraphQLSource
 )
 
 const (
@@ -143,6 +146,34 @@ func obfuscateValkeySpan(o *obfuscate.Obfuscator, span obfuscateSpan, removeAllA
 	span.SetStringAttribute(tagValkeyRawCommand, o.ObfuscateRedisString(v))
 }
 
+func obfuscateCQLSpan(o *obfuscate.Obfuscator, span obfuscateSpan) {
+	if span.Resource() == "" {
+		return
+	}
+	query := o.ObfuscateCQLString(span.Resource())
+	span.SetResource(query)
+	obfuscateOTelDBAttributes(query, span)
+	span.SetStringAttribute(tagSQLQuery, query)
+}
+
+// obfuscateOTelDBAttributes replaces the OpenTelemetry statement attributes of span, if any,
+// with its obfuscated query.
+func obfuscateOTelDBAttributes(query string, span obfuscateSpan) {
+	for _, key := range []string{tagDBStatement, tagDBQueryText} {
+		if _, ok := span.GetAttributeAsString(key); ok {
+			span.SetStringAttribute(key, query)
+		}
+	}
+}
+
+func obfuscateDynamoDBSpan(o *obfuscate.Obfuscator, span obfuscateSpan) {
+	for _, key := range []string{tagDBStatement, tagDBQueryText} {
+		if v, ok := span.GetAttributeAsString(key); ok && v != "" {
+			span.SetStringAttribute(key, o.ObfuscateDynamoDBString(v))
+		}
+	}
+}
+
 func (a *Agent) obfuscateSpanInternal(span obfuscateSpan) {
 	o := a.lazyInitObfuscator()
 	if a.conf.Obfuscation != nil && a.conf.Obfuscation.CreditCards.Enabled {
@@ -156,8 +187,17 @@ func (a *Agent) obfuscateSpanInternal(span obfuscateSpan) {
 		})
 	}
 
+	// DynamoDB spans of the Datadog tracers have the http type, so they are obfuscated on top of their URL
+	if a.conf.Obfuscation != nil && a.conf.Obfuscation.DynamoDB.Enabled && transform.IsDynamoDBSpan(span.Type(), span.GetAttributeAsString) {
+		obfuscateDynamoDBSpan(o, span)
+	}
+
 	switch span.Type() {
 	case "sql", "cassandra":
+		if span.Type() == "cassandra" && a.conf.Obfuscation.CQL.Enabled {
+			obfuscateCQLSpan(o, span)
+			return
+		}
 		if span.Resource() == "" {
 			return
 		}
@@ -220,6 +260,15 @@ func (a *Agent) obfuscateSpanInternal(span obfuscateSpan) {
 			}
 			span.SetStringAttribute(tagOpenSearchBody, o.ObfuscateOpenSearchString(v))
 		}
+	case "graphql":
+		if !a.conf.Obfuscation.GraphQL.Enabled {
+			return
+		}
+		v, ok := span.GetAttributeAsString(tagGraphQLSource)
+		if !ok || v == "" {
+			return
+		}
+		span.SetStringAttribute(tagGraphQLSource, o.ObfuscateGraphQLString(v))
 	}
 }
 
@@ -282,6 +331,10 @@ func (a *Agent) obfuscateStatsGroup(b *pb.ClientGroupedStats) {
 
 	switch b.Type {
 	case "sql", "cassandra":
+		if b.Type == "cassandra" && a.conf.Obfuscation.CQL.Enabled {
+			b.Resource = o.ObfuscateCQLString(b.Resource)
+			return
+		}
 		oq, err := o.ObfuscateSQLStringForDBMS(b.Resource, b.DBType)
 		if err != nil {
 			log.Errorf("Error obfuscating stats group resource %q: %v", b.Resource, err)
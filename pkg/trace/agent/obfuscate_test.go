@@ -100,6 +100,54 @@ func TestObfuscateDefaults(t *testing.T) {
 	})
 }
 
+func TestObfuscateCassandra(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		cql     bool
+		in, out string
+	}{
+		{"sql", false, "SELECT * FROM users WHERE id = 42", "SELECT * FROM users WHERE id = ?"},
+		{"cql", true, "SELECT * FROM users WHERE id = 42", "SELECT * FROM users WHERE id = ?"},
+		{"cql-collection", true, "UPDATE users SET tags = tags + {'a', 'b'} WHERE id = 42", "UPDATE users SET tags = tags + ? WHERE id = ?"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancelFunc := context.WithCancel(context.Background())
+			defer cancelFunc()
+			cfg := config.New()
+			cfg.Endpoints[0].APIKey = "test"
+			cfg.Obfuscation = &config.ObfuscationConfig{CQL: obfuscate.CQLConfig{Enabled: tt.cql}}
+			agnt := NewAgent(ctx, cfg, telemetry.NewNoopCollector(), &statsd.NoOpClient{}, gzip.NewComponent())
+			span := &pb.Span{Type: "cassandra", Resource: tt.in}
+			agnt.obfuscateSpan(span)
+			assert.Equal(t, tt.out, span.Resource)
+			assert.Equal(t, tt.out, span.Meta["sql.query"])
+
+			stats := &pb.ClientGroupedStats{Type: "cassandra", Resource: tt.in}
+			agnt.obfuscateStatsGroup(stats)
+			assert.Equal(t, tt.out, stats.Resource)
+		})
+	}
+
+	t.Run("otel", func(t *testing.T) {
+		ctx, cancelFunc := context.WithCancel(context.Background())
+		defer cancelFunc()
+		cfg := config.New()
+		cfg.Endpoints[0].APIKey = "test"
+		cfg.Obfuscation = &config.ObfuscationConfig{CQL: obfuscate.CQLConfig{Enabled: true}}
+		agnt := NewAgent(ctx, cfg, telemetry.NewNoopCollector(), &statsd.NoOpClient{}, gzip.NewComponent())
+		query := "SELECT * FROM users WHERE id = 42"
+		span := &pb.Span{Type: "cassandra", Resource: query, Meta: map[string]string{
+			"db.system":     "cassandra",
+			"db.statement":  query,
+			"db.query.text": query,
+		}}
+		agnt.obfuscateSpan(span)
+		assert.Equal(t, "SELECT * FROM users WHERE id = ?", span.Resource)
+		assert.Equal(t, "SELECT * FROM users WHERE id = ?", span.Meta["db.statement"])
+		assert.Equal(t, "SELECT * FROM users WHERE id = ?", span.Meta["db.query.text"])
+	})
+}
+
 func agentWithDefaults(features ...string) (agnt *Agent, stop func()) {
 	ctx, cancelFunc := context.WithCancel(context.Background())
 	cfg := config.New()
@@ -296,6 +344,103 @@ func TestObfuscateConfig(t *testing.T) {
 		&config.ObfuscationConfig{},
 	))
 
+	t.Run("graphql/enabled", testConfig(
+		"graphql",
+		"graphql.source",
+		`query { user(id: 42, name: "Jane") { id } }`,
+		`query { user(id: ?, name: ?) { id } }`,
+		&config.ObfuscationConfig{GraphQL: obfuscate.GraphQLConfig{Enabled: true}},
+	))
+
+	t.Run("graphql/keep_values", testConfig(
+		"graphql",
+		"graphql.source",
+		`query { user(id: 42, name: "Jane") { id } }`,
+		`query { user(id: 42, name: ?) { id } }`,
+		&config.ObfuscationConfig{GraphQL: obfuscate.GraphQLConfig{
+			Enabled:    true,
+			KeepValues: []string{"id"},
+		}},
+	))
+
+	t.Run("graphql/disabled", testConfig(
+		"graphql",
+		"graphql.source",
+		`query { user(id: 42) { id } }`,
+		`query { user(id: 42) { id } }`,
+		&config.ObfuscationConfig{},
+	))
+
+	t.Run("dynamodb/enabled", testConfig(
+		"dynamodb",
+		"db.statement",
+		`SELECT * FROM "Music" WHERE Artist = 'Acme Band'`,
+		`SELECT * FROM "Music" WHERE Artist = ?`,
+		&config.ObfuscationConfig{DynamoDB: obfuscate.DynamoDBConfig{Enabled: true}},
+	))
+
+	t.Run("dynamodb/other-db", testConfig(
+		"db",
+		"db.statement",
+		`SELECT * FROM "Music" WHERE Artist = 'Acme Band'`,
+		`SELECT * FROM "Music" WHERE Artist = 'Acme Band'`,
+		&config.ObfuscationConfig{DynamoDB: obfuscate.DynamoDBConfig{Enabled: true}},
+	))
+
+	t.Run("dynamodb/query-text", testConfig(
+		"dynamodb",
+		"db.query.text",
+		`SELECT * FROM "Music" WHERE Artist = 'Acme Band'`,
+		`SELECT * FROM "Music" WHERE Artist = ?`,
+		&config.ObfuscationConfig{DynamoDB: obfuscate.DynamoDBConfig{Enabled: true}},
+	))
+
+	t.Run("dynamodb/tracer", func(t *testing.T) {
+		ctx, cancelFunc := context.WithCancel(context.Background())
+		defer cancelFunc()
+		cfg := config.New()
+		cfg.Endpoints[0].APIKey = "test"
+		cfg.Obfuscation = &config.ObfuscationConfig{DynamoDB: obfuscate.DynamoDBConfig{Enabled: true}}
+		agnt := NewAgent(ctx, cfg, telemetry.NewNoopCollector(), &statsd.NoOpClient{}, gzip.NewComponent())
+		for _, tt := range []struct {
+			typ, awsService, exp string
+		}{
+			{"http", "DynamoDB", `SELECT * FROM "Music" WHERE Artist = ?`},
+			{"aws", "AmazonDynamoDBv2", `SELECT * FROM "Music" WHERE Artist = ?`},
+			{"http", "S3", `SELECT * FROM "Music" WHERE Artist = 'Acme Band'`},
+		} {
+			span := &pb.Span{Type: tt.typ, Meta: map[string]string{
+				"aws.service":  tt.awsService,
+				"db.statement": `SELECT * FROM "Music" WHERE Artist = 'Acme Band'`,
+			}}
+			agnt.obfuscateSpan(span)
+			assert.Equal(t, tt.exp, span.Meta["db.statement"], tt.awsService)
+		}
+	})
+
+	t.Run("dynamodb/otel", func(t *testing.T) {
+		ctx, cancelFunc := context.WithCancel(context.Background())
+		defer cancelFunc()
+		cfg := config.New()
+		cfg.Endpoints[0].APIKey = "test"
+		cfg.Obfuscation = &config.ObfuscationConfig{DynamoDB: obfuscate.DynamoDBConfig{Enabled: true}}
+		agnt := NewAgent(ctx, cfg, telemetry.NewNoopCollector(), &statsd.NoOpClient{}, gzip.NewComponent())
+		span := &pb.Span{Type: "db", Meta: map[string]string{
+			"db.system":    "dynamodb",
+			"db.statement": `SELECT * FROM "Music" WHERE Artist = 'Acme Band'`,
+		}}
+		agnt.obfuscateSpan(span)
+		assert.Equal(t, `SELECT * FROM "Music" WHERE Artist = ?`, span.Meta["db.statement"])
+	})
+
+	t.Run("dynamodb/disabled", testConfig(
+		"dynamodb",
+		"db.statement",
+		`SELECT * FROM "Music" WHERE Artist = 'Acme Band'`,
+		`SELECT * FROM "Music" WHERE Artist = 'Acme Band'`,
+		&config.ObfuscationConfig{},
+	))
+
 	t.Run("creditcard", func(t *testing.T) {
 		for _, tt := range []struct {
 			k, v string
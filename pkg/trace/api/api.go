@@ -101,6 +101,11 @@ type HTTPReceiver struct {
 	statsProcessor      StatsProcessor
 	containerIDProvider IDProvider
 
+	// zipkinConverter and jaegerConverter convert the spans received in Zipkin and Jaeger
+	// formats the same way OpenTelemetry spans received over OTLP are converted.
+	zipkinConverter *OTLPReceiver
+	jaegerConverter *OTLPReceiver
+
 	telemetryCollector telemetry.TelemetryCollector
 	telemetryForwarder *TelemetryForwarder
 
@@ -158,6 +163,8 @@ func NewHTTPReceiver(
 		conf:                conf,
 		dynConf:             dynConf,
 		containerIDProvider: containerIDProvider,
+		zipkinConverter:     newSpanConverter(out, conf, containerIDProvider, statsd, timing, zipkinEndpointVersion),
+		jaegerConverter:     newSpanConverter(out, conf, containerIDProvider, statsd, timing, jaegerEndpointVersion),
 
 		telemetryCollector: telemetryCollector,
 		telemetryForwarder: telemetryForwarder,
@@ -99,6 +99,16 @@ var endpoints = []Endpoint{
 		Handler:   func(r *HTTPReceiver) http.Handler { return r.handleWithVersion(V10, r.handleTraces) },
 		IsEnabled: func(cfg *config.AgentConfig) bool { return cfg.EnableV1TraceEndpoint },
 	},
+	{
+		Pattern:   "/api/v2/spans",
+		Handler:   func(r *HTTPReceiver) http.Handler { return r.handleZipkinSpans() },
+		IsEnabled: func(cfg *config.AgentConfig) bool { return cfg.EnableZipkinEndpoint },
+	},
+	{
+		Pattern:   "/api/traces",
+		Handler:   func(r *HTTPReceiver) http.Handler { return r.handleJaegerSpans() },
+		IsEnabled: func(cfg *config.AgentConfig) bool { return cfg.EnableJaegerEndpoint },
+	},
 	{
 		Pattern:         "/profiling/v1/input",
 		Handler:         func(r *HTTPReceiver) http.Handler { return r.profileProxyHandler() },
@@ -0,0 +1,247 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package jaeger decodes Jaeger span batches (Thrift and protobuf) into OpenTelemetry traces,
+// so that they can go through the same conversion as spans received over OTLP.
+package jaeger
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// valueType is the type of a Jaeger tag value.
+type valueType int
+
+const (
+	valueString valueType = iota
+	valueBool
+	valueInt64
+	valueFloat64
+	valueBinary
+)
+
+// keyValue is a Jaeger tag or log field.
+type keyValue struct {
+	key     string
+	vType   valueType
+	vStr    string
+	vBool   bool
+	vInt64  int64
+	vDouble float64
+	vBinary []byte
+}
+
+// process describes the traced process emitting a batch of spans.
+type process struct {
+	serviceName string
+	tags        []keyValue
+}
+
+// spanRef references another span. refType 0 is CHILD_OF, 1 is FOLLOWS_FROM.
+type spanRef struct {
+	refType     int32
+	traceIDHigh uint64
+	traceIDLow  uint64
+	spanID      uint64
+}
+
+// spanLog is a timestamped set of fields attached to a span.
+type spanLog struct {
+	timestamp int64 // nanoseconds since epoch
+	fields    []keyValue
+}
+
+// span is a Jaeger span, common to the Thrift and protobuf models.
+type span struct {
+	traceIDHigh   uint64
+	traceIDLow    uint64
+	spanID        uint64
+	parentSpanID  uint64
+	operationName string
+	references    []spanRef
+	start         int64 // nanoseconds since epoch
+	duration      int64 // nanoseconds
+	tags          []keyValue
+	logs          []spanLog
+	process       *process // protobuf spans may override the batch process
+}
+
+// batch is a set of spans emitted by the same process.
+type batch struct {
+	process *process
+	spans   []*span
+}
+
+const (
+	// serviceNameKey is the OpenTelemetry resource attribute holding the service name.
+	serviceNameKey = "service.name"
+	// unknownService is the service used for spans without a process service name.
+	unknownService = "unknown_service"
+)
+
+// Decode decodes a Jaeger batch of the given media type into OpenTelemetry traces.
+// Thrift payloads are those sent to the Jaeger collector /api/traces endpoint, protobuf
+// payloads are api_v2.PostSpansRequest messages.
+func Decode(mediaType string, data []byte) (ptrace.Traces, error) {
+	var (
+		b   *batch
+		err error
+	)
+	switch mediaType {
+	case "application/x-thrift", "application/vnd.apache.thrift.binary":
+		b, err = unmarshalThriftBatch(data)
+	case "application/x-protobuf", "application/protobuf":
+		b, err = unmarshalProtoRequest(data)
+	default:
+		return ptrace.Traces{}, fmt.Errorf("unsupported media type: %q", mediaType)
+	}
+	if err != nil {
+		return ptrace.Traces{}, err
+	}
+	return toTraces(b), nil
+}
+
+// toTraces converts b into OpenTelemetry traces with one resource per process.
+func toTraces(b *batch) ptrace.Traces {
+	traces := ptrace.NewTraces()
+	byProcess := make(map[*process]ptrace.SpanSlice)
+	for _, s := range b.spans {
+		p := s.process
+		if p == nil {
+			p = b.process
+		}
+		slice, ok := byProcess[p]
+		if !ok {
+			rs := traces.ResourceSpans().AppendEmpty()
+			attrs := rs.Resource().Attributes()
+			service := unknownService
+			if p != nil {
+				for _, kv := range p.tags {
+					putAttribute(attrs, kv)
+				}
+				if p.serviceName != "" {
+					service = p.serviceName
+				}
+			}
+			attrs.PutStr(serviceNameKey, service)
+			slice = rs.ScopeSpans().AppendEmpty().Spans()
+			byProcess[p] = slice
+		}
+		convertSpan(s, slice.AppendEmpty())
+	}
+	return traces
+}
+
+func convertSpan(s *span, out ptrace.Span) {
+	out.SetTraceID(traceID(s.traceIDHigh, s.traceIDLow))
+	out.SetSpanID(spanID(s.spanID))
+	parent := s.parentSpanID
+	if parent == 0 {
+		for _, ref := range s.references {
+			if ref.refType == 0 && ref.traceIDHigh == s.traceIDHigh && ref.traceIDLow == s.traceIDLow {
+				parent = ref.spanID
+				break
+			}
+		}
+	}
+	if parent != 0 {
+		out.SetParentSpanID(spanID(parent))
+	}
+	out.SetName(s.operationName)
+	out.SetStartTimestamp(pcommon.Timestamp(s.start))
+	out.SetEndTimestamp(pcommon.Timestamp(s.start + s.duration))
+	out.SetKind(ptrace.SpanKindInternal)
+
+	attrs := out.Attributes()
+	for _, kv := range s.tags {
+		switch kv.key {
+		case "span.kind":
+			out.SetKind(spanKind(kv.vStr))
+		case "error":
+			if (kv.vType == valueBool && kv.vBool) || (kv.vType == valueString && kv.vStr == "true") {
+				out.Status().SetCode(ptrace.StatusCodeError)
+			}
+		case "otel.status_code":
+			switch strings.ToUpper(kv.vStr) {
+			case "ERROR":
+				out.Status().SetCode(ptrace.StatusCodeError)
+			case "OK":
+				out.Status().SetCode(ptrace.StatusCodeOk)
+			}
+		case "otel.status_description":
+			out.Status().SetMessage(kv.vStr)
+		default:
+			putAttribute(attrs, kv)
+		}
+	}
+	for _, l := range s.logs {
+		ev := out.Events().AppendEmpty()
+		ev.SetTimestamp(pcommon.Timestamp(l.timestamp))
+		for _, kv := range l.fields {
+			if kv.key == "event" && kv.vType == valueString {
+				ev.SetName(kv.vStr)
+				continue
+			}
+			putAttribute(ev.Attributes(), kv)
+		}
+		if ev.Name() == "" {
+			if msg, ok := ev.Attributes().Get("message"); ok {
+				ev.SetName(msg.AsString())
+			}
+		}
+	}
+}
+
+func putAttribute(attrs pcommon.Map, kv keyValue) {
+	switch kv.vType {
+	case valueBool:
+		attrs.PutBool(kv.key, kv.vBool)
+	case valueInt64:
+		attrs.PutInt(kv.key, kv.vInt64)
+	case valueFloat64:
+		attrs.PutDouble(kv.key, kv.vDouble)
+	case valueBinary:
+		attrs.PutStr(kv.key, base64.StdEncoding.EncodeToString(kv.vBinary))
+	default:
+		attrs.PutStr(kv.key, kv.vStr)
+	}
+}
+
+func spanKind(kind string) ptrace.SpanKind {
+	switch strings.ToLower(kind) {
+	case "client":
+		return ptrace.SpanKindClient
+	case "server":
+		return ptrace.SpanKindServer
+	case "producer":
+		return ptrace.SpanKindProducer
+	case "consumer":
+		return ptrace.SpanKindConsumer
+	default:
+		return ptrace.SpanKindInternal
+	}
+}
+
+func traceID(high, low uint64) pcommon.TraceID {
+	var id pcommon.TraceID
+	for i := 0; i < 8; i++ {
+		id[7-i] = byte(high >> (8 * i))
+		id[15-i] = byte(low >> (8 * i))
+	}
+	return id
+}
+
+func spanID(v uint64) pcommon.SpanID {
+	var id pcommon.SpanID
+	for i := 0; i < 8; i++ {
+		id[7-i] = byte(v >> (8 * i))
+	}
+	return id
+}
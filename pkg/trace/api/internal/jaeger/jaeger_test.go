@@ -0,0 +1,259 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package jaeger
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// thriftWriter writes values with the Thrift binary protocol.
+type thriftWriter struct {
+	b []byte
+}
+
+func (w *thriftWriter) field(typ byte, id int16) {
+	w.b = append(w.b, typ)
+	w.b = binary.BigEndian.AppendUint16(w.b, uint16(id))
+}
+
+func (w *thriftWriter) stop() { w.b = append(w.b, thriftStop) }
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.field(thriftI32, id)
+	w.b = binary.BigEndian.AppendUint32(w.b, uint32(v))
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.field(thriftI64, id)
+	w.b = binary.BigEndian.AppendUint64(w.b, uint64(v))
+}
+
+func (w *thriftWriter) double(id int16, v float64) {
+	w.field(thriftDouble, id)
+	w.b = binary.BigEndian.AppendUint64(w.b, math.Float64bits(v))
+}
+
+func (w *thriftWriter) boolean(id int16, v bool) {
+	w.field(thriftBool, id)
+	if v {
+		w.b = append(w.b, 1)
+	} else {
+		w.b = append(w.b, 0)
+	}
+}
+
+func (w *thriftWriter) str(id int16, v string) {
+	w.field(thriftString, id)
+	w.b = binary.BigEndian.AppendUint32(w.b, uint32(len(v)))
+	w.b = append(w.b, v...)
+}
+
+func (w *thriftWriter) list(id int16, n int) {
+	w.field(thriftList, id)
+	w.b = append(w.b, thriftStruct)
+	w.b = binary.BigEndian.AppendUint32(w.b, uint32(n))
+}
+
+// tag writes a jaeger.thrift Tag struct holding v.
+func (w *thriftWriter) tag(key string, v any) {
+	w.str(1, key)
+	switch v := v.(type) {
+	case string:
+		w.i32(2, 0)
+		w.str(3, v)
+	case float64:
+		w.i32(2, 1)
+		w.double(4, v)
+	case bool:
+		w.i32(2, 2)
+		w.boolean(5, v)
+	case int64:
+		w.i32(2, 3)
+		w.i64(6, v)
+	}
+	w.stop()
+}
+
+func TestDecodeThrift(t *testing.T) {
+	w := &thriftWriter{}
+	// Batch.process
+	w.field(thriftStruct, 1)
+	w.str(1, "checkout")
+	w.list(2, 1)
+	w.tag("hostname", "web-1")
+	w.stop()
+	// Batch.spans
+	w.list(2, 2)
+	{
+		// root span
+		w.i64(1, 0x10) // traceIdLow
+		w.i64(2, 0x20) // traceIdHigh
+		w.i64(3, 0x1)  // spanId
+		w.i64(4, 0)    // parentSpanId
+		w.str(5, "HTTP POST")
+		w.i32(7, 1) // flags, skipped
+		w.i64(8, 1000)
+		w.i64(9, 500)
+		w.list(10, 4)
+		w.tag("span.kind", "server")
+		w.tag("error", true)
+		w.tag("http.status_code", int64(500))
+		w.tag("sample.rate", 0.5)
+		w.list(11, 1)
+		w.i64(1, 1200)
+		w.list(2, 2)
+		w.tag("event", "retry")
+		w.tag("attempt", int64(2))
+		w.stop()
+		w.stop()
+	}
+	{
+		// child span referencing its parent
+		w.i64(1, 0x10)
+		w.i64(2, 0x20)
+		w.i64(3, 0x2)
+		w.str(5, "db")
+		w.list(6, 1)
+		w.i32(1, 0) // CHILD_OF
+		w.i64(2, 0x10)
+		w.i64(3, 0x20)
+		w.i64(4, 0x1)
+		w.stop()
+		w.i64(8, 1100)
+		w.i64(9, 100)
+		w.stop()
+	}
+	w.stop()
+
+	traces, err := Decode("application/x-thrift", w.b)
+	require.NoError(t, err)
+	require.Equal(t, 1, traces.ResourceSpans().Len())
+	rs := traces.ResourceSpans().At(0)
+	assert.Equal(t, map[string]any{"service.name": "checkout", "hostname": "web-1"}, rs.Resource().Attributes().AsRaw())
+	spans := rs.ScopeSpans().At(0).Spans()
+	require.Equal(t, 2, spans.Len())
+
+	root := spans.At(0)
+	assert.Equal(t, pcommon.TraceID{7: 0x20, 15: 0x10}, root.TraceID())
+	assert.Equal(t, pcommon.SpanID{7: 1}, root.SpanID())
+	assert.True(t, root.ParentSpanID().IsEmpty())
+	assert.Equal(t, "HTTP POST", root.Name())
+	assert.Equal(t, ptrace.SpanKindServer, root.Kind())
+	assert.Equal(t, ptrace.StatusCodeError, root.Status().Code())
+	assert.EqualValues(t, 1000000, root.StartTimestamp())
+	assert.EqualValues(t, 1500000, root.EndTimestamp())
+	assert.Equal(t, map[string]any{"http.status_code": int64(500), "sample.rate": 0.5}, root.Attributes().AsRaw())
+	require.Equal(t, 1, root.Events().Len())
+	ev := root.Events().At(0)
+	assert.Equal(t, "retry", ev.Name())
+	assert.EqualValues(t, 1200000, ev.Timestamp())
+	assert.Equal(t, map[string]any{"attempt": int64(2)}, ev.Attributes().AsRaw())
+
+	child := spans.At(1)
+	assert.Equal(t, pcommon.SpanID{7: 2}, child.SpanID())
+	assert.Equal(t, pcommon.SpanID{7: 1}, child.ParentSpanID())
+	assert.Equal(t, ptrace.SpanKindInternal, child.Kind())
+	assert.Equal(t, ptrace.StatusCodeUnset, child.Status().Code())
+}
+
+func TestDecodeProto(t *testing.T) {
+	appendMsg := func(b []byte, num protowire.Number, msg []byte) []byte {
+		b = protowire.AppendTag(b, num, protowire.BytesType)
+		return protowire.AppendBytes(b, msg)
+	}
+	appendStr := func(b []byte, num protowire.Number, s string) []byte {
+		b = protowire.AppendTag(b, num, protowire.BytesType)
+		return protowire.AppendString(b, s)
+	}
+	appendVarint := func(b []byte, num protowire.Number, v uint64) []byte {
+		b = protowire.AppendTag(b, num, protowire.VarintType)
+		return protowire.AppendVarint(b, v)
+	}
+
+	var kind []byte
+	kind = appendStr(kind, 1, "span.kind")
+	kind = appendStr(kind, 3, "client")
+	var status []byte
+	status = appendStr(status, 1, "otel.status_code")
+	status = appendStr(status, 3, "ERROR")
+	var description []byte
+	description = appendStr(description, 1, "otel.status_description")
+	description = appendStr(description, 3, "timeout")
+	var retries []byte
+	retries = appendStr(retries, 1, "retries")
+	retries = appendVarint(retries, 2, 2) // INT64
+	retries = appendVarint(retries, 5, 3)
+
+	var start, duration []byte
+	start = appendVarint(start, 1, 2)
+	start = appendVarint(start, 2, 500)
+	duration = appendVarint(duration, 2, 1000)
+
+	var spanProcess []byte
+	spanProcess = appendStr(spanProcess, 1, "cart")
+
+	var span []byte
+	span = appendMsg(span, 1, []byte{15: 0x0f})
+	span = appendMsg(span, 2, []byte{7: 0x0a})
+	span = appendStr(span, 3, "GET")
+	span = appendMsg(span, 6, start)
+	span = appendMsg(span, 7, duration)
+	span = appendMsg(span, 8, kind)
+	span = appendMsg(span, 8, status)
+	span = appendMsg(span, 8, description)
+	span = appendMsg(span, 8, retries)
+	span = appendMsg(span, 10, spanProcess)
+
+	var batch []byte
+	batch = appendMsg(batch, 1, span)
+
+	var req []byte
+	req = appendMsg(req, 1, batch)
+
+	traces, err := Decode("application/x-protobuf", req)
+	require.NoError(t, err)
+	require.Equal(t, 1, traces.SpanCount())
+	rs := traces.ResourceSpans().At(0)
+	assert.Equal(t, map[string]any{"service.name": "cart"}, rs.Resource().Attributes().AsRaw())
+	out := rs.ScopeSpans().At(0).Spans().At(0)
+	assert.Equal(t, pcommon.TraceID{15: 0x0f}, out.TraceID())
+	assert.Equal(t, pcommon.SpanID{7: 0x0a}, out.SpanID())
+	assert.Equal(t, "GET", out.Name())
+	assert.Equal(t, ptrace.SpanKindClient, out.Kind())
+	assert.Equal(t, ptrace.StatusCodeError, out.Status().Code())
+	assert.Equal(t, "timeout", out.Status().Message())
+	assert.EqualValues(t, 2000000500, out.StartTimestamp())
+	assert.EqualValues(t, 2000001500, out.EndTimestamp())
+	assert.Equal(t, map[string]any{"retries": int64(3)}, out.Attributes().AsRaw())
+}
+
+func TestDecodeErrors(t *testing.T) {
+	for name, tt := range map[string]struct {
+		mediaType string
+		data      []byte
+	}{
+		"media-type":     {"application/json", []byte(`{}`)},
+		"thrift-eof":     {"application/x-thrift", []byte{thriftStruct, 0, 1, thriftString}},
+		"thrift-list":    {"application/x-thrift", []byte{thriftList, 0, 2, thriftI32, 0, 0, 0, 1, 0, 0, 0, 0, 0}},
+		"thrift-type":    {"application/x-thrift", []byte{99, 0, 3}},
+		"thrift-length":  {"application/vnd.apache.thrift.binary", []byte{thriftString, 0, 3, 0x7f, 0xff, 0xff, 0xff}},
+		"proto":          {"application/x-protobuf", []byte{0x0a, 0x05, 0x01}},
+		"proto-trace-id": {"application/protobuf", []byte{0x0a, 0x05, 0x0a, 0x03, 0x0a, 0x01, 0x01}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Decode(tt.mediaType, tt.data)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,232 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package jaeger
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+var errInvalidID = errors.New("invalid trace or span ID length")
+
+// unmarshalProtoRequest decodes an api_v2.PostSpansRequest message.
+// See https://github.com/jaegertracing/jaeger-idl/blob/main/proto/api_v2/model.proto
+func unmarshalProtoRequest(data []byte) (*batch, error) {
+	b := &batch{}
+	err := consumeFields(data, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if num != 1 || typ != protowire.BytesType {
+			return nil
+		}
+		return consumeFields(v, func(num protowire.Number, typ protowire.Type, v []byte) error {
+			if typ != protowire.BytesType {
+				return nil
+			}
+			switch num {
+			case 1:
+				s, err := unmarshalProtoSpan(v)
+				b.spans = append(b.spans, s)
+				return err
+			case 2:
+				p, err := unmarshalProtoProcess(v)
+				b.process = p
+				return err
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func unmarshalProtoSpan(data []byte) (*span, error) {
+	s := &span{}
+	err := consumeFields(data, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		var err error
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			s.traceIDHigh, s.traceIDLow, err = protoTraceID(v)
+		case num == 2 && typ == protowire.BytesType:
+			s.spanID, err = protoSpanID(v)
+		case num == 3 && typ == protowire.BytesType:
+			s.operationName = string(v)
+		case num == 4 && typ == protowire.BytesType:
+			var ref spanRef
+			ref, err = unmarshalProtoSpanRef(v)
+			s.references = append(s.references, ref)
+		case num == 6 && typ == protowire.BytesType:
+			s.start, err = protoNanos(v)
+		case num == 7 && typ == protowire.BytesType:
+			s.duration, err = protoNanos(v)
+		case num == 8 && typ == protowire.BytesType:
+			var kv keyValue
+			kv, err = unmarshalProtoKeyValue(v)
+			s.tags = append(s.tags, kv)
+		case num == 9 && typ == protowire.BytesType:
+			var l spanLog
+			l, err = unmarshalProtoLog(v)
+			s.logs = append(s.logs, l)
+		case num == 10 && typ == protowire.BytesType:
+			s.process, err = unmarshalProtoProcess(v)
+		}
+		return err
+	})
+	return s, err
+}
+
+func unmarshalProtoProcess(data []byte) (*process, error) {
+	p := &process{}
+	err := consumeFields(data, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1:
+			p.serviceName = string(v)
+		case 2:
+			kv, err := unmarshalProtoKeyValue(v)
+			p.tags = append(p.tags, kv)
+			return err
+		}
+		return nil
+	})
+	return p, err
+}
+
+func unmarshalProtoSpanRef(data []byte) (spanRef, error) {
+	var ref spanRef
+	err := consumeFields(data, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		var err error
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			ref.traceIDHigh, ref.traceIDLow, err = protoTraceID(v)
+		case num == 2 && typ == protowire.BytesType:
+			ref.spanID, err = protoSpanID(v)
+		case num == 3 && typ == protowire.VarintType:
+			n, _ := protowire.ConsumeVarint(v)
+			ref.refType = int32(n)
+		}
+		return err
+	})
+	return ref, err
+}
+
+func unmarshalProtoLog(data []byte) (spanLog, error) {
+	var l spanLog
+	err := consumeFields(data, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		var err error
+		switch num {
+		case 1:
+			l.timestamp, err = protoNanos(v)
+		case 2:
+			var kv keyValue
+			kv, err = unmarshalProtoKeyValue(v)
+			l.fields = append(l.fields, kv)
+		}
+		return err
+	})
+	return l, err
+}
+
+func unmarshalProtoKeyValue(data []byte) (keyValue, error) {
+	var kv keyValue
+	err := consumeFields(data, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			kv.key = string(v)
+		case num == 2 && typ == protowire.VarintType:
+			n, _ := protowire.ConsumeVarint(v)
+			kv.vType = valueType(n)
+		case num == 3 && typ == protowire.BytesType:
+			kv.vStr = string(v)
+		case num == 4 && typ == protowire.VarintType:
+			n, _ := protowire.ConsumeVarint(v)
+			kv.vBool = n != 0
+		case num == 5 && typ == protowire.VarintType:
+			n, _ := protowire.ConsumeVarint(v)
+			kv.vInt64 = int64(n)
+		case num == 6 && typ == protowire.Fixed64Type:
+			n, _ := protowire.ConsumeFixed64(v)
+			kv.vDouble = math.Float64frombits(n)
+		case num == 7 && typ == protowire.BytesType:
+			kv.vBinary = v
+		}
+		return nil
+	})
+	return kv, err
+}
+
+// protoNanos decodes a google.protobuf.Timestamp or google.protobuf.Duration into nanoseconds.
+func protoNanos(data []byte) (int64, error) {
+	var seconds, nanos int64
+	err := consumeFields(data, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if typ != protowire.VarintType {
+			return nil
+		}
+		n, _ := protowire.ConsumeVarint(v)
+		switch num {
+		case 1:
+			seconds = int64(n)
+		case 2:
+			nanos = int64(int32(n))
+		}
+		return nil
+	})
+	return seconds*1e9 + nanos, err
+}
+
+func protoTraceID(b []byte) (high, low uint64, err error) {
+	switch len(b) {
+	case 16:
+		return binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:]), nil
+	case 8:
+		return 0, binary.BigEndian.Uint64(b), nil
+	}
+	return 0, 0, errInvalidID
+}
+
+func protoSpanID(b []byte) (uint64, error) {
+	if len(b) != 8 {
+		return 0, errInvalidID
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
+
+// consumeFields calls fn with the raw value of each field of the protobuf message data:
+// the payload of length-delimited fields, or the encoded value of scalar fields.
+func consumeFields(data []byte, fn func(num protowire.Number, typ protowire.Type, v []byte) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		var v []byte
+		if typ == protowire.BytesType {
+			v, n = protowire.ConsumeBytes(data)
+		} else {
+			n = protowire.ConsumeFieldValue(num, typ, data)
+			if n >= 0 {
+				v = data[:n]
+			}
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		if err := fn(num, typ, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
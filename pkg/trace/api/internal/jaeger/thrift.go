@@ -0,0 +1,386 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package jaeger
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Thrift binary protocol field types.
+const (
+	thriftStop   byte = 0
+	thriftBool   byte = 2
+	thriftByte   byte = 3
+	thriftDouble byte = 4
+	thriftI16    byte = 6
+	thriftI32    byte = 8
+	thriftI64    byte = 10
+	thriftString byte = 11
+	thriftStruct byte = 12
+	thriftMap    byte = 13
+	thriftSet    byte = 14
+	thriftList   byte = 15
+)
+
+// thriftMaxDepth bounds the nesting of skipped structures.
+const thriftMaxDepth = 64
+
+// thriftTagTypes maps the jaeger.thrift TagType enum to valueType.
+var thriftTagTypes = [...]valueType{valueString, valueFloat64, valueBool, valueInt64, valueBinary}
+
+var errThriftTruncated = errors.New("thrift: unexpected end of payload")
+
+// thriftReader reads values encoded with the Thrift binary protocol.
+type thriftReader struct {
+	b []byte
+}
+
+func (r *thriftReader) next(n int) ([]byte, error) {
+	if n < 0 || len(r.b) < n {
+		return nil, errThriftTruncated
+	}
+	v := r.b[:n]
+	r.b = r.b[n:]
+	return v, nil
+}
+
+func (r *thriftReader) readByte() (byte, error) {
+	b, err := r.next(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (r *thriftReader) readI16() (int16, error) {
+	b, err := r.next(2)
+	if err != nil {
+		return 0, err
+	}
+	return int16(binary.BigEndian.Uint16(b)), nil
+}
+
+func (r *thriftReader) readI32() (int32, error) {
+	b, err := r.next(4)
+	if err != nil {
+		return 0, err
+	}
+	return int32(binary.BigEndian.Uint32(b)), nil
+}
+
+func (r *thriftReader) readI64() (int64, error) {
+	b, err := r.next(8)
+	if err != nil {
+		return 0, err
+	}
+	return int64(binary.BigEndian.Uint64(b)), nil
+}
+
+func (r *thriftReader) readDouble() (float64, error) {
+	v, err := r.readI64()
+	return math.Float64frombits(uint64(v)), err
+}
+
+func (r *thriftReader) readBinary() ([]byte, error) {
+	n, err := r.readI32()
+	if err != nil {
+		return nil, err
+	}
+	return r.next(int(n))
+}
+
+func (r *thriftReader) readString() (string, error) {
+	b, err := r.readBinary()
+	return string(b), err
+}
+
+func (r *thriftReader) readFieldHeader() (typ byte, id int16, err error) {
+	if typ, err = r.readByte(); err != nil || typ == thriftStop {
+		return typ, 0, err
+	}
+	id, err = r.readI16()
+	return typ, id, err
+}
+
+func (r *thriftReader) readListHeader() (elemType byte, size int, err error) {
+	if elemType, err = r.readByte(); err != nil {
+		return 0, 0, err
+	}
+	n, err := r.readI32()
+	if err != nil {
+		return 0, 0, err
+	}
+	if n < 0 || int(n) > len(r.b) {
+		// every element takes at least one byte
+		return 0, 0, errThriftTruncated
+	}
+	return elemType, int(n), nil
+}
+
+// readStruct calls fn for each field of a struct until the stop field. Fields not consumed
+// by fn must be skipped by it.
+func (r *thriftReader) readStruct(fn func(typ byte, id int16) error) error {
+	for {
+		typ, id, err := r.readFieldHeader()
+		if err != nil {
+			return err
+		}
+		if typ == thriftStop {
+			return nil
+		}
+		if err := fn(typ, id); err != nil {
+			return err
+		}
+	}
+}
+
+// readList calls fn for each element of a list of structs.
+func (r *thriftReader) readList(fn func() error) error {
+	elemType, n, err := r.readListHeader()
+	if err != nil {
+		return err
+	}
+	if elemType != thriftStruct {
+		return fmt.Errorf("thrift: expected a list of structs, got element type %d", elemType)
+	}
+	for i := 0; i < n; i++ {
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *thriftReader) skip(typ byte) error {
+	return r.skipDepth(typ, 0)
+}
+
+func (r *thriftReader) skipDepth(typ byte, depth int) error {
+	if depth > thriftMaxDepth {
+		return errors.New("thrift: maximum nesting depth reached")
+	}
+	var err error
+	switch typ {
+	case thriftBool, thriftByte:
+		_, err = r.next(1)
+	case thriftI16:
+		_, err = r.next(2)
+	case thriftI32:
+		_, err = r.next(4)
+	case thriftI64, thriftDouble:
+		_, err = r.next(8)
+	case thriftString:
+		_, err = r.readBinary()
+	case thriftStruct:
+		err = r.readStruct(func(typ byte, _ int16) error { return r.skipDepth(typ, depth+1) })
+	case thriftMap:
+		var kt, vt byte
+		var n int32
+		if kt, err = r.readByte(); err != nil {
+			return err
+		}
+		if vt, err = r.readByte(); err != nil {
+			return err
+		}
+		if n, err = r.readI32(); err != nil {
+			return err
+		}
+		for i := int32(0); i < n && err == nil; i++ {
+			if err = r.skipDepth(kt, depth+1); err == nil {
+				err = r.skipDepth(vt, depth+1)
+			}
+		}
+	case thriftSet, thriftList:
+		var et byte
+		var n int
+		if et, n, err = r.readListHeader(); err != nil {
+			return err
+		}
+		for i := 0; i < n && err == nil; i++ {
+			err = r.skipDepth(et, depth+1)
+		}
+	default:
+		err = fmt.Errorf("thrift: unknown field type %d", typ)
+	}
+	return err
+}
+
+// unmarshalThriftBatch decodes a jaeger.thrift Batch encoded with the binary protocol.
+// See https://github.com/jaegertracing/jaeger-idl/blob/main/thrift/jaeger.thrift
+func unmarshalThriftBatch(data []byte) (*batch, error) {
+	r := &thriftReader{b: data}
+	b := &batch{}
+	err := r.readStruct(func(typ byte, id int16) error {
+		switch {
+		case id == 1 && typ == thriftStruct:
+			p, err := r.readProcess()
+			b.process = p
+			return err
+		case id == 2 && typ == thriftList:
+			return r.readList(func() error {
+				s, err := r.readSpan()
+				b.spans = append(b.spans, s)
+				return err
+			})
+		}
+		return r.skip(typ)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (r *thriftReader) readProcess() (*process, error) {
+	p := &process{}
+	err := r.readStruct(func(typ byte, id int16) error {
+		var err error
+		switch {
+		case id == 1 && typ == thriftString:
+			p.serviceName, err = r.readString()
+		case id == 2 && typ == thriftList:
+			p.tags, err = r.readTags()
+		default:
+			err = r.skip(typ)
+		}
+		return err
+	})
+	return p, err
+}
+
+func (r *thriftReader) readSpan() (*span, error) {
+	s := &span{}
+	err := r.readStruct(func(typ byte, id int16) error {
+		var (
+			v   int64
+			err error
+		)
+		switch {
+		case id <= 4 && typ == thriftI64:
+			v, err = r.readI64()
+			switch id {
+			case 1:
+				s.traceIDLow = uint64(v)
+			case 2:
+				s.traceIDHigh = uint64(v)
+			case 3:
+				s.spanID = uint64(v)
+			case 4:
+				s.parentSpanID = uint64(v)
+			}
+		case id == 5 && typ == thriftString:
+			s.operationName, err = r.readString()
+		case id == 6 && typ == thriftList:
+			err = r.readList(func() error {
+				ref, err := r.readSpanRef()
+				s.references = append(s.references, ref)
+				return err
+			})
+		case id == 8 && typ == thriftI64:
+			v, err = r.readI64()
+			s.start = v * 1000
+		case id == 9 && typ == thriftI64:
+			v, err = r.readI64()
+			s.duration = v * 1000
+		case id == 10 && typ == thriftList:
+			s.tags, err = r.readTags()
+		case id == 11 && typ == thriftList:
+			err = r.readList(func() error {
+				l, err := r.readLog()
+				s.logs = append(s.logs, l)
+				return err
+			})
+		default:
+			err = r.skip(typ)
+		}
+		return err
+	})
+	return s, err
+}
+
+func (r *thriftReader) readSpanRef() (spanRef, error) {
+	var ref spanRef
+	err := r.readStruct(func(typ byte, id int16) error {
+		switch {
+		case id == 1 && typ == thriftI32:
+			v, err := r.readI32()
+			ref.refType = v
+			return err
+		case id >= 2 && id <= 4 && typ == thriftI64:
+			v, err := r.readI64()
+			switch id {
+			case 2:
+				ref.traceIDLow = uint64(v)
+			case 3:
+				ref.traceIDHigh = uint64(v)
+			case 4:
+				ref.spanID = uint64(v)
+			}
+			return err
+		}
+		return r.skip(typ)
+	})
+	return ref, err
+}
+
+func (r *thriftReader) readLog() (spanLog, error) {
+	var l spanLog
+	err := r.readStruct(func(typ byte, id int16) error {
+		switch {
+		case id == 1 && typ == thriftI64:
+			v, err := r.readI64()
+			l.timestamp = v * 1000
+			return err
+		case id == 2 && typ == thriftList:
+			fields, err := r.readTags()
+			l.fields = fields
+			return err
+		}
+		return r.skip(typ)
+	})
+	return l, err
+}
+
+func (r *thriftReader) readTags() ([]keyValue, error) {
+	var tags []keyValue
+	err := r.readList(func() error {
+		var kv keyValue
+		err := r.readStruct(func(typ byte, id int16) error {
+			var err error
+			switch {
+			case id == 1 && typ == thriftString:
+				kv.key, err = r.readString()
+			case id == 2 && typ == thriftI32:
+				var v int32
+				v, err = r.readI32()
+				if v >= 0 && int(v) < len(thriftTagTypes) {
+					kv.vType = thriftTagTypes[v]
+				}
+			case id == 3 && typ == thriftString:
+				kv.vStr, err = r.readString()
+			case id == 4 && typ == thriftDouble:
+				kv.vDouble, err = r.readDouble()
+			case id == 5 && typ == thriftBool:
+				var v byte
+				v, err = r.readByte()
+				kv.vBool = v != 0
+			case id == 6 && typ == thriftI64:
+				kv.vInt64, err = r.readI64()
+			case id == 7 && typ == thriftString:
+				kv.vBinary, err = r.readBinary()
+			default:
+				err = r.skip(typ)
+			}
+			return err
+		})
+		tags = append(tags, kv)
+		return err
+	})
+	return tags, err
+}
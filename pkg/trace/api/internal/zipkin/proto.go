@@ -0,0 +1,155 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package zipkin
+
+import (
+	"encoding/hex"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// protoKinds maps the zipkin.proto3 Span.Kind enum to its JSON name.
+var protoKinds = [...]string{"", "CLIENT", "SERVER", "PRODUCER", "CONSUMER"}
+
+// unmarshalProto decodes a zipkin.proto3 ListOfSpans message.
+// See https://github.com/openzipkin/zipkin-api/blob/master/zipkin.proto
+func unmarshalProto(data []byte) ([]*Span, error) {
+	var spans []*Span
+	err := consumeFields(data, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if num != 1 || typ != protowire.BytesType {
+			return nil
+		}
+		s, err := unmarshalProtoSpan(v)
+		spans = append(spans, s)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return spans, nil
+}
+
+func unmarshalProtoSpan(data []byte) (*Span, error) {
+	s := &Span{}
+	err := consumeFields(data, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		var err error
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			s.TraceID = hex.EncodeToString(v)
+		case num == 2 && typ == protowire.BytesType:
+			s.ParentID = hex.EncodeToString(v)
+		case num == 3 && typ == protowire.BytesType:
+			s.ID = hex.EncodeToString(v)
+		case num == 4 && typ == protowire.VarintType:
+			if k, _ := protowire.ConsumeVarint(v); k < uint64(len(protoKinds)) {
+				s.Kind = protoKinds[k]
+			}
+		case num == 5 && typ == protowire.BytesType:
+			s.Name = string(v)
+		case num == 6 && typ == protowire.Fixed64Type:
+			s.Timestamp, _ = protowire.ConsumeFixed64(v)
+		case num == 7 && typ == protowire.VarintType:
+			s.Duration, _ = protowire.ConsumeVarint(v)
+		case num == 8 && typ == protowire.BytesType:
+			s.LocalEndpoint, err = unmarshalProtoEndpoint(v)
+		case num == 9 && typ == protowire.BytesType:
+			s.RemoteEndpoint, err = unmarshalProtoEndpoint(v)
+		case num == 10 && typ == protowire.BytesType:
+			var a Annotation
+			a, err = unmarshalProtoAnnotation(v)
+			s.Annotations = append(s.Annotations, a)
+		case num == 11 && typ == protowire.BytesType:
+			var k, val string
+			k, val, err = unmarshalProtoMapEntry(v)
+			if s.Tags == nil {
+				s.Tags = make(map[string]string)
+			}
+			s.Tags[k] = val
+		}
+		return err
+	})
+	return s, err
+}
+
+func unmarshalProtoEndpoint(data []byte) (*Endpoint, error) {
+	ep := &Endpoint{}
+	err := consumeFields(data, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		var err error
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			ep.ServiceName = string(v)
+		case num == 2 && typ == protowire.BytesType && len(v) > 0:
+			ep.IPv4, err = ipString(v)
+		case num == 3 && typ == protowire.BytesType && len(v) > 0:
+			ep.IPv6, err = ipString(v)
+		case num == 4 && typ == protowire.VarintType:
+			port, _ := protowire.ConsumeVarint(v)
+			ep.Port = int(int32(port))
+		}
+		return err
+	})
+	return ep, err
+}
+
+func unmarshalProtoAnnotation(data []byte) (Annotation, error) {
+	var a Annotation
+	err := consumeFields(data, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		switch {
+		case num == 1 && typ == protowire.Fixed64Type:
+			a.Timestamp, _ = protowire.ConsumeFixed64(v)
+		case num == 2 && typ == protowire.BytesType:
+			a.Value = string(v)
+		}
+		return nil
+	})
+	return a, err
+}
+
+// unmarshalProtoMapEntry decodes a map<string, string> entry.
+func unmarshalProtoMapEntry(data []byte) (key, value string, err error) {
+	err = consumeFields(data, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1:
+			key = string(v)
+		case 2:
+			value = string(v)
+		}
+		return nil
+	})
+	return key, value, err
+}
+
+// consumeFields calls fn with the raw value of each field of the protobuf message data:
+// the payload of length-delimited fields, or the encoded value of scalar fields.
+func consumeFields(data []byte, fn func(num protowire.Number, typ protowire.Type, v []byte) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		var v []byte
+		if typ == protowire.BytesType {
+			v, n = protowire.ConsumeBytes(data)
+		} else {
+			n = protowire.ConsumeFieldValue(num, typ, data)
+			if n >= 0 {
+				v = data[:n]
+			}
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		if err := fn(num, typ, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package zipkin decodes Zipkin v2 span payloads (JSON and protobuf) into OpenTelemetry traces,
+// so that they can go through the same conversion as spans received over OTLP.
+package zipkin
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// Span is a Zipkin v2 span, as defined in https://zipkin.io/zipkin-api/#/default/post_spans.
+type Span struct {
+	TraceID        string            `json:"traceId"`
+	ParentID       string            `json:"parentId,omitempty"`
+	ID             string            `json:"id"`
+	Kind           string            `json:"kind,omitempty"`
+	Name           string            `json:"name,omitempty"`
+	Timestamp      uint64            `json:"timestamp,omitempty"` // microseconds since epoch
+	Duration       uint64            `json:"duration,omitempty"`  // microseconds
+	LocalEndpoint  *Endpoint         `json:"localEndpoint,omitempty"`
+	RemoteEndpoint *Endpoint         `json:"remoteEndpoint,omitempty"`
+	Annotations    []Annotation      `json:"annotations,omitempty"`
+	Tags           map[string]string `json:"tags,omitempty"`
+}
+
+// Endpoint is the network context of a node in the service graph.
+type Endpoint struct {
+	ServiceName string `json:"serviceName,omitempty"`
+	IPv4        string `json:"ipv4,omitempty"`
+	IPv6        string `json:"ipv6,omitempty"`
+	Port        int    `json:"port,omitempty"`
+}
+
+// Annotation associates an event that explains latency with a timestamp.
+type Annotation struct {
+	Timestamp uint64 `json:"timestamp"` // microseconds since epoch
+	Value     string `json:"value"`
+}
+
+const (
+	// tagError is the Zipkin tag marking a span as errored. Its value is the error message, if any.
+	tagError = "error"
+	// serviceNameKey is the OpenTelemetry resource attribute holding the service name.
+	serviceNameKey = "service.name"
+	// unknownService is the service used for spans without a local endpoint service name.
+	unknownService = "unknown_service"
+)
+
+// Decode decodes a Zipkin v2 payload of the given media type into OpenTelemetry traces.
+func Decode(mediaType string, data []byte) (ptrace.Traces, error) {
+	var (
+		spans []*Span
+		err   error
+	)
+	switch mediaType {
+	case "application/x-protobuf", "application/protobuf":
+		spans, err = unmarshalProto(data)
+	case "", "application/json", "text/plain":
+		err = json.Unmarshal(data, &spans)
+	default:
+		return ptrace.Traces{}, fmt.Errorf("unsupported media type: %q", mediaType)
+	}
+	if err != nil {
+		return ptrace.Traces{}, err
+	}
+	return ToTraces(spans)
+}
+
+// ToTraces converts Zipkin spans into OpenTelemetry traces, grouping them by service name.
+func ToTraces(spans []*Span) (ptrace.Traces, error) {
+	traces := ptrace.NewTraces()
+	byService := make(map[string]ptrace.SpanSlice)
+	for _, zs := range spans {
+		if zs == nil {
+			continue
+		}
+		service := unknownService
+		if zs.LocalEndpoint != nil && zs.LocalEndpoint.ServiceName != "" {
+			service = zs.LocalEndpoint.ServiceName
+		}
+		slice, ok := byService[service]
+		if !ok {
+			rs := traces.ResourceSpans().AppendEmpty()
+			rs.Resource().Attributes().PutStr(serviceNameKey, service)
+			slice = rs.ScopeSpans().AppendEmpty().Spans()
+			byService[service] = slice
+		}
+		if err := convertSpan(zs, slice.AppendEmpty()); err != nil {
+			return ptrace.Traces{}, err
+		}
+	}
+	return traces, nil
+}
+
+func convertSpan(zs *Span, span ptrace.Span) error {
+	traceID, err := parseTraceID(zs.TraceID)
+	if err != nil {
+		return err
+	}
+	span.SetTraceID(traceID)
+	spanID, err := parseSpanID(zs.ID)
+	if err != nil {
+		return fmt.Errorf("invalid span ID: %w", err)
+	}
+	span.SetSpanID(spanID)
+	if zs.ParentID != "" {
+		parentID, err := parseSpanID(zs.ParentID)
+		if err != nil {
+			return fmt.Errorf("invalid parent ID: %w", err)
+		}
+		span.SetParentSpanID(parentID)
+	}
+	span.SetName(zs.Name)
+	span.SetKind(spanKind(zs.Kind))
+	start := zs.Timestamp * 1000
+	span.SetStartTimestamp(pcommon.Timestamp(start))
+	span.SetEndTimestamp(pcommon.Timestamp(start + zs.Duration*1000))
+
+	attrs := span.Attributes()
+	if ep := zs.RemoteEndpoint; ep != nil {
+		if ep.ServiceName != "" {
+			attrs.PutStr("peer.service", ep.ServiceName)
+		}
+		if ep.IPv4 != "" {
+			attrs.PutStr("net.peer.ip", ep.IPv4)
+		} else if ep.IPv6 != "" {
+			attrs.PutStr("net.peer.ip", ep.IPv6)
+		}
+		if ep.Port != 0 {
+			attrs.PutInt("net.peer.port", int64(ep.Port))
+		}
+	}
+	for k, v := range zs.Tags {
+		switch k {
+		case tagError:
+			span.Status().SetCode(ptrace.StatusCodeError)
+			if v != "" && v != "true" {
+				span.Status().SetMessage(v)
+			}
+		case "otel.status_code":
+			switch strings.ToUpper(v) {
+			case "ERROR":
+				span.Status().SetCode(ptrace.StatusCodeError)
+			case "OK":
+				span.Status().SetCode(ptrace.StatusCodeOk)
+			}
+		case "otel.status_description":
+			span.Status().SetMessage(v)
+		default:
+			attrs.PutStr(k, v)
+		}
+	}
+	for _, a := range zs.Annotations {
+		ev := span.Events().AppendEmpty()
+		ev.SetName(a.Value)
+		ev.SetTimestamp(pcommon.Timestamp(a.Timestamp * 1000))
+	}
+	return nil
+}
+
+func spanKind(kind string) ptrace.SpanKind {
+	switch strings.ToUpper(kind) {
+	case "CLIENT":
+		return ptrace.SpanKindClient
+	case "SERVER":
+		return ptrace.SpanKindServer
+	case "PRODUCER":
+		return ptrace.SpanKindProducer
+	case "CONSUMER":
+		return ptrace.SpanKindConsumer
+	default:
+		return ptrace.SpanKindInternal
+	}
+}
+
+// parseTraceID parses a 64 or 128-bit lower-hex trace ID.
+func parseTraceID(s string) (pcommon.TraceID, error) {
+	var id pcommon.TraceID
+	if s == "" || len(s) > 32 {
+		return id, fmt.Errorf("invalid trace ID %q", s)
+	}
+	b, err := hex.DecodeString(strings.Repeat("0", 32-len(s)) + s)
+	if err != nil {
+		return id, fmt.Errorf("invalid trace ID %q: %w", s, err)
+	}
+	copy(id[:], b)
+	return id, nil
+}
+
+// parseSpanID parses a 64-bit lower-hex span ID.
+func parseSpanID(s string) (pcommon.SpanID, error) {
+	var id pcommon.SpanID
+	if s == "" || len(s) > 16 {
+		return id, fmt.Errorf("invalid ID %q", s)
+	}
+	v, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return id, err
+	}
+	for i := 7; i >= 0; i-- {
+		id[i] = byte(v)
+		v >>= 8
+	}
+	return id, nil
+}
+
+var errInvalidIP = errors.New("invalid endpoint IP address")
+
+// ipString returns the textual representation of a binary IPv4 or IPv6 address.
+func ipString(b []byte) (string, error) {
+	if len(b) != net.IPv4len && len(b) != net.IPv6len {
+		return "", errInvalidIP
+	}
+	return net.IP(b).String(), nil
+}
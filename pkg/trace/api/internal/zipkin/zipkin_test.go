@@ -0,0 +1,193 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package zipkin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	payload := `[
+		{
+			"traceId": "463ac35c9f6413ad48485a3953bb6124",
+			"id": "a2fb4a1d1a96d312",
+			"kind": "CLIENT",
+			"name": "get /users",
+			"timestamp": 1000,
+			"duration": 250,
+			"localEndpoint": {"serviceName": "frontend"},
+			"remoteEndpoint": {"serviceName": "users", "ipv4": "10.0.0.2", "port": 8080},
+			"annotations": [{"timestamp": 1100, "value": "ws"}],
+			"tags": {"http.method": "GET", "error": "true"}
+		},
+		{
+			"traceId": "48485a3953bb6124",
+			"parentId": "a2fb4a1d1a96d312",
+			"id": "0000000000000002",
+			"name": "query",
+			"localEndpoint": {"serviceName": "users"}
+		},
+		{
+			"traceId": "48485a3953bb6124",
+			"id": "3",
+			"tags": {"otel.status_code": "OK"}
+		}
+	]`
+	traces, err := Decode("application/json", []byte(payload))
+	require.NoError(t, err)
+	require.Equal(t, 3, traces.ResourceSpans().Len())
+	require.Equal(t, 3, traces.SpanCount())
+
+	rs := traces.ResourceSpans().At(0)
+	service, _ := rs.Resource().Attributes().Get("service.name")
+	assert.Equal(t, "frontend", service.Str())
+	span := rs.ScopeSpans().At(0).Spans().At(0)
+	assert.Equal(t, pcommon.TraceID{0x46, 0x3a, 0xc3, 0x5c, 0x9f, 0x64, 0x13, 0xad, 0x48, 0x48, 0x5a, 0x39, 0x53, 0xbb, 0x61, 0x24}, span.TraceID())
+	assert.Equal(t, pcommon.SpanID{0xa2, 0xfb, 0x4a, 0x1d, 0x1a, 0x96, 0xd3, 0x12}, span.SpanID())
+	assert.True(t, span.ParentSpanID().IsEmpty())
+	assert.Equal(t, ptrace.SpanKindClient, span.Kind())
+	assert.Equal(t, "get /users", span.Name())
+	assert.EqualValues(t, 1000000, span.StartTimestamp())
+	assert.EqualValues(t, 1250000, span.EndTimestamp())
+	assert.Equal(t, ptrace.StatusCodeError, span.Status().Code())
+	assert.Empty(t, span.Status().Message())
+	assert.Equal(t, map[string]any{
+		"http.method":   "GET",
+		"peer.service":  "users",
+		"net.peer.ip":   "10.0.0.2",
+		"net.peer.port": int64(8080),
+	}, span.Attributes().AsRaw())
+	require.Equal(t, 1, span.Events().Len())
+	assert.Equal(t, "ws", span.Events().At(0).Name())
+	assert.EqualValues(t, 1100000, span.Events().At(0).Timestamp())
+
+	rs = traces.ResourceSpans().At(1)
+	service, _ = rs.Resource().Attributes().Get("service.name")
+	assert.Equal(t, "users", service.Str())
+	span = rs.ScopeSpans().At(0).Spans().At(0)
+	assert.Equal(t, pcommon.TraceID{8: 0x48, 9: 0x48, 10: 0x5a, 11: 0x39, 12: 0x53, 13: 0xbb, 14: 0x61, 15: 0x24}, span.TraceID())
+	assert.Equal(t, pcommon.SpanID{0xa2, 0xfb, 0x4a, 0x1d, 0x1a, 0x96, 0xd3, 0x12}, span.ParentSpanID())
+	assert.Equal(t, ptrace.SpanKindInternal, span.Kind())
+
+	rs = traces.ResourceSpans().At(2)
+	service, _ = rs.Resource().Attributes().Get("service.name")
+	assert.Equal(t, "unknown_service", service.Str())
+	span = rs.ScopeSpans().At(0).Spans().At(0)
+	assert.Equal(t, pcommon.SpanID{7: 3}, span.SpanID())
+	assert.Equal(t, ptrace.StatusCodeOk, span.Status().Code())
+}
+
+func TestDecodeJSONErrorMessage(t *testing.T) {
+	traces, err := Decode("", []byte(`[{"traceId": "1", "id": "2", "tags": {"error": "connection refused"}}]`))
+	require.NoError(t, err)
+	span := traces.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+	assert.Equal(t, ptrace.StatusCodeError, span.Status().Code())
+	assert.Equal(t, "connection refused", span.Status().Message())
+}
+
+func TestDecodeProto(t *testing.T) {
+	var endpoint []byte
+	endpoint = protowire.AppendTag(endpoint, 1, protowire.BytesType)
+	endpoint = protowire.AppendString(endpoint, "backend")
+	endpoint = protowire.AppendTag(endpoint, 2, protowire.BytesType)
+	endpoint = protowire.AppendBytes(endpoint, []byte{127, 0, 0, 1})
+
+	var remote []byte
+	remote = protowire.AppendTag(remote, 3, protowire.BytesType)
+	remote = protowire.AppendBytes(remote, []byte{15: 1})
+	remote = protowire.AppendTag(remote, 4, protowire.VarintType)
+	remote = protowire.AppendVarint(remote, 5432)
+
+	var tag []byte
+	tag = protowire.AppendTag(tag, 1, protowire.BytesType)
+	tag = protowire.AppendString(tag, "db.system")
+	tag = protowire.AppendTag(tag, 2, protowire.BytesType)
+	tag = protowire.AppendString(tag, "postgresql")
+
+	var annotation []byte
+	annotation = protowire.AppendTag(annotation, 1, protowire.Fixed64Type)
+	annotation = protowire.AppendFixed64(annotation, 30)
+	annotation = protowire.AppendTag(annotation, 2, protowire.BytesType)
+	annotation = protowire.AppendString(annotation, "cs")
+
+	var span []byte
+	span = protowire.AppendTag(span, 1, protowire.BytesType)
+	span = protowire.AppendBytes(span, []byte{15: 0x2a})
+	span = protowire.AppendTag(span, 2, protowire.BytesType)
+	span = protowire.AppendBytes(span, []byte{7: 1})
+	span = protowire.AppendTag(span, 3, protowire.BytesType)
+	span = protowire.AppendBytes(span, []byte{7: 2})
+	span = protowire.AppendTag(span, 4, protowire.VarintType)
+	span = protowire.AppendVarint(span, 1) // CLIENT
+	span = protowire.AppendTag(span, 5, protowire.BytesType)
+	span = protowire.AppendString(span, "select")
+	span = protowire.AppendTag(span, 6, protowire.Fixed64Type)
+	span = protowire.AppendFixed64(span, 20)
+	span = protowire.AppendTag(span, 7, protowire.VarintType)
+	span = protowire.AppendVarint(span, 15)
+	span = protowire.AppendTag(span, 8, protowire.BytesType)
+	span = protowire.AppendBytes(span, endpoint)
+	span = protowire.AppendTag(span, 9, protowire.BytesType)
+	span = protowire.AppendBytes(span, remote)
+	span = protowire.AppendTag(span, 10, protowire.BytesType)
+	span = protowire.AppendBytes(span, annotation)
+	span = protowire.AppendTag(span, 11, protowire.BytesType)
+	span = protowire.AppendBytes(span, tag)
+
+	var list []byte
+	list = protowire.AppendTag(list, 1, protowire.BytesType)
+	list = protowire.AppendBytes(list, span)
+
+	traces, err := Decode("application/x-protobuf", list)
+	require.NoError(t, err)
+	require.Equal(t, 1, traces.SpanCount())
+	rs := traces.ResourceSpans().At(0)
+	service, _ := rs.Resource().Attributes().Get("service.name")
+	assert.Equal(t, "backend", service.Str())
+
+	out := rs.ScopeSpans().At(0).Spans().At(0)
+	assert.Equal(t, pcommon.TraceID{15: 0x2a}, out.TraceID())
+	assert.Equal(t, pcommon.SpanID{7: 1}, out.ParentSpanID())
+	assert.Equal(t, pcommon.SpanID{7: 2}, out.SpanID())
+	assert.Equal(t, ptrace.SpanKindClient, out.Kind())
+	assert.Equal(t, "select", out.Name())
+	assert.EqualValues(t, 20000, out.StartTimestamp())
+	assert.EqualValues(t, 35000, out.EndTimestamp())
+	assert.Equal(t, map[string]any{
+		"db.system":     "postgresql",
+		"net.peer.ip":   "::1",
+		"net.peer.port": int64(5432),
+	}, out.Attributes().AsRaw())
+	require.Equal(t, 1, out.Events().Len())
+	assert.Equal(t, "cs", out.Events().At(0).Name())
+}
+
+func TestDecodeErrors(t *testing.T) {
+	for name, tt := range map[string]struct {
+		mediaType string
+		data      []byte
+	}{
+		"media-type":     {"application/xml", []byte(`<spans/>`)},
+		"json":           {"application/json", []byte(`{"traceId": "1"}`)},
+		"trace-id":       {"application/json", []byte(`[{"traceId": "xyz", "id": "1"}]`)},
+		"long-trace-id":  {"application/json", []byte(`[{"traceId": "463ac35c9f6413ad48485a3953bb61240", "id": "1"}]`)},
+		"span-id":        {"application/json", []byte(`[{"traceId": "1", "id": ""}]`)},
+		"parent-id":      {"application/json", []byte(`[{"traceId": "1", "id": "1", "parentId": "g"}]`)},
+		"proto":          {"application/x-protobuf", []byte{0x0a, 0x05, 0x01}},
+		"proto-endpoint": {"application/protobuf", []byte{0x0a, 0x05, 0x42, 0x03, 0x12, 0x01, 0x01}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Decode(tt.mediaType, tt.data)
+			assert.Error(t, err)
+		})
+	}
+}
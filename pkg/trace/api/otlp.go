@@ -62,6 +62,7 @@ type OTLPReceiver struct {
 	statsd             statsd.ClientInterface
 	timing             timing.Reporter
 	grpcMaxRecvMsgSize int
+	endpointVersion    string // the endpoint version of the payloads produced by the receiver
 }
 
 // NewOTLPReceiver returns a new OTLPReceiver which sends any incoming traces down the out channel.
@@ -99,7 +100,15 @@ func NewOTLPReceiver(out chan<- *Payload, cfg *config.AgentConfig, statsd statsd
 	if cfg.OTLPReceiver.GrpcMaxRecvMsgSizeMib > 0 {
 		grpcMaxRecvMsgSize = cfg.OTLPReceiver.GrpcMaxRecvMsgSizeMib * 1024 * 1024
 	}
-	return &OTLPReceiver{out: out, conf: cfg, cidProvider: NewIDProvider(cfg.ContainerProcRoot, cfg.ContainerIDFromOriginInfo), statsd: statsd, timing: timing, grpcMaxRecvMsgSize: grpcMaxRecvMsgSize}
+	o := newSpanConverter(out, cfg, NewIDProvider(cfg.ContainerProcRoot, cfg.ContainerIDFromOriginInfo), statsd, timing, "opentelemetry_grpc_v1")
+	o.grpcMaxRecvMsgSize = grpcMaxRecvMsgSize
+	return o
+}
+
+// newSpanConverter returns an OTLPReceiver which doesn't serve OTLP, but converts OpenTelemetry
+// spans decoded by other receivers into payloads tagged with the given endpoint version.
+func newSpanConverter(out chan<- *Payload, cfg *config.AgentConfig, cidProvider IDProvider, statsd statsd.ClientInterface, timing timing.Reporter, endpointVersion string) *OTLPReceiver {
+	return &OTLPReceiver{out: out, conf: cfg, cidProvider: cidProvider, statsd: statsd, timing: timing, endpointVersion: endpointVersion}
 }
 
 // Start starts the OTLPReceiver, if any of the servers were configured as active.
@@ -279,7 +288,7 @@ func (o *OTLPReceiver) receiveResourceSpansV2(ctx context.Context, rspans ptrace
 		Tags: info.Tags{
 			Lang:            lang,
 			TracerVersion:   fmt.Sprintf("otlp-%s", traceutil.GetOTelAttrVal(resourceAttributes, true, string(semconv.TelemetrySDKVersionKey))),
-			EndpointVersion: "opentelemetry_grpc_v1",
+			EndpointVersion: o.endpointVersion,
 		},
 		Stats: info.NewStats(),
 	}
@@ -400,7 +409,7 @@ func (o *OTLPReceiver) receiveResourceSpansV1(ctx context.Context, rspans ptrace
 			Interpreter:     fastHeaderGet(httpHeader, header.LangInterpreter),
 			LangVendor:      fastHeaderGet(httpHeader, header.LangInterpreterVendor),
 			TracerVersion:   fmt.Sprintf("otlp-%s", rattr[string(semconv.TelemetrySDKVersionKey)]),
-			EndpointVersion: "opentelemetry_grpc_v1",
+			EndpointVersion: o.endpointVersion,
 		},
 		Stats: info.NewStats(),
 	}
@@ -0,0 +1,113 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/api/apiutil"
+	"github.com/DataDog/datadog-agent/pkg/trace/api/internal/header"
+	"github.com/DataDog/datadog-agent/pkg/trace/api/internal/jaeger"
+	"github.com/DataDog/datadog-agent/pkg/trace/api/internal/zipkin"
+	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/log"
+)
+
+const (
+	// zipkinEndpointVersion is the endpoint version of the payloads received on the Zipkin endpoint
+	zipkinEndpointVersion = "zipkin_http_v2"
+	// jaegerEndpointVersion is the endpoint version of the payloads received on the Jaeger endpoint
+	jaegerEndpointVersion = "jaeger_http_v1"
+)
+
+// spanDecoder decodes a payload of the given media type into OpenTelemetry traces.
+type spanDecoder func(mediaType string, data []byte) (ptrace.Traces, error)
+
+// handleZipkinSpans returns the handler for the Zipkin v2 /api/v2/spans endpoint,
+// accepting JSON and protobuf encoded lists of spans.
+func (r *HTTPReceiver) handleZipkinSpans() http.Handler {
+	return r.handleConvertedSpans("zipkin", zipkin.Decode, r.zipkinConverter)
+}
+
+// handleJaegerSpans returns the handler for the Jaeger collector /api/traces endpoint,
+// accepting Thrift (binary protocol) batches and protobuf PostSpansRequest messages.
+func (r *HTTPReceiver) handleJaegerSpans() http.Handler {
+	return r.handleConvertedSpans("jaeger", jaeger.Decode, r.jaegerConverter)
+}
+
+// handleConvertedSpans returns a handler decoding spans in a third-party format into
+// OpenTelemetry traces, which then go through the same conversion as spans received
+// over OTLP. Like the traces endpoints, it is subject to the concurrency limit of the
+// decoders.
+func (r *HTTPReceiver) handleConvertedSpans(format string, decode spanDecoder, converter *OTLPReceiver) http.Handler {
+	tags := []string{"handler:" + format}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		r.wg.Add(1)
+		defer r.wg.Done()
+		defer req.Body.Close()
+
+		select {
+		// Wait for the semaphore to become available, allowing the handler to
+		// decode its payload.
+		case r.recvsem <- struct{}{}:
+		case <-time.After(time.Duration(r.conf.DecoderTimeout) * time.Millisecond):
+			log.Debugf("trace-agent is overwhelmed, a %s payload has been rejected", format)
+			io.Copy(io.Discard, req.Body) //nolint:errcheck
+			if isHeaderTrue(header.SendRealHTTPStatus, req.Header.Get(header.SendRealHTTPStatus)) {
+				w.WriteHeader(http.StatusTooManyRequests)
+			} else {
+				w.WriteHeader(r.rateLimiterResponse)
+			}
+			r.Stats.GetTagStats(info.Tags{EndpointVersion: converter.endpointVersion}).PayloadRefused.Inc()
+			return
+		}
+		defer func() {
+			<-r.recvsem
+		}()
+		defer r.timing.Since(fmt.Sprintf("datadog.trace_agent.receiver.%s.process_ms", format), time.Now())
+
+		rd := apiutil.NewLimitedReader(req.Body, r.conf.MaxRequestBytes)
+		var body io.Reader = rd
+		if req.Header.Get("Content-Encoding") == "gzip" {
+			gz, err := gzip.NewReader(rd)
+			if err != nil {
+				httpDecodingError(err, tags, w, r.statsd)
+				return
+			}
+			defer gz.Close()
+			body = gz
+		}
+		data, err := io.ReadAll(body)
+		if err != nil {
+			httpDecodingError(err, tags, w, r.statsd)
+			return
+		}
+		traces, err := decode(getMediaType(req), data)
+		if err != nil {
+			log.Debugf("Error decoding %s spans: %v", format, err)
+			httpDecodingError(err, tags, w, r.statsd)
+			return
+		}
+		_ = r.statsd.Count(fmt.Sprintf("datadog.trace_agent.receiver.%s.payload", format), 1, nil, 1)
+		_ = r.statsd.Count(fmt.Sprintf("datadog.trace_agent.receiver.%s.spans", format), int64(traces.SpanCount()), nil, 1)
+
+		rspans := traces.ResourceSpans()
+		for i := 0; i < rspans.Len(); i++ {
+			converter.ReceiveResourceSpans(req.Context(), rspans.At(i), req.Header, nil)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+}
@@ -0,0 +1,145 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/api/internal/header"
+	"github.com/DataDog/datadog-agent/pkg/trace/info"
+)
+
+const zipkinTestPayload = `[{
+	"traceId": "5af7183fb1d4cf5f",
+	"id": "352bff9a74ca9ad2",
+	"kind": "SERVER",
+	"name": "get /api",
+	"timestamp": 1556604172355737,
+	"duration": 1431,
+	"localEndpoint": {"serviceName": "frontend", "ipv4": "192.168.99.1"},
+	"tags": {"http.method": "GET", "http.path": "/api", "error": "boom"}
+}]`
+
+func TestHandleZipkinSpans(t *testing.T) {
+	cfg := NewTestConfig(t)
+	cfg.EnableZipkinEndpoint = true
+	r := newTestReceiverFromConfig(cfg)
+	handler := r.handleZipkinSpans()
+
+	t.Run("json", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/spans", bytes.NewBufferString(zipkinTestPayload))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+
+		select {
+		case p := <-r.out:
+			require.Len(t, p.TracerPayload.Chunks, 1)
+			require.Len(t, p.TracerPayload.Chunks[0].Spans, 1)
+			span := p.TracerPayload.Chunks[0].Spans[0]
+			assert.Equal(t, "frontend", span.Service)
+			assert.EqualValues(t, 0x352bff9a74ca9ad2, span.SpanID)
+			assert.EqualValues(t, 0x5af7183fb1d4cf5f, span.TraceID)
+			assert.EqualValues(t, 1, span.Error)
+			assert.Equal(t, "boom", span.Meta["error.msg"])
+			assert.EqualValues(t, 1431000, span.Duration)
+			assert.Equal(t, zipkinEndpointVersion, p.Source.EndpointVersion)
+		case <-time.After(time.Second):
+			t.Fatal("no payload received")
+		}
+	})
+
+	t.Run("gzip", func(t *testing.T) {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		_, err := gz.Write([]byte(zipkinTestPayload))
+		require.NoError(t, err)
+		require.NoError(t, gz.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/spans", &buf)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", "gzip")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		select {
+		case p := <-r.out:
+			assert.Equal(t, "frontend", p.TracerPayload.Chunks[0].Spans[0].Service)
+		case <-time.After(time.Second):
+			t.Fatal("no payload received")
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/spans", bytes.NewBufferString(`[{"traceId": "zz"}]`))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Len(t, r.out, 0)
+	})
+
+	t.Run("method", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v2/spans", nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+
+	t.Run("overwhelmed", func(t *testing.T) {
+		r.conf.DecoderTimeout = 10
+		for len(r.recvsem) < cap(r.recvsem) {
+			r.recvsem <- struct{}{}
+		}
+		defer func() {
+			for len(r.recvsem) > 0 {
+				<-r.recvsem
+			}
+		}()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/spans", bytes.NewBufferString(zipkinTestPayload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(header.SendRealHTTPStatus, "true")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Len(t, r.out, 0)
+		assert.EqualValues(t, 1, r.Stats.GetTagStats(info.Tags{EndpointVersion: zipkinEndpointVersion}).PayloadRefused.Load())
+	})
+}
+
+func TestHandleJaegerSpans(t *testing.T) {
+	cfg := NewTestConfig(t)
+	cfg.EnableJaegerEndpoint = true
+	r := newTestReceiverFromConfig(cfg)
+	handler := r.handleJaegerSpans()
+
+	t.Run("unsupported", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/traces", bytes.NewBufferString(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		// a Batch struct opening field 1 (process) and ending abruptly
+		req := httptest.NewRequest(http.MethodPost, "/api/traces", bytes.NewReader([]byte{12, 0, 1, 11}))
+		req.Header.Set("Content-Type", "application/x-thrift")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Len(t, r.out, 0)
+	})
+}
@@ -8,6 +8,7 @@ package config
 import (
 	"crypto/tls"
 	"errors"
+	"fmt"
 	"net"
 	"net/http"
 	"net/url"
@@ -134,6 +135,18 @@ type ObfuscationConfig struct {
 	// CreditCards holds the configuration for obfuscating credit cards.
 	CreditCards obfuscate.CreditCardsConfig `mapstructure:"credit_cards"`
 
+	// GraphQL holds the configuration for obfuscating the "graphql.source" tag
+	// for spans of type "graphql".
+	GraphQL obfuscate.GraphQLConfig `mapstructure:"graphql"`
+
+	// CQL holds the configuration for obfuscating the resource of spans of type
+	// "cassandra" with the CQL obfuscator instead of the SQL one.
+	CQL obfuscate.CQLConfig `mapstructure:"cql"`
+
+	// DynamoDB holds the configuration for obfuscating the "db.statement" tag
+	// for DynamoDB spans.
+	DynamoDB obfuscate.DynamoDBConfig `mapstructure:"dynamodb"`
+
 	// Cache holds the configuration for caching obfuscation results.
 	Cache obfuscate.CacheConfig `mapstructure:"cache"`
 }
@@ -174,6 +187,9 @@ func (o *ObfuscationConfig) Export(conf *AgentConfig) obfuscate.Config {
 		Valkey:               o.Valkey,
 		Memcached:            o.Memcached,
 		CreditCard:           o.CreditCards,
+		GraphQL:              o.GraphQL,
+		CQL:                  o.CQL,
+		DynamoDB:             o.DynamoDB,
 		FullLogger:           new(logger),
 		Cache:                o.Cache,
 	}
@@ -249,6 +265,22 @@ type WriterConfig struct {
 	FlushPeriodSeconds float64 `mapstructure:"flush_period_seconds"`
 }
 
+// DiskBufferConfig specifies the configuration of the on-disk buffer where the writers
+// store the payloads which can't be sent or queued in memory, to replay them once the
+// intake is reachable again.
+type DiskBufferConfig struct {
+	// Enabled reports whether payloads should be buffered on disk instead of being dropped.
+	Enabled bool
+	// Path is the directory where payloads are stored.
+	Path string
+	// MaxSizeBytes is the maximum size of the payloads stored for each intake endpoint.
+	// When reached, the oldest payloads are dropped.
+	MaxSizeBytes int64
+	// MaxAge is the maximum age of a stored payload. Older payloads are dropped instead
+	// of being replayed.
+	MaxAge time.Duration
+}
+
 // FargateOrchestratorName is a Fargate orchestrator name.
 type FargateOrchestratorName string
 
@@ -261,6 +293,100 @@ const (
 	OrchestratorUnknown FargateOrchestratorName = "Unknown"
 )
 
+// TailSamplingConfig holds the configuration of the tail-based sampler. When enabled,
+// spans are buffered by trace ID and the sampling decision is taken once the
+// whole trace has been received, instead of on each chunk as it arrives.
+type TailSamplingConfig struct {
+	// Enabled reports whether tail-based sampling is enabled.
+	Enabled bool
+	// DecisionWait is the time spans of a trace are buffered before a decision is taken.
+	DecisionWait time.Duration
+	// MaxTraces is the maximum number of traces buffered at any given time. Up to as many
+	// traces kept by the head samplers are remembered separately.
+	MaxTraces int
+	// MaxMemoryBytes is the maximum estimated size of the buffered spans, including the string
+	// tables of V1 payloads. When reached,
+	// the oldest traces are decided early with the spans received so far.
+	MaxMemoryBytes int64
+	// Policies lists the rules used to keep a trace. A trace is kept as soon as any policy matches.
+	Policies []*TailSamplingPolicy
+}
+
+// Types of tail sampling policies.
+const (
+	// TailSamplingPolicyError keeps traces with at least one span in error.
+	TailSamplingPolicyError = "error"
+	// TailSamplingPolicyLatency keeps traces whose root span lasts at least ThresholdMs.
+	TailSamplingPolicyLatency = "latency"
+	// TailSamplingPolicyAttribute keeps traces with a span holding the Key meta or metric.
+	TailSamplingPolicyAttribute = "attribute"
+	// TailSamplingPolicyRate keeps a Rate share of the traces.
+	TailSamplingPolicyRate = "rate"
+)
+
+// TailSamplingPolicy defines a single tail sampling rule.
+type TailSamplingPolicy struct {
+	// Name identifies the policy in telemetry and on kept root spans.
+	Name string `mapstructure:"name" json:"name"`
+	// Type is one of "error", "latency", "attribute" or "rate".
+	Type string `mapstructure:"type" json:"type"`
+	// Service restricts the policy to traces whose root span has this service. Empty matches all services.
+	Service string `mapstructure:"service" json:"service"`
+	// ThresholdMs is the minimum root span duration in milliseconds for "latency" policies.
+	ThresholdMs int64 `mapstructure:"threshold_ms" json:"threshold_ms"`
+	// Key is the meta or metric key looked up by "attribute" policies.
+	Key string `mapstructure:"key" json:"key"`
+	// Value, if set, is the meta value required by "attribute" policies.
+	Value string `mapstructure:"value" json:"value"`
+	// Rate is the sampling rate applied by "rate" policies, between 0 and 1.
+	Rate float64 `mapstructure:"rate" json:"rate"`
+}
+
+// Validate returns an error if the policy can't be applied.
+func (p *TailSamplingPolicy) Validate() error {
+	name := p.Name
+	if name == "" {
+		name = p.Type
+	}
+	switch p.Type {
+	case TailSamplingPolicyError:
+	case TailSamplingPolicyLatency:
+		if p.ThresholdMs <= 0 {
+			return fmt.Errorf("policy %q: threshold_ms must be positive, got %d", name, p.ThresholdMs)
+		}
+	case TailSamplingPolicyAttribute:
+		if p.Key == "" {
+			return fmt.Errorf("policy %q: attribute policies require a key", name)
+		}
+	case TailSamplingPolicyRate:
+		if p.Rate < 0 || p.Rate > 1 {
+			return fmt.Errorf("policy %q: rate must be between 0 and 1, got %v", name, p.Rate)
+		}
+	default:
+		return fmt.Errorf("policy %q: unknown type %q, valid types are %q, %q, %q and %q", name, p.Type,
+			TailSamplingPolicyError, TailSamplingPolicyLatency, TailSamplingPolicyAttribute, TailSamplingPolicyRate)
+	}
+	return nil
+}
+
+// SpanFilterRule defines a rule dropping, keeping or rewriting the spans matching its condition.
+// Rules are evaluated in order on each span before sampling; see pkg/trace/filters for the
+// condition syntax.
+type SpanFilterRule struct {
+	// Name identifies the rule in logs.
+	Name string `mapstructure:"name" json:"name"`
+	// Action is one of "drop", "keep" or "rewrite". A span matching a "drop" or "keep" rule
+	// is not evaluated against the following rules, while "rewrite" rules are cumulative.
+	Action string `mapstructure:"action" json:"action"`
+	// Condition is the expression a span must match for the rule to apply. Empty matches all spans.
+	Condition string `mapstructure:"condition" json:"condition"`
+	// Set maps the tags set by "rewrite" rules to their new value. The "service", "name",
+	// "resource" and "type" keys address the span fields of the same name.
+	Set map[string]string `mapstructure:"set" json:"set"`
+	// Delete lists the meta and metrics keys removed by "rewrite" rules.
+	Delete []string `mapstructure:"delete" json:"delete"`
+}
+
 // ProfilingProxyConfig ...
 type ProfilingProxyConfig struct {
 	// DDURL ...
@@ -384,6 +510,9 @@ type AgentConfig struct {
 	ProbabilisticSamplerHashSeed           uint32
 	ProbabilisticSamplerSamplingPercentage float32
 
+	// Tail Sampler configuration
+	TailSampling *TailSamplingConfig
+
 	// Error Tracking Standalone
 	ErrorTrackingStandalone bool
 
@@ -417,6 +546,8 @@ type AgentConfig struct {
 	// case, the sender will drop failed payloads when it is unable to enqueue
 	// them for another retry.
 	MaxSenderRetries int
+	// DiskBuffer specifies the on-disk buffer for the payloads which can't be sent or queued in memory.
+	DiskBuffer *DiskBufferConfig
 	// HTTP Transport used in writer connections. If nil, default transport values will be used.
 	HTTPTransportFunc func() *http.Transport `json:"-"`
 	// ClientStatsFlushInterval specifies the frequency at which the client stats aggregator will flush its buffer.
@@ -448,6 +579,10 @@ type AgentConfig struct {
 	// It maps tag keys to a set of replacements. Only supported in A6.
 	ReplaceTags []*ReplaceRule
 
+	// SpanFilters lists the rules dropping, keeping or rewriting individual spans before sampling.
+	// They can be overridden at runtime through remote configuration.
+	SpanFilters []*SpanFilterRule
+
 	// GlobalTags list metadata that will be added to all spans
 	GlobalTags map[string]string
 
@@ -556,6 +691,12 @@ type AgentConfig struct {
 	// EnableV1TraceEndpoint enables the V1 trace endpoint, it is hidden by default
 	EnableV1TraceEndpoint bool
 
+	// EnableZipkinEndpoint enables the Zipkin v2 /api/v2/spans endpoint on the receiver
+	EnableZipkinEndpoint bool
+
+	// EnableJaegerEndpoint enables the Jaeger collector /api/traces endpoint on the receiver
+	EnableJaegerEndpoint bool
+
 	// SendAllInternalStats enables all internal stats to be published, otherwise some less-frequently-used stats will be omitted when zero to save costs
 	SendAllInternalStats bool
 }
@@ -603,6 +744,12 @@ func New() *AgentConfig {
 		RareSamplerCooldownPeriod: 5 * time.Minute,
 		RareSamplerCardinality:    200,
 
+		TailSampling: &TailSamplingConfig{
+			DecisionWait:   10 * time.Second,
+			MaxTraces:      50000,
+			MaxMemoryBytes: 64 * 1024 * 1024, // 64MB
+		},
+
 		ErrorTrackingStandalone: false,
 
 		ReceiverEnabled:        true,
@@ -618,6 +765,10 @@ func New() *AgentConfig {
 		ConnectionResetInterval:  0, // disabled
 		MaxSenderRetries:         4,
 		ClientStatsFlushInterval: 2 * time.Second, // bucket duration (2s)
+		DiskBuffer: &DiskBufferConfig{
+			MaxSizeBytes: 100 * 1024 * 1024, // 100MB
+			MaxAge:       time.Hour,
+		},
 
 		StatsdHost:    "localhost",
 		StatsdPort:    8125,
@@ -133,3 +133,27 @@ func TestSQLObfuscationMode(t *testing.T) {
 		assert.Equal(t, obfuscate.ObfuscateOnly, obfuscationMode(cfg, true))
 	})
 }
+
+func TestTailSamplingPolicyValidate(t *testing.T) {
+	for _, tc := range []struct {
+		policy TailSamplingPolicy
+		err    string
+	}{
+		{policy: TailSamplingPolicy{Type: "error"}},
+		{policy: TailSamplingPolicy{Type: "latency", ThresholdMs: 100}},
+		{policy: TailSamplingPolicy{Type: "latency"}, err: `policy "latency": threshold_ms must be positive, got 0`},
+		{policy: TailSamplingPolicy{Type: "attribute", Key: "customer.tier"}},
+		{policy: TailSamplingPolicy{Name: "tier", Type: "attribute"}, err: `policy "tier": attribute policies require a key`},
+		{policy: TailSamplingPolicy{Type: "rate", Rate: 0.5}},
+		{policy: TailSamplingPolicy{Type: "rate", Rate: 1.5}, err: `policy "rate": rate must be between 0 and 1, got 1.5`},
+		{policy: TailSamplingPolicy{Type: "rate", Rate: -1}, err: `policy "rate": rate must be between 0 and 1, got -1`},
+		{policy: TailSamplingPolicy{Name: "slow", Type: "duration"}, err: `policy "slow": unknown type "duration"`},
+	} {
+		err := tc.policy.Validate()
+		if tc.err == "" {
+			assert.NoError(t, err)
+		} else {
+			assert.ErrorContains(t, err, tc.err)
+		}
+	}
+}
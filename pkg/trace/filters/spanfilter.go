@@ -0,0 +1,299 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package filters
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace/idx"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/log"
+)
+
+// Span filter rule actions.
+const (
+	actionDrop    = "drop"
+	actionKeep    = "keep"
+	actionRewrite = "rewrite"
+)
+
+// spanRule is a compiled config.SpanFilterRule.
+type spanRule struct {
+	name   string
+	action string
+	cond   condition
+	set    map[string]string
+	delete []string
+}
+
+// compileSpanRule compiles the condition of r and validates its action.
+func compileSpanRule(r *config.SpanFilterRule) (*spanRule, error) {
+	switch r.Action {
+	case actionDrop, actionKeep:
+	case actionRewrite:
+		if len(r.Set) == 0 && len(r.Delete) == 0 {
+			return nil, errors.New(`"rewrite" rule has nothing to set or delete`)
+		}
+	default:
+		return nil, fmt.Errorf("unknown action %q", r.Action)
+	}
+	cond, err := parseCondition(r.Condition)
+	if err != nil {
+		return nil, err
+	}
+	return &spanRule{name: r.Name, action: r.Action, cond: cond, set: r.Set, delete: r.Delete}, nil
+}
+
+// SpanFilter drops, keeps or rewrites individual spans according to a list of rules,
+// each one made of an action and a condition on the span service, name, resource, type,
+// duration, error, meta or metrics. The rules are evaluated in order on each span: the
+// first matching "drop" or "keep" rule decides the fate of the span, while all matching
+// "rewrite" rules preceding it are applied.
+//
+// The rules can be replaced at runtime with UpdateRules. SpanFilter is safe for concurrent use.
+type SpanFilter struct {
+	// local holds the rules coming from the agent configuration.
+	local []*spanRule
+	// rules holds the rules in use, either local or set through UpdateRules.
+	rules atomic.Pointer[[]*spanRule]
+}
+
+// NewSpanFilter returns a new SpanFilter using the given rules. Invalid rules are logged
+// and ignored.
+func NewSpanFilter(rules []*config.SpanFilterRule) *SpanFilter {
+	f := &SpanFilter{local: make([]*spanRule, 0, len(rules))}
+	for _, r := range rules {
+		rule, err := compileSpanRule(r)
+		if err != nil {
+			log.Errorf("Invalid span filter rule %q: %v", r.Name, err)
+			continue
+		}
+		f.local = append(f.local, rule)
+	}
+	f.rules.Store(&f.local)
+	return f
+}
+
+// UpdateRules replaces the rules in use by the given ones. An empty list restores the rules
+// from the agent configuration. If any rule is invalid, an error is returned and the rules
+// in use are left unchanged.
+func (f *SpanFilter) UpdateRules(rules []*config.SpanFilterRule) error {
+	if len(rules) == 0 {
+		f.rules.Store(&f.local)
+		return nil
+	}
+	compiled := make([]*spanRule, 0, len(rules))
+	for _, r := range rules {
+		rule, err := compileSpanRule(r)
+		if err != nil {
+			return fmt.Errorf("invalid span filter rule %q: %w", r.Name, err)
+		}
+		compiled = append(compiled, rule)
+	}
+	f.rules.Store(&compiled)
+	return nil
+}
+
+// getRules returns the rules in use, or nil if f is nil.
+func (f *SpanFilter) getRules() []*spanRule {
+	if f == nil {
+		return nil
+	}
+	return *f.rules.Load()
+}
+
+// match evaluates the rules on span s, applying the matching "rewrite" rules, and reports
+// whether the span must be dropped.
+func match(rules []*spanRule, s spanView) bool {
+	for _, rule := range rules {
+		if !rule.cond.eval(s) {
+			continue
+		}
+		switch rule.action {
+		case actionDrop:
+			return true
+		case actionKeep:
+			return false
+		default:
+			for _, k := range rule.delete {
+				s.deleteTag(k)
+			}
+			for k, v := range rule.set {
+				s.setTag(k, v)
+			}
+		}
+	}
+	return false
+}
+
+// Apply runs the rules on every span of the chunk, removing the dropped ones, and returns
+// the number of spans dropped. The children of a dropped span are attached to its parent.
+func (f *SpanFilter) Apply(chunk *pb.TraceChunk) int {
+	rules := f.getRules()
+	if len(rules) == 0 {
+		return 0
+	}
+	var reparent map[uint64]uint64
+	kept := chunk.Spans[:0]
+	for _, span := range chunk.Spans {
+		if match(rules, pbSpanView{span}) {
+			if reparent == nil {
+				reparent = make(map[uint64]uint64)
+			}
+			reparent[span.SpanID] = span.ParentID
+			continue
+		}
+		kept = append(kept, span)
+	}
+	dropped := len(chunk.Spans) - len(kept)
+	for i := len(kept); i < len(chunk.Spans); i++ {
+		chunk.Spans[i] = nil
+	}
+	chunk.Spans = kept
+	for _, span := range kept {
+		span.ParentID = resolveParent(reparent, span.ParentID)
+	}
+	return dropped
+}
+
+// ApplyV1 runs the rules on every span of the chunk, removing the dropped ones, and returns
+// the number of spans dropped. The children of a dropped span are attached to its parent.
+func (f *SpanFilter) ApplyV1(chunk *idx.InternalTraceChunk) int {
+	rules := f.getRules()
+	if len(rules) == 0 {
+		return 0
+	}
+	var reparent map[uint64]uint64
+	kept := chunk.Spans[:0]
+	for _, span := range chunk.Spans {
+		if match(rules, internalSpanView{span}) {
+			if reparent == nil {
+				reparent = make(map[uint64]uint64)
+			}
+			reparent[span.SpanID()] = span.ParentID()
+			continue
+		}
+		kept = append(kept, span)
+	}
+	dropped := len(chunk.Spans) - len(kept)
+	for i := len(kept); i < len(chunk.Spans); i++ {
+		chunk.Spans[i] = nil
+	}
+	chunk.Spans = kept
+	for _, span := range kept {
+		if parent := resolveParent(reparent, span.ParentID()); parent != span.ParentID() {
+			span.SetParentID(parent)
+		}
+	}
+	return dropped
+}
+
+// resolveParent returns the closest ancestor of a span with the given parent ID that was not dropped.
+func resolveParent(dropped map[uint64]uint64, parentID uint64) uint64 {
+	for i := 0; i < len(dropped); i++ {
+		grandParent, ok := dropped[parentID]
+		if !ok {
+			break
+		}
+		parentID = grandParent
+	}
+	return parentID
+}
+
+// spanView gives the rules access to a span, whatever its representation.
+type spanView interface {
+	service() string
+	name() string
+	resource() string
+	spanType() string
+	duration() int64
+	isError() bool
+	meta(key string) (string, bool)
+	metric(key string) (float64, bool)
+	setTag(key, value string)
+	deleteTag(key string)
+}
+
+type pbSpanView struct{ s *pb.Span }
+
+func (v pbSpanView) service() string  { return v.s.Service }
+func (v pbSpanView) name() string     { return v.s.Name }
+func (v pbSpanView) resource() string { return v.s.Resource }
+func (v pbSpanView) spanType() string { return v.s.Type }
+func (v pbSpanView) duration() int64  { return v.s.Duration }
+func (v pbSpanView) isError() bool    { return v.s.Error != 0 }
+
+func (v pbSpanView) meta(key string) (string, bool) {
+	val, ok := v.s.Meta[key]
+	return val, ok
+}
+
+func (v pbSpanView) metric(key string) (float64, bool) {
+	val, ok := v.s.Metrics[key]
+	return val, ok
+}
+
+func (v pbSpanView) setTag(key, value string) {
+	switch key {
+	case "service":
+		v.s.Service = value
+	case "name":
+		v.s.Name = value
+	case "resource":
+		v.s.Resource = value
+	case "type":
+		v.s.Type = value
+	default:
+		if v.s.Meta == nil {
+			v.s.Meta = make(map[string]string)
+		}
+		v.s.Meta[key] = value
+	}
+}
+
+func (v pbSpanView) deleteTag(key string) {
+	delete(v.s.Meta, key)
+	delete(v.s.Metrics, key)
+}
+
+type internalSpanView struct{ s *idx.InternalSpan }
+
+func (v internalSpanView) service() string  { return v.s.Service() }
+func (v internalSpanView) name() string     { return v.s.Name() }
+func (v internalSpanView) resource() string { return v.s.Resource() }
+func (v internalSpanView) spanType() string { return v.s.Type() }
+func (v internalSpanView) duration() int64  { return int64(v.s.Duration()) }
+func (v internalSpanView) isError() bool    { return v.s.Error() }
+
+func (v internalSpanView) meta(key string) (string, bool) {
+	return v.s.GetAttributeAsString(key)
+}
+
+func (v internalSpanView) metric(key string) (float64, bool) {
+	return v.s.GetAttributeAsFloat64(key)
+}
+
+func (v internalSpanView) setTag(key, value string) {
+	switch key {
+	case "service":
+		v.s.SetService(value)
+	case "name":
+		v.s.SetName(value)
+	case "resource":
+		v.s.SetResource(value)
+	case "type":
+		v.s.SetType(value)
+	default:
+		v.s.SetStringAttribute(key, value)
+	}
+}
+
+func (v internalSpanView) deleteTag(key string) {
+	v.s.DeleteAttribute(key)
+}
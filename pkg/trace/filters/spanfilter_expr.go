@@ -0,0 +1,525 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package filters
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The span filter conditions use the following grammar:
+//
+//	condition  = or
+//	or         = and { ( "||" | "or" ) and }
+//	and        = not { ( "&&" | "and" ) not }
+//	not        = ( "!" | "not" ) not | "(" or ")" | predicate
+//	predicate  = "exists" "(" attribute ")" | attribute operator literal
+//	attribute  = "service" | "name" | "resource" | "type" | "duration" | "error"
+//	           | "meta" "[" string "]" | "metrics" "[" string "]"
+//	operator   = "==" | "!=" | "<" | "<=" | ">" | ">=" | "matches" | "=~"
+//	literal    = string | number | duration | "true" | "false"
+//
+// Strings are double-quoted with Go escapes. Durations are numbers followed by a unit
+// ("ns", "us", "µs", "ms", "s", "m" or "h") and are compared in nanoseconds, the unit of
+// the span duration. The service, name, resource, type and meta attributes are compared
+// to strings, "matches" testing them against a regular expression. The duration, error
+// and metrics attributes are compared to numbers. A predicate on a meta or metrics key
+// that the span does not have never matches.
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOperator
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+	tokLBracket
+	tokRBracket
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	num  float64
+	pos  int
+}
+
+// symbols lists the punctuation tokens, longest first.
+var symbols = []struct {
+	text string
+	kind tokenKind
+}{
+	{"&&", tokAnd},
+	{"||", tokOr},
+	{"==", tokOperator},
+	{"!=", tokOperator},
+	{"<=", tokOperator},
+	{">=", tokOperator},
+	{"=~", tokOperator},
+	{"<", tokOperator},
+	{">", tokOperator},
+	{"!", tokNot},
+	{"(", tokLParen},
+	{")", tokRParen},
+	{"[", tokLBracket},
+	{"]", tokRBracket},
+}
+
+// lex splits the condition s into tokens.
+func lex(s string) ([]token, error) {
+	var tokens []token
+	i := 0
+next:
+	for i < len(s) {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+			continue
+		case c == '"':
+			j := i + 1
+			for j < len(s) && s[j] != '"' {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+			str, err := strconv.Unquote(s[i : j+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at position %d: %v", i, err)
+			}
+			tokens = append(tokens, token{kind: tokString, text: str, pos: i})
+			i = j + 1
+			continue
+		case isDigit(c) || ((c == '-' || c == '.') && i+1 < len(s) && isDigit(s[i+1])):
+			tok, n, err := lexNumber(s[i:])
+			if err != nil {
+				return nil, fmt.Errorf("invalid number at position %d: %v", i, err)
+			}
+			tok.pos = i
+			tokens = append(tokens, tok)
+			i += n
+			continue
+		case isLetter(c):
+			j := i
+			for j < len(s) && (isLetter(s[j]) || isDigit(s[j]) || s[j] == '.') {
+				j++
+			}
+			word := s[i:j]
+			kind := tokIdent
+			switch word {
+			case "and":
+				kind = tokAnd
+			case "or":
+				kind = tokOr
+			case "not":
+				kind = tokNot
+			case "matches":
+				kind = tokOperator
+			}
+			tokens = append(tokens, token{kind: kind, text: word, pos: i})
+			i = j
+			continue
+		}
+		for _, sym := range symbols {
+			if strings.HasPrefix(s[i:], sym.text) {
+				tokens = append(tokens, token{kind: sym.kind, text: sym.text, pos: i})
+				i += len(sym.text)
+				continue next
+			}
+		}
+		return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(s)}), nil
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func isLetter(c byte) bool { return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
+
+// lexNumber reads a number or a duration at the beginning of s and returns it along
+// with the number of bytes read. Durations are converted to nanoseconds.
+func lexNumber(s string) (token, int, error) {
+	n := 0
+	if s[0] == '-' {
+		n++
+	}
+	for n < len(s) && (isDigit(s[n]) || s[n] == '.') {
+		n++
+	}
+	unit := n
+	for unit < len(s) {
+		if s[unit] >= 'a' && s[unit] <= 'z' {
+			unit++
+		} else if strings.HasPrefix(s[unit:], "µ") {
+			unit += len("µ")
+		} else {
+			break
+		}
+	}
+	if unit > n {
+		d, err := time.ParseDuration(s[:unit])
+		if err != nil {
+			return token{}, 0, err
+		}
+		return token{kind: tokNumber, text: s[:unit], num: float64(d.Nanoseconds())}, unit, nil
+	}
+	f, err := strconv.ParseFloat(s[:n], 64)
+	if err != nil {
+		return token{}, 0, err
+	}
+	return token{kind: tokNumber, text: s[:n], num: f}, n, nil
+}
+
+// attrKind identifies the span attribute a predicate applies to.
+type attrKind int
+
+const (
+	attrService attrKind = iota
+	attrName
+	attrResource
+	attrType
+	attrDuration
+	attrError
+	attrMeta
+	attrMetrics
+)
+
+var attrKinds = map[string]attrKind{
+	"service":  attrService,
+	"name":     attrName,
+	"resource": attrResource,
+	"type":     attrType,
+	"duration": attrDuration,
+	"error":    attrError,
+	"meta":     attrMeta,
+	"metrics":  attrMetrics,
+}
+
+// attribute is a span attribute referenced by a condition.
+type attribute struct {
+	kind attrKind
+	key  string // meta or metrics key
+}
+
+func (a attribute) numeric() bool {
+	return a.kind == attrDuration || a.kind == attrError || a.kind == attrMetrics
+}
+
+func (a attribute) stringValue(s spanView) (string, bool) {
+	switch a.kind {
+	case attrService:
+		return s.service(), true
+	case attrName:
+		return s.name(), true
+	case attrResource:
+		return s.resource(), true
+	case attrType:
+		return s.spanType(), true
+	case attrMeta:
+		return s.meta(a.key)
+	}
+	return "", false
+}
+
+func (a attribute) numberValue(s spanView) (float64, bool) {
+	switch a.kind {
+	case attrDuration:
+		return float64(s.duration()), true
+	case attrError:
+		if s.isError() {
+			return 1, true
+		}
+		return 0, true
+	case attrMetrics:
+		return s.metric(a.key)
+	}
+	return 0, false
+}
+
+// condition is a compiled span filter condition.
+type condition interface {
+	eval(s spanView) bool
+}
+
+type matchAll struct{}
+
+func (matchAll) eval(spanView) bool { return true }
+
+type andCond struct{ left, right condition }
+
+func (c andCond) eval(s spanView) bool { return c.left.eval(s) && c.right.eval(s) }
+
+type orCond struct{ left, right condition }
+
+func (c orCond) eval(s spanView) bool { return c.left.eval(s) || c.right.eval(s) }
+
+type notCond struct{ cond condition }
+
+func (c notCond) eval(s spanView) bool { return !c.cond.eval(s) }
+
+type existsCond struct{ attr attribute }
+
+func (c existsCond) eval(s spanView) bool {
+	if c.attr.numeric() {
+		_, ok := c.attr.numberValue(s)
+		return ok
+	}
+	_, ok := c.attr.stringValue(s)
+	return ok
+}
+
+type stringCond struct {
+	attr  attribute
+	op    string
+	value string
+	re    *regexp.Regexp
+}
+
+func (c stringCond) eval(s spanView) bool {
+	v, ok := c.attr.stringValue(s)
+	if !ok {
+		return false
+	}
+	switch c.op {
+	case "==":
+		return v == c.value
+	case "!=":
+		return v != c.value
+	default:
+		return c.re.MatchString(v)
+	}
+}
+
+type numberCond struct {
+	attr  attribute
+	op    string
+	value float64
+}
+
+func (c numberCond) eval(s spanView) bool {
+	v, ok := c.attr.numberValue(s)
+	if !ok {
+		return false
+	}
+	switch c.op {
+	case "==":
+		return v == c.value
+	case "!=":
+		return v != c.value
+	case "<":
+		return v < c.value
+	case "<=":
+		return v <= c.value
+	case ">":
+		return v > c.value
+	default:
+		return v >= c.value
+	}
+}
+
+// parseCondition compiles the condition expression s. An empty expression matches all spans.
+func parseCondition(s string) (condition, error) {
+	if strings.TrimSpace(s) == "" {
+		return matchAll{}, nil
+	}
+	tokens, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	cond, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+	return cond, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) expect(kind tokenKind, what string) (token, error) {
+	tok := p.next()
+	if tok.kind != kind {
+		return tok, unexpected(tok, what)
+	}
+	return tok, nil
+}
+
+func unexpected(tok token, what string) error {
+	if tok.kind == tokEOF {
+		return fmt.Errorf("unexpected end of condition, expected %s", what)
+	}
+	return fmt.Errorf("unexpected %q at position %d, expected %s", tok.text, tok.pos, what)
+}
+
+func (p *parser) parseOr() (condition, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orCond{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (condition, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokAnd {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andCond{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (condition, error) {
+	switch p.peek().kind {
+	case tokNot:
+		p.next()
+		cond, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notCond{cond}, nil
+	case tokLParen:
+		p.next()
+		cond, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokRParen, `")"`); err != nil {
+			return nil, err
+		}
+		return cond, nil
+	}
+	return p.parsePredicate()
+}
+
+func (p *parser) parsePredicate() (condition, error) {
+	if tok := p.peek(); tok.kind == tokIdent && tok.text == "exists" {
+		p.next()
+		if _, err := p.expect(tokLParen, `"("`); err != nil {
+			return nil, err
+		}
+		attr, err := p.parseAttribute()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokRParen, `")"`); err != nil {
+			return nil, err
+		}
+		return existsCond{attr}, nil
+	}
+	attr, err := p.parseAttribute()
+	if err != nil {
+		return nil, err
+	}
+	opTok, err := p.expect(tokOperator, "an operator")
+	if err != nil {
+		return nil, err
+	}
+	op := opTok.text
+	if op == "=~" {
+		op = "matches"
+	}
+	lit := p.next()
+	if attr.numeric() {
+		value := lit.num
+		switch {
+		case lit.kind == tokIdent && (lit.text == "true" || lit.text == "false"):
+			value = 0
+			if lit.text == "true" {
+				value = 1
+			}
+		case lit.kind != tokNumber:
+			return nil, unexpected(lit, "a number")
+		}
+		if op == "matches" {
+			return nil, fmt.Errorf(`operator "matches" at position %d only applies to string attributes`, opTok.pos)
+		}
+		return numberCond{attr: attr, op: op, value: value}, nil
+	}
+	if lit.kind != tokString {
+		return nil, unexpected(lit, "a string")
+	}
+	cond := stringCond{attr: attr, op: op, value: lit.text}
+	switch op {
+	case "==", "!=":
+	case "matches":
+		if cond.re, err = regexp.Compile(lit.text); err != nil {
+			return nil, fmt.Errorf("invalid regular expression at position %d: %v", lit.pos, err)
+		}
+	default:
+		return nil, fmt.Errorf("operator %q at position %d only applies to numeric attributes", op, opTok.pos)
+	}
+	return cond, nil
+}
+
+func (p *parser) parseAttribute() (attribute, error) {
+	tok, err := p.expect(tokIdent, "an attribute")
+	if err != nil {
+		return attribute{}, err
+	}
+	kind, ok := attrKinds[tok.text]
+	if !ok {
+		return attribute{}, fmt.Errorf("unknown attribute %q at position %d", tok.text, tok.pos)
+	}
+	attr := attribute{kind: kind}
+	if kind == attrMeta || kind == attrMetrics {
+		if _, err := p.expect(tokLBracket, `"["`); err != nil {
+			return attribute{}, err
+		}
+		key, err := p.expect(tokString, "a quoted key")
+		if err != nil {
+			return attribute{}, err
+		}
+		if _, err := p.expect(tokRBracket, `"]"`); err != nil {
+			return attribute{}, err
+		}
+		attr.key = key.text
+	}
+	return attr, nil
+}
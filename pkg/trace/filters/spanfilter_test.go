@@ -0,0 +1,237 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package filters
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace/idx"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+)
+
+func newTestFilterSpan() *pb.Span {
+	return &pb.Span{
+		Service:  "web",
+		Name:     "http.request",
+		Resource: "GET /health",
+		Type:     "web",
+		Duration: int64(3 * time.Millisecond),
+		Meta:     map[string]string{"http.url": "http://localhost/health", "http.method": "GET"},
+		Metrics:  map[string]float64{"http.status_code": 200, "_top_level": 1},
+	}
+}
+
+func TestParseCondition(t *testing.T) {
+	for _, tt := range []struct {
+		cond string
+		want bool
+	}{
+		{``, true},
+		{`service == "web"`, true},
+		{`service != "web"`, false},
+		{`name == "http.request" && resource == "GET /health"`, true},
+		{`name == "db.query" || resource == "GET /health"`, true},
+		{`name == "db.query" or (resource == "GET /health" and type == "web")`, true},
+		{`!(service == "web")`, false},
+		{`not service == "db"`, true},
+		{`meta["http.url"] matches "/health$" && duration < 5ms`, true},
+		{`meta["http.url"] =~ "^/health"`, false},
+		{`meta["http.url"] matches "/health" && duration < 2ms`, false},
+		{`duration >= 3000000`, true},
+		{`duration > 1.5ms && duration <= 0.003s`, true},
+		{`duration == 3ms`, true},
+		{`error == false`, true},
+		{`error == true`, false},
+		{`error != 0`, false},
+		{`metrics["http.status_code"] >= 200 && metrics["http.status_code"] < 300`, true},
+		{`metrics["http.status_code"] == -1`, false},
+		{`metrics["missing"] != 0`, false},
+		{`meta["missing"] != "x"`, false},
+		{`exists(meta["http.method"])`, true},
+		{`exists(meta["http.status_code"])`, false},
+		{`exists(metrics["http.status_code"])`, true},
+		{`!exists(meta["user.id"])`, true},
+		{`meta["http.method"] == "G\x45T"`, true},
+	} {
+		t.Run(tt.cond, func(t *testing.T) {
+			cond, err := parseCondition(tt.cond)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cond.eval(pbSpanView{newTestFilterSpan()}))
+		})
+	}
+}
+
+func TestParseConditionErrors(t *testing.T) {
+	for cond, msg := range map[string]string{
+		`service`:                     "unexpected end of condition, expected an operator",
+		`service == `:                 "unexpected end of condition, expected a string",
+		`service == "web`:             "unterminated string at position 11",
+		`service < "web"`:             `operator "<" at position 8 only applies to numeric attributes`,
+		`service == 3`:                `unexpected "3" at position 11, expected a string`,
+		`duration matches "1"`:        `unexpected "1" at position 17, expected a number`,
+		`duration matches 1`:          `operator "matches" at position 9 only applies to string attributes`,
+		`duration < 5parsecs`:         "invalid number at position 11",
+		`host == "a"`:                 `unknown attribute "host" at position 0`,
+		`meta.http.url == "a"`:        `unknown attribute "meta.http.url" at position 0`,
+		`meta[http.url] == "a"`:       `unexpected "http.url" at position 5, expected a quoted key`,
+		`meta["a" == "b"`:             `unexpected "==" at position 9, expected "]"`,
+		`(service == "a"`:             `unexpected end of condition, expected ")"`,
+		`service == "a")`:             `unexpected ")" at position 14`,
+		`resource matches "("`:        "invalid regular expression at position 17",
+		`service == "a" & name == ""`: "unexpected character '&' at position 15",
+		`exists(service`:              `unexpected end of condition, expected ")"`,
+	} {
+		t.Run(cond, func(t *testing.T) {
+			_, err := parseCondition(cond)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), msg)
+		})
+	}
+}
+
+func TestNewSpanFilterInvalidRules(t *testing.T) {
+	f := NewSpanFilter([]*config.SpanFilterRule{
+		{Name: "bad-action", Action: "sample"},
+		{Name: "bad-condition", Action: "drop", Condition: "service =="},
+		{Name: "empty-rewrite", Action: "rewrite"},
+		{Name: "ok", Action: "drop", Condition: `resource == "GET /health"`},
+	})
+	require.Len(t, f.getRules(), 1)
+	assert.Equal(t, "ok", f.getRules()[0].name)
+}
+
+func TestSpanFilterApply(t *testing.T) {
+	newChunk := func() *pb.TraceChunk {
+		root := newTestFilterSpan()
+		root.SpanID, root.Resource = 1, "GET /users"
+		root.Meta["http.url"] = "http://localhost/users"
+		health := newTestFilterSpan()
+		health.SpanID, health.ParentID = 2, 1
+		child := newTestFilterSpan()
+		child.SpanID, child.ParentID, child.Name, child.Resource = 3, 2, "db.query", "SELECT 1"
+		child.Meta["http.url"] = "http://localhost/users"
+		child.Meta["db.user"] = "admin"
+		grandChild := newTestFilterSpan()
+		grandChild.SpanID, grandChild.ParentID, grandChild.Name = 4, 3, "cache.get"
+		grandChild.Meta["http.url"] = "http://localhost/users"
+		return &pb.TraceChunk{Spans: []*pb.Span{root, health, child, grandChild}}
+	}
+
+	t.Run("no-rules", func(t *testing.T) {
+		chunk := newChunk()
+		assert.Equal(t, 0, NewSpanFilter(nil).Apply(chunk))
+		assert.Len(t, chunk.Spans, 4)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		var f *SpanFilter
+		assert.Equal(t, 0, f.Apply(newChunk()))
+	})
+
+	t.Run("drop", func(t *testing.T) {
+		f := NewSpanFilter([]*config.SpanFilterRule{
+			{Name: "health", Action: "drop", Condition: `meta["http.url"] matches "/health" && duration < 5ms`},
+			{Name: "db", Action: "drop", Condition: `name == "db.query"`},
+		})
+		chunk := newChunk()
+		assert.Equal(t, 2, f.Apply(chunk))
+		require.Len(t, chunk.Spans, 2)
+		assert.EqualValues(t, 1, chunk.Spans[0].SpanID)
+		assert.EqualValues(t, 4, chunk.Spans[1].SpanID)
+		// the grand child is re-attached to the closest kept ancestor
+		assert.EqualValues(t, 1, chunk.Spans[1].ParentID)
+	})
+
+	t.Run("keep", func(t *testing.T) {
+		f := NewSpanFilter([]*config.SpanFilterRule{
+			{Name: "keep-db", Action: "keep", Condition: `name == "db.query"`},
+			{Name: "web", Action: "drop", Condition: `service == "web" && resource != "GET /users"`},
+		})
+		chunk := newChunk()
+		assert.Equal(t, 2, f.Apply(chunk))
+		require.Len(t, chunk.Spans, 2)
+		assert.EqualValues(t, 1, chunk.Spans[0].SpanID)
+		assert.EqualValues(t, 3, chunk.Spans[1].SpanID)
+		assert.EqualValues(t, 1, chunk.Spans[1].ParentID)
+	})
+
+	t.Run("rewrite", func(t *testing.T) {
+		f := NewSpanFilter([]*config.SpanFilterRule{
+			{Name: "redact", Action: "rewrite", Condition: `exists(meta["db.user"])`, Set: map[string]string{"db.user": "?", "resource": "SELECT ?"}, Delete: []string{"http.status_code"}},
+			{Name: "rename", Action: "rewrite", Condition: `name == "cache.get"`, Set: map[string]string{"service": "cache", "name": "redis.command", "type": "redis"}},
+			{Name: "drop-renamed", Action: "drop", Condition: `service == "cache"`},
+		})
+		chunk := newChunk()
+		assert.Equal(t, 1, f.Apply(chunk))
+		require.Len(t, chunk.Spans, 3)
+		child := chunk.Spans[2]
+		assert.Equal(t, "?", child.Meta["db.user"])
+		assert.Equal(t, "SELECT ?", child.Resource)
+		assert.NotContains(t, child.Metrics, "http.status_code")
+		assert.Contains(t, child.Metrics, "_top_level")
+	})
+
+	t.Run("all", func(t *testing.T) {
+		f := NewSpanFilter([]*config.SpanFilterRule{{Name: "all", Action: "drop"}})
+		chunk := newChunk()
+		assert.Equal(t, 4, f.Apply(chunk))
+		assert.Empty(t, chunk.Spans)
+	})
+}
+
+func TestSpanFilterUpdateRules(t *testing.T) {
+	local := []*config.SpanFilterRule{{Name: "health", Action: "drop", Condition: `resource == "GET /health"`}}
+	f := NewSpanFilter(local)
+	assert.Equal(t, 1, f.Apply(&pb.TraceChunk{Spans: []*pb.Span{newTestFilterSpan()}}))
+
+	require.NoError(t, f.UpdateRules([]*config.SpanFilterRule{{Name: "slow", Action: "drop", Condition: `duration > 1s`}}))
+	assert.Equal(t, 0, f.Apply(&pb.TraceChunk{Spans: []*pb.Span{newTestFilterSpan()}}))
+
+	err := f.UpdateRules([]*config.SpanFilterRule{{Name: "invalid", Action: "drop", Condition: `duration > "1s"`}})
+	assert.ErrorContains(t, err, `invalid span filter rule "invalid"`)
+	require.Len(t, f.getRules(), 1)
+	assert.Equal(t, "slow", f.getRules()[0].name)
+
+	require.NoError(t, f.UpdateRules(nil))
+	assert.Equal(t, 1, f.Apply(&pb.TraceChunk{Spans: []*pb.Span{newTestFilterSpan()}}))
+}
+
+func TestSpanFilterApplyV1(t *testing.T) {
+	strings := idx.NewStringTable()
+	newSpan := func(id, parent uint64, name string) *idx.InternalSpan {
+		return idx.NewInternalSpan(strings, &idx.Span{
+			SpanID:      id,
+			ParentID:    parent,
+			ServiceRef:  strings.Add("web"),
+			NameRef:     strings.Add(name),
+			ResourceRef: strings.Add("GET /health"),
+			Duration:    uint64(3 * time.Millisecond),
+			Attributes:  map[uint32]*idx.AnyValue{},
+		})
+	}
+	root := newSpan(1, 0, "http.request")
+	health := newSpan(2, 1, "http.health")
+	health.SetStringAttribute("http.url", "/health")
+	child := newSpan(3, 2, "db.query")
+	child.SetStringAttribute("db.user", "admin")
+	chunk := &idx.InternalTraceChunk{Strings: strings, Spans: []*idx.InternalSpan{root, health, child}}
+
+	f := NewSpanFilter([]*config.SpanFilterRule{
+		{Name: "health", Action: "drop", Condition: `meta["http.url"] matches "/health" && duration < 5ms`},
+		{Name: "redact", Action: "rewrite", Condition: `exists(meta["db.user"])`, Set: map[string]string{"resource": "SELECT ?"}, Delete: []string{"db.user"}},
+	})
+	assert.Equal(t, 1, f.ApplyV1(chunk))
+	require.Len(t, chunk.Spans, 2)
+	assert.EqualValues(t, 1, chunk.Spans[1].ParentID())
+	assert.Equal(t, "SELECT ?", chunk.Spans[1].Resource())
+	_, ok := chunk.Spans[1].GetAttributeAsString("db.user")
+	assert.False(t, ok)
+}
@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
 
 	"github.com/DataDog/datadog-agent/pkg/remoteconfig/state"
@@ -33,6 +34,15 @@ type rareSampler interface {
 	SetEnabled(enabled bool)
 }
 
+type spanFilter interface {
+	UpdateRules(rules []*config.SpanFilterRule) error
+}
+
+// spanFiltersConfig is the payload of the APM_SPAN_FILTERS remote configuration product.
+type spanFiltersConfig struct {
+	Rules []*config.SpanFilterRule `json:"rules"`
+}
+
 // RemoteConfigHandler holds pointers to samplers that need to be updated when APM remote config changes
 type RemoteConfigHandler struct {
 	client                        config.RemoteClient
@@ -40,6 +50,7 @@ type RemoteConfigHandler struct {
 	prioritySampler               prioritySampler
 	errorsSampler                 errorsSampler
 	rareSampler                   rareSampler
+	spanFilter                    spanFilter
 	agentConfig                   *config.AgentConfig
 	configState                   *state.AgentConfigState
 	configHTTPClient              *http.Client
@@ -47,7 +58,7 @@ type RemoteConfigHandler struct {
 }
 
 // New creates a new RemoteConfigHandler
-func New(conf *config.AgentConfig, prioritySampler prioritySampler, rareSampler rareSampler, errorsSampler errorsSampler) *RemoteConfigHandler {
+func New(conf *config.AgentConfig, prioritySampler prioritySampler, rareSampler rareSampler, errorsSampler errorsSampler, spanFilter spanFilter) *RemoteConfigHandler {
 	if conf.RemoteConfigClient == nil {
 		return nil
 	}
@@ -69,6 +80,7 @@ func New(conf *config.AgentConfig, prioritySampler prioritySampler, rareSampler
 		prioritySampler: prioritySampler,
 		rareSampler:     rareSampler,
 		errorsSampler:   errorsSampler,
+		spanFilter:      spanFilter,
 		agentConfig:     conf,
 		configState: &state.AgentConfigState{
 			FallbackLogLevel: level.String(),
@@ -93,6 +105,9 @@ func (h *RemoteConfigHandler) Start() {
 	h.client.Start()
 	h.client.Subscribe(state.ProductAPMSampling, h.onUpdate)
 	h.client.Subscribe(state.ProductAgentConfig, h.onAgentConfigUpdate)
+	if h.spanFilter != nil {
+		h.client.Subscribe(state.ProductAPMSpanFilters, h.onSpanFiltersUpdate)
+	}
 	if h.mrfClient != nil {
 		h.mrfClient.Start()
 		h.mrfClient.Subscribe(state.ProductAgentFailover, h.mrfUpdateCallback)
@@ -273,3 +288,45 @@ func (h *RemoteConfigHandler) updateSamplers(config apmsampling.SamplerConfig) {
 	}
 	h.rareSampler.SetEnabled(rareSamplerEnabled)
 }
+
+// onSpanFiltersUpdate replaces the span filter rules by the ones of all the APM_SPAN_FILTERS configs,
+// evaluated in the order of their path. Without any config, the rules from the agent configuration apply.
+func (h *RemoteConfigHandler) onSpanFiltersUpdate(updates map[string]state.RawConfig, applyStateCallback func(string, state.ApplyStatus)) {
+	paths := make([]string, 0, len(updates))
+	for path := range updates {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var (
+		rules []*config.SpanFilterRule
+		err   error
+	)
+	for _, path := range paths {
+		var payload spanFiltersConfig
+		if err = json.Unmarshal(updates[path].Config, &payload); err != nil {
+			err = fmt.Errorf("%s: %w", path, err)
+			break
+		}
+		rules = append(rules, payload.Rules...)
+	}
+	if err == nil {
+		err = h.spanFilter.UpdateRules(rules)
+	}
+	if err != nil {
+		log.Errorf("couldn't apply the span filter rules from remote configuration: %s", err)
+	} else {
+		log.Debugf("updated span filters with %d rules from remote configuration", len(rules))
+	}
+
+	for _, path := range paths {
+		if err == nil {
+			applyStateCallback(path, state.ApplyStatus{State: state.ApplyStateAcknowledged})
+		} else {
+			applyStateCallback(path, state.ApplyStatus{
+				State: state.ApplyStateError,
+				Error: err.Error(),
+			})
+		}
+	}
+}
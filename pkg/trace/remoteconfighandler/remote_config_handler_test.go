@@ -7,6 +7,7 @@ package remoteconfighandler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
@@ -33,12 +34,14 @@ func TestStart(t *testing.T) {
 	prioritySampler := NewMockprioritySampler(ctrl)
 	errorsSampler := NewMockerrorsSampler(ctrl)
 	rareSampler := NewMockrareSampler(ctrl)
+	spanFilter := NewMockspanFilter(ctrl)
 	pkglog.SetupLogger(pkglog.Default(), "debug")
 
-	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler)
+	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler, spanFilter)
 
 	remoteClient.EXPECT().Subscribe(state.ProductAPMSampling, gomock.Any()).Times(1)
 	remoteClient.EXPECT().Subscribe(state.ProductAgentConfig, gomock.Any()).Times(1)
+	remoteClient.EXPECT().Subscribe(state.ProductAPMSpanFilters, gomock.Any()).Times(1)
 	remoteClient.EXPECT().Start().Times(1)
 
 	h.Start()
@@ -49,6 +52,58 @@ func TestStartNoRemoteClient(t *testing.T) {
 	assert.NotPanics(t, h.Start)
 }
 
+func TestSpanFilters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	remoteClient := NewMockRemoteClient(ctrl)
+	spanFilter := NewMockspanFilter(ctrl)
+	pkglog.SetupLogger(pkglog.Default(), "debug")
+
+	agentConfig := config.AgentConfig{RemoteConfigClient: remoteClient, DebugServerPort: 1}
+	h := New(&agentConfig, nil, nil, nil, spanFilter)
+
+	rawRules := func(rules ...*config.SpanFilterRule) state.RawConfig {
+		raw, _ := json.Marshal(spanFiltersConfig{Rules: rules})
+		return state.RawConfig{Config: raw}
+	}
+	health := &config.SpanFilterRule{Name: "health", Action: "drop", Condition: `resource == "GET /health"`}
+	redact := &config.SpanFilterRule{Name: "redact", Action: "rewrite", Delete: []string{"user.email"}}
+
+	t.Run("ordered", func(t *testing.T) {
+		statuses := map[string]state.ApplyStatus{}
+		spanFilter.EXPECT().UpdateRules([]*config.SpanFilterRule{health, redact}).Return(nil).Times(1)
+		h.onSpanFiltersUpdate(map[string]state.RawConfig{
+			"datadog/2/APM_SPAN_FILTERS/b/config": rawRules(redact),
+			"datadog/2/APM_SPAN_FILTERS/a/config": rawRules(health),
+		}, func(path string, status state.ApplyStatus) { statuses[path] = status })
+		assert.Equal(t, map[string]state.ApplyStatus{
+			"datadog/2/APM_SPAN_FILTERS/a/config": {State: state.ApplyStateAcknowledged},
+			"datadog/2/APM_SPAN_FILTERS/b/config": {State: state.ApplyStateAcknowledged},
+		}, statuses)
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		spanFilter.EXPECT().UpdateRules(nil).Return(nil).Times(1)
+		h.onSpanFiltersUpdate(map[string]state.RawConfig{}, applyEmpty)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		statuses := map[string]state.ApplyStatus{}
+		spanFilter.EXPECT().UpdateRules(gomock.Any()).Return(errors.New("invalid span filter rule")).Times(1)
+		h.onSpanFiltersUpdate(map[string]state.RawConfig{
+			"datadog/2/APM_SPAN_FILTERS/a/config": rawRules(health),
+		}, func(path string, status state.ApplyStatus) { statuses[path] = status })
+		assert.Equal(t, state.ApplyStateError, statuses["datadog/2/APM_SPAN_FILTERS/a/config"].State)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		statuses := map[string]state.ApplyStatus{}
+		h.onSpanFiltersUpdate(map[string]state.RawConfig{
+			"datadog/2/APM_SPAN_FILTERS/a/config": {Config: []byte("{")},
+		}, func(path string, status state.ApplyStatus) { statuses[path] = status })
+		assert.Equal(t, state.ApplyStateError, statuses["datadog/2/APM_SPAN_FILTERS/a/config"].State)
+	})
+}
+
 func TestPrioritySampler(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	remoteClient := NewMockRemoteClient(ctrl)
@@ -58,7 +113,7 @@ func TestPrioritySampler(t *testing.T) {
 	pkglog.SetupLogger(pkglog.Default(), "debug")
 
 	agentConfig := config.AgentConfig{RemoteConfigClient: remoteClient, TargetTPS: 41, ErrorTPS: 41, RareSamplerEnabled: true, DebugServerPort: 1}
-	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler)
+	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler, nil)
 
 	payload := apmsampling.SamplerConfig{
 		AllEnvs: apmsampling.SamplerEnvConfig{
@@ -87,7 +142,7 @@ func TestErrorsSampler(t *testing.T) {
 	pkglog.SetupLogger(pkglog.Default(), "debug")
 
 	agentConfig := config.AgentConfig{RemoteConfigClient: remoteClient, TargetTPS: 41, ErrorTPS: 41, RareSamplerEnabled: true, DebugServerPort: 1}
-	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler)
+	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler, nil)
 
 	payload := apmsampling.SamplerConfig{
 		AllEnvs: apmsampling.SamplerEnvConfig{
@@ -116,7 +171,7 @@ func TestRareSampler(t *testing.T) {
 	pkglog.SetupLogger(pkglog.Default(), "debug")
 
 	agentConfig := config.AgentConfig{RemoteConfigClient: remoteClient, TargetTPS: 41, ErrorTPS: 41, RareSamplerEnabled: true, DebugServerPort: 1}
-	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler)
+	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler, nil)
 
 	payload := apmsampling.SamplerConfig{
 		AllEnvs: apmsampling.SamplerEnvConfig{
@@ -145,7 +200,7 @@ func TestEnvPrecedence(t *testing.T) {
 	pkglog.SetupLogger(pkglog.Default(), "debug")
 
 	agentConfig := config.AgentConfig{RemoteConfigClient: remoteClient, TargetTPS: 41, ErrorTPS: 41, RareSamplerEnabled: true, DefaultEnv: "agent-env", DebugServerPort: 1}
-	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler)
+	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler, nil)
 
 	payload := apmsampling.SamplerConfig{
 		AllEnvs: apmsampling.SamplerEnvConfig{
@@ -196,7 +251,7 @@ func TestLogLevel(t *testing.T) {
 		DebugServerPort:    port,
 		AuthToken:          "fakeToken",
 	}
-	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler)
+	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler, nil)
 
 	layer := state.RawConfig{Config: []byte(`{"name": "layer1", "config": {"log_level": "debug"}}`)}
 	configOrder := state.RawConfig{Config: []byte(`{"internal_order": ["layer1", "layer2"]}`)}
@@ -230,7 +285,7 @@ func TestStartWithMRF(t *testing.T) {
 	rareSampler := NewMockrareSampler(ctrl)
 	pkglog.SetupLogger(pkglog.Default(), "debug")
 
-	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler)
+	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler, nil)
 
 	remoteClient.EXPECT().Subscribe(state.ProductAPMSampling, gomock.Any()).Times(1)
 	remoteClient.EXPECT().Subscribe(state.ProductAgentConfig, gomock.Any()).Times(1)
@@ -255,7 +310,7 @@ func TestMRFUpdateCallback(t *testing.T) {
 		MRFRemoteConfigClient: mrfClient,
 		DebugServerPort:       1,
 	}
-	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler)
+	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler, nil)
 
 	// Disabled by default
 	assert.False(t, h.agentConfig.MRFFailoverAPM())
@@ -312,7 +367,7 @@ func TestMRFUpdateCallbackWithMultipleConfigs(t *testing.T) {
 		MRFRemoteConfigClient: mrfClient,
 		DebugServerPort:       1,
 	}
-	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler)
+	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler, nil)
 
 	// Test with multiple configs, first one should take precedence
 	enableAPM1 := true
@@ -0,0 +1,550 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sampler
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"go.uber.org/atomic"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace/idx"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/log"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
+	"github.com/DataDog/datadog-go/v5/statsd"
+)
+
+const (
+	// tailPolicyKey is the meta key set on the root span of traces kept by a tail sampling policy.
+	tailPolicyKey = "_dd.tail_sampling.policy"
+	// tailMaxTickInterval is the maximum delay between two checks for expired traces.
+	tailMaxTickInterval = time.Second
+
+	// MetricsTailTraces is the metric name for the number of traces buffered by the tail sampler.
+	MetricsTailTraces = "datadog.trace_agent.sampler.tail.traces"
+	// MetricsTailBytes is the metric name for the estimated size of the spans buffered by the tail sampler.
+	MetricsTailBytes = "datadog.trace_agent.sampler.tail.bytes"
+	// MetricsTailKept is the metric name for the number of traces kept by the tail sampler.
+	MetricsTailKept = "datadog.trace_agent.sampler.tail.kept"
+	// MetricsTailDropped is the metric name for the number of traces dropped by the tail sampler.
+	MetricsTailDropped = "datadog.trace_agent.sampler.tail.dropped"
+	// MetricsTailEvicted is the metric name for the number of traces decided before the end of their
+	// decision window because the tail sampler buffer was full.
+	MetricsTailEvicted = "datadog.trace_agent.sampler.tail.evicted"
+)
+
+// TailChunk is a trace chunk dropped by the head samplers and buffered by the TailSampler,
+// which may still keep it once its trace is complete. Either Chunk or ChunkV1 is set, depending
+// on the version of the tracer payload it was received in.
+type TailChunk struct {
+	// Payload holds the metadata of the v0.x tracer payload the chunk was received in. Its Chunks are not set.
+	Payload *pb.TracerPayload
+	// Chunk is the buffered v0.x trace chunk, with all its spans.
+	Chunk *pb.TraceChunk
+	// PayloadV1 holds the metadata of the V1 tracer payload the chunk was received in, along with
+	// the string table of the chunk. Its Chunks are not set.
+	PayloadV1 *idx.InternalTracerPayload
+	// ChunkV1 is the buffered V1 trace chunk, with all its spans.
+	ChunkV1 *idx.InternalTraceChunk
+	// Env is the environment resolved for the chunk.
+	Env string
+
+	// Dropped and DroppedV1 hold what the head samplers kept of the chunk, i.e. single sampled
+	// spans or analytics events, to be sent if the trace is dropped. They are nil if nothing was kept.
+	Dropped   *pb.TraceChunk
+	DroppedV1 *idx.InternalTraceChunk
+	// Events is the number of analytics events extracted from the chunk.
+	Events int
+}
+
+func (c *TailChunk) traceID() uint64 {
+	if c.ChunkV1 != nil {
+		return c.ChunkV1.LegacyTraceID()
+	}
+	return c.Chunk.Spans[0].TraceID
+}
+
+// size returns the estimated size of the chunk, including the string table of its V1 payload,
+// which is cloned for each buffered chunk.
+func (c *TailChunk) size() int64 {
+	if c.ChunkV1 != nil {
+		size := c.ChunkV1.Msgsize()
+		if c.PayloadV1 != nil && c.PayloadV1.Strings != nil {
+			size += c.PayloadV1.Strings.Msgsize()
+		}
+		return int64(size)
+	}
+	return int64(c.Chunk.Msgsize())
+}
+
+func (c *TailChunk) priority() (SamplingPriority, bool) {
+	if c.ChunkV1 != nil {
+		return GetSamplingPriorityV1(c.ChunkV1)
+	}
+	return GetSamplingPriority(c.Chunk)
+}
+
+// setDecision marks the chunk as kept or dropped, without overriding the priority set by users.
+func (c *TailChunk) setDecision(keep bool) {
+	priority := int32(PriorityAutoDrop)
+	if keep {
+		priority = int32(PriorityAutoKeep)
+	}
+	userPriority := false
+	if p, ok := c.priority(); ok && (p == PriorityUserKeep || p == PriorityUserDrop) {
+		userPriority = true
+	}
+	if c.ChunkV1 != nil {
+		c.ChunkV1.DroppedTrace = !keep
+		if !userPriority {
+			c.ChunkV1.Priority = priority
+		}
+		return
+	}
+	c.Chunk.DroppedTrace = !keep
+	if !userPriority {
+		c.Chunk.Priority = priority
+	}
+}
+
+func (c *TailChunk) spans() []tailSpan {
+	if c.ChunkV1 != nil {
+		spans := make([]tailSpan, len(c.ChunkV1.Spans))
+		for i, s := range c.ChunkV1.Spans {
+			spans[i] = tailSpanV1{s}
+		}
+		return spans
+	}
+	spans := make([]tailSpan, len(c.Chunk.Spans))
+	for i, s := range c.Chunk.Spans {
+		spans[i] = tailSpanV0{s}
+	}
+	return spans
+}
+
+// tailSpan is the view of a v0.x or V1 span used by the tail sampling policies.
+type tailSpan interface {
+	service() string
+	spanID() uint64
+	parentID() uint64
+	isError() bool
+	duration() int64
+	// attribute returns the value of the meta or the metric key.
+	attribute(key string) (value string, isMetric bool, ok bool)
+	setMeta(key, value string)
+}
+
+type tailSpanV0 struct{ s *pb.Span }
+
+func (s tailSpanV0) service() string  { return s.s.Service }
+func (s tailSpanV0) spanID() uint64   { return s.s.SpanID }
+func (s tailSpanV0) parentID() uint64 { return s.s.ParentID }
+func (s tailSpanV0) isError() bool    { return s.s.Error != 0 }
+func (s tailSpanV0) duration() int64  { return s.s.Duration }
+func (s tailSpanV0) attribute(key string) (string, bool, bool) {
+	if v, ok := s.s.Meta[key]; ok {
+		return v, false, true
+	}
+	if _, ok := s.s.Metrics[key]; ok {
+		return "", true, true
+	}
+	return "", false, false
+}
+func (s tailSpanV0) setMeta(key, value string) { traceutil.SetMeta(s.s, key, value) }
+
+type tailSpanV1 struct{ s *idx.InternalSpan }
+
+func (s tailSpanV1) service() string  { return s.s.Service() }
+func (s tailSpanV1) spanID() uint64   { return s.s.SpanID() }
+func (s tailSpanV1) parentID() uint64 { return s.s.ParentID() }
+func (s tailSpanV1) isError() bool    { return s.s.Error() }
+func (s tailSpanV1) duration() int64  { return int64(s.s.Duration()) }
+func (s tailSpanV1) attribute(key string) (string, bool, bool) {
+	if _, ok := s.s.GetAttributeAsFloat64(key); ok {
+		return "", true, true
+	}
+	v, ok := s.s.GetAttributeAsString(key)
+	return v, false, ok
+}
+func (s tailSpanV1) setMeta(key, value string) { s.s.SetStringAttribute(key, value) }
+
+// TailTrace is a trace on which the TailSampler took a decision.
+type TailTrace struct {
+	TraceID uint64
+	Chunks  []TailChunk
+	// Service is the service of the root span of the trace.
+	Service string
+	Env     string
+	// Keep reports whether the trace was kept.
+	Keep bool
+	// Policy is the name of the policy that kept the trace, if any.
+	Policy string
+}
+
+// tailTrace is a trace buffered by the TailSampler until its decision deadline.
+type tailTrace struct {
+	id       uint64
+	deadline time.Time
+	chunks   []TailChunk
+	size     int64
+	// headKept reports whether a chunk of the trace was kept by the head samplers.
+	headKept bool
+}
+
+// headKeptTrace records that a chunk of a trace was kept by the head samplers, until the end
+// of its decision window.
+type headKeptTrace struct {
+	id       uint64
+	deadline time.Time
+}
+
+// tailPolicy is a compiled config.TailSamplingPolicy.
+type tailPolicy struct {
+	name    string
+	service string
+	match   func(traceID uint64, root tailSpan, spans []tailSpan) bool
+}
+
+// TailSampler buffers the chunks dropped by the head samplers by trace ID for a bounded window
+// and memory budget, then decides to keep or drop each trace as a whole by running its policies
+// on all the spans received for it. Decided traces are passed to the flush function.
+type TailSampler struct {
+	enabled      bool
+	decisionWait time.Duration
+	maxTraces    int
+	maxBytes     int64
+	policies     []tailPolicy
+	flush        func(*TailTrace)
+
+	mu     sync.Mutex
+	traces map[uint64]*list.Element
+	order  *list.List // *tailTrace, ordered by deadline
+	size   int64
+	// headKept and headKeptOrder hold the traces kept by the head samplers. They are bounded
+	// separately so that they don't push the buffered traces out.
+	headKept      map[uint64]*list.Element
+	headKeptOrder *list.List // *headKeptTrace, ordered by deadline
+
+	kept    *atomic.Int64
+	dropped *atomic.Int64
+	evicted *atomic.Int64
+
+	started *atomic.Bool
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewTailSampler returns a TailSampler configured from conf. flush is called with every decided
+// trace, kept or not, and must be safe for concurrent use.
+func NewTailSampler(conf *config.AgentConfig, flush func(*TailTrace)) *TailSampler {
+	tconf := conf.TailSampling
+	if tconf == nil {
+		tconf = &config.TailSamplingConfig{}
+	}
+	return &TailSampler{
+		enabled:       tconf.Enabled,
+		decisionWait:  tconf.DecisionWait,
+		maxTraces:     tconf.MaxTraces,
+		maxBytes:      tconf.MaxMemoryBytes,
+		policies:      newTailPolicies(tconf.Policies),
+		flush:         flush,
+		traces:        make(map[uint64]*list.Element),
+		order:         list.New(),
+		headKept:      make(map[uint64]*list.Element),
+		headKeptOrder: list.New(),
+		kept:          atomic.NewInt64(0),
+		dropped:       atomic.NewInt64(0),
+		evicted:       atomic.NewInt64(0),
+		started:       atomic.NewBool(false),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+}
+
+func newTailPolicies(confs []*config.TailSamplingPolicy) []tailPolicy {
+	policies := make([]tailPolicy, 0, len(confs))
+	for _, c := range confs {
+		if c == nil {
+			continue
+		}
+		if err := c.Validate(); err != nil {
+			log.Warnf("Ignoring tail sampling policy: %v", err)
+			continue
+		}
+		p := tailPolicy{name: c.Name, service: c.Service}
+		if p.name == "" {
+			p.name = c.Type
+		}
+		switch c.Type {
+		case config.TailSamplingPolicyError:
+			p.match = func(_ uint64, _ tailSpan, spans []tailSpan) bool {
+				for _, s := range spans {
+					if s.isError() {
+						return true
+					}
+				}
+				return false
+			}
+		case config.TailSamplingPolicyLatency:
+			threshold := (time.Duration(c.ThresholdMs) * time.Millisecond).Nanoseconds()
+			p.match = func(_ uint64, root tailSpan, _ []tailSpan) bool {
+				return root.duration() >= threshold
+			}
+		case config.TailSamplingPolicyAttribute:
+			key, value := c.Key, c.Value
+			p.match = func(_ uint64, _ tailSpan, spans []tailSpan) bool {
+				for _, s := range spans {
+					v, isMetric, ok := s.attribute(key)
+					if ok && (value == "" || (!isMetric && v == value)) {
+						return true
+					}
+				}
+				return false
+			}
+		case config.TailSamplingPolicyRate:
+			rate := c.Rate
+			p.match = func(traceID uint64, _ tailSpan, _ []tailSpan) bool {
+				return rate > 0 && SampleByRate(traceID, rate)
+			}
+		}
+		policies = append(policies, p)
+	}
+	return policies
+}
+
+// IsEnabled returns whether the sampler is enabled.
+func (s *TailSampler) IsEnabled() bool {
+	return s != nil && s.enabled
+}
+
+// Start starts the routine deciding on traces once their decision window is over.
+func (s *TailSampler) Start() {
+	if !s.enabled || s.started.Swap(true) {
+		return
+	}
+	interval := min(s.decisionWait/4, tailMaxTickInterval)
+	if interval <= 0 {
+		interval = tailMaxTickInterval
+	}
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				s.flushExpired(now)
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the sampler and decides on all buffered traces with the spans received so far.
+func (s *TailSampler) Stop() {
+	if !s.started.Swap(false) {
+		return
+	}
+	close(s.stop)
+	<-s.done
+	s.mu.Lock()
+	pending := s.popLocked(s.order.Len())
+	s.headKept = make(map[uint64]*list.Element)
+	s.headKeptOrder.Init()
+	s.mu.Unlock()
+	s.decideAll(pending)
+}
+
+// Add buffers a chunk dropped by the head samplers until the decision window of its trace is
+// over. If the buffer exceeds its limits, the oldest traces are decided right away.
+func (s *TailSampler) Add(now time.Time, chunk TailChunk) {
+	if chunk.Chunk == nil && chunk.ChunkV1 == nil {
+		return
+	}
+	if (chunk.Chunk != nil && len(chunk.Chunk.Spans) == 0) || (chunk.ChunkV1 != nil && len(chunk.ChunkV1.Spans) == 0) {
+		return
+	}
+	size := chunk.size()
+	s.mu.Lock()
+	t := s.getLocked(now, chunk.traceID())
+	t.chunks = append(t.chunks, chunk)
+	t.size += size
+	s.size += size
+	evicted := s.evictLocked()
+	s.mu.Unlock()
+
+	s.evicted.Add(int64(len(evicted)))
+	s.decideAll(evicted)
+}
+
+// MarkKept records that a chunk of the trace traceID was kept by the head samplers, so that
+// the chunks of this trace which are buffered or received during its decision window are kept
+// too, and the trace isn't sent partially.
+func (s *TailSampler) MarkKept(now time.Time, traceID uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.traces[traceID]; ok {
+		el.Value.(*tailTrace).headKept = true
+	}
+	if _, ok := s.headKept[traceID]; ok {
+		return
+	}
+	s.headKept[traceID] = s.headKeptOrder.PushBack(&headKeptTrace{id: traceID, deadline: now.Add(s.decisionWait)})
+	// Forgetting the oldest kept traces only risks sending them partially.
+	for s.maxTraces > 0 && s.headKeptOrder.Len() > s.maxTraces {
+		s.popHeadKeptLocked()
+	}
+}
+
+// getLocked returns the buffered trace id, adding it if needed. s.mu must be held.
+func (s *TailSampler) getLocked(now time.Time, id uint64) *tailTrace {
+	if el, ok := s.traces[id]; ok {
+		return el.Value.(*tailTrace)
+	}
+	_, headKept := s.headKept[id]
+	t := &tailTrace{id: id, deadline: now.Add(s.decisionWait), headKept: headKept}
+	s.traces[id] = s.order.PushBack(t)
+	return t
+}
+
+// popHeadKeptLocked forgets the oldest trace kept by the head samplers. s.mu must be held.
+func (s *TailSampler) popHeadKeptLocked() {
+	t := s.headKeptOrder.Remove(s.headKeptOrder.Front()).(*headKeptTrace)
+	delete(s.headKept, t.id)
+}
+
+// evictLocked removes the oldest traces until the buffer is within its limits, and returns
+// them. s.mu must be held.
+func (s *TailSampler) evictLocked() []*tailTrace {
+	var evicted []*tailTrace
+	for s.order.Len() > 1 && ((s.maxTraces > 0 && s.order.Len() > s.maxTraces) || (s.maxBytes > 0 && s.size > s.maxBytes)) {
+		evicted = append(evicted, s.popLocked(1)...)
+	}
+	return evicted
+}
+
+// flushExpired decides on all traces whose decision window is over at now.
+func (s *TailSampler) flushExpired(now time.Time) {
+	s.mu.Lock()
+	n := 0
+	for el := s.order.Front(); el != nil && !el.Value.(*tailTrace).deadline.After(now); el = el.Next() {
+		n++
+	}
+	expired := s.popLocked(n)
+	for el := s.headKeptOrder.Front(); el != nil && !el.Value.(*headKeptTrace).deadline.After(now); el = s.headKeptOrder.Front() {
+		s.popHeadKeptLocked()
+	}
+	s.mu.Unlock()
+	s.decideAll(expired)
+}
+
+// popLocked removes the n oldest traces from the buffer. s.mu must be held.
+func (s *TailSampler) popLocked(n int) []*tailTrace {
+	popped := make([]*tailTrace, 0, n)
+	for i := 0; i < n; i++ {
+		el := s.order.Front()
+		if el == nil {
+			break
+		}
+		t := s.order.Remove(el).(*tailTrace)
+		delete(s.traces, t.id)
+		s.size -= t.size
+		popped = append(popped, t)
+	}
+	return popped
+}
+
+func (s *TailSampler) decideAll(traces []*tailTrace) {
+	for _, t := range traces {
+		s.flush(s.decide(t))
+	}
+}
+
+// decide runs the sampler policies on the complete trace t. The decisions of users are
+// always respected, and traces partially kept by the head samplers are kept entirely.
+func (s *TailSampler) decide(t *tailTrace) *TailTrace {
+	var spans []tailSpan
+	for _, c := range t.chunks {
+		spans = append(spans, c.spans()...)
+	}
+	root := tailRoot(spans)
+	tt := &TailTrace{
+		TraceID: t.id,
+		Chunks:  t.chunks,
+		Service: root.service(),
+		Env:     t.chunks[0].Env,
+	}
+	userKeep, userDrop := false, false
+	for _, c := range t.chunks {
+		if priority, ok := c.priority(); ok {
+			userKeep = userKeep || priority == PriorityUserKeep
+			userDrop = userDrop || priority == PriorityUserDrop
+		}
+	}
+	switch {
+	case userDrop:
+	case userKeep, t.headKept:
+		tt.Keep = true
+	default:
+		for _, p := range s.policies {
+			if p.service != "" && p.service != tt.Service {
+				continue
+			}
+			if p.match(t.id, root, spans) {
+				tt.Keep, tt.Policy = true, p.name
+				root.setMeta(tailPolicyKey, p.name)
+				break
+			}
+		}
+	}
+	for i := range t.chunks {
+		t.chunks[i].setDecision(tt.Keep)
+	}
+	if tt.Keep {
+		s.kept.Inc()
+	} else {
+		s.dropped.Inc()
+	}
+	return tt
+}
+
+// tailRoot returns the root span of a trace received in several chunks, like traceutil.GetRoot.
+func tailRoot(spans []tailSpan) tailSpan {
+	ids := make(map[uint64]struct{}, len(spans))
+	for _, s := range spans {
+		ids[s.spanID()] = struct{}{}
+	}
+	for i := len(spans) - 1; i >= 0; i-- {
+		if spans[i].parentID() == 0 {
+			return spans[i]
+		}
+	}
+	for i := len(spans) - 1; i >= 0; i-- {
+		if _, ok := ids[spans[i].parentID()]; !ok {
+			return spans[i]
+		}
+	}
+	return spans[len(spans)-1]
+}
+
+func (s *TailSampler) report(statsd statsd.ClientInterface) {
+	if !s.enabled {
+		return
+	}
+	s.mu.Lock()
+	traces, size := s.order.Len(), s.size
+	s.mu.Unlock()
+	_ = statsd.Gauge(MetricsTailTraces, float64(traces), nil, 1)
+	_ = statsd.Gauge(MetricsTailBytes, float64(size), nil, 1)
+	_ = statsd.Count(MetricsTailKept, s.kept.Swap(0), nil, 1)
+	_ = statsd.Count(MetricsTailDropped, s.dropped.Swap(0), nil, 1)
+	_ = statsd.Count(MetricsTailEvicted, s.evicted.Swap(0), nil, 1)
+}
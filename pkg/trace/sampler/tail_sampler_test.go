@@ -0,0 +1,298 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sampler
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace/idx"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+)
+
+type tailRecorder struct {
+	mu      sync.Mutex
+	decided []*TailTrace
+}
+
+func (r *tailRecorder) flush(t *TailTrace) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.decided = append(r.decided, t)
+}
+
+func newTestTailSampler(policies ...*config.TailSamplingPolicy) (*TailSampler, *tailRecorder) {
+	conf := config.New()
+	conf.TailSampling.Enabled = true
+	conf.TailSampling.Policies = policies
+	r := &tailRecorder{}
+	return NewTailSampler(conf, r.flush), r
+}
+
+func tailChunk(spans ...*pb.Span) TailChunk {
+	return TailChunk{
+		Payload: &pb.TracerPayload{},
+		Chunk:   getTraceChunkWithSpansAndPriority(spans, PriorityAutoKeep),
+		Env:     "prod",
+	}
+}
+
+func TestTailSamplerWaitsForCompleteTrace(t *testing.T) {
+	s, r := newTestTailSampler(&config.TailSamplingPolicy{Name: "errors", Type: "error"})
+	now := time.Now()
+
+	// A first chunk without errors, then the root span with an error in a later payload.
+	s.Add(now, tailChunk(&pb.Span{TraceID: 1, SpanID: 2, ParentID: 1, Service: "db"}))
+	s.flushExpired(now.Add(s.decisionWait / 2))
+	assert.Empty(t, r.decided)
+	s.Add(now.Add(time.Second), tailChunk(&pb.Span{TraceID: 1, SpanID: 1, Service: "web", Error: 1}))
+
+	s.flushExpired(now.Add(s.decisionWait))
+	require.Len(t, r.decided, 1)
+	tt := r.decided[0]
+	assert.True(t, tt.Keep)
+	assert.Equal(t, "errors", tt.Policy)
+	assert.Equal(t, "web", tt.Service)
+	require.Len(t, tt.Chunks, 2)
+	assert.Equal(t, "errors", tt.Chunks[1].Chunk.Spans[0].Meta[tailPolicyKey])
+	assert.NotContains(t, tt.Chunks[0].Chunk.Spans[0].Meta, tailPolicyKey)
+	for _, c := range tt.Chunks {
+		assert.False(t, c.Chunk.DroppedTrace)
+	}
+}
+
+func TestTailSamplerPolicies(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		policy *config.TailSamplingPolicy
+		spans  []*pb.Span
+		keep   bool
+	}{
+		{
+			name:   "no-error",
+			policy: &config.TailSamplingPolicy{Type: "error"},
+			spans:  []*pb.Span{{TraceID: 1, SpanID: 1}, {TraceID: 1, SpanID: 2, ParentID: 1}},
+		},
+		{
+			name:   "child-error",
+			policy: &config.TailSamplingPolicy{Type: "error"},
+			spans:  []*pb.Span{{TraceID: 1, SpanID: 1}, {TraceID: 1, SpanID: 2, ParentID: 1, Error: 1}},
+			keep:   true,
+		},
+		{
+			name:   "latency-below",
+			policy: &config.TailSamplingPolicy{Type: "latency", ThresholdMs: 100},
+			spans:  []*pb.Span{{TraceID: 1, SpanID: 1, Duration: int64(99 * time.Millisecond)}},
+		},
+		{
+			name:   "latency-above",
+			policy: &config.TailSamplingPolicy{Type: "latency", ThresholdMs: 100},
+			spans:  []*pb.Span{{TraceID: 1, SpanID: 1, Duration: int64(100 * time.Millisecond)}},
+			keep:   true,
+		},
+		{
+			name:   "attribute-meta",
+			policy: &config.TailSamplingPolicy{Type: "attribute", Key: "customer.tier", Value: "gold"},
+			spans:  []*pb.Span{{TraceID: 1, SpanID: 1}, {TraceID: 1, SpanID: 2, ParentID: 1, Meta: map[string]string{"customer.tier": "gold"}}},
+			keep:   true,
+		},
+		{
+			name:   "attribute-meta-other-value",
+			policy: &config.TailSamplingPolicy{Type: "attribute", Key: "customer.tier", Value: "gold"},
+			spans:  []*pb.Span{{TraceID: 1, SpanID: 1, Meta: map[string]string{"customer.tier": "free"}}},
+		},
+		{
+			name:   "attribute-metric",
+			policy: &config.TailSamplingPolicy{Type: "attribute", Key: "retries"},
+			spans:  []*pb.Span{{TraceID: 1, SpanID: 1, Metrics: map[string]float64{"retries": 3}}},
+			keep:   true,
+		},
+		{
+			name:   "rate-one",
+			policy: &config.TailSamplingPolicy{Type: "rate", Rate: 1},
+			spans:  []*pb.Span{{TraceID: 1, SpanID: 1, Service: "web"}},
+			keep:   true,
+		},
+		{
+			name:   "rate-zero",
+			policy: &config.TailSamplingPolicy{Type: "rate", Rate: 0},
+			spans:  []*pb.Span{{TraceID: 1, SpanID: 1, Service: "web"}},
+		},
+		{
+			name:   "other-service",
+			policy: &config.TailSamplingPolicy{Type: "rate", Rate: 1, Service: "api"},
+			spans:  []*pb.Span{{TraceID: 1, SpanID: 1, Service: "web"}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, r := newTestTailSampler(tc.policy)
+			now := time.Now()
+			s.Add(now, tailChunk(tc.spans...))
+			s.flushExpired(now.Add(s.decisionWait))
+			require.Len(t, r.decided, 1)
+			assert.Equal(t, tc.keep, r.decided[0].Keep)
+			assert.Equal(t, !tc.keep, r.decided[0].Chunks[0].Chunk.DroppedTrace)
+		})
+	}
+}
+
+func TestTailSamplerUserDecisions(t *testing.T) {
+	t.Run("keep", func(t *testing.T) {
+		s, r := newTestTailSampler()
+		now := time.Now()
+		c := tailChunk(&pb.Span{TraceID: 1, SpanID: 1})
+		c.Chunk.Priority = int32(PriorityUserKeep)
+		s.Add(now, c)
+		s.flushExpired(now.Add(s.decisionWait))
+		require.Len(t, r.decided, 1)
+		assert.True(t, r.decided[0].Keep)
+		assert.Equal(t, int32(PriorityUserKeep), r.decided[0].Chunks[0].Chunk.Priority)
+	})
+
+	t.Run("drop", func(t *testing.T) {
+		s, r := newTestTailSampler(&config.TailSamplingPolicy{Type: "error"})
+		now := time.Now()
+		c := tailChunk(&pb.Span{TraceID: 1, SpanID: 1, Error: 1})
+		c.Chunk.Priority = int32(PriorityUserDrop)
+		s.Add(now, c)
+		s.flushExpired(now.Add(s.decisionWait))
+		require.Len(t, r.decided, 1)
+		assert.False(t, r.decided[0].Keep)
+		assert.Equal(t, int32(PriorityUserDrop), r.decided[0].Chunks[0].Chunk.Priority)
+		assert.True(t, r.decided[0].Chunks[0].Chunk.DroppedTrace)
+	})
+}
+
+func TestTailSamplerMarkKept(t *testing.T) {
+	s, r := newTestTailSampler()
+	now := time.Now()
+
+	// the chunks of a trace partially kept by the head samplers are kept, before and after
+	s.Add(now, tailChunk(&pb.Span{TraceID: 1, SpanID: 2, ParentID: 1}))
+	s.MarkKept(now, 1)
+	s.Add(now, tailChunk(&pb.Span{TraceID: 1, SpanID: 3, ParentID: 1}))
+	// traces entirely kept by the head samplers aren't flushed
+	s.MarkKept(now, 2)
+	s.Add(now, tailChunk(&pb.Span{TraceID: 3, SpanID: 4}))
+
+	s.flushExpired(now.Add(s.decisionWait))
+	require.Len(t, r.decided, 2)
+	assert.Equal(t, uint64(1), r.decided[0].TraceID)
+	assert.True(t, r.decided[0].Keep)
+	assert.Len(t, r.decided[0].Chunks, 2)
+	assert.Equal(t, uint64(3), r.decided[1].TraceID)
+	assert.False(t, r.decided[1].Keep)
+	assert.Zero(t, s.order.Len())
+	assert.Zero(t, s.headKeptOrder.Len())
+}
+
+func TestTailSamplerV1(t *testing.T) {
+	s, r := newTestTailSampler(&config.TailSamplingPolicy{Type: "attribute", Key: "customer.tier", Value: "gold"})
+	now := time.Now()
+
+	strings := idx.NewStringTable()
+	root := idx.NewInternalSpan(strings, &idx.Span{ServiceRef: strings.Add("web"), SpanID: 1})
+	child := idx.NewInternalSpan(strings, &idx.Span{ServiceRef: strings.Add("db"), SpanID: 2, ParentID: 1})
+	child.SetStringAttribute("customer.tier", "gold")
+	traceID := make([]byte, 16)
+	traceID[15] = 1
+	chunk := &idx.InternalTraceChunk{Strings: strings, Spans: []*idx.InternalSpan{root, child}, TraceID: traceID}
+	s.Add(now, TailChunk{ChunkV1: chunk, Env: "prod"})
+
+	s.flushExpired(now.Add(s.decisionWait))
+	require.Len(t, r.decided, 1)
+	tt := r.decided[0]
+	assert.Equal(t, uint64(1), tt.TraceID)
+	assert.True(t, tt.Keep)
+	assert.Equal(t, "web", tt.Service)
+	assert.False(t, chunk.DroppedTrace)
+	assert.Equal(t, int32(PriorityAutoKeep), chunk.Priority)
+	policy, _ := root.GetAttributeAsString(tailPolicyKey)
+	assert.Equal(t, "attribute", policy)
+}
+
+func TestNewTailPoliciesSkipsInvalid(t *testing.T) {
+	policies := newTailPolicies([]*config.TailSamplingPolicy{
+		{Type: "rate", Rate: 2},
+		{Type: "latency"},
+		{Type: "unknown"},
+		{Name: "errors", Type: "error"},
+	})
+	require.Len(t, policies, 1)
+	assert.Equal(t, "errors", policies[0].name)
+}
+
+func TestTailSamplerEviction(t *testing.T) {
+	t.Run("max-traces", func(t *testing.T) {
+		s, r := newTestTailSampler()
+		s.maxTraces = 2
+		now := time.Now()
+		for i := uint64(1); i <= 3; i++ {
+			s.Add(now, tailChunk(&pb.Span{TraceID: i, SpanID: i}))
+		}
+		require.Len(t, r.decided, 1)
+		assert.Equal(t, uint64(1), r.decided[0].TraceID)
+		assert.EqualValues(t, 1, s.evicted.Load())
+		assert.Equal(t, 2, s.order.Len())
+	})
+
+	t.Run("head-kept", func(t *testing.T) {
+		s, r := newTestTailSampler()
+		s.maxTraces = 2
+		now := time.Now()
+		s.Add(now, tailChunk(&pb.Span{TraceID: 1, SpanID: 1}))
+		// the traces kept by the head samplers don't push the buffered traces out
+		for i := uint64(10); i < 20; i++ {
+			s.MarkKept(now, i)
+		}
+		s.Add(now, tailChunk(&pb.Span{TraceID: 2, SpanID: 2}))
+		assert.Empty(t, r.decided)
+		assert.Equal(t, 2, s.order.Len())
+		// only the most recent ones are remembered
+		assert.Equal(t, 2, s.headKeptOrder.Len())
+		s.Add(now, tailChunk(&pb.Span{TraceID: 19, SpanID: 19}))
+		s.flushExpired(now.Add(s.decisionWait))
+		require.Len(t, r.decided, 3)
+		assert.True(t, r.decided[2].Keep)
+	})
+
+	t.Run("v1-string-table", func(t *testing.T) {
+		s, _ := newTestTailSampler()
+		strings := idx.NewStringTable()
+		span := idx.NewInternalSpan(strings, &idx.Span{ServiceRef: strings.Add("web"), SpanID: 1})
+		chunk := &idx.InternalTraceChunk{Strings: strings, Spans: []*idx.InternalSpan{span}, TraceID: make([]byte, 16)}
+		s.Add(time.Now(), TailChunk{ChunkV1: chunk, PayloadV1: &idx.InternalTracerPayload{Strings: strings}})
+		assert.Equal(t, int64(chunk.Msgsize()+strings.Msgsize()), s.size)
+	})
+
+	t.Run("max-memory", func(t *testing.T) {
+		s, r := newTestTailSampler()
+		c := tailChunk(&pb.Span{TraceID: 1, SpanID: 1})
+		s.maxBytes = int64(c.Chunk.Msgsize()) + 1
+		now := time.Now()
+		s.Add(now, c)
+		s.Add(now, tailChunk(&pb.Span{TraceID: 2, SpanID: 2}))
+		require.Len(t, r.decided, 1)
+		assert.Equal(t, uint64(1), r.decided[0].TraceID)
+		assert.Equal(t, int64(c.Chunk.Msgsize()), s.size)
+	})
+}
+
+func TestTailSamplerStopFlushesPending(t *testing.T) {
+	s, r := newTestTailSampler()
+	s.Start()
+	s.Add(time.Now(), tailChunk(&pb.Span{TraceID: 1, SpanID: 1}))
+	s.Add(time.Now(), tailChunk(&pb.Span{TraceID: 2, SpanID: 2}))
+	s.Stop()
+	assert.Len(t, r.decided, 2)
+	assert.Zero(t, s.order.Len())
+	assert.Zero(t, s.size)
+}
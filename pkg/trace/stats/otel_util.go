@@ -139,8 +139,15 @@ func obfuscateSpanForConcentrator(o *obfuscate.Obfuscator, span *pb.Span, conf *
 	if span.Meta == nil {
 		return
 	}
+	if conf.Obfuscation.DynamoDB.Enabled && transform.IsDynamoDBSpan(span.Type, func(key string) (string, bool) { return traceutil.GetMeta(span, key) }) {
+		transform.ObfuscateDynamoDBSpan(o, span)
+	}
 	switch span.Type {
 	case "sql", "cassandra":
+		if span.Type == "cassandra" && conf.Obfuscation.CQL.Enabled {
+			transform.ObfuscateCQLSpan(o, span)
+			return
+		}
 		_, err := transform.ObfuscateSQLSpan(o, span)
 		if err != nil {
 			log.Debugf("Error parsing SQL query: %v. Resource: %q", err, span.Resource)
@@ -150,6 +157,10 @@ func obfuscateSpanForConcentrator(o *obfuscate.Obfuscator, span *pb.Span, conf *
 		if conf.Obfuscation.Redis.Enabled {
 			transform.ObfuscateRedisSpan(o, span, conf.Obfuscation.Redis.RemoveAllArgs)
 		}
+	case "graphql":
+		if conf.Obfuscation.GraphQL.Enabled {
+			transform.ObfuscateGraphQLSpan(o, span)
+		}
 	}
 }
 
@@ -6,6 +6,8 @@
 package transform
 
 import (
+	"strings"
+
 	semconv126 "go.opentelemetry.io/otel/semconv/v1.26.0"
 	semconv "go.opentelemetry.io/otel/semconv/v1.6.1"
 
@@ -33,6 +35,16 @@ const (
 	TagHTTPURL = "http.url"
 	// TagDBMS represents a DBMS tag
 	TagDBMS = "db.type"
+	// TagDBSystem represents a database system tag
+	TagDBSystem = "db.system"
+	// TagDBStatement represents a database statement tag
+	TagDBStatement = "db.statement"
+	// TagDBQueryText represents a database query text tag, replacing db.statement in recent semantic conventions
+	TagDBQueryText = string(semconv126.DBQueryTextKey)
+	// TagGraphQLSource represents a GraphQL document tag
+	TagGraphQLSource = "graphql.source"
+	// TagAWSService represents the AWS service tag set by the Datadog tracers on AWS SDK spans
+	TagAWSService = "aws.service"
 )
 
 const (
@@ -40,12 +52,12 @@ const (
 	TextNonParsable = "Non-parsable SQL query"
 )
 
-func obfuscateOTelDBAttributes(oq *obfuscate.ObfuscatedQuery, span *pb.Span) {
+func obfuscateOTelDBAttributes(query string, span *pb.Span) {
 	if _, ok := traceutil.GetMeta(span, string(semconv.DBStatementKey)); ok {
-		traceutil.SetMeta(span, string(semconv.DBStatementKey), oq.Query)
+		traceutil.SetMeta(span, string(semconv.DBStatementKey), query)
 	}
 	if _, ok := traceutil.GetMeta(span, string(semconv126.DBQueryTextKey)); ok {
-		traceutil.SetMeta(span, string(semconv126.DBQueryTextKey), oq.Query)
+		traceutil.SetMeta(span, string(semconv126.DBQueryTextKey), query)
 	}
 }
 
@@ -62,7 +74,7 @@ func ObfuscateSQLSpan(o *obfuscate.Obfuscator, span *pb.Span) (*obfuscate.Obfusc
 		return nil, err
 	}
 	span.Resource = oq.Query
-	obfuscateOTelDBAttributes(oq, span)
+	obfuscateOTelDBAttributes(oq.Query, span)
 	if len(oq.Metadata.TablesCSV) > 0 {
 		traceutil.SetMeta(span, "sql.tables", oq.Metadata.TablesCSV)
 	}
@@ -70,6 +82,49 @@ func ObfuscateSQLSpan(o *obfuscate.Obfuscator, span *pb.Span) (*obfuscate.Obfusc
 	return oq, nil
 }
 
+// ObfuscateCQLSpan obfuscates a Cassandra span using pkg/obfuscate CQL logic
+func ObfuscateCQLSpan(o *obfuscate.Obfuscator, span *pb.Span) {
+	if span.Resource == "" {
+		return
+	}
+	query := o.ObfuscateCQLString(span.Resource)
+	span.Resource = query
+	obfuscateOTelDBAttributes(query, span)
+	traceutil.SetMeta(span, TagSQLQuery, query)
+}
+
+// ObfuscateGraphQLSpan obfuscates the GraphQL document of a span using pkg/obfuscate logic
+func ObfuscateGraphQLSpan(o *obfuscate.Obfuscator, span *pb.Span) {
+	if span.Meta == nil || span.Meta[TagGraphQLSource] == "" {
+		return
+	}
+	span.Meta[TagGraphQLSource] = o.ObfuscateGraphQLString(span.Meta[TagGraphQLSource])
+}
+
+// IsDynamoDBSpan reports whether a span of type spanType, whose attributes are read with
+// getAttribute, is a DynamoDB call. This covers the spans of type "dynamodb", the OpenTelemetry
+// spans with db.system set to dynamodb and the AWS SDK spans of the Datadog tracers, which have
+// the http or aws type and name the DynamoDB service in aws.service.
+func IsDynamoDBSpan(spanType string, getAttribute func(key string) (string, bool)) bool {
+	if spanType == "dynamodb" {
+		return true
+	}
+	if system, _ := getAttribute(TagDBSystem); system == "dynamodb" {
+		return true
+	}
+	service, _ := getAttribute(TagAWSService)
+	return strings.Contains(strings.ToLower(service), "dynamodb")
+}
+
+// ObfuscateDynamoDBSpan obfuscates the PartiQL statement of a DynamoDB span using pkg/obfuscate logic
+func ObfuscateDynamoDBSpan(o *obfuscate.Obfuscator, span *pb.Span) {
+	for _, key := range []string{TagDBStatement, TagDBQueryText} {
+		if v, ok := traceutil.GetMeta(span, key); ok && v != "" {
+			traceutil.SetMeta(span, key, o.ObfuscateDynamoDBString(v))
+		}
+	}
+}
+
 // ObfuscateRedisSpan obfuscates a Redis span using pkg/obfuscate logic
 func ObfuscateRedisSpan(o *obfuscate.Obfuscator, span *pb.Span, removeAllArgs bool) {
 	if span.Meta == nil || span.Meta[TagRedisRawCommand] == "" {
@@ -0,0 +1,50 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package transform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/obfuscate"
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
+)
+
+func TestObfuscateGraphQLSpan(t *testing.T) {
+	o := obfuscate.NewObfuscator(obfuscate.Config{GraphQL: obfuscate.GraphQLConfig{Enabled: true}})
+	span := &pb.Span{Type: "graphql", Meta: map[string]string{TagGraphQLSource: `query { user(id: 42) { name } }`}}
+	ObfuscateGraphQLSpan(o, span)
+	assert.Equal(t, `query { user(id: ?) { name } }`, span.Meta[TagGraphQLSource])
+}
+
+func TestObfuscateDynamoDBSpan(t *testing.T) {
+	o := obfuscate.NewObfuscator(obfuscate.Config{DynamoDB: obfuscate.DynamoDBConfig{Enabled: true}})
+	for _, tt := range []struct {
+		name     string
+		span     *pb.Span
+		dynamoDB bool
+	}{
+		{"type", &pb.Span{Type: "dynamodb", Meta: map[string]string{}}, true},
+		{"otel", &pb.Span{Type: "db", Meta: map[string]string{TagDBSystem: "dynamodb"}}, true},
+		{"tracer", &pb.Span{Type: "http", Meta: map[string]string{TagAWSService: "DynamoDB"}}, true},
+		{"other service", &pb.Span{Type: "http", Meta: map[string]string{TagAWSService: "SQS"}}, false},
+		{"other database", &pb.Span{Type: "db", Meta: map[string]string{TagDBSystem: "postgresql"}}, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.dynamoDB, IsDynamoDBSpan(tt.span.Type, func(key string) (string, bool) { return traceutil.GetMeta(tt.span, key) }))
+		})
+	}
+
+	span := &pb.Span{Type: "dynamodb", Meta: map[string]string{
+		TagDBStatement: `SELECT * FROM "Music" WHERE Artist = 'Acme Band'`,
+		TagDBQueryText: `UPDATE "Music" SET Price = 12 WHERE Artist = 'Acme Band'`,
+	}}
+	ObfuscateDynamoDBSpan(o, span)
+	assert.Equal(t, `SELECT * FROM "Music" WHERE Artist = ?`, span.Meta[TagDBStatement])
+	assert.Equal(t, `UPDATE "Music" SET Price = ? WHERE Artist = ?`, span.Meta[TagDBQueryText])
+}
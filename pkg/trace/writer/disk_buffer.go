@@ -0,0 +1,429 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package writer
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/log"
+)
+
+const (
+	// diskBufferFileExt is the extension of the buffered payload files. It is the one used by
+	// the forwarder's on-disk retry queue.
+	diskBufferFileExt = ".retry"
+	// diskBufferFormatVersion is the version of the HttpTransactionProtoCollection
+	// serialization used by the forwarder's on-disk retry queue.
+	diskBufferFormatVersion = 2
+)
+
+// diskBufferReplayInterval is the interval at which a sender checks whether buffered
+// payloads can be replayed; replaced in tests.
+var diskBufferReplayInterval = time.Second
+
+// diskBuffer persists the payloads which couldn't be delivered to an intake endpoint, so that
+// they can be replayed, oldest first, once the endpoint recovers. Each payload is stored in its
+// own file, serialized as the HttpTransactionProtoCollection used by the forwarder's on-disk
+// retry queue. The API key is not stored; it is added back when the payload is sent.
+type diskBuffer struct {
+	path    string        // directory holding the payload files
+	url     *url.URL      // URL of the endpoint the payloads are sent to
+	maxSize int64         // maximum total size of the payload files
+	maxAge  time.Duration // maximum age of a payload before it's dropped; 0 for no limit
+
+	// onDrop is called with the body size of each payload dropped because it was too old or
+	// had to be evicted to stay within maxSize.
+	onDrop func(bytes int)
+
+	mu    sync.Mutex
+	files []diskBufferFile // payload files, oldest first
+	size  int64            // total size of files
+	seq   uint64           // sequence number of the last file written
+}
+
+// diskBufferFile describes a payload file of a diskBuffer.
+type diskBufferFile struct {
+	name     string
+	size     int64
+	bodySize int // size of the payload body; 0 if unknown
+}
+
+// newDiskBuffer returns a diskBuffer storing the payloads sent to u in the directory path,
+// which is created if needed. Payload files left by a previous run are kept and replayed first.
+func newDiskBuffer(path string, u *url.URL, maxSize int64, maxAge time.Duration) (*diskBuffer, error) {
+	if maxSize <= 0 {
+		return nil, fmt.Errorf("invalid maximum size: %d", maxSize)
+	}
+	if err := os.MkdirAll(path, 0700); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	b := &diskBuffer{
+		path:    path,
+		url:     u,
+		maxSize: maxSize,
+		maxAge:  maxAge,
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if !strings.HasSuffix(e.Name(), diskBufferFileExt) {
+			// leftover of an interrupted write
+			_ = os.Remove(filepath.Join(path, e.Name()))
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		b.files = append(b.files, diskBufferFile{name: e.Name(), size: info.Size()})
+		b.size += info.Size()
+	}
+	// file names start with their creation time
+	sort.Slice(b.files, func(i, j int) bool { return b.files[i].name < b.files[j].name })
+	if len(b.files) > 0 {
+		log.Infof("Found %d payloads (%d bytes) to replay in the disk buffer %s", len(b.files), b.size, path)
+	}
+	b.makeRoomFor(0)
+	return b, nil
+}
+
+// len returns the number of buffered payloads.
+func (b *diskBuffer) len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.files)
+}
+
+// push writes p to the buffer, evicting the oldest payloads if the maximum size is reached.
+// The caller keeps the ownership of p.
+func (b *diskBuffer) push(p *payload) error {
+	data := b.encode(p)
+	if int64(len(data)) > b.maxSize {
+		return fmt.Errorf("payload of %d bytes exceeds the disk buffer size", len(data))
+	}
+	b.mu.Lock()
+	now := time.Now().UTC()
+	b.seq++
+	name := fmt.Sprintf("%s%09d_%020d%s", now.Format("2006_01_02__15_04_05_"), now.Nanosecond(), b.seq, diskBufferFileExt)
+	b.mu.Unlock()
+
+	// the file is written without holding the lock, so that a slow disk doesn't block the
+	// other pushes and the replay
+	tmp := filepath.Join(b.path, name+".tmp")
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(b.path, name)); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.makeRoomFor(int64(len(data)))
+	// concurrent pushes may complete out of order
+	i := sort.Search(len(b.files), func(i int) bool { return b.files[i].name > name })
+	b.files = slices.Insert(b.files, i, diskBufferFile{name: name, size: int64(len(data)), bodySize: p.body.Len()})
+	b.size += int64(len(data))
+	return nil
+}
+
+// pop reads the oldest buffered payload and passes it to send. The payload is removed from the
+// buffer only if send accepts it by returning true, in which case send takes the ownership of
+// the payload. Payloads which are older than the maximum age or can't be read are dropped.
+// pop reports whether a payload was accepted by send.
+//
+// The lock is only held to look up and remove files: reading the payload and calling send,
+// which may enqueue it, happen without it, so that pushes are never blocked by the replay.
+func (b *diskBuffer) pop(send func(p *payload) bool) bool {
+	for {
+		b.mu.Lock()
+		if len(b.files) == 0 {
+			b.mu.Unlock()
+			return false
+		}
+		f := b.files[0]
+		b.mu.Unlock()
+
+		path := filepath.Join(b.path, f.name)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			// evicted by a concurrent push
+			b.remove(f.name)
+			continue
+		}
+		if err != nil {
+			log.Errorf("Error reading buffered payload %s: %v", path, err)
+			b.remove(f.name)
+			continue
+		}
+		p, err := b.decode(data)
+		if err != nil {
+			log.Errorf("Error decoding buffered payload %s: %v", path, err)
+			b.remove(f.name)
+			continue
+		}
+		if b.maxAge > 0 && time.Since(p.created) > b.maxAge {
+			b.drop(p.body.Len())
+			ppool.Put(p)
+			b.remove(f.name)
+			continue
+		}
+		if !send(p) {
+			ppool.Put(p)
+			return false
+		}
+		b.remove(f.name)
+		return true
+	}
+}
+
+// remove removes the payload file name from the buffer, if it wasn't evicted in the meantime.
+func (b *diskBuffer) remove(name string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, f := range b.files {
+		if f.name == name {
+			b.removeAt(i)
+			return
+		}
+	}
+}
+
+// makeRoomFor evicts the oldest payloads until n more bytes fit in the buffer. It must be
+// called with b.mu held.
+func (b *diskBuffer) makeRoomFor(n int64) {
+	for len(b.files) > 0 && b.size+n > b.maxSize {
+		f := b.files[0]
+		bytes := f.bodySize
+		if bytes == 0 {
+			// file left by a previous run
+			bytes = int(f.size)
+			if data, err := os.ReadFile(filepath.Join(b.path, f.name)); err == nil {
+				if p, err := b.decode(data); err == nil {
+					bytes = p.body.Len()
+					ppool.Put(p)
+				}
+			}
+		}
+		log.Debugf("Disk buffer %s is full; dropping payload %s", b.path, f.name)
+		b.drop(bytes)
+		b.removeAt(0)
+	}
+}
+
+// removeAt removes the i-th payload file. It must be called with b.mu held.
+func (b *diskBuffer) removeAt(i int) {
+	f := b.files[i]
+	if err := os.Remove(filepath.Join(b.path, f.name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Errorf("Error removing buffered payload: %v", err)
+	}
+	b.files = slices.Delete(b.files, i, i+1)
+	b.size -= f.size
+}
+
+func (b *diskBuffer) drop(bytes int) {
+	if b.onDrop != nil {
+		b.onDrop(bytes)
+	}
+}
+
+// Field numbers of the forwarder's HttpTransactionProtoCollection and HttpTransactionProto
+// messages.
+const (
+	collectionVersionField = 1
+	collectionValuesField  = 2
+
+	transactionDomainField     = 1
+	transactionEndpointField   = 2
+	transactionHeadersField    = 3
+	transactionPayloadField    = 4
+	transactionErrorCountField = 5
+	transactionCreatedAtField  = 6
+	transactionRetryableField  = 7
+
+	endpointRouteField = 1
+	endpointNameField  = 2
+
+	mapKeyField   = 1
+	mapValueField = 2
+
+	headerValuesField = 1
+)
+
+// encode serializes p as a collection holding a single transaction.
+func (b *diskBuffer) encode(p *payload) []byte {
+	var endpoint []byte
+	endpoint = protowire.AppendTag(endpoint, endpointRouteField, protowire.BytesType)
+	endpoint = protowire.AppendString(endpoint, b.url.Path)
+	endpoint = protowire.AppendTag(endpoint, endpointNameField, protowire.BytesType)
+	endpoint = protowire.AppendString(endpoint, "trace_agent")
+
+	var tx []byte
+	tx = protowire.AppendTag(tx, transactionDomainField, protowire.BytesType)
+	tx = protowire.AppendString(tx, b.url.Scheme+"://"+b.url.Host)
+	tx = protowire.AppendTag(tx, transactionEndpointField, protowire.BytesType)
+	tx = protowire.AppendBytes(tx, endpoint)
+	for k, v := range p.headers {
+		var values, entry []byte
+		values = protowire.AppendTag(values, headerValuesField, protowire.BytesType)
+		values = protowire.AppendString(values, v)
+		entry = protowire.AppendTag(entry, mapKeyField, protowire.BytesType)
+		entry = protowire.AppendString(entry, k)
+		entry = protowire.AppendTag(entry, mapValueField, protowire.BytesType)
+		entry = protowire.AppendBytes(entry, values)
+		tx = protowire.AppendTag(tx, transactionHeadersField, protowire.BytesType)
+		tx = protowire.AppendBytes(tx, entry)
+	}
+	tx = protowire.AppendTag(tx, transactionPayloadField, protowire.BytesType)
+	tx = protowire.AppendBytes(tx, p.body.Bytes())
+	tx = protowire.AppendTag(tx, transactionErrorCountField, protowire.VarintType)
+	tx = protowire.AppendVarint(tx, uint64(p.retries.Load()))
+	tx = protowire.AppendTag(tx, transactionCreatedAtField, protowire.VarintType)
+	tx = protowire.AppendVarint(tx, uint64(p.created.Unix()))
+	tx = protowire.AppendTag(tx, transactionRetryableField, protowire.VarintType)
+	tx = protowire.AppendVarint(tx, protowire.EncodeBool(true))
+
+	var out []byte
+	out = protowire.AppendTag(out, collectionVersionField, protowire.VarintType)
+	out = protowire.AppendVarint(out, diskBufferFormatVersion)
+	out = protowire.AppendTag(out, collectionValuesField, protowire.BytesType)
+	out = protowire.AppendBytes(out, tx)
+	return out
+}
+
+// decode returns the payload serialized in data. Its creation time is restored while its
+// retry count is reset.
+func (b *diskBuffer) decode(data []byte) (*payload, error) {
+	var (
+		tx      []byte
+		found   bool
+		version uint64
+	)
+	err := consumeFields(data, func(num protowire.Number, v uint64, bytes []byte) error {
+		switch num {
+		case collectionVersionField:
+			version = v
+		case collectionValuesField:
+			if found {
+				return errors.New("more than one transaction")
+			}
+			tx, found = bytes, true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if version != diskBufferFormatVersion {
+		return nil, fmt.Errorf("unsupported version %d", version)
+	}
+	if !found {
+		return nil, errors.New("no transaction")
+	}
+	var (
+		headers = make(map[string]string)
+		body    []byte
+		created int64
+	)
+	err = consumeFields(tx, func(num protowire.Number, v uint64, bytes []byte) error {
+		switch num {
+		case transactionHeadersField:
+			var key, value string
+			err := consumeFields(bytes, func(num protowire.Number, _ uint64, bytes []byte) error {
+				switch num {
+				case mapKeyField:
+					key = string(bytes)
+				case mapValueField:
+					return consumeFields(bytes, func(num protowire.Number, _ uint64, bytes []byte) error {
+						if num == headerValuesField {
+							value = string(bytes)
+						}
+						return nil
+					})
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			headers[key] = value
+		case transactionPayloadField:
+			body = bytes
+		case transactionCreatedAtField:
+			created = int64(v)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	p := newPayload(headers)
+	p.body.Write(body)
+	p.created = time.Unix(created, 0)
+	return p, nil
+}
+
+// consumeFields calls fn with the number and the value of each field of the protobuf message
+// data. Varint values are passed in v and length-delimited ones in bytes; other wire types are
+// skipped.
+func consumeFields(data []byte, fn func(num protowire.Number, v uint64, bytes []byte) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		var (
+			v     uint64
+			bytes []byte
+		)
+		switch typ {
+		case protowire.VarintType:
+			v, n = protowire.ConsumeVarint(data)
+		case protowire.BytesType:
+			bytes, n = protowire.ConsumeBytes(data)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		if err := fn(num, v, bytes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// diskBufferDir returns the name of the directory holding the buffered payloads sent to u.
+func diskBufferDir(u *url.URL) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9', r == '.', r == '-':
+			return r
+		}
+		return '_'
+	}, u.Host+u.Path)
+}
@@ -0,0 +1,229 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package writer
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestDiskBuffer(t *testing.T) {
+	u, err := url.Parse("https://trace.agent.datadoghq.com/api/v0.2/stats")
+	require.NoError(t, err)
+
+	testPayload := func(body string) *payload {
+		p := newPayload(map[string]string{"Content-Type": "application/msgpack", "Content-Encoding": "gzip"})
+		p.body.WriteString(body)
+		return p
+	}
+	popAll := func(b *diskBuffer) []string {
+		var bodies []string
+		for b.pop(func(p *payload) bool {
+			bodies = append(bodies, p.body.String())
+			return true
+		}) {
+		}
+		return bodies
+	}
+
+	t.Run("round-trip", func(t *testing.T) {
+		b, err := newDiskBuffer(t.TempDir(), u, 1024*1024, time.Hour)
+		require.NoError(t, err)
+		p := testPayload("payload")
+		p.retries.Store(3)
+		require.NoError(t, b.push(p))
+		assert.Equal(t, 1, b.len())
+
+		var got *payload
+		assert.True(t, b.pop(func(p *payload) bool {
+			got = p
+			return true
+		}))
+		assert.Equal(t, "payload", got.body.String())
+		assert.Equal(t, map[string]string{"Content-Type": "application/msgpack", "Content-Encoding": "gzip"}, got.headers)
+		assert.EqualValues(t, 0, got.retries.Load())
+		assert.Equal(t, p.created.Unix(), got.created.Unix())
+		assert.Equal(t, 0, b.len())
+		assert.False(t, b.pop(func(*payload) bool { return true }))
+	})
+
+	t.Run("refused", func(t *testing.T) {
+		b, err := newDiskBuffer(t.TempDir(), u, 1024*1024, time.Hour)
+		require.NoError(t, err)
+		require.NoError(t, b.push(testPayload("payload")))
+		assert.False(t, b.pop(func(*payload) bool { return false }))
+		assert.Equal(t, 1, b.len())
+		assert.Equal(t, []string{"payload"}, popAll(b))
+	})
+
+	t.Run("order", func(t *testing.T) {
+		dir := t.TempDir()
+		b, err := newDiskBuffer(dir, u, 1024*1024, time.Hour)
+		require.NoError(t, err)
+		var want []string
+		for i := 0; i < 20; i++ {
+			want = append(want, strconv.Itoa(i))
+			require.NoError(t, b.push(testPayload(strconv.Itoa(i))))
+		}
+		// leftover of an interrupted write
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "partial.retry.tmp"), []byte("x"), 0600))
+
+		// payloads are replayed in order after a restart
+		b, err = newDiskBuffer(dir, u, 1024*1024, time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, want, popAll(b))
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("max-size", func(t *testing.T) {
+		var dropped []int
+		b, err := newDiskBuffer(t.TempDir(), u, 1024*1024, time.Hour)
+		require.NoError(t, err)
+		size := int64(len(b.encode(testPayload("0"))))
+		b.maxSize = 3 * size
+		b.onDrop = func(bytes int) { dropped = append(dropped, bytes) }
+		for i := 0; i < 5; i++ {
+			require.NoError(t, b.push(testPayload(strconv.Itoa(i))))
+		}
+		assert.Equal(t, []int{1, 1}, dropped)
+		assert.Equal(t, []string{"2", "3", "4"}, popAll(b))
+
+		// a payload larger than the buffer is refused
+		assert.Error(t, b.push(testPayload(string(make([]byte, 3*size)))))
+	})
+
+	t.Run("max-age", func(t *testing.T) {
+		var dropped []int
+		dir := t.TempDir()
+		b, err := newDiskBuffer(dir, u, 1024*1024, time.Hour)
+		require.NoError(t, err)
+		b.onDrop = func(bytes int) { dropped = append(dropped, bytes) }
+		require.NoError(t, b.push(testPayload("old")))
+		require.NoError(t, b.push(testPayload("new")))
+
+		// rewrite the creation time of the first payload
+		old := filepath.Join(dir, b.files[0].name)
+		data, err := os.ReadFile(old)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(old, setCreatedAt(t, data, time.Now().Add(-2*time.Hour)), 0600))
+
+		assert.Equal(t, []string{"new"}, popAll(b))
+		assert.Equal(t, []int{3}, dropped)
+
+		// the age is measured from the creation of the payload, not from its buffering
+		p := testPayload("stale")
+		p.created = time.Now().Add(-2 * time.Hour)
+		require.NoError(t, b.push(p))
+		assert.Empty(t, popAll(b))
+		assert.Equal(t, []int{3, 5}, dropped)
+	})
+
+	t.Run("corrupted", func(t *testing.T) {
+		dir := t.TempDir()
+		b, err := newDiskBuffer(dir, u, 1024*1024, time.Hour)
+		require.NoError(t, err)
+		require.NoError(t, b.push(testPayload("first")))
+		require.NoError(t, b.push(testPayload("second")))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, b.files[0].name), []byte{0xff, 0xff}, 0600))
+		assert.Equal(t, []string{"second"}, popAll(b))
+	})
+}
+
+func TestDiskBufferFormat(t *testing.T) {
+	// the payloads are stored as the forwarder's HttpTransactionProtoCollection
+	u, err := url.Parse("https://trace.agent.datadoghq.com/api/v0.2/stats")
+	require.NoError(t, err)
+	b, err := newDiskBuffer(t.TempDir(), u, 1024*1024, time.Hour)
+	require.NoError(t, err)
+	p := newPayload(map[string]string{"Content-Type": "application/msgpack"})
+	p.body.WriteString("payload")
+	p.created = time.Now().Add(-time.Minute)
+
+	var (
+		version uint64
+		tx      []byte
+	)
+	require.NoError(t, consumeFields(b.encode(p), func(num protowire.Number, v uint64, bytes []byte) error {
+		switch num {
+		case collectionVersionField:
+			version = v
+		case collectionValuesField:
+			tx = bytes
+		}
+		return nil
+	}))
+	assert.EqualValues(t, 2, version)
+
+	fields := make(map[protowire.Number]interface{})
+	require.NoError(t, consumeFields(tx, func(num protowire.Number, v uint64, bytes []byte) error {
+		switch num {
+		case transactionDomainField, transactionPayloadField:
+			fields[num] = string(bytes)
+		case transactionEndpointField:
+			return consumeFields(bytes, func(num protowire.Number, _ uint64, bytes []byte) error {
+				if num == endpointRouteField {
+					fields[transactionEndpointField] = string(bytes)
+				}
+				return nil
+			})
+		case transactionCreatedAtField, transactionRetryableField:
+			fields[num] = v
+		}
+		return nil
+	}))
+	assert.Equal(t, "https://trace.agent.datadoghq.com", fields[transactionDomainField])
+	assert.Equal(t, "/api/v0.2/stats", fields[transactionEndpointField])
+	assert.Equal(t, "payload", fields[transactionPayloadField])
+	assert.EqualValues(t, 1, fields[transactionRetryableField])
+	assert.EqualValues(t, p.created.Unix(), fields[transactionCreatedAtField])
+}
+
+func TestDiskBufferDir(t *testing.T) {
+	u, err := url.Parse("https://trace.agent.datadoghq.com:443/api/v0.2/traces")
+	require.NoError(t, err)
+	assert.Equal(t, "trace.agent.datadoghq.com_443_api_v0.2_traces", diskBufferDir(u))
+}
+
+// setCreatedAt returns the serialized collection data with the creation time of its
+// transaction replaced by ts.
+func setCreatedAt(t *testing.T, data []byte, ts time.Time) []byte {
+	var tx, out []byte
+	require.NoError(t, consumeFields(data, func(num protowire.Number, _ uint64, bytes []byte) error {
+		if num == collectionValuesField {
+			tx = bytes
+		}
+		return nil
+	}))
+	require.NoError(t, consumeFields(tx, func(num protowire.Number, v uint64, bytes []byte) error {
+		switch num {
+		case transactionCreatedAtField:
+			v = uint64(ts.Unix())
+			fallthrough
+		case transactionErrorCountField, transactionRetryableField:
+			out = protowire.AppendTag(out, num, protowire.VarintType)
+			out = protowire.AppendVarint(out, v)
+		default:
+			out = protowire.AppendTag(out, num, protowire.BytesType)
+			out = protowire.AppendBytes(out, bytes)
+		}
+		return nil
+	}))
+	var collection []byte
+	collection = protowire.AppendTag(collection, collectionVersionField, protowire.VarintType)
+	collection = protowire.AppendVarint(collection, diskBufferFormatVersion)
+	collection = protowire.AppendTag(collection, collectionValuesField, protowire.BytesType)
+	return protowire.AppendBytes(collection, out)
+}
@@ -17,6 +17,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 	"sync"
 	"time"
@@ -45,6 +46,14 @@ func newSenders(cfg *config.AgentConfig, r eventRecorder, path string, climit, q
 			log.Criticalf("Invalid host endpoint: %q", endpoint.Host)
 			os.Exit(1)
 		}
+		var buffer *diskBuffer
+		if b := cfg.DiskBuffer; b != nil && b.Enabled {
+			buffer, err = newDiskBuffer(filepath.Join(b.Path, diskBufferDir(url)), url, b.MaxSizeBytes, b.MaxAge)
+			if err != nil {
+				log.Errorf("Disk buffer disabled for %s: %v", url.Host, err)
+				buffer = nil
+			}
+		}
 		senders[i] = newSender(&senderConfig{
 			client:         cfg.NewHTTPClient(),
 			maxConns:       int(maxConns),
@@ -56,6 +65,7 @@ func newSenders(cfg *config.AgentConfig, r eventRecorder, path string, climit, q
 			userAgent:      fmt.Sprintf("Datadog Trace Agent/%s/%s", cfg.AgentVersion, cfg.GitCommit),
 			isMRF:          endpoint.IsMRF,
 			MRFFailoverAPM: cfg.MRFFailoverAPM,
+			diskBuffer:     buffer,
 		}, statsd)
 	}
 	return senders
@@ -100,6 +110,9 @@ const (
 	// eventTypeDropped specifies that a payload had to be dropped to make room
 	// in the queue.
 	eventTypeDropped
+	// eventTypeBuffered specifies that a payload was written to the disk buffer
+	// to be sent later.
+	eventTypeBuffered
 )
 
 var eventTypeStrings = map[eventType]string{
@@ -107,6 +120,7 @@ var eventTypeStrings = map[eventType]string{
 	eventTypeSent:     "eventTypeSent",
 	eventTypeRejected: "eventTypeRejected",
 	eventTypeDropped:  "eventTypeDropped",
+	eventTypeBuffered: "eventTypeBuffered",
 }
 
 // String implements fmt.Stringer.
@@ -160,6 +174,9 @@ type senderConfig struct {
 	isMRF bool
 	// MRFFailoverAPM determines whether APM data should be failed over to the secondary (MRF) DC.
 	MRFFailoverAPM func() bool
+	// diskBuffer, if set, persists the payloads which can't be queued or sent, to replay them
+	// once the endpoint recovers.
+	diskBuffer *diskBuffer
 }
 
 // sender is responsible for sending payloads to a given URL. It uses a size-limited
@@ -175,6 +192,10 @@ type sender struct {
 	closed  bool         // closed reports if the loop is stopped
 	statsd  statsd.ClientInterface
 	enabled bool // false on inactive MRF senders. True otherwise
+
+	healthy    *atomic.Bool  // false after a retriable error, until a payload is sent
+	exit       chan struct{} // closed to stop the disk buffer replay
+	replayDone chan struct{} // closed once the disk buffer replay is stopped
 }
 
 // newSender returns a new sender based on the given config cfg.
@@ -186,10 +207,21 @@ func newSender(cfg *senderConfig, statsd statsd.ClientInterface) *sender {
 		maxRetries: int32(cfg.maxRetries),
 		statsd:     statsd,
 		enabled:    true,
+		healthy:    atomic.NewBool(true),
+		exit:       make(chan struct{}),
+		replayDone: make(chan struct{}),
 	}
 	for i := 0; i < cfg.maxConns; i++ {
 		go s.loop()
 	}
+	if cfg.diskBuffer != nil {
+		cfg.diskBuffer.onDrop = func(bytes int) {
+			s.recordEvent(eventTypeDropped, &eventData{bytes: bytes, count: 1})
+		}
+		go s.replay()
+	} else {
+		close(s.replayDone)
+	}
 	return &s
 }
 
@@ -213,6 +245,8 @@ func (s *sender) backoff(attempt int) {
 // with a timeout of 5 seconds.
 func (s *sender) Stop() {
 	s.WaitForInflight()
+	close(s.exit)
+	<-s.replayDone
 	s.mu.Lock()
 	s.closed = true
 	s.mu.Unlock()
@@ -237,7 +271,9 @@ outer:
 	}
 }
 
-// Push pushes p onto the sender's queue, to be written to the destination.
+// Push pushes p onto the sender's queue, to be written to the destination. When the
+// destination is failing and the queue is full, p is written to the disk buffer, if any,
+// instead of blocking.
 func (s *sender) Push(p *payload) {
 	s.mu.RLock()
 	if s.closed {
@@ -248,6 +284,10 @@ func (s *sender) Push(p *payload) {
 	select {
 	case s.queue <- p:
 	default:
+		if !s.healthy.Load() && s.bufferPayload(p, &eventData{bytes: p.body.Len(), count: 1}) {
+			// the endpoint is failing; rather than blocking, keep the payload for later
+			return
+		}
 		_ = s.statsd.Count("datadog.trace_agent.sender.push_blocked", 1, nil, 1)
 		s.queue <- p
 	}
@@ -287,9 +327,14 @@ func (s *sender) sendOnce(p *payload) bool {
 	switch err.(type) {
 	case *retriableError:
 		// request failed again, but can be retried
+		s.healthy.Store(false)
 		s.mu.RLock()
 		defer s.mu.RUnlock()
 		if s.closed {
+			if s.bufferPayload(p, stats) {
+				s.inflight.Dec()
+				return true
+			}
 			s.releasePayload(p, eventTypeDropped, stats)
 			// sender is stopped
 			return true
@@ -302,6 +347,10 @@ func (s *sender) sendOnce(p *payload) bool {
 			log.Warnf("Retried payload %d times: %s", r, err.Error())
 		}
 		if p.retries.Load() >= s.maxRetries {
+			if s.bufferPayload(p, stats) {
+				s.inflight.Dec()
+				return true
+			}
 			log.Warnf("Dropping Payload after %d retries, due to: %v.\n", p.retries.Load(), err)
 			// queue is full; since this is the oldest payload, we drop it
 			s.releasePayload(p, eventTypeDropped, stats)
@@ -310,6 +359,7 @@ func (s *sender) sendOnce(p *payload) bool {
 		s.recordEvent(eventTypeRetry, stats)
 		return false
 	case nil:
+		s.healthy.Store(true)
 		s.releasePayload(p, eventTypeSent, stats)
 	default:
 		// this is a fatal error, we have to drop this payload
@@ -340,6 +390,65 @@ func (s *sender) releasePayload(p *payload, t eventType, data *eventData) {
 	s.inflight.Dec()
 }
 
+// bufferPayload writes the payload p to the disk buffer, if any, and records the event. It
+// reports whether p was buffered, in which case it is released; otherwise the caller keeps
+// the ownership of p.
+func (s *sender) bufferPayload(p *payload, data *eventData) bool {
+	if s.cfg.diskBuffer == nil {
+		return false
+	}
+	if err := s.cfg.diskBuffer.push(p); err != nil {
+		log.Errorf("Error writing payload to the disk buffer: %v", err)
+		return false
+	}
+	s.recordEvent(eventTypeBuffered, data)
+	ppool.Put(p)
+	return true
+}
+
+// replay periodically moves the payloads of the disk buffer to the queue, oldest first, as
+// long as the endpoint is healthy and the queue has room. While the endpoint fails, the queued
+// payloads probe it; a single buffered payload is moved when none is in flight, so that an
+// idle sender recovers too. It returns when the sender is stopped.
+func (s *sender) replay() {
+	defer close(s.replayDone)
+	tick := time.NewTicker(diskBufferReplayInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-s.exit:
+			return
+		case <-tick.C:
+		}
+		if !s.healthy.Load() {
+			if s.inflight.Load() == 0 {
+				s.replayOne()
+			}
+			continue
+		}
+		for s.healthy.Load() && s.replayOne() {
+		}
+	}
+}
+
+// replayOne moves the oldest payload of the disk buffer to the queue if it has room, leaving
+// it on disk otherwise. It reports whether a payload was moved.
+func (s *sender) replayOne() bool {
+	if cap(s.queue) > 0 && len(s.queue) >= cap(s.queue) {
+		return false
+	}
+	// Stop waits for the replay to return before closing the queue
+	return s.cfg.diskBuffer.pop(func(p *payload) bool {
+		select {
+		case s.queue <- p:
+			s.inflight.Inc()
+			return true
+		default:
+			return false
+		}
+	})
+}
+
 // recordEvent records the occurrence of the given event type t. It additionally
 // passes on the data and augments it with additional information.
 func (s *sender) recordEvent(t eventType, data *eventData) {
@@ -431,6 +540,7 @@ type payload struct {
 	body    *bytes.Buffer     // request body
 	headers map[string]string // request headers
 	retries *atomic.Int32     // number of retries sending this payload
+	created time.Time         // creation time, kept when the payload goes through the disk buffer
 }
 
 // ppool is a pool of payloads.
@@ -451,6 +561,7 @@ func newPayload(headers map[string]string) *payload {
 	p.body.Reset()
 	p.headers = headers
 	p.retries.Store(0)
+	p.created = time.Now()
 	return p
 }
 
@@ -458,6 +569,7 @@ func (p *payload) clone() *payload {
 	headers := make(map[string]string, len(p.headers))
 	maps.Copy(headers, p.headers)
 	clone := newPayload(headers)
+	clone.created = p.created
 	if _, err := clone.body.ReadFrom(bytes.NewBuffer(p.body.Bytes())); err != nil {
 		log.Errorf("Error cloning writer payload: %v", err)
 	}
@@ -263,6 +263,94 @@ func TestSender(t *testing.T) {
 		}
 	})
 
+	t.Run("disk-buffer", func(t *testing.T) {
+		assert := assert.New(t)
+		server := newTestServer()
+		defer server.Close()
+		defer useBackoffDuration(time.Millisecond)()
+		defer func(old time.Duration) { diskBufferReplayInterval = old }(diskBufferReplayInterval)
+		diskBufferReplayInterval = 10 * time.Millisecond
+
+		var recorder mockRecorder
+		cfg := testSenderConfig(server.URL)
+		cfg.recorder = &recorder
+		cfg.maxRetries = 2
+		buffer, err := newDiskBuffer(t.TempDir(), cfg.url, 1024*1024, time.Hour)
+		assert.NoError(err)
+		cfg.diskBuffer = buffer
+		s := newSender(cfg, statsd)
+
+		// the payload is buffered after its retries are exhausted and replayed on the next tick
+		s.Push(expectResponses(503, 503, 200))
+		assert.Eventually(func() bool { return server.Accepted() == 1 }, 5*time.Second, 10*time.Millisecond)
+		s.Stop()
+
+		assert.Equal(3, server.Total(), "total")
+		assert.Equal(2, server.Retried(), "retry")
+		assert.Len(recorder.data(eventTypeBuffered), 1)
+		assert.Len(recorder.data(eventTypeSent), 1)
+		assert.Len(recorder.data(eventTypeDropped), 0)
+		assert.Equal(0, buffer.len())
+	})
+
+	t.Run("disk-buffer-stop", func(t *testing.T) {
+		assert := assert.New(t)
+		server := newTestServer()
+		defer server.Close()
+		defer useBackoffDuration(time.Millisecond)()
+
+		cfg := testSenderConfig(server.URL)
+		buffer, err := newDiskBuffer(t.TempDir(), cfg.url, 1024*1024, time.Hour)
+		assert.NoError(err)
+		cfg.diskBuffer = buffer
+		s := newSender(cfg, statsd)
+
+		// payloads which could not be delivered are kept for the next run
+		s.Push(expectResponses(503))
+		s.Stop()
+
+		assert.Equal(1, buffer.len())
+	})
+
+	t.Run("disk-buffer-full-queue", func(t *testing.T) {
+		assert := assert.New(t)
+		server := newTestServer()
+		defer server.Close()
+		defer useBackoffDuration(time.Millisecond)()
+		defer func(old time.Duration) { diskBufferReplayInterval = old }(diskBufferReplayInterval)
+		diskBufferReplayInterval = time.Millisecond
+
+		cfg := testSenderConfig(server.URL)
+		cfg.maxConns = 1
+		cfg.maxQueued = 1
+		buffer, err := newDiskBuffer(t.TempDir(), cfg.url, 1024*1024, time.Hour)
+		assert.NoError(err)
+		for i := 0; i < 10; i++ {
+			assert.NoError(buffer.push(expectResponses(503)))
+		}
+		cfg.diskBuffer = buffer
+		s := newSender(cfg, statsd)
+
+		// with a failing endpoint and a full queue, pushes go to the disk buffer while it is
+		// being replayed, without blocking
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 100; i++ {
+				s.Push(expectResponses(503))
+			}
+			s.Stop()
+		}()
+		select {
+		case <-done:
+		case <-time.After(30 * time.Second):
+			t.Fatal("sender is blocked")
+		}
+		// the payloads left in the queue are buffered once the sender is stopped
+		assert.Eventually(func() bool { return s.inflight.Load() == 0 }, 5*time.Second, 10*time.Millisecond)
+		assert.Greater(buffer.len(), 0)
+	})
+
 	t.Run("mrf", func(t *testing.T) {
 		assert := assert.New(t)
 		servers := []*testServer{
@@ -368,8 +456,8 @@ func useBackoffDuration(d time.Duration) func() {
 
 // mockRecorder is a mock eventRecorder which records all calls to recordEvent.
 type mockRecorder struct {
-	mu                             sync.RWMutex
-	retry, sent, dropped, rejected []*eventData
+	mu                                       sync.RWMutex
+	retry, sent, dropped, rejected, buffered []*eventData
 }
 
 // data returns all call data for the given eventType.
@@ -385,6 +473,8 @@ func (r *mockRecorder) data(t eventType) []*eventData {
 		return r.dropped
 	case eventTypeRejected:
 		return r.rejected
+	case eventTypeBuffered:
+		return r.buffered
 	default:
 		panic("unknown event")
 	}
@@ -403,5 +493,7 @@ func (r *mockRecorder) recordEvent(t eventType, data *eventData) {
 		r.dropped = append(r.dropped, data)
 	case eventTypeRejected:
 		r.rejected = append(r.rejected, data)
+	case eventTypeBuffered:
+		r.buffered = append(r.buffered, data)
 	}
 }
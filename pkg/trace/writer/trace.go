@@ -369,5 +369,10 @@ func (w *TraceWriter) recordEvent(t eventType, data *eventData) {
 		w.easylog.Warn("Trace Payload dropped (%.2fKB).", float64(data.bytes)/1024)
 		_ = w.statsd.Count("datadog.trace_agent.trace_writer.dropped", 1, nil, 1)
 		_ = w.statsd.Count("datadog.trace_agent.trace_writer.dropped_bytes", int64(data.bytes), nil, 1)
+
+	case eventTypeBuffered:
+		log.Debugf("Trace payload written to the disk buffer (%.2fKB)", float64(data.bytes)/1024)
+		_ = w.statsd.Count("datadog.trace_agent.trace_writer.disk_buffered", 1, nil, 1)
+		_ = w.statsd.Count("datadog.trace_agent.trace_writer.disk_buffered_bytes", int64(data.bytes), nil, 1)
 	}
 }
@@ -152,11 +152,11 @@ func AddDefaultReplacers(scrubber *Scrubber) {
 	tokenReplacer.LastUpdated = parseVersion("7.70.2")
 
 	secretReplacer := matchYAMLKey(
-		`(token_secret|consumer_secret)`,
-		[]string{"token_secret", "consumer_secret"},
+		`(token_secret|consumer_secret|secret_access_key)`,
+		[]string{"token_secret", "consumer_secret", "secret_access_key"},
 		[]byte(`$1 "********"`),
 	)
-	secretReplacer.LastUpdated = parseVersion("7.70.0") // https://github.com/DataDog/datadog-agent/pull/40345
+	secretReplacer.LastUpdated = parseVersion("7.73.0")
 
 	// OAuth credentials scrubbers for continuous_ai_netsuite and similar integrations
 	consumerKeyAndTokenIDReplacer := matchYAMLKey(
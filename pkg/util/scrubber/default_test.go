@@ -636,6 +636,11 @@ func TestOAuthCredentials(t *testing.T) {
 		`  consumer_secret: 'my_consumer_secret_456'`,
 		`  consumer_secret: "********"`)
 
+	// Test secret_access_key
+	assertClean(t,
+		`    secret_access_key: wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY`,
+		`    secret_access_key: "********"`)
+
 	// Test token_id
 	assertClean(t,
 		`token_id: my_token_id_789`,
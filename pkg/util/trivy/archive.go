@@ -0,0 +1,43 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build trivy
+
+package trivy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aquasecurity/trivy/pkg/fanal/applier"
+	image2 "github.com/aquasecurity/trivy/pkg/fanal/artifact/image"
+	fimage "github.com/aquasecurity/trivy/pkg/fanal/image"
+
+	"github.com/DataDog/datadog-agent/pkg/sbom"
+)
+
+// ScanImageArchive scans an image exported with `docker save` or stored as an OCI image layout
+// directory, without relying on any container runtime.
+func (c *Collector) ScanImageArchive(ctx context.Context, path string, scanOptions sbom.ScanOptions) (sbom.Report, error) {
+	fanalImage, err := fimage.NewArchiveImage(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open image archive %s, err: %w", path, err)
+	}
+
+	// Archives are scanned once, persisting their layers in the cache would not add any value.
+	cache := newMemoryCache()
+
+	imageArtifact, err := image2.NewArtifact(fanalImage, cache, getDefaultArtifactOption(scanOptions))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create artifact from image archive, err: %w", err)
+	}
+
+	trivyReport, err := c.scan(ctx, imageArtifact, applier.NewApplier(cache))
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal report to sbom format, err: %w", err)
+	}
+
+	return c.buildReport(trivyReport, trivyReport.Metadata.ImageID)
+}